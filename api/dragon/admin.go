@@ -0,0 +1,121 @@
+package dragon
+
+import (
+	"context"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+// adminMethods are the RPC method names of the admin methods. They are only
+// served with Config.EnableAdmin and only allowed for callers whose Policy
+// sets Admin. ExportAuditEvents is the method name of the audit stream of the
+// gateway (see StreamAuditPath).
+var adminMethods = map[string]bool{
+	"ExportAuditEvents":     true,
+	"GetCacheStats":         true,
+	"InvalidateKeyRing":     true,
+	"GetKeyPoolHealth":      true,
+	"GetRootKeyGenerations": true,
+	"GetAuditSinkStatus":    true,
+	"GetDualRunStats":       true,
+}
+
+// adminEnabled returns twirp.Unimplemented, unless config enables the admin
+// methods.
+func (s *service) adminEnabled() error {
+	if !s.config.EnableAdmin {
+		return twirp.NewError(twirp.Unimplemented, "dragon: admin methods are disabled")
+	}
+	return nil
+}
+
+func (s *service) GetCacheStats(_ context.Context, _ *dragonv1.GetCacheStatsRequest) (*dragonv1.GetCacheStatsResponse, error) {
+	if err := s.adminEnabled(); err != nil {
+		return nil, err
+	}
+
+	resp := &dragonv1.GetCacheStatsResponse{KdfCalls: s.p.Stats().KDFCalls}
+	for _, pool := range s.p.KeyPools() {
+		stats := &dragonv1.KeyPoolCacheStats{
+			Version: pool.Version,
+			Caching: pool.Caching,
+			Hits:    pool.CacheHits,
+			Misses:  pool.CacheMisses,
+		}
+		for _, shard := range pool.Shards {
+			stats.Shards = append(stats.Shards, &dragonv1.CacheShardStats{
+				Size:   int32(shard.Size),
+				Target: int32(shard.Target),
+				T1:     int32(shard.T1),
+				T2:     int32(shard.T2),
+				B1:     int32(shard.B1),
+				B2:     int32(shard.B2),
+				B1Hits: shard.B1Hits,
+				B2Hits: shard.B2Hits,
+			})
+		}
+		resp.KeyPools = append(resp.KeyPools, stats)
+	}
+	return resp, nil
+}
+
+func (s *service) InvalidateKeyRing(ctx context.Context, req *dragonv1.InvalidateKeyRingRequest) (*dragonv1.InvalidateKeyRingResponse, error) {
+	if err := s.adminEnabled(); err != nil {
+		return nil, err
+	}
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+
+	removed := s.p.Invalidate(req.KeyRing)
+	fingerprint := dvx.KeyRingFingerprint(req.KeyRing)
+	caller, _ := CallerFromContext(ctx)
+	s.log.Info("invalidated keyRing",
+		logger.NewField("key_ring_fingerprint", fingerprint),
+		logger.NewField("removed", removed),
+		logger.NewField("caller", caller))
+	return &dragonv1.InvalidateKeyRingResponse{Removed: int32(removed), KeyRingFingerprint: fingerprint}, nil
+}
+
+func (s *service) GetKeyPoolHealth(ctx context.Context, _ *dragonv1.GetKeyPoolHealthRequest) (*dragonv1.GetKeyPoolHealthResponse, error) {
+	if err := s.adminEnabled(); err != nil {
+		return nil, err
+	}
+
+	if err := s.p.SelfTest(ctx); err != nil {
+		return &dragonv1.GetKeyPoolHealthResponse{Error: err.Error()}, nil
+	}
+	return &dragonv1.GetKeyPoolHealthResponse{Healthy: true}, nil
+}
+
+func (s *service) GetRootKeyGenerations(_ context.Context, _ *dragonv1.GetRootKeyGenerationsRequest) (*dragonv1.GetRootKeyGenerationsResponse, error) {
+	if err := s.adminEnabled(); err != nil {
+		return nil, err
+	}
+
+	resp := &dragonv1.GetRootKeyGenerationsResponse{Generations: make(map[string]uint64)}
+	for _, pool := range s.p.KeyPools() {
+		if pool.Generation != 0 {
+			resp.Generations[pool.Version] = pool.Generation
+		}
+	}
+	return resp, nil
+}
+
+func (s *service) GetAuditSinkStatus(ctx context.Context, _ *dragonv1.GetAuditSinkStatusRequest) (*dragonv1.GetAuditSinkStatusResponse, error) {
+	if err := s.adminEnabled(); err != nil {
+		return nil, err
+	}
+	if s.config.AuditSinkCheck == nil {
+		return &dragonv1.GetAuditSinkStatusResponse{}, nil
+	}
+
+	if err := s.config.AuditSinkCheck(ctx); err != nil {
+		return &dragonv1.GetAuditSinkStatusResponse{Configured: true, Error: err.Error()}, nil
+	}
+	return &dragonv1.GetAuditSinkStatusResponse{Configured: true, Available: true}, nil
+}
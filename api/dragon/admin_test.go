@@ -0,0 +1,100 @@
+package dragon
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/liblog"
+	"azoo.dev/utils/dvx/tearc"
+)
+
+func TestService_Admin(t *testing.T) {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	log := logger.MustNewStd()
+	pool, err := tearc.New(&tearc.Config{
+		Size:          64,
+		Shards:        1,
+		BucketMinTick: time.Second,
+		BucketMaxTick: 10 * time.Second,
+		AliveTime:     time.Minute,
+	}, dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, liblog.Wrap(log)), liblog.Wrap(log))
+	require.NoError(t, err)
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool})
+
+	auditErr := errors.New("shipper unreachable")
+	srv := httptest.NewServer(NewHandler(p, &Config{
+		EnableAdmin:    true,
+		AuditSinkCheck: func(ctx context.Context) error { return auditErr },
+	}, log))
+	t.Cleanup(srv.Close)
+	c := dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err = c.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data")})
+		require.NoError(t, err)
+	}
+
+	stats, err := c.GetCacheStats(ctx, &dragonv1.GetCacheStatsRequest{})
+	require.NoError(t, err)
+	require.Len(t, stats.KeyPools, 1)
+	assert.Equal(t, dvx.Version, stats.KeyPools[0].Version)
+	assert.True(t, stats.KeyPools[0].Caching)
+	assert.Equal(t, uint64(1), stats.KeyPools[0].Hits)
+	assert.Equal(t, uint64(1), stats.KeyPools[0].Misses)
+	assert.Equal(t, uint64(2), stats.KdfCalls)
+	require.Len(t, stats.KeyPools[0].Shards, 1)
+	assert.Equal(t, int32(64), stats.KeyPools[0].Shards[0].Size)
+	assert.Equal(t, int32(1), stats.KeyPools[0].Shards[0].T2, "the key was used twice")
+
+	inv, err := c.InvalidateKeyRing(ctx, &dragonv1.InvalidateKeyRingRequest{KeyRing: "keyring"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), inv.Removed)
+	assert.Equal(t, dvx.KeyRingFingerprint("keyring"), inv.KeyRingFingerprint)
+	_, err = c.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data")})
+	require.NoError(t, err)
+	stats, err = c.GetCacheStats(ctx, &dragonv1.GetCacheStatsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.KeyPools[0].Misses, "invalidated keys are derived again")
+
+	health, err := c.GetKeyPoolHealth(ctx, &dragonv1.GetKeyPoolHealthRequest{})
+	require.NoError(t, err)
+	assert.True(t, health.Healthy)
+
+	generations, err := c.GetRootKeyGenerations(ctx, &dragonv1.GetRootKeyGenerationsRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]uint64{dvx.Version: 1}, generations.Generations)
+
+	audit, err := c.GetAuditSinkStatus(ctx, &dragonv1.GetAuditSinkStatusRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, &dragonv1.GetAuditSinkStatusResponse{Configured: true, Error: "shipper unreachable"}, stripAudit(audit))
+}
+
+// stripAudit returns a copy of resp without internal proto state.
+func stripAudit(resp *dragonv1.GetAuditSinkStatusResponse) *dragonv1.GetAuditSinkStatusResponse {
+	return &dragonv1.GetAuditSinkStatusResponse{Configured: resp.Configured, Available: resp.Available, Error: resp.Error}
+}
+
+func TestService_AdminDisabled(t *testing.T) {
+	c := newClient(t, nil)
+
+	_, err := c.GetCacheStats(context.Background(), &dragonv1.GetCacheStatsRequest{})
+	require.Error(t, err)
+	assert.Equal(t, twirp.Unimplemented, err.(twirp.Error).Code())
+}
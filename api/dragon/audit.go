@@ -0,0 +1,246 @@
+package dragon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+// AuditLog buffers an AuditEvent of every method call in memory, so they can
+// be exported to SIEM consumers in near-real-time by the audit stream of the
+// gateway (see StreamAuditPath) or an AuditPusher.
+//
+// The buffer is a ring of fixed size, so method calls never wait for
+// consumers. Events are numbered consecutively and consumers resume after the
+// last event they processed, so every event is delivered at least once, as
+// long as a consumer doesn't fall behind by more than the size of the buffer.
+// Otherwise, the oldest events are dropped, which consumers detect as gap in
+// the sequences. The buffer doesn't survive restarts of the service, its
+// LogID changes instead.
+type AuditLog struct {
+	id string
+
+	mu     sync.Mutex
+	events []*dragonv1.AuditEvent
+	// last is the sequence of the latest event, 0 without events.
+	last uint64
+	// notify is closed and replaced whenever an event is recorded.
+	notify chan struct{}
+}
+
+// NewAuditLog creates an AuditLog buffering up to size events. Defaults to
+// 65536 events.
+func NewAuditLog(size int) *AuditLog {
+	if size <= 0 {
+		size = 65536
+	}
+
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	return &AuditLog{
+		id:     hex.EncodeToString(id[:]),
+		events: make([]*dragonv1.AuditEvent, size),
+		notify: make(chan struct{}),
+	}
+}
+
+// LogID returns the id of a, which is set as log_id of all its events.
+func (a *AuditLog) LogID() string {
+	return a.id
+}
+
+// Last returns the sequence of the latest event, or 0 without events.
+func (a *AuditLog) Last() uint64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
+
+func (a *AuditLog) record(e *dragonv1.AuditEvent) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.last++
+	e.LogId = a.id
+	e.Sequence = a.last
+	a.events[a.last%uint64(len(a.events))] = e
+
+	close(a.notify)
+	a.notify = make(chan struct{})
+}
+
+// Read returns up to max buffered events following the event with sequence
+// after, and the amount of events following after that were already dropped.
+// Events must not be modified.
+func (a *AuditLog) Read(after uint64, max int) (events []*dragonv1.AuditEvent, dropped uint64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if after >= a.last {
+		return nil, 0
+	}
+	size := uint64(len(a.events))
+	if a.last > size && after < a.last-size {
+		dropped = a.last - size - after
+		after = a.last - size
+	}
+	for seq := after + 1; seq <= a.last && len(events) < max; seq++ {
+		events = append(events, a.events[seq%size])
+	}
+	return events, dropped
+}
+
+// wait returns a channel that is closed as soon as an event following after
+// is recorded.
+func (a *AuditLog) wait(after uint64) <-chan struct{} {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if after < a.last {
+		closed := make(chan struct{})
+		close(closed)
+		return closed
+	}
+	return a.notify
+}
+
+// auditInterceptor records an AuditEvent of every method call in
+// config.AuditLog. It must run directly after errorInterceptor, so it sees
+// the calls rejected by all other interceptors. The keyRing is read after the
+// call, so its fingerprint includes the tenant added by tenantInterceptor.
+func auditInterceptor(config *Config) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if config.AuditLog == nil {
+				return next(ctx, req)
+			}
+
+			resp, err := next(ctx, req)
+
+			e := &dragonv1.AuditEvent{
+				Time:   time.Now().UnixNano() / int64(time.Millisecond),
+				Result: "ok",
+			}
+			e.Method, _ = twirp.MethodName(ctx)
+			e.Caller, _ = CallerFromContext(ctx)
+			e.CorrelationId, _ = CorrelationIDFromContext(ctx)
+			if r, ok := req.(interface{ GetKeyRing() string }); ok && r.GetKeyRing() != "" {
+				e.KeyRingFingerprint = dvx.KeyRingFingerprint(r.GetKeyRing())
+			}
+			var twerr twirp.Error
+			if errors.As(err, &twerr) {
+				e.Result = string(twerr.Code())
+				e.Reason = twerr.Meta("reason")
+			} else if err != nil {
+				e.Result = string(twirp.Internal)
+			}
+			config.AuditLog.record(e)
+
+			return resp, err
+		}
+	}
+}
+
+// auditStreamBatchSize is the maximum amount of events the audit stream
+// writes before it flushes them.
+const auditStreamBatchSize = 256
+
+// serveAuditStream serves the audit event stream (ExportAuditEvents) of the
+// gateway. It is a GET request with the query parameters "after" and "log_id"
+// of ExportAuditEventsRequest, which is authorized like the admin methods.
+// The response is newline delimited JSON (application/x-ndjson) with an
+// AuditEvent per line. It contains all buffered events following after and
+// stays open to deliver new events as soon as they are recorded, until the
+// client disconnects.
+//
+// Events are written as fast as the client reads them, so slow clients fall
+// behind instead of blocking the service (see AuditLog). To resume without
+// losing events, clients reconnect with the sequence and log_id of the last
+// event they processed.
+func (g *gateway) serveAuditStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		g.writeError(r.Context(), w, twirp.NewErrorf(twirp.BadRoute, "dragon: %s must be called with GET", r.URL.Path))
+		return
+	}
+
+	ctx := withTLSCaller(r).Context()
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "ExportAuditEvents")
+
+	req := &dragonv1.ExportAuditEventsRequest{LogId: r.URL.Query().Get("log_id")}
+	if after := r.URL.Query().Get("after"); after != "" {
+		var err error
+		if req.After, err = strconv.ParseUint(after, 10, 64); err != nil {
+			g.writeError(ctx, w, twirp.InvalidArgumentError("after", "must be an unsigned integer"))
+			return
+		}
+	}
+
+	_, err := g.intercept(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if err := g.svc.adminEnabled(); err != nil {
+			return nil, err
+		}
+		if g.svc.config.AuditLog == nil {
+			return nil, twirp.NewError(twirp.Unimplemented, "dragon: audit events are disabled")
+		}
+		return req, nil
+	})(ctx, req)
+	if err != nil {
+		g.writeError(ctx, w, err)
+		return
+	}
+
+	audit := g.svc.config.AuditLog
+	after := req.After
+	if req.LogId != audit.LogID() {
+		after = 0
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+
+	marshal := protojson.MarshalOptions{UseProtoNames: true}
+	for {
+		events, _ := audit.Read(after, auditStreamBatchSize)
+		if len(events) == 0 {
+			select {
+			case <-audit.wait(after):
+				continue
+			case <-r.Context().Done():
+				return
+			}
+		}
+
+		for _, e := range events {
+			buf, err := marshal.Marshal(e)
+			if err != nil {
+				g.abortStream(ctx, err)
+			}
+			if _, err = w.Write(append(buf, '\n')); err != nil {
+				// the client disconnected
+				return
+			}
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		after = events[len(events)-1].Sequence
+	}
+}
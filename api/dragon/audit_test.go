@@ -0,0 +1,202 @@
+package dragon
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/liblog"
+)
+
+func TestAuditLog(t *testing.T) {
+	a := NewAuditLog(3)
+	events, dropped := a.Read(0, 10)
+	assert.Empty(t, events)
+	assert.Zero(t, dropped)
+
+	for i := 0; i < 5; i++ {
+		a.record(&dragonv1.AuditEvent{Method: "Encrypt"})
+	}
+	assert.Equal(t, uint64(5), a.Last())
+
+	// the first two events were overwritten
+	events, dropped = a.Read(0, 10)
+	assert.Equal(t, uint64(2), dropped)
+	require.Len(t, events, 3)
+	assert.Equal(t, uint64(3), events[0].Sequence)
+	assert.Equal(t, a.LogID(), events[0].LogId)
+
+	events, dropped = a.Read(3, 1)
+	assert.Zero(t, dropped)
+	require.Len(t, events, 1)
+	assert.Equal(t, uint64(4), events[0].Sequence)
+
+	select {
+	case <-a.wait(4):
+	default:
+		t.Fatal("wait must not block while events follow")
+	}
+	wait := a.wait(5)
+	a.record(&dragonv1.AuditEvent{})
+	select {
+	case <-wait:
+	case <-time.After(time.Second):
+		t.Fatal("wait must return after a new event")
+	}
+}
+
+func newAuditProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, liblog.Wrap(logger.MustNewStd()))})
+}
+
+func TestAuditInterceptor(t *testing.T) {
+	audit := NewAuditLog(16)
+	srv := httptest.NewServer(NewHandler(newAuditProtocol(t), &Config{
+		AuditLog: audit,
+		Policies: map[string]*Policy{
+			"users": {KeyRingPrefixes: []string{"users/"}, Tenant: "acme"},
+		},
+	}, logger.MustNewStd()))
+	t.Cleanup(srv.Close)
+
+	c := dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+	_, err := c.Encrypt(context.Background(), &dragonv1.EncryptRequest{KeyRing: "users/42", Data: []byte("data")})
+	require.Error(t, err) // the test server has no TLS, so the caller is unknown
+
+	events, _ := audit.Read(0, 10)
+	require.Len(t, events, 1)
+	assert.Equal(t, "Encrypt", events[0].Method)
+	assert.Equal(t, string(twirp.Unauthenticated), events[0].Result)
+	assert.Equal(t, dvx.KeyRingFingerprint("users/42"), events[0].KeyRingFingerprint)
+	assert.NotEmpty(t, events[0].CorrelationId)
+	assert.NotZero(t, events[0].Time)
+}
+
+func TestGateway_AuditStream(t *testing.T) {
+	audit := NewAuditLog(16)
+	srv := httptest.NewServer(NewGateway(newAuditProtocol(t), &Config{EnableAdmin: true, AuditLog: audit}, logger.MustNewStd()))
+	t.Cleanup(srv.Close)
+
+	var out struct{}
+	require.Equal(t, http.StatusOK, post(t, srv, "encrypt", `{"key_ring":"keyring","data":"ZGF0YQ=="}`, &out))
+	require.Equal(t, http.StatusBadRequest, post(t, srv, "decrypt", `{"key_ring":"keyring","ciphertext":"dv2.enc.AA"}`, &out))
+
+	resp, err := http.Get(srv.URL + GatewayPathPrefix + StreamAuditPath)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	lines := bufio.NewScanner(resp.Body)
+	next := func() *dragonv1.AuditEvent {
+		require.True(t, lines.Scan())
+		e := &dragonv1.AuditEvent{}
+		require.NoError(t, protojson.Unmarshal(lines.Bytes(), e))
+		return e
+	}
+
+	e := next()
+	assert.Equal(t, uint64(1), e.Sequence)
+	assert.Equal(t, "Encrypt", e.Method)
+	assert.Equal(t, "ok", e.Result)
+	assert.Equal(t, dvx.KeyRingFingerprint("keyring"), e.KeyRingFingerprint)
+	e = next()
+	assert.Equal(t, "Decrypt", e.Method)
+	assert.Equal(t, string(twirp.InvalidArgument), e.Result)
+	assert.Equal(t, ReasonInvalidFormat, e.Reason)
+	// the stream audits itself
+	e = next()
+	assert.Equal(t, "ExportAuditEvents", e.Method)
+
+	// new events are delivered while the stream is open
+	require.Equal(t, http.StatusOK, post(t, srv, "mac", `{"key_ring":"keyring","message":"ZGF0YQ=="}`, &out))
+	e = next()
+	assert.Equal(t, uint64(4), e.Sequence)
+	assert.Equal(t, "MAC", e.Method)
+
+	// streams resume after the last processed event of the same log
+	resp2, err := http.Get(srv.URL + GatewayPathPrefix + StreamAuditPath + "?after=3&log_id=" + audit.LogID())
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+	lines = bufio.NewScanner(resp2.Body)
+	assert.Equal(t, uint64(4), next().Sequence)
+
+	// without admin methods the stream isn't served
+	srv2 := httptest.NewServer(NewGateway(newAuditProtocol(t), &Config{AuditLog: audit}, logger.MustNewStd()))
+	t.Cleanup(srv2.Close)
+	resp3, err := http.Get(srv2.URL + GatewayPathPrefix + StreamAuditPath)
+	require.NoError(t, err)
+	defer resp3.Body.Close()
+	assert.Equal(t, http.StatusNotImplemented, resp3.StatusCode)
+}
+
+func TestAuditPusher(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		received []string
+	)
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/x-ndjson", r.Header.Get("Content-Type"))
+		assert.Equal(t, "secret", r.Header.Get("Authorization"))
+		body, _ := io.ReadAll(r.Body)
+		received = append(received, strings.Split(strings.TrimSpace(string(body)), "\n")...)
+	}))
+	t.Cleanup(webhook.Close)
+
+	audit := NewAuditLog(16)
+	for i := 0; i < 3; i++ {
+		audit.record(&dragonv1.AuditEvent{Method: "Encrypt"})
+	}
+
+	p, err := NewAuditPusher(audit, &AuditWebhook{
+		URL:        webhook.URL,
+		Header:     http.Header{"Authorization": []string{"secret"}},
+		BatchSize:  2,
+		MinBackoff: time.Millisecond,
+	}, logger.MustNewStd())
+	require.NoError(t, err)
+	defer p.Close()
+
+	// the failed first request is retried
+	require.Eventually(t, func() bool { return p.Acknowledged() == 3 }, 5*time.Second, time.Millisecond)
+	audit.record(&dragonv1.AuditEvent{Method: "MAC"})
+	require.Eventually(t, func() bool { return p.Acknowledged() == 4 }, 5*time.Second, time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 4, requests)
+	require.Len(t, received, 4)
+	e := &dragonv1.AuditEvent{}
+	require.NoError(t, protojson.Unmarshal([]byte(received[3]), e))
+	assert.Equal(t, "MAC", e.Method)
+	assert.Equal(t, uint64(4), e.Sequence)
+
+	_, err = NewAuditPusher(audit, &AuditWebhook{}, logger.MustNewStd())
+	assert.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package dragon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+// AuditWebhook configures the delivery of audit events by an AuditPusher.
+type AuditWebhook struct {
+	// URL receives the events as POST requests with newline delimited JSON
+	// (application/x-ndjson) bodies, an AuditEvent per line. For example:
+	// "https://siem.example.com/ingest/dragon"
+	URL string
+	// Header is added to every request, e.g. to authenticate the pusher.
+	// For example:
+	//   http.Header{"Authorization": []string{"Bearer …"}}
+	Header http.Header
+	// Client sends the requests. Defaults to a http.Client with a timeout of
+	// 10 seconds.
+	Client *http.Client
+	// BatchSize is the maximum amount of events per request. Defaults to 100.
+	BatchSize int
+	// MinBackoff and MaxBackoff bound the time between retries of a failed
+	// request, which doubles after every failure. Default to 1 second and 1
+	// minute.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+func (c *AuditWebhook) client() *http.Client {
+	if c.Client == nil {
+		return &http.Client{Timeout: 10 * time.Second}
+	}
+	return c.Client
+}
+
+func (c *AuditWebhook) batchSize() int {
+	if c.BatchSize <= 0 {
+		return 100
+	}
+	return c.BatchSize
+}
+
+func (c *AuditWebhook) minBackoff() time.Duration {
+	if c.MinBackoff <= 0 {
+		return time.Second
+	}
+	return c.MinBackoff
+}
+
+func (c *AuditWebhook) maxBackoff() time.Duration {
+	if c.MaxBackoff <= 0 {
+		return time.Minute
+	}
+	return c.MaxBackoff
+}
+
+// AuditPusher pushes the events of an AuditLog to an AuditWebhook. It sends
+// one request at a time and only continues with the next events after the
+// webhook acknowledged the previous ones with a 2xx status code. Failed
+// requests are retried with backoff, so every event is delivered at least
+// once, unless the webhook is unavailable for so long that the AuditLog drops
+// events. Dropped events are logged.
+type AuditPusher struct {
+	// after is the sequence of the last acknowledged event. It is first in
+	// the struct, so it is 64-bit aligned for atomic access.
+	after  uint64
+	audit  *AuditLog
+	config *AuditWebhook
+	client *http.Client
+	log    logger.Logger
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewAuditPusher starts to push all events of audit, starting with the
+// oldest buffered event, to the webhook of config. Close stops it. If log is
+// nil nothing is logged.
+func NewAuditPusher(audit *AuditLog, config *AuditWebhook, log logger.Logger) (*AuditPusher, error) {
+	if audit == nil {
+		return nil, errors.New("dragon: AuditLog must not be nil")
+	}
+	if config == nil || config.URL == "" {
+		return nil, errors.New("dragon: AuditWebhook.URL must be set")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &AuditPusher{
+		audit:  audit,
+		config: config,
+		client: config.client(),
+		log:    named(log, "audit_pusher"),
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go p.run(ctx)
+	return p, nil
+}
+
+// Acknowledged returns the sequence of the last event acknowledged by the
+// webhook.
+func (p *AuditPusher) Acknowledged() uint64 {
+	return atomic.LoadUint64(&p.after)
+}
+
+// Close stops p. A running request is canceled, its events are lost unless
+// the webhook already received them.
+func (p *AuditPusher) Close() error {
+	p.cancel()
+	<-p.done
+	return nil
+}
+
+func (p *AuditPusher) run(ctx context.Context) {
+	defer close(p.done)
+
+	backoff := p.config.minBackoff()
+	for {
+		after := atomic.LoadUint64(&p.after)
+		events, dropped := p.audit.Read(after, p.config.batchSize())
+		if dropped > 0 {
+			p.log.Warn("audit events dropped before they were pushed",
+				logger.NewField("dropped", dropped))
+			// don't report the same events again while the webhook fails
+			atomic.StoreUint64(&p.after, events[0].Sequence-1)
+		}
+		if len(events) == 0 {
+			select {
+			case <-p.audit.wait(after):
+				continue
+			case <-ctx.Done():
+				return
+			}
+		}
+
+		if err := p.push(ctx, events); err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			p.log.Warn("unable to push audit events",
+				logger.NewField("events", len(events)),
+				logger.NewField("retry_in", backoff.String()),
+				logger.NewField("error", err))
+
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return
+			}
+			if backoff *= 2; backoff > p.config.maxBackoff() {
+				backoff = p.config.maxBackoff()
+			}
+			continue
+		}
+
+		backoff = p.config.minBackoff()
+		atomic.StoreUint64(&p.after, events[len(events)-1].Sequence)
+	}
+}
+
+func (p *AuditPusher) push(ctx context.Context, events []*dragonv1.AuditEvent) error {
+	var body bytes.Buffer
+	marshal := protojson.MarshalOptions{UseProtoNames: true}
+	for _, e := range events {
+		buf, err := marshal.Marshal(e)
+		if err != nil {
+			return err
+		}
+		body.Write(buf)
+		body.WriteByte('\n')
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL, &body)
+	if err != nil {
+		return err
+	}
+	for name, values := range p.config.Header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("dragon: webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
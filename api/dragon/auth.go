@@ -0,0 +1,251 @@
+package dragon
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"net/http"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+// Policy restricts which keyRings and methods a caller may use.
+type Policy struct {
+	// KeyRingPrefixes lists the prefixes of all keyRings the caller may use.
+	// A keyRing is allowed if it starts with at least one of the prefixes. An
+	// empty prefix allows all keyRings. The prefixes are matched against the
+	// bytes the Protocol derives keys from, so "label:base64" keyRings must
+	// decode to an allowed keyRing. For example:
+	//   []string{"users/", "sessions/"}
+	KeyRingPrefixes []string `json:"key_ring_prefixes"`
+	// Methods lists the RPC method names, as defined in the DragonAPI proto
+	// service, the caller may use. If empty all methods are allowed. For
+	// example:
+	//   []string{"Encrypt", "Decrypt"}
+	Methods []string `json:"methods"`
+	// Tenant namespaces all keyRings of the caller. If set, every keyRing is
+	// prefixed with Tenant before it reaches the Protocol, so callers of
+	// different tenants derive different keys even when they use the same
+	// keyRing strings. KeyRingPrefixes are matched against the keyRing of the
+	// caller before it's namespaced, after decoding "label:base64" keyRings
+	// like the Protocol. For example: "acme"
+	Tenant string `json:"tenant"`
+	// Admin allows the admin methods (see Config.EnableAdmin). They are
+	// never allowed without Admin, even if Methods is empty or lists them.
+	Admin bool `json:"admin"`
+	// DeriveKeys allows DeriveKey (see Config.KeyPool), which returns the
+	// derived keys themselves. The keyRing of the KeyPool input (without the
+	// label of the dvx version, kdf and purpose) must match KeyRingPrefixes,
+	// which are compared to its raw bytes, as "label:base64" keyRings reach
+	// the KeyPool decoded. It's never allowed together with Tenant.
+	DeriveKeys bool `json:"derive_keys"`
+}
+
+func (p *Policy) allowsMethod(method string) bool {
+	if adminMethods[method] && !p.Admin {
+		return false
+	}
+	if method == "DeriveKey" && (!p.DeriveKeys || p.Tenant != "") {
+		return false
+	}
+	if len(p.Methods) == 0 {
+		return true
+	}
+	for _, m := range p.Methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// allowsKeyRing reports whether the keyRing bytes (see keyRingToBytes) start
+// with one of the KeyRingPrefixes.
+func (p *Policy) allowsKeyRing(keyRing []byte) bool {
+	for _, prefix := range p.KeyRingPrefixes {
+		if bytes.HasPrefix(keyRing, []byte(prefix)) {
+			return true
+		}
+	}
+	return false
+}
+
+// deriveKeyRing returns the keyRing of the KeyPool input of a DeriveKey
+// request. dvx passes DV1 keyRings unchanged, but prefixes those of newer
+// versions with a "version/kdf/purpose" label terminated by a zero byte.
+func deriveKeyRing(input []byte) []byte {
+	idx := bytes.IndexByte(input, 0)
+	if idx == -1 || bytes.Count(input[:idx], []byte("/")) != 2 {
+		return input
+	}
+	return input[idx+1:]
+}
+
+type callerKey struct{}
+
+// WithCaller returns a copy of ctx that carries the caller identity used to
+// look up the caller's Policy.
+func WithCaller(ctx context.Context, caller string) context.Context {
+	return context.WithValue(ctx, callerKey{}, caller)
+}
+
+// CallerFromContext returns the caller identity stored in ctx by WithCaller.
+func CallerFromContext(ctx context.Context) (caller string, ok bool) {
+	caller, ok = ctx.Value(callerKey{}).(string)
+	return caller, ok && caller != ""
+}
+
+// CallerFromTLS returns the identity of the verified client certificate of
+// state. If the certificate contains a SPIFFE ID (an URI SAN with scheme
+// "spiffe") it is used as identity, otherwise the subject's common name.
+func CallerFromTLS(state *tls.ConnectionState) (caller string, ok bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return "", false
+	}
+
+	cert := state.VerifiedChains[0][0]
+	for _, uri := range cert.URIs {
+		if uri.Scheme == "spiffe" {
+			return uri.String(), true
+		}
+	}
+	if cert.Subject.CommonName != "" {
+		return cert.Subject.CommonName, true
+	}
+	return "", false
+}
+
+// withTLSCaller stores the identity of r's client certificate (see
+// CallerFromTLS) in the request's context.
+func withTLSCaller(r *http.Request) *http.Request {
+	if caller, ok := CallerFromTLS(r.TLS); ok {
+		return r.WithContext(WithCaller(r.Context(), caller))
+	}
+	return r
+}
+
+// callerServer stores the caller identity, correlation id and idempotency key
+// of every request in its context before handing it to the Twirp server.
+type callerServer struct {
+	dragonv1.TwirpServer
+}
+
+func (s *callerServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.TwirpServer.ServeHTTP(w, withIdempotencyKey(withTLSCaller(withCorrelationID(w, r))))
+}
+
+// authInterceptor enforces config.Policies before a method reaches the
+// Protocol. Requests without caller identity fail with twirp.Unauthenticated,
+// requests outside of the caller's Policy with twirp.PermissionDenied.
+func authInterceptor(config *Config) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if config.Policies == nil {
+				return next(ctx, req)
+			}
+
+			caller, ok := CallerFromContext(ctx)
+			if !ok {
+				return nil, twirp.NewError(twirp.Unauthenticated, "dragon: missing caller identity")
+			}
+			policy, ok := config.Policies[caller]
+			if !ok || policy == nil {
+				return nil, twirp.NewErrorf(twirp.PermissionDenied, "dragon: no policy for caller %q", caller)
+			}
+
+			method, _ := twirp.MethodName(ctx)
+			if !policy.allowsMethod(method) {
+				return nil, twirp.NewErrorf(twirp.PermissionDenied, "dragon: caller %q may not use %s", caller, method)
+			}
+			if r, ok := req.(interface{ GetKeyRing() string }); ok && !policy.allowsKeyRing(keyRingToBytes(r.GetKeyRing())) {
+				return nil, twirp.NewErrorf(twirp.PermissionDenied, "dragon: caller %q may not use keyRing %q", caller, r.GetKeyRing())
+			}
+			if r, ok := req.(*dragonv1.DeriveKeyRequest); ok {
+				keyRing := deriveKeyRing(r.Input)
+				if bytes.HasPrefix(keyRing, []byte(tenantKeyRingMagic)) || !policy.allowsKeyRing(keyRing) {
+					return nil, twirp.NewErrorf(twirp.PermissionDenied, "dragon: caller %q may not derive keys of keyRing %q", caller, keyRing)
+				}
+			}
+
+			return next(ctx, req)
+		}
+	}
+}
+
+// tenantKeyRingMagic starts the keyRing bytes of every namespaced keyRing.
+// Keys of callers without tenant must never start with it, otherwise they
+// could derive the keys of a tenant.
+const tenantKeyRingMagic = "dragon-tenant\x00"
+
+// tenantInterceptor namespaces the keyRing of every request with the tenant
+// of the caller's Policy. It must run after authInterceptor.
+func tenantInterceptor(config *Config) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if config.Policies == nil {
+				return next(ctx, req)
+			}
+
+			msg, ok := req.(proto.Message)
+			if !ok {
+				return next(ctx, req)
+			}
+			field := msg.ProtoReflect().Descriptor().Fields().ByName("key_ring")
+			if field == nil {
+				return next(ctx, req)
+			}
+
+			caller, _ := CallerFromContext(ctx)
+			tenant := config.Policies[caller].Tenant
+			keyRing := keyRingToBytes(msg.ProtoReflect().Get(field).String())
+
+			if tenant == "" {
+				if bytes.HasPrefix(keyRing, []byte(tenantKeyRingMagic)) {
+					return nil, twirp.NewErrorf(twirp.PermissionDenied, "dragon: caller %q may not use tenant keyRings", caller)
+				}
+				return next(ctx, req)
+			}
+
+			msg.ProtoReflect().Set(field, protoreflect.ValueOfString(tenantKeyRing(tenant, keyRing)))
+			return next(ctx, req)
+		}
+	}
+}
+
+// tenantKeyRing returns the keyRing string of keyRing namespaced with tenant.
+// The tenant is length-prefixed, so no two (tenant, keyRing) pairs result in
+// the same keyRing.
+func tenantKeyRing(tenant string, keyRing []byte) string {
+	var length [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(length[:], uint64(len(tenant)))
+
+	buf := make([]byte, 0, len(tenantKeyRingMagic)+n+len(tenant)+len(keyRing))
+	buf = append(buf, tenantKeyRingMagic...)
+	buf = append(buf, length[:n]...)
+	buf = append(buf, tenant...)
+	buf = append(buf, keyRing...)
+	return "tenant:" + base64.RawStdEncoding.EncodeToString(buf)
+}
+
+// keyRingToBytes mirrors the conversion of keyRing strings in dvx.Protocol:
+// the base64 part of "label:base64" keyRings is decoded, all other keyRings
+// are used as is.
+func keyRingToBytes(keyRing string) []byte {
+	idx := strings.IndexRune(keyRing, ':')
+	if idx == -1 {
+		return []byte(keyRing)
+	}
+
+	buf, err := base64.RawStdEncoding.DecodeString(keyRing[idx+1:])
+	if err != nil {
+		return []byte(keyRing)
+	}
+	return buf
+}
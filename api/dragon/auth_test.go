@@ -0,0 +1,251 @@
+package dragon
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+func TestCallerFromTLS(t *testing.T) {
+	_, ok := CallerFromTLS(nil)
+	assert.False(t, ok)
+
+	spiffe, _ := url.Parse("spiffe://azoo.dev/ns/prod/sa/users")
+	cert := &x509.Certificate{
+		Subject: pkix.Name{CommonName: "users"},
+		URIs:    []*url.URL{{Scheme: "https", Host: "azoo.dev"}, spiffe},
+	}
+	caller, ok := CallerFromTLS(&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}})
+	require.True(t, ok)
+	assert.Equal(t, "spiffe://azoo.dev/ns/prod/sa/users", caller)
+
+	cert.URIs = nil
+	caller, ok = CallerFromTLS(&tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}})
+	require.True(t, ok)
+	assert.Equal(t, "users", caller)
+
+	// unverified peer certificates are ignored
+	_, ok = CallerFromTLS(&tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}})
+	assert.False(t, ok)
+}
+
+func TestAuthInterceptor(t *testing.T) {
+	m := authInterceptor(&Config{Policies: map[string]*Policy{
+		"users": {KeyRingPrefixes: []string{"users/"}, Methods: []string{"Encrypt"}},
+		"all":   {KeyRingPrefixes: []string{""}},
+		"admin": {KeyRingPrefixes: []string{""}, Admin: true},
+		"keys":  {KeyRingPrefixes: []string{""}, DeriveKeys: true},
+		"acme":  {KeyRingPrefixes: []string{""}, DeriveKeys: true, Tenant: "acme"},
+		"pool":  {KeyRingPrefixes: []string{"users/"}, DeriveKeys: true},
+	}})(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	})
+
+	call := func(caller, method, keyRing string) error {
+		ctx := ctxsetters.WithMethodName(context.Background(), method)
+		if caller != "" {
+			ctx = WithCaller(ctx, caller)
+		}
+		_, err := m(ctx, &dragonv1.EncryptRequest{KeyRing: keyRing})
+		return err
+	}
+	code := func(err error) twirp.ErrorCode {
+		require.Error(t, err)
+		return err.(twirp.Error).Code()
+	}
+
+	assert.NoError(t, call("users", "Encrypt", "users/1"))
+	assert.NoError(t, call("all", "Decrypt", "sessions/1"))
+	assert.Equal(t, twirp.Unauthenticated, code(call("", "Encrypt", "users/1")))
+	assert.Equal(t, twirp.PermissionDenied, code(call("unknown", "Encrypt", "users/1")))
+	assert.Equal(t, twirp.PermissionDenied, code(call("users", "Decrypt", "users/1")))
+	assert.Equal(t, twirp.PermissionDenied, code(call("users", "Encrypt", "sessions/1")))
+
+	// admin methods require Admin, even if Methods is empty
+	assert.Equal(t, twirp.PermissionDenied, code(call("all", "InvalidateKeyRing", "sessions/1")))
+	assert.NoError(t, call("admin", "InvalidateKeyRing", "sessions/1"))
+
+	// DeriveKey requires DeriveKeys and is never allowed for tenants
+	assert.Equal(t, twirp.PermissionDenied, code(call("all", "DeriveKey", "")))
+	assert.Equal(t, twirp.PermissionDenied, code(call("acme", "DeriveKey", "")))
+	assert.NoError(t, call("keys", "DeriveKey", ""))
+
+	// the keyRing of DeriveKey inputs must match KeyRingPrefixes, with or
+	// without the label of newer dvx versions
+	derive := func(caller, input string) error {
+		ctx := WithCaller(ctxsetters.WithMethodName(context.Background(), "DeriveKey"), caller)
+		_, err := m(ctx, &dragonv1.DeriveKeyRequest{Input: []byte(input), Size: 32})
+		return err
+	}
+	assert.NoError(t, derive("pool", "users/1"))
+	assert.NoError(t, derive("pool", "dv2/kdf32/encrypt\x00users/1"))
+	assert.NoError(t, derive("keys", "sessions/1"))
+	assert.Equal(t, twirp.PermissionDenied, code(derive("pool", "sessions/1")))
+	assert.Equal(t, twirp.PermissionDenied, code(derive("pool", "dv2/kdf64/mac\x00sessions/1")))
+	assert.Equal(t, twirp.PermissionDenied, code(derive("pool", "dv2/kdf32/encrypt\x00")))
+	// nor may they reach tenant keyRings
+	assert.Equal(t, twirp.PermissionDenied, code(derive("keys", "dv2/kdf32/encrypt\x00"+tenantKeyRingMagic+"\x04acme")))
+
+	// authorization is disabled without policies
+	_, err := authInterceptor(&Config{})(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	})(context.Background(), &dragonv1.EncryptRequest{})
+	assert.NoError(t, err)
+}
+
+func TestAuth_LabeledKeyRing(t *testing.T) {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	pool := dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, nil)
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool})
+
+	log := logger.MustNewStd()
+	config := &Config{
+		KeyPool: pool,
+		// without TLS the caller identity is set by an interceptor
+		Interceptors: []twirp.Interceptor{func(next twirp.Method) twirp.Method {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				return next(WithCaller(ctx, "users"), req)
+			}
+		}},
+		Policies: map[string]*Policy{
+			"users": {KeyRingPrefixes: []string{"users/"}, DeriveKeys: true},
+		},
+	}
+	h := NewHandler(p, config, log)
+	mux := http.NewServeMux()
+	mux.Handle(h.PathPrefix(), h)
+	mux.Handle(GatewayPathPrefix, NewGateway(p, config, log))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	api := dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+	ctx := context.Background()
+	code := func(err error) twirp.ErrorCode {
+		require.Error(t, err)
+		return err.(twirp.Error).Code()
+	}
+
+	// "label:base64" keyRings are matched by the keyRing they decode to
+	billing := "users/x:" + base64.RawStdEncoding.EncodeToString([]byte("billing/secret"))
+	users := "users/x:" + base64.RawStdEncoding.EncodeToString([]byte("users/1"))
+	ciphertext, err := p.Encrypt("billing/secret", []byte("data"))
+	require.NoError(t, err)
+
+	_, err = api.Decrypt(ctx, &dragonv1.DecryptRequest{KeyRing: billing, Ciphertext: ciphertext})
+	assert.Equal(t, twirp.PermissionDenied, code(err))
+	_, err = api.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: billing, Data: []byte("data")})
+	assert.Equal(t, twirp.PermissionDenied, code(err))
+	_, err = api.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: "label:" + base64.RawStdEncoding.EncodeToString([]byte("billing/secret")), Data: []byte("data")})
+	assert.Equal(t, twirp.PermissionDenied, code(err))
+	resp, err := api.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: users, Data: []byte("data")})
+	require.NoError(t, err)
+	data, err := p.Decrypt("users/1", resp.Ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	for _, path := range []string{StreamEncryptPath, StreamDecryptPath} {
+		r, err := http.Post(srv.URL+GatewayPathPrefix+path+"?key_ring="+url.QueryEscape(billing), "application/octet-stream", strings.NewReader("data"))
+		require.NoError(t, err)
+		r.Body.Close()
+		assert.Equal(t, http.StatusForbidden, r.StatusCode, path)
+	}
+	r, err := http.Post(srv.URL+GatewayPathPrefix+StreamEncryptPath+"?key_ring="+url.QueryEscape(users), "application/octet-stream", strings.NewReader("data"))
+	require.NoError(t, err)
+	r.Body.Close()
+	assert.Equal(t, http.StatusOK, r.StatusCode)
+
+	// DeriveKey inputs are the decoded keyRings
+	_, err = api.DeriveKey(ctx, &dragonv1.DeriveKeyRequest{Input: []byte("billing/secret"), Size: 32})
+	assert.Equal(t, twirp.PermissionDenied, code(err))
+	_, err = api.DeriveKey(ctx, &dragonv1.DeriveKeyRequest{Input: []byte("dv2/kdf32/encrypt\x00billing/secret"), Size: 32})
+	assert.Equal(t, twirp.PermissionDenied, code(err))
+	key, err := api.DeriveKey(ctx, &dragonv1.DeriveKeyRequest{Input: []byte("users/1"), Size: 32})
+	require.NoError(t, err)
+	expected, err := pool.KDF32(keyRingToBytes(users))
+	require.NoError(t, err)
+	assert.Equal(t, expected, key.Key)
+}
+
+func TestTenantInterceptor(t *testing.T) {
+	config := &Config{Policies: map[string]*Policy{
+		"acme-1": {KeyRingPrefixes: []string{""}, Tenant: "acme"},
+		"acme-2": {KeyRingPrefixes: []string{""}, Tenant: "acme"},
+		"other":  {KeyRingPrefixes: []string{""}, Tenant: "other"},
+		"none":   {KeyRingPrefixes: []string{""}},
+	}}
+	m := tenantInterceptor(config)(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req.(*dragonv1.EncryptRequest).KeyRing, nil
+	})
+
+	keyRing := func(caller, keyRing string) (string, error) {
+		resp, err := m(WithCaller(context.Background(), caller), &dragonv1.EncryptRequest{KeyRing: keyRing})
+		if err != nil {
+			return "", err
+		}
+		return resp.(string), nil
+	}
+
+	acme1, err := keyRing("acme-1", "users")
+	require.NoError(t, err)
+	acme2, err := keyRing("acme-2", "users")
+	require.NoError(t, err)
+	other, err := keyRing("other", "users")
+	require.NoError(t, err)
+	none, err := keyRing("none", "users")
+	require.NoError(t, err)
+
+	assert.Equal(t, acme1, acme2)
+	assert.NotEqual(t, acme1, other)
+	assert.Equal(t, "users", none)
+
+	// label:base64 keyRings are namespaced by their decoded bytes
+	labeled, err := keyRing("acme-1", "label:"+base64.RawStdEncoding.EncodeToString([]byte("users")))
+	require.NoError(t, err)
+	assert.Equal(t, acme1, labeled)
+
+	// callers without tenant can't reach tenant keyRings
+	_, err = keyRing("none", acme1)
+	require.Error(t, err)
+	assert.Equal(t, twirp.PermissionDenied, err.(twirp.Error).Code())
+
+	// within a tenant, prefixes apply to the decoded bytes of labeled keyRings
+	config.Policies["acme-users"] = &Policy{KeyRingPrefixes: []string{"users/"}, Tenant: "acme"}
+	chain := twirp.ChainInterceptors(authInterceptor(config), tenantInterceptor(config))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req.(*dragonv1.EncryptRequest).KeyRing, nil
+	})
+	call := func(keyRing string) (string, error) {
+		ctx := WithCaller(ctxsetters.WithMethodName(context.Background(), "Encrypt"), "acme-users")
+		resp, err := chain(ctx, &dragonv1.EncryptRequest{KeyRing: keyRing})
+		if err != nil {
+			return "", err
+		}
+		return resp.(string), nil
+	}
+	_, err = call("users/x:" + base64.RawStdEncoding.EncodeToString([]byte("billing/secret")))
+	require.Error(t, err)
+	assert.Equal(t, twirp.PermissionDenied, err.(twirp.Error).Code())
+	namespaced, err := call("label:" + base64.RawStdEncoding.EncodeToString([]byte("users/1")))
+	require.NoError(t, err)
+	expected, err := call("users/1")
+	require.NoError(t, err)
+	assert.Equal(t, expected, namespaced)
+}
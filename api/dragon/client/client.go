@@ -0,0 +1,255 @@
+// Package client provides a Go client for the dragon service. It wraps the
+// generated Twirp client with connection pooling, retries, default deadlines
+// and typed errors.
+//
+// Crypto is implemented by both the remote client (New) and an in-process
+// dvx.Protocol (NewLocal), so services can switch between a local Protocol and
+// a remote dragon service without changing their code.
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+// Crypto is the common interface of a remote dragon service and an in-process
+// dvx.Protocol. See azoo.dev/utils/dvx for the documentation of every method.
+type Crypto interface {
+	Encrypt(ctx context.Context, keyRing string, data []byte) (ciphertext string, err error)
+	// EncryptWithFooter is like Encrypt, but binds the ciphertext to the
+	// encryption context footer (see dvx.Protocol.EncryptWithFooter), which
+	// Decrypt authenticates.
+	EncryptWithFooter(ctx context.Context, keyRing string, data []byte, footer []byte) (ciphertext string, err error)
+	Decrypt(ctx context.Context, keyRing string, ciphertext string) (data []byte, err error)
+	// GenerateDataKey returns a random 256-bit data encryption key in
+	// plaintext and wrapped with the key of keyRing, for local (envelope)
+	// encryption of data. Only the wrapped key should be stored.
+	GenerateDataKey(ctx context.Context, keyRing string) (plaintext []byte, wrappedKey string, err error)
+	// DecryptDataKey unwraps a wrapped key of GenerateDataKey.
+	DecryptDataKey(ctx context.Context, keyRing string, wrappedKey string) (plaintext []byte, err error)
+	// EncryptStream encrypts everything read from src chunk by chunk into a
+	// dvxfile written to dst (see dvx.Protocol.NewFileWriter), so payloads of
+	// any size are never buffered as a whole.
+	EncryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error
+	// DecryptStream decrypts the dvxfile read from src chunk by chunk into
+	// dst. Every chunk is authenticated before it is written, but if an error
+	// is returned dst might have received the data of the chunks before it.
+	DecryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error
+	CreateSignKey(ctx context.Context, keyRing string) (publicKey []byte, err error)
+	Sign(ctx context.Context, keyRing string, message []byte) (signature string, rawSignature []byte, err error)
+	Verify(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error)
+	VerifyPK(ctx context.Context, publicKey []byte, message []byte, signature string) (valid bool, err error)
+	MAC(ctx context.Context, keyRing string, message []byte) (tag string, err error)
+	GenerateTOTP(ctx context.Context, keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error)
+	VerifyTOTP(ctx context.Context, keyRing string, id string, accountID string, code string) (valid bool, err error)
+}
+
+// Config provides all options for a remote dragon client. Only BaseURL is
+// required, all other fields fall back to their documented defaults.
+type Config struct {
+	// BaseURL is the address of the dragon service.
+	//   Example: "https://dragon.internal:8080"
+	BaseURL string
+	// HTTPClient is used for all requests. If nil a new http.Client with a
+	// pooled http.Transport (see MaxIdleConnsPerHost) is created.
+	HTTPClient *http.Client
+	// TLSConfig is used by the http.Transport created when HTTPClient is nil,
+	// e.g. to present a client certificate to a dragon service that requires
+	// mTLS. Ignored if HTTPClient is set.
+	TLSConfig *tls.Config
+	// MaxIdleConnsPerHost is the amount of idle (keep-alive) connections kept
+	// open to the dragon service. Ignored if HTTPClient is set. Defaults to 64.
+	MaxIdleConnsPerHost int
+	// Timeout is the deadline applied to every call whose context has no
+	// deadline yet. It applies to all attempts combined. Defaults to 5 seconds.
+	Timeout time.Duration
+	// MaxRetries is the amount of additional attempts after a call failed with
+	// a retryable error (see Error.Retryable: errors the dragon service marks
+	// as retryable, twirp.Unavailable or a transport error). GenerateTOTP
+	// is only retried with an idempotency key (see IdempotencyKeys), as
+	// every call creates a new TOTP id. Defaults to 2. Negative values
+	// disable retries.
+	MaxRetries int
+	// RetryBackoff is the wait time before the first retry. It doubles with
+	// every following attempt. Defaults to 50 milliseconds.
+	RetryBackoff time.Duration
+	// IdempotencyKeys sends a random idempotency key with every Encrypt,
+	// GenerateTOTP and GenerateDataKey call, which its retries reuse, so
+	// the dragon service returns the response of the first attempt instead
+	// of creating a second ciphertext, data key or TOTP id. The service must
+	// enable them (see dragon.Config.Idempotency), otherwise retries of
+	// GenerateTOTP create new TOTP ids.
+	IdempotencyKeys bool
+	// JSON uses the JSON encoding instead of Protobuf for requests.
+	JSON bool
+	// Interceptors are additional Twirp client interceptors. They run before
+	// the built-in deadline and retry interceptors, so they observe each call
+	// once, regardless of the amount of retries. EncryptStream and
+	// DecryptStream aren't Twirp calls and bypass all interceptors, Timeout
+	// and retries.
+	Interceptors []twirp.Interceptor
+}
+
+// New creates a Crypto client for the remote dragon service at
+// config.BaseURL.
+func New(config *Config) Crypto {
+	return newRemote(config)
+}
+
+// newRemote creates the remote client of config, with all defaults applied.
+func newRemote(config *Config) *remote {
+	c := *config
+	if c.MaxIdleConnsPerHost <= 0 {
+		c.MaxIdleConnsPerHost = 64
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = 2
+	} else if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 50 * time.Millisecond
+	}
+	if c.HTTPClient == nil {
+		t := http.DefaultTransport.(*http.Transport).Clone()
+		t.MaxIdleConns = c.MaxIdleConnsPerHost
+		t.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+		if c.TLSConfig != nil {
+			t.TLSClientConfig = c.TLSConfig
+		}
+		c.HTTPClient = &http.Client{Transport: t}
+	}
+
+	interceptors := append([]twirp.Interceptor(nil), c.Interceptors...)
+	if c.IdempotencyKeys {
+		interceptors = append(interceptors, idempotencyInterceptor())
+	}
+	interceptors = append(interceptors,
+		deadlineInterceptor(c.Timeout),
+		retryInterceptor(c.MaxRetries, c.RetryBackoff),
+	)
+	opts := []twirp.ClientOption{
+		twirp.WithClientInterceptors(interceptors...),
+	}
+
+	var api dragonv1.DragonAPI
+	if c.JSON {
+		api = dragonv1.NewDragonAPIJSONClient(c.BaseURL, c.HTTPClient, opts...)
+	} else {
+		api = dragonv1.NewDragonAPIProtobufClient(c.BaseURL, c.HTTPClient, opts...)
+	}
+
+	return &remote{api: api, baseURL: c.BaseURL, httpClient: c.HTTPClient}
+}
+
+type remote struct {
+	api        dragonv1.DragonAPI
+	baseURL    string
+	httpClient *http.Client
+}
+
+func (r *remote) Encrypt(ctx context.Context, keyRing string, data []byte) (ciphertext string, err error) {
+	resp, err := r.api.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: keyRing, Data: data})
+	if err != nil {
+		return "", mapError(err)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (r *remote) EncryptWithFooter(ctx context.Context, keyRing string, data []byte, footer []byte) (ciphertext string, err error) {
+	resp, err := r.api.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: keyRing, Data: data, Footer: footer})
+	if err != nil {
+		return "", mapError(err)
+	}
+	return resp.Ciphertext, nil
+}
+
+func (r *remote) Decrypt(ctx context.Context, keyRing string, ciphertext string) (data []byte, err error) {
+	resp, err := r.api.Decrypt(ctx, &dragonv1.DecryptRequest{KeyRing: keyRing, Ciphertext: ciphertext})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp.Data, nil
+}
+
+func (r *remote) GenerateDataKey(ctx context.Context, keyRing string) (plaintext []byte, wrappedKey string, err error) {
+	resp, err := r.api.GenerateDataKey(ctx, &dragonv1.GenerateDataKeyRequest{KeyRing: keyRing})
+	if err != nil {
+		return nil, "", mapError(err)
+	}
+	return resp.Plaintext, resp.WrappedKey, nil
+}
+
+func (r *remote) DecryptDataKey(ctx context.Context, keyRing string, wrappedKey string) (plaintext []byte, err error) {
+	resp, err := r.api.DecryptDataKey(ctx, &dragonv1.DecryptDataKeyRequest{KeyRing: keyRing, WrappedKey: wrappedKey})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp.Plaintext, nil
+}
+
+func (r *remote) CreateSignKey(ctx context.Context, keyRing string) (publicKey []byte, err error) {
+	resp, err := r.api.CreateKey(ctx, &dragonv1.CreateKeyRequest{KeyRing: keyRing, Type: dragonv1.CreateKeyRequest_TYPE_SIGNING})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	return resp.GetSigningKey().GetPublicKey(), nil
+}
+
+func (r *remote) Sign(ctx context.Context, keyRing string, message []byte) (signature string, rawSignature []byte, err error) {
+	resp, err := r.api.Sign(ctx, &dragonv1.SignRequest{KeyRing: keyRing, Message: message})
+	if err != nil {
+		return "", nil, mapError(err)
+	}
+	return resp.Signature, resp.RawSignature, nil
+}
+
+func (r *remote) Verify(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error) {
+	resp, err := r.api.Verify(ctx, &dragonv1.VerifyRequest{KeyRing: keyRing, Message: message, Signature: signature})
+	if err != nil {
+		return false, mapError(err)
+	}
+	return resp.Valid, nil
+}
+
+func (r *remote) VerifyPK(ctx context.Context, publicKey []byte, message []byte, signature string) (valid bool, err error) {
+	resp, err := r.api.VerifyPK(ctx, &dragonv1.VerifyPKRequest{PublicKey: publicKey, Message: message, Signature: signature})
+	if err != nil {
+		return false, mapError(err)
+	}
+	return resp.Valid, nil
+}
+
+func (r *remote) MAC(ctx context.Context, keyRing string, message []byte) (tag string, err error) {
+	resp, err := r.api.MAC(ctx, &dragonv1.MACRequest{KeyRing: keyRing, Message: message})
+	if err != nil {
+		return "", mapError(err)
+	}
+	return resp.Tag, nil
+}
+
+func (r *remote) GenerateTOTP(ctx context.Context, keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error) {
+	resp, err := r.api.GenerateTOTP(ctx, &dragonv1.GenerateTOTPRequest{KeyRing: keyRing, Issuer: issuer, AccountName: accountName, AccountId: accountID})
+	if err != nil {
+		return "", "", mapError(err)
+	}
+	return resp.Id, resp.Uri, nil
+}
+
+func (r *remote) VerifyTOTP(ctx context.Context, keyRing string, id string, accountID string, code string) (valid bool, err error) {
+	resp, err := r.api.VerifyTOTP(ctx, &dragonv1.VerifyTOTPRequest{KeyRing: keyRing, Id: id, AccountId: accountID, Code: code})
+	if err != nil {
+		return false, mapError(err)
+	}
+	return resp.Valid, nil
+}
@@ -0,0 +1,178 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/api/dragon"
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/liblog"
+)
+
+func newProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, liblog.Wrap(logger.MustNewStd()))})
+}
+
+func TestRemoteAndLocal(t *testing.T) {
+	p := newProtocol(t)
+
+	h := dragon.NewHandler(p, nil, logger.MustNewStd())
+	mux := http.NewServeMux()
+	mux.Handle(h.PathPrefix(), h)
+	mux.Handle(dragon.GatewayPathPrefix, dragon.NewGateway(p, nil, logger.MustNewStd()))
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx := context.Background()
+	for name, c := range map[string]Crypto{
+		"remote":      New(&Config{BaseURL: srv.URL}),
+		"remote-json": New(&Config{BaseURL: srv.URL, JSON: true}),
+		"local":       NewLocal(p),
+	} {
+		t.Run(name, func(t *testing.T) {
+			ciphertext, err := c.Encrypt(ctx, "keyring", []byte("data"))
+			require.NoError(t, err)
+
+			data, err := c.Decrypt(ctx, "keyring", ciphertext)
+			require.NoError(t, err)
+			assert.Equal(t, []byte("data"), data)
+
+			publicKey, err := c.CreateSignKey(ctx, "keyring")
+			require.NoError(t, err)
+
+			signature, _, err := c.Sign(ctx, "keyring", []byte("message"))
+			require.NoError(t, err)
+
+			valid, err := c.VerifyPK(ctx, publicKey, []byte("message"), signature)
+			require.NoError(t, err)
+			assert.True(t, valid)
+
+			plaintext, wrappedKey, err := c.GenerateDataKey(ctx, "keyring")
+			require.NoError(t, err)
+			assert.Len(t, plaintext, 32)
+
+			// wrapped keys are interchangeable between local and remote
+			for _, other := range []Crypto{New(&Config{BaseURL: srv.URL}), NewLocal(p)} {
+				unwrapped, err := other.DecryptDataKey(ctx, "keyring", wrappedKey)
+				require.NoError(t, err)
+				assert.Equal(t, plaintext, unwrapped)
+			}
+
+			_, err = c.DecryptDataKey(ctx, "keyring", ciphertext)
+			assert.Error(t, err, "ciphertexts aren't wrapped keys")
+
+			payload := bytes.Repeat([]byte("payload"), 100000)
+			var encrypted, decrypted bytes.Buffer
+			require.NoError(t, c.EncryptStream(ctx, "keyring", &encrypted, bytes.NewReader(payload)))
+			for _, other := range []Crypto{New(&Config{BaseURL: srv.URL}), NewLocal(p)} {
+				decrypted.Reset()
+				require.NoError(t, other.DecryptStream(ctx, "keyring", &decrypted, bytes.NewReader(encrypted.Bytes())))
+				assert.Equal(t, payload, decrypted.Bytes())
+			}
+
+			err = c.DecryptStream(ctx, "other", &decrypted, bytes.NewReader(encrypted.Bytes()))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRemote_TypedErrors(t *testing.T) {
+	srv := httptest.NewServer(dragon.NewHandler(newProtocol(t), nil, logger.MustNewStd()))
+	defer srv.Close()
+
+	_, err := New(&Config{BaseURL: srv.URL}).Encrypt(context.Background(), "", []byte("data"))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInvalidArgument))
+
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, dragonv1.ErrorCategory_ERROR_CATEGORY_INVALID_REQUEST, e.Category())
+	assert.False(t, e.Retryable())
+	assert.NotEmpty(t, e.CorrelationID())
+}
+
+func TestRemote_Retry(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"code":"unavailable","msg":"try again"}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(&Config{BaseURL: srv.URL, MaxRetries: 3}).MAC(context.Background(), "keyring", []byte("message"))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrUnavailable))
+	assert.Equal(t, int32(4), atomic.LoadInt32(&calls))
+
+	var e *Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, dragonv1.ErrorCategory_ERROR_CATEGORY_UNAVAILABLE, e.Category(), "derived from the code")
+}
+
+func TestRemote_RetryHint(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"code":"unavailable","msg":"locked","meta":{"retryable":"false"}}`))
+	}))
+	defer srv.Close()
+
+	_, err := New(&Config{BaseURL: srv.URL, MaxRetries: 3}).MAC(context.Background(), "keyring", []byte("message"))
+	require.Error(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls), "the hint of the service takes precedence")
+}
+
+func TestRemote_IdempotencyKeys(t *testing.T) {
+	var calls int32
+	var keys []string
+	h := dragon.NewHandler(newProtocol(t), &dragon.Config{
+		Idempotency: &dragon.Idempotency{Store: dragon.NewMemoryIdempotencyStore()},
+	}, logger.MustNewStd())
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		// the response of the first attempt is lost
+		if atomic.AddInt32(&calls, 1) == 1 {
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"code":"unavailable","msg":"try again"}`))
+			return
+		}
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	c := New(&Config{BaseURL: srv.URL, IdempotencyKeys: true})
+	id, _, err := c.GenerateTOTP(context.Background(), "keyring", "azoo", "alice", "42")
+	require.NoError(t, err)
+	assert.NotEmpty(t, id)
+	require.Len(t, keys, 2, "GenerateTOTP is retried with idempotency key")
+	assert.NotEmpty(t, keys[0])
+	assert.Equal(t, keys[0], keys[1])
+
+	_, err = c.Encrypt(WithIdempotencyKey(context.Background(), "custom"), "keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, "custom", keys[2])
+	_, err = c.MAC(context.Background(), "keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.Empty(t, keys[3])
+}
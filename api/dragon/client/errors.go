@@ -0,0 +1,127 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+var (
+	// ErrInvalidArgument is returned if the dragon service rejected the
+	// arguments of a call.
+	ErrInvalidArgument = errors.New("dragon/client: invalid argument")
+	// ErrDeadlineExceeded is returned if the call didn't finish before its
+	// deadline.
+	ErrDeadlineExceeded = errors.New("dragon/client: deadline exceeded")
+	// ErrUnauthenticated is returned if the dragon service couldn't
+	// authenticate the caller.
+	ErrUnauthenticated = errors.New("dragon/client: unauthenticated")
+	// ErrPermissionDenied is returned if the caller isn't allowed to perform
+	// the call.
+	ErrPermissionDenied = errors.New("dragon/client: permission denied")
+	// ErrUnavailable is returned if the dragon service couldn't be reached
+	// (after all retries).
+	ErrUnavailable = errors.New("dragon/client: unavailable")
+	// ErrInternal is returned for all other failures.
+	ErrInternal = errors.New("dragon/client: internal error")
+)
+
+// Error is the error type returned by the remote Crypto client. It wraps one
+// of the sentinel errors of this package, which can be checked with
+// errors.Is, and the original twirp.Error.
+type Error struct {
+	kind  error
+	twerr twirp.Error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%v: %s", e.kind, e.twerr.Msg())
+}
+
+// Is reports whether target is the sentinel error e wraps.
+func (e *Error) Is(target error) bool {
+	return e.kind == target
+}
+
+// Unwrap returns the original twirp.Error.
+func (e *Error) Unwrap() error {
+	return e.twerr
+}
+
+// Code returns the twirp.ErrorCode returned by the dragon service.
+func (e *Error) Code() twirp.ErrorCode {
+	return e.twerr.Code()
+}
+
+// Reason returns the machine-readable dvx error class of the error (e.g.
+// "invalid_format"), or "" if it wasn't caused by the dvx Protocol.
+func (e *Error) Reason() string {
+	return e.twerr.Meta("reason")
+}
+
+// Category returns the category of the error, which tells how to react to
+// it. If the dragon service didn't send one (e.g. for transport errors), it
+// is derived from the code.
+func (e *Error) Category() dragonv1.ErrorCategory {
+	if c, ok := dragonv1.ErrorCategory_value[e.twerr.Meta("category")]; ok {
+		return dragonv1.ErrorCategory(c)
+	}
+
+	switch e.kind {
+	case ErrInvalidArgument:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_INVALID_REQUEST
+	case ErrDeadlineExceeded:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_DEADLINE_EXCEEDED
+	case ErrUnauthenticated, ErrPermissionDenied:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_ACCESS_DENIED
+	case ErrUnavailable:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_UNAVAILABLE
+	default:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_INTERNAL
+	}
+}
+
+// Retryable reports whether the call may succeed if it is retried with
+// backoff. The client already retried such calls up to Config.MaxRetries
+// times before it returned the error.
+func (e *Error) Retryable() bool {
+	return isRetryable(e.twerr)
+}
+
+// CorrelationID returns the id of the call in the logs of the dragon
+// service, or "" if the service didn't send one.
+func (e *Error) CorrelationID() string {
+	return e.twerr.Meta("correlation_id")
+}
+
+// mapError converts err returned by the generated Twirp client into an *Error.
+func mapError(err error) error {
+	var twerr twirp.Error
+	if !errors.As(err, &twerr) {
+		twerr = twirp.InternalErrorWith(err)
+	}
+
+	var kind error
+	switch twerr.Code() {
+	case twirp.InvalidArgument, twirp.Malformed, twirp.OutOfRange, twirp.FailedPrecondition:
+		kind = ErrInvalidArgument
+	case twirp.DeadlineExceeded, twirp.Canceled:
+		kind = ErrDeadlineExceeded
+	case twirp.Unauthenticated:
+		kind = ErrUnauthenticated
+	case twirp.PermissionDenied:
+		kind = ErrPermissionDenied
+	case twirp.Unavailable:
+		kind = ErrUnavailable
+	default:
+		kind = ErrInternal
+		if isRetryable(twerr) {
+			kind = ErrUnavailable
+		}
+	}
+
+	return &Error{kind: kind, twerr: twerr}
+}
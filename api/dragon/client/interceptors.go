@@ -0,0 +1,142 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/twitchtv/twirp"
+)
+
+// deadlineInterceptor sets a deadline of timeout on every call whose context
+// doesn't have a deadline yet.
+func deadlineInterceptor(timeout time.Duration) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if _, ok := ctx.Deadline(); ok {
+				return next(ctx, req)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, req)
+		}
+	}
+}
+
+// idempotencyKeyHeader is the request header of the idempotency key (see
+// dragon.IdempotencyKeyHeader).
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// idempotentMethods are the methods the dragon service deduplicates by
+// idempotency key (see dragon.Config.Idempotency).
+var idempotentMethods = map[string]bool{
+	"Encrypt":         true,
+	"GenerateTOTP":    true,
+	"GenerateDataKey": true,
+}
+
+// idempotencyInterceptor sends a random idempotency key with every call of
+// the idempotentMethods, which all retries of the call reuse. Keys set by the
+// caller (see WithIdempotencyKey) are kept.
+func idempotencyInterceptor() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if method, _ := twirp.MethodName(ctx); !idempotentMethods[method] {
+				return next(ctx, req)
+			}
+
+			header, _ := twirp.HTTPRequestHeaders(ctx)
+			if header.Get(idempotencyKeyHeader) != "" {
+				return next(ctx, req)
+			}
+			var key [16]byte
+			if _, err := rand.Read(key[:]); err != nil {
+				return nil, err
+			}
+			return next(withHeader(ctx, header, idempotencyKeyHeader, hex.EncodeToString(key[:])), req)
+		}
+	}
+}
+
+// WithIdempotencyKey returns a copy of ctx that sends key as idempotency key
+// of the next Encrypt, GenerateTOTP or GenerateDataKey call, e.g. to
+// deduplicate retries of the caller itself. The dragon service must enable
+// idempotency keys (see dragon.Config.Idempotency).
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	header, _ := twirp.HTTPRequestHeaders(ctx)
+	return withHeader(ctx, header, idempotencyKeyHeader, key)
+}
+
+// withHeader returns a copy of ctx whose twirp request headers are header
+// with name set to value.
+func withHeader(ctx context.Context, header http.Header, name string, value string) context.Context {
+	if header == nil {
+		header = make(http.Header)
+	} else {
+		header = header.Clone()
+	}
+	header.Set(name, value)
+	ctx, _ = twirp.WithHTTPRequestHeaders(ctx, header)
+	return ctx
+}
+
+// retryInterceptor retries calls that failed with a retryable error up to
+// maxRetries times, with an exponential backoff starting at backoff.
+// GenerateTOTP is only retried with idempotency keys.
+func retryInterceptor(maxRetries int, backoff time.Duration) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			if method, _ := twirp.MethodName(ctx); method == "GenerateTOTP" {
+				if header, _ := twirp.HTTPRequestHeaders(ctx); header.Get(idempotencyKeyHeader) == "" {
+					return next(ctx, req)
+				}
+			}
+
+			wait := backoff
+			for attempt := 0; ; attempt++ {
+				resp, err := next(ctx, req)
+				if err == nil || attempt >= maxRetries || !isRetryable(err) {
+					return resp, err
+				}
+
+				t := time.NewTimer(wait)
+				select {
+				case <-ctx.Done():
+					t.Stop()
+					return nil, err
+				case <-t.C:
+				}
+				wait *= 2
+			}
+		}
+	}
+}
+
+// isRetryable reports whether err is a transient error, that might succeed on
+// a subsequent attempt. The retryable hint of the dragon service takes
+// precedence over the error code.
+func isRetryable(err error) bool {
+	var twerr twirp.Error
+	if errors.As(err, &twerr) {
+		switch twerr.Meta("retryable") {
+		case "true":
+			return true
+		case "false":
+			return false
+		}
+		if twerr.Code() == twirp.Unavailable {
+			return true
+		}
+	}
+
+	// transport errors (connection refused, reset, etc.) are returned by the
+	// http.Client as *url.Error and wrapped as twirp.Internal by the generated
+	// client
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !urlErr.Timeout()
+}
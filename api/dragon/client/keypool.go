@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"fmt"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/tearc"
+)
+
+// NewKeyPool creates a dvx.KeyPool that derives keys with the DeriveKey
+// method of the remote dragon service at config.BaseURL (see
+// azoo.dev/api/dragon.Config.KeyPool). A dvx.Protocol using it derives the
+// same keys as the service, so applications can encrypt and decrypt locally
+// without running a sidecar. The returned KeyPool implements
+// dvx.ContextKeyPool, so the deadlines of the Context methods of dvx.Protocol
+// reach the service.
+//
+// DeriveKey returns key material, so the service only serves it to callers
+// whose Policy sets DeriveKeys, for the keyRings of their KeyRingPrefixes.
+// config.TLSConfig must present the client certificate of such a caller,
+// other derivations fail with ErrPermissionDenied.
+//
+// Every derivation is a call to the service, so the KeyPool should be
+// wrapped in a cache. NewCachingKeyPool does so with tearc.
+func NewKeyPool(config *Config) dvx.KeyPool {
+	return &remoteKeyPool{r: newRemote(config)}
+}
+
+// NewCachingKeyPool creates a KeyPool with NewKeyPool and wraps it in a tearc
+// cache configured by cache, so the service (and its HSM) only derives keys
+// that aren't cached locally. For example:
+//
+//	client.NewCachingKeyPool(&client.Config{BaseURL: "https://dragon.internal:8080"}, &tearc.Config{
+//	  Size: 4096, Shards: 16, BucketMinTick: 5 * time.Second, BucketMaxTick: 20 * time.Second, AliveTime: time.Minute,
+//	}, nil)
+//
+// If log is nil nothing is logged.
+func NewCachingKeyPool(config *Config, cache *tearc.Config, log dvx.Logger) (dvx.KeyPool, error) {
+	var tlog tearc.Logger
+	if log != nil {
+		tlog = log
+	}
+	return tearc.New(cache, NewKeyPool(config), tlog)
+}
+
+type remoteKeyPool struct {
+	r *remote
+}
+
+func (k *remoteKeyPool) kdf(ctx context.Context, keyRing []byte, size int32) ([]byte, error) {
+	resp, err := k.r.api.DeriveKey(ctx, &dragonv1.DeriveKeyRequest{Input: keyRing, Size: size})
+	if err != nil {
+		return nil, mapError(err)
+	}
+	if len(resp.Key) != int(size) {
+		return nil, fmt.Errorf("dragon/client: service returned %d byte key instead of %d", len(resp.Key), size)
+	}
+	return resp.Key, nil
+}
+
+func (k *remoteKeyPool) KDF32(keyRing []byte) (key []byte, err error) {
+	return k.kdf(context.Background(), keyRing, 32)
+}
+
+func (k *remoteKeyPool) KDF32Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	return k.kdf(ctx, keyRing, 32)
+}
+
+func (k *remoteKeyPool) KDF64(keyRing []byte) (key []byte, err error) {
+	return k.kdf(context.Background(), keyRing, 64)
+}
+
+func (k *remoteKeyPool) KDF64Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	return k.kdf(ctx, keyRing, 64)
+}
+
+// Close closes the idle connections to the service.
+func (k *remoteKeyPool) Close() error {
+	k.r.httpClient.CloseIdleConnections()
+	return nil
+}
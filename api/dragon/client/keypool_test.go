@@ -0,0 +1,99 @@
+package client
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	"azoo.dev/api/dragon"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/tearc"
+)
+
+func TestCachingKeyPool(t *testing.T) {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	serverPool := dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, nil)
+	server := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: serverPool})
+
+	srv := httptest.NewServer(dragon.NewHandler(server, &dragon.Config{
+		KeyPool: serverPool,
+		// without TLS the caller identity is set by an interceptor
+		Interceptors: []twirp.Interceptor{func(next twirp.Method) twirp.Method {
+			return func(ctx context.Context, req interface{}) (interface{}, error) {
+				return next(dragon.WithCaller(ctx, "app"), req)
+			}
+		}},
+		Policies: map[string]*dragon.Policy{
+			"app": {KeyRingPrefixes: []string{"keyring"}, DeriveKeys: true},
+		},
+	}, logger.MustNewStd()))
+	defer srv.Close()
+
+	pool, err := NewCachingKeyPool(&Config{BaseURL: srv.URL}, &tearc.Config{
+		Size:          64,
+		Shards:        1,
+		BucketMinTick: time.Second,
+		BucketMaxTick: 10 * time.Second,
+		AliveTime:     time.Minute,
+	}, nil)
+	require.NoError(t, err)
+	defer pool.Close()
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool})
+
+	// keys derived through the service equal those of the service
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		ciphertext, err := p.EncryptContext(ctx, "keyring", []byte("data"))
+		require.NoError(t, err)
+		data, err := server.Decrypt("keyring", ciphertext)
+		require.NoError(t, err)
+		assert.Equal(t, []byte("data"), data)
+	}
+	tag, err := p.MAC("keyring", []byte("message"))
+	require.NoError(t, err)
+	serverTag, err := server.MAC("keyring", []byte("message"))
+	require.NoError(t, err)
+	assert.Equal(t, serverTag, tag)
+
+	stats := p.Stats()
+	assert.Equal(t, uint64(2), stats.KDFCacheHits)
+
+	// keyRings outside of the policy are refused
+	_, err = p.EncryptContext(ctx, "other", []byte("data"))
+	require.Error(t, err)
+}
+
+func TestKeyPool_WithoutPolicies(t *testing.T) {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	serverPool := dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, nil)
+	server := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: serverPool})
+
+	srv := httptest.NewServer(dragon.NewHandler(server, &dragon.Config{KeyPool: serverPool}, logger.MustNewStd()))
+	defer srv.Close()
+
+	_, err = NewKeyPool(&Config{BaseURL: srv.URL}).KDF32([]byte("keyring"))
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrPermissionDenied))
+}
+
+func TestKeyPool_Disabled(t *testing.T) {
+	srv := httptest.NewServer(dragon.NewHandler(newProtocol(t), nil, logger.MustNewStd()))
+	defer srv.Close()
+
+	_, err := NewKeyPool(&Config{BaseURL: srv.URL}).KDF32([]byte("keyring"))
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrUnavailable))
+}
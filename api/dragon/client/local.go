@@ -0,0 +1,113 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+
+	"azoo.dev/utils/dvx"
+)
+
+// dataKeyFooter mirrors the footer of wrapped data keys of the dragon service,
+// so keys wrapped locally and remotely are interchangeable.
+const dataKeyFooter = "dragon-data-key"
+
+// NewLocal wraps an in-process dvx.Protocol as Crypto. The passed contexts
+// are handed to the Context methods of dvx.Protocol, so they are checked
+// before every key derivation and reach ContextKeyPool implementations.
+func NewLocal(p *dvx.Protocol) Crypto {
+	return &local{p: p}
+}
+
+type local struct {
+	p *dvx.Protocol
+}
+
+func (l *local) Encrypt(ctx context.Context, keyRing string, data []byte) (ciphertext string, err error) {
+	return l.p.EncryptContext(ctx, keyRing, data)
+}
+
+func (l *local) EncryptWithFooter(ctx context.Context, keyRing string, data []byte, footer []byte) (ciphertext string, err error) {
+	return l.p.EncryptWithFooterContext(ctx, keyRing, data, footer)
+}
+
+func (l *local) Decrypt(ctx context.Context, keyRing string, ciphertext string) (data []byte, err error) {
+	return l.p.DecryptContext(ctx, keyRing, ciphertext)
+}
+
+func (l *local) GenerateDataKey(ctx context.Context, keyRing string) (plaintext []byte, wrappedKey string, err error) {
+	plaintext = make([]byte, 32)
+	if _, err = io.ReadFull(rand.Reader, plaintext); err != nil {
+		return nil, "", err
+	}
+
+	wrappedKey, err = l.p.EncryptWithFooterContext(ctx, keyRing, plaintext, []byte(dataKeyFooter))
+	if err != nil {
+		return nil, "", err
+	}
+	return plaintext, wrappedKey, nil
+}
+
+func (l *local) DecryptDataKey(ctx context.Context, keyRing string, wrappedKey string) (plaintext []byte, err error) {
+	_, typePrefix, _, footer, err := dvx.DecodeWithFooter(wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+	if typePrefix != dvx.Encrypted || !bytes.Equal(footer, []byte(dataKeyFooter)) {
+		return nil, fmt.Errorf("%w: wrappedKey isn't a wrapped data key", dvx.ErrInvalidFormat)
+	}
+	return l.p.DecryptContext(ctx, keyRing, wrappedKey)
+}
+
+func (l *local) CreateSignKey(ctx context.Context, keyRing string) (publicKey []byte, err error) {
+	return l.p.CreateSignKeyContext(ctx, keyRing)
+}
+
+func (l *local) Sign(ctx context.Context, keyRing string, message []byte) (signature string, rawSignature []byte, err error) {
+	return l.p.SignContext(ctx, keyRing, message)
+}
+
+func (l *local) Verify(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error) {
+	return l.p.VerifyContext(ctx, keyRing, message, signature)
+}
+
+func (l *local) VerifyPK(ctx context.Context, publicKey []byte, message []byte, signature string) (valid bool, err error) {
+	if err = ctx.Err(); err != nil {
+		return false, err
+	}
+	return l.p.VerifyPK(publicKey, message, signature)
+}
+
+func (l *local) MAC(ctx context.Context, keyRing string, message []byte) (tag string, err error) {
+	return l.p.MACContext(ctx, keyRing, message)
+}
+
+func (l *local) GenerateTOTP(ctx context.Context, keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error) {
+	return l.p.GenerateTOTPContext(ctx, keyRing, issuer, accountName, accountID)
+}
+
+func (l *local) VerifyTOTP(ctx context.Context, keyRing string, id string, accountID string, code string) (valid bool, err error) {
+	return l.p.VerifyTOTPContext(ctx, keyRing, id, accountID, code)
+}
+
+func (l *local) EncryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error {
+	fw, err := l.p.NewFileWriter(ctx, dst, keyRing)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(fw, src); err != nil {
+		return err
+	}
+	return fw.Close()
+}
+
+func (l *local) DecryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error {
+	fr, err := l.p.NewFileReader(ctx, src, keyRing)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, fr)
+	return err
+}
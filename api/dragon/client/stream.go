@@ -0,0 +1,93 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/twitchtv/twirp"
+)
+
+// Paths of the streaming methods of the dragon JSON gateway (see
+// azoo.dev/api/dragon.StreamEncryptPath).
+const (
+	streamEncryptPath = "/v1/stream/encrypt"
+	streamDecryptPath = "/v1/stream/decrypt"
+)
+
+func (r *remote) EncryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error {
+	return r.stream(ctx, streamEncryptPath, keyRing, dst, src)
+}
+
+func (r *remote) DecryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error {
+	return r.stream(ctx, streamDecryptPath, keyRing, dst, src)
+}
+
+// stream sends src as body of a streaming method of the gateway and copies the
+// response body to dst, while src is still being sent.
+func (r *remote) stream(ctx context.Context, path string, keyRing string, dst io.Writer, src io.Reader) error {
+	u := strings.TrimSuffix(r.baseURL, "/") + path + "?key_ring=" + url.QueryEscape(keyRing)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, src)
+	if err != nil {
+		return mapError(err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return mapError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return mapError(gatewayError(resp))
+	}
+
+	// the service aborts the response on errors, which results in an
+	// unexpected EOF instead of a complete body
+	if _, err = io.Copy(dst, resp.Body); err != nil {
+		return mapError(err)
+	}
+	return nil
+}
+
+// gatewayError decodes the JSON error envelope of the gateway into a
+// twirp.Error.
+func gatewayError(resp *http.Response) twirp.Error {
+	var e struct {
+		Error struct {
+			Code          string `json:"code"`
+			Message       string `json:"message"`
+			Reason        string `json:"reason"`
+			Argument      string `json:"argument"`
+			Category      string `json:"category"`
+			Retryable     *bool  `json:"retryable"`
+			CorrelationID string `json:"correlation_id"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || !twirp.IsValidErrorCode(twirp.ErrorCode(e.Error.Code)) {
+		return twirp.NewErrorf(twirp.Internal, "dragon/client: unexpected response status %d", resp.StatusCode)
+	}
+
+	twerr := twirp.NewError(twirp.ErrorCode(e.Error.Code), e.Error.Message)
+	if e.Error.Reason != "" {
+		twerr = twerr.WithMeta("reason", e.Error.Reason)
+	}
+	if e.Error.Argument != "" {
+		twerr = twerr.WithMeta("argument", e.Error.Argument)
+	}
+	if e.Error.Category != "" {
+		twerr = twerr.WithMeta("category", e.Error.Category)
+	}
+	if e.Error.Retryable != nil {
+		twerr = twerr.WithMeta("retryable", strconv.FormatBool(*e.Error.Retryable))
+	}
+	if e.Error.CorrelationID != "" {
+		twerr = twerr.WithMeta("correlation_id", e.Error.CorrelationID)
+	}
+	return twerr
+}
@@ -0,0 +1,248 @@
+// Command dragon runs the DragonAPI Twirp service around a dvx Protocol.
+//
+// The root KeyPool is either a WrapDVXAsKeyPool instance, whose 64 byte root
+// key is read base64 encoded from the environment variable DRAGON_ROOT_KEY, or
+// a PKCS#11 HSM (-hsm-module or -hsm-uri), whose user pin is read from the
+// environment variable DRAGON_HSM_PIN. In both cases the root KeyPool is
+// wrapped in a tearc caching KeyPool.
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/harwoeck/liblog/contract"
+
+	"azoo.dev/api/dragon"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/hsm"
+	"azoo.dev/utils/dvx/liblog"
+	"azoo.dev/utils/dvx/tearc"
+)
+
+const (
+	envRootKey = "DRAGON_ROOT_KEY"
+	envHSMPin  = "DRAGON_HSM_PIN"
+)
+
+var (
+	addr           = flag.String("addr", ":8080", "address the http server listens on")
+	tlsCert        = flag.String("tls-cert", "", "path to a PEM encoded TLS certificate. Serves plain http if empty")
+	tlsKey         = flag.String("tls-key", "", "path to the PEM encoded private key of -tls-cert")
+	tlsClientCA    = flag.String("tls-client-ca", "", "path to PEM encoded CA certificates. If set, clients must present a certificate signed by one of them (mTLS)")
+	policies       = flag.String("policies", "", "path to a JSON file mapping caller identities (SPIFFE ID or certificate common name) to policies. Requires -tls-client-ca")
+	defaultTimeout = flag.Duration("timeout", 5*time.Second, "default deadline for every method")
+	totpTimeout    = flag.Duration("timeout-totp", 2*time.Second, "deadline for GenerateTOTP, VerifyTOTP and BatchVerifyTOTP")
+	totpFailures   = flag.Int("totp-max-failures", 5, "failed TOTP verifications within -totp-lockout-window that lock an account. Counted in memory of this instance. 0 disables the lockout")
+	totpWindow     = flag.Duration("totp-lockout-window", 15*time.Minute, "time in which failed TOTP verifications are counted, starting with the first failure")
+	deriveKeys     = flag.Bool("derive-keys", false, "enable DeriveKey, which returns derived keys to remote KeyPools (client.NewCachingKeyPool). Requires -policies, only callers whose policy sets \"derive_keys\" may use it for their key_ring_prefixes")
+	admin          = flag.Bool("admin", false, "enable the admin methods (cache stats, keyRing invalidation, KeyPool health, root key generations, audit sink status). With -policies only callers whose policy sets \"admin\" may use them")
+	idempotencyTTL = flag.Duration("idempotency-ttl", 10*time.Minute, "time the responses of Encrypt, GenerateTOTP and GenerateDataKey requests with Idempotency-Key header are stored in memory of this instance, so retries receive the same response. 0 disables idempotency keys")
+	auditEvents    = flag.Int("audit-events", 0, "amount of audit events buffered for export by the gateway stream /v1/stream/audit (requires -admin) and -audit-webhook. 0 disables audit events")
+	auditWebhook   = flag.String("audit-webhook", "", "URL receiving the audit events as POST requests with newline delimited JSON. Requires -audit-events")
+	dualRun        = flag.String("dual-run", "", "dvx version (e.g. \"dv1\") with which a sample of Encrypt, Decrypt, Sign, Verify and MAC calls is repeated and compared. Results are served by GetDualRunStats (requires -admin). Empty disables the dual-run mode")
+	dualRunRate    = flag.Float64("dual-run-sample-rate", 0.01, "fraction of the calls repeated by -dual-run")
+	requireContext = flag.String("require-encryption-context", "", "comma separated keyRing prefixes (e.g. \"tenants/,payments/\") whose Encrypt calls must pass a footer as encryption context. Empty disables the requirement")
+
+	hsmModule   = flag.String("hsm-module", "", "path to the PKCS#11 module. Uses "+envRootKey+" instead of an HSM if empty")
+	hsmURI      = flag.String("hsm-uri", "", "PKCS#11 URI (RFC 7512) of the HSM root key, e.g. \"pkcs11:token=dvx;object=dvx_root;id=dvx_root?module-path=/usr/lib/softhsm/libsofthsm2.so\". Replaces -hsm-module, -hsm-label, -hsm-key-id and -hsm-key-label. The user pin is read from its pin-source or "+envHSMPin)
+	hsmLabel    = flag.String("hsm-label", "dvx", "label of the HSM token")
+	hsmKeyID    = flag.String("hsm-key-id", "dvx_root", "id of the HSM root key")
+	hsmKeyLabel = flag.String("hsm-key-label", "dvx_root", "label of the HSM root key")
+
+	cacheSize     = flag.Int("cache-size", 65536, "size of the tearc key cache")
+	cacheShards   = flag.Int("cache-shards", 64, "amount of shards of the tearc key cache")
+	cacheMinTick  = flag.Duration("cache-min-tick", 5*time.Second, "minimum time between tearc reaper runs")
+	cacheMaxTick  = flag.Duration("cache-max-tick", 20*time.Second, "maximum time between tearc reaper runs")
+	cacheAliveFor = flag.Duration("cache-alive-time", 1*time.Minute, "time cached keys stay alive after their last usage")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "dragon: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	log := contract.MustNewStd()
+
+	dvxLog := liblog.Wrap(log)
+	rootPool, err := newRootPool(dvxLog)
+	if err != nil {
+		return err
+	}
+
+	pool, err := tearc.New(&tearc.Config{
+		Size:          *cacheSize,
+		Shards:        *cacheShards,
+		BucketMinTick: *cacheMinTick,
+		BucketMaxTick: *cacheMaxTick,
+		AliveTime:     *cacheAliveFor,
+	}, rootPool, dvxLog)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = pool.Close()
+	}()
+
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{
+		dvx.Version: pool,
+	})
+	if *requireContext != "" {
+		p.RequireEncryptionContext(strings.Split(*requireContext, ",")...)
+	}
+
+	config := &dragon.Config{
+		DefaultTimeout: *defaultTimeout,
+		Timeouts: map[string]time.Duration{
+			"GenerateTOTP":    *totpTimeout,
+			"VerifyTOTP":      *totpTimeout,
+			"BatchVerifyTOTP": *totpTimeout,
+		},
+		EnableAdmin: *admin,
+	}
+	if *deriveKeys {
+		config.KeyPool = pool
+	}
+	if *totpFailures > 0 {
+		config.TOTPLockout = &dragon.Lockout{
+			Store:       dragon.NewMemoryAttemptStore(),
+			MaxFailures: *totpFailures,
+			Window:      *totpWindow,
+		}
+	}
+	if *idempotencyTTL > 0 {
+		config.Idempotency = &dragon.Idempotency{
+			Store: dragon.NewMemoryIdempotencyStore(),
+			TTL:   *idempotencyTTL,
+		}
+	}
+	if *auditEvents > 0 {
+		config.AuditLog = dragon.NewAuditLog(*auditEvents)
+	}
+	if *auditWebhook != "" {
+		if config.AuditLog == nil {
+			return fmt.Errorf("-audit-webhook requires -audit-events")
+		}
+		pusher, err := dragon.NewAuditPusher(config.AuditLog, &dragon.AuditWebhook{URL: *auditWebhook}, log)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			_ = pusher.Close()
+		}()
+	}
+	if *dualRun != "" {
+		if config.DualRun, err = dragon.NewDualRun(p, *dualRun, *dualRunRate, log); err != nil {
+			return err
+		}
+	}
+	if *policies != "" {
+		if *tlsClientCA == "" {
+			return fmt.Errorf("-policies requires -tls-client-ca")
+		}
+		if config.Policies, err = readPolicies(*policies); err != nil {
+			return err
+		}
+	}
+	if *deriveKeys && config.Policies == nil {
+		return fmt.Errorf("-derive-keys requires -policies")
+	}
+	h := dragon.NewHandler(p, config, log)
+
+	mux := http.NewServeMux()
+	mux.Handle(h.PathPrefix(), h)
+	mux.Handle(dragon.GatewayPathPrefix, dragon.NewGateway(p, config, log))
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	if *tlsClientCA != "" {
+		if *tlsCert == "" {
+			return fmt.Errorf("-tls-client-ca requires -tls-cert")
+		}
+		if srv.TLSConfig, err = clientAuthTLSConfig(*tlsClientCA); err != nil {
+			return err
+		}
+	}
+
+	log.Info("listening", contract.NewField("addr", *addr))
+	if *tlsCert != "" {
+		return srv.ListenAndServeTLS(*tlsCert, *tlsKey)
+	}
+	return srv.ListenAndServe()
+}
+
+func clientAuthTLSConfig(caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", caFile)
+	}
+
+	return &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  pool,
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+func readPolicies(file string) (map[string]*dragon.Policy, error) {
+	buf, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string]*dragon.Policy)
+	if err := json.Unmarshal(buf, &policies); err != nil {
+		return nil, fmt.Errorf("invalid policies file %s: %w", file, err)
+	}
+	return policies, nil
+}
+
+func newRootPool(log dvx.Logger) (dvx.KeyPool, error) {
+	if *hsmURI != "" {
+		config := &hsm.Config{URI: *hsmURI}
+		if !strings.Contains(*hsmURI, "pin-") {
+			config.UserPin = os.Getenv(envHSMPin)
+		}
+		return hsm.New(config, log)
+	}
+	if *hsmModule != "" {
+		return hsm.New(&hsm.Config{
+			Module:       *hsmModule,
+			Label:        *hsmLabel,
+			UserPin:      os.Getenv(envHSMPin),
+			RootKeyID:    *hsmKeyID,
+			RootKeyLabel: *hsmKeyLabel,
+		}, log)
+	}
+
+	root, err := base64.StdEncoding.DecodeString(os.Getenv(envRootKey))
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode %s: %w", envRootKey, err)
+	}
+	if len(root) != 64 {
+		return nil, fmt.Errorf("%s must be 64 bytes, but is %d bytes", envRootKey, len(root))
+	}
+
+	return dvx.WrapDVXAsKeyPool(dvx.DV1{}, root, log), nil
+}
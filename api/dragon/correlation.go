@@ -0,0 +1,62 @@
+package dragon
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+const (
+	// CorrelationIDHeader is the request and response header carrying the
+	// correlation id of a request. It is logged with every failed operation
+	// and returned in every error (see dragonv1.Error).
+	CorrelationIDHeader = "X-Correlation-Id"
+
+	// maxCorrelationIDLength limits the length of correlation ids accepted
+	// from callers.
+	maxCorrelationIDLength = 128
+)
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx that carries the correlation id of
+// a request.
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation id stored in ctx by
+// WithCorrelationID.
+func CorrelationIDFromContext(ctx context.Context) (id string, ok bool) {
+	id, ok = ctx.Value(correlationIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// withCorrelationID stores the correlation id of r in the request's context
+// and returns it in the CorrelationIDHeader of w. The id of the caller is
+// kept if it is a valid header value of at most maxCorrelationIDLength
+// printable ASCII characters, otherwise a random id is created.
+func withCorrelationID(w http.ResponseWriter, r *http.Request) *http.Request {
+	id := r.Header.Get(CorrelationIDHeader)
+	if !validCorrelationID(id) {
+		var buf [16]byte
+		_, _ = rand.Read(buf[:])
+		id = hex.EncodeToString(buf[:])
+	}
+
+	w.Header().Set(CorrelationIDHeader, id)
+	return r.WithContext(WithCorrelationID(r.Context(), id))
+}
+
+func validCorrelationID(id string) bool {
+	if id == "" || len(id) > maxCorrelationIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
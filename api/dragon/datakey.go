@@ -0,0 +1,72 @@
+package dragon
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"io"
+
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/proto"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+const (
+	// dataKeySize is the size of data encryption keys of GenerateDataKey.
+	dataKeySize = 32
+	// dataKeyFooter is the footer of all wrapped data keys. It is
+	// authenticated, so DecryptDataKey only unwraps keys of GenerateDataKey
+	// and never other ciphertexts of the keyRing.
+	dataKeyFooter = "dragon-data-key"
+)
+
+func (s *service) GenerateDataKey(ctx context.Context, req *dragonv1.GenerateDataKeyRequest) (*dragonv1.GenerateDataKeyResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+
+	resp, err := s.idempotent(ctx, req, &dragonv1.GenerateDataKeyResponse{}, func() (proto.Message, error) {
+		plaintext := make([]byte, dataKeySize)
+		if _, err := io.ReadFull(rand.Reader, plaintext); err != nil {
+			return nil, s.twirpError(ctx, err)
+		}
+
+		wrapped, err := s.p.EncryptWithFooterContext(ctx, req.KeyRing, plaintext, []byte(dataKeyFooter))
+		if err != nil {
+			return nil, s.twirpError(ctx, err)
+		}
+		return &dragonv1.GenerateDataKeyResponse{Plaintext: plaintext, WrappedKey: wrapped}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*dragonv1.GenerateDataKeyResponse), nil
+}
+
+func (s *service) DecryptDataKey(ctx context.Context, req *dragonv1.DecryptDataKeyRequest) (*dragonv1.DecryptDataKeyResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.WrappedKey == "" {
+		return nil, twirp.RequiredArgumentError("wrapped_key")
+	}
+
+	_, typePrefix, _, footer, err := dvx.DecodeWithFooter(req.WrappedKey)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+	if typePrefix != dvx.Encrypted || !bytes.Equal(footer, []byte(dataKeyFooter)) {
+		return nil, twirp.InvalidArgumentError("wrapped_key", "isn't a wrapped data key").
+			WithMeta("reason", ReasonInvalidFormat)
+	}
+
+	plaintext, err := s.p.DecryptContext(ctx, req.KeyRing, req.WrappedKey)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+
+	return &dragonv1.DecryptDataKeyResponse{Plaintext: plaintext}, nil
+}
@@ -0,0 +1,51 @@
+package dragon
+
+import (
+	"context"
+
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+func (s *service) DeriveKey(ctx context.Context, req *dragonv1.DeriveKeyRequest) (*dragonv1.DeriveKeyResponse, error) {
+	pool := s.config.KeyPool
+	if pool == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "dragon: key derivation is disabled")
+	}
+	if s.config.Policies == nil {
+		// the keys would be returned to every caller
+		return nil, twirp.NewError(twirp.PermissionDenied, "dragon: DeriveKey requires policies")
+	}
+	if len(req.Input) == 0 {
+		return nil, twirp.RequiredArgumentError("input")
+	}
+
+	var key []byte
+	var err error
+	cp, isContextPool := pool.(dvx.ContextKeyPool)
+	switch req.Size {
+	case 32:
+		if isContextPool {
+			key, err = cp.KDF32Context(ctx, req.Input)
+		} else {
+			key, err = pool.KDF32(req.Input)
+		}
+	case 64:
+		if isContextPool {
+			key, err = cp.KDF64Context(ctx, req.Input)
+		} else {
+			key, err = pool.KDF64(req.Input)
+		}
+	default:
+		return nil, twirp.InvalidArgumentError("size", "must be 32 or 64")
+	}
+	if err != nil {
+		s.logError(ctx, err)
+		return nil, twirp.NewError(twirp.Unavailable, "dragon: key derivation failed").
+			WithMeta("reason", ReasonKeyDerivation)
+	}
+
+	return &dragonv1.DeriveKeyResponse{Key: key}, nil
+}
@@ -0,0 +1,214 @@
+// Package dragon provides the DragonAPI (azoo.dev/api/generated) as a Twirp
+// service around a dvx (azoo.dev/utils/dvx) Protocol instance. It allows
+// services written in any language to use dvx (Encryption/Decryption,
+// Signing/Verifying, MAC and TOTP) without reimplementing DV1 themselves.
+//
+// All secret keys are derived from the KeyPool chain configured in the passed
+// Protocol and never leave the service, with one exception: DeriveKey returns
+// derived keys to remote KeyPools (see Config.KeyPool). It's disabled unless
+// Config.KeyPool is set, and only served to callers whose Policy sets
+// DeriveKeys, for keyRings matching their KeyRingPrefixes.
+package dragon
+
+import (
+	"context"
+	"errors"
+	"os"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+// Config provides all options for a dragon service.
+type Config struct {
+	// DefaultTimeout is the deadline applied to every method that has no
+	// explicit entry in Timeouts. A zero value disables the default deadline.
+	// For example: 5 * time.Second
+	DefaultTimeout time.Duration
+	// Timeouts specifies per-method deadlines. The map key is the RPC method
+	// name as defined in the DragonAPI proto service. For example:
+	//   map[string]time.Duration{
+	//     "GenerateTOTP": 2 * time.Second,
+	//     "Encrypt":      10 * time.Second,
+	//   }
+	Timeouts map[string]time.Duration
+	// DisableQRCode disables the creation of PNG QR-Codes in GenerateTOTP. The
+	// uri is returned regardless.
+	DisableQRCode bool
+	// Policies enables per-caller authorization. The map key is the caller
+	// identity, which the handlers take from the verified TLS client
+	// certificate (see CallerFromTLS), so the http.Server must be configured
+	// to require and verify client certificates. Callers without a Policy are
+	// rejected. A nil map disables authorization. For example:
+	//   map[string]*Policy{
+	//     "spiffe://azoo.dev/ns/prod/sa/users": {
+	//       KeyRingPrefixes: []string{"users/"},
+	//       Methods:         []string{"Encrypt", "Decrypt"},
+	//     },
+	//   }
+	Policies map[string]*Policy
+	// Interceptors are additional Twirp interceptors for the Twirp server and
+	// the JSON gateway. They run before the built-in authorization and deadline
+	// interceptors. For example:
+	//   []twirp.Interceptor{telemetryInterceptor}
+	Interceptors []twirp.Interceptor
+	// TOTPLockout locks accounts after too many failed verifications in
+	// VerifyTOTP and BatchVerifyTOTP. A nil value disables the lockout and
+	// the methods GetTOTPLockout and ResetTOTPLockout. For example:
+	//   &Lockout{Store: NewMemoryAttemptStore(), MaxFailures: 5, Window: 15 * time.Minute}
+	TOTPLockout *Lockout
+	// EnableAdmin enables the admin methods (GetCacheStats,
+	// InvalidateKeyRing, GetKeyPoolHealth, GetRootKeyGenerations,
+	// GetAuditSinkStatus and GetDualRunStats). With Policies only callers
+	// whose Policy sets Admin may use them, so without Policies every caller
+	// can.
+	EnableAdmin bool
+	// KeyPool enables DeriveKey, which derives keys from it and returns them
+	// to remote KeyPools (see client.NewKeyPool). It should be the KeyPool
+	// of the Protocol, so both derive equal keys. DeriveKey requires
+	// Policies: only callers whose Policy sets DeriveKeys may use it, and
+	// without Policies it's refused. A nil value disables DeriveKey. For
+	// example: the tearc KeyPool passed to dvx.NewProtocol
+	KeyPool dvx.KeyPool
+	// AuditSinkCheck reports whether the sink receiving the audit logs of
+	// the KeyPools (e.g. the "dvx_keypool.audit" logger) is available, for
+	// GetAuditSinkStatus. For example, it can check the connection of a log
+	// shipper. A nil value reports the status as unknown. For example:
+	//   func(ctx context.Context) error { return shipper.Ping(ctx) }
+	AuditSinkCheck func(ctx context.Context) error
+	// AuditLog records an AuditEvent of every method call, including calls
+	// rejected by Policies. The events are exported to SIEM consumers by the
+	// audit stream of the gateway (see StreamAuditPath), which requires
+	// EnableAdmin, and by AuditPushers. A nil value disables audit events.
+	// For example:
+	//   dragon.NewAuditLog(65536)
+	AuditLog *AuditLog
+	// Idempotency deduplicates retries of Encrypt, GenerateTOTP and
+	// GenerateDataKey requests that carry an IdempotencyKeyHeader, so
+	// clients can retry them after network failures without creating a
+	// second TOTP enrollment or ciphertext. A nil value ignores idempotency
+	// keys. For example:
+	//   &Idempotency{Store: NewMemoryIdempotencyStore(), TTL: 10 * time.Minute}
+	Idempotency *Idempotency
+	// DualRun repeats a sample of the method calls with another dvx version
+	// and compares their behavior and latency, to de-risk the migration to
+	// it. GetDualRunStats reports the results. A nil value disables the
+	// dual-run mode. For example:
+	//   dragon.NewDualRun(p, "dv2", 0.01, log)
+	DualRun *DualRun
+}
+
+func (c *Config) timeout(method string) time.Duration {
+	if t, ok := c.Timeouts[method]; ok {
+		return t
+	}
+	return c.DefaultTimeout
+}
+
+// New creates a new DragonAPI implementation that uses the Protocol p for all
+// cryptographic operations. If log is nil nothing is logged.
+func New(p *dvx.Protocol, config *Config, log logger.Logger) dragonv1.DragonAPI {
+	if config == nil {
+		config = &Config{}
+	}
+
+	return &service{
+		p:      p,
+		config: config,
+		log:    named(log, "dragon"),
+	}
+}
+
+// named returns log named with name, or a Logger that discards all messages
+// if log is nil.
+func named(log logger.Logger, name string) logger.Logger {
+	if log == nil {
+		return nopLogger{}
+	}
+	return log.Named(name)
+}
+
+// nopLogger is a logger.Logger that discards all messages. Like every
+// logger.Logger it still panics in Panic and exits in Fatal.
+type nopLogger struct{}
+
+func (l nopLogger) Named(string) logger.Logger                    { return l }
+func (l nopLogger) With(...logger.Field) logger.Logger            { return l }
+func (nopLogger) Sync() error                                     { return nil }
+func (nopLogger) Debug(string, ...logger.Field)                   {}
+func (nopLogger) Info(string, ...logger.Field)                    {}
+func (nopLogger) Warn(string, ...logger.Field)                    {}
+func (nopLogger) Error(string, ...logger.Field)                   {}
+func (nopLogger) ErrorReturn(msg string, _ ...logger.Field) error { return errors.New(msg) }
+func (nopLogger) DPanic(string, ...logger.Field)                  {}
+func (nopLogger) Panic(msg string, _ ...logger.Field)             { panic(msg) }
+func (nopLogger) Fatal(string, ...logger.Field)                   { os.Exit(1) }
+
+// NewHandler creates a new DragonAPI implementation using New and wraps it in
+// a Twirp server, which can be mounted on a http.ServeMux using it's
+// PathPrefix. If log is nil nothing is logged.
+func NewHandler(p *dvx.Protocol, config *Config, log logger.Logger) dragonv1.TwirpServer {
+	if config == nil {
+		config = &Config{}
+	}
+
+	return &callerServer{dragonv1.NewDragonAPIServer(New(p, config, log),
+		twirp.WithServerInterceptors(interceptors(config)...))}
+}
+
+// interceptors returns all interceptors that are applied to the Twirp server
+// and the JSON gateway. errorInterceptor comes first, so it sees the errors of
+// all others, followed by auditInterceptor.
+func interceptors(config *Config) []twirp.Interceptor {
+	return append(append([]twirp.Interceptor{errorInterceptor(), auditInterceptor(config)}, config.Interceptors...),
+		authInterceptor(config),
+		tenantInterceptor(config),
+		deadlineInterceptor(config),
+		dualRunInterceptor(config),
+	)
+}
+
+// deadlineInterceptor applies the per-method deadlines from config. The
+// method receives the deadline in its context, which the context-aware dvx
+// operations pass on to KeyPools implementing dvx.ContextKeyPool. Other
+// KeyPools and the primitives themselves don't observe it, therefore the
+// method is executed in a separate go routine and the interceptor returns
+// twirp.DeadlineExceeded as soon as the deadline is reached, even if the
+// underlying operation is still running.
+func deadlineInterceptor(config *Config) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			method, _ := twirp.MethodName(ctx)
+
+			timeout := config.timeout(method)
+			if timeout <= 0 {
+				return next(ctx, req)
+			}
+
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+
+			type result struct {
+				resp interface{}
+				err  error
+			}
+
+			done := make(chan result, 1)
+			go func() {
+				resp, err := next(ctx, req)
+				done <- result{resp, err}
+			}()
+
+			select {
+			case r := <-done:
+				return r.resp, r.err
+			case <-ctx.Done():
+				return nil, twirp.NewErrorf(twirp.DeadlineExceeded, "dragon: %s exceeded deadline of %s", method, timeout)
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package dragon
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/liblog"
+	"azoo.dev/utils/dvx/totp"
+)
+
+func newClient(t *testing.T, config *Config) dragonv1.DragonAPI {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	log := logger.MustNewStd()
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, liblog.Wrap(log))})
+
+	srv := httptest.NewServer(NewHandler(p, config, log))
+	t.Cleanup(srv.Close)
+
+	return dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+}
+
+func TestService_EncryptDecrypt(t *testing.T) {
+	c := newClient(t, nil)
+	ctx := context.Background()
+
+	enc, err := c.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data")})
+	require.NoError(t, err)
+
+	dec, err := c.Decrypt(ctx, &dragonv1.DecryptRequest{KeyRing: "keyring", Ciphertext: enc.Ciphertext})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), dec.Data)
+
+	_, err = c.Decrypt(ctx, &dragonv1.DecryptRequest{KeyRing: "other", Ciphertext: enc.Ciphertext})
+	assert.Error(t, err)
+
+	enc, err = c.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data"), Footer: []byte("users/42")})
+	require.NoError(t, err)
+	_, _, _, footer, err := dvx.DecodeWithFooter(enc.Ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("users/42"), footer)
+	dec, err = c.Decrypt(ctx, &dragonv1.DecryptRequest{KeyRing: "keyring", Ciphertext: enc.Ciphertext})
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), dec.Data)
+
+	_, err = c.Encrypt(ctx, &dragonv1.EncryptRequest{Data: []byte("data")})
+	require.Error(t, err)
+	assert.Equal(t, twirp.InvalidArgument, err.(twirp.Error).Code())
+	assert.Equal(t, "ERROR_CATEGORY_INVALID_REQUEST", err.(twirp.Error).Meta("category"))
+	assert.Equal(t, "false", err.(twirp.Error).Meta("retryable"))
+	assert.NotEmpty(t, err.(twirp.Error).Meta("correlation_id"))
+}
+
+func TestErrorCategory(t *testing.T) {
+	for _, tc := range []struct {
+		err       twirp.Error
+		category  dragonv1.ErrorCategory
+		retryable bool
+	}{
+		{twirp.NewError(twirp.InvalidArgument, "").WithMeta("reason", ReasonAuthentication), dragonv1.ErrorCategory_ERROR_CATEGORY_INVALID_DATA, false},
+		{twirp.NewError(twirp.FailedPrecondition, "").WithMeta("reason", ReasonTimeLocked), dragonv1.ErrorCategory_ERROR_CATEGORY_INVALID_REQUEST, false},
+		{twirp.NewError(twirp.PermissionDenied, ""), dragonv1.ErrorCategory_ERROR_CATEGORY_ACCESS_DENIED, false},
+		{twirp.NewError(twirp.Unavailable, "").WithMeta("reason", ReasonKeyDerivation), dragonv1.ErrorCategory_ERROR_CATEGORY_UNAVAILABLE, true},
+		{twirp.NewError(twirp.DeadlineExceeded, ""), dragonv1.ErrorCategory_ERROR_CATEGORY_DEADLINE_EXCEEDED, false},
+		{twirp.NewError(twirp.Unimplemented, ""), dragonv1.ErrorCategory_ERROR_CATEGORY_UNSUPPORTED, false},
+		{twirp.NewError(twirp.Internal, ""), dragonv1.ErrorCategory_ERROR_CATEGORY_INTERNAL, false},
+	} {
+		category, retryable := errorCategory(tc.err)
+		assert.Equal(t, tc.category, category, tc.err.Code())
+		assert.Equal(t, tc.retryable, retryable, tc.err.Code())
+	}
+}
+
+func TestService_SignVerifyPK(t *testing.T) {
+	c := newClient(t, nil)
+	ctx := context.Background()
+
+	key, err := c.CreateKey(ctx, &dragonv1.CreateKeyRequest{KeyRing: "keyring", Type: dragonv1.CreateKeyRequest_TYPE_SIGNING})
+	require.NoError(t, err)
+
+	sig, err := c.Sign(ctx, &dragonv1.SignRequest{KeyRing: "keyring", Message: []byte("message")})
+	require.NoError(t, err)
+
+	verify, err := c.VerifyPK(ctx, &dragonv1.VerifyPKRequest{PublicKey: key.SigningKey.PublicKey, Message: []byte("message"), Signature: sig.Signature})
+	require.NoError(t, err)
+	assert.True(t, verify.Valid)
+
+	verify, err = c.VerifyPK(ctx, &dragonv1.VerifyPKRequest{PublicKey: key.SigningKey.PublicKey, Message: []byte("other"), Signature: sig.Signature})
+	require.NoError(t, err)
+	assert.False(t, verify.Valid)
+}
+
+func TestService_DataKey(t *testing.T) {
+	c := newClient(t, nil)
+	ctx := context.Background()
+
+	gen, err := c.GenerateDataKey(ctx, &dragonv1.GenerateDataKeyRequest{KeyRing: "keyring"})
+	require.NoError(t, err)
+	assert.Len(t, gen.Plaintext, 32)
+
+	dec, err := c.DecryptDataKey(ctx, &dragonv1.DecryptDataKeyRequest{KeyRing: "keyring", WrappedKey: gen.WrappedKey})
+	require.NoError(t, err)
+	assert.Equal(t, gen.Plaintext, dec.Plaintext)
+
+	_, err = c.DecryptDataKey(ctx, &dragonv1.DecryptDataKeyRequest{KeyRing: "other", WrappedKey: gen.WrappedKey})
+	assert.Error(t, err)
+
+	// ordinary ciphertexts of the keyRing aren't unwrapped
+	enc, err := c.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data")})
+	require.NoError(t, err)
+	_, err = c.DecryptDataKey(ctx, &dragonv1.DecryptDataKeyRequest{KeyRing: "keyring", WrappedKey: enc.Ciphertext})
+	require.Error(t, err)
+	assert.Equal(t, twirp.InvalidArgument, err.(twirp.Error).Code())
+}
+
+func TestService_BatchVerifyTOTP(t *testing.T) {
+	c := newClient(t, &Config{DisableQRCode: true})
+	ctx := context.Background()
+
+	gen1, err := c.GenerateTOTP(ctx, &dragonv1.GenerateTOTPRequest{KeyRing: "totp", Issuer: "i", AccountName: "a", AccountId: "a-id"})
+	require.NoError(t, err)
+	assert.Empty(t, gen1.QrCode)
+
+	gen2, err := c.GenerateTOTP(ctx, &dragonv1.GenerateTOTPRequest{KeyRing: "totp", Issuer: "i", AccountName: "a", AccountId: "a-id"})
+	require.NoError(t, err)
+
+	client, err := totp.ParseFromURI(gen2.Uri)
+	require.NoError(t, err)
+	code, err := client.Generate()
+	require.NoError(t, err)
+
+	resp, err := c.BatchVerifyTOTP(ctx, &dragonv1.BatchVerifyTOTPRequest{KeyRing: "totp", Ids: []string{gen1.Id, gen2.Id}, AccountId: "a-id", Code: code})
+	require.NoError(t, err)
+	assert.True(t, resp.Valid)
+	assert.Equal(t, gen2.Id, resp.ValidThroughId)
+}
+
+func TestDeadlineInterceptor(t *testing.T) {
+	m := deadlineInterceptor(&Config{DefaultTimeout: 10 * time.Millisecond})(func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(100 * time.Millisecond)
+		return req, nil
+	})
+
+	_, err := m(context.Background(), nil)
+	require.Error(t, err)
+	assert.Equal(t, twirp.DeadlineExceeded, err.(twirp.Error).Code())
+
+	m = deadlineInterceptor(&Config{DefaultTimeout: 10 * time.Millisecond, Timeouts: map[string]time.Duration{"": 0}})(func(ctx context.Context, req interface{}) (interface{}, error) {
+		time.Sleep(20 * time.Millisecond)
+		return req, nil
+	})
+
+	resp, err := m(context.Background(), "ok")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+}
+
+func TestNew_NilLogger(t *testing.T) {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, nil)})
+
+	dualRun, err := NewDualRun(p, "dv1", 1, nil)
+	require.NoError(t, err)
+	srv := httptest.NewServer(NewHandler(p, &Config{DualRun: dualRun}, nil))
+	defer srv.Close()
+	c := dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+
+	// failed operations are logged
+	_, err = c.Decrypt(context.Background(), &dragonv1.DecryptRequest{KeyRing: "keyring", Ciphertext: "dv1.enc.invalid"})
+	assert.Error(t, err)
+	resp, err := c.Encrypt(context.Background(), &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data")})
+	require.NoError(t, err)
+	assert.NotEmpty(t, resp.Ciphertext)
+
+	gateway := httptest.NewServer(NewGateway(p, nil, nil))
+	defer gateway.Close()
+	var dec struct {
+		Data []byte `json:"data"`
+	}
+	status := post(t, gateway, "decrypt", `{"key_ring":"keyring","ciphertext":"`+resp.Ciphertext+`"}`, &dec)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []byte("data"), dec.Data)
+}
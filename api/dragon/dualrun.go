@@ -0,0 +1,313 @@
+package dragon
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+const (
+	// dualRunConcurrency limits the amount of concurrently running shadow
+	// operations, so a slow shadow version can't exhaust the service.
+	dualRunConcurrency = 8
+	// dualRunTimeout is the deadline of a shadow operation.
+	dualRunTimeout = 10 * time.Second
+)
+
+// DualRun de-risks the migration to another dvx version. It repeats a sample
+// of Encrypt, Decrypt, Sign, Verify and MAC calls with a shadow Protocol,
+// which uses the other version (see dvx.Protocol.WithVersion), and compares
+// their behavior and latency. Results are reported by GetDualRunStats and
+// divergences are logged.
+//
+// Shadow operations run after the response was computed, in the background,
+// so they neither delay nor change responses. Their results are never
+// returned to callers. A shadow operation diverges if:
+//   - it fails while the operation succeeded, or vice versa
+//   - its ciphertext or signature isn't accepted by the shadow Protocol
+//   - its tag or signature has another size than the one of the operation
+//
+// Decrypt and Verify can't process the ciphertexts and signatures of the
+// request with another version. Instead, the shadow operation decrypts and
+// verifies a ciphertext and signature of the shadow version for the same
+// data, which is created beforehand and not included in its latency.
+//
+// Only calls that succeeded or failed with an error of the Protocol (see
+// Reasons) are sampled, except failed Decrypt and Verify calls.
+type DualRun struct {
+	version    string
+	shadow     *dvx.Protocol
+	sampleRate float64
+	log        logger.Logger
+	sem        chan struct{}
+
+	mu      sync.Mutex
+	methods map[string]*dualRunCounters
+}
+
+type dualRunCounters struct {
+	samples         uint64
+	divergences     uint64
+	skipped         uint64
+	primaryDuration time.Duration
+	shadowDuration  time.Duration
+}
+
+// NewDualRun creates a DualRun that repeats the fraction sampleRate (between
+// 0 and 1) of all supported method calls with a copy of p using version. If
+// log is nil nothing is logged. For example:
+//   dragon.NewDualRun(p, "dv2", 0.01, log)
+func NewDualRun(p *dvx.Protocol, version string, sampleRate float64, log logger.Logger) (*DualRun, error) {
+	if sampleRate < 0 || sampleRate > 1 {
+		return nil, fmt.Errorf("dragon: sample rate %v must be between 0 and 1", sampleRate)
+	}
+	shadow, err := p.WithVersion(version)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DualRun{
+		version:    version,
+		shadow:     shadow,
+		sampleRate: sampleRate,
+		log:        named(log, "dual_run"),
+		sem:        make(chan struct{}, dualRunConcurrency),
+		methods:    make(map[string]*dualRunCounters),
+	}, nil
+}
+
+// dualRunMethod is the shadow operation of a method. compare returns its
+// duration and the error of the shadow Protocol or the divergence from the
+// response of the method, which is nil if the method failed.
+type dualRunMethod struct {
+	// needsResponse excludes failed calls from sampling.
+	needsResponse bool
+	compare       func(ctx context.Context, shadow *dvx.Protocol, req interface{}, resp interface{}) (time.Duration, error)
+}
+
+// dualRunMethods are the methods DualRun samples.
+var dualRunMethods = map[string]dualRunMethod{
+	"Encrypt": {compare: func(ctx context.Context, shadow *dvx.Protocol, req interface{}, _ interface{}) (time.Duration, error) {
+		r := req.(*dragonv1.EncryptRequest)
+		start := time.Now()
+		ciphertext, err := encrypt(ctx, shadow, r)
+		duration := time.Since(start)
+		if err != nil {
+			return duration, err
+		}
+		if data, err := shadow.DecryptContext(ctx, r.KeyRing, ciphertext); err != nil || !bytes.Equal(data, r.Data) {
+			return duration, errors.New("dragon: ciphertext can't be decrypted")
+		}
+		return duration, nil
+	}},
+	"Decrypt": {needsResponse: true, compare: func(ctx context.Context, shadow *dvx.Protocol, req interface{}, resp interface{}) (time.Duration, error) {
+		r, data := req.(*dragonv1.DecryptRequest), resp.(*dragonv1.DecryptResponse).Data
+		// the footer of the ciphertext is reused, as it might be required
+		// for the keyRing (see dvx.Protocol.RequireEncryptionContext)
+		_, _, _, footer, _ := dvx.DecodeWithFooter(r.Ciphertext)
+		ciphertext, err := encrypt(ctx, shadow, &dragonv1.EncryptRequest{KeyRing: r.KeyRing, Data: data, Footer: footer})
+		if err != nil {
+			return 0, err
+		}
+		start := time.Now()
+		decrypted, err := shadow.DecryptContext(ctx, r.KeyRing, ciphertext)
+		duration := time.Since(start)
+		if err != nil {
+			return duration, err
+		}
+		if !bytes.Equal(decrypted, data) {
+			return duration, errors.New("dragon: decrypted data differs")
+		}
+		return duration, nil
+	}},
+	"Sign": {compare: func(ctx context.Context, shadow *dvx.Protocol, req interface{}, resp interface{}) (time.Duration, error) {
+		r := req.(*dragonv1.SignRequest)
+		start := time.Now()
+		signature, raw, err := shadow.SignContext(ctx, r.KeyRing, r.Message)
+		duration := time.Since(start)
+		if err != nil {
+			return duration, err
+		}
+		if resp, ok := resp.(*dragonv1.SignResponse); ok && len(raw) != len(resp.RawSignature) {
+			return duration, fmt.Errorf("dragon: signature has %d instead of %d bytes", len(raw), len(resp.RawSignature))
+		}
+		if valid, err := shadow.VerifyContext(ctx, r.KeyRing, r.Message, signature); err != nil || !valid {
+			return duration, errors.New("dragon: signature can't be verified")
+		}
+		return duration, nil
+	}},
+	"Verify": {needsResponse: true, compare: func(ctx context.Context, shadow *dvx.Protocol, req interface{}, _ interface{}) (time.Duration, error) {
+		r := req.(*dragonv1.VerifyRequest)
+		signature, _, err := shadow.SignContext(ctx, r.KeyRing, r.Message)
+		if err != nil {
+			return 0, err
+		}
+		start := time.Now()
+		valid, err := shadow.VerifyContext(ctx, r.KeyRing, r.Message, signature)
+		duration := time.Since(start)
+		if err != nil {
+			return duration, err
+		}
+		if !valid {
+			return duration, errors.New("dragon: signature can't be verified")
+		}
+		return duration, nil
+	}},
+	"MAC": {compare: func(ctx context.Context, shadow *dvx.Protocol, req interface{}, resp interface{}) (time.Duration, error) {
+		r := req.(*dragonv1.MACRequest)
+		start := time.Now()
+		tag, err := shadow.MACContext(ctx, r.KeyRing, r.Message)
+		duration := time.Since(start)
+		if err != nil {
+			return duration, err
+		}
+		if resp, ok := resp.(*dragonv1.MACResponse); ok {
+			_, shadowTag, _ := dvx.DecodeExpect(tag, dvx.Tagged)
+			_, primaryTag, _ := dvx.DecodeExpect(resp.Tag, dvx.Tagged)
+			if len(shadowTag) != len(primaryTag) {
+				return duration, fmt.Errorf("dragon: tag has %d instead of %d bytes", len(shadowTag), len(primaryTag))
+			}
+		}
+		return duration, nil
+	}},
+}
+
+// dualRunInterceptor repeats a sample of the method calls with the shadow
+// Protocol of config.DualRun. It runs directly around the method, after all
+// other interceptors, so only authorized calls are sampled, their keyRings
+// contain the caller's tenant and the primary duration is that of the method.
+func dualRunInterceptor(config *Config) twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			d := config.DualRun
+			if d == nil {
+				return next(ctx, req)
+			}
+			method, _ := twirp.MethodName(ctx)
+			m, ok := dualRunMethods[method]
+			if !ok || rand.Float64() >= d.sampleRate {
+				return next(ctx, req)
+			}
+
+			start := time.Now()
+			resp, err := next(ctx, req)
+			d.run(method, m, req, resp, err, time.Since(start))
+			return resp, err
+		}
+	}
+}
+
+// run starts the shadow operation of a sampled call in the background, unless
+// too many are running already.
+func (d *DualRun) run(method string, m dualRunMethod, req interface{}, resp interface{}, err error, primaryDuration time.Duration) {
+	if err != nil {
+		// only errors of the Protocol carry a reason. Other errors (e.g.
+		// invalid requests or deadlines) say nothing about the version.
+		var twerr twirp.Error
+		if m.needsResponse || !errors.As(err, &twerr) || twerr.Meta("reason") == "" {
+			return
+		}
+		// don't pass typed nil responses
+		resp = nil
+	}
+
+	select {
+	case d.sem <- struct{}{}:
+	default:
+		d.counters(method, func(c *dualRunCounters) { c.skipped++ })
+		return
+	}
+
+	go func() {
+		defer func() { <-d.sem }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), dualRunTimeout)
+		defer cancel()
+
+		shadowDuration, shadowErr := m.compare(ctx, d.shadow, req, resp)
+		var divergence error
+		switch {
+		case err == nil && shadowErr != nil:
+			divergence = shadowErr
+		case err != nil && shadowErr == nil:
+			divergence = fmt.Errorf("dragon: shadow operation succeeded, but the operation failed: %v", err)
+		}
+
+		d.counters(method, func(c *dualRunCounters) {
+			c.samples++
+			c.primaryDuration += primaryDuration
+			c.shadowDuration += shadowDuration
+			if divergence != nil {
+				c.divergences++
+			}
+		})
+		if divergence != nil {
+			fields := []logger.Field{
+				logger.NewField("method", method),
+				logger.NewField("version", d.version),
+				logger.NewField("error", divergence),
+			}
+			if r, ok := req.(interface{ GetKeyRing() string }); ok {
+				fields = append(fields, logger.NewField("key_ring_fingerprint", dvx.KeyRingFingerprint(r.GetKeyRing())))
+			}
+			d.log.Warn("shadow operation diverged", fields...)
+		}
+	}()
+}
+
+func (d *DualRun) counters(method string, update func(c *dualRunCounters)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.methods[method]
+	if !ok {
+		c = &dualRunCounters{}
+		d.methods[method] = c
+	}
+	update(c)
+}
+
+// stats returns the GetDualRunStatsResponse of d.
+func (d *DualRun) stats() *dragonv1.GetDualRunStatsResponse {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	resp := &dragonv1.GetDualRunStatsResponse{
+		Enabled:    true,
+		Version:    d.version,
+		SampleRate: d.sampleRate,
+	}
+	for method, c := range d.methods {
+		resp.Methods = append(resp.Methods, &dragonv1.DualRunMethodStats{
+			Method:            method,
+			Samples:           c.samples,
+			Divergences:       c.divergences,
+			Skipped:           c.skipped,
+			PrimaryDurationMs: float64(c.primaryDuration) / float64(time.Millisecond),
+			ShadowDurationMs:  float64(c.shadowDuration) / float64(time.Millisecond),
+		})
+	}
+	sort.Slice(resp.Methods, func(i, j int) bool { return resp.Methods[i].Method < resp.Methods[j].Method })
+	return resp
+}
+
+func (s *service) GetDualRunStats(_ context.Context, _ *dragonv1.GetDualRunStatsRequest) (*dragonv1.GetDualRunStatsResponse, error) {
+	if err := s.adminEnabled(); err != nil {
+		return nil, err
+	}
+	if s.config.DualRun == nil {
+		return &dragonv1.GetDualRunStatsResponse{}, nil
+	}
+	return s.config.DualRun.stats(), nil
+}
@@ -0,0 +1,106 @@
+package dragon
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/liblog"
+)
+
+// failingPool is a KeyPool whose derivations always fail.
+type failingPool struct{}
+
+func (failingPool) KDF32([]byte) ([]byte, error) { return nil, errors.New("hsm unreachable") }
+func (failingPool) KDF64([]byte) ([]byte, error) { return nil, errors.New("hsm unreachable") }
+func (failingPool) Close() error                 { return nil }
+
+func TestService_DualRun(t *testing.T) {
+	p := newAuditProtocol(t)
+	dualRun, err := NewDualRun(p, "dv2", 1, logger.MustNewStd())
+	require.NoError(t, err)
+	srv := httptest.NewServer(NewHandler(p, &Config{EnableAdmin: true, DualRun: dualRun}, logger.MustNewStd()))
+	t.Cleanup(srv.Close)
+	c := dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+	ctx := context.Background()
+
+	enc, err := c.Encrypt(ctx, &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data")})
+	require.NoError(t, err)
+	_, err = c.Decrypt(ctx, &dragonv1.DecryptRequest{KeyRing: "keyring", Ciphertext: enc.Ciphertext})
+	require.NoError(t, err)
+	sig, err := c.Sign(ctx, &dragonv1.SignRequest{KeyRing: "keyring", Message: []byte("message")})
+	require.NoError(t, err)
+	_, err = c.Verify(ctx, &dragonv1.VerifyRequest{KeyRing: "keyring", Message: []byte("message"), Signature: sig.Signature})
+	require.NoError(t, err)
+	_, err = c.MAC(ctx, &dragonv1.MACRequest{KeyRing: "keyring", Message: []byte("message")})
+	require.NoError(t, err)
+
+	// neither invalid requests nor failed decryptions are sampled
+	_, err = c.Encrypt(ctx, &dragonv1.EncryptRequest{Data: []byte("data")})
+	require.Error(t, err)
+	_, err = c.Decrypt(ctx, &dragonv1.DecryptRequest{KeyRing: "other", Ciphertext: enc.Ciphertext})
+	require.Error(t, err)
+
+	var stats *dragonv1.GetDualRunStatsResponse
+	require.Eventually(t, func() bool {
+		stats, err = c.GetDualRunStats(ctx, &dragonv1.GetDualRunStatsRequest{})
+		require.NoError(t, err)
+		return len(stats.Methods) == 5
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.True(t, stats.Enabled)
+	assert.Equal(t, "dv2", stats.Version)
+	assert.Equal(t, float64(1), stats.SampleRate)
+	for i, method := range []string{"Decrypt", "Encrypt", "MAC", "Sign", "Verify"} {
+		assert.Equal(t, method, stats.Methods[i].Method)
+		assert.Equal(t, uint64(1), stats.Methods[i].Samples, method)
+		assert.Zero(t, stats.Methods[i].Divergences, method)
+		assert.Greater(t, stats.Methods[i].ShadowDurationMs, float64(0), method)
+	}
+
+	_, err = NewDualRun(p, "dv2", 2, logger.MustNewStd())
+	assert.Error(t, err)
+	_, err = NewDualRun(p, "dv9", 0.5, logger.MustNewStd())
+	assert.Error(t, err)
+}
+
+func TestService_DualRunDivergence(t *testing.T) {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{
+		dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, liblog.Wrap(logger.MustNewStd())),
+		"dv2":       failingPool{},
+	})
+
+	dualRun, err := NewDualRun(p, "dv2", 1, logger.MustNewStd())
+	require.NoError(t, err)
+	s := New(p, &Config{EnableAdmin: true, DualRun: dualRun}, logger.MustNewStd())
+	srv := httptest.NewServer(NewHandler(p, &Config{DualRun: dualRun}, logger.MustNewStd()))
+	t.Cleanup(srv.Close)
+	c := dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+
+	_, err = c.MAC(context.Background(), &dragonv1.MACRequest{KeyRing: "keyring", Message: []byte("message")})
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		stats, err := s.GetDualRunStats(context.Background(), &dragonv1.GetDualRunStatsRequest{})
+		require.NoError(t, err)
+		return len(stats.Methods) == 1 && stats.Methods[0].Divergences == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// without dual-run mode the stats are empty
+	stats, err := New(p, &Config{EnableAdmin: true}, logger.MustNewStd()).GetDualRunStats(context.Background(), &dragonv1.GetDualRunStatsRequest{})
+	require.NoError(t, err)
+	assert.False(t, stats.Enabled)
+}
@@ -0,0 +1,141 @@
+package dragon
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+// Reasons are attached as "reason" meta value to all errors that are mapped
+// from a dvx error class, so clients can react to them without parsing error
+// messages.
+const (
+	ReasonInvalidFormat  = "invalid_format"
+	ReasonInvalidKey     = "invalid_key"
+	ReasonAuthentication = "authentication_failed"
+	ReasonKeyDerivation  = "key_derivation_failed"
+	ReasonTimeLocked     = "time_locked"
+	ReasonKeyRingPolicy  = "key_ring_policy"
+	ReasonExpired        = "expired"
+	ReasonRevoked        = "revoked"
+	ReasonInternal       = "internal"
+)
+
+// twirpError maps err returned by a dvx.Protocol to a twirp.Error. Only
+// errors caused by the caller's input carry their original message. All other
+// errors are logged and replaced by a generic message, as they might contain
+// details about the underlying KeyPool.
+func (s *service) twirpError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, dvx.ErrKeyDerivation):
+		s.logError(ctx, err)
+		return twirp.NewError(twirp.Unavailable, "dragon: key derivation failed").
+			WithMeta("reason", ReasonKeyDerivation)
+	case errors.Is(err, dvx.ErrInvalidFormat):
+		return twirp.NewError(twirp.InvalidArgument, err.Error()).
+			WithMeta("reason", ReasonInvalidFormat)
+	case errors.Is(err, dvx.ErrInvalidKey):
+		return twirp.NewError(twirp.InvalidArgument, err.Error()).
+			WithMeta("reason", ReasonInvalidKey)
+	case errors.Is(err, dvx.ErrAuthentication):
+		return twirp.NewError(twirp.InvalidArgument, "dragon: authentication of ciphertext failed").
+			WithMeta("reason", ReasonAuthentication)
+	case errors.Is(err, dvx.ErrTimeLocked):
+		return twirp.NewError(twirp.FailedPrecondition, err.Error()).
+			WithMeta("reason", ReasonTimeLocked)
+	case errors.Is(err, dvx.ErrKeyRingPolicy):
+		return twirp.NewError(twirp.InvalidArgument, err.Error()).
+			WithMeta("reason", ReasonKeyRingPolicy)
+	case errors.Is(err, dvx.ErrExpired):
+		return twirp.NewError(twirp.FailedPrecondition, err.Error()).
+			WithMeta("reason", ReasonExpired)
+	case errors.Is(err, dvx.ErrRevoked):
+		return twirp.NewError(twirp.FailedPrecondition, err.Error()).
+			WithMeta("reason", ReasonRevoked)
+	case errors.Is(err, context.DeadlineExceeded):
+		return twirp.NewError(twirp.DeadlineExceeded, "dragon: deadline exceeded")
+	case errors.Is(err, context.Canceled):
+		return twirp.NewError(twirp.Canceled, "dragon: request canceled")
+	default:
+		s.logError(ctx, err)
+		return twirp.NewError(twirp.Internal, "dragon: operation failed").
+			WithMeta("reason", ReasonInternal)
+	}
+}
+
+func (s *service) logError(ctx context.Context, err error) {
+	method, _ := twirp.MethodName(ctx)
+	correlationID, _ := CorrelationIDFromContext(ctx)
+	s.log.Warn("operation failed",
+		logger.NewField("method", method),
+		logger.NewField("correlation_id", correlationID),
+		logger.NewField("error", err))
+}
+
+// errorCategory returns the ErrorCategory of twerr and whether it is
+// retryable. Errors of dvx are categorized by their reason, all others by
+// their code.
+func errorCategory(twerr twirp.Error) (category dragonv1.ErrorCategory, retryable bool) {
+	switch twerr.Meta("reason") {
+	case ReasonInvalidFormat, ReasonInvalidKey, ReasonAuthentication:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_INVALID_DATA, false
+	}
+
+	switch twerr.Code() {
+	case twirp.InvalidArgument, twirp.Malformed, twirp.BadRoute, twirp.OutOfRange,
+		twirp.FailedPrecondition, twirp.AlreadyExists, twirp.NotFound:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_INVALID_REQUEST, false
+	case twirp.Unauthenticated, twirp.PermissionDenied:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_ACCESS_DENIED, false
+	case twirp.Unavailable, twirp.ResourceExhausted, twirp.Aborted:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_UNAVAILABLE, true
+	case twirp.DeadlineExceeded, twirp.Canceled:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_DEADLINE_EXCEEDED, false
+	case twirp.Unimplemented:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_UNSUPPORTED, false
+	default:
+		return dragonv1.ErrorCategory_ERROR_CATEGORY_INTERNAL, false
+	}
+}
+
+// withErrorDetails converts err into a twirp.Error carrying the fields of
+// the Error model (see dragonv1.Error) as meta values. Errors that aren't a
+// twirp.Error are replaced by a generic internal error.
+func withErrorDetails(ctx context.Context, err error) twirp.Error {
+	var twerr twirp.Error
+	if !errors.As(err, &twerr) {
+		twerr = twirp.NewError(twirp.Internal, "dragon: operation failed").
+			WithMeta("reason", ReasonInternal)
+	}
+
+	category, retryable := errorCategory(twerr)
+	twerr = twerr.
+		WithMeta("category", category.String()).
+		WithMeta("retryable", strconv.FormatBool(retryable))
+	if id, ok := CorrelationIDFromContext(ctx); ok {
+		twerr = twerr.WithMeta("correlation_id", id)
+	}
+	return twerr
+}
+
+// errorInterceptor adds the fields of the Error model to all errors of the
+// methods and the interceptors after it. Errors of the Twirp server itself
+// (e.g. of unknown routes) don't pass interceptors, therefore clients fall
+// back to the category of the code.
+func errorInterceptor() twirp.Interceptor {
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			resp, err := next(ctx, req)
+			if err != nil {
+				return nil, withErrorDetails(ctx, err)
+			}
+			return resp, nil
+		}
+	}
+}
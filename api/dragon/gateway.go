@@ -0,0 +1,276 @@
+package dragon
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+)
+
+const (
+	// GatewayPathPrefix is the path prefix under which the gateway handler
+	// serves its methods and OpenAPI document.
+	GatewayPathPrefix = "/v1/"
+
+	// maxGatewayBodySize limits the size of request bodies accepted by the
+	// gateway.
+	maxGatewayBodySize = 16 << 20
+)
+
+// gatewayRoute binds a DragonAPI method to its gateway path.
+type gatewayRoute struct {
+	path       string
+	method     string
+	newRequest func() proto.Message
+	call       func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error)
+}
+
+var gatewayRoutes = []gatewayRoute{
+	{"create-key", "CreateKey", func() proto.Message { return &dragonv1.CreateKeyRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.CreateKey(ctx, req.(*dragonv1.CreateKeyRequest))
+		}},
+	{"encrypt", "Encrypt", func() proto.Message { return &dragonv1.EncryptRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.Encrypt(ctx, req.(*dragonv1.EncryptRequest))
+		}},
+	{"decrypt", "Decrypt", func() proto.Message { return &dragonv1.DecryptRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.Decrypt(ctx, req.(*dragonv1.DecryptRequest))
+		}},
+	{"generate-data-key", "GenerateDataKey", func() proto.Message { return &dragonv1.GenerateDataKeyRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GenerateDataKey(ctx, req.(*dragonv1.GenerateDataKeyRequest))
+		}},
+	{"decrypt-data-key", "DecryptDataKey", func() proto.Message { return &dragonv1.DecryptDataKeyRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.DecryptDataKey(ctx, req.(*dragonv1.DecryptDataKeyRequest))
+		}},
+	{"derive-key", "DeriveKey", func() proto.Message { return &dragonv1.DeriveKeyRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.DeriveKey(ctx, req.(*dragonv1.DeriveKeyRequest))
+		}},
+	{"mac", "MAC", func() proto.Message { return &dragonv1.MACRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.MAC(ctx, req.(*dragonv1.MACRequest))
+		}},
+	{"sign", "Sign", func() proto.Message { return &dragonv1.SignRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.Sign(ctx, req.(*dragonv1.SignRequest))
+		}},
+	{"verify", "Verify", func() proto.Message { return &dragonv1.VerifyRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.Verify(ctx, req.(*dragonv1.VerifyRequest))
+		}},
+	{"verify-pk", "VerifyPK", func() proto.Message { return &dragonv1.VerifyPKRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.VerifyPK(ctx, req.(*dragonv1.VerifyPKRequest))
+		}},
+	{"generate-totp", "GenerateTOTP", func() proto.Message { return &dragonv1.GenerateTOTPRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GenerateTOTP(ctx, req.(*dragonv1.GenerateTOTPRequest))
+		}},
+	{"verify-totp", "VerifyTOTP", func() proto.Message { return &dragonv1.VerifyTOTPRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.VerifyTOTP(ctx, req.(*dragonv1.VerifyTOTPRequest))
+		}},
+	{"batch-verify-totp", "BatchVerifyTOTP", func() proto.Message { return &dragonv1.BatchVerifyTOTPRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.BatchVerifyTOTP(ctx, req.(*dragonv1.BatchVerifyTOTPRequest))
+		}},
+	{"delete-totp", "DeleteTOTP", func() proto.Message { return &dragonv1.DeleteTOTPRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.DeleteTOTP(ctx, req.(*dragonv1.DeleteTOTPRequest))
+		}},
+	{"get-totp-lockout", "GetTOTPLockout", func() proto.Message { return &dragonv1.GetTOTPLockoutRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GetTOTPLockout(ctx, req.(*dragonv1.GetTOTPLockoutRequest))
+		}},
+	{"reset-totp-lockout", "ResetTOTPLockout", func() proto.Message { return &dragonv1.ResetTOTPLockoutRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.ResetTOTPLockout(ctx, req.(*dragonv1.ResetTOTPLockoutRequest))
+		}},
+	{"get-cache-stats", "GetCacheStats", func() proto.Message { return &dragonv1.GetCacheStatsRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GetCacheStats(ctx, req.(*dragonv1.GetCacheStatsRequest))
+		}},
+	{"invalidate-key-ring", "InvalidateKeyRing", func() proto.Message { return &dragonv1.InvalidateKeyRingRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.InvalidateKeyRing(ctx, req.(*dragonv1.InvalidateKeyRingRequest))
+		}},
+	{"get-key-pool-health", "GetKeyPoolHealth", func() proto.Message { return &dragonv1.GetKeyPoolHealthRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GetKeyPoolHealth(ctx, req.(*dragonv1.GetKeyPoolHealthRequest))
+		}},
+	{"get-root-key-generations", "GetRootKeyGenerations", func() proto.Message { return &dragonv1.GetRootKeyGenerationsRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GetRootKeyGenerations(ctx, req.(*dragonv1.GetRootKeyGenerationsRequest))
+		}},
+	{"get-audit-sink-status", "GetAuditSinkStatus", func() proto.Message { return &dragonv1.GetAuditSinkStatusRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GetAuditSinkStatus(ctx, req.(*dragonv1.GetAuditSinkStatusRequest))
+		}},
+	{"get-dual-run-stats", "GetDualRunStats", func() proto.Message { return &dragonv1.GetDualRunStatsRequest{} },
+		func(ctx context.Context, api dragonv1.DragonAPI, req proto.Message) (proto.Message, error) {
+			return api.GetDualRunStats(ctx, req.(*dragonv1.GetDualRunStatsRequest))
+		}},
+}
+
+// NewGateway creates a plain net/http JSON API mirroring the DragonAPI, for
+// platforms where using a Twirp client is inconvenient. Every method is
+// served as "POST /v1/<method>" (e.g. "POST /v1/verify-pk") and accepts and
+// returns the JSON encoding of its proto messages with original field names.
+// The OpenAPI 3 document of the gateway is served at "GET /v1/openapi.json".
+// The gateway additionally serves streaming variants of Encrypt and Decrypt
+// (see StreamEncryptPath) and the audit event stream (see StreamAuditPath),
+// which the Twirp service can't provide.
+//
+// Errors are always returned as JSON envelope (see GatewayError) with the
+// http status code matching the error code. If log is nil nothing is logged.
+func NewGateway(p *dvx.Protocol, config *Config, log logger.Logger) http.Handler {
+	if config == nil {
+		config = &Config{}
+	}
+
+	svc := New(p, config, log).(*service)
+	g := &gateway{
+		api:       svc,
+		svc:       svc,
+		intercept: twirp.ChainInterceptors(interceptors(config)...),
+		routes:    make(map[string]gatewayRoute),
+		openAPI:   mustMarshalOpenAPI(),
+	}
+	for _, r := range gatewayRoutes {
+		g.routes[r.path] = r
+	}
+	return g
+}
+
+// GatewayError is the JSON envelope of all errors returned by the gateway. Its
+// fields follow the Error model of the DragonAPI (see dragonv1.Error).
+type GatewayError struct {
+	Error struct {
+		// Code is the Twirp error code, e.g. "invalid_argument".
+		Code string `json:"code"`
+		// Message is a human-readable description of the error.
+		Message string `json:"message"`
+		// Reason is the machine-readable dvx error class, e.g.
+		// "invalid_format". See the Reason constants.
+		Reason string `json:"reason,omitempty"`
+		// Argument is the name of the invalid argument, if any.
+		Argument string `json:"argument,omitempty"`
+		// Category groups the error by how callers should react to it, e.g.
+		// "ERROR_CATEGORY_UNAVAILABLE". See dragonv1.ErrorCategory.
+		Category string `json:"category"`
+		// Retryable reports whether the request may succeed if it is retried
+		// with backoff.
+		Retryable bool `json:"retryable"`
+		// CorrelationID identifies the request in the logs of the service.
+		CorrelationID string `json:"correlation_id"`
+	} `json:"error"`
+}
+
+type gateway struct {
+	api       dragonv1.DragonAPI
+	svc       *service
+	intercept twirp.Interceptor
+	routes    map[string]gatewayRoute
+	openAPI   []byte
+}
+
+func (g *gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r = withCorrelationID(w, r)
+	path := strings.TrimPrefix(r.URL.Path, GatewayPathPrefix)
+
+	if path == "openapi.json" {
+		if r.Method != http.MethodGet {
+			g.writeError(r.Context(), w, twirp.NewError(twirp.BadRoute, "dragon: openapi.json must be requested with GET"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(g.openAPI)
+		return
+	}
+
+	if path == StreamAuditPath {
+		g.serveAuditStream(w, r)
+		return
+	}
+	if _, ok := streamMethods[path]; ok {
+		g.serveStream(w, r, path)
+		return
+	}
+
+	route, ok := g.routes[path]
+	if !ok || path == r.URL.Path {
+		g.writeError(r.Context(), w, twirp.NewErrorf(twirp.BadRoute, "dragon: no method for path %q", r.URL.Path))
+		return
+	}
+	if r.Method != http.MethodPost {
+		g.writeError(r.Context(), w, twirp.NewErrorf(twirp.BadRoute, "dragon: %s must be called with POST", r.URL.Path))
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxGatewayBodySize))
+	if err != nil {
+		g.writeError(r.Context(), w, twirp.NewError(twirp.Malformed, "dragon: unable to read request body"))
+		return
+	}
+
+	req := route.newRequest()
+	if err := protojson.Unmarshal(body, req); err != nil {
+		g.writeError(r.Context(), w, twirp.NewErrorf(twirp.Malformed, "dragon: unable to decode request body: %v", err))
+		return
+	}
+
+	ctx := withIdempotencyKey(withTLSCaller(r)).Context()
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, route.method)
+
+	resp, err := g.intercept(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return route.call(ctx, g.api, req.(proto.Message))
+	})(ctx, req)
+	if err != nil {
+		g.writeError(ctx, w, err)
+		return
+	}
+
+	buf, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(resp.(proto.Message))
+	if err != nil {
+		g.writeError(ctx, w, twirp.InternalErrorWith(err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(buf)
+}
+
+func (g *gateway) writeError(ctx context.Context, w http.ResponseWriter, err error) {
+	// errors of the interceptors already carry the details, but those of the
+	// gateway itself don't
+	twerr := withErrorDetails(ctx, err)
+
+	var e GatewayError
+	e.Error.Code = string(twerr.Code())
+	e.Error.Message = twerr.Msg()
+	e.Error.Reason = twerr.Meta("reason")
+	e.Error.Argument = twerr.Meta("argument")
+	e.Error.Category = twerr.Meta("category")
+	e.Error.Retryable = twerr.Meta("retryable") == "true"
+	e.Error.CorrelationID = twerr.Meta("correlation_id")
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(twirp.ServerHTTPStatusFromErrorCode(twerr.Code()))
+	_ = json.NewEncoder(w).Encode(&e)
+}
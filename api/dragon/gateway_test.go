@@ -0,0 +1,121 @@
+package dragon
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/liblog"
+)
+
+func newGateway(t *testing.T) *httptest.Server {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	log := logger.MustNewStd()
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, liblog.Wrap(log))})
+
+	srv := httptest.NewServer(NewGateway(p, nil, log))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func post(t *testing.T, srv *httptest.Server, path string, body string, v interface{}) int {
+	resp, err := http.Post(srv.URL+GatewayPathPrefix+path, "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(v))
+	return resp.StatusCode
+}
+
+func TestGateway_EncryptDecrypt(t *testing.T) {
+	srv := newGateway(t)
+
+	var enc struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	status := post(t, srv, "encrypt", `{"key_ring":"keyring","data":"ZGF0YQ=="}`, &enc)
+	require.Equal(t, http.StatusOK, status)
+	require.NotEmpty(t, enc.Ciphertext)
+
+	var dec struct {
+		Data []byte `json:"data"`
+	}
+	status = post(t, srv, "decrypt", `{"key_ring":"keyring","ciphertext":"`+enc.Ciphertext+`"}`, &dec)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []byte("data"), dec.Data)
+}
+
+func TestGateway_Errors(t *testing.T) {
+	srv := newGateway(t)
+
+	var e GatewayError
+	status := post(t, srv, "decrypt", `{"key_ring":"keyring","ciphertext":"dv1.x.invalid"}`, &e)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "invalid_argument", e.Error.Code)
+	assert.Equal(t, ReasonInvalidFormat, e.Error.Reason)
+	assert.Equal(t, "ERROR_CATEGORY_INVALID_DATA", e.Error.Category)
+	assert.False(t, e.Error.Retryable)
+	assert.Len(t, e.Error.CorrelationID, 32)
+
+	e = GatewayError{}
+	status = post(t, srv, "encrypt", `{"data":"ZGF0YQ=="}`, &e)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "key_ring", e.Error.Argument)
+
+	e = GatewayError{}
+	status = post(t, srv, "unknown", `{}`, &e)
+	assert.Equal(t, http.StatusNotFound, status)
+	assert.Equal(t, "bad_route", e.Error.Code)
+	assert.Equal(t, "ERROR_CATEGORY_INVALID_REQUEST", e.Error.Category)
+
+	// the correlation id of the caller is kept
+	req, err := http.NewRequest(http.MethodPost, srv.URL+GatewayPathPrefix+"encrypt", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	req.Header.Set(CorrelationIDHeader, "req-1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	e = GatewayError{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+	assert.Equal(t, "req-1", resp.Header.Get(CorrelationIDHeader))
+	assert.Equal(t, "req-1", e.Error.CorrelationID)
+}
+
+func TestGateway_OpenAPI(t *testing.T) {
+	srv := newGateway(t)
+
+	resp, err := http.Get(srv.URL + GatewayPathPrefix + "openapi.json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var doc struct {
+		Paths      map[string]interface{} `json:"paths"`
+		Components struct {
+			Schemas map[string]interface{} `json:"schemas"`
+		} `json:"components"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&doc))
+
+	for _, r := range gatewayRoutes {
+		assert.Contains(t, doc.Paths, GatewayPathPrefix+r.path)
+	}
+	assert.Contains(t, doc.Paths, GatewayPathPrefix+StreamEncryptPath)
+	assert.Contains(t, doc.Paths, GatewayPathPrefix+StreamAuditPath)
+	assert.Contains(t, doc.Components.Schemas, "AuditEvent")
+	assert.Contains(t, doc.Components.Schemas, "EncryptRequest")
+	assert.Contains(t, doc.Components.Schemas, "CreateKeyResponse.SigningKey")
+	assert.Contains(t, doc.Components.Schemas, "Error")
+}
@@ -0,0 +1,30 @@
+module azoo.dev/api/dragon
+
+go 1.16
+
+require (
+	azoo.dev/api/generated v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx/hsm v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx/liblog v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx/tearc v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/qr v0.0.0-00010101000000-000000000000
+	github.com/harwoeck/liblog/contract v1.1.2
+	github.com/stretchr/testify v1.7.0
+	github.com/twitchtv/twirp v8.1.3+incompatible
+	go.opentelemetry.io/otel v1.16.0
+	go.opentelemetry.io/otel/metric v1.16.0
+	go.opentelemetry.io/otel/sdk v1.16.0
+	go.opentelemetry.io/otel/trace v1.16.0
+	google.golang.org/protobuf v1.27.1
+)
+
+replace (
+	azoo.dev/api/generated => ../generated
+	azoo.dev/utils/dvx => ../../utils/dvx
+	azoo.dev/utils/dvx/hsm => ../../utils/dvx/hsm
+	azoo.dev/utils/dvx/liblog => ../../utils/dvx/liblog
+	azoo.dev/utils/dvx/tearc => ../../utils/dvx/tearc
+	azoo.dev/utils/qr => ../../utils/qr
+	azoo.dev/utils/tearc => ../../utils/tearc
+)
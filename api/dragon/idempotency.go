@@ -0,0 +1,272 @@
+package dragon
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/proto"
+
+	"azoo.dev/utils/dvx"
+)
+
+const (
+	// IdempotencyKeyHeader is the request header carrying the idempotency key
+	// of a request (see Config.Idempotency). Keys must be printable ASCII
+	// characters of at most maxIdempotencyKeyLength, e.g. a random UUID per
+	// logical operation that is reused for all of its retries.
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// maxIdempotencyKeyLength limits the length of idempotency keys.
+	maxIdempotencyKeyLength = 128
+
+	// idempotencyKeyRing prefixes the keyRings that encrypt stored
+	// responses. Tenant keyRings can't start with it (see tenantKeyRing).
+	idempotencyKeyRing = "dragon-idempotency/"
+)
+
+// IdempotencyStore stores the responses of requests with idempotency key for
+// a short time, so their retries receive the same response. Implementations
+// must be safe for concurrent use. Multiple service instances must share a
+// store (e.g. NewRedisIdempotencyStore) to deduplicate retries that reach
+// another instance.
+type IdempotencyStore interface {
+	// Reserve atomically reserves key for ttl if it is unknown and returns
+	// true. Otherwise, it returns false and the response stored by Complete,
+	// which is nil while the request that reserved key is still running.
+	Reserve(ctx context.Context, key string, ttl time.Duration) (reserved bool, response []byte, err error)
+	// Complete stores the response of the request that reserved key for ttl.
+	Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error
+	// Release removes the reservation of key, so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// Idempotency configures the deduplication of retries of Encrypt,
+// GenerateTOTP and GenerateDataKey. Requests with an IdempotencyKeyHeader are
+// executed only once per caller, method and key within TTL; retries receive
+// the response of the first request. Retries while the first request is still
+// running fail with twirp.Aborted, retries with the same key but a different
+// request with twirp.InvalidArgument. Failed requests aren't stored.
+//
+// Responses contain secrets (e.g. the TOTP uri and data keys), therefore they
+// are encrypted with the Protocol before they are stored.
+type Idempotency struct {
+	// Store stores the responses. For example: NewMemoryIdempotencyStore()
+	Store IdempotencyStore
+	// TTL is the time responses are stored, which must exceed the time in
+	// which clients retry. Defaults to 10 minutes.
+	TTL time.Duration
+}
+
+func (i *Idempotency) ttl() time.Duration {
+	if i.TTL <= 0 {
+		return 10 * time.Minute
+	}
+	return i.TTL
+}
+
+type idempotencyKey struct{}
+
+// WithIdempotencyKey returns a copy of ctx that carries the idempotency key
+// of a request.
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, idempotencyKey{}, key)
+}
+
+// IdempotencyKeyFromContext returns the idempotency key stored in ctx by
+// WithIdempotencyKey.
+func IdempotencyKeyFromContext(ctx context.Context) (key string, ok bool) {
+	key, ok = ctx.Value(idempotencyKey{}).(string)
+	return key, ok && key != ""
+}
+
+// withIdempotencyKey stores the IdempotencyKeyHeader of r in the request's
+// context. Invalid keys are stored as well and rejected by idempotent.
+func withIdempotencyKey(r *http.Request) *http.Request {
+	if key := r.Header.Get(IdempotencyKeyHeader); key != "" {
+		return r.WithContext(WithIdempotencyKey(r.Context(), key))
+	}
+	return r
+}
+
+// idempotent calls fn, unless ctx carries an idempotency key whose response
+// is already stored, which is unmarshalled into resp instead. req identifies
+// the request, so keys can't be reused for different requests.
+func (s *service) idempotent(ctx context.Context, req proto.Message, resp proto.Message, fn func() (proto.Message, error)) (proto.Message, error) {
+	key, ok := IdempotencyKeyFromContext(ctx)
+	if !ok || s.config.Idempotency == nil {
+		return fn()
+	}
+	if len(key) > maxIdempotencyKeyLength || !validCorrelationID(key) {
+		return nil, twirp.InvalidArgumentError(IdempotencyKeyHeader, "must be at most 128 printable ASCII characters")
+	}
+
+	method, _ := twirp.MethodName(ctx)
+	caller, _ := CallerFromContext(ctx)
+	storeKey := idempotencyStoreKey(caller, method, key)
+	requestHash, err := idempotencyRequestHash(req)
+	if err != nil {
+		return nil, twirp.InternalErrorWith(err)
+	}
+
+	store, ttl := s.config.Idempotency.Store, s.config.Idempotency.ttl()
+	reserved, stored, err := store.Reserve(ctx, storeKey, ttl)
+	if err != nil {
+		return nil, s.idempotencyError(ctx, err)
+	}
+	if !reserved {
+		return s.replay(ctx, storeKey, requestHash, stored, resp)
+	}
+
+	result, err := fn()
+	if err != nil {
+		if releaseErr := store.Release(ctx, storeKey); releaseErr != nil {
+			s.logError(ctx, releaseErr)
+		}
+		return nil, err
+	}
+
+	buf, err := proto.Marshal(result)
+	if err == nil {
+		var ciphertext string
+		ciphertext, err = s.p.EncryptWithFooterContext(ctx, idempotencyKeyRing+storeKey, buf, requestHash)
+		if err == nil {
+			err = store.Complete(ctx, storeKey, []byte(ciphertext), ttl)
+		}
+	}
+	if err != nil {
+		// the response is still returned, but retries would fail with
+		// twirp.Aborted until the reservation expires
+		s.logError(ctx, err)
+		if releaseErr := store.Release(ctx, storeKey); releaseErr != nil {
+			s.logError(ctx, releaseErr)
+		}
+	}
+	return result, nil
+}
+
+// replay unmarshals the stored response of a request with the same
+// idempotency key into resp.
+func (s *service) replay(ctx context.Context, storeKey string, requestHash []byte, stored []byte, resp proto.Message) (proto.Message, error) {
+	if stored == nil {
+		return nil, twirp.NewError(twirp.Aborted, "dragon: request with the same idempotency key is in progress")
+	}
+
+	_, _, _, footer, err := dvx.DecodeWithFooter(string(stored))
+	if err != nil {
+		return nil, s.idempotencyError(ctx, err)
+	}
+	if !bytes.Equal(footer, requestHash) {
+		return nil, twirp.InvalidArgumentError(IdempotencyKeyHeader, "was already used for a different request")
+	}
+
+	buf, err := s.p.DecryptContext(ctx, idempotencyKeyRing+storeKey, string(stored))
+	if err != nil {
+		return nil, s.idempotencyError(ctx, err)
+	}
+	if err = proto.Unmarshal(buf, resp); err != nil {
+		return nil, s.idempotencyError(ctx, err)
+	}
+	return resp, nil
+}
+
+// idempotencyError logs err of an IdempotencyStore or of a stored response
+// and returns twirp.Unavailable. The request isn't executed, as it might
+// already have been.
+func (s *service) idempotencyError(ctx context.Context, err error) error {
+	s.logError(ctx, err)
+	return twirp.NewError(twirp.Unavailable, "dragon: idempotency store unavailable")
+}
+
+// idempotencyStoreKey returns the IdempotencyStore key of an idempotency key.
+// Keys are scoped by caller and method, so callers can't read the responses
+// of others.
+func idempotencyStoreKey(caller string, method string, key string) string {
+	h := sha256.New()
+	for _, part := range []string{caller, method, key} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// idempotencyRequestHash returns the hash of req. The keyRing of req already
+// contains the caller's tenant.
+func idempotencyRequestHash(req proto.Message) ([]byte, error) {
+	buf, err := proto.MarshalOptions{Deterministic: true}.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.Sum256(buf)
+	return h[:], nil
+}
+
+// NewMemoryIdempotencyStore creates an IdempotencyStore that keeps all
+// responses in memory. It is only suitable for a single service instance.
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotencyStore{
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+type idempotencyEntry struct {
+	response []byte
+	end      time.Time
+}
+
+type memoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]*idempotencyEntry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func (m *memoryIdempotencyStore) Reserve(_ context.Context, key string, ttl time.Duration) (bool, []byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now, ttl)
+
+	if e, ok := m.entries[key]; ok && now.Before(e.end) {
+		return false, e.response, nil
+	}
+	m.entries[key] = &idempotencyEntry{end: now.Add(ttl)}
+	return true, nil, nil
+}
+
+func (m *memoryIdempotencyStore) Complete(_ context.Context, key string, response []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.entries[key] = &idempotencyEntry{response: response, end: m.now().Add(ttl)}
+	return nil
+}
+
+func (m *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+// sweep removes all expired entries at most once per ttl. m.mu must be held.
+func (m *memoryIdempotencyStore) sweep(now time.Time, ttl time.Duration) {
+	if now.Sub(m.lastSweep) < ttl {
+		return
+	}
+	m.lastSweep = now
+
+	for key, e := range m.entries {
+		if !now.Before(e.end) {
+			delete(m.entries, key)
+		}
+	}
+}
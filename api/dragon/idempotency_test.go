@@ -0,0 +1,112 @@
+package dragon
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+func TestService_Idempotency(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	srv := httptest.NewServer(NewHandler(newAuditProtocol(t), &Config{
+		Idempotency: &Idempotency{Store: store},
+	}, logger.MustNewStd()))
+	t.Cleanup(srv.Close)
+	c := dragonv1.NewDragonAPIProtobufClient(srv.URL, http.DefaultClient)
+
+	withKey := func(key string) context.Context {
+		ctx, err := twirp.WithHTTPRequestHeaders(context.Background(), http.Header{IdempotencyKeyHeader: []string{key}})
+		require.NoError(t, err)
+		return ctx
+	}
+
+	// retries receive the response of the first request
+	totpReq := &dragonv1.GenerateTOTPRequest{KeyRing: "keyring", AccountId: "42"}
+	first, err := c.GenerateTOTP(withKey("a"), totpReq)
+	require.NoError(t, err)
+	retry, err := c.GenerateTOTP(withKey("a"), totpReq)
+	require.NoError(t, err)
+	assert.Equal(t, first.Id, retry.Id)
+	assert.Equal(t, first.Uri, retry.Uri)
+
+	// other keys and requests without key are executed
+	other, err := c.GenerateTOTP(withKey("b"), totpReq)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Id, other.Id)
+	other, err = c.GenerateTOTP(context.Background(), totpReq)
+	require.NoError(t, err)
+	assert.NotEqual(t, first.Id, other.Id)
+
+	encReq := &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("data")}
+	ciphertext, err := c.Encrypt(withKey("a"), encReq)
+	require.NoError(t, err, "keys are scoped by method")
+	again, err := c.Encrypt(withKey("a"), encReq)
+	require.NoError(t, err)
+	assert.Equal(t, ciphertext.Ciphertext, again.Ciphertext)
+
+	// keys can't be reused for other requests
+	_, err = c.Encrypt(withKey("a"), &dragonv1.EncryptRequest{KeyRing: "keyring", Data: []byte("other")})
+	requireCode(t, twirp.InvalidArgument, err)
+
+	// failed requests aren't stored
+	_, err = c.Encrypt(withKey("c"), &dragonv1.EncryptRequest{})
+	requireCode(t, twirp.InvalidArgument, err)
+	_, err = c.GenerateDataKey(withKey("d"), &dragonv1.GenerateDataKeyRequest{KeyRing: "keyring"})
+	require.NoError(t, err)
+
+	// running requests abort retries
+	reserved, _, err := store.Reserve(context.Background(), idempotencyStoreKey("", "Encrypt", "e"), time.Minute)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	_, err = c.Encrypt(withKey("e"), encReq)
+	requireCode(t, twirp.Aborted, err)
+
+	_, err = c.Encrypt(withKey(strings.Repeat("a", maxIdempotencyKeyLength+1)), encReq)
+	requireCode(t, twirp.InvalidArgument, err)
+}
+
+func requireCode(t *testing.T, code twirp.ErrorCode, err error) {
+	var twerr twirp.Error
+	require.ErrorAs(t, err, &twerr)
+	assert.Equal(t, code, twerr.Code())
+}
+
+func TestMemoryIdempotencyStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := NewMemoryIdempotencyStore().(*memoryIdempotencyStore)
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	reserved, response, err := m.Reserve(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.True(t, reserved)
+	assert.Nil(t, response)
+	reserved, response, err = m.Reserve(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.False(t, reserved)
+	assert.Nil(t, response)
+
+	require.NoError(t, m.Complete(ctx, "a", []byte("response"), time.Minute))
+	_, response, _ = m.Reserve(ctx, "a", time.Minute)
+	assert.Equal(t, []byte("response"), response)
+
+	require.NoError(t, m.Release(ctx, "a"))
+	reserved, _, _ = m.Reserve(ctx, "a", time.Minute)
+	assert.True(t, reserved)
+
+	// expired keys are reserved again and swept
+	now = now.Add(2 * time.Minute)
+	reserved, _, _ = m.Reserve(ctx, "b", time.Minute)
+	assert.True(t, reserved)
+	assert.NotContains(t, m.entries, "a")
+}
@@ -0,0 +1,250 @@
+package dragon
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+// AttemptStore counts the failed TOTP verifications of accounts for a
+// Lockout. Failures are counted in a window, which starts with the first
+// failure of an account and has a fixed length. Implementations must be safe
+// for concurrent use. Multiple service instances must share a store (e.g.
+// RedisAttemptStore) to enforce a common limit.
+type AttemptStore interface {
+	// Failures returns the amount of failures of account in the current
+	// window and the end of the window. Without window it returns 0 and the
+	// zero time.
+	Failures(ctx context.Context, account string) (failures int, windowEnd time.Time, err error)
+	// AddFailure counts a failure of account. If account has no window, a new
+	// window of length window is started. It returns the state after the
+	// failure was counted, like Failures.
+	AddFailure(ctx context.Context, account string, window time.Duration) (failures int, windowEnd time.Time, err error)
+	// Reset removes the window and all failures of account.
+	Reset(ctx context.Context, account string) error
+}
+
+// Lockout configures the locking of accounts after too many failed TOTP
+// verifications. As dvx verifies codes statelessly, every service without
+// lockout allows unlimited online guessing of codes.
+type Lockout struct {
+	// Store counts the failures. For example: NewMemoryAttemptStore()
+	Store AttemptStore
+	// MaxFailures is the amount of failures within Window that locks an
+	// account. Defaults to 5.
+	MaxFailures int
+	// Window is the time in which failures are counted, starting with the
+	// first failure. A locked account is unlocked at the end of the window.
+	// Defaults to 15 minutes.
+	Window time.Duration
+}
+
+func (l *Lockout) maxFailures() int {
+	if l.MaxFailures <= 0 {
+		return 5
+	}
+	return l.MaxFailures
+}
+
+func (l *Lockout) window() time.Duration {
+	if l.Window <= 0 {
+		return 15 * time.Minute
+	}
+	return l.Window
+}
+
+// state returns the TOTPLockout message of failures in a window ending at
+// windowEnd.
+func (l *Lockout) state(failures int, windowEnd time.Time) *dragonv1.TOTPLockout {
+	state := &dragonv1.TOTPLockout{
+		Locked:            failures >= l.maxFailures(),
+		Failures:          int32(failures),
+		RemainingAttempts: int32(l.maxFailures() - failures),
+	}
+	if state.RemainingAttempts < 0 {
+		state.RemainingAttempts = 0
+	}
+	if !windowEnd.IsZero() {
+		state.WindowEnd = windowEnd.Unix()
+	}
+	return state
+}
+
+// lockoutAccount returns the AttemptStore account of accountID. Accounts are
+// scoped by keyRing, which already contains the tenant of the caller (see
+// tenantInterceptor).
+func lockoutAccount(keyRing string, accountID string) string {
+	return keyRing + "\x00" + accountID
+}
+
+// totpLockout returns the lockout state of account, or nil if config has no
+// TOTPLockout.
+func (s *service) totpLockout(ctx context.Context, account string) (*dragonv1.TOTPLockout, error) {
+	l := s.config.TOTPLockout
+	if l == nil {
+		return nil, nil
+	}
+
+	failures, windowEnd, err := l.Store.Failures(ctx, account)
+	if err != nil {
+		return nil, s.lockoutError(ctx, err)
+	}
+	return l.state(failures, windowEnd), nil
+}
+
+// recordTOTPAttempt resets the failures of account after a valid code and
+// counts a failure otherwise. It returns the new lockout state, or nil if
+// config has no TOTPLockout.
+func (s *service) recordTOTPAttempt(ctx context.Context, account string, valid bool) (*dragonv1.TOTPLockout, error) {
+	l := s.config.TOTPLockout
+	if l == nil {
+		return nil, nil
+	}
+
+	if valid {
+		if err := l.Store.Reset(ctx, account); err != nil {
+			return nil, s.lockoutError(ctx, err)
+		}
+		return l.state(0, time.Time{}), nil
+	}
+
+	failures, windowEnd, err := l.Store.AddFailure(ctx, account, l.window())
+	if err != nil {
+		return nil, s.lockoutError(ctx, err)
+	}
+	return l.state(failures, windowEnd), nil
+}
+
+// lockoutError logs err of an AttemptStore and returns twirp.Unavailable.
+// Verifications fail as long as the store is unavailable, as they would be
+// unlimited otherwise.
+func (s *service) lockoutError(ctx context.Context, err error) error {
+	s.logError(ctx, err)
+	return twirp.NewError(twirp.Unavailable, "dragon: TOTP attempt store unavailable")
+}
+
+func (s *service) GetTOTPLockout(ctx context.Context, req *dragonv1.GetTOTPLockoutRequest) (*dragonv1.GetTOTPLockoutResponse, error) {
+	if s.config.TOTPLockout == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "dragon: TOTP lockout is disabled")
+	}
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.AccountId == "" {
+		return nil, twirp.RequiredArgumentError("account_id")
+	}
+
+	lockout, err := s.totpLockout(ctx, lockoutAccount(req.KeyRing, req.AccountId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &dragonv1.GetTOTPLockoutResponse{Lockout: lockout}, nil
+}
+
+func (s *service) ResetTOTPLockout(ctx context.Context, req *dragonv1.ResetTOTPLockoutRequest) (*dragonv1.ResetTOTPLockoutResponse, error) {
+	if s.config.TOTPLockout == nil {
+		return nil, twirp.NewError(twirp.Unimplemented, "dragon: TOTP lockout is disabled")
+	}
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.AccountId == "" {
+		return nil, twirp.RequiredArgumentError("account_id")
+	}
+
+	if err := s.config.TOTPLockout.Store.Reset(ctx, lockoutAccount(req.KeyRing, req.AccountId)); err != nil {
+		return nil, s.lockoutError(ctx, err)
+	}
+
+	return &dragonv1.ResetTOTPLockoutResponse{}, nil
+}
+
+// NewMemoryAttemptStore creates an AttemptStore that keeps all failures in
+// memory. It is only suitable for a single service instance, as every
+// instance counts its own failures.
+func NewMemoryAttemptStore() AttemptStore {
+	return &memoryAttemptStore{
+		windows: make(map[string]*attemptWindow),
+		now:     time.Now,
+	}
+}
+
+type attemptWindow struct {
+	failures int
+	end      time.Time
+}
+
+type memoryAttemptStore struct {
+	mu        sync.Mutex
+	windows   map[string]*attemptWindow
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// window returns the unexpired window of account. m.mu must be held.
+func (m *memoryAttemptStore) window(account string, now time.Time) *attemptWindow {
+	w, ok := m.windows[account]
+	if !ok {
+		return nil
+	}
+	if !now.Before(w.end) {
+		delete(m.windows, account)
+		return nil
+	}
+	return w
+}
+
+func (m *memoryAttemptStore) Failures(_ context.Context, account string) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	w := m.window(account, m.now())
+	if w == nil {
+		return 0, time.Time{}, nil
+	}
+	return w.failures, w.end, nil
+}
+
+func (m *memoryAttemptStore) AddFailure(_ context.Context, account string, window time.Duration) (int, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.sweep(now, window)
+
+	w := m.window(account, now)
+	if w == nil {
+		w = &attemptWindow{end: now.Add(window)}
+		m.windows[account] = w
+	}
+	w.failures++
+	return w.failures, w.end, nil
+}
+
+// sweep removes all expired windows at most once per window, so accounts
+// with a single failure don't accumulate. m.mu must be held.
+func (m *memoryAttemptStore) sweep(now time.Time, window time.Duration) {
+	if now.Sub(m.lastSweep) < window {
+		return
+	}
+	m.lastSweep = now
+
+	for account, w := range m.windows {
+		if !now.Before(w.end) {
+			delete(m.windows, account)
+		}
+	}
+}
+
+func (m *memoryAttemptStore) Reset(_ context.Context, account string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.windows, account)
+	return nil
+}
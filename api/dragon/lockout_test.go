@@ -0,0 +1,191 @@
+package dragon
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx/totp"
+)
+
+func TestService_TOTPLockout(t *testing.T) {
+	c := newClient(t, &Config{DisableQRCode: true, TOTPLockout: &Lockout{Store: NewMemoryAttemptStore(), MaxFailures: 3}})
+	ctx := context.Background()
+
+	gen, err := c.GenerateTOTP(ctx, &dragonv1.GenerateTOTPRequest{KeyRing: "totp", AccountId: "a-id"})
+	require.NoError(t, err)
+	client, err := totp.ParseFromURI(gen.Uri)
+	require.NoError(t, err)
+	code, err := client.Generate()
+	require.NoError(t, err)
+	wrong := "000000"
+	if code == wrong {
+		wrong = "111111"
+	}
+
+	verify := func(code string) *dragonv1.VerifyTOTPResponse {
+		resp, err := c.VerifyTOTP(ctx, &dragonv1.VerifyTOTPRequest{KeyRing: "totp", Id: gen.Id, AccountId: "a-id", Code: code})
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := verify(wrong)
+	assert.False(t, resp.Valid)
+	assert.Equal(t, int32(1), resp.Lockout.Failures)
+	assert.Equal(t, int32(2), resp.Lockout.RemainingAttempts)
+	assert.NotZero(t, resp.Lockout.WindowEnd)
+
+	// a valid code resets the failures
+	resp = verify(code)
+	assert.True(t, resp.Valid)
+	assert.Equal(t, &dragonv1.TOTPLockout{RemainingAttempts: 3}, stripped(resp.Lockout))
+
+	for i := 0; i < 3; i++ {
+		assert.False(t, verify(wrong).Valid)
+	}
+	resp = verify(code)
+	assert.False(t, resp.Valid, "locked accounts don't verify valid codes")
+	assert.True(t, resp.Lockout.Locked)
+	assert.Equal(t, int32(0), resp.Lockout.RemainingAttempts)
+
+	batch, err := c.BatchVerifyTOTP(ctx, &dragonv1.BatchVerifyTOTPRequest{KeyRing: "totp", Ids: []string{gen.Id}, AccountId: "a-id", Code: code})
+	require.NoError(t, err)
+	assert.False(t, batch.Valid)
+	assert.True(t, batch.Lockout.Locked)
+
+	// other accounts and keyRings aren't affected
+	other, err := c.GetTOTPLockout(ctx, &dragonv1.GetTOTPLockoutRequest{KeyRing: "other", AccountId: "a-id"})
+	require.NoError(t, err)
+	assert.False(t, other.Lockout.Locked)
+
+	state, err := c.GetTOTPLockout(ctx, &dragonv1.GetTOTPLockoutRequest{KeyRing: "totp", AccountId: "a-id"})
+	require.NoError(t, err)
+	assert.True(t, state.Lockout.Locked)
+	assert.Equal(t, int32(3), state.Lockout.Failures)
+
+	_, err = c.ResetTOTPLockout(ctx, &dragonv1.ResetTOTPLockoutRequest{KeyRing: "totp", AccountId: "a-id"})
+	require.NoError(t, err)
+	assert.True(t, verify(code).Valid)
+}
+
+// stripped returns l without its WindowEnd.
+func stripped(l *dragonv1.TOTPLockout) *dragonv1.TOTPLockout {
+	return &dragonv1.TOTPLockout{Locked: l.Locked, Failures: l.Failures, RemainingAttempts: l.RemainingAttempts}
+}
+
+func TestService_TOTPLockoutDisabled(t *testing.T) {
+	c := newClient(t, nil)
+	ctx := context.Background()
+
+	_, err := c.GetTOTPLockout(ctx, &dragonv1.GetTOTPLockoutRequest{KeyRing: "totp", AccountId: "a-id"})
+	require.Error(t, err)
+	assert.Equal(t, twirp.Unimplemented, err.(twirp.Error).Code())
+}
+
+func TestMemoryAttemptStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	m := NewMemoryAttemptStore().(*memoryAttemptStore)
+	m.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	failures, end, err := m.Failures(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 0, failures)
+	assert.True(t, end.IsZero())
+
+	for i := 1; i <= 2; i++ {
+		failures, end, err = m.AddFailure(ctx, "a", time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, i, failures)
+		assert.Equal(t, time.Unix(1060, 0), end)
+	}
+
+	// the window doesn't move with later failures and expires
+	now = now.Add(59 * time.Second)
+	failures, end, _ = m.AddFailure(ctx, "a", time.Minute)
+	assert.Equal(t, 3, failures)
+	assert.Equal(t, time.Unix(1060, 0), end)
+	now = now.Add(time.Second)
+	failures, _, _ = m.Failures(ctx, "a")
+	assert.Equal(t, 0, failures)
+
+	// expired windows of other accounts are swept
+	_, _, _ = m.AddFailure(ctx, "b", time.Minute)
+	now = now.Add(2 * time.Minute)
+	_, _, _ = m.AddFailure(ctx, "c", time.Minute)
+	assert.Len(t, m.windows, 1)
+
+	require.NoError(t, m.Reset(ctx, "c"))
+	failures, _, _ = m.Failures(ctx, "c")
+	assert.Equal(t, 0, failures)
+}
+
+func TestRedisAttemptStore(t *testing.T) {
+	now := time.Unix(1000, 0)
+	type key struct {
+		failures int64
+		end      time.Time
+	}
+	keys := map[string]*key{}
+
+	// do emulates the scripts of the store
+	do := func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		if args[0] == "DEL" {
+			delete(keys, args[1].(string))
+			return int64(1), nil
+		}
+		k := keys[args[3].(string)]
+		if k != nil && !now.Before(k.end) {
+			k = nil
+		}
+		switch args[1] {
+		case redisFailures:
+			if k == nil {
+				return []interface{}{int64(0), int64(-2)}, nil
+			}
+		case redisAddFailure:
+			if k == nil {
+				k = &key{end: now.Add(time.Duration(args[4].(int64)) * time.Millisecond)}
+				keys[args[3].(string)] = k
+			}
+			k.failures++
+		default:
+			return nil, errors.New("unknown script")
+		}
+		return []interface{}{k.failures, int64(k.end.Sub(now) / time.Millisecond)}, nil
+	}
+
+	r := NewRedisAttemptStore(do, "lockout:").(*redisAttemptStore)
+	r.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	failures, end, err := r.AddFailure(ctx, "a", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+	assert.Equal(t, time.Unix(1060, 0), end)
+	assert.Contains(t, keys, "lockout:a")
+
+	now = now.Add(30 * time.Second)
+	failures, end, err = r.Failures(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+	assert.Equal(t, time.Unix(1060, 0), end)
+
+	require.NoError(t, r.Reset(ctx, "a"))
+	failures, end, err = r.Failures(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, 0, failures)
+	assert.True(t, end.IsZero())
+
+	r.do = func(ctx context.Context, args ...interface{}) (interface{}, error) {
+		return "OK", nil
+	}
+	_, _, err = r.Failures(ctx, "a")
+	assert.Error(t, err)
+}
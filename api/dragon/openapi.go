@@ -0,0 +1,247 @@
+package dragon
+
+import (
+	"encoding/json"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+// OpenAPI returns the OpenAPI 3 document of the gateway created by
+// NewGateway. The schemas are generated from the DragonAPI proto descriptors,
+// so the document always matches the served messages.
+func OpenAPI() map[string]interface{} {
+	svc := dragonv1.File_azoo_dragon_v1_dragon_api_proto.Services().ByName("DragonAPI")
+
+	schemas := map[string]interface{}{
+		"Error": gatewayErrorSchema(),
+	}
+	paths := map[string]interface{}{}
+
+	for _, r := range gatewayRoutes {
+		m := svc.Methods().ByName(protoreflect.Name(r.method))
+		addSchema(schemas, m.Input())
+		addSchema(schemas, m.Output())
+
+		paths[GatewayPathPrefix+r.path] = map[string]interface{}{
+			"post": map[string]interface{}{
+				"operationId": r.method,
+				"requestBody": map[string]interface{}{
+					"required": true,
+					"content":  jsonContent(schemaRef(m.Input())),
+				},
+				"responses": map[string]interface{}{
+					"200": map[string]interface{}{
+						"description": "OK",
+						"content":     jsonContent(schemaRef(m.Output())),
+					},
+					"default": map[string]interface{}{
+						"description": "Error",
+						"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/Error"}),
+					},
+				},
+			},
+		}
+	}
+
+	for path, method := range streamMethods {
+		paths[GatewayPathPrefix+path] = streamOperation(method)
+	}
+
+	event := (&dragonv1.AuditEvent{}).ProtoReflect().Descriptor()
+	addSchema(schemas, event)
+	paths[GatewayPathPrefix+StreamAuditPath] = auditStreamOperation(event)
+
+	return map[string]interface{}{
+		"openapi": "3.0.3",
+		"info": map[string]interface{}{
+			"title":   "DragonAPI JSON gateway",
+			"version": "v1",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+}
+
+func mustMarshalOpenAPI() []byte {
+	buf, err := json.Marshal(OpenAPI())
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+func jsonContent(schema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"application/json": map[string]interface{}{
+			"schema": schema,
+		},
+	}
+}
+
+func schemaName(md protoreflect.MessageDescriptor) string {
+	return string(md.FullName())[len(md.ParentFile().Package())+1:]
+}
+
+func schemaRef(md protoreflect.MessageDescriptor) map[string]interface{} {
+	return map[string]interface{}{"$ref": "#/components/schemas/" + schemaName(md)}
+}
+
+// addSchema adds the schema of md and all messages referenced by it to
+// schemas.
+func addSchema(schemas map[string]interface{}, md protoreflect.MessageDescriptor) {
+	name := schemaName(md)
+	if _, ok := schemas[name]; ok {
+		return
+	}
+
+	properties := map[string]interface{}{}
+	schemas[name] = map[string]interface{}{
+		"type":       "object",
+		"properties": properties,
+	}
+
+	fields := md.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+
+		schema := fieldSchema(schemas, fd)
+		if fd.IsList() {
+			schema = map[string]interface{}{
+				"type":  "array",
+				"items": schema,
+			}
+		}
+		properties[string(fd.Name())] = schema
+	}
+}
+
+func fieldSchema(schemas map[string]interface{}, fd protoreflect.FieldDescriptor) map[string]interface{} {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return map[string]interface{}{"type": "boolean"}
+	case protoreflect.StringKind:
+		return map[string]interface{}{"type": "string"}
+	case protoreflect.BytesKind:
+		return map[string]interface{}{"type": "string", "format": "byte"}
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		return map[string]interface{}{"type": "integer", "format": "int32"}
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// protojson encodes 64-bit integers as strings
+		return map[string]interface{}{"type": "string", "format": "int64"}
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return map[string]interface{}{"type": "number"}
+	case protoreflect.EnumKind:
+		return enumSchema(fd.Enum())
+	case protoreflect.MessageKind:
+		addSchema(schemas, fd.Message())
+		return schemaRef(fd.Message())
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// enumSchema returns the schema of the enum ed, whose values protojson
+// encodes by name.
+func enumSchema(ed protoreflect.EnumDescriptor) map[string]interface{} {
+	var values []string
+	ev := ed.Values()
+	for i := 0; i < ev.Len(); i++ {
+		values = append(values, string(ev.Get(i).Name()))
+	}
+	return map[string]interface{}{"type": "string", "enum": values}
+}
+
+// streamOperation returns the path item of a streaming method (see
+// serveStream), whose bodies are binary instead of JSON.
+func streamOperation(method string) map[string]interface{} {
+	binary := map[string]interface{}{
+		"application/octet-stream": map[string]interface{}{
+			"schema": map[string]interface{}{"type": "string", "format": "binary"},
+		},
+	}
+	return map[string]interface{}{
+		"post": map[string]interface{}{
+			"operationId": method,
+			"parameters": []interface{}{
+				map[string]interface{}{
+					"name":     "key_ring",
+					"in":       "query",
+					"required": true,
+					"schema":   map[string]interface{}{"type": "string"},
+				},
+			},
+			"requestBody": map[string]interface{}{
+				"required": true,
+				"content":  binary,
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "OK",
+					"content":     binary,
+				},
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/Error"}),
+				},
+			},
+		},
+	}
+}
+
+// auditStreamOperation returns the path item of the audit event stream (see
+// serveAuditStream), whose response is a line of JSON per event.
+func auditStreamOperation(event protoreflect.MessageDescriptor) map[string]interface{} {
+	query := func(name string, schema map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"name": name, "in": "query", "schema": schema}
+	}
+	return map[string]interface{}{
+		"get": map[string]interface{}{
+			"operationId": "ExportAuditEvents",
+			"parameters": []interface{}{
+				query("after", map[string]interface{}{"type": "string", "format": "int64"}),
+				query("log_id", map[string]interface{}{"type": "string"}),
+			},
+			"responses": map[string]interface{}{
+				"200": map[string]interface{}{
+					"description": "OK, an event per line",
+					"content": map[string]interface{}{
+						"application/x-ndjson": map[string]interface{}{"schema": schemaRef(event)},
+					},
+				},
+				"default": map[string]interface{}{
+					"description": "Error",
+					"content":     jsonContent(map[string]interface{}{"$ref": "#/components/schemas/Error"}),
+				},
+			},
+		},
+	}
+}
+
+func gatewayErrorSchema() map[string]interface{} {
+	str := map[string]interface{}{"type": "string"}
+	return map[string]interface{}{
+		"type":     "object",
+		"required": []string{"error"},
+		"properties": map[string]interface{}{
+			"error": map[string]interface{}{
+				"type":     "object",
+				"required": []string{"code", "message", "category", "retryable", "correlation_id"},
+				"properties": map[string]interface{}{
+					"code":           str,
+					"message":        str,
+					"reason":         str,
+					"argument":       str,
+					"category":       enumSchema(dragonv1.File_azoo_dragon_v1_dragon_api_proto.Enums().ByName("ErrorCategory")),
+					"retryable":      map[string]interface{}{"type": "boolean"},
+					"correlation_id": str,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,169 @@
+package dragon
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// RedisDoFunc executes a single Redis command and returns its reply, with
+// integers as int64 and arrays as []interface{}. It decouples
+// RedisAttemptStore from a specific Redis client. For example, with
+// github.com/go-redis/redis:
+//
+//	dragon.RedisDoFunc(func(ctx context.Context, args ...interface{}) (interface{}, error) {
+//	  return rdb.Do(ctx, args...).Result()
+//	})
+type RedisDoFunc func(ctx context.Context, args ...interface{}) (interface{}, error)
+
+// redisAddFailure increments the failures of KEYS[1] and starts a window of
+// ARGV[1] milliseconds with the first failure. Both happen atomically, so the
+// key can't be left without expiry.
+const redisAddFailure = `local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {n, redis.call('PTTL', KEYS[1])}`
+
+// redisFailures returns the failures of KEYS[1] and the remaining time of its
+// window in milliseconds.
+const redisFailures = `local n = redis.call('GET', KEYS[1])
+if not n then
+  return {0, -2}
+end
+return {tonumber(n), redis.call('PTTL', KEYS[1])}`
+
+// NewRedisAttemptStore creates an AttemptStore that counts failures in Redis,
+// so all service instances using the same Redis enforce a common limit. Every
+// account is stored as a single key, which expires with its window, under
+// keyPrefix. For example: "dragon:totp-lockout:"
+func NewRedisAttemptStore(do RedisDoFunc, keyPrefix string) AttemptStore {
+	return &redisAttemptStore{
+		do:        do,
+		keyPrefix: keyPrefix,
+		now:       time.Now,
+	}
+}
+
+type redisAttemptStore struct {
+	do        RedisDoFunc
+	keyPrefix string
+	now       func() time.Time
+}
+
+func (r *redisAttemptStore) Failures(ctx context.Context, account string) (int, time.Time, error) {
+	reply, err := r.do(ctx, "EVAL", redisFailures, 1, r.keyPrefix+account)
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("dragon: redis failures of account failed: %w", err)
+	}
+	return r.window(reply)
+}
+
+func (r *redisAttemptStore) AddFailure(ctx context.Context, account string, window time.Duration) (int, time.Time, error) {
+	reply, err := r.do(ctx, "EVAL", redisAddFailure, 1, r.keyPrefix+account, window.Milliseconds())
+	if err != nil {
+		return 0, time.Time{}, fmt.Errorf("dragon: redis add failure of account failed: %w", err)
+	}
+	return r.window(reply)
+}
+
+func (r *redisAttemptStore) Reset(ctx context.Context, account string) error {
+	if _, err := r.do(ctx, "DEL", r.keyPrefix+account); err != nil {
+		return fmt.Errorf("dragon: redis reset of account failed: %w", err)
+	}
+	return nil
+}
+
+// window converts the {failures, pttl} reply of the scripts.
+func (r *redisAttemptStore) window(reply interface{}) (int, time.Time, error) {
+	values, ok := reply.([]interface{})
+	if !ok || len(values) != 2 {
+		return 0, time.Time{}, fmt.Errorf("dragon: unexpected redis reply %v", reply)
+	}
+	failures, ok1 := values[0].(int64)
+	ttl, ok2 := values[1].(int64)
+	if !ok1 || !ok2 {
+		return 0, time.Time{}, fmt.Errorf("dragon: unexpected redis reply %v", reply)
+	}
+
+	// a negative ttl means the key expired between the commands
+	if failures == 0 || ttl < 0 {
+		return 0, time.Time{}, nil
+	}
+	return int(failures), r.now().Add(time.Duration(ttl) * time.Millisecond), nil
+}
+
+// NewRedisIdempotencyStore creates an IdempotencyStore that stores responses
+// in Redis, so retries reaching any service instance using the same Redis are
+// deduplicated. Every key is stored as a single Redis key, which expires with
+// its ttl, under keyPrefix. Reservations are stored as empty value. For
+// example: "dragon:idempotency:"
+//
+// do must return nil replies as nil without error. For example, with
+// github.com/go-redis/redis:
+//
+//	func(ctx context.Context, args ...interface{}) (interface{}, error) {
+//	  reply, err := rdb.Do(ctx, args...).Result()
+//	  if err == redis.Nil {
+//	    return nil, nil
+//	  }
+//	  return reply, err
+//	}
+func NewRedisIdempotencyStore(do RedisDoFunc, keyPrefix string) IdempotencyStore {
+	return &redisIdempotencyStore{
+		do:        do,
+		keyPrefix: keyPrefix,
+	}
+}
+
+type redisIdempotencyStore struct {
+	do        RedisDoFunc
+	keyPrefix string
+}
+
+func (r *redisIdempotencyStore) Reserve(ctx context.Context, key string, ttl time.Duration) (bool, []byte, error) {
+	reply, err := r.do(ctx, "SET", r.keyPrefix+key, "", "NX", "PX", ttl.Milliseconds())
+	if err != nil {
+		return false, nil, fmt.Errorf("dragon: redis reserve of idempotency key failed: %w", err)
+	}
+	if reply != nil {
+		return true, nil, nil
+	}
+
+	// the key exists, unless it expired in the meantime, which is handled
+	// like a running request
+	reply, err = r.do(ctx, "GET", r.keyPrefix+key)
+	if err != nil {
+		return false, nil, fmt.Errorf("dragon: redis get of idempotency key failed: %w", err)
+	}
+	switch v := reply.(type) {
+	case nil:
+		return false, nil, nil
+	case string:
+		if v == "" {
+			return false, nil, nil
+		}
+		return false, []byte(v), nil
+	case []byte:
+		if len(v) == 0 {
+			return false, nil, nil
+		}
+		return false, v, nil
+	default:
+		return false, nil, fmt.Errorf("dragon: unexpected redis reply %v", reply)
+	}
+}
+
+func (r *redisIdempotencyStore) Complete(ctx context.Context, key string, response []byte, ttl time.Duration) error {
+	if _, err := r.do(ctx, "SET", r.keyPrefix+key, response, "PX", ttl.Milliseconds()); err != nil {
+		return fmt.Errorf("dragon: redis complete of idempotency key failed: %w", err)
+	}
+	return nil
+}
+
+func (r *redisIdempotencyStore) Release(ctx context.Context, key string) error {
+	if _, err := r.do(ctx, "DEL", r.keyPrefix+key); err != nil {
+		return fmt.Errorf("dragon: redis release of idempotency key failed: %w", err)
+	}
+	return nil
+}
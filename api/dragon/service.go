@@ -0,0 +1,262 @@
+package dragon
+
+import (
+	"context"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/twitchtv/twirp"
+	"google.golang.org/protobuf/proto"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/qr"
+)
+
+type service struct {
+	p      *dvx.Protocol
+	config *Config
+	log    logger.Logger
+}
+
+func (s *service) CreateKey(ctx context.Context, req *dragonv1.CreateKeyRequest) (*dragonv1.CreateKeyResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+
+	switch req.Type {
+	case dragonv1.CreateKeyRequest_TYPE_ENCRYPTION:
+		// keys are derived on demand -> nothing to create
+		return &dragonv1.CreateKeyResponse{EncryptionKey: &dragonv1.CreateKeyResponse_EncryptionKey{}}, nil
+	case dragonv1.CreateKeyRequest_TYPE_SIGNING:
+		publicKey, err := s.p.CreateSignKeyContext(ctx, req.KeyRing)
+		if err != nil {
+			return nil, s.twirpError(ctx, err)
+		}
+		return &dragonv1.CreateKeyResponse{SigningKey: &dragonv1.CreateKeyResponse_SigningKey{PublicKey: publicKey}}, nil
+	case dragonv1.CreateKeyRequest_TYPE_MAC:
+		// keys are derived on demand -> nothing to create
+		return &dragonv1.CreateKeyResponse{MacKey: &dragonv1.CreateKeyResponse_MACKey{}}, nil
+	default:
+		return nil, twirp.InvalidArgumentError("type", "unknown key type")
+	}
+}
+
+func (s *service) Encrypt(ctx context.Context, req *dragonv1.EncryptRequest) (*dragonv1.EncryptResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+
+	resp, err := s.idempotent(ctx, req, &dragonv1.EncryptResponse{}, func() (proto.Message, error) {
+		ciphertext, err := encrypt(ctx, s.p, req)
+		if err != nil {
+			return nil, s.twirpError(ctx, err)
+		}
+		return &dragonv1.EncryptResponse{Ciphertext: ciphertext}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*dragonv1.EncryptResponse), nil
+}
+
+// encrypt encrypts the data of req with EncryptWithFooterContext if it has a
+// footer, otherwise with EncryptContext.
+func encrypt(ctx context.Context, p *dvx.Protocol, req *dragonv1.EncryptRequest) (string, error) {
+	if len(req.Footer) > 0 {
+		return p.EncryptWithFooterContext(ctx, req.KeyRing, req.Data, req.Footer)
+	}
+	return p.EncryptContext(ctx, req.KeyRing, req.Data)
+}
+
+func (s *service) Decrypt(ctx context.Context, req *dragonv1.DecryptRequest) (*dragonv1.DecryptResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.Ciphertext == "" {
+		return nil, twirp.RequiredArgumentError("ciphertext")
+	}
+
+	data, err := s.p.DecryptContext(ctx, req.KeyRing, req.Ciphertext)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+
+	return &dragonv1.DecryptResponse{Data: data}, nil
+}
+
+func (s *service) MAC(ctx context.Context, req *dragonv1.MACRequest) (*dragonv1.MACResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+
+	tag, err := s.p.MACContext(ctx, req.KeyRing, req.Message)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+
+	return &dragonv1.MACResponse{Tag: tag}, nil
+}
+
+func (s *service) Sign(ctx context.Context, req *dragonv1.SignRequest) (*dragonv1.SignResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+
+	signature, rawSignature, err := s.p.SignContext(ctx, req.KeyRing, req.Message)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+
+	return &dragonv1.SignResponse{Signature: signature, RawSignature: rawSignature}, nil
+}
+
+func (s *service) Verify(ctx context.Context, req *dragonv1.VerifyRequest) (*dragonv1.VerifyResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.Signature == "" {
+		return nil, twirp.RequiredArgumentError("signature")
+	}
+
+	valid, err := s.p.VerifyContext(ctx, req.KeyRing, req.Message, req.Signature)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+
+	return &dragonv1.VerifyResponse{Valid: valid}, nil
+}
+
+func (s *service) VerifyPK(ctx context.Context, req *dragonv1.VerifyPKRequest) (*dragonv1.VerifyPKResponse, error) {
+	if len(req.PublicKey) == 0 {
+		return nil, twirp.RequiredArgumentError("public_key")
+	}
+	if req.Signature == "" {
+		return nil, twirp.RequiredArgumentError("signature")
+	}
+
+	valid, err := s.p.VerifyPK(req.PublicKey, req.Message, req.Signature)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+
+	return &dragonv1.VerifyPKResponse{Valid: valid}, nil
+}
+
+func (s *service) GenerateTOTP(ctx context.Context, req *dragonv1.GenerateTOTPRequest) (*dragonv1.GenerateTOTPResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.AccountId == "" {
+		return nil, twirp.RequiredArgumentError("account_id")
+	}
+
+	resp, err := s.idempotent(ctx, req, &dragonv1.GenerateTOTPResponse{}, func() (proto.Message, error) {
+		id, uri, err := s.p.GenerateTOTPContext(ctx, req.KeyRing, req.Issuer, req.AccountName, req.AccountId)
+		if err != nil {
+			return nil, s.twirpError(ctx, err)
+		}
+
+		resp := &dragonv1.GenerateTOTPResponse{Id: id, Uri: uri}
+		if !s.config.DisableQRCode {
+			resp.QrCode, err = qr.PNGDataURI(uri)
+			if err != nil {
+				return nil, s.twirpError(ctx, err)
+			}
+		}
+		return resp, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.(*dragonv1.GenerateTOTPResponse), nil
+}
+
+func (s *service) VerifyTOTP(ctx context.Context, req *dragonv1.VerifyTOTPRequest) (*dragonv1.VerifyTOTPResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.Id == "" {
+		return nil, twirp.RequiredArgumentError("id")
+	}
+	if req.AccountId == "" {
+		return nil, twirp.RequiredArgumentError("account_id")
+	}
+
+	account := lockoutAccount(req.KeyRing, req.AccountId)
+	lockout, err := s.totpLockout(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if lockout.GetLocked() {
+		return &dragonv1.VerifyTOTPResponse{Lockout: lockout}, nil
+	}
+
+	valid, err := s.p.VerifyTOTPContext(ctx, req.KeyRing, req.Id, req.AccountId, req.Code)
+	if err != nil {
+		return nil, s.twirpError(ctx, err)
+	}
+
+	lockout, err = s.recordTOTPAttempt(ctx, account, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dragonv1.VerifyTOTPResponse{Valid: valid, Lockout: lockout}, nil
+}
+
+func (s *service) BatchVerifyTOTP(ctx context.Context, req *dragonv1.BatchVerifyTOTPRequest) (*dragonv1.BatchVerifyTOTPResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if len(req.Ids) == 0 {
+		return nil, twirp.RequiredArgumentError("ids")
+	}
+	if req.AccountId == "" {
+		return nil, twirp.RequiredArgumentError("account_id")
+	}
+
+	account := lockoutAccount(req.KeyRing, req.AccountId)
+	lockout, err := s.totpLockout(ctx, account)
+	if err != nil {
+		return nil, err
+	}
+	if lockout.GetLocked() {
+		return &dragonv1.BatchVerifyTOTPResponse{Lockout: lockout}, nil
+	}
+
+	// verify all ids, even after a match was found, so the response time
+	// doesn't leak the position of the matching id
+	resp := &dragonv1.BatchVerifyTOTPResponse{}
+	for _, id := range req.Ids {
+		valid, err := s.p.VerifyTOTPContext(ctx, req.KeyRing, id, req.AccountId, req.Code)
+		if err != nil {
+			return nil, s.twirpError(ctx, err)
+		}
+		if valid && !resp.Valid {
+			resp.Valid = true
+			resp.ValidThroughId = id
+		}
+	}
+
+	resp.Lockout, err = s.recordTOTPAttempt(ctx, account, resp.Valid)
+	if err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
+
+func (s *service) DeleteTOTP(_ context.Context, req *dragonv1.DeleteTOTPRequest) (*dragonv1.DeleteTOTPResponse, error) {
+	if req.KeyRing == "" {
+		return nil, twirp.RequiredArgumentError("key_ring")
+	}
+	if req.Id == "" {
+		return nil, twirp.RequiredArgumentError("id")
+	}
+
+	// all TOTP secrets are derived dynamically from the id -> nothing to
+	// delete. The caller must delete its stored id.
+	return &dragonv1.DeleteTOTPResponse{}, nil
+}
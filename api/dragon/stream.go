@@ -0,0 +1,184 @@
+package dragon
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/twitchtv/twirp"
+	"github.com/twitchtv/twirp/ctxsetters"
+
+	dragonv1 "azoo.dev/api/generated/dragonv1-twirpgo"
+)
+
+const (
+	// StreamEncryptPath and StreamDecryptPath are the paths of the streaming
+	// methods of the gateway, relative to GatewayPathPrefix.
+	StreamEncryptPath = "stream/encrypt"
+	StreamDecryptPath = "stream/decrypt"
+	// StreamAuditPath is the path of the audit event stream of the gateway
+	// (see Config.AuditLog), relative to GatewayPathPrefix.
+	StreamAuditPath = "stream/audit"
+
+	// maxStreamLineSize limits the lines of dvxfile streams accepted by
+	// DecryptStream. A chunk of dvx.FileChunkSize bytes is encoded into a line
+	// of less than 90KiB.
+	maxStreamLineSize = 128 << 10
+)
+
+// streamMethods maps the paths of the streaming methods to their method
+// names, which are used like RPC method names in Policies, Timeouts and
+// Interceptors.
+var streamMethods = map[string]string{
+	StreamEncryptPath: "EncryptStream",
+	StreamDecryptPath: "DecryptStream",
+}
+
+// serveStream serves the streaming methods of the gateway. Twirp has no
+// streaming RPCs, so they are plain http requests with the keyRing as query
+// parameter "key_ring": EncryptStream encrypts the request body into a dvxfile
+// (see dvx.Protocol.NewFileWriter) and DecryptStream decrypts a dvxfile
+// request body, both chunk by chunk without buffering the payload.
+//
+// The interceptors (authorization, tenant, deadline and Config.Interceptors)
+// run before the payload is processed, so deadlines don't limit the transfer.
+// Errors before the response started are returned like all gateway errors,
+// later errors abort the response, so clients receive a truncated body and an
+// error instead of a complete response.
+func (g *gateway) serveStream(w http.ResponseWriter, r *http.Request, path string) {
+	method := streamMethods[path]
+	if r.Method != http.MethodPost {
+		g.writeError(r.Context(), w, twirp.NewErrorf(twirp.BadRoute, "dragon: %s must be called with POST", r.URL.Path))
+		return
+	}
+
+	ctx := withTLSCaller(r).Context()
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, method)
+
+	keyRing, err := g.streamKeyRing(ctx, method, r.URL.Query().Get("key_ring"))
+	if err != nil {
+		g.writeError(ctx, w, err)
+		return
+	}
+
+	// HTTP/1.x servers close the request body as soon as the response is
+	// flushed, unless full duplex is enabled (Go 1.21+). HTTP/2 is always
+	// full duplex.
+	enableFullDuplex(w)
+
+	switch method {
+	case "EncryptStream":
+		g.encryptStream(ctx, w, r.Body, keyRing)
+	case "DecryptStream":
+		g.decryptStream(ctx, w, r.Body, keyRing)
+	}
+}
+
+// streamKeyRing runs the interceptors for a streaming method and returns the
+// keyRing as seen by the Protocol (e.g. namespaced with the caller's tenant).
+func (g *gateway) streamKeyRing(ctx context.Context, method string, keyRing string) (string, error) {
+	var req interface{ GetKeyRing() string }
+	if method == "EncryptStream" {
+		req = &dragonv1.EncryptRequest{KeyRing: keyRing}
+	} else {
+		req = &dragonv1.DecryptRequest{KeyRing: keyRing}
+	}
+
+	resp, err := g.intercept(func(ctx context.Context, req interface{}) (interface{}, error) {
+		if req.(interface{ GetKeyRing() string }).GetKeyRing() == "" {
+			return nil, twirp.RequiredArgumentError("key_ring")
+		}
+		return req, nil
+	})(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	return resp.(interface{ GetKeyRing() string }).GetKeyRing(), nil
+}
+
+func (g *gateway) encryptStream(ctx context.Context, w http.ResponseWriter, body io.Reader, keyRing string) {
+	// NewFileWriter writes the header of the dvxfile only after the key was
+	// derived, so errors of the derivation can still be returned as JSON
+	w.Header().Set("Content-Type", "application/octet-stream")
+	fw, err := g.svc.p.NewFileWriter(ctx, w, keyRing)
+	if err != nil {
+		g.writeError(ctx, w, g.svc.twirpError(ctx, err))
+		return
+	}
+
+	if _, err = io.Copy(fw, body); err == nil {
+		err = fw.Close()
+	}
+	if err != nil {
+		g.abortStream(ctx, err)
+	}
+}
+
+func (g *gateway) decryptStream(ctx context.Context, w http.ResponseWriter, body io.Reader, keyRing string) {
+	fr, err := g.svc.p.NewFileReader(ctx, &lineLimitReader{r: body, limit: maxStreamLineSize}, keyRing)
+	if errors.Is(err, errLineTooLong) {
+		g.writeError(ctx, w, twirp.NewError(twirp.InvalidArgument, err.Error()).WithMeta("reason", ReasonInvalidFormat))
+		return
+	}
+	if err != nil {
+		g.writeError(ctx, w, g.svc.twirpError(ctx, err))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	if _, err = io.Copy(w, fr); err != nil {
+		g.abortStream(ctx, err)
+	}
+}
+
+// abortStream logs err and aborts the response of a streaming method.
+func (g *gateway) abortStream(ctx context.Context, err error) {
+	g.svc.logError(ctx, err)
+	panic(http.ErrAbortHandler)
+}
+
+// enableFullDuplex enables full duplex for the HTTP/1.x response w, like
+// http.ResponseController.EnableFullDuplex.
+func enableFullDuplex(w http.ResponseWriter) {
+	for {
+		if d, ok := w.(interface{ EnableFullDuplex() error }); ok {
+			_ = d.EnableFullDuplex()
+			return
+		}
+		u, ok := w.(interface{ Unwrap() http.ResponseWriter })
+		if !ok {
+			return
+		}
+		w = u.Unwrap()
+	}
+}
+
+// errLineTooLong is returned by lineLimitReader.
+var errLineTooLong = errors.New("dragon: line of stream exceeds limit")
+
+// lineLimitReader fails if a line of r exceeds limit bytes, so readers that
+// buffer whole lines (like dvx.Protocol.NewFileReader) can't be forced to
+// buffer an unlimited amount of data.
+type lineLimitReader struct {
+	r     io.Reader
+	limit int
+	line  int
+}
+
+func (l *lineLimitReader) Read(p []byte) (n int, err error) {
+	n, err = l.r.Read(p)
+	for _, b := range p[:n] {
+		if b == '\n' {
+			l.line = 0
+			continue
+		}
+		l.line++
+		if l.line > l.limit {
+			return 0, errLineTooLong
+		}
+	}
+	return n, err
+}
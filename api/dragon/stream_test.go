@@ -0,0 +1,74 @@
+package dragon
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGateway_Stream(t *testing.T) {
+	srv := newGateway(t)
+
+	// larger than the buffers of the http server, so the response is flushed
+	// while the request body is still being read
+	data := make([]byte, 3<<20+17)
+	_, err := io.ReadFull(rand.Reader, data)
+	require.NoError(t, err)
+
+	resp, err := http.Post(srv.URL+GatewayPathPrefix+StreamEncryptPath+"?key_ring=keyring", "application/octet-stream", bytes.NewReader(data))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	encrypted, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(encrypted, []byte("dvxfile v1 ")))
+
+	resp, err = http.Post(srv.URL+GatewayPathPrefix+StreamDecryptPath+"?key_ring=keyring", "application/octet-stream", bytes.NewReader(encrypted))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	decrypted, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, data, decrypted)
+
+	// a truncated stream is detected after the chunks before were returned
+	truncated := encrypted[:bytes.LastIndexByte(encrypted[:len(encrypted)-1], '\n')+1]
+	resp, err = http.Post(srv.URL+GatewayPathPrefix+StreamDecryptPath+"?key_ring=keyring", "application/octet-stream", bytes.NewReader(truncated))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	_, err = io.ReadAll(resp.Body)
+	assert.Error(t, err)
+}
+
+func TestGateway_StreamErrors(t *testing.T) {
+	srv := newGateway(t)
+
+	postStream := func(path string, body string) (int, GatewayError) {
+		resp, err := http.Post(srv.URL+GatewayPathPrefix+path, "application/octet-stream", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var e GatewayError
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&e))
+		return resp.StatusCode, e
+	}
+
+	status, e := postStream(StreamEncryptPath, "data")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, "key_ring", e.Error.Argument)
+
+	status, e = postStream(StreamDecryptPath+"?key_ring=keyring", "no dvxfile\n")
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, ReasonInvalidFormat, e.Error.Reason)
+
+	status, e = postStream(StreamDecryptPath+"?key_ring=keyring", strings.Repeat("a", maxStreamLineSize+1))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, ReasonInvalidFormat, e.Error.Reason)
+}
@@ -0,0 +1,140 @@
+package telemetry
+
+import (
+	"context"
+	"io"
+
+	"go.opentelemetry.io/otel/trace"
+
+	"azoo.dev/api/dragon/client"
+)
+
+// WrapCrypto instruments every operation of c, like ServerInterceptor does
+// for the dragon service. It is meant for in-process Protocol instances
+// created with (azoo.dev/api/dragon/client).NewLocal. Remote clients should
+// use ClientInterceptor instead, which also propagates the trace context.
+func WrapCrypto(c client.Crypto, config *Config) (client.Crypto, error) {
+	in, err := newInstruments(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return &crypto{c: c, in: in}, nil
+}
+
+type crypto struct {
+	c  client.Crypto
+	in *instruments
+}
+
+func (c *crypto) observe(ctx context.Context, method string, op func(ctx context.Context) error) error {
+	return c.in.observe(ctx, method, trace.SpanKindInternal, "local", op)
+}
+
+func (c *crypto) Encrypt(ctx context.Context, keyRing string, data []byte) (ciphertext string, err error) {
+	err = c.observe(ctx, "Encrypt", func(ctx context.Context) error {
+		ciphertext, err = c.c.Encrypt(ctx, keyRing, data)
+		return err
+	})
+	return
+}
+
+func (c *crypto) EncryptWithFooter(ctx context.Context, keyRing string, data []byte, footer []byte) (ciphertext string, err error) {
+	err = c.observe(ctx, "Encrypt", func(ctx context.Context) error {
+		ciphertext, err = c.c.EncryptWithFooter(ctx, keyRing, data, footer)
+		return err
+	})
+	return
+}
+
+func (c *crypto) Decrypt(ctx context.Context, keyRing string, ciphertext string) (data []byte, err error) {
+	err = c.observe(ctx, "Decrypt", func(ctx context.Context) error {
+		data, err = c.c.Decrypt(ctx, keyRing, ciphertext)
+		return err
+	})
+	return
+}
+
+func (c *crypto) GenerateDataKey(ctx context.Context, keyRing string) (plaintext []byte, wrappedKey string, err error) {
+	err = c.observe(ctx, "GenerateDataKey", func(ctx context.Context) error {
+		plaintext, wrappedKey, err = c.c.GenerateDataKey(ctx, keyRing)
+		return err
+	})
+	return
+}
+
+func (c *crypto) DecryptDataKey(ctx context.Context, keyRing string, wrappedKey string) (plaintext []byte, err error) {
+	err = c.observe(ctx, "DecryptDataKey", func(ctx context.Context) error {
+		plaintext, err = c.c.DecryptDataKey(ctx, keyRing, wrappedKey)
+		return err
+	})
+	return
+}
+
+func (c *crypto) EncryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error {
+	return c.observe(ctx, "EncryptStream", func(ctx context.Context) error {
+		return c.c.EncryptStream(ctx, keyRing, dst, src)
+	})
+}
+
+func (c *crypto) DecryptStream(ctx context.Context, keyRing string, dst io.Writer, src io.Reader) error {
+	return c.observe(ctx, "DecryptStream", func(ctx context.Context) error {
+		return c.c.DecryptStream(ctx, keyRing, dst, src)
+	})
+}
+
+func (c *crypto) CreateSignKey(ctx context.Context, keyRing string) (publicKey []byte, err error) {
+	err = c.observe(ctx, "CreateKey", func(ctx context.Context) error {
+		publicKey, err = c.c.CreateSignKey(ctx, keyRing)
+		return err
+	})
+	return
+}
+
+func (c *crypto) Sign(ctx context.Context, keyRing string, message []byte) (signature string, rawSignature []byte, err error) {
+	err = c.observe(ctx, "Sign", func(ctx context.Context) error {
+		signature, rawSignature, err = c.c.Sign(ctx, keyRing, message)
+		return err
+	})
+	return
+}
+
+func (c *crypto) Verify(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error) {
+	err = c.observe(ctx, "Verify", func(ctx context.Context) error {
+		valid, err = c.c.Verify(ctx, keyRing, message, signature)
+		return err
+	})
+	return
+}
+
+func (c *crypto) VerifyPK(ctx context.Context, publicKey []byte, message []byte, signature string) (valid bool, err error) {
+	err = c.observe(ctx, "VerifyPK", func(ctx context.Context) error {
+		valid, err = c.c.VerifyPK(ctx, publicKey, message, signature)
+		return err
+	})
+	return
+}
+
+func (c *crypto) MAC(ctx context.Context, keyRing string, message []byte) (tag string, err error) {
+	err = c.observe(ctx, "MAC", func(ctx context.Context) error {
+		tag, err = c.c.MAC(ctx, keyRing, message)
+		return err
+	})
+	return
+}
+
+func (c *crypto) GenerateTOTP(ctx context.Context, keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error) {
+	err = c.observe(ctx, "GenerateTOTP", func(ctx context.Context) error {
+		id, uri, err = c.c.GenerateTOTP(ctx, keyRing, issuer, accountName, accountID)
+		return err
+	})
+	return
+}
+
+func (c *crypto) VerifyTOTP(ctx context.Context, keyRing string, id string, accountID string, code string) (valid bool, err error) {
+	err = c.observe(ctx, "VerifyTOTP", func(ctx context.Context) error {
+		valid, err = c.c.VerifyTOTP(ctx, keyRing, id, accountID, code)
+		return err
+	})
+	return
+}
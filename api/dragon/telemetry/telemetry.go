@@ -0,0 +1,210 @@
+// Package telemetry provides OpenTelemetry instrumentation for the dragon
+// service (azoo.dev/api/dragon) and its clients (azoo.dev/api/dragon/client).
+//
+// Every dvx operation is recorded as span and in the "dragon.rpc.duration"
+// histogram, so crypto latency shows up in existing distributed traces. If the
+// Protocol uses a tearc KeyPool (azoo.dev/utils/dvx/tearc), spans carry the
+// "dvx.kdf.cache_hit" attribute and cache misses are counted in
+// "dvx.kdf.cache_misses".
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	"azoo.dev/utils/dvx/tearc"
+)
+
+const instrumentationName = "azoo.dev/api/dragon/telemetry"
+
+// Config provides all options for the instrumentation. All fields are
+// optional.
+type Config struct {
+	// TracerProvider creates the tracer of all spans. Defaults to
+	// otel.GetTracerProvider().
+	TracerProvider trace.TracerProvider
+	// MeterProvider creates the meter of all metrics. Defaults to
+	// otel.GetMeterProvider().
+	MeterProvider metric.MeterProvider
+	// Propagator extracts and injects the trace context of http requests.
+	// Defaults to otel.GetTextMapPropagator().
+	Propagator propagation.TextMapPropagator
+}
+
+// instruments holds the tracer and metric instruments shared by all
+// interceptors and wrappers.
+type instruments struct {
+	tracer      trace.Tracer
+	propagator  propagation.TextMapPropagator
+	duration    metric.Float64Histogram
+	cacheMisses metric.Int64Counter
+}
+
+func newInstruments(config *Config) (*instruments, error) {
+	c := Config{}
+	if config != nil {
+		c = *config
+	}
+	if c.TracerProvider == nil {
+		c.TracerProvider = otel.GetTracerProvider()
+	}
+	if c.MeterProvider == nil {
+		c.MeterProvider = otel.GetMeterProvider()
+	}
+	if c.Propagator == nil {
+		c.Propagator = otel.GetTextMapPropagator()
+	}
+
+	meter := c.MeterProvider.Meter(instrumentationName)
+
+	duration, err := meter.Float64Histogram("dragon.rpc.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of dvx operations"))
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMisses, err := meter.Int64Counter("dvx.kdf.cache_misses",
+		metric.WithDescription("Key derivations that missed the tearc cache"))
+	if err != nil {
+		return nil, err
+	}
+
+	return &instruments{
+		tracer:      c.TracerProvider.Tracer(instrumentationName),
+		propagator:  c.Propagator,
+		duration:    duration,
+		cacheMisses: cacheMisses,
+	}, nil
+}
+
+// observe runs op inside a span named after method and records its duration.
+// kind is the span kind, side is the value of the "rpc.side" attribute.
+func (in *instruments) observe(ctx context.Context, method string, kind trace.SpanKind, side string, op func(ctx context.Context) error) error {
+	ctx, span := in.tracer.Start(ctx, "azoo.dragon.v1.DragonAPI/"+method,
+		trace.WithSpanKind(kind),
+		trace.WithAttributes(
+			attribute.String("rpc.system", "twirp"),
+			attribute.String("rpc.service", "azoo.dragon.v1.DragonAPI"),
+			attribute.String("rpc.method", method),
+		))
+	defer span.End()
+
+	var misses int64
+	ctx = tearc.WithLoadObserver(ctx, func() {
+		atomic.AddInt64(&misses, 1)
+	})
+
+	start := time.Now()
+	err := op(ctx)
+	elapsed := time.Since(start)
+
+	code := "ok"
+	if err != nil {
+		code = "unknown"
+		if twerr, ok := err.(twirp.Error); ok {
+			code = string(twerr.Code())
+		}
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	misses = atomic.LoadInt64(&misses)
+	span.SetAttributes(
+		attribute.String("rpc.twirp.code", code),
+		attribute.Bool("dvx.kdf.cache_hit", misses == 0),
+		attribute.Int64("dvx.kdf.cache_misses", misses),
+	)
+
+	attrs := metric.WithAttributes(
+		attribute.String("rpc.method", method),
+		attribute.String("rpc.side", side),
+		attribute.String("rpc.twirp.code", code),
+	)
+	in.duration.Record(ctx, elapsed.Seconds(), attrs)
+	if misses > 0 {
+		in.cacheMisses.Add(ctx, misses, metric.WithAttributes(attribute.String("rpc.method", method)))
+	}
+
+	return err
+}
+
+// ServerInterceptor returns a twirp.Interceptor that instruments every method
+// of the dragon service. Pass it as first element of
+// (azoo.dev/api/dragon).Config.Interceptors, so the span covers the
+// authorization and deadline interceptors as well.
+func ServerInterceptor(config *Config) (twirp.Interceptor, error) {
+	in, err := newInstruments(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (resp interface{}, err error) {
+			method, _ := twirp.MethodName(ctx)
+			err = in.observe(ctx, method, trace.SpanKindServer, "server", func(ctx context.Context) error {
+				resp, err = next(ctx, req)
+				return err
+			})
+			return resp, err
+		}
+	}, nil
+}
+
+// Handler extracts the trace context of incoming requests, so spans created
+// by ServerInterceptor continue the trace of the caller. Wrap the handlers
+// of the dragon service with it.
+func Handler(h http.Handler, config *Config) http.Handler {
+	propagator := otel.GetTextMapPropagator()
+	if config != nil && config.Propagator != nil {
+		propagator = config.Propagator
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		h.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// ClientInterceptor returns a twirp.Interceptor that instruments every call of
+// a dragon client and injects the trace context into the request headers.
+// Pass it to (azoo.dev/api/dragon/client).Config.Interceptors.
+func ClientInterceptor(config *Config) (twirp.Interceptor, error) {
+	in, err := newInstruments(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(next twirp.Method) twirp.Method {
+		return func(ctx context.Context, req interface{}) (resp interface{}, err error) {
+			method, _ := twirp.MethodName(ctx)
+			err = in.observe(ctx, method, trace.SpanKindClient, "client", func(ctx context.Context) error {
+				header, _ := twirp.HTTPRequestHeaders(ctx)
+				header = header.Clone()
+				if header == nil {
+					header = make(http.Header)
+				}
+				in.propagator.Inject(ctx, propagation.HeaderCarrier(header))
+
+				ctx, err = twirp.WithHTTPRequestHeaders(ctx, header)
+				if err != nil {
+					return err
+				}
+
+				resp, err = next(ctx, req)
+				return err
+			})
+			return resp, err
+		}
+	}, nil
+}
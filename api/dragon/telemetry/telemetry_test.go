@@ -0,0 +1,106 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	logger "github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/twitchtv/twirp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"azoo.dev/api/dragon"
+	"azoo.dev/api/dragon/client"
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/liblog"
+	"azoo.dev/utils/dvx/tearc"
+)
+
+func newProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	log := logger.MustNewStd()
+	pool, err := tearc.New(&tearc.Config{
+		Size:          128,
+		Shards:        1,
+		BucketMinTick: time.Second,
+		BucketMaxTick: 2 * time.Second,
+		AliveTime:     time.Minute,
+	}, dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, liblog.Wrap(log)), liblog.Wrap(log))
+	require.NoError(t, err)
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool})
+}
+
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+	return attrs
+}
+
+func TestServerAndClientInterceptor(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	config := &Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+		Propagator:     propagation.TraceContext{},
+	}
+
+	serverInterceptor, err := ServerInterceptor(config)
+	require.NoError(t, err)
+	clientInterceptor, err := ClientInterceptor(config)
+	require.NoError(t, err)
+
+	h := dragon.NewHandler(newProtocol(t), &dragon.Config{Interceptors: []twirp.Interceptor{serverInterceptor}}, logger.MustNewStd())
+	srv := httptest.NewServer(Handler(h, config))
+	defer srv.Close()
+
+	c := client.New(&client.Config{BaseURL: srv.URL, Interceptors: []twirp.Interceptor{clientInterceptor}})
+	ctx := context.Background()
+
+	_, err = c.MAC(ctx, "keyring", []byte("message"))
+	require.NoError(t, err)
+	_, err = c.MAC(ctx, "keyring", []byte("message"))
+	require.NoError(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+
+	// server spans end before client spans
+	server1, client1, server2 := spans[0], spans[1], spans[2]
+	assert.Equal(t, "azoo.dragon.v1.DragonAPI/MAC", server1.Name())
+	assert.Equal(t, client1.SpanContext().TraceID(), server1.SpanContext().TraceID())
+	assert.Equal(t, client1.SpanContext().SpanID(), server1.Parent().SpanID())
+
+	assert.False(t, attributes(server1)["dvx.kdf.cache_hit"].AsBool())
+	assert.True(t, attributes(server2)["dvx.kdf.cache_hit"].AsBool())
+	assert.Equal(t, "ok", attributes(server2)["rpc.twirp.code"].AsString())
+}
+
+func TestWrapCrypto(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	c, err := WrapCrypto(client.NewLocal(newProtocol(t)), &Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)),
+	})
+	require.NoError(t, err)
+
+	_, err = c.Decrypt(context.Background(), "keyring", "invalid")
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "azoo.dragon.v1.DragonAPI/Decrypt", spans[0].Name())
+	assert.Equal(t, "unknown", attributes(spans[0])["rpc.twirp.code"].AsString())
+	assert.NotEmpty(t, spans[0].Events())
+}
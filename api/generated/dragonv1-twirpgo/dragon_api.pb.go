@@ -20,6 +20,87 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// ErrorCategory groups errors by how callers should react to them.
+type ErrorCategory int32
+
+const (
+	// UNSPECIFIED is the zero value of ErrorCategory.
+	ErrorCategory_ERROR_CATEGORY_UNSPECIFIED ErrorCategory = 0
+	// INVALID_REQUEST errors are caused by malformed requests, missing or
+	// invalid arguments or requests the service can't fulfill in its current
+	// state. The request must be changed.
+	ErrorCategory_ERROR_CATEGORY_INVALID_REQUEST ErrorCategory = 1
+	// INVALID_DATA errors are caused by ciphertexts, signatures, keys or
+	// tokens that are malformed or failed authentication. The data is
+	// corrupted, was tampered with or belongs to another keyRing.
+	ErrorCategory_ERROR_CATEGORY_INVALID_DATA ErrorCategory = 2
+	// ACCESS_DENIED errors are caused by callers without identity or without
+	// permission for the method or keyRing.
+	ErrorCategory_ERROR_CATEGORY_ACCESS_DENIED ErrorCategory = 3
+	// UNAVAILABLE errors are transient, e.g. a KeyPool (HSM) or store of the
+	// service is unreachable. The request can be retried with backoff.
+	ErrorCategory_ERROR_CATEGORY_UNAVAILABLE ErrorCategory = 4
+	// DEADLINE_EXCEEDED errors are caused by requests that didn't finish in
+	// time or were canceled.
+	ErrorCategory_ERROR_CATEGORY_DEADLINE_EXCEEDED ErrorCategory = 5
+	// UNSUPPORTED errors are caused by methods the service doesn't provide or
+	// has disabled.
+	ErrorCategory_ERROR_CATEGORY_UNSUPPORTED ErrorCategory = 6
+	// INTERNAL errors are unexpected failures of the service.
+	ErrorCategory_ERROR_CATEGORY_INTERNAL ErrorCategory = 7
+)
+
+// Enum value maps for ErrorCategory.
+var (
+	ErrorCategory_name = map[int32]string{
+		0: "ERROR_CATEGORY_UNSPECIFIED",
+		1: "ERROR_CATEGORY_INVALID_REQUEST",
+		2: "ERROR_CATEGORY_INVALID_DATA",
+		3: "ERROR_CATEGORY_ACCESS_DENIED",
+		4: "ERROR_CATEGORY_UNAVAILABLE",
+		5: "ERROR_CATEGORY_DEADLINE_EXCEEDED",
+		6: "ERROR_CATEGORY_UNSUPPORTED",
+		7: "ERROR_CATEGORY_INTERNAL",
+	}
+	ErrorCategory_value = map[string]int32{
+		"ERROR_CATEGORY_UNSPECIFIED":       0,
+		"ERROR_CATEGORY_INVALID_REQUEST":   1,
+		"ERROR_CATEGORY_INVALID_DATA":      2,
+		"ERROR_CATEGORY_ACCESS_DENIED":     3,
+		"ERROR_CATEGORY_UNAVAILABLE":       4,
+		"ERROR_CATEGORY_DEADLINE_EXCEEDED": 5,
+		"ERROR_CATEGORY_UNSUPPORTED":       6,
+		"ERROR_CATEGORY_INTERNAL":          7,
+	}
+)
+
+func (x ErrorCategory) Enum() *ErrorCategory {
+	p := new(ErrorCategory)
+	*p = x
+	return p
+}
+
+func (x ErrorCategory) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorCategory) Descriptor() protoreflect.EnumDescriptor {
+	return file_azoo_dragon_v1_dragon_api_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorCategory) Type() protoreflect.EnumType {
+	return &file_azoo_dragon_v1_dragon_api_proto_enumTypes[0]
+}
+
+func (x ErrorCategory) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorCategory.Descriptor instead.
+func (ErrorCategory) EnumDescriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{0}
+}
+
 // Type is the type of key you want to create
 type CreateKeyRequest_Type int32
 
@@ -62,11 +143,11 @@ func (x CreateKeyRequest_Type) String() string {
 }
 
 func (CreateKeyRequest_Type) Descriptor() protoreflect.EnumDescriptor {
-	return file_azoo_dragon_v1_dragon_api_proto_enumTypes[0].Descriptor()
+	return file_azoo_dragon_v1_dragon_api_proto_enumTypes[1].Descriptor()
 }
 
 func (CreateKeyRequest_Type) Type() protoreflect.EnumType {
-	return &file_azoo_dragon_v1_dragon_api_proto_enumTypes[0]
+	return &file_azoo_dragon_v1_dragon_api_proto_enumTypes[1]
 }
 
 func (x CreateKeyRequest_Type) Number() protoreflect.EnumNumber {
@@ -203,6 +284,12 @@ type EncryptRequest struct {
 
 	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
 	Data    []byte `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	// footer is the encryption context (e.g. the table and primary key of the
+	// record) that the ciphertext is bound to. It isn't encrypted, but it is
+	// authenticated, so Decrypt fails if it was changed or removed. It is
+	// required for keyRings the service configured with
+	// dvx.Protocol.RequireEncryptionContext.
+	Footer []byte `protobuf:"bytes,3,opt,name=footer,proto3" json:"footer,omitempty"`
 }
 
 func (x *EncryptRequest) Reset() {
@@ -251,6 +338,13 @@ func (x *EncryptRequest) GetData() []byte {
 	return nil
 }
 
+func (x *EncryptRequest) GetFooter() []byte {
+	if x != nil {
+		return x.Footer
+	}
+	return nil
+}
+
 type EncryptResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -400,17 +494,16 @@ func (x *DecryptResponse) GetData() []byte {
 	return nil
 }
 
-type MACRequest struct {
+type GenerateDataKeyRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
 	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
-	Message []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *MACRequest) Reset() {
-	*x = MACRequest{}
+func (x *GenerateDataKeyRequest) Reset() {
+	*x = GenerateDataKeyRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -418,13 +511,13 @@ func (x *MACRequest) Reset() {
 	}
 }
 
-func (x *MACRequest) String() string {
+func (x *GenerateDataKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MACRequest) ProtoMessage() {}
+func (*GenerateDataKeyRequest) ProtoMessage() {}
 
-func (x *MACRequest) ProtoReflect() protoreflect.Message {
+func (x *GenerateDataKeyRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -436,35 +529,29 @@ func (x *MACRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MACRequest.ProtoReflect.Descriptor instead.
-func (*MACRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GenerateDataKeyRequest.ProtoReflect.Descriptor instead.
+func (*GenerateDataKeyRequest) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *MACRequest) GetKeyRing() string {
+func (x *GenerateDataKeyRequest) GetKeyRing() string {
 	if x != nil {
 		return x.KeyRing
 	}
 	return ""
 }
 
-func (x *MACRequest) GetMessage() []byte {
-	if x != nil {
-		return x.Message
-	}
-	return nil
-}
-
-type MACResponse struct {
+type GenerateDataKeyResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Tag string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Plaintext  []byte `protobuf:"bytes,1,opt,name=plaintext,proto3" json:"plaintext,omitempty"`
+	WrappedKey string `protobuf:"bytes,2,opt,name=wrapped_key,json=wrappedKey,proto3" json:"wrapped_key,omitempty"`
 }
 
-func (x *MACResponse) Reset() {
-	*x = MACResponse{}
+func (x *GenerateDataKeyResponse) Reset() {
+	*x = GenerateDataKeyResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -472,13 +559,13 @@ func (x *MACResponse) Reset() {
 	}
 }
 
-func (x *MACResponse) String() string {
+func (x *GenerateDataKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*MACResponse) ProtoMessage() {}
+func (*GenerateDataKeyResponse) ProtoMessage() {}
 
-func (x *MACResponse) ProtoReflect() protoreflect.Message {
+func (x *GenerateDataKeyResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -490,29 +577,38 @@ func (x *MACResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use MACResponse.ProtoReflect.Descriptor instead.
-func (*MACResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use GenerateDataKeyResponse.ProtoReflect.Descriptor instead.
+func (*GenerateDataKeyResponse) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *MACResponse) GetTag() string {
+func (x *GenerateDataKeyResponse) GetPlaintext() []byte {
 	if x != nil {
-		return x.Tag
+		return x.Plaintext
+	}
+	return nil
+}
+
+func (x *GenerateDataKeyResponse) GetWrappedKey() string {
+	if x != nil {
+		return x.WrappedKey
 	}
 	return ""
 }
 
-type SignRequest struct {
+type DecryptDataKeyRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
-	Message []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// key_ring must be the key_ring passed to GenerateDataKey. It isn't part of
+	// the wrapped key, but selects the key and the caller's authorization.
+	KeyRing    string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	WrappedKey string `protobuf:"bytes,2,opt,name=wrapped_key,json=wrappedKey,proto3" json:"wrapped_key,omitempty"`
 }
 
-func (x *SignRequest) Reset() {
-	*x = SignRequest{}
+func (x *DecryptDataKeyRequest) Reset() {
+	*x = DecryptDataKeyRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -520,13 +616,13 @@ func (x *SignRequest) Reset() {
 	}
 }
 
-func (x *SignRequest) String() string {
+func (x *DecryptDataKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SignRequest) ProtoMessage() {}
+func (*DecryptDataKeyRequest) ProtoMessage() {}
 
-func (x *SignRequest) ProtoReflect() protoreflect.Message {
+func (x *DecryptDataKeyRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -538,36 +634,35 @@ func (x *SignRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SignRequest.ProtoReflect.Descriptor instead.
-func (*SignRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DecryptDataKeyRequest.ProtoReflect.Descriptor instead.
+func (*DecryptDataKeyRequest) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *SignRequest) GetKeyRing() string {
+func (x *DecryptDataKeyRequest) GetKeyRing() string {
 	if x != nil {
 		return x.KeyRing
 	}
 	return ""
 }
 
-func (x *SignRequest) GetMessage() []byte {
+func (x *DecryptDataKeyRequest) GetWrappedKey() string {
 	if x != nil {
-		return x.Message
+		return x.WrappedKey
 	}
-	return nil
+	return ""
 }
 
-type SignResponse struct {
+type DecryptDataKeyResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Signature    string `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
-	RawSignature []byte `protobuf:"bytes,2,opt,name=raw_signature,json=rawSignature,proto3" json:"raw_signature,omitempty"`
+	Plaintext []byte `protobuf:"bytes,1,opt,name=plaintext,proto3" json:"plaintext,omitempty"`
 }
 
-func (x *SignResponse) Reset() {
-	*x = SignResponse{}
+func (x *DecryptDataKeyResponse) Reset() {
+	*x = DecryptDataKeyResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -575,13 +670,13 @@ func (x *SignResponse) Reset() {
 	}
 }
 
-func (x *SignResponse) String() string {
+func (x *DecryptDataKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SignResponse) ProtoMessage() {}
+func (*DecryptDataKeyResponse) ProtoMessage() {}
 
-func (x *SignResponse) ProtoReflect() protoreflect.Message {
+func (x *DecryptDataKeyResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -593,37 +688,32 @@ func (x *SignResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SignResponse.ProtoReflect.Descriptor instead.
-func (*SignResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DecryptDataKeyResponse.ProtoReflect.Descriptor instead.
+func (*DecryptDataKeyResponse) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *SignResponse) GetSignature() string {
-	if x != nil {
-		return x.Signature
-	}
-	return ""
-}
-
-func (x *SignResponse) GetRawSignature() []byte {
+func (x *DecryptDataKeyResponse) GetPlaintext() []byte {
 	if x != nil {
-		return x.RawSignature
+		return x.Plaintext
 	}
 	return nil
 }
 
-type VerifyRequest struct {
+type DeriveKeyRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	KeyRing   string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
-	Message   []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
-	Signature string `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
+	// input is the input of the KeyPool: the keyRing bytes, which dvx prefixes
+	// with the label of the derivation since dv2.
+	Input []byte `protobuf:"bytes,1,opt,name=input,proto3" json:"input,omitempty"`
+	// size is the size of the derived key in bytes: 32 or 64.
+	Size int32 `protobuf:"varint,2,opt,name=size,proto3" json:"size,omitempty"`
 }
 
-func (x *VerifyRequest) Reset() {
-	*x = VerifyRequest{}
+func (x *DeriveKeyRequest) Reset() {
+	*x = DeriveKeyRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -631,13 +721,13 @@ func (x *VerifyRequest) Reset() {
 	}
 }
 
-func (x *VerifyRequest) String() string {
+func (x *DeriveKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VerifyRequest) ProtoMessage() {}
+func (*DeriveKeyRequest) ProtoMessage() {}
 
-func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
+func (x *DeriveKeyRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -649,42 +739,35 @@ func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
-func (*VerifyRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeriveKeyRequest.ProtoReflect.Descriptor instead.
+func (*DeriveKeyRequest) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *VerifyRequest) GetKeyRing() string {
-	if x != nil {
-		return x.KeyRing
-	}
-	return ""
-}
-
-func (x *VerifyRequest) GetMessage() []byte {
+func (x *DeriveKeyRequest) GetInput() []byte {
 	if x != nil {
-		return x.Message
+		return x.Input
 	}
 	return nil
 }
 
-func (x *VerifyRequest) GetSignature() string {
+func (x *DeriveKeyRequest) GetSize() int32 {
 	if x != nil {
-		return x.Signature
+		return x.Size
 	}
-	return ""
+	return 0
 }
 
-type VerifyResponse struct {
+type DeriveKeyResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Key []byte `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
 }
 
-func (x *VerifyResponse) Reset() {
-	*x = VerifyResponse{}
+func (x *DeriveKeyResponse) Reset() {
+	*x = DeriveKeyResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -692,13 +775,13 @@ func (x *VerifyResponse) Reset() {
 	}
 }
 
-func (x *VerifyResponse) String() string {
+func (x *DeriveKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VerifyResponse) ProtoMessage() {}
+func (*DeriveKeyResponse) ProtoMessage() {}
 
-func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
+func (x *DeriveKeyResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -710,31 +793,29 @@ func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
-func (*VerifyResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use DeriveKeyResponse.ProtoReflect.Descriptor instead.
+func (*DeriveKeyResponse) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *VerifyResponse) GetValid() bool {
+func (x *DeriveKeyResponse) GetKey() []byte {
 	if x != nil {
-		return x.Valid
+		return x.Key
 	}
-	return false
+	return nil
 }
 
-type GenerateTOTPRequest struct {
+type MACRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	KeyRing     string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
-	Issuer      string `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
-	AccountName string `protobuf:"bytes,3,opt,name=account_name,json=accountName,proto3" json:"account_name,omitempty"`
-	AccountId   string `protobuf:"bytes,4,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	Message []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *GenerateTOTPRequest) Reset() {
-	*x = GenerateTOTPRequest{}
+func (x *MACRequest) Reset() {
+	*x = MACRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -742,13 +823,13 @@ func (x *GenerateTOTPRequest) Reset() {
 	}
 }
 
-func (x *GenerateTOTPRequest) String() string {
+func (x *MACRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GenerateTOTPRequest) ProtoMessage() {}
+func (*MACRequest) ProtoMessage() {}
 
-func (x *GenerateTOTPRequest) ProtoReflect() protoreflect.Message {
+func (x *MACRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -760,51 +841,35 @@ func (x *GenerateTOTPRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GenerateTOTPRequest.ProtoReflect.Descriptor instead.
-func (*GenerateTOTPRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use MACRequest.ProtoReflect.Descriptor instead.
+func (*MACRequest) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *GenerateTOTPRequest) GetKeyRing() string {
+func (x *MACRequest) GetKeyRing() string {
 	if x != nil {
 		return x.KeyRing
 	}
 	return ""
 }
 
-func (x *GenerateTOTPRequest) GetIssuer() string {
-	if x != nil {
-		return x.Issuer
-	}
-	return ""
-}
-
-func (x *GenerateTOTPRequest) GetAccountName() string {
-	if x != nil {
-		return x.AccountName
-	}
-	return ""
-}
-
-func (x *GenerateTOTPRequest) GetAccountId() string {
+func (x *MACRequest) GetMessage() []byte {
 	if x != nil {
-		return x.AccountId
+		return x.Message
 	}
-	return ""
+	return nil
 }
 
-type GenerateTOTPResponse struct {
+type MACResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Id     string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Uri    string `protobuf:"bytes,2,opt,name=uri,proto3" json:"uri,omitempty"`
-	QrCode string `protobuf:"bytes,3,opt,name=qr_code,json=qrCode,proto3" json:"qr_code,omitempty"`
+	Tag string `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
 }
 
-func (x *GenerateTOTPResponse) Reset() {
-	*x = GenerateTOTPResponse{}
+func (x *MACResponse) Reset() {
+	*x = MACResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -812,13 +877,13 @@ func (x *GenerateTOTPResponse) Reset() {
 	}
 }
 
-func (x *GenerateTOTPResponse) String() string {
+func (x *MACResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GenerateTOTPResponse) ProtoMessage() {}
+func (*MACResponse) ProtoMessage() {}
 
-func (x *GenerateTOTPResponse) ProtoReflect() protoreflect.Message {
+func (x *MACResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -830,45 +895,29 @@ func (x *GenerateTOTPResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GenerateTOTPResponse.ProtoReflect.Descriptor instead.
-func (*GenerateTOTPResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use MACResponse.ProtoReflect.Descriptor instead.
+func (*MACResponse) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{13}
 }
 
-func (x *GenerateTOTPResponse) GetId() string {
-	if x != nil {
-		return x.Id
-	}
-	return ""
-}
-
-func (x *GenerateTOTPResponse) GetUri() string {
-	if x != nil {
-		return x.Uri
-	}
-	return ""
-}
-
-func (x *GenerateTOTPResponse) GetQrCode() string {
+func (x *MACResponse) GetTag() string {
 	if x != nil {
-		return x.QrCode
+		return x.Tag
 	}
 	return ""
 }
 
-type VerifyTOTPRequest struct {
+type SignRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	KeyRing   string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
-	Id        string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
-	AccountId string `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	Code      string `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`
+	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	Message []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
 }
 
-func (x *VerifyTOTPRequest) Reset() {
-	*x = VerifyTOTPRequest{}
+func (x *SignRequest) Reset() {
+	*x = SignRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -876,13 +925,13 @@ func (x *VerifyTOTPRequest) Reset() {
 	}
 }
 
-func (x *VerifyTOTPRequest) String() string {
+func (x *SignRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VerifyTOTPRequest) ProtoMessage() {}
+func (*SignRequest) ProtoMessage() {}
 
-func (x *VerifyTOTPRequest) ProtoReflect() protoreflect.Message {
+func (x *SignRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -894,49 +943,36 @@ func (x *VerifyTOTPRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VerifyTOTPRequest.ProtoReflect.Descriptor instead.
-func (*VerifyTOTPRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use SignRequest.ProtoReflect.Descriptor instead.
+func (*SignRequest) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *VerifyTOTPRequest) GetKeyRing() string {
+func (x *SignRequest) GetKeyRing() string {
 	if x != nil {
 		return x.KeyRing
 	}
 	return ""
 }
 
-func (x *VerifyTOTPRequest) GetId() string {
+func (x *SignRequest) GetMessage() []byte {
 	if x != nil {
-		return x.Id
+		return x.Message
 	}
-	return ""
+	return nil
 }
 
-func (x *VerifyTOTPRequest) GetAccountId() string {
-	if x != nil {
-		return x.AccountId
-	}
-	return ""
-}
-
-func (x *VerifyTOTPRequest) GetCode() string {
-	if x != nil {
-		return x.Code
-	}
-	return ""
-}
-
-type VerifyTOTPResponse struct {
+type SignResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	Signature    string `protobuf:"bytes,1,opt,name=signature,proto3" json:"signature,omitempty"`
+	RawSignature []byte `protobuf:"bytes,2,opt,name=raw_signature,json=rawSignature,proto3" json:"raw_signature,omitempty"`
 }
 
-func (x *VerifyTOTPResponse) Reset() {
-	*x = VerifyTOTPResponse{}
+func (x *SignResponse) Reset() {
+	*x = SignResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -944,13 +980,13 @@ func (x *VerifyTOTPResponse) Reset() {
 	}
 }
 
-func (x *VerifyTOTPResponse) String() string {
+func (x *SignResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*VerifyTOTPResponse) ProtoMessage() {}
+func (*SignResponse) ProtoMessage() {}
 
-func (x *VerifyTOTPResponse) ProtoReflect() protoreflect.Message {
+func (x *SignResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -962,31 +998,37 @@ func (x *VerifyTOTPResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use VerifyTOTPResponse.ProtoReflect.Descriptor instead.
-func (*VerifyTOTPResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use SignResponse.ProtoReflect.Descriptor instead.
+func (*SignResponse) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *VerifyTOTPResponse) GetValid() bool {
+func (x *SignResponse) GetSignature() string {
 	if x != nil {
-		return x.Valid
+		return x.Signature
 	}
-	return false
+	return ""
 }
 
-type BatchVerifyTOTPRequest struct {
+func (x *SignResponse) GetRawSignature() []byte {
+	if x != nil {
+		return x.RawSignature
+	}
+	return nil
+}
+
+type VerifyRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	KeyRing   string   `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
-	Ids       []string `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`
-	AccountId string   `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
-	Code      string   `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`
+	KeyRing   string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	Message   []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Signature string `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
 }
 
-func (x *BatchVerifyTOTPRequest) Reset() {
-	*x = BatchVerifyTOTPRequest{}
+func (x *VerifyRequest) Reset() {
+	*x = VerifyRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -994,13 +1036,13 @@ func (x *BatchVerifyTOTPRequest) Reset() {
 	}
 }
 
-func (x *BatchVerifyTOTPRequest) String() string {
+func (x *VerifyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchVerifyTOTPRequest) ProtoMessage() {}
+func (*VerifyRequest) ProtoMessage() {}
 
-func (x *BatchVerifyTOTPRequest) ProtoReflect() protoreflect.Message {
+func (x *VerifyRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1012,50 +1054,42 @@ func (x *BatchVerifyTOTPRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchVerifyTOTPRequest.ProtoReflect.Descriptor instead.
-func (*BatchVerifyTOTPRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use VerifyRequest.ProtoReflect.Descriptor instead.
+func (*VerifyRequest) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *BatchVerifyTOTPRequest) GetKeyRing() string {
+func (x *VerifyRequest) GetKeyRing() string {
 	if x != nil {
 		return x.KeyRing
 	}
 	return ""
 }
 
-func (x *BatchVerifyTOTPRequest) GetIds() []string {
+func (x *VerifyRequest) GetMessage() []byte {
 	if x != nil {
-		return x.Ids
+		return x.Message
 	}
 	return nil
 }
 
-func (x *BatchVerifyTOTPRequest) GetAccountId() string {
-	if x != nil {
-		return x.AccountId
-	}
-	return ""
-}
-
-func (x *BatchVerifyTOTPRequest) GetCode() string {
+func (x *VerifyRequest) GetSignature() string {
 	if x != nil {
-		return x.Code
+		return x.Signature
 	}
 	return ""
 }
 
-type BatchVerifyTOTPResponse struct {
+type VerifyResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Valid          bool   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
-	ValidThroughId string `protobuf:"bytes,2,opt,name=valid_through_id,json=validThroughId,proto3" json:"valid_through_id,omitempty"`
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
 }
 
-func (x *BatchVerifyTOTPResponse) Reset() {
-	*x = BatchVerifyTOTPResponse{}
+func (x *VerifyResponse) Reset() {
+	*x = VerifyResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[17]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1063,13 +1097,13 @@ func (x *BatchVerifyTOTPResponse) Reset() {
 	}
 }
 
-func (x *BatchVerifyTOTPResponse) String() string {
+func (x *VerifyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchVerifyTOTPResponse) ProtoMessage() {}
+func (*VerifyResponse) ProtoMessage() {}
 
-func (x *BatchVerifyTOTPResponse) ProtoReflect() protoreflect.Message {
+func (x *VerifyResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[17]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1081,36 +1115,30 @@ func (x *BatchVerifyTOTPResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchVerifyTOTPResponse.ProtoReflect.Descriptor instead.
-func (*BatchVerifyTOTPResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use VerifyResponse.ProtoReflect.Descriptor instead.
+func (*VerifyResponse) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{17}
 }
 
-func (x *BatchVerifyTOTPResponse) GetValid() bool {
+func (x *VerifyResponse) GetValid() bool {
 	if x != nil {
 		return x.Valid
 	}
 	return false
 }
 
-func (x *BatchVerifyTOTPResponse) GetValidThroughId() string {
-	if x != nil {
-		return x.ValidThroughId
-	}
-	return ""
-}
-
-type DeleteTOTPRequest struct {
+type VerifyPKRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
-	Id      string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Message   []byte `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Signature string `protobuf:"bytes,3,opt,name=signature,proto3" json:"signature,omitempty"`
 }
 
-func (x *DeleteTOTPRequest) Reset() {
-	*x = DeleteTOTPRequest{}
+func (x *VerifyPKRequest) Reset() {
+	*x = VerifyPKRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1118,13 +1146,13 @@ func (x *DeleteTOTPRequest) Reset() {
 	}
 }
 
-func (x *DeleteTOTPRequest) String() string {
+func (x *VerifyPKRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteTOTPRequest) ProtoMessage() {}
+func (*VerifyPKRequest) ProtoMessage() {}
 
-func (x *DeleteTOTPRequest) ProtoReflect() protoreflect.Message {
+func (x *VerifyPKRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1136,33 +1164,42 @@ func (x *DeleteTOTPRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteTOTPRequest.ProtoReflect.Descriptor instead.
-func (*DeleteTOTPRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use VerifyPKRequest.ProtoReflect.Descriptor instead.
+func (*VerifyPKRequest) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{18}
 }
 
-func (x *DeleteTOTPRequest) GetKeyRing() string {
+func (x *VerifyPKRequest) GetPublicKey() []byte {
 	if x != nil {
-		return x.KeyRing
+		return x.PublicKey
 	}
-	return ""
+	return nil
 }
 
-func (x *DeleteTOTPRequest) GetId() string {
+func (x *VerifyPKRequest) GetMessage() []byte {
 	if x != nil {
-		return x.Id
+		return x.Message
+	}
+	return nil
+}
+
+func (x *VerifyPKRequest) GetSignature() string {
+	if x != nil {
+		return x.Signature
 	}
 	return ""
 }
 
-type DeleteTOTPResponse struct {
+type VerifyPKResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
 }
 
-func (x *DeleteTOTPResponse) Reset() {
-	*x = DeleteTOTPResponse{}
+func (x *VerifyPKResponse) Reset() {
+	*x = VerifyPKResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1170,13 +1207,13 @@ func (x *DeleteTOTPResponse) Reset() {
 	}
 }
 
-func (x *DeleteTOTPResponse) String() string {
+func (x *VerifyPKResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteTOTPResponse) ProtoMessage() {}
+func (*VerifyPKResponse) ProtoMessage() {}
 
-func (x *DeleteTOTPResponse) ProtoReflect() protoreflect.Message {
+func (x *VerifyPKResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1188,19 +1225,31 @@ func (x *DeleteTOTPResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteTOTPResponse.ProtoReflect.Descriptor instead.
-func (*DeleteTOTPResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use VerifyPKResponse.ProtoReflect.Descriptor instead.
+func (*VerifyPKResponse) Descriptor() ([]byte, []int) {
 	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{19}
 }
 
-type CreateKeyResponse_EncryptionKey struct {
+func (x *VerifyPKResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+type GenerateTOTPRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	KeyRing     string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	Issuer      string `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	AccountName string `protobuf:"bytes,3,opt,name=account_name,json=accountName,proto3" json:"account_name,omitempty"`
+	AccountId   string `protobuf:"bytes,4,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
 }
 
-func (x *CreateKeyResponse_EncryptionKey) Reset() {
-	*x = CreateKeyResponse_EncryptionKey{}
+func (x *GenerateTOTPRequest) Reset() {
+	*x = GenerateTOTPRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[20]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1208,13 +1257,13 @@ func (x *CreateKeyResponse_EncryptionKey) Reset() {
 	}
 }
 
-func (x *CreateKeyResponse_EncryptionKey) String() string {
+func (x *GenerateTOTPRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateKeyResponse_EncryptionKey) ProtoMessage() {}
+func (*GenerateTOTPRequest) ProtoMessage() {}
 
-func (x *CreateKeyResponse_EncryptionKey) ProtoReflect() protoreflect.Message {
+func (x *GenerateTOTPRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[20]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1226,21 +1275,51 @@ func (x *CreateKeyResponse_EncryptionKey) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateKeyResponse_EncryptionKey.ProtoReflect.Descriptor instead.
-func (*CreateKeyResponse_EncryptionKey) Descriptor() ([]byte, []int) {
-	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{1, 0}
+// Deprecated: Use GenerateTOTPRequest.ProtoReflect.Descriptor instead.
+func (*GenerateTOTPRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{20}
 }
 
-type CreateKeyResponse_SigningKey struct {
+func (x *GenerateTOTPRequest) GetKeyRing() string {
+	if x != nil {
+		return x.KeyRing
+	}
+	return ""
+}
+
+func (x *GenerateTOTPRequest) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *GenerateTOTPRequest) GetAccountName() string {
+	if x != nil {
+		return x.AccountName
+	}
+	return ""
+}
+
+func (x *GenerateTOTPRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+type GenerateTOTPResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+	Id     string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Uri    string `protobuf:"bytes,2,opt,name=uri,proto3" json:"uri,omitempty"`
+	QrCode string `protobuf:"bytes,3,opt,name=qr_code,json=qrCode,proto3" json:"qr_code,omitempty"`
 }
 
-func (x *CreateKeyResponse_SigningKey) Reset() {
-	*x = CreateKeyResponse_SigningKey{}
+func (x *GenerateTOTPResponse) Reset() {
+	*x = GenerateTOTPResponse{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[21]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1248,13 +1327,13 @@ func (x *CreateKeyResponse_SigningKey) Reset() {
 	}
 }
 
-func (x *CreateKeyResponse_SigningKey) String() string {
+func (x *GenerateTOTPResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateKeyResponse_SigningKey) ProtoMessage() {}
+func (*GenerateTOTPResponse) ProtoMessage() {}
 
-func (x *CreateKeyResponse_SigningKey) ProtoReflect() protoreflect.Message {
+func (x *GenerateTOTPResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[21]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1266,26 +1345,45 @@ func (x *CreateKeyResponse_SigningKey) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CreateKeyResponse_SigningKey.ProtoReflect.Descriptor instead.
-func (*CreateKeyResponse_SigningKey) Descriptor() ([]byte, []int) {
-	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{1, 1}
+// Deprecated: Use GenerateTOTPResponse.ProtoReflect.Descriptor instead.
+func (*GenerateTOTPResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{21}
 }
 
-func (x *CreateKeyResponse_SigningKey) GetPublicKey() []byte {
+func (x *GenerateTOTPResponse) GetId() string {
 	if x != nil {
-		return x.PublicKey
+		return x.Id
 	}
-	return nil
+	return ""
 }
 
-type CreateKeyResponse_MACKey struct {
+func (x *GenerateTOTPResponse) GetUri() string {
+	if x != nil {
+		return x.Uri
+	}
+	return ""
+}
+
+func (x *GenerateTOTPResponse) GetQrCode() string {
+	if x != nil {
+		return x.QrCode
+	}
+	return ""
+}
+
+type VerifyTOTPRequest struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	KeyRing   string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	Id        string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+	AccountId string `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Code      string `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`
 }
 
-func (x *CreateKeyResponse_MACKey) Reset() {
-	*x = CreateKeyResponse_MACKey{}
+func (x *VerifyTOTPRequest) Reset() {
+	*x = VerifyTOTPRequest{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[22]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -1293,286 +1391,2785 @@ func (x *CreateKeyResponse_MACKey) Reset() {
 	}
 }
 
-func (x *CreateKeyResponse_MACKey) String() string {
+func (x *VerifyTOTPRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CreateKeyResponse_MACKey) ProtoMessage() {}
+func (*VerifyTOTPRequest) ProtoMessage() {}
 
-func (x *CreateKeyResponse_MACKey) ProtoReflect() protoreflect.Message {
+func (x *VerifyTOTPRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[22]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
 			ms.StoreMessageInfo(mi)
 		}
-		return ms
-	}
-	return mi.MessageOf(x)
-}
-
-// Deprecated: Use CreateKeyResponse_MACKey.ProtoReflect.Descriptor instead.
-func (*CreateKeyResponse_MACKey) Descriptor() ([]byte, []int) {
-	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{1, 2}
-}
-
-var File_azoo_dragon_v1_dragon_api_proto protoreflect.FileDescriptor
-
-var file_azoo_dragon_v1_dragon_api_proto_rawDesc = []byte{
-	0x0a, 0x1f, 0x61, 0x7a, 0x6f, 0x6f, 0x2f, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2f, 0x76, 0x31,
-	0x2f, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74,
-	0x6f, 0x12, 0x0e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76,
-	0x31, 0x22, 0xb7, 0x01, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52,
-	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69,
-	0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e,
-	0x67, 0x12, 0x39, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
-	0x25, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
-	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x4d, 0x0a, 0x04,
-	0x54, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x0c, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x56,
-	0x41, 0x4c, 0x49, 0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45,
-	0x4e, 0x43, 0x52, 0x59, 0x50, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x54,
-	0x59, 0x50, 0x45, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x0c, 0x0a,
-	0x08, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d, 0x41, 0x43, 0x10, 0x03, 0x22, 0xc5, 0x02, 0x0a, 0x11,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x12, 0x56, 0x0a, 0x0e, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
-	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
-	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x45, 0x6e, 0x63,
-	0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x52, 0x0d, 0x65, 0x6e, 0x63, 0x72,
-	0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x4d, 0x0a, 0x0b, 0x73, 0x69, 0x67,
-	0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c,
-	0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
-	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
-	0x65, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x52, 0x0a, 0x73, 0x69,
-	0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x12, 0x41, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x5f,
-	0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
-	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4d, 0x41, 0x43,
-	0x4b, 0x65, 0x79, 0x52, 0x06, 0x6d, 0x61, 0x63, 0x4b, 0x65, 0x79, 0x1a, 0x0f, 0x0a, 0x0d, 0x45,
-	0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x1a, 0x2b, 0x0a, 0x0a,
-	0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75,
-	0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
-	0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x1a, 0x08, 0x0a, 0x06, 0x4d, 0x41, 0x43,
-	0x4b, 0x65, 0x79, 0x22, 0x3f, 0x0a, 0x0e, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65,
-	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e,
-	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67,
-	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
-	0x64, 0x61, 0x74, 0x61, 0x22, 0x31, 0x0a, 0x0f, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65,
-	0x72, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x69, 0x70,
-	0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74, 0x22, 0x4b, 0x0a, 0x0e, 0x44, 0x65, 0x63, 0x72, 0x79,
-	0x70, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79,
-	0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79,
-	0x52, 0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65,
-	0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72,
-	0x74, 0x65, 0x78, 0x74, 0x22, 0x25, 0x0a, 0x0f, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x41, 0x0a, 0x0a, 0x4d,
-	0x41, 0x43, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79,
-	0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79,
-	0x52, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x1f,
-	0x0a, 0x0b, 0x4d, 0x41, 0x43, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a,
-	0x03, 0x74, 0x61, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x22,
-	0x42, 0x0a, 0x0b, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19,
-	0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73,
-	0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73,
-	0x61, 0x67, 0x65, 0x22, 0x51, 0x0a, 0x0c, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f,
-	0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72,
-	0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61, 0x77, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
-	0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x0c, 0x72, 0x61, 0x77, 0x53, 0x69, 0x67,
-	0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x62, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72,
-	0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69,
-	0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20,
-	0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09,
-	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x26, 0x0a, 0x0e, 0x56, 0x65,
-	0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05,
-	0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c,
-	0x69, 0x64, 0x22, 0x8a, 0x01, 0x0a, 0x13, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54,
-	0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65,
-	0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65,
-	0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x18,
-	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x12, 0x21, 0x0a,
-	0x0c, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x0b, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4e, 0x61, 0x6d, 0x65,
-	0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x04,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x22,
-	0x51, 0x0a, 0x14, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20,
-	0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x02,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x17, 0x0a, 0x07, 0x71, 0x72, 0x5f,
-	0x63, 0x6f, 0x64, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x71, 0x72, 0x43, 0x6f,
-	0x64, 0x65, 0x22, 0x71, 0x0a, 0x11, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72,
-	0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69,
-	0x6e, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
-	0x69, 0x64, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49,
-	0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x2a, 0x0a, 0x12, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54,
-	0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
-	0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69,
-	0x64, 0x22, 0x78, 0x0a, 0x16, 0x42, 0x61, 0x74, 0x63, 0x68, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
-	0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b,
-	0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b,
-	0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f,
-	0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63,
-	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18,
-	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22, 0x59, 0x0a, 0x17, 0x42,
-	0x61, 0x74, 0x63, 0x68, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65,
-	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x28, 0x0a, 0x10,
-	0x76, 0x61, 0x6c, 0x69, 0x64, 0x5f, 0x74, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x5f, 0x69, 0x64,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x54, 0x68, 0x72,
-	0x6f, 0x75, 0x67, 0x68, 0x49, 0x64, 0x22, 0x3e, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b,
-	0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b,
-	0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
-	0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x32, 0xbe, 0x06, 0x0a,
-	0x09, 0x44, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x41, 0x50, 0x49, 0x12, 0x52, 0x0a, 0x09, 0x43, 0x72,
-	0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x20, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64,
-	0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b,
-	0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
-	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
-	0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4c,
-	0x0a, 0x07, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x12, 0x1e, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
-	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x63, 0x72, 0x79,
-	0x70, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
-	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x6e, 0x63, 0x72, 0x79,
-	0x70, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x07,
-	0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x12, 0x1e, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64,
-	0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64,
-	0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x40, 0x0a, 0x03, 0x4d, 0x41,
-	0x43, 0x12, 0x1a, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
-	0x76, 0x31, 0x2e, 0x4d, 0x41, 0x43, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1b, 0x2e,
-	0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x4d,
-	0x41, 0x43, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x43, 0x0a, 0x04,
-	0x53, 0x69, 0x67, 0x6e, 0x12, 0x1b, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67,
-	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
-	0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
-	0x00, 0x12, 0x49, 0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x12, 0x1d, 0x2e, 0x61, 0x7a,
-	0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72,
-	0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1e, 0x2e, 0x61, 0x7a, 0x6f,
-	0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69,
-	0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x5b, 0x0a, 0x0c,
-	0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x12, 0x23, 0x2e, 0x61,
-	0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
-	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
-	0x74, 0x1a, 0x24, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
-	0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52,
-	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x0a, 0x56, 0x65, 0x72,
-	0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x12, 0x21, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64,
-	0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54,
-	0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x61, 0x7a, 0x6f,
-	0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56, 0x65, 0x72, 0x69,
-	0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00,
-	0x12, 0x64, 0x0a, 0x0f, 0x42, 0x61, 0x74, 0x63, 0x68, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54,
-	0x4f, 0x54, 0x50, 0x12, 0x26, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f,
-	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
-	0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x61, 0x7a,
-	0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74,
-	0x63, 0x68, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70,
-	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x0a, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x54, 0x4f, 0x54, 0x50, 0x12, 0x21, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67,
-	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50,
-	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64,
-	0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54,
-	0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x42, 0x21, 0x5a,
-	0x1f, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x65, 0x76, 0x2f, 0x61, 0x70, 0x69, 0x2f, 0x67, 0x65,
-	0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2f, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x76, 0x31,
-	0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
-}
-
-var (
-	file_azoo_dragon_v1_dragon_api_proto_rawDescOnce sync.Once
-	file_azoo_dragon_v1_dragon_api_proto_rawDescData = file_azoo_dragon_v1_dragon_api_proto_rawDesc
-)
-
-func file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP() []byte {
-	file_azoo_dragon_v1_dragon_api_proto_rawDescOnce.Do(func() {
-		file_azoo_dragon_v1_dragon_api_proto_rawDescData = protoimpl.X.CompressGZIP(file_azoo_dragon_v1_dragon_api_proto_rawDescData)
-	})
-	return file_azoo_dragon_v1_dragon_api_proto_rawDescData
-}
-
-var file_azoo_dragon_v1_dragon_api_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_azoo_dragon_v1_dragon_api_proto_msgTypes = make([]protoimpl.MessageInfo, 23)
-var file_azoo_dragon_v1_dragon_api_proto_goTypes = []interface{}{
-	(CreateKeyRequest_Type)(0),              // 0: azoo.dragon.v1.CreateKeyRequest.Type
-	(*CreateKeyRequest)(nil),                // 1: azoo.dragon.v1.CreateKeyRequest
-	(*CreateKeyResponse)(nil),               // 2: azoo.dragon.v1.CreateKeyResponse
-	(*EncryptRequest)(nil),                  // 3: azoo.dragon.v1.EncryptRequest
-	(*EncryptResponse)(nil),                 // 4: azoo.dragon.v1.EncryptResponse
-	(*DecryptRequest)(nil),                  // 5: azoo.dragon.v1.DecryptRequest
-	(*DecryptResponse)(nil),                 // 6: azoo.dragon.v1.DecryptResponse
-	(*MACRequest)(nil),                      // 7: azoo.dragon.v1.MACRequest
-	(*MACResponse)(nil),                     // 8: azoo.dragon.v1.MACResponse
-	(*SignRequest)(nil),                     // 9: azoo.dragon.v1.SignRequest
-	(*SignResponse)(nil),                    // 10: azoo.dragon.v1.SignResponse
-	(*VerifyRequest)(nil),                   // 11: azoo.dragon.v1.VerifyRequest
-	(*VerifyResponse)(nil),                  // 12: azoo.dragon.v1.VerifyResponse
-	(*GenerateTOTPRequest)(nil),             // 13: azoo.dragon.v1.GenerateTOTPRequest
-	(*GenerateTOTPResponse)(nil),            // 14: azoo.dragon.v1.GenerateTOTPResponse
-	(*VerifyTOTPRequest)(nil),               // 15: azoo.dragon.v1.VerifyTOTPRequest
-	(*VerifyTOTPResponse)(nil),              // 16: azoo.dragon.v1.VerifyTOTPResponse
-	(*BatchVerifyTOTPRequest)(nil),          // 17: azoo.dragon.v1.BatchVerifyTOTPRequest
-	(*BatchVerifyTOTPResponse)(nil),         // 18: azoo.dragon.v1.BatchVerifyTOTPResponse
-	(*DeleteTOTPRequest)(nil),               // 19: azoo.dragon.v1.DeleteTOTPRequest
-	(*DeleteTOTPResponse)(nil),              // 20: azoo.dragon.v1.DeleteTOTPResponse
-	(*CreateKeyResponse_EncryptionKey)(nil), // 21: azoo.dragon.v1.CreateKeyResponse.EncryptionKey
-	(*CreateKeyResponse_SigningKey)(nil),    // 22: azoo.dragon.v1.CreateKeyResponse.SigningKey
-	(*CreateKeyResponse_MACKey)(nil),        // 23: azoo.dragon.v1.CreateKeyResponse.MACKey
-}
-var file_azoo_dragon_v1_dragon_api_proto_depIdxs = []int32{
-	0,  // 0: azoo.dragon.v1.CreateKeyRequest.type:type_name -> azoo.dragon.v1.CreateKeyRequest.Type
-	21, // 1: azoo.dragon.v1.CreateKeyResponse.encryption_key:type_name -> azoo.dragon.v1.CreateKeyResponse.EncryptionKey
-	22, // 2: azoo.dragon.v1.CreateKeyResponse.signing_key:type_name -> azoo.dragon.v1.CreateKeyResponse.SigningKey
-	23, // 3: azoo.dragon.v1.CreateKeyResponse.mac_key:type_name -> azoo.dragon.v1.CreateKeyResponse.MACKey
-	1,  // 4: azoo.dragon.v1.DragonAPI.CreateKey:input_type -> azoo.dragon.v1.CreateKeyRequest
-	3,  // 5: azoo.dragon.v1.DragonAPI.Encrypt:input_type -> azoo.dragon.v1.EncryptRequest
-	5,  // 6: azoo.dragon.v1.DragonAPI.Decrypt:input_type -> azoo.dragon.v1.DecryptRequest
-	7,  // 7: azoo.dragon.v1.DragonAPI.MAC:input_type -> azoo.dragon.v1.MACRequest
-	9,  // 8: azoo.dragon.v1.DragonAPI.Sign:input_type -> azoo.dragon.v1.SignRequest
-	11, // 9: azoo.dragon.v1.DragonAPI.Verify:input_type -> azoo.dragon.v1.VerifyRequest
-	13, // 10: azoo.dragon.v1.DragonAPI.GenerateTOTP:input_type -> azoo.dragon.v1.GenerateTOTPRequest
-	15, // 11: azoo.dragon.v1.DragonAPI.VerifyTOTP:input_type -> azoo.dragon.v1.VerifyTOTPRequest
-	17, // 12: azoo.dragon.v1.DragonAPI.BatchVerifyTOTP:input_type -> azoo.dragon.v1.BatchVerifyTOTPRequest
-	19, // 13: azoo.dragon.v1.DragonAPI.DeleteTOTP:input_type -> azoo.dragon.v1.DeleteTOTPRequest
-	2,  // 14: azoo.dragon.v1.DragonAPI.CreateKey:output_type -> azoo.dragon.v1.CreateKeyResponse
-	4,  // 15: azoo.dragon.v1.DragonAPI.Encrypt:output_type -> azoo.dragon.v1.EncryptResponse
-	6,  // 16: azoo.dragon.v1.DragonAPI.Decrypt:output_type -> azoo.dragon.v1.DecryptResponse
-	8,  // 17: azoo.dragon.v1.DragonAPI.MAC:output_type -> azoo.dragon.v1.MACResponse
-	10, // 18: azoo.dragon.v1.DragonAPI.Sign:output_type -> azoo.dragon.v1.SignResponse
-	12, // 19: azoo.dragon.v1.DragonAPI.Verify:output_type -> azoo.dragon.v1.VerifyResponse
-	14, // 20: azoo.dragon.v1.DragonAPI.GenerateTOTP:output_type -> azoo.dragon.v1.GenerateTOTPResponse
-	16, // 21: azoo.dragon.v1.DragonAPI.VerifyTOTP:output_type -> azoo.dragon.v1.VerifyTOTPResponse
-	18, // 22: azoo.dragon.v1.DragonAPI.BatchVerifyTOTP:output_type -> azoo.dragon.v1.BatchVerifyTOTPResponse
-	20, // 23: azoo.dragon.v1.DragonAPI.DeleteTOTP:output_type -> azoo.dragon.v1.DeleteTOTPResponse
-	14, // [14:24] is the sub-list for method output_type
-	4,  // [4:14] is the sub-list for method input_type
-	4,  // [4:4] is the sub-list for extension type_name
-	4,  // [4:4] is the sub-list for extension extendee
-	0,  // [0:4] is the sub-list for field type_name
-}
-
-func init() { file_azoo_dragon_v1_dragon_api_proto_init() }
-func file_azoo_dragon_v1_dragon_api_proto_init() {
-	if File_azoo_dragon_v1_dragon_api_proto != nil {
-		return
-	}
-	if !protoimpl.UnsafeEnabled {
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateKeyRequest); i {
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTOTPRequest.ProtoReflect.Descriptor instead.
+func (*VerifyTOTPRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *VerifyTOTPRequest) GetKeyRing() string {
+	if x != nil {
+		return x.KeyRing
+	}
+	return ""
+}
+
+func (x *VerifyTOTPRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *VerifyTOTPRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *VerifyTOTPRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type VerifyTOTPResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// lockout is only set if the service enforces a TOTP lockout. While the
+	// account is locked the code isn't verified and valid is always false.
+	Lockout *TOTPLockout `protobuf:"bytes,2,opt,name=lockout,proto3" json:"lockout,omitempty"`
+}
+
+func (x *VerifyTOTPResponse) Reset() {
+	*x = VerifyTOTPResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *VerifyTOTPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VerifyTOTPResponse) ProtoMessage() {}
+
+func (x *VerifyTOTPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VerifyTOTPResponse.ProtoReflect.Descriptor instead.
+func (*VerifyTOTPResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *VerifyTOTPResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *VerifyTOTPResponse) GetLockout() *TOTPLockout {
+	if x != nil {
+		return x.Lockout
+	}
+	return nil
+}
+
+type BatchVerifyTOTPRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyRing   string   `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	Ids       []string `protobuf:"bytes,2,rep,name=ids,proto3" json:"ids,omitempty"`
+	AccountId string   `protobuf:"bytes,3,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	Code      string   `protobuf:"bytes,4,opt,name=code,proto3" json:"code,omitempty"`
+}
+
+func (x *BatchVerifyTOTPRequest) Reset() {
+	*x = BatchVerifyTOTPRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchVerifyTOTPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchVerifyTOTPRequest) ProtoMessage() {}
+
+func (x *BatchVerifyTOTPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchVerifyTOTPRequest.ProtoReflect.Descriptor instead.
+func (*BatchVerifyTOTPRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *BatchVerifyTOTPRequest) GetKeyRing() string {
+	if x != nil {
+		return x.KeyRing
+	}
+	return ""
+}
+
+func (x *BatchVerifyTOTPRequest) GetIds() []string {
+	if x != nil {
+		return x.Ids
+	}
+	return nil
+}
+
+func (x *BatchVerifyTOTPRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *BatchVerifyTOTPRequest) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+type BatchVerifyTOTPResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Valid          bool   `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	ValidThroughId string `protobuf:"bytes,2,opt,name=valid_through_id,json=validThroughId,proto3" json:"valid_through_id,omitempty"`
+	// lockout is the same as in VerifyTOTPResponse. A batch counts as a single
+	// verification attempt.
+	Lockout *TOTPLockout `protobuf:"bytes,3,opt,name=lockout,proto3" json:"lockout,omitempty"`
+}
+
+func (x *BatchVerifyTOTPResponse) Reset() {
+	*x = BatchVerifyTOTPResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BatchVerifyTOTPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BatchVerifyTOTPResponse) ProtoMessage() {}
+
+func (x *BatchVerifyTOTPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BatchVerifyTOTPResponse.ProtoReflect.Descriptor instead.
+func (*BatchVerifyTOTPResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *BatchVerifyTOTPResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *BatchVerifyTOTPResponse) GetValidThroughId() string {
+	if x != nil {
+		return x.ValidThroughId
+	}
+	return ""
+}
+
+func (x *BatchVerifyTOTPResponse) GetLockout() *TOTPLockout {
+	if x != nil {
+		return x.Lockout
+	}
+	return nil
+}
+
+type DeleteTOTPRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	Id      string `protobuf:"bytes,2,opt,name=id,proto3" json:"id,omitempty"`
+}
+
+func (x *DeleteTOTPRequest) Reset() {
+	*x = DeleteTOTPRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteTOTPRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTOTPRequest) ProtoMessage() {}
+
+func (x *DeleteTOTPRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTOTPRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTOTPRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DeleteTOTPRequest) GetKeyRing() string {
+	if x != nil {
+		return x.KeyRing
+	}
+	return ""
+}
+
+func (x *DeleteTOTPRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type DeleteTOTPResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteTOTPResponse) Reset() {
+	*x = DeleteTOTPResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteTOTPResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTOTPResponse) ProtoMessage() {}
+
+func (x *DeleteTOTPResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTOTPResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTOTPResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{27}
+}
+
+// TOTPLockout is the lockout state of an account. Failed verifications are
+// counted within a window, which starts with the first failure. The account
+// is locked as soon as the failures reach the service's maximum and stays
+// locked until the window ends.
+type TOTPLockout struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Locked bool `protobuf:"varint,1,opt,name=locked,proto3" json:"locked,omitempty"`
+	// failures is the amount of failed verifications in the current window.
+	Failures int32 `protobuf:"varint,2,opt,name=failures,proto3" json:"failures,omitempty"`
+	// remaining_attempts is the amount of failed verifications until the
+	// account is locked.
+	RemainingAttempts int32 `protobuf:"varint,3,opt,name=remaining_attempts,json=remainingAttempts,proto3" json:"remaining_attempts,omitempty"`
+	// window_end is the end of the current window as unix timestamp in seconds,
+	// or 0 if there were no failures.
+	WindowEnd int64 `protobuf:"varint,4,opt,name=window_end,json=windowEnd,proto3" json:"window_end,omitempty"`
+}
+
+func (x *TOTPLockout) Reset() {
+	*x = TOTPLockout{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TOTPLockout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TOTPLockout) ProtoMessage() {}
+
+func (x *TOTPLockout) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TOTPLockout.ProtoReflect.Descriptor instead.
+func (*TOTPLockout) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *TOTPLockout) GetLocked() bool {
+	if x != nil {
+		return x.Locked
+	}
+	return false
+}
+
+func (x *TOTPLockout) GetFailures() int32 {
+	if x != nil {
+		return x.Failures
+	}
+	return 0
+}
+
+func (x *TOTPLockout) GetRemainingAttempts() int32 {
+	if x != nil {
+		return x.RemainingAttempts
+	}
+	return 0
+}
+
+func (x *TOTPLockout) GetWindowEnd() int64 {
+	if x != nil {
+		return x.WindowEnd
+	}
+	return 0
+}
+
+type GetTOTPLockoutRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyRing   string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	AccountId string `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+}
+
+func (x *GetTOTPLockoutRequest) Reset() {
+	*x = GetTOTPLockoutRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTOTPLockoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTOTPLockoutRequest) ProtoMessage() {}
+
+func (x *GetTOTPLockoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTOTPLockoutRequest.ProtoReflect.Descriptor instead.
+func (*GetTOTPLockoutRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *GetTOTPLockoutRequest) GetKeyRing() string {
+	if x != nil {
+		return x.KeyRing
+	}
+	return ""
+}
+
+func (x *GetTOTPLockoutRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+type GetTOTPLockoutResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Lockout *TOTPLockout `protobuf:"bytes,1,opt,name=lockout,proto3" json:"lockout,omitempty"`
+}
+
+func (x *GetTOTPLockoutResponse) Reset() {
+	*x = GetTOTPLockoutResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[30]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetTOTPLockoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTOTPLockoutResponse) ProtoMessage() {}
+
+func (x *GetTOTPLockoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[30]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTOTPLockoutResponse.ProtoReflect.Descriptor instead.
+func (*GetTOTPLockoutResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *GetTOTPLockoutResponse) GetLockout() *TOTPLockout {
+	if x != nil {
+		return x.Lockout
+	}
+	return nil
+}
+
+type ResetTOTPLockoutRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyRing   string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+	AccountId string `protobuf:"bytes,2,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+}
+
+func (x *ResetTOTPLockoutRequest) Reset() {
+	*x = ResetTOTPLockoutRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[31]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetTOTPLockoutRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetTOTPLockoutRequest) ProtoMessage() {}
+
+func (x *ResetTOTPLockoutRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[31]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetTOTPLockoutRequest.ProtoReflect.Descriptor instead.
+func (*ResetTOTPLockoutRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ResetTOTPLockoutRequest) GetKeyRing() string {
+	if x != nil {
+		return x.KeyRing
+	}
+	return ""
+}
+
+func (x *ResetTOTPLockoutRequest) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+type ResetTOTPLockoutResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *ResetTOTPLockoutResponse) Reset() {
+	*x = ResetTOTPLockoutResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[32]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ResetTOTPLockoutResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetTOTPLockoutResponse) ProtoMessage() {}
+
+func (x *ResetTOTPLockoutResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[32]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetTOTPLockoutResponse.ProtoReflect.Descriptor instead.
+func (*ResetTOTPLockoutResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{32}
+}
+
+// KeyPoolCacheStats are the cache statistics of the KeyPool of a version.
+type KeyPoolCacheStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// version is the major dvx version of the KeyPool, e.g. "dv2".
+	Version string `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	// caching is false if the KeyPool doesn't cache keys.
+	Caching bool `protobuf:"varint,2,opt,name=caching,proto3" json:"caching,omitempty"`
+	// hits is the amount of keys served from the cache.
+	Hits uint64 `protobuf:"varint,3,opt,name=hits,proto3" json:"hits,omitempty"`
+	// misses is the amount of keys derived by the underlying KeyPool.
+	Misses uint64 `protobuf:"varint,4,opt,name=misses,proto3" json:"misses,omitempty"`
+	// shards are the adaptive replacement caches of a tearc KeyPool, ordered
+	// by shard.
+	Shards []*CacheShardStats `protobuf:"bytes,5,rep,name=shards,proto3" json:"shards,omitempty"`
+}
+
+func (x *KeyPoolCacheStats) Reset() {
+	*x = KeyPoolCacheStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[33]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *KeyPoolCacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*KeyPoolCacheStats) ProtoMessage() {}
+
+func (x *KeyPoolCacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[33]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use KeyPoolCacheStats.ProtoReflect.Descriptor instead.
+func (*KeyPoolCacheStats) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *KeyPoolCacheStats) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *KeyPoolCacheStats) GetCaching() bool {
+	if x != nil {
+		return x.Caching
+	}
+	return false
+}
+
+func (x *KeyPoolCacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *KeyPoolCacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *KeyPoolCacheStats) GetShards() []*CacheShardStats {
+	if x != nil {
+		return x.Shards
+	}
+	return nil
+}
+
+// CacheShardStats are the partition sizes of the adaptive replacement cache
+// (ARC) of a shard. t1 holds the keys used once, t2 the keys used at least
+// twice, b1 and b2 are the ghost lists of keys recently replaced from t1 and
+// t2.
+type CacheShardStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// size is the maximum amount of cached keys.
+	Size int32 `protobuf:"varint,1,opt,name=size,proto3" json:"size,omitempty"`
+	// target is the adaptive target size of t1.
+	Target int32 `protobuf:"varint,2,opt,name=target,proto3" json:"target,omitempty"`
+	T1     int32 `protobuf:"varint,3,opt,name=t1,proto3" json:"t1,omitempty"`
+	T2     int32 `protobuf:"varint,4,opt,name=t2,proto3" json:"t2,omitempty"`
+	B1     int32 `protobuf:"varint,5,opt,name=b1,proto3" json:"b1,omitempty"`
+	B2     int32 `protobuf:"varint,6,opt,name=b2,proto3" json:"b2,omitempty"`
+	// b1_hits and b2_hits are the amounts of keys derived again while they
+	// were in b1 and b2.
+	B1Hits uint64 `protobuf:"varint,7,opt,name=b1_hits,json=b1Hits,proto3" json:"b1_hits,omitempty"`
+	B2Hits uint64 `protobuf:"varint,8,opt,name=b2_hits,json=b2Hits,proto3" json:"b2_hits,omitempty"`
+}
+
+func (x *CacheShardStats) Reset() {
+	*x = CacheShardStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[34]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CacheShardStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheShardStats) ProtoMessage() {}
+
+func (x *CacheShardStats) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[34]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheShardStats.ProtoReflect.Descriptor instead.
+func (*CacheShardStats) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *CacheShardStats) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *CacheShardStats) GetTarget() int32 {
+	if x != nil {
+		return x.Target
+	}
+	return 0
+}
+
+func (x *CacheShardStats) GetT1() int32 {
+	if x != nil {
+		return x.T1
+	}
+	return 0
+}
+
+func (x *CacheShardStats) GetT2() int32 {
+	if x != nil {
+		return x.T2
+	}
+	return 0
+}
+
+func (x *CacheShardStats) GetB1() int32 {
+	if x != nil {
+		return x.B1
+	}
+	return 0
+}
+
+func (x *CacheShardStats) GetB2() int32 {
+	if x != nil {
+		return x.B2
+	}
+	return 0
+}
+
+func (x *CacheShardStats) GetB1Hits() uint64 {
+	if x != nil {
+		return x.B1Hits
+	}
+	return 0
+}
+
+func (x *CacheShardStats) GetB2Hits() uint64 {
+	if x != nil {
+		return x.B2Hits
+	}
+	return 0
+}
+
+type GetCacheStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetCacheStatsRequest) Reset() {
+	*x = GetCacheStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[35]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCacheStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheStatsRequest) ProtoMessage() {}
+
+func (x *GetCacheStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[35]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetCacheStatsRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{35}
+}
+
+type GetCacheStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyPools []*KeyPoolCacheStats `protobuf:"bytes,1,rep,name=key_pools,json=keyPools,proto3" json:"key_pools,omitempty"`
+	// kdf_calls is the amount of keys requested from all KeyPools.
+	KdfCalls uint64 `protobuf:"varint,2,opt,name=kdf_calls,json=kdfCalls,proto3" json:"kdf_calls,omitempty"`
+}
+
+func (x *GetCacheStatsResponse) Reset() {
+	*x = GetCacheStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[36]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetCacheStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCacheStatsResponse) ProtoMessage() {}
+
+func (x *GetCacheStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[36]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCacheStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetCacheStatsResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *GetCacheStatsResponse) GetKeyPools() []*KeyPoolCacheStats {
+	if x != nil {
+		return x.KeyPools
+	}
+	return nil
+}
+
+func (x *GetCacheStatsResponse) GetKdfCalls() uint64 {
+	if x != nil {
+		return x.KdfCalls
+	}
+	return 0
+}
+
+type InvalidateKeyRingRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	KeyRing string `protobuf:"bytes,1,opt,name=key_ring,json=keyRing,proto3" json:"key_ring,omitempty"`
+}
+
+func (x *InvalidateKeyRingRequest) Reset() {
+	*x = InvalidateKeyRingRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[37]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InvalidateKeyRingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateKeyRingRequest) ProtoMessage() {}
+
+func (x *InvalidateKeyRingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[37]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateKeyRingRequest.ProtoReflect.Descriptor instead.
+func (*InvalidateKeyRingRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *InvalidateKeyRingRequest) GetKeyRing() string {
+	if x != nil {
+		return x.KeyRing
+	}
+	return ""
+}
+
+type InvalidateKeyRingResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// removed is the amount of cached keys that were removed.
+	Removed int32 `protobuf:"varint,1,opt,name=removed,proto3" json:"removed,omitempty"`
+	// key_ring_fingerprint identifies the keyRing in audit logs without
+	// revealing it (see dvx.KeyRingFingerprint).
+	KeyRingFingerprint string `protobuf:"bytes,2,opt,name=key_ring_fingerprint,json=keyRingFingerprint,proto3" json:"key_ring_fingerprint,omitempty"`
+}
+
+func (x *InvalidateKeyRingResponse) Reset() {
+	*x = InvalidateKeyRingResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[38]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *InvalidateKeyRingResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InvalidateKeyRingResponse) ProtoMessage() {}
+
+func (x *InvalidateKeyRingResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[38]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InvalidateKeyRingResponse.ProtoReflect.Descriptor instead.
+func (*InvalidateKeyRingResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *InvalidateKeyRingResponse) GetRemoved() int32 {
+	if x != nil {
+		return x.Removed
+	}
+	return 0
+}
+
+func (x *InvalidateKeyRingResponse) GetKeyRingFingerprint() string {
+	if x != nil {
+		return x.KeyRingFingerprint
+	}
+	return ""
+}
+
+type GetKeyPoolHealthRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetKeyPoolHealthRequest) Reset() {
+	*x = GetKeyPoolHealthRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[39]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetKeyPoolHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKeyPoolHealthRequest) ProtoMessage() {}
+
+func (x *GetKeyPoolHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[39]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKeyPoolHealthRequest.ProtoReflect.Descriptor instead.
+func (*GetKeyPoolHealthRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{39}
+}
+
+type GetKeyPoolHealthResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Healthy bool `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
+	// error describes the first failed check if healthy is false.
+	Error string `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *GetKeyPoolHealthResponse) Reset() {
+	*x = GetKeyPoolHealthResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[40]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetKeyPoolHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetKeyPoolHealthResponse) ProtoMessage() {}
+
+func (x *GetKeyPoolHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[40]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetKeyPoolHealthResponse.ProtoReflect.Descriptor instead.
+func (*GetKeyPoolHealthResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *GetKeyPoolHealthResponse) GetHealthy() bool {
+	if x != nil {
+		return x.Healthy
+	}
+	return false
+}
+
+func (x *GetKeyPoolHealthResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetRootKeyGenerationsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetRootKeyGenerationsRequest) Reset() {
+	*x = GetRootKeyGenerationsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[41]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRootKeyGenerationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRootKeyGenerationsRequest) ProtoMessage() {}
+
+func (x *GetRootKeyGenerationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[41]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRootKeyGenerationsRequest.ProtoReflect.Descriptor instead.
+func (*GetRootKeyGenerationsRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{41}
+}
+
+type GetRootKeyGenerationsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// generations maps the major dvx versions to the generation of their root
+	// key. It starts at 1 and is incremented by every rotation. KeyPools
+	// without generations are omitted.
+	Generations map[string]uint64 `protobuf:"bytes,1,rep,name=generations,proto3" json:"generations,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3"`
+}
+
+func (x *GetRootKeyGenerationsResponse) Reset() {
+	*x = GetRootKeyGenerationsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[42]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetRootKeyGenerationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRootKeyGenerationsResponse) ProtoMessage() {}
+
+func (x *GetRootKeyGenerationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[42]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRootKeyGenerationsResponse.ProtoReflect.Descriptor instead.
+func (*GetRootKeyGenerationsResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *GetRootKeyGenerationsResponse) GetGenerations() map[string]uint64 {
+	if x != nil {
+		return x.Generations
+	}
+	return nil
+}
+
+type GetAuditSinkStatusRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetAuditSinkStatusRequest) Reset() {
+	*x = GetAuditSinkStatusRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[43]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAuditSinkStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditSinkStatusRequest) ProtoMessage() {}
+
+func (x *GetAuditSinkStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[43]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditSinkStatusRequest.ProtoReflect.Descriptor instead.
+func (*GetAuditSinkStatusRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{43}
+}
+
+type GetAuditSinkStatusResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// configured is false if the service has no check of its audit sink. The
+	// status is unknown in this case.
+	Configured bool `protobuf:"varint,1,opt,name=configured,proto3" json:"configured,omitempty"`
+	Available  bool `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`
+	// error describes why the sink is unavailable.
+	Error string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+}
+
+func (x *GetAuditSinkStatusResponse) Reset() {
+	*x = GetAuditSinkStatusResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[44]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetAuditSinkStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAuditSinkStatusResponse) ProtoMessage() {}
+
+func (x *GetAuditSinkStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[44]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAuditSinkStatusResponse.ProtoReflect.Descriptor instead.
+func (*GetAuditSinkStatusResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *GetAuditSinkStatusResponse) GetConfigured() bool {
+	if x != nil {
+		return x.Configured
+	}
+	return false
+}
+
+func (x *GetAuditSinkStatusResponse) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *GetAuditSinkStatusResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type GetDualRunStatsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetDualRunStatsRequest) Reset() {
+	*x = GetDualRunStatsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[45]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDualRunStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDualRunStatsRequest) ProtoMessage() {}
+
+func (x *GetDualRunStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[45]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDualRunStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetDualRunStatsRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{45}
+}
+
+type GetDualRunStatsResponse struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// enabled is false if the service doesn't run in dual-run mode.
+	Enabled bool `protobuf:"varint,1,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	// version is the dvx version of the shadow operations, e.g. "dv1".
+	Version string `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	// sample_rate is the fraction of operations that are repeated.
+	SampleRate float64 `protobuf:"fixed64,3,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	// methods contains the results of every method with samples.
+	Methods []*DualRunMethodStats `protobuf:"bytes,4,rep,name=methods,proto3" json:"methods,omitempty"`
+}
+
+func (x *GetDualRunStatsResponse) Reset() {
+	*x = GetDualRunStatsResponse{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[46]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetDualRunStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetDualRunStatsResponse) ProtoMessage() {}
+
+func (x *GetDualRunStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[46]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetDualRunStatsResponse.ProtoReflect.Descriptor instead.
+func (*GetDualRunStatsResponse) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *GetDualRunStatsResponse) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *GetDualRunStatsResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *GetDualRunStatsResponse) GetSampleRate() float64 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *GetDualRunStatsResponse) GetMethods() []*DualRunMethodStats {
+	if x != nil {
+		return x.Methods
+	}
+	return nil
+}
+
+type DualRunMethodStats struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// method is the RPC method name, e.g. "Encrypt".
+	Method string `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	// samples is the amount of compared operations.
+	Samples uint64 `protobuf:"varint,2,opt,name=samples,proto3" json:"samples,omitempty"`
+	// divergences is the amount of samples whose shadow operation behaved
+	// differently, e.g. failed while the operation succeeded or produced a
+	// result that couldn't be decrypted or verified.
+	Divergences uint64 `protobuf:"varint,3,opt,name=divergences,proto3" json:"divergences,omitempty"`
+	// skipped is the amount of sampled operations that weren't repeated,
+	// because too many shadow operations were running.
+	Skipped uint64 `protobuf:"varint,4,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	// primary_duration_ms and shadow_duration_ms are the total durations of
+	// the sampled operations and of their shadow operations in milliseconds.
+	PrimaryDurationMs float64 `protobuf:"fixed64,5,opt,name=primary_duration_ms,json=primaryDurationMs,proto3" json:"primary_duration_ms,omitempty"`
+	ShadowDurationMs  float64 `protobuf:"fixed64,6,opt,name=shadow_duration_ms,json=shadowDurationMs,proto3" json:"shadow_duration_ms,omitempty"`
+}
+
+func (x *DualRunMethodStats) Reset() {
+	*x = DualRunMethodStats{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[47]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DualRunMethodStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DualRunMethodStats) ProtoMessage() {}
+
+func (x *DualRunMethodStats) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[47]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DualRunMethodStats.ProtoReflect.Descriptor instead.
+func (*DualRunMethodStats) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *DualRunMethodStats) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *DualRunMethodStats) GetSamples() uint64 {
+	if x != nil {
+		return x.Samples
+	}
+	return 0
+}
+
+func (x *DualRunMethodStats) GetDivergences() uint64 {
+	if x != nil {
+		return x.Divergences
+	}
+	return 0
+}
+
+func (x *DualRunMethodStats) GetSkipped() uint64 {
+	if x != nil {
+		return x.Skipped
+	}
+	return 0
+}
+
+func (x *DualRunMethodStats) GetPrimaryDurationMs() float64 {
+	if x != nil {
+		return x.PrimaryDurationMs
+	}
+	return 0
+}
+
+func (x *DualRunMethodStats) GetShadowDurationMs() float64 {
+	if x != nil {
+		return x.ShadowDurationMs
+	}
+	return 0
+}
+
+// ExportAuditEventsRequest starts the audit event stream of the JSON gateway
+// (GET /v1/stream/audit). Twirp has no streaming RPCs, therefore it isn't a
+// method of DragonAPI, but is authorized like the admin methods.
+type ExportAuditEventsRequest struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// after is the sequence of the last event the consumer processed. The
+	// stream starts with the next event still buffered by the service.
+	After uint64 `protobuf:"varint,1,opt,name=after,proto3" json:"after,omitempty"`
+	// log_id is the log_id of the event of after. If it differs from the
+	// current log of the service (e.g. after a restart), after is ignored and
+	// the stream starts with the oldest buffered event.
+	LogId string `protobuf:"bytes,2,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+}
+
+func (x *ExportAuditEventsRequest) Reset() {
+	*x = ExportAuditEventsRequest{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[48]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ExportAuditEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportAuditEventsRequest) ProtoMessage() {}
+
+func (x *ExportAuditEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[48]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportAuditEventsRequest.ProtoReflect.Descriptor instead.
+func (*ExportAuditEventsRequest) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *ExportAuditEventsRequest) GetAfter() uint64 {
+	if x != nil {
+		return x.After
+	}
+	return 0
+}
+
+func (x *ExportAuditEventsRequest) GetLogId() string {
+	if x != nil {
+		return x.LogId
+	}
+	return ""
+}
+
+// AuditEvent is a structured audit event of a method call.
+type AuditEvent struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// log_id identifies the audit log of a service instance. Sequences are
+	// unique per log_id, consumers deduplicate redelivered events by both.
+	LogId string `protobuf:"bytes,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	// sequence numbers the events of a log consecutively, starting at 1. A gap
+	// means events were dropped before they were delivered.
+	Sequence uint64 `protobuf:"varint,2,opt,name=sequence,proto3" json:"sequence,omitempty"`
+	// time is the end of the call as unix timestamp in milliseconds.
+	Time int64 `protobuf:"varint,3,opt,name=time,proto3" json:"time,omitempty"`
+	// method is the RPC method name, e.g. "Encrypt".
+	Method string `protobuf:"bytes,4,opt,name=method,proto3" json:"method,omitempty"`
+	// key_ring_fingerprint is the dvx.KeyRingFingerprint of the keyRing used
+	// by the method, including the caller's tenant. Empty for methods without
+	// keyRing.
+	KeyRingFingerprint string `protobuf:"bytes,5,opt,name=key_ring_fingerprint,json=keyRingFingerprint,proto3" json:"key_ring_fingerprint,omitempty"`
+	// caller is the caller identity, if known.
+	Caller        string `protobuf:"bytes,6,opt,name=caller,proto3" json:"caller,omitempty"`
+	CorrelationId string `protobuf:"bytes,7,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	// result is "ok", or the Twirp error code of a failed call.
+	Result string `protobuf:"bytes,8,opt,name=result,proto3" json:"result,omitempty"`
+	// reason is the machine-readable reason of a failed call, if any.
+	Reason string `protobuf:"bytes,9,opt,name=reason,proto3" json:"reason,omitempty"`
+}
+
+func (x *AuditEvent) Reset() {
+	*x = AuditEvent{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[49]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *AuditEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AuditEvent) ProtoMessage() {}
+
+func (x *AuditEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[49]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AuditEvent.ProtoReflect.Descriptor instead.
+func (*AuditEvent) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *AuditEvent) GetLogId() string {
+	if x != nil {
+		return x.LogId
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetSequence() uint64 {
+	if x != nil {
+		return x.Sequence
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetTime() int64 {
+	if x != nil {
+		return x.Time
+	}
+	return 0
+}
+
+func (x *AuditEvent) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetKeyRingFingerprint() string {
+	if x != nil {
+		return x.KeyRingFingerprint
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetCaller() string {
+	if x != nil {
+		return x.Caller
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+func (x *AuditEvent) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+// Error is the error model of the DragonAPI. The JSON gateway returns it as
+// {"error": Error}. Twirp errors carry code and message as usual and all
+// other fields as error meta values with the same names: the category as its
+// enum value name and retryable as "true" or "false".
+type Error struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// code is the Twirp error code, e.g. "invalid_argument".
+	Code string `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	// message is a human-readable description of the error. Clients must not
+	// parse it.
+	Message string `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	// reason is the machine-readable dvx error class, e.g. "invalid_format",
+	// if the error was caused by the dvx Protocol.
+	Reason string `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	// argument is the name of the invalid argument, if any.
+	Argument string        `protobuf:"bytes,4,opt,name=argument,proto3" json:"argument,omitempty"`
+	Category ErrorCategory `protobuf:"varint,5,opt,name=category,proto3,enum=azoo.dragon.v1.ErrorCategory" json:"category,omitempty"`
+	// retryable reports whether the same request may succeed if it is retried
+	// with backoff.
+	Retryable bool `protobuf:"varint,6,opt,name=retryable,proto3" json:"retryable,omitempty"`
+	// correlation_id identifies the request in the logs of the service. It is
+	// taken from the X-Correlation-Id request header, or generated.
+	CorrelationId string `protobuf:"bytes,7,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+}
+
+func (x *Error) Reset() {
+	*x = Error{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[50]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Error) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Error) ProtoMessage() {}
+
+func (x *Error) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[50]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Error.ProtoReflect.Descriptor instead.
+func (*Error) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *Error) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *Error) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *Error) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *Error) GetArgument() string {
+	if x != nil {
+		return x.Argument
+	}
+	return ""
+}
+
+func (x *Error) GetCategory() ErrorCategory {
+	if x != nil {
+		return x.Category
+	}
+	return ErrorCategory_ERROR_CATEGORY_UNSPECIFIED
+}
+
+func (x *Error) GetRetryable() bool {
+	if x != nil {
+		return x.Retryable
+	}
+	return false
+}
+
+func (x *Error) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+type CreateKeyResponse_EncryptionKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CreateKeyResponse_EncryptionKey) Reset() {
+	*x = CreateKeyResponse_EncryptionKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[51]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateKeyResponse_EncryptionKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateKeyResponse_EncryptionKey) ProtoMessage() {}
+
+func (x *CreateKeyResponse_EncryptionKey) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[51]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateKeyResponse_EncryptionKey.ProtoReflect.Descriptor instead.
+func (*CreateKeyResponse_EncryptionKey) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{1, 0}
+}
+
+type CreateKeyResponse_SigningKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	PublicKey []byte `protobuf:"bytes,1,opt,name=public_key,json=publicKey,proto3" json:"public_key,omitempty"`
+}
+
+func (x *CreateKeyResponse_SigningKey) Reset() {
+	*x = CreateKeyResponse_SigningKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[52]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateKeyResponse_SigningKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateKeyResponse_SigningKey) ProtoMessage() {}
+
+func (x *CreateKeyResponse_SigningKey) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[52]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateKeyResponse_SigningKey.ProtoReflect.Descriptor instead.
+func (*CreateKeyResponse_SigningKey) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{1, 1}
+}
+
+func (x *CreateKeyResponse_SigningKey) GetPublicKey() []byte {
+	if x != nil {
+		return x.PublicKey
+	}
+	return nil
+}
+
+type CreateKeyResponse_MACKey struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *CreateKeyResponse_MACKey) Reset() {
+	*x = CreateKeyResponse_MACKey{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[53]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *CreateKeyResponse_MACKey) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateKeyResponse_MACKey) ProtoMessage() {}
+
+func (x *CreateKeyResponse_MACKey) ProtoReflect() protoreflect.Message {
+	mi := &file_azoo_dragon_v1_dragon_api_proto_msgTypes[53]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateKeyResponse_MACKey.ProtoReflect.Descriptor instead.
+func (*CreateKeyResponse_MACKey) Descriptor() ([]byte, []int) {
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP(), []int{1, 2}
+}
+
+var File_azoo_dragon_v1_dragon_api_proto protoreflect.FileDescriptor
+
+var file_azoo_dragon_v1_dragon_api_proto_rawDesc = []byte{
+	0x0a, 0x1f, 0x61, 0x7a, 0x6f, 0x6f, 0x2f, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2f, 0x76, 0x31,
+	0x2f, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x5f, 0x61, 0x70, 0x69, 0x2e, 0x70, 0x72, 0x6f, 0x74,
+	0x6f, 0x12, 0x0e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x22, 0xb7, 0x01, 0x0a, 0x10, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69,
+	0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e,
+	0x67, 0x12, 0x39, 0x0a, 0x04, 0x74, 0x79, 0x70, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0e, 0x32,
+	0x25, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x2e, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65, 0x22, 0x4d, 0x0a, 0x04,
+	0x54, 0x79, 0x70, 0x65, 0x12, 0x10, 0x0a, 0x0c, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x49, 0x4e, 0x56,
+	0x41, 0x4c, 0x49, 0x44, 0x10, 0x00, 0x12, 0x13, 0x0a, 0x0f, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x45,
+	0x4e, 0x43, 0x52, 0x59, 0x50, 0x54, 0x49, 0x4f, 0x4e, 0x10, 0x01, 0x12, 0x10, 0x0a, 0x0c, 0x54,
+	0x59, 0x50, 0x45, 0x5f, 0x53, 0x49, 0x47, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x02, 0x12, 0x0c, 0x0a,
+	0x08, 0x54, 0x59, 0x50, 0x45, 0x5f, 0x4d, 0x41, 0x43, 0x10, 0x03, 0x22, 0xc5, 0x02, 0x0a, 0x11,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x56, 0x0a, 0x0e, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x5f,
+	0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2f, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
+	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x45, 0x6e, 0x63,
+	0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x52, 0x0d, 0x65, 0x6e, 0x63, 0x72,
+	0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x12, 0x4d, 0x0a, 0x0b, 0x73, 0x69, 0x67,
+	0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x2c,
+	0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x52, 0x0a, 0x73, 0x69,
+	0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x12, 0x41, 0x0a, 0x07, 0x6d, 0x61, 0x63, 0x5f,
+	0x6b, 0x65, 0x79, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x28, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
+	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74,
+	0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x4d, 0x41, 0x43,
+	0x4b, 0x65, 0x79, 0x52, 0x06, 0x6d, 0x61, 0x63, 0x4b, 0x65, 0x79, 0x1a, 0x0f, 0x0a, 0x0d, 0x45,
+	0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x4b, 0x65, 0x79, 0x1a, 0x2b, 0x0a, 0x0a,
+	0x53, 0x69, 0x67, 0x6e, 0x69, 0x6e, 0x67, 0x4b, 0x65, 0x79, 0x12, 0x1d, 0x0a, 0x0a, 0x70, 0x75,
+	0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09,
+	0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x1a, 0x08, 0x0a, 0x06, 0x4d, 0x41, 0x43,
+	0x4b, 0x65, 0x79, 0x22, 0x57, 0x0a, 0x0e, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e,
+	0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67,
+	0x12, 0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x64, 0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x66, 0x6f, 0x6f, 0x74, 0x65, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x06, 0x66, 0x6f, 0x6f, 0x74, 0x65, 0x72, 0x22, 0x31, 0x0a, 0x0f,
+	0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x1e, 0x0a, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74, 0x22,
+	0x4b, 0x0a, 0x0e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x1e, 0x0a, 0x0a,
+	0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x63, 0x69, 0x70, 0x68, 0x65, 0x72, 0x74, 0x65, 0x78, 0x74, 0x22, 0x25, 0x0a, 0x0f,
+	0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x22, 0x33, 0x0a, 0x16, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x44,
+	0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a,
+	0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x22, 0x58, 0x0a, 0x17, 0x47, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f,
+	0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x78, 0x74,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x78,
+	0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x72, 0x61, 0x70, 0x70, 0x65, 0x64, 0x5f, 0x6b, 0x65, 0x79,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77, 0x72, 0x61, 0x70, 0x70, 0x65, 0x64, 0x4b,
+	0x65, 0x79, 0x22, 0x53, 0x0a, 0x15, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x44, 0x61, 0x74,
+	0x61, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b,
+	0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b,
+	0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x1f, 0x0a, 0x0b, 0x77, 0x72, 0x61, 0x70, 0x70, 0x65,
+	0x64, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x77, 0x72, 0x61,
+	0x70, 0x70, 0x65, 0x64, 0x4b, 0x65, 0x79, 0x22, 0x36, 0x0a, 0x16, 0x44, 0x65, 0x63, 0x72, 0x79,
+	0x70, 0x74, 0x44, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x1c, 0x0a, 0x09, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x0c, 0x52, 0x09, 0x70, 0x6c, 0x61, 0x69, 0x6e, 0x74, 0x65, 0x78, 0x74, 0x22,
+	0x3c, 0x0a, 0x10, 0x44, 0x65, 0x72, 0x69, 0x76, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x05, 0x69, 0x6e, 0x70, 0x75, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x22, 0x25, 0x0a,
+	0x11, 0x44, 0x65, 0x72, 0x69, 0x76, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x22, 0x41, 0x0a, 0x0a, 0x4d, 0x41, 0x43, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x1f, 0x0a, 0x0b, 0x4d, 0x41, 0x43, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x10, 0x0a, 0x03, 0x74, 0x61, 0x67, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x03, 0x74, 0x61, 0x67, 0x22, 0x42, 0x0a, 0x0b, 0x53, 0x69, 0x67, 0x6e,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72,
+	0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69,
+	0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x22, 0x51, 0x0a, 0x0c,
+	0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1c, 0x0a, 0x09,
+	0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x72, 0x61,
+	0x77, 0x5f, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x0c, 0x72, 0x61, 0x77, 0x53, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75, 0x72, 0x65, 0x22,
+	0x62, 0x0a, 0x0d, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x18, 0x0a, 0x07, 0x6d,
+	0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74, 0x75,
+	0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61, 0x74,
+	0x75, 0x72, 0x65, 0x22, 0x26, 0x0a, 0x0e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x22, 0x68, 0x0a, 0x0f, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x50, 0x4b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x1d,
+	0x0a, 0x0a, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x5f, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x09, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x4b, 0x65, 0x79, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x12, 0x1c, 0x0a, 0x09, 0x73, 0x69, 0x67, 0x6e, 0x61,
+	0x74, 0x75, 0x72, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x73, 0x69, 0x67, 0x6e,
+	0x61, 0x74, 0x75, 0x72, 0x65, 0x22, 0x28, 0x0a, 0x10, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x50,
+	0x4b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x22,
+	0x8a, 0x01, 0x0a, 0x13, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72,
+	0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69,
+	0x6e, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x69, 0x73, 0x73, 0x75, 0x65, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x61, 0x63,
+	0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0b, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x1d, 0x0a,
+	0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x51, 0x0a, 0x14,
+	0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x02, 0x69, 0x64, 0x12, 0x10, 0x0a, 0x03, 0x75, 0x72, 0x69, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x75, 0x72, 0x69, 0x12, 0x17, 0x0a, 0x07, 0x71, 0x72, 0x5f, 0x63, 0x6f, 0x64,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x71, 0x72, 0x43, 0x6f, 0x64, 0x65, 0x22,
+	0x71, 0x0a, 0x11, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71,
+	0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12,
+	0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12,
+	0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12,
+	0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f,
+	0x64, 0x65, 0x22, 0x61, 0x0a, 0x12, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x12, 0x35,
+	0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32,
+	0x1b, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x07, 0x6c, 0x6f,
+	0x63, 0x6b, 0x6f, 0x75, 0x74, 0x22, 0x78, 0x0a, 0x16, 0x42, 0x61, 0x74, 0x63, 0x68, 0x56, 0x65,
+	0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12,
+	0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x10, 0x0a, 0x03, 0x69, 0x64,
+	0x73, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x03, 0x69, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a,
+	0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x12, 0x12, 0x0a, 0x04, 0x63,
+	0x6f, 0x64, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x22,
+	0x90, 0x01, 0x0a, 0x17, 0x42, 0x61, 0x74, 0x63, 0x68, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54,
+	0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x76,
+	0x61, 0x6c, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x69,
+	0x64, 0x12, 0x28, 0x0a, 0x10, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x5f, 0x74, 0x68, 0x72, 0x6f, 0x75,
+	0x67, 0x68, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x54, 0x68, 0x72, 0x6f, 0x75, 0x67, 0x68, 0x49, 0x64, 0x12, 0x35, 0x0a, 0x07, 0x6c,
+	0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x61,
+	0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x4f,
+	0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x6b, 0x6f,
+	0x75, 0x74, 0x22, 0x3e, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72,
+	0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69,
+	0x6e, 0x67, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x02,
+	0x69, 0x64, 0x22, 0x14, 0x0a, 0x12, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50,
+	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x8f, 0x01, 0x0a, 0x0b, 0x54, 0x4f, 0x54,
+	0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x6c, 0x6f, 0x63, 0x6b,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x06, 0x6c, 0x6f, 0x63, 0x6b, 0x65, 0x64,
+	0x12, 0x1a, 0x0a, 0x08, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x08, 0x66, 0x61, 0x69, 0x6c, 0x75, 0x72, 0x65, 0x73, 0x12, 0x2d, 0x0a, 0x12,
+	0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e, 0x69, 0x6e, 0x67, 0x5f, 0x61, 0x74, 0x74, 0x65, 0x6d, 0x70,
+	0x74, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x11, 0x72, 0x65, 0x6d, 0x61, 0x69, 0x6e,
+	0x69, 0x6e, 0x67, 0x41, 0x74, 0x74, 0x65, 0x6d, 0x70, 0x74, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x77,
+	0x69, 0x6e, 0x64, 0x6f, 0x77, 0x5f, 0x65, 0x6e, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52,
+	0x09, 0x77, 0x69, 0x6e, 0x64, 0x6f, 0x77, 0x45, 0x6e, 0x64, 0x22, 0x51, 0x0a, 0x15, 0x47, 0x65,
+	0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x12, 0x1d,
+	0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x49, 0x64, 0x22, 0x4f, 0x0a,
+	0x16, 0x47, 0x65, 0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x35, 0x0a, 0x07, 0x6c, 0x6f, 0x63, 0x6b, 0x6f,
+	0x75, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1b, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e,
+	0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f,
+	0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x07, 0x6c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x22, 0x53,
+	0x0a, 0x17, 0x52, 0x65, 0x73, 0x65, 0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f,
+	0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65, 0x79,
+	0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65, 0x79,
+	0x52, 0x69, 0x6e, 0x67, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x5f,
+	0x69, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x63, 0x63, 0x6f, 0x75, 0x6e,
+	0x74, 0x49, 0x64, 0x22, 0x1a, 0x0a, 0x18, 0x52, 0x65, 0x73, 0x65, 0x74, 0x54, 0x4f, 0x54, 0x50,
+	0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0xac, 0x01, 0x0a, 0x11, 0x4b, 0x65, 0x79, 0x50, 0x6f, 0x6f, 0x6c, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69, 0x6f, 0x6e, 0x12,
+	0x18, 0x0a, 0x07, 0x63, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08,
+	0x52, 0x07, 0x63, 0x61, 0x63, 0x68, 0x69, 0x6e, 0x67, 0x12, 0x12, 0x0a, 0x04, 0x68, 0x69, 0x74,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52, 0x04, 0x68, 0x69, 0x74, 0x73, 0x12, 0x16, 0x0a,
+	0x06, 0x6d, 0x69, 0x73, 0x73, 0x65, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x6d,
+	0x69, 0x73, 0x73, 0x65, 0x73, 0x12, 0x37, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x18,
+	0x05, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1f, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61,
+	0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x68, 0x61, 0x72,
+	0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x06, 0x73, 0x68, 0x61, 0x72, 0x64, 0x73, 0x22, 0xaf,
+	0x01, 0x0a, 0x0f, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x68, 0x61, 0x72, 0x64, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05,
+	0x52, 0x04, 0x73, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x74, 0x61, 0x72, 0x67, 0x65, 0x74, 0x12, 0x0e,
+	0x0a, 0x02, 0x74, 0x31, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x74, 0x31, 0x12, 0x0e,
+	0x0a, 0x02, 0x74, 0x32, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x74, 0x32, 0x12, 0x0e,
+	0x0a, 0x02, 0x62, 0x31, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x62, 0x31, 0x12, 0x0e,
+	0x0a, 0x02, 0x62, 0x32, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x02, 0x62, 0x32, 0x12, 0x17,
+	0x0a, 0x07, 0x62, 0x31, 0x5f, 0x68, 0x69, 0x74, 0x73, 0x18, 0x07, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x06, 0x62, 0x31, 0x48, 0x69, 0x74, 0x73, 0x12, 0x17, 0x0a, 0x07, 0x62, 0x32, 0x5f, 0x68, 0x69,
+	0x74, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x04, 0x52, 0x06, 0x62, 0x32, 0x48, 0x69, 0x74, 0x73,
+	0x22, 0x16, 0x0a, 0x14, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x74, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x43,
+	0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x3e, 0x0a, 0x09, 0x6b, 0x65, 0x79, 0x5f, 0x70, 0x6f, 0x6f, 0x6c, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x21, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x4b, 0x65, 0x79, 0x50, 0x6f, 0x6f, 0x6c, 0x43, 0x61, 0x63,
+	0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x08, 0x6b, 0x65, 0x79, 0x50, 0x6f, 0x6f, 0x6c,
+	0x73, 0x12, 0x1b, 0x0a, 0x09, 0x6b, 0x64, 0x66, 0x5f, 0x63, 0x61, 0x6c, 0x6c, 0x73, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x6b, 0x64, 0x66, 0x43, 0x61, 0x6c, 0x6c, 0x73, 0x22, 0x35,
+	0x0a, 0x18, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52,
+	0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x6b, 0x65,
+	0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6b, 0x65,
+	0x79, 0x52, 0x69, 0x6e, 0x67, 0x22, 0x67, 0x0a, 0x19, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64,
+	0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x05, 0x52, 0x07, 0x72, 0x65, 0x6d, 0x6f, 0x76, 0x65, 0x64, 0x12, 0x30, 0x0a, 0x14,
+	0x6b, 0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70,
+	0x72, 0x69, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x6b, 0x65, 0x79, 0x52,
+	0x69, 0x6e, 0x67, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x22, 0x19,
+	0x0a, 0x17, 0x47, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c,
+	0x74, 0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x4a, 0x0a, 0x18, 0x47, 0x65, 0x74,
+	0x4b, 0x65, 0x79, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73,
+	0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x07, 0x68, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x1e, 0x0a, 0x1c, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x6f, 0x74,
+	0x4b, 0x65, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0xc1, 0x01, 0x0a, 0x1d, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x6f,
+	0x74, 0x4b, 0x65, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x52,
+	0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x60, 0x0a, 0x0b, 0x67, 0x65, 0x6e, 0x65, 0x72,
+	0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x3e, 0x2e, 0x61,
+	0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x52, 0x6f, 0x6f, 0x74, 0x4b, 0x65, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x2e, 0x47, 0x65, 0x6e, 0x65,
+	0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0b, 0x67, 0x65,
+	0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x1a, 0x3e, 0x0a, 0x10, 0x47, 0x65, 0x6e,
+	0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
+	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
+	0x14, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x05,
+	0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x22, 0x1b, 0x0a, 0x19, 0x47, 0x65, 0x74,
+	0x41, 0x75, 0x64, 0x69, 0x74, 0x53, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x22, 0x70, 0x0a, 0x1a, 0x47, 0x65, 0x74, 0x41, 0x75, 0x64,
+	0x69, 0x74, 0x53, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x75, 0x72,
+	0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52, 0x0a, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x75, 0x72, 0x65, 0x64, 0x12, 0x1c, 0x0a, 0x09, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62, 0x6c,
+	0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x61, 0x76, 0x61, 0x69, 0x6c, 0x61, 0x62,
+	0x6c, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x05, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x18, 0x0a, 0x16, 0x47, 0x65, 0x74, 0x44,
+	0x75, 0x61, 0x6c, 0x52, 0x75, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x22, 0xac, 0x01, 0x0a, 0x17, 0x47, 0x65, 0x74, 0x44, 0x75, 0x61, 0x6c, 0x52, 0x75,
+	0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x18,
+	0x0a, 0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x08, 0x52,
+	0x07, 0x65, 0x6e, 0x61, 0x62, 0x6c, 0x65, 0x64, 0x12, 0x18, 0x0a, 0x07, 0x76, 0x65, 0x72, 0x73,
+	0x69, 0x6f, 0x6e, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x76, 0x65, 0x72, 0x73, 0x69,
+	0x6f, 0x6e, 0x12, 0x1f, 0x0a, 0x0b, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x5f, 0x72, 0x61, 0x74,
+	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x01, 0x52, 0x0a, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x52,
+	0x61, 0x74, 0x65, 0x12, 0x3c, 0x0a, 0x07, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x73, 0x18, 0x04,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x22, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x75, 0x61, 0x6c, 0x52, 0x75, 0x6e, 0x4d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x07, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x73, 0x22, 0xe0, 0x01, 0x0a, 0x12, 0x44, 0x75, 0x61, 0x6c, 0x52, 0x75, 0x6e, 0x4d, 0x65, 0x74,
+	0x68, 0x6f, 0x64, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68,
+	0x6f, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64,
+	0x12, 0x18, 0x0a, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x04, 0x52, 0x07, 0x73, 0x61, 0x6d, 0x70, 0x6c, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x0b, 0x64, 0x69,
+	0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x0b, 0x64, 0x69, 0x76, 0x65, 0x72, 0x67, 0x65, 0x6e, 0x63, 0x65, 0x73, 0x12, 0x18, 0x0a, 0x07,
+	0x73, 0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x04, 0x52, 0x07, 0x73,
+	0x6b, 0x69, 0x70, 0x70, 0x65, 0x64, 0x12, 0x2e, 0x0a, 0x13, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72,
+	0x79, 0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x05, 0x20,
+	0x01, 0x28, 0x01, 0x52, 0x11, 0x70, 0x72, 0x69, 0x6d, 0x61, 0x72, 0x79, 0x44, 0x75, 0x72, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x4d, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x73, 0x68, 0x61, 0x64, 0x6f, 0x77,
+	0x5f, 0x64, 0x75, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6d, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x01, 0x52, 0x10, 0x73, 0x68, 0x61, 0x64, 0x6f, 0x77, 0x44, 0x75, 0x72, 0x61, 0x74, 0x69,
+	0x6f, 0x6e, 0x4d, 0x73, 0x22, 0x47, 0x0a, 0x18, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x41, 0x75,
+	0x64, 0x69, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x12, 0x14, 0x0a, 0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x04, 0x52,
+	0x05, 0x61, 0x66, 0x74, 0x65, 0x72, 0x12, 0x15, 0x0a, 0x06, 0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64,
+	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f, 0x67, 0x49, 0x64, 0x22, 0x8c, 0x02,
+	0x0a, 0x0a, 0x41, 0x75, 0x64, 0x69, 0x74, 0x45, 0x76, 0x65, 0x6e, 0x74, 0x12, 0x15, 0x0a, 0x06,
+	0x6c, 0x6f, 0x67, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x6c, 0x6f,
+	0x67, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x04, 0x52, 0x08, 0x73, 0x65, 0x71, 0x75, 0x65, 0x6e, 0x63, 0x65, 0x12,
+	0x12, 0x0a, 0x04, 0x74, 0x69, 0x6d, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x03, 0x52, 0x04, 0x74,
+	0x69, 0x6d, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x06, 0x6d, 0x65, 0x74, 0x68, 0x6f, 0x64, 0x12, 0x30, 0x0a, 0x14, 0x6b,
+	0x65, 0x79, 0x5f, 0x72, 0x69, 0x6e, 0x67, 0x5f, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72,
+	0x69, 0x6e, 0x74, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52, 0x12, 0x6b, 0x65, 0x79, 0x52, 0x69,
+	0x6e, 0x67, 0x46, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x63, 0x61, 0x6c, 0x6c, 0x65, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63,
+	0x61, 0x6c, 0x6c, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63,
+	0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x16, 0x0a, 0x06,
+	0x72, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65,
+	0x73, 0x75, 0x6c, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x09,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x22, 0xe9, 0x01, 0x0a,
+	0x05, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x12, 0x0a, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x63, 0x6f, 0x64, 0x65, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65,
+	0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73,
+	0x73, 0x61, 0x67, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x61, 0x73, 0x6f, 0x6e, 0x12, 0x1a, 0x0a, 0x08,
+	0x61, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08,
+	0x61, 0x72, 0x67, 0x75, 0x6d, 0x65, 0x6e, 0x74, 0x12, 0x39, 0x0a, 0x08, 0x63, 0x61, 0x74, 0x65,
+	0x67, 0x6f, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x1d, 0x2e, 0x61, 0x7a, 0x6f,
+	0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x52, 0x08, 0x63, 0x61, 0x74, 0x65, 0x67,
+	0x6f, 0x72, 0x79, 0x12, 0x1c, 0x0a, 0x09, 0x72, 0x65, 0x74, 0x72, 0x79, 0x61, 0x62, 0x6c, 0x65,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x08, 0x52, 0x09, 0x72, 0x65, 0x74, 0x72, 0x79, 0x61, 0x62, 0x6c,
+	0x65, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x5f, 0x69, 0x64, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x72, 0x72, 0x65,
+	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x2a, 0x99, 0x02, 0x0a, 0x0d, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x12, 0x1e, 0x0a, 0x1a, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x5f, 0x43, 0x41, 0x54, 0x45, 0x47, 0x4f, 0x52, 0x59, 0x5f, 0x55, 0x4e, 0x53,
+	0x50, 0x45, 0x43, 0x49, 0x46, 0x49, 0x45, 0x44, 0x10, 0x00, 0x12, 0x22, 0x0a, 0x1e, 0x45, 0x52,
+	0x52, 0x4f, 0x52, 0x5f, 0x43, 0x41, 0x54, 0x45, 0x47, 0x4f, 0x52, 0x59, 0x5f, 0x49, 0x4e, 0x56,
+	0x41, 0x4c, 0x49, 0x44, 0x5f, 0x52, 0x45, 0x51, 0x55, 0x45, 0x53, 0x54, 0x10, 0x01, 0x12, 0x1f,
+	0x0a, 0x1b, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x43, 0x41, 0x54, 0x45, 0x47, 0x4f, 0x52, 0x59,
+	0x5f, 0x49, 0x4e, 0x56, 0x41, 0x4c, 0x49, 0x44, 0x5f, 0x44, 0x41, 0x54, 0x41, 0x10, 0x02, 0x12,
+	0x20, 0x0a, 0x1c, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x43, 0x41, 0x54, 0x45, 0x47, 0x4f, 0x52,
+	0x59, 0x5f, 0x41, 0x43, 0x43, 0x45, 0x53, 0x53, 0x5f, 0x44, 0x45, 0x4e, 0x49, 0x45, 0x44, 0x10,
+	0x03, 0x12, 0x1e, 0x0a, 0x1a, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x43, 0x41, 0x54, 0x45, 0x47,
+	0x4f, 0x52, 0x59, 0x5f, 0x55, 0x4e, 0x41, 0x56, 0x41, 0x49, 0x4c, 0x41, 0x42, 0x4c, 0x45, 0x10,
+	0x04, 0x12, 0x24, 0x0a, 0x20, 0x45, 0x52, 0x52, 0x4f, 0x52, 0x5f, 0x43, 0x41, 0x54, 0x45, 0x47,
+	0x4f, 0x52, 0x59, 0x5f, 0x44, 0x45, 0x41, 0x44, 0x4c, 0x49, 0x4e, 0x45, 0x5f, 0x45, 0x58, 0x43,
+	0x45, 0x45, 0x44, 0x45, 0x44, 0x10, 0x05, 0x12, 0x1e, 0x0a, 0x1a, 0x45, 0x52, 0x52, 0x4f, 0x52,
+	0x5f, 0x43, 0x41, 0x54, 0x45, 0x47, 0x4f, 0x52, 0x59, 0x5f, 0x55, 0x4e, 0x53, 0x55, 0x50, 0x50,
+	0x4f, 0x52, 0x54, 0x45, 0x44, 0x10, 0x06, 0x12, 0x1b, 0x0a, 0x17, 0x45, 0x52, 0x52, 0x4f, 0x52,
+	0x5f, 0x43, 0x41, 0x54, 0x45, 0x47, 0x4f, 0x52, 0x59, 0x5f, 0x49, 0x4e, 0x54, 0x45, 0x52, 0x4e,
+	0x41, 0x4c, 0x10, 0x07, 0x32, 0xfa, 0x0f, 0x0a, 0x09, 0x44, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x41,
+	0x50, 0x49, 0x12, 0x52, 0x0a, 0x09, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x12,
+	0x20, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x21, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x43, 0x72, 0x65, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x07, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70,
+	0x74, 0x12, 0x1e, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x1f, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x4c, 0x0a, 0x07, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x12,
+	0x1e, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1f, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x12, 0x64, 0x0a, 0x0f, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x44, 0x61,
+	0x74, 0x61, 0x4b, 0x65, 0x79, 0x12, 0x26, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61,
+	0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x44,
+	0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e,
+	0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x44, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x61, 0x0a, 0x0e, 0x44, 0x65, 0x63, 0x72,
+	0x79, 0x70, 0x74, 0x44, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x12, 0x25, 0x2e, 0x61, 0x7a, 0x6f,
+	0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x72,
+	0x79, 0x70, 0x74, 0x44, 0x61, 0x74, 0x61, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x26, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x44, 0x65, 0x63, 0x72, 0x79, 0x70, 0x74, 0x44, 0x61, 0x74, 0x61, 0x4b, 0x65,
+	0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x52, 0x0a, 0x09, 0x44,
+	0x65, 0x72, 0x69, 0x76, 0x65, 0x4b, 0x65, 0x79, 0x12, 0x20, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e,
+	0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x72, 0x69, 0x76, 0x65,
+	0x4b, 0x65, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x21, 0x2e, 0x61, 0x7a, 0x6f,
+	0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x72, 0x69,
+	0x76, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x40, 0x0a, 0x03, 0x4d, 0x41, 0x43, 0x12, 0x1a, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72,
+	0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x41, 0x43, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x1b, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x4d, 0x41, 0x43, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x43, 0x0a, 0x04, 0x53, 0x69, 0x67, 0x6e, 0x12, 0x1b, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
+	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x1c, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72,
+	0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x53, 0x69, 0x67, 0x6e, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x49, 0x0a, 0x06, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79,
+	0x12, 0x1d, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a,
+	0x1e, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x12, 0x4f, 0x0a, 0x08, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x50, 0x4b, 0x12, 0x1f, 0x2e,
+	0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x50, 0x4b, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x20,
+	0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x50, 0x4b, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65,
+	0x22, 0x00, 0x12, 0x5b, 0x0a, 0x0c, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f,
+	0x54, 0x50, 0x12, 0x23, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x24, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64,
+	0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x55, 0x0a, 0x0a, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x12, 0x21, 0x2e,
+	0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74,
+	0x1a, 0x22, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x64, 0x0a, 0x0f, 0x42, 0x61, 0x74, 0x63, 0x68, 0x56,
+	0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x12, 0x26, 0x2e, 0x61, 0x7a, 0x6f, 0x6f,
+	0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x27, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e,
+	0x76, 0x31, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x54, 0x4f,
+	0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x55, 0x0a, 0x0a,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x12, 0x21, 0x2e, 0x61, 0x7a, 0x6f,
+	0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44, 0x65, 0x6c, 0x65,
+	0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x22, 0x2e,
+	0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x54, 0x4f, 0x54, 0x50, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x22, 0x00, 0x12, 0x61, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f,
+	0x63, 0x6b, 0x6f, 0x75, 0x74, 0x12, 0x25, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61,
+	0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f,
+	0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x26, 0x2e, 0x61,
+	0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65,
+	0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70,
+	0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x67, 0x0a, 0x10, 0x52, 0x65, 0x73, 0x65, 0x74, 0x54,
+	0x4f, 0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x12, 0x27, 0x2e, 0x61, 0x7a, 0x6f,
+	0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x65,
+	0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f, 0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x71, 0x75,
+	0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f,
+	0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x52, 0x65, 0x73, 0x65, 0x74, 0x54, 0x4f, 0x54, 0x50, 0x4c, 0x6f,
+	0x63, 0x6b, 0x6f, 0x75, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x5e, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73,
+	0x12, 0x24, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76,
+	0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x25, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72,
+	0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x43, 0x61, 0x63, 0x68, 0x65,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12,
+	0x6a, 0x0a, 0x11, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79,
+	0x52, 0x69, 0x6e, 0x67, 0x12, 0x28, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67,
+	0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65,
+	0x4b, 0x65, 0x79, 0x52, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x29,
+	0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x49, 0x6e, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x65, 0x4b, 0x65, 0x79, 0x52, 0x69, 0x6e,
+	0x67, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x67, 0x0a, 0x10, 0x47,
+	0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x12,
+	0x27, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31,
+	0x2e, 0x47, 0x65, 0x74, 0x4b, 0x65, 0x79, 0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74,
+	0x68, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x28, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e,
+	0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x4b, 0x65, 0x79,
+	0x50, 0x6f, 0x6f, 0x6c, 0x48, 0x65, 0x61, 0x6c, 0x74, 0x68, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x22, 0x00, 0x12, 0x76, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x52, 0x6f, 0x6f, 0x74, 0x4b,
+	0x65, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x73, 0x12, 0x2c, 0x2e,
+	0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x52, 0x6f, 0x6f, 0x74, 0x4b, 0x65, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74,
+	0x69, 0x6f, 0x6e, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2d, 0x2e, 0x61, 0x7a,
+	0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74,
+	0x52, 0x6f, 0x6f, 0x74, 0x4b, 0x65, 0x79, 0x47, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x6d, 0x0a, 0x12,
+	0x47, 0x65, 0x74, 0x41, 0x75, 0x64, 0x69, 0x74, 0x53, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74,
+	0x75, 0x73, 0x12, 0x29, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e,
+	0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x41, 0x75, 0x64, 0x69, 0x74, 0x53, 0x69, 0x6e, 0x6b,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x2a, 0x2e,
+	0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47,
+	0x65, 0x74, 0x41, 0x75, 0x64, 0x69, 0x74, 0x53, 0x69, 0x6e, 0x6b, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22, 0x00, 0x12, 0x64, 0x0a, 0x0f, 0x47,
+	0x65, 0x74, 0x44, 0x75, 0x61, 0x6c, 0x52, 0x75, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x26,
+	0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72, 0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e,
+	0x47, 0x65, 0x74, 0x44, 0x75, 0x61, 0x6c, 0x52, 0x75, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x27, 0x2e, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x72,
+	0x61, 0x67, 0x6f, 0x6e, 0x2e, 0x76, 0x31, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x75, 0x61, 0x6c, 0x52,
+	0x75, 0x6e, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x22,
+	0x00, 0x42, 0x21, 0x5a, 0x1f, 0x61, 0x7a, 0x6f, 0x6f, 0x2e, 0x64, 0x65, 0x76, 0x2f, 0x61, 0x70,
+	0x69, 0x2f, 0x67, 0x65, 0x6e, 0x65, 0x72, 0x61, 0x74, 0x65, 0x64, 0x2f, 0x64, 0x72, 0x61, 0x67,
+	0x6f, 0x6e, 0x76, 0x31, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_azoo_dragon_v1_dragon_api_proto_rawDescOnce sync.Once
+	file_azoo_dragon_v1_dragon_api_proto_rawDescData = file_azoo_dragon_v1_dragon_api_proto_rawDesc
+)
+
+func file_azoo_dragon_v1_dragon_api_proto_rawDescGZIP() []byte {
+	file_azoo_dragon_v1_dragon_api_proto_rawDescOnce.Do(func() {
+		file_azoo_dragon_v1_dragon_api_proto_rawDescData = protoimpl.X.CompressGZIP(file_azoo_dragon_v1_dragon_api_proto_rawDescData)
+	})
+	return file_azoo_dragon_v1_dragon_api_proto_rawDescData
+}
+
+var file_azoo_dragon_v1_dragon_api_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_azoo_dragon_v1_dragon_api_proto_msgTypes = make([]protoimpl.MessageInfo, 55)
+var file_azoo_dragon_v1_dragon_api_proto_goTypes = []interface{}{
+	(ErrorCategory)(0),                      // 0: azoo.dragon.v1.ErrorCategory
+	(CreateKeyRequest_Type)(0),              // 1: azoo.dragon.v1.CreateKeyRequest.Type
+	(*CreateKeyRequest)(nil),                // 2: azoo.dragon.v1.CreateKeyRequest
+	(*CreateKeyResponse)(nil),               // 3: azoo.dragon.v1.CreateKeyResponse
+	(*EncryptRequest)(nil),                  // 4: azoo.dragon.v1.EncryptRequest
+	(*EncryptResponse)(nil),                 // 5: azoo.dragon.v1.EncryptResponse
+	(*DecryptRequest)(nil),                  // 6: azoo.dragon.v1.DecryptRequest
+	(*DecryptResponse)(nil),                 // 7: azoo.dragon.v1.DecryptResponse
+	(*GenerateDataKeyRequest)(nil),          // 8: azoo.dragon.v1.GenerateDataKeyRequest
+	(*GenerateDataKeyResponse)(nil),         // 9: azoo.dragon.v1.GenerateDataKeyResponse
+	(*DecryptDataKeyRequest)(nil),           // 10: azoo.dragon.v1.DecryptDataKeyRequest
+	(*DecryptDataKeyResponse)(nil),          // 11: azoo.dragon.v1.DecryptDataKeyResponse
+	(*DeriveKeyRequest)(nil),                // 12: azoo.dragon.v1.DeriveKeyRequest
+	(*DeriveKeyResponse)(nil),               // 13: azoo.dragon.v1.DeriveKeyResponse
+	(*MACRequest)(nil),                      // 14: azoo.dragon.v1.MACRequest
+	(*MACResponse)(nil),                     // 15: azoo.dragon.v1.MACResponse
+	(*SignRequest)(nil),                     // 16: azoo.dragon.v1.SignRequest
+	(*SignResponse)(nil),                    // 17: azoo.dragon.v1.SignResponse
+	(*VerifyRequest)(nil),                   // 18: azoo.dragon.v1.VerifyRequest
+	(*VerifyResponse)(nil),                  // 19: azoo.dragon.v1.VerifyResponse
+	(*VerifyPKRequest)(nil),                 // 20: azoo.dragon.v1.VerifyPKRequest
+	(*VerifyPKResponse)(nil),                // 21: azoo.dragon.v1.VerifyPKResponse
+	(*GenerateTOTPRequest)(nil),             // 22: azoo.dragon.v1.GenerateTOTPRequest
+	(*GenerateTOTPResponse)(nil),            // 23: azoo.dragon.v1.GenerateTOTPResponse
+	(*VerifyTOTPRequest)(nil),               // 24: azoo.dragon.v1.VerifyTOTPRequest
+	(*VerifyTOTPResponse)(nil),              // 25: azoo.dragon.v1.VerifyTOTPResponse
+	(*BatchVerifyTOTPRequest)(nil),          // 26: azoo.dragon.v1.BatchVerifyTOTPRequest
+	(*BatchVerifyTOTPResponse)(nil),         // 27: azoo.dragon.v1.BatchVerifyTOTPResponse
+	(*DeleteTOTPRequest)(nil),               // 28: azoo.dragon.v1.DeleteTOTPRequest
+	(*DeleteTOTPResponse)(nil),              // 29: azoo.dragon.v1.DeleteTOTPResponse
+	(*TOTPLockout)(nil),                     // 30: azoo.dragon.v1.TOTPLockout
+	(*GetTOTPLockoutRequest)(nil),           // 31: azoo.dragon.v1.GetTOTPLockoutRequest
+	(*GetTOTPLockoutResponse)(nil),          // 32: azoo.dragon.v1.GetTOTPLockoutResponse
+	(*ResetTOTPLockoutRequest)(nil),         // 33: azoo.dragon.v1.ResetTOTPLockoutRequest
+	(*ResetTOTPLockoutResponse)(nil),        // 34: azoo.dragon.v1.ResetTOTPLockoutResponse
+	(*KeyPoolCacheStats)(nil),               // 35: azoo.dragon.v1.KeyPoolCacheStats
+	(*CacheShardStats)(nil),                 // 36: azoo.dragon.v1.CacheShardStats
+	(*GetCacheStatsRequest)(nil),            // 37: azoo.dragon.v1.GetCacheStatsRequest
+	(*GetCacheStatsResponse)(nil),           // 38: azoo.dragon.v1.GetCacheStatsResponse
+	(*InvalidateKeyRingRequest)(nil),        // 39: azoo.dragon.v1.InvalidateKeyRingRequest
+	(*InvalidateKeyRingResponse)(nil),       // 40: azoo.dragon.v1.InvalidateKeyRingResponse
+	(*GetKeyPoolHealthRequest)(nil),         // 41: azoo.dragon.v1.GetKeyPoolHealthRequest
+	(*GetKeyPoolHealthResponse)(nil),        // 42: azoo.dragon.v1.GetKeyPoolHealthResponse
+	(*GetRootKeyGenerationsRequest)(nil),    // 43: azoo.dragon.v1.GetRootKeyGenerationsRequest
+	(*GetRootKeyGenerationsResponse)(nil),   // 44: azoo.dragon.v1.GetRootKeyGenerationsResponse
+	(*GetAuditSinkStatusRequest)(nil),       // 45: azoo.dragon.v1.GetAuditSinkStatusRequest
+	(*GetAuditSinkStatusResponse)(nil),      // 46: azoo.dragon.v1.GetAuditSinkStatusResponse
+	(*GetDualRunStatsRequest)(nil),          // 47: azoo.dragon.v1.GetDualRunStatsRequest
+	(*GetDualRunStatsResponse)(nil),         // 48: azoo.dragon.v1.GetDualRunStatsResponse
+	(*DualRunMethodStats)(nil),              // 49: azoo.dragon.v1.DualRunMethodStats
+	(*ExportAuditEventsRequest)(nil),        // 50: azoo.dragon.v1.ExportAuditEventsRequest
+	(*AuditEvent)(nil),                      // 51: azoo.dragon.v1.AuditEvent
+	(*Error)(nil),                           // 52: azoo.dragon.v1.Error
+	(*CreateKeyResponse_EncryptionKey)(nil), // 53: azoo.dragon.v1.CreateKeyResponse.EncryptionKey
+	(*CreateKeyResponse_SigningKey)(nil),    // 54: azoo.dragon.v1.CreateKeyResponse.SigningKey
+	(*CreateKeyResponse_MACKey)(nil),        // 55: azoo.dragon.v1.CreateKeyResponse.MACKey
+	nil,                                     // 56: azoo.dragon.v1.GetRootKeyGenerationsResponse.GenerationsEntry
+}
+var file_azoo_dragon_v1_dragon_api_proto_depIdxs = []int32{
+	1,  // 0: azoo.dragon.v1.CreateKeyRequest.type:type_name -> azoo.dragon.v1.CreateKeyRequest.Type
+	53, // 1: azoo.dragon.v1.CreateKeyResponse.encryption_key:type_name -> azoo.dragon.v1.CreateKeyResponse.EncryptionKey
+	54, // 2: azoo.dragon.v1.CreateKeyResponse.signing_key:type_name -> azoo.dragon.v1.CreateKeyResponse.SigningKey
+	55, // 3: azoo.dragon.v1.CreateKeyResponse.mac_key:type_name -> azoo.dragon.v1.CreateKeyResponse.MACKey
+	30, // 4: azoo.dragon.v1.VerifyTOTPResponse.lockout:type_name -> azoo.dragon.v1.TOTPLockout
+	30, // 5: azoo.dragon.v1.BatchVerifyTOTPResponse.lockout:type_name -> azoo.dragon.v1.TOTPLockout
+	30, // 6: azoo.dragon.v1.GetTOTPLockoutResponse.lockout:type_name -> azoo.dragon.v1.TOTPLockout
+	36, // 7: azoo.dragon.v1.KeyPoolCacheStats.shards:type_name -> azoo.dragon.v1.CacheShardStats
+	35, // 8: azoo.dragon.v1.GetCacheStatsResponse.key_pools:type_name -> azoo.dragon.v1.KeyPoolCacheStats
+	56, // 9: azoo.dragon.v1.GetRootKeyGenerationsResponse.generations:type_name -> azoo.dragon.v1.GetRootKeyGenerationsResponse.GenerationsEntry
+	49, // 10: azoo.dragon.v1.GetDualRunStatsResponse.methods:type_name -> azoo.dragon.v1.DualRunMethodStats
+	0,  // 11: azoo.dragon.v1.Error.category:type_name -> azoo.dragon.v1.ErrorCategory
+	2,  // 12: azoo.dragon.v1.DragonAPI.CreateKey:input_type -> azoo.dragon.v1.CreateKeyRequest
+	4,  // 13: azoo.dragon.v1.DragonAPI.Encrypt:input_type -> azoo.dragon.v1.EncryptRequest
+	6,  // 14: azoo.dragon.v1.DragonAPI.Decrypt:input_type -> azoo.dragon.v1.DecryptRequest
+	8,  // 15: azoo.dragon.v1.DragonAPI.GenerateDataKey:input_type -> azoo.dragon.v1.GenerateDataKeyRequest
+	10, // 16: azoo.dragon.v1.DragonAPI.DecryptDataKey:input_type -> azoo.dragon.v1.DecryptDataKeyRequest
+	12, // 17: azoo.dragon.v1.DragonAPI.DeriveKey:input_type -> azoo.dragon.v1.DeriveKeyRequest
+	14, // 18: azoo.dragon.v1.DragonAPI.MAC:input_type -> azoo.dragon.v1.MACRequest
+	16, // 19: azoo.dragon.v1.DragonAPI.Sign:input_type -> azoo.dragon.v1.SignRequest
+	18, // 20: azoo.dragon.v1.DragonAPI.Verify:input_type -> azoo.dragon.v1.VerifyRequest
+	20, // 21: azoo.dragon.v1.DragonAPI.VerifyPK:input_type -> azoo.dragon.v1.VerifyPKRequest
+	22, // 22: azoo.dragon.v1.DragonAPI.GenerateTOTP:input_type -> azoo.dragon.v1.GenerateTOTPRequest
+	24, // 23: azoo.dragon.v1.DragonAPI.VerifyTOTP:input_type -> azoo.dragon.v1.VerifyTOTPRequest
+	26, // 24: azoo.dragon.v1.DragonAPI.BatchVerifyTOTP:input_type -> azoo.dragon.v1.BatchVerifyTOTPRequest
+	28, // 25: azoo.dragon.v1.DragonAPI.DeleteTOTP:input_type -> azoo.dragon.v1.DeleteTOTPRequest
+	31, // 26: azoo.dragon.v1.DragonAPI.GetTOTPLockout:input_type -> azoo.dragon.v1.GetTOTPLockoutRequest
+	33, // 27: azoo.dragon.v1.DragonAPI.ResetTOTPLockout:input_type -> azoo.dragon.v1.ResetTOTPLockoutRequest
+	37, // 28: azoo.dragon.v1.DragonAPI.GetCacheStats:input_type -> azoo.dragon.v1.GetCacheStatsRequest
+	39, // 29: azoo.dragon.v1.DragonAPI.InvalidateKeyRing:input_type -> azoo.dragon.v1.InvalidateKeyRingRequest
+	41, // 30: azoo.dragon.v1.DragonAPI.GetKeyPoolHealth:input_type -> azoo.dragon.v1.GetKeyPoolHealthRequest
+	43, // 31: azoo.dragon.v1.DragonAPI.GetRootKeyGenerations:input_type -> azoo.dragon.v1.GetRootKeyGenerationsRequest
+	45, // 32: azoo.dragon.v1.DragonAPI.GetAuditSinkStatus:input_type -> azoo.dragon.v1.GetAuditSinkStatusRequest
+	47, // 33: azoo.dragon.v1.DragonAPI.GetDualRunStats:input_type -> azoo.dragon.v1.GetDualRunStatsRequest
+	3,  // 34: azoo.dragon.v1.DragonAPI.CreateKey:output_type -> azoo.dragon.v1.CreateKeyResponse
+	5,  // 35: azoo.dragon.v1.DragonAPI.Encrypt:output_type -> azoo.dragon.v1.EncryptResponse
+	7,  // 36: azoo.dragon.v1.DragonAPI.Decrypt:output_type -> azoo.dragon.v1.DecryptResponse
+	9,  // 37: azoo.dragon.v1.DragonAPI.GenerateDataKey:output_type -> azoo.dragon.v1.GenerateDataKeyResponse
+	11, // 38: azoo.dragon.v1.DragonAPI.DecryptDataKey:output_type -> azoo.dragon.v1.DecryptDataKeyResponse
+	13, // 39: azoo.dragon.v1.DragonAPI.DeriveKey:output_type -> azoo.dragon.v1.DeriveKeyResponse
+	15, // 40: azoo.dragon.v1.DragonAPI.MAC:output_type -> azoo.dragon.v1.MACResponse
+	17, // 41: azoo.dragon.v1.DragonAPI.Sign:output_type -> azoo.dragon.v1.SignResponse
+	19, // 42: azoo.dragon.v1.DragonAPI.Verify:output_type -> azoo.dragon.v1.VerifyResponse
+	21, // 43: azoo.dragon.v1.DragonAPI.VerifyPK:output_type -> azoo.dragon.v1.VerifyPKResponse
+	23, // 44: azoo.dragon.v1.DragonAPI.GenerateTOTP:output_type -> azoo.dragon.v1.GenerateTOTPResponse
+	25, // 45: azoo.dragon.v1.DragonAPI.VerifyTOTP:output_type -> azoo.dragon.v1.VerifyTOTPResponse
+	27, // 46: azoo.dragon.v1.DragonAPI.BatchVerifyTOTP:output_type -> azoo.dragon.v1.BatchVerifyTOTPResponse
+	29, // 47: azoo.dragon.v1.DragonAPI.DeleteTOTP:output_type -> azoo.dragon.v1.DeleteTOTPResponse
+	32, // 48: azoo.dragon.v1.DragonAPI.GetTOTPLockout:output_type -> azoo.dragon.v1.GetTOTPLockoutResponse
+	34, // 49: azoo.dragon.v1.DragonAPI.ResetTOTPLockout:output_type -> azoo.dragon.v1.ResetTOTPLockoutResponse
+	38, // 50: azoo.dragon.v1.DragonAPI.GetCacheStats:output_type -> azoo.dragon.v1.GetCacheStatsResponse
+	40, // 51: azoo.dragon.v1.DragonAPI.InvalidateKeyRing:output_type -> azoo.dragon.v1.InvalidateKeyRingResponse
+	42, // 52: azoo.dragon.v1.DragonAPI.GetKeyPoolHealth:output_type -> azoo.dragon.v1.GetKeyPoolHealthResponse
+	44, // 53: azoo.dragon.v1.DragonAPI.GetRootKeyGenerations:output_type -> azoo.dragon.v1.GetRootKeyGenerationsResponse
+	46, // 54: azoo.dragon.v1.DragonAPI.GetAuditSinkStatus:output_type -> azoo.dragon.v1.GetAuditSinkStatusResponse
+	48, // 55: azoo.dragon.v1.DragonAPI.GetDualRunStats:output_type -> azoo.dragon.v1.GetDualRunStatsResponse
+	34, // [34:56] is the sub-list for method output_type
+	12, // [12:34] is the sub-list for method input_type
+	12, // [12:12] is the sub-list for extension type_name
+	12, // [12:12] is the sub-list for extension extendee
+	0,  // [0:12] is the sub-list for field type_name
+}
+
+func init() { file_azoo_dragon_v1_dragon_api_proto_init() }
+func file_azoo_dragon_v1_dragon_api_proto_init() {
+	if File_azoo_dragon_v1_dragon_api_proto != nil {
+		return
+	}
+	if !protoimpl.UnsafeEnabled {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateKeyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CreateKeyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EncryptRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EncryptResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecryptRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecryptResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateDataKeyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateDataKeyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecryptDataKeyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DecryptDataKeyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeriveKeyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeriveKeyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MACRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MACResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*SignResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyPKRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyPKResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateTOTPRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1583,8 +4180,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*CreateKeyResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GenerateTOTPResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1595,8 +4192,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EncryptRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyTOTPRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1607,8 +4204,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*EncryptResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[23].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*VerifyTOTPResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1619,8 +4216,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DecryptRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[24].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchVerifyTOTPRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1631,8 +4228,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DecryptResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[25].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BatchVerifyTOTPResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1643,8 +4240,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MACRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[26].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteTOTPRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1655,8 +4252,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MACResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[27].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DeleteTOTPResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1667,8 +4264,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[28].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TOTPLockout); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1679,8 +4276,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*SignResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[29].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTOTPLockoutRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1691,8 +4288,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VerifyRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[30].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetTOTPLockoutResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1703,8 +4300,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[11].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VerifyResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[31].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetTOTPLockoutRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1715,8 +4312,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[12].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GenerateTOTPRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[32].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ResetTOTPLockoutResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1727,8 +4324,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*GenerateTOTPResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[33].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*KeyPoolCacheStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1739,8 +4336,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VerifyTOTPRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[34].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*CacheShardStats); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1751,8 +4348,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*VerifyTOTPResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[35].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCacheStatsRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1763,8 +4360,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[16].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BatchVerifyTOTPRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[36].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetCacheStatsResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1775,8 +4372,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*BatchVerifyTOTPResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[37].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InvalidateKeyRingRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1787,8 +4384,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteTOTPRequest); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[38].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*InvalidateKeyRingResponse); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1799,8 +4396,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*DeleteTOTPResponse); i {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[39].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetKeyPoolHealthRequest); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1811,7 +4408,139 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[20].Exporter = func(v interface{}, i int) interface{} {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[40].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetKeyPoolHealthResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[41].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRootKeyGenerationsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[42].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetRootKeyGenerationsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[43].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAuditSinkStatusRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[44].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetAuditSinkStatusResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[45].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDualRunStatsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[46].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*GetDualRunStatsResponse); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[47].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*DualRunMethodStats); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[48].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ExportAuditEventsRequest); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[49].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*AuditEvent); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[50].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Error); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[51].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateKeyResponse_EncryptionKey); i {
 			case 0:
 				return &v.state
@@ -1823,7 +4552,7 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[21].Exporter = func(v interface{}, i int) interface{} {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[52].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateKeyResponse_SigningKey); i {
 			case 0:
 				return &v.state
@@ -1835,7 +4564,7 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 				return nil
 			}
 		}
-		file_azoo_dragon_v1_dragon_api_proto_msgTypes[22].Exporter = func(v interface{}, i int) interface{} {
+		file_azoo_dragon_v1_dragon_api_proto_msgTypes[53].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*CreateKeyResponse_MACKey); i {
 			case 0:
 				return &v.state
@@ -1853,8 +4582,8 @@ func file_azoo_dragon_v1_dragon_api_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_azoo_dragon_v1_dragon_api_proto_rawDesc,
-			NumEnums:      1,
-			NumMessages:   23,
+			NumEnums:      2,
+			NumMessages:   55,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
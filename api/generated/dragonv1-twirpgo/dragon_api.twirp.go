@@ -1,4 +1,4 @@
-// Code generated by protoc-gen-twirp v8.1.0, DO NOT EDIT.
+// Code generated by protoc-gen-twirp v8.1.3, DO NOT EDIT.
 // source: azoo/dragon/v1/dragon_api.proto
 
 package dragonv1
@@ -6,7 +6,7 @@ package dragonv1
 import context "context"
 import fmt "fmt"
 import http "net/http"
-import ioutil "io/ioutil"
+import io "io"
 import json "encoding/json"
 import strconv "strconv"
 import strings "strings"
@@ -18,7 +18,6 @@ import ctxsetters "github.com/twitchtv/twirp/ctxsetters"
 
 import bytes "bytes"
 import errors "errors"
-import io "io"
 import path "path"
 import url "net/url"
 
@@ -46,6 +45,23 @@ type DragonAPI interface {
 	// Decrypt decrypts the passed ciphertext and returns the raw data.
 	Decrypt(context.Context, *DecryptRequest) (*DecryptResponse, error)
 
+	// GenerateDataKey generates a random 256-bit data encryption key and
+	// returns it in plaintext and wrapped (encrypted) with the key of key_ring.
+	// Callers encrypt their data locally with the plaintext key, store only
+	// the wrapped key next to the data and discard the plaintext key.
+	GenerateDataKey(context.Context, *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error)
+
+	// DecryptDataKey unwraps a wrapped key returned by GenerateDataKey and
+	// returns the plaintext data encryption key.
+	DecryptDataKey(context.Context, *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error)
+
+	// DeriveKey derives a key from the root KeyPool of the service and returns
+	// it, so applications without sidecar can derive and cache keys locally
+	// (see client.NewCachingKeyPool). As it exposes key material, it is only
+	// served if the service enables it, and only to callers whose policy
+	// permits key derivation.
+	DeriveKey(context.Context, *DeriveKeyRequest) (*DeriveKeyResponse, error)
+
 	// MAC computes a massage-authentication-code (tag) for the passed message
 	// and returns it.
 	MAC(context.Context, *MACRequest) (*MACResponse, error)
@@ -56,12 +72,19 @@ type DragonAPI interface {
 	// Verify verifies that the passed signature is valid for the passed message.
 	Verify(context.Context, *VerifyRequest) (*VerifyResponse, error)
 
+	// VerifyPK verifies that the passed signature is valid for the passed
+	// message using the passed public key directly. No key is derived from the
+	// service's KeyPool.
+	VerifyPK(context.Context, *VerifyPKRequest) (*VerifyPKResponse, error)
+
 	// GenerateTOTP generates a TOTP selector ID and it's corresponding URI and
 	// QR-Code for user-setup.
 	GenerateTOTP(context.Context, *GenerateTOTPRequest) (*GenerateTOTPResponse, error)
 
 	// VerifyTOTP verifies if a code is valid for a specific TOTP selector ID and
-	// a associated account_id.
+	// a associated account_id. If the service enforces a TOTP lockout, accounts
+	// are locked after too many failed verifications and the response contains
+	// the lockout state of the account.
 	VerifyTOTP(context.Context, *VerifyTOTPRequest) (*VerifyTOTPResponse, error)
 
 	// BatchVerifyTOTP is the same as VerifyTOTP but accepts multiple TOTP
@@ -72,6 +95,38 @@ type DragonAPI interface {
 	// DeleteTOTP should delete the underlying TOTP secret. This is irrelevant for
 	// a dvx.Protocol (as all keys are derived dynamically).
 	DeleteTOTP(context.Context, *DeleteTOTPRequest) (*DeleteTOTPResponse, error)
+
+	// GetTOTPLockout returns the TOTP lockout state of an account_id, without
+	// counting as verification attempt.
+	GetTOTPLockout(context.Context, *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error)
+
+	// ResetTOTPLockout removes all failed verifications of an account_id and
+	// thereby lifts its lockout, e.g. after the account owner was verified by
+	// support.
+	ResetTOTPLockout(context.Context, *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error)
+
+	// GetCacheStats returns the cache statistics of every KeyPool.
+	GetCacheStats(context.Context, *GetCacheStatsRequest) (*GetCacheStatsResponse, error)
+
+	// InvalidateKeyRing removes all cached keys of a keyRing, so they are
+	// derived again by the root KeyPool.
+	InvalidateKeyRing(context.Context, *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error)
+
+	// GetKeyPoolHealth runs the self-test of the Protocol, which checks that
+	// every KeyPool is reachable and derives deterministic keys.
+	GetKeyPoolHealth(context.Context, *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error)
+
+	// GetRootKeyGenerations returns the generation of the current root key of
+	// every KeyPool.
+	GetRootKeyGenerations(context.Context, *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error)
+
+	// GetAuditSinkStatus returns whether the sink receiving the audit logs is
+	// available.
+	GetAuditSinkStatus(context.Context, *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error)
+
+	// GetDualRunStats returns the results of the dual-run mode, which repeats
+	// a sample of operations with another dvx version and compares them.
+	GetDualRunStats(context.Context, *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error)
 }
 
 // =========================
@@ -80,7 +135,7 @@ type DragonAPI interface {
 
 type dragonAPIProtobufClient struct {
 	client      HTTPClient
-	urls        [10]string
+	urls        [22]string
 	interceptor twirp.Interceptor
 	opts        twirp.ClientOptions
 }
@@ -97,7 +152,7 @@ func NewDragonAPIProtobufClient(baseURL string, client HTTPClient, opts ...twirp
 		o(&clientOpts)
 	}
 
-	// Using ReadOpt allows backwards and forwads compatibility with new options in the future
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
 	literalURLs := false
 	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
 	var pathPrefix string
@@ -108,17 +163,29 @@ func NewDragonAPIProtobufClient(baseURL string, client HTTPClient, opts ...twirp
 	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
 	serviceURL := sanitizeBaseURL(baseURL)
 	serviceURL += baseServicePath(pathPrefix, "azoo.dragon.v1", "DragonAPI")
-	urls := [10]string{
+	urls := [22]string{
 		serviceURL + "CreateKey",
 		serviceURL + "Encrypt",
 		serviceURL + "Decrypt",
+		serviceURL + "GenerateDataKey",
+		serviceURL + "DecryptDataKey",
+		serviceURL + "DeriveKey",
 		serviceURL + "MAC",
 		serviceURL + "Sign",
 		serviceURL + "Verify",
+		serviceURL + "VerifyPK",
 		serviceURL + "GenerateTOTP",
 		serviceURL + "VerifyTOTP",
 		serviceURL + "BatchVerifyTOTP",
 		serviceURL + "DeleteTOTP",
+		serviceURL + "GetTOTPLockout",
+		serviceURL + "ResetTOTPLockout",
+		serviceURL + "GetCacheStats",
+		serviceURL + "InvalidateKeyRing",
+		serviceURL + "GetKeyPoolHealth",
+		serviceURL + "GetRootKeyGenerations",
+		serviceURL + "GetAuditSinkStatus",
+		serviceURL + "GetDualRunStats",
 	}
 
 	return &dragonAPIProtobufClient{
@@ -267,6 +334,144 @@ func (c *dragonAPIProtobufClient) callDecrypt(ctx context.Context, in *DecryptRe
 	return out, nil
 }
 
+func (c *dragonAPIProtobufClient) GenerateDataKey(ctx context.Context, in *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GenerateDataKey")
+	caller := c.callGenerateDataKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GenerateDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GenerateDataKeyRequest) when calling interceptor")
+					}
+					return c.callGenerateDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GenerateDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GenerateDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIProtobufClient) callGenerateDataKey(ctx context.Context, in *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
+	out := new(GenerateDataKeyResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIProtobufClient) DecryptDataKey(ctx context.Context, in *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "DecryptDataKey")
+	caller := c.callDecryptDataKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DecryptDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DecryptDataKeyRequest) when calling interceptor")
+					}
+					return c.callDecryptDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DecryptDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DecryptDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIProtobufClient) callDecryptDataKey(ctx context.Context, in *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+	out := new(DecryptDataKeyResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIProtobufClient) DeriveKey(ctx context.Context, in *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "DeriveKey")
+	caller := c.callDeriveKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeriveKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeriveKeyRequest) when calling interceptor")
+					}
+					return c.callDeriveKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeriveKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeriveKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIProtobufClient) callDeriveKey(ctx context.Context, in *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+	out := new(DeriveKeyResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
 func (c *dragonAPIProtobufClient) MAC(ctx context.Context, in *MACRequest) (*MACResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
@@ -298,7 +503,7 @@ func (c *dragonAPIProtobufClient) MAC(ctx context.Context, in *MACRequest) (*MAC
 
 func (c *dragonAPIProtobufClient) callMAC(ctx context.Context, in *MACRequest) (*MACResponse, error) {
 	out := new(MACResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -344,7 +549,7 @@ func (c *dragonAPIProtobufClient) Sign(ctx context.Context, in *SignRequest) (*S
 
 func (c *dragonAPIProtobufClient) callSign(ctx context.Context, in *SignRequest) (*SignResponse, error) {
 	out := new(SignResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -390,7 +595,53 @@ func (c *dragonAPIProtobufClient) Verify(ctx context.Context, in *VerifyRequest)
 
 func (c *dragonAPIProtobufClient) callVerify(ctx context.Context, in *VerifyRequest) (*VerifyResponse, error) {
 	out := new(VerifyResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIProtobufClient) VerifyPK(ctx context.Context, in *VerifyPKRequest) (*VerifyPKResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "VerifyPK")
+	caller := c.callVerifyPK
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *VerifyPKRequest) (*VerifyPKResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyPKRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyPKRequest) when calling interceptor")
+					}
+					return c.callVerifyPK(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyPKResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyPKResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIProtobufClient) callVerifyPK(ctx context.Context, in *VerifyPKRequest) (*VerifyPKResponse, error) {
+	out := new(VerifyPKResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -436,7 +687,7 @@ func (c *dragonAPIProtobufClient) GenerateTOTP(ctx context.Context, in *Generate
 
 func (c *dragonAPIProtobufClient) callGenerateTOTP(ctx context.Context, in *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
 	out := new(GenerateTOTPResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[10], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -482,7 +733,7 @@ func (c *dragonAPIProtobufClient) VerifyTOTP(ctx context.Context, in *VerifyTOTP
 
 func (c *dragonAPIProtobufClient) callVerifyTOTP(ctx context.Context, in *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
 	out := new(VerifyTOTPResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[11], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -528,7 +779,7 @@ func (c *dragonAPIProtobufClient) BatchVerifyTOTP(ctx context.Context, in *Batch
 
 func (c *dragonAPIProtobufClient) callBatchVerifyTOTP(ctx context.Context, in *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
 	out := new(BatchVerifyTOTPResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[12], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -574,7 +825,7 @@ func (c *dragonAPIProtobufClient) DeleteTOTP(ctx context.Context, in *DeleteTOTP
 
 func (c *dragonAPIProtobufClient) callDeleteTOTP(ctx context.Context, in *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
 	out := new(DeleteTOTPResponse)
-	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[13], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -589,81 +840,26 @@ func (c *dragonAPIProtobufClient) callDeleteTOTP(ctx context.Context, in *Delete
 	return out, nil
 }
 
-// =====================
-// DragonAPI JSON Client
-// =====================
-
-type dragonAPIJSONClient struct {
-	client      HTTPClient
-	urls        [10]string
-	interceptor twirp.Interceptor
-	opts        twirp.ClientOptions
-}
-
-// NewDragonAPIJSONClient creates a JSON client that implements the DragonAPI interface.
-// It communicates using JSON and can be configured with a custom HTTPClient.
-func NewDragonAPIJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) DragonAPI {
-	if c, ok := client.(*http.Client); ok {
-		client = withoutRedirects(c)
-	}
-
-	clientOpts := twirp.ClientOptions{}
-	for _, o := range opts {
-		o(&clientOpts)
-	}
-
-	// Using ReadOpt allows backwards and forwads compatibility with new options in the future
-	literalURLs := false
-	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
-	var pathPrefix string
-	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
-		pathPrefix = "/twirp" // default prefix
-	}
-
-	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
-	serviceURL := sanitizeBaseURL(baseURL)
-	serviceURL += baseServicePath(pathPrefix, "azoo.dragon.v1", "DragonAPI")
-	urls := [10]string{
-		serviceURL + "CreateKey",
-		serviceURL + "Encrypt",
-		serviceURL + "Decrypt",
-		serviceURL + "MAC",
-		serviceURL + "Sign",
-		serviceURL + "Verify",
-		serviceURL + "GenerateTOTP",
-		serviceURL + "VerifyTOTP",
-		serviceURL + "BatchVerifyTOTP",
-		serviceURL + "DeleteTOTP",
-	}
-
-	return &dragonAPIJSONClient{
-		client:      client,
-		urls:        urls,
-		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
-		opts:        clientOpts,
-	}
-}
-
-func (c *dragonAPIJSONClient) CreateKey(ctx context.Context, in *CreateKeyRequest) (*CreateKeyResponse, error) {
+func (c *dragonAPIProtobufClient) GetTOTPLockout(ctx context.Context, in *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "CreateKey")
-	caller := c.callCreateKey
+	ctx = ctxsetters.WithMethodName(ctx, "GetTOTPLockout")
+	caller := c.callGetTOTPLockout
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error) {
+		caller = func(ctx context.Context, req *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*CreateKeyRequest)
+					typedReq, ok := req.(*GetTOTPLockoutRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*CreateKeyRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetTOTPLockoutRequest) when calling interceptor")
 					}
-					return c.callCreateKey(ctx, typedReq)
+					return c.callGetTOTPLockout(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*CreateKeyResponse)
+				typedResp, ok := resp.(*GetTOTPLockoutResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*CreateKeyResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTOTPLockoutResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -673,9 +869,9 @@ func (c *dragonAPIJSONClient) CreateKey(ctx context.Context, in *CreateKeyReques
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callCreateKey(ctx context.Context, in *CreateKeyRequest) (*CreateKeyResponse, error) {
-	out := new(CreateKeyResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
+func (c *dragonAPIProtobufClient) callGetTOTPLockout(ctx context.Context, in *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
+	out := new(GetTOTPLockoutResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[14], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -690,26 +886,26 @@ func (c *dragonAPIJSONClient) callCreateKey(ctx context.Context, in *CreateKeyRe
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) Encrypt(ctx context.Context, in *EncryptRequest) (*EncryptResponse, error) {
+func (c *dragonAPIProtobufClient) ResetTOTPLockout(ctx context.Context, in *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "Encrypt")
-	caller := c.callEncrypt
+	ctx = ctxsetters.WithMethodName(ctx, "ResetTOTPLockout")
+	caller := c.callResetTOTPLockout
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *EncryptRequest) (*EncryptResponse, error) {
+		caller = func(ctx context.Context, req *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*EncryptRequest)
+					typedReq, ok := req.(*ResetTOTPLockoutRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*EncryptRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ResetTOTPLockoutRequest) when calling interceptor")
 					}
-					return c.callEncrypt(ctx, typedReq)
+					return c.callResetTOTPLockout(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*EncryptResponse)
+				typedResp, ok := resp.(*ResetTOTPLockoutResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*EncryptResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ResetTOTPLockoutResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -719,9 +915,9 @@ func (c *dragonAPIJSONClient) Encrypt(ctx context.Context, in *EncryptRequest) (
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callEncrypt(ctx context.Context, in *EncryptRequest) (*EncryptResponse, error) {
-	out := new(EncryptResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
+func (c *dragonAPIProtobufClient) callResetTOTPLockout(ctx context.Context, in *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
+	out := new(ResetTOTPLockoutResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[15], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -736,26 +932,26 @@ func (c *dragonAPIJSONClient) callEncrypt(ctx context.Context, in *EncryptReques
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) Decrypt(ctx context.Context, in *DecryptRequest) (*DecryptResponse, error) {
+func (c *dragonAPIProtobufClient) GetCacheStats(ctx context.Context, in *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "Decrypt")
-	caller := c.callDecrypt
+	ctx = ctxsetters.WithMethodName(ctx, "GetCacheStats")
+	caller := c.callGetCacheStats
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *DecryptRequest) (*DecryptResponse, error) {
+		caller = func(ctx context.Context, req *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DecryptRequest)
+					typedReq, ok := req.(*GetCacheStatsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DecryptRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetCacheStatsRequest) when calling interceptor")
 					}
-					return c.callDecrypt(ctx, typedReq)
+					return c.callGetCacheStats(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DecryptResponse)
+				typedResp, ok := resp.(*GetCacheStatsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DecryptResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetCacheStatsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -765,9 +961,9 @@ func (c *dragonAPIJSONClient) Decrypt(ctx context.Context, in *DecryptRequest) (
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callDecrypt(ctx context.Context, in *DecryptRequest) (*DecryptResponse, error) {
-	out := new(DecryptResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+func (c *dragonAPIProtobufClient) callGetCacheStats(ctx context.Context, in *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
+	out := new(GetCacheStatsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[16], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -782,26 +978,26 @@ func (c *dragonAPIJSONClient) callDecrypt(ctx context.Context, in *DecryptReques
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) MAC(ctx context.Context, in *MACRequest) (*MACResponse, error) {
+func (c *dragonAPIProtobufClient) InvalidateKeyRing(ctx context.Context, in *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "MAC")
-	caller := c.callMAC
+	ctx = ctxsetters.WithMethodName(ctx, "InvalidateKeyRing")
+	caller := c.callInvalidateKeyRing
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *MACRequest) (*MACResponse, error) {
+		caller = func(ctx context.Context, req *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*MACRequest)
+					typedReq, ok := req.(*InvalidateKeyRingRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*MACRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*InvalidateKeyRingRequest) when calling interceptor")
 					}
-					return c.callMAC(ctx, typedReq)
+					return c.callInvalidateKeyRing(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*MACResponse)
+				typedResp, ok := resp.(*InvalidateKeyRingResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*MACResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*InvalidateKeyRingResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -811,9 +1007,9 @@ func (c *dragonAPIJSONClient) MAC(ctx context.Context, in *MACRequest) (*MACResp
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callMAC(ctx context.Context, in *MACRequest) (*MACResponse, error) {
-	out := new(MACResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+func (c *dragonAPIProtobufClient) callInvalidateKeyRing(ctx context.Context, in *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
+	out := new(InvalidateKeyRingResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[17], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -828,26 +1024,26 @@ func (c *dragonAPIJSONClient) callMAC(ctx context.Context, in *MACRequest) (*MAC
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) Sign(ctx context.Context, in *SignRequest) (*SignResponse, error) {
+func (c *dragonAPIProtobufClient) GetKeyPoolHealth(ctx context.Context, in *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "Sign")
-	caller := c.callSign
+	ctx = ctxsetters.WithMethodName(ctx, "GetKeyPoolHealth")
+	caller := c.callGetKeyPoolHealth
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+		caller = func(ctx context.Context, req *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*SignRequest)
+					typedReq, ok := req.(*GetKeyPoolHealthRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*SignRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetKeyPoolHealthRequest) when calling interceptor")
 					}
-					return c.callSign(ctx, typedReq)
+					return c.callGetKeyPoolHealth(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*SignResponse)
+				typedResp, ok := resp.(*GetKeyPoolHealthResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*SignResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetKeyPoolHealthResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -857,9 +1053,9 @@ func (c *dragonAPIJSONClient) Sign(ctx context.Context, in *SignRequest) (*SignR
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callSign(ctx context.Context, in *SignRequest) (*SignResponse, error) {
-	out := new(SignResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+func (c *dragonAPIProtobufClient) callGetKeyPoolHealth(ctx context.Context, in *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
+	out := new(GetKeyPoolHealthResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[18], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -874,26 +1070,26 @@ func (c *dragonAPIJSONClient) callSign(ctx context.Context, in *SignRequest) (*S
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) Verify(ctx context.Context, in *VerifyRequest) (*VerifyResponse, error) {
+func (c *dragonAPIProtobufClient) GetRootKeyGenerations(ctx context.Context, in *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "Verify")
-	caller := c.callVerify
+	ctx = ctxsetters.WithMethodName(ctx, "GetRootKeyGenerations")
+	caller := c.callGetRootKeyGenerations
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+		caller = func(ctx context.Context, req *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*VerifyRequest)
+					typedReq, ok := req.(*GetRootKeyGenerationsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*VerifyRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetRootKeyGenerationsRequest) when calling interceptor")
 					}
-					return c.callVerify(ctx, typedReq)
+					return c.callGetRootKeyGenerations(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*VerifyResponse)
+				typedResp, ok := resp.(*GetRootKeyGenerationsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*VerifyResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetRootKeyGenerationsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -903,9 +1099,9 @@ func (c *dragonAPIJSONClient) Verify(ctx context.Context, in *VerifyRequest) (*V
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callVerify(ctx context.Context, in *VerifyRequest) (*VerifyResponse, error) {
-	out := new(VerifyResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+func (c *dragonAPIProtobufClient) callGetRootKeyGenerations(ctx context.Context, in *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
+	out := new(GetRootKeyGenerationsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[19], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -920,26 +1116,26 @@ func (c *dragonAPIJSONClient) callVerify(ctx context.Context, in *VerifyRequest)
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) GenerateTOTP(ctx context.Context, in *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+func (c *dragonAPIProtobufClient) GetAuditSinkStatus(ctx context.Context, in *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "GenerateTOTP")
-	caller := c.callGenerateTOTP
+	ctx = ctxsetters.WithMethodName(ctx, "GetAuditSinkStatus")
+	caller := c.callGetAuditSinkStatus
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+		caller = func(ctx context.Context, req *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*GenerateTOTPRequest)
+					typedReq, ok := req.(*GetAuditSinkStatusRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*GenerateTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetAuditSinkStatusRequest) when calling interceptor")
 					}
-					return c.callGenerateTOTP(ctx, typedReq)
+					return c.callGetAuditSinkStatus(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*GenerateTOTPResponse)
+				typedResp, ok := resp.(*GetAuditSinkStatusResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*GenerateTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetAuditSinkStatusResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -949,9 +1145,9 @@ func (c *dragonAPIJSONClient) GenerateTOTP(ctx context.Context, in *GenerateTOTP
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callGenerateTOTP(ctx context.Context, in *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
-	out := new(GenerateTOTPResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+func (c *dragonAPIProtobufClient) callGetAuditSinkStatus(ctx context.Context, in *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
+	out := new(GetAuditSinkStatusResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[20], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -966,26 +1162,26 @@ func (c *dragonAPIJSONClient) callGenerateTOTP(ctx context.Context, in *Generate
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+func (c *dragonAPIProtobufClient) GetDualRunStats(ctx context.Context, in *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "VerifyTOTP")
-	caller := c.callVerifyTOTP
+	ctx = ctxsetters.WithMethodName(ctx, "GetDualRunStats")
+	caller := c.callGetDualRunStats
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+		caller = func(ctx context.Context, req *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*VerifyTOTPRequest)
+					typedReq, ok := req.(*GetDualRunStatsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*VerifyTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetDualRunStatsRequest) when calling interceptor")
 					}
-					return c.callVerifyTOTP(ctx, typedReq)
+					return c.callGetDualRunStats(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*VerifyTOTPResponse)
+				typedResp, ok := resp.(*GetDualRunStatsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*VerifyTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetDualRunStatsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -995,9 +1191,9 @@ func (c *dragonAPIJSONClient) VerifyTOTP(ctx context.Context, in *VerifyTOTPRequ
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callVerifyTOTP(ctx context.Context, in *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
-	out := new(VerifyTOTPResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+func (c *dragonAPIProtobufClient) callGetDualRunStats(ctx context.Context, in *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
+	out := new(GetDualRunStatsResponse)
+	ctx, err := doProtobufRequest(ctx, c.client, c.opts.Hooks, c.urls[21], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -1012,26 +1208,93 @@ func (c *dragonAPIJSONClient) callVerifyTOTP(ctx context.Context, in *VerifyTOTP
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) BatchVerifyTOTP(ctx context.Context, in *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
+// =====================
+// DragonAPI JSON Client
+// =====================
+
+type dragonAPIJSONClient struct {
+	client      HTTPClient
+	urls        [22]string
+	interceptor twirp.Interceptor
+	opts        twirp.ClientOptions
+}
+
+// NewDragonAPIJSONClient creates a JSON client that implements the DragonAPI interface.
+// It communicates using JSON and can be configured with a custom HTTPClient.
+func NewDragonAPIJSONClient(baseURL string, client HTTPClient, opts ...twirp.ClientOption) DragonAPI {
+	if c, ok := client.(*http.Client); ok {
+		client = withoutRedirects(c)
+	}
+
+	clientOpts := twirp.ClientOptions{}
+	for _, o := range opts {
+		o(&clientOpts)
+	}
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	literalURLs := false
+	_ = clientOpts.ReadOpt("literalURLs", &literalURLs)
+	var pathPrefix string
+	if ok := clientOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	// Build method URLs: <baseURL>[<prefix>]/<package>.<Service>/<Method>
+	serviceURL := sanitizeBaseURL(baseURL)
+	serviceURL += baseServicePath(pathPrefix, "azoo.dragon.v1", "DragonAPI")
+	urls := [22]string{
+		serviceURL + "CreateKey",
+		serviceURL + "Encrypt",
+		serviceURL + "Decrypt",
+		serviceURL + "GenerateDataKey",
+		serviceURL + "DecryptDataKey",
+		serviceURL + "DeriveKey",
+		serviceURL + "MAC",
+		serviceURL + "Sign",
+		serviceURL + "Verify",
+		serviceURL + "VerifyPK",
+		serviceURL + "GenerateTOTP",
+		serviceURL + "VerifyTOTP",
+		serviceURL + "BatchVerifyTOTP",
+		serviceURL + "DeleteTOTP",
+		serviceURL + "GetTOTPLockout",
+		serviceURL + "ResetTOTPLockout",
+		serviceURL + "GetCacheStats",
+		serviceURL + "InvalidateKeyRing",
+		serviceURL + "GetKeyPoolHealth",
+		serviceURL + "GetRootKeyGenerations",
+		serviceURL + "GetAuditSinkStatus",
+		serviceURL + "GetDualRunStats",
+	}
+
+	return &dragonAPIJSONClient{
+		client:      client,
+		urls:        urls,
+		interceptor: twirp.ChainInterceptors(clientOpts.Interceptors...),
+		opts:        clientOpts,
+	}
+}
+
+func (c *dragonAPIJSONClient) CreateKey(ctx context.Context, in *CreateKeyRequest) (*CreateKeyResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "BatchVerifyTOTP")
-	caller := c.callBatchVerifyTOTP
+	ctx = ctxsetters.WithMethodName(ctx, "CreateKey")
+	caller := c.callCreateKey
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
+		caller = func(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*BatchVerifyTOTPRequest)
+					typedReq, ok := req.(*CreateKeyRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*BatchVerifyTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*CreateKeyRequest) when calling interceptor")
 					}
-					return c.callBatchVerifyTOTP(ctx, typedReq)
+					return c.callCreateKey(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*BatchVerifyTOTPResponse)
+				typedResp, ok := resp.(*CreateKeyResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*BatchVerifyTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateKeyResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1041,9 +1304,9 @@ func (c *dragonAPIJSONClient) BatchVerifyTOTP(ctx context.Context, in *BatchVeri
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callBatchVerifyTOTP(ctx context.Context, in *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
-	out := new(BatchVerifyTOTPResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+func (c *dragonAPIJSONClient) callCreateKey(ctx context.Context, in *CreateKeyRequest) (*CreateKeyResponse, error) {
+	out := new(CreateKeyResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[0], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -1058,26 +1321,26 @@ func (c *dragonAPIJSONClient) callBatchVerifyTOTP(ctx context.Context, in *Batch
 	return out, nil
 }
 
-func (c *dragonAPIJSONClient) DeleteTOTP(ctx context.Context, in *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
+func (c *dragonAPIJSONClient) Encrypt(ctx context.Context, in *EncryptRequest) (*EncryptResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithMethodName(ctx, "DeleteTOTP")
-	caller := c.callDeleteTOTP
+	ctx = ctxsetters.WithMethodName(ctx, "Encrypt")
+	caller := c.callEncrypt
 	if c.interceptor != nil {
-		caller = func(ctx context.Context, req *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
+		caller = func(ctx context.Context, req *EncryptRequest) (*EncryptResponse, error) {
 			resp, err := c.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DeleteTOTPRequest)
+					typedReq, ok := req.(*EncryptRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DeleteTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*EncryptRequest) when calling interceptor")
 					}
-					return c.callDeleteTOTP(ctx, typedReq)
+					return c.callEncrypt(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DeleteTOTPResponse)
+				typedResp, ok := resp.(*EncryptResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*EncryptResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1087,9 +1350,9 @@ func (c *dragonAPIJSONClient) DeleteTOTP(ctx context.Context, in *DeleteTOTPRequ
 	return caller(ctx, in)
 }
 
-func (c *dragonAPIJSONClient) callDeleteTOTP(ctx context.Context, in *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
-	out := new(DeleteTOTPResponse)
-	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+func (c *dragonAPIJSONClient) callEncrypt(ctx context.Context, in *EncryptRequest) (*EncryptResponse, error) {
+	out := new(EncryptResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[1], in, out)
 	if err != nil {
 		twerr, ok := err.(twirp.Error)
 		if !ok {
@@ -1104,141 +1367,3257 @@ func (c *dragonAPIJSONClient) callDeleteTOTP(ctx context.Context, in *DeleteTOTP
 	return out, nil
 }
 
-// ========================
-// DragonAPI Server Handler
-// ========================
-
-type dragonAPIServer struct {
-	DragonAPI
-	interceptor      twirp.Interceptor
-	hooks            *twirp.ServerHooks
-	pathPrefix       string // prefix for routing
-	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
-	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
-}
-
-// NewDragonAPIServer builds a TwirpServer that can be used as an http.Handler to handle
-// HTTP requests that are routed to the right method in the provided svc implementation.
-// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
-func NewDragonAPIServer(svc DragonAPI, opts ...interface{}) TwirpServer {
-	serverOpts := newServerOpts(opts)
-
-	// Using ReadOpt allows backwards and forwads compatibility with new options in the future
-	jsonSkipDefaults := false
-	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
-	jsonCamelCase := false
-	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
-	var pathPrefix string
-	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
-		pathPrefix = "/twirp" // default prefix
+func (c *dragonAPIJSONClient) Decrypt(ctx context.Context, in *DecryptRequest) (*DecryptResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "Decrypt")
+	caller := c.callDecrypt
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DecryptRequest) (*DecryptResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DecryptRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DecryptRequest) when calling interceptor")
+					}
+					return c.callDecrypt(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DecryptResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DecryptResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
 	}
+	return caller(ctx, in)
+}
 
-	return &dragonAPIServer{
-		DragonAPI:        svc,
-		hooks:            serverOpts.Hooks,
-		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
-		pathPrefix:       pathPrefix,
-		jsonSkipDefaults: jsonSkipDefaults,
-		jsonCamelCase:    jsonCamelCase,
+func (c *dragonAPIJSONClient) callDecrypt(ctx context.Context, in *DecryptRequest) (*DecryptResponse, error) {
+	out := new(DecryptResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[2], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
 	}
-}
 
-// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
-// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
-func (s *dragonAPIServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
-	writeError(ctx, resp, err, s.hooks)
-}
+	callClientResponseReceived(ctx, c.opts.Hooks)
 
-// handleRequestBodyError is used to handle error when the twirp server cannot read request
-func (s *dragonAPIServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
-	if context.Canceled == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
-		return
-	}
-	if context.DeadlineExceeded == ctx.Err() {
-		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
-		return
-	}
-	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
+	return out, nil
 }
 
-// DragonAPIPathPrefix is a convenience constant that may identify URL paths.
-// Should be used with caution, it only matches routes generated by Twirp Go clients,
-// with the default "/twirp" prefix and default CamelCase service and method names.
-// More info: https://twitchtv.github.io/twirp/docs/routing.html
-const DragonAPIPathPrefix = "/twirp/azoo.dragon.v1.DragonAPI/"
-
-func (s *dragonAPIServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
-	ctx := req.Context()
+func (c *dragonAPIJSONClient) GenerateDataKey(ctx context.Context, in *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
 	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
 	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
-	ctx = ctxsetters.WithResponseWriter(ctx, resp)
-
-	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GenerateDataKey")
+	caller := c.callGenerateDataKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GenerateDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GenerateDataKeyRequest) when calling interceptor")
+					}
+					return c.callGenerateDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GenerateDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GenerateDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGenerateDataKey(ctx context.Context, in *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
+	out := new(GenerateDataKeyResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[3], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) DecryptDataKey(ctx context.Context, in *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "DecryptDataKey")
+	caller := c.callDecryptDataKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DecryptDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DecryptDataKeyRequest) when calling interceptor")
+					}
+					return c.callDecryptDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DecryptDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DecryptDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callDecryptDataKey(ctx context.Context, in *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+	out := new(DecryptDataKeyResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[4], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) DeriveKey(ctx context.Context, in *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "DeriveKey")
+	caller := c.callDeriveKey
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeriveKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeriveKeyRequest) when calling interceptor")
+					}
+					return c.callDeriveKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeriveKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeriveKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callDeriveKey(ctx context.Context, in *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+	out := new(DeriveKeyResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[5], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) MAC(ctx context.Context, in *MACRequest) (*MACResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "MAC")
+	caller := c.callMAC
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *MACRequest) (*MACResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*MACRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*MACRequest) when calling interceptor")
+					}
+					return c.callMAC(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*MACResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*MACResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callMAC(ctx context.Context, in *MACRequest) (*MACResponse, error) {
+	out := new(MACResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[6], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) Sign(ctx context.Context, in *SignRequest) (*SignResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "Sign")
+	caller := c.callSign
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SignRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SignRequest) when calling interceptor")
+					}
+					return c.callSign(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SignResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SignResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callSign(ctx context.Context, in *SignRequest) (*SignResponse, error) {
+	out := new(SignResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[7], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) Verify(ctx context.Context, in *VerifyRequest) (*VerifyResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "Verify")
+	caller := c.callVerify
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyRequest) when calling interceptor")
+					}
+					return c.callVerify(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callVerify(ctx context.Context, in *VerifyRequest) (*VerifyResponse, error) {
+	out := new(VerifyResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[8], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) VerifyPK(ctx context.Context, in *VerifyPKRequest) (*VerifyPKResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "VerifyPK")
+	caller := c.callVerifyPK
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *VerifyPKRequest) (*VerifyPKResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyPKRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyPKRequest) when calling interceptor")
+					}
+					return c.callVerifyPK(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyPKResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyPKResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callVerifyPK(ctx context.Context, in *VerifyPKRequest) (*VerifyPKResponse, error) {
+	out := new(VerifyPKResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[9], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) GenerateTOTP(ctx context.Context, in *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GenerateTOTP")
+	caller := c.callGenerateTOTP
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GenerateTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GenerateTOTPRequest) when calling interceptor")
+					}
+					return c.callGenerateTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GenerateTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GenerateTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGenerateTOTP(ctx context.Context, in *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+	out := new(GenerateTOTPResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[10], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) VerifyTOTP(ctx context.Context, in *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "VerifyTOTP")
+	caller := c.callVerifyTOTP
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyTOTPRequest) when calling interceptor")
+					}
+					return c.callVerifyTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callVerifyTOTP(ctx context.Context, in *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+	out := new(VerifyTOTPResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[11], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) BatchVerifyTOTP(ctx context.Context, in *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchVerifyTOTP")
+	caller := c.callBatchVerifyTOTP
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*BatchVerifyTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*BatchVerifyTOTPRequest) when calling interceptor")
+					}
+					return c.callBatchVerifyTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*BatchVerifyTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchVerifyTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callBatchVerifyTOTP(ctx context.Context, in *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
+	out := new(BatchVerifyTOTPResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[12], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) DeleteTOTP(ctx context.Context, in *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTOTP")
+	caller := c.callDeleteTOTP
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeleteTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteTOTPRequest) when calling interceptor")
+					}
+					return c.callDeleteTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeleteTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callDeleteTOTP(ctx context.Context, in *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
+	out := new(DeleteTOTPResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[13], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) GetTOTPLockout(ctx context.Context, in *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GetTOTPLockout")
+	caller := c.callGetTOTPLockout
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetTOTPLockoutRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetTOTPLockoutRequest) when calling interceptor")
+					}
+					return c.callGetTOTPLockout(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetTOTPLockoutResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTOTPLockoutResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGetTOTPLockout(ctx context.Context, in *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
+	out := new(GetTOTPLockoutResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[14], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) ResetTOTPLockout(ctx context.Context, in *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "ResetTOTPLockout")
+	caller := c.callResetTOTPLockout
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*ResetTOTPLockoutRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*ResetTOTPLockoutRequest) when calling interceptor")
+					}
+					return c.callResetTOTPLockout(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*ResetTOTPLockoutResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*ResetTOTPLockoutResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callResetTOTPLockout(ctx context.Context, in *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
+	out := new(ResetTOTPLockoutResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[15], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) GetCacheStats(ctx context.Context, in *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GetCacheStats")
+	caller := c.callGetCacheStats
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetCacheStatsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetCacheStatsRequest) when calling interceptor")
+					}
+					return c.callGetCacheStats(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetCacheStatsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetCacheStatsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGetCacheStats(ctx context.Context, in *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
+	out := new(GetCacheStatsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[16], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) InvalidateKeyRing(ctx context.Context, in *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "InvalidateKeyRing")
+	caller := c.callInvalidateKeyRing
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*InvalidateKeyRingRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*InvalidateKeyRingRequest) when calling interceptor")
+					}
+					return c.callInvalidateKeyRing(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*InvalidateKeyRingResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*InvalidateKeyRingResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callInvalidateKeyRing(ctx context.Context, in *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
+	out := new(InvalidateKeyRingResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[17], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) GetKeyPoolHealth(ctx context.Context, in *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GetKeyPoolHealth")
+	caller := c.callGetKeyPoolHealth
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetKeyPoolHealthRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetKeyPoolHealthRequest) when calling interceptor")
+					}
+					return c.callGetKeyPoolHealth(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetKeyPoolHealthResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetKeyPoolHealthResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGetKeyPoolHealth(ctx context.Context, in *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
+	out := new(GetKeyPoolHealthResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[18], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) GetRootKeyGenerations(ctx context.Context, in *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GetRootKeyGenerations")
+	caller := c.callGetRootKeyGenerations
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetRootKeyGenerationsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetRootKeyGenerationsRequest) when calling interceptor")
+					}
+					return c.callGetRootKeyGenerations(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetRootKeyGenerationsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetRootKeyGenerationsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGetRootKeyGenerations(ctx context.Context, in *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
+	out := new(GetRootKeyGenerationsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[19], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) GetAuditSinkStatus(ctx context.Context, in *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GetAuditSinkStatus")
+	caller := c.callGetAuditSinkStatus
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetAuditSinkStatusRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetAuditSinkStatusRequest) when calling interceptor")
+					}
+					return c.callGetAuditSinkStatus(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetAuditSinkStatusResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetAuditSinkStatusResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGetAuditSinkStatus(ctx context.Context, in *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
+	out := new(GetAuditSinkStatusResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[20], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+func (c *dragonAPIJSONClient) GetDualRunStats(ctx context.Context, in *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithMethodName(ctx, "GetDualRunStats")
+	caller := c.callGetDualRunStats
+	if c.interceptor != nil {
+		caller = func(ctx context.Context, req *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
+			resp, err := c.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GetDualRunStatsRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GetDualRunStatsRequest) when calling interceptor")
+					}
+					return c.callGetDualRunStats(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GetDualRunStatsResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GetDualRunStatsResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+	return caller(ctx, in)
+}
+
+func (c *dragonAPIJSONClient) callGetDualRunStats(ctx context.Context, in *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
+	out := new(GetDualRunStatsResponse)
+	ctx, err := doJSONRequest(ctx, c.client, c.opts.Hooks, c.urls[21], in, out)
+	if err != nil {
+		twerr, ok := err.(twirp.Error)
+		if !ok {
+			twerr = twirp.InternalErrorWith(err)
+		}
+		callClientError(ctx, c.opts.Hooks, twerr)
+		return nil, err
+	}
+
+	callClientResponseReceived(ctx, c.opts.Hooks)
+
+	return out, nil
+}
+
+// ========================
+// DragonAPI Server Handler
+// ========================
+
+type dragonAPIServer struct {
+	DragonAPI
+	interceptor      twirp.Interceptor
+	hooks            *twirp.ServerHooks
+	pathPrefix       string // prefix for routing
+	jsonSkipDefaults bool   // do not include unpopulated fields (default values) in the response
+	jsonCamelCase    bool   // JSON fields are serialized as lowerCamelCase rather than keeping the original proto names
+}
+
+// NewDragonAPIServer builds a TwirpServer that can be used as an http.Handler to handle
+// HTTP requests that are routed to the right method in the provided svc implementation.
+// The opts are twirp.ServerOption modifiers, for example twirp.WithServerHooks(hooks).
+func NewDragonAPIServer(svc DragonAPI, opts ...interface{}) TwirpServer {
+	serverOpts := newServerOpts(opts)
+
+	// Using ReadOpt allows backwards and forwards compatibility with new options in the future
+	jsonSkipDefaults := false
+	_ = serverOpts.ReadOpt("jsonSkipDefaults", &jsonSkipDefaults)
+	jsonCamelCase := false
+	_ = serverOpts.ReadOpt("jsonCamelCase", &jsonCamelCase)
+	var pathPrefix string
+	if ok := serverOpts.ReadOpt("pathPrefix", &pathPrefix); !ok {
+		pathPrefix = "/twirp" // default prefix
+	}
+
+	return &dragonAPIServer{
+		DragonAPI:        svc,
+		hooks:            serverOpts.Hooks,
+		interceptor:      twirp.ChainInterceptors(serverOpts.Interceptors...),
+		pathPrefix:       pathPrefix,
+		jsonSkipDefaults: jsonSkipDefaults,
+		jsonCamelCase:    jsonCamelCase,
+	}
+}
+
+// writeError writes an HTTP response with a valid Twirp error format, and triggers hooks.
+// If err is not a twirp.Error, it will get wrapped with twirp.InternalErrorWith(err)
+func (s *dragonAPIServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	writeError(ctx, resp, err, s.hooks)
+}
+
+// handleRequestBodyError is used to handle error when the twirp server cannot read request
+func (s *dragonAPIServer) handleRequestBodyError(ctx context.Context, resp http.ResponseWriter, msg string, err error) {
+	if context.Canceled == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.Canceled, "failed to read request: context canceled"))
+		return
+	}
+	if context.DeadlineExceeded == ctx.Err() {
+		s.writeError(ctx, resp, twirp.NewError(twirp.DeadlineExceeded, "failed to read request: deadline exceeded"))
+		return
+	}
+	s.writeError(ctx, resp, twirp.WrapError(malformedRequestError(msg), err))
+}
+
+// DragonAPIPathPrefix is a convenience constant that may identify URL paths.
+// Should be used with caution, it only matches routes generated by Twirp Go clients,
+// with the default "/twirp" prefix and default CamelCase service and method names.
+// More info: https://twitchtv.github.io/twirp/docs/routing.html
+const DragonAPIPathPrefix = "/twirp/azoo.dragon.v1.DragonAPI/"
+
+func (s *dragonAPIServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	ctx = ctxsetters.WithPackageName(ctx, "azoo.dragon.v1")
+	ctx = ctxsetters.WithServiceName(ctx, "DragonAPI")
+	ctx = ctxsetters.WithResponseWriter(ctx, resp)
+
+	var err error
 	ctx, err = callRequestReceived(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	if req.Method != "POST" {
-		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	if req.Method != "POST" {
+		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
+	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
+	if pkgService != "azoo.dragon.v1.DragonAPI" {
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+	if prefix != s.pathPrefix {
+		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+
+	switch method {
+	case "CreateKey":
+		s.serveCreateKey(ctx, resp, req)
+		return
+	case "Encrypt":
+		s.serveEncrypt(ctx, resp, req)
+		return
+	case "Decrypt":
+		s.serveDecrypt(ctx, resp, req)
+		return
+	case "GenerateDataKey":
+		s.serveGenerateDataKey(ctx, resp, req)
+		return
+	case "DecryptDataKey":
+		s.serveDecryptDataKey(ctx, resp, req)
+		return
+	case "DeriveKey":
+		s.serveDeriveKey(ctx, resp, req)
+		return
+	case "MAC":
+		s.serveMAC(ctx, resp, req)
+		return
+	case "Sign":
+		s.serveSign(ctx, resp, req)
+		return
+	case "Verify":
+		s.serveVerify(ctx, resp, req)
+		return
+	case "VerifyPK":
+		s.serveVerifyPK(ctx, resp, req)
+		return
+	case "GenerateTOTP":
+		s.serveGenerateTOTP(ctx, resp, req)
+		return
+	case "VerifyTOTP":
+		s.serveVerifyTOTP(ctx, resp, req)
+		return
+	case "BatchVerifyTOTP":
+		s.serveBatchVerifyTOTP(ctx, resp, req)
+		return
+	case "DeleteTOTP":
+		s.serveDeleteTOTP(ctx, resp, req)
+		return
+	case "GetTOTPLockout":
+		s.serveGetTOTPLockout(ctx, resp, req)
+		return
+	case "ResetTOTPLockout":
+		s.serveResetTOTPLockout(ctx, resp, req)
+		return
+	case "GetCacheStats":
+		s.serveGetCacheStats(ctx, resp, req)
+		return
+	case "InvalidateKeyRing":
+		s.serveInvalidateKeyRing(ctx, resp, req)
+		return
+	case "GetKeyPoolHealth":
+		s.serveGetKeyPoolHealth(ctx, resp, req)
+		return
+	case "GetRootKeyGenerations":
+		s.serveGetRootKeyGenerations(ctx, resp, req)
+		return
+	case "GetAuditSinkStatus":
+		s.serveGetAuditSinkStatus(ctx, resp, req)
+		return
+	case "GetDualRunStats":
+		s.serveGetDualRunStats(ctx, resp, req)
+		return
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+		return
+	}
+}
+
+func (s *dragonAPIServer) serveCreateKey(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveCreateKeyJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveCreateKeyProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveCreateKeyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(CreateKeyRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.CreateKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.CreateKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreateKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateKeyResponse and nil error while calling CreateKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveCreateKeyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "CreateKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(CreateKeyRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.CreateKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*CreateKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*CreateKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.CreateKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*CreateKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*CreateKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *CreateKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateKeyResponse and nil error while calling CreateKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveEncrypt(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveEncryptJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveEncryptProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveEncryptJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Encrypt")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(EncryptRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.Encrypt
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *EncryptRequest) (*EncryptResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*EncryptRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*EncryptRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Encrypt(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*EncryptResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*EncryptResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *EncryptResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *EncryptResponse and nil error while calling Encrypt. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveEncryptProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Encrypt")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(EncryptRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.Encrypt
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *EncryptRequest) (*EncryptResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*EncryptRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*EncryptRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Encrypt(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*EncryptResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*EncryptResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *EncryptResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *EncryptResponse and nil error while calling Encrypt. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveDecrypt(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDecryptJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDecryptProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveDecryptJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Decrypt")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DecryptRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.Decrypt
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DecryptRequest) (*DecryptResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DecryptRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DecryptRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Decrypt(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DecryptResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DecryptResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DecryptResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DecryptResponse and nil error while calling Decrypt. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveDecryptProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Decrypt")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DecryptRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.Decrypt
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DecryptRequest) (*DecryptResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DecryptRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DecryptRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Decrypt(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DecryptResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DecryptResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DecryptResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DecryptResponse and nil error while calling Decrypt. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveGenerateDataKey(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveGenerateDataKeyJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGenerateDataKeyProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveGenerateDataKeyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GenerateDataKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(GenerateDataKeyRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.GenerateDataKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GenerateDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GenerateDataKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.GenerateDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GenerateDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GenerateDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GenerateDataKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GenerateDataKeyResponse and nil error while calling GenerateDataKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveGenerateDataKeyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GenerateDataKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(GenerateDataKeyRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.GenerateDataKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GenerateDataKeyRequest) (*GenerateDataKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GenerateDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GenerateDataKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.GenerateDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GenerateDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GenerateDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GenerateDataKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GenerateDataKeyResponse and nil error while calling GenerateDataKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveDecryptDataKey(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDecryptDataKeyJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDecryptDataKeyProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveDecryptDataKeyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DecryptDataKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DecryptDataKeyRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.DecryptDataKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DecryptDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DecryptDataKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.DecryptDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DecryptDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DecryptDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DecryptDataKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DecryptDataKeyResponse and nil error while calling DecryptDataKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveDecryptDataKeyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DecryptDataKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DecryptDataKeyRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.DecryptDataKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DecryptDataKeyRequest) (*DecryptDataKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DecryptDataKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DecryptDataKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.DecryptDataKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DecryptDataKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DecryptDataKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DecryptDataKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DecryptDataKeyResponse and nil error while calling DecryptDataKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveDeriveKey(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveDeriveKeyJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveDeriveKeyProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveDeriveKeyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeriveKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(DeriveKeyRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.DeriveKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeriveKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeriveKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.DeriveKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeriveKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeriveKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeriveKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeriveKeyResponse and nil error while calling DeriveKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveDeriveKeyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "DeriveKey")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(DeriveKeyRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.DeriveKey
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *DeriveKeyRequest) (*DeriveKeyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*DeriveKeyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*DeriveKeyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.DeriveKey(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*DeriveKeyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*DeriveKeyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *DeriveKeyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeriveKeyResponse and nil error while calling DeriveKey. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveMAC(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveMACJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveMACProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveMACJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "MAC")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(MACRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.MAC
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *MACRequest) (*MACResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*MACRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*MACRequest) when calling interceptor")
+					}
+					return s.DragonAPI.MAC(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*MACResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*MACResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *MACResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *MACResponse and nil error while calling MAC. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveMACProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "MAC")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(MACRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.MAC
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *MACRequest) (*MACResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*MACRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*MACRequest) when calling interceptor")
+					}
+					return s.DragonAPI.MAC(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*MACResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*MACResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *MACResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *MACResponse and nil error while calling MAC. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveSign(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveSignJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveSignProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveSignJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Sign")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(SignRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.Sign
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SignRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SignRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Sign(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SignResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SignResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SignResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SignResponse and nil error while calling Sign. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveSignProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Sign")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(SignRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.Sign
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*SignRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*SignRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Sign(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*SignResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*SignResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *SignResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *SignResponse and nil error while calling Sign. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveVerify(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveVerifyJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveVerifyProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveVerifyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Verify")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(VerifyRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.Verify
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Verify(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *VerifyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyResponse and nil error while calling Verify. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveVerifyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "Verify")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(VerifyRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.Verify
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyRequest) when calling interceptor")
+					}
+					return s.DragonAPI.Verify(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *VerifyResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyResponse and nil error while calling Verify. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveVerifyPK(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveVerifyPKJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveVerifyPKProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveVerifyPKJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "VerifyPK")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(VerifyPKRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.VerifyPK
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *VerifyPKRequest) (*VerifyPKResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyPKRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyPKRequest) when calling interceptor")
+					}
+					return s.DragonAPI.VerifyPK(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyPKResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyPKResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *VerifyPKResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyPKResponse and nil error while calling VerifyPK. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveVerifyPKProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "VerifyPK")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(VerifyPKRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.VerifyPK
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *VerifyPKRequest) (*VerifyPKResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyPKRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyPKRequest) when calling interceptor")
+					}
+					return s.DragonAPI.VerifyPK(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyPKResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyPKResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *VerifyPKResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyPKResponse and nil error while calling VerifyPK. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveGenerateTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveGenerateTOTPJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveGenerateTOTPProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveGenerateTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GenerateTOTP")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+	reqContent := new(GenerateTOTPRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
+		return
+	}
+
+	handler := s.DragonAPI.GenerateTOTP
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GenerateTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GenerateTOTPRequest) when calling interceptor")
+					}
+					return s.DragonAPI.GenerateTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GenerateTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GenerateTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GenerateTOTPResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GenerateTOTPResponse and nil error while calling GenerateTOTP. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveGenerateTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "GenerateTOTP")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
+		return
+	}
+	reqContent := new(GenerateTOTPRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
+		return
+	}
+
+	handler := s.DragonAPI.GenerateTOTP
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*GenerateTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*GenerateTOTPRequest) when calling interceptor")
+					}
+					return s.DragonAPI.GenerateTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*GenerateTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*GenerateTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *GenerateTOTPResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GenerateTOTPResponse and nil error while calling GenerateTOTP. nil responses are not supported"))
+		return
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
+		return
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveVerifyTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	header := req.Header.Get("Content-Type")
+	i := strings.Index(header, ";")
+	if i == -1 {
+		i = len(header)
+	}
+	switch strings.TrimSpace(strings.ToLower(header[:i])) {
+	case "application/json":
+		s.serveVerifyTOTPJSON(ctx, resp, req)
+	case "application/protobuf":
+		s.serveVerifyTOTPProtobuf(ctx, resp, req)
+	default:
+		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
+		twerr := badRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, twerr)
+	}
+}
+
+func (s *dragonAPIServer) serveVerifyTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "VerifyTOTP")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	d := json.NewDecoder(req.Body)
+	rawReqBody := json.RawMessage{}
+	if err := d.Decode(&rawReqBody); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-
-	// Verify path format: [<prefix>]/<package>.<Service>/<Method>
-	prefix, pkgService, method := parseTwirpPath(req.URL.Path)
-	if pkgService != "azoo.dragon.v1.DragonAPI" {
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	reqContent := new(VerifyTOTPRequest)
+	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
+	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
+		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	if prefix != s.pathPrefix {
-		msg := fmt.Sprintf("invalid path prefix %q, expected %q, on path %q", prefix, s.pathPrefix, req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
-		return
+
+	handler := s.DragonAPI.VerifyTOTP
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyTOTPRequest) when calling interceptor")
+					}
+					return s.DragonAPI.VerifyTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
 	}
 
-	switch method {
-	case "CreateKey":
-		s.serveCreateKey(ctx, resp, req)
-		return
-	case "Encrypt":
-		s.serveEncrypt(ctx, resp, req)
-		return
-	case "Decrypt":
-		s.serveDecrypt(ctx, resp, req)
+	// Call service method
+	var respContent *VerifyTOTPResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
 		return
-	case "MAC":
-		s.serveMAC(ctx, resp, req)
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyTOTPResponse and nil error while calling VerifyTOTP. nil responses are not supported"))
 		return
-	case "Sign":
-		s.serveSign(ctx, resp, req)
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	marshaler := &protojson.MarshalOptions{UseProtoNames: !s.jsonCamelCase, EmitUnpopulated: !s.jsonSkipDefaults}
+	respBytes, err := marshaler.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal json response"))
 		return
-	case "Verify":
-		s.serveVerify(ctx, resp, req)
+	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/json")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
+}
+
+func (s *dragonAPIServer) serveVerifyTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	var err error
+	ctx = ctxsetters.WithMethodName(ctx, "VerifyTOTP")
+	ctx, err = callRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
 		return
-	case "GenerateTOTP":
-		s.serveGenerateTOTP(ctx, resp, req)
+	}
+
+	buf, err := io.ReadAll(req.Body)
+	if err != nil {
+		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
-	case "VerifyTOTP":
-		s.serveVerifyTOTP(ctx, resp, req)
+	}
+	reqContent := new(VerifyTOTPRequest)
+	if err = proto.Unmarshal(buf, reqContent); err != nil {
+		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
-	case "BatchVerifyTOTP":
-		s.serveBatchVerifyTOTP(ctx, resp, req)
+	}
+
+	handler := s.DragonAPI.VerifyTOTP
+	if s.interceptor != nil {
+		handler = func(ctx context.Context, req *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+			resp, err := s.interceptor(
+				func(ctx context.Context, req interface{}) (interface{}, error) {
+					typedReq, ok := req.(*VerifyTOTPRequest)
+					if !ok {
+						return nil, twirp.InternalError("failed type assertion req.(*VerifyTOTPRequest) when calling interceptor")
+					}
+					return s.DragonAPI.VerifyTOTP(ctx, typedReq)
+				},
+			)(ctx, req)
+			if resp != nil {
+				typedResp, ok := resp.(*VerifyTOTPResponse)
+				if !ok {
+					return nil, twirp.InternalError("failed type assertion resp.(*VerifyTOTPResponse) when calling interceptor")
+				}
+				return typedResp, err
+			}
+			return nil, err
+		}
+	}
+
+	// Call service method
+	var respContent *VerifyTOTPResponse
+	func() {
+		defer ensurePanicResponses(ctx, resp, s.hooks)
+		respContent, err = handler(ctx, reqContent)
+	}()
+
+	if err != nil {
+		s.writeError(ctx, resp, err)
 		return
-	case "DeleteTOTP":
-		s.serveDeleteTOTP(ctx, resp, req)
+	}
+	if respContent == nil {
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyTOTPResponse and nil error while calling VerifyTOTP. nil responses are not supported"))
 		return
-	default:
-		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
-		s.writeError(ctx, resp, badRouteError(msg, req.Method, req.URL.Path))
+	}
+
+	ctx = callResponsePrepared(ctx, s.hooks)
+
+	respBytes, err := proto.Marshal(respContent)
+	if err != nil {
+		s.writeError(ctx, resp, wrapInternal(err, "failed to marshal proto response"))
 		return
 	}
+
+	ctx = ctxsetters.WithStatusCode(ctx, http.StatusOK)
+	resp.Header().Set("Content-Type", "application/protobuf")
+	resp.Header().Set("Content-Length", strconv.Itoa(len(respBytes)))
+	resp.WriteHeader(http.StatusOK)
+	if n, err := resp.Write(respBytes); err != nil {
+		msg := fmt.Sprintf("failed to write response, %d of %d bytes written: %s", n, len(respBytes), err.Error())
+		twerr := twirp.NewError(twirp.Unknown, msg)
+		ctx = callError(ctx, s.hooks, twerr)
+	}
+	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveCreateKey(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveBatchVerifyTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1246,9 +4625,9 @@ func (s *dragonAPIServer) serveCreateKey(ctx context.Context, resp http.Response
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveCreateKeyJSON(ctx, resp, req)
+		s.serveBatchVerifyTOTPJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveCreateKeyProtobuf(ctx, resp, req)
+		s.serveBatchVerifyTOTPProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1256,9 +4635,9 @@ func (s *dragonAPIServer) serveCreateKey(ctx context.Context, resp http.Response
 	}
 }
 
-func (s *dragonAPIServer) serveCreateKeyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveBatchVerifyTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "CreateKey")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchVerifyTOTP")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1271,29 +4650,29 @@ func (s *dragonAPIServer) serveCreateKeyJSON(ctx context.Context, resp http.Resp
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(CreateKeyRequest)
+	reqContent := new(BatchVerifyTOTPRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.CreateKey
+	handler := s.DragonAPI.BatchVerifyTOTP
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error) {
+		handler = func(ctx context.Context, req *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*CreateKeyRequest)
+					typedReq, ok := req.(*BatchVerifyTOTPRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*CreateKeyRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*BatchVerifyTOTPRequest) when calling interceptor")
 					}
-					return s.DragonAPI.CreateKey(ctx, typedReq)
+					return s.DragonAPI.BatchVerifyTOTP(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*CreateKeyResponse)
+				typedResp, ok := resp.(*BatchVerifyTOTPResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*CreateKeyResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchVerifyTOTPResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1302,7 +4681,7 @@ func (s *dragonAPIServer) serveCreateKeyJSON(ctx context.Context, resp http.Resp
 	}
 
 	// Call service method
-	var respContent *CreateKeyResponse
+	var respContent *BatchVerifyTOTPResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1313,7 +4692,7 @@ func (s *dragonAPIServer) serveCreateKeyJSON(ctx context.Context, resp http.Resp
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateKeyResponse and nil error while calling CreateKey. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchVerifyTOTPResponse and nil error while calling BatchVerifyTOTP. nil responses are not supported"))
 		return
 	}
 
@@ -1339,42 +4718,42 @@ func (s *dragonAPIServer) serveCreateKeyJSON(ctx context.Context, resp http.Resp
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveCreateKeyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveBatchVerifyTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "CreateKey")
+	ctx = ctxsetters.WithMethodName(ctx, "BatchVerifyTOTP")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(CreateKeyRequest)
+	reqContent := new(BatchVerifyTOTPRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.CreateKey
+	handler := s.DragonAPI.BatchVerifyTOTP
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *CreateKeyRequest) (*CreateKeyResponse, error) {
+		handler = func(ctx context.Context, req *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*CreateKeyRequest)
+					typedReq, ok := req.(*BatchVerifyTOTPRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*CreateKeyRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*BatchVerifyTOTPRequest) when calling interceptor")
 					}
-					return s.DragonAPI.CreateKey(ctx, typedReq)
+					return s.DragonAPI.BatchVerifyTOTP(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*CreateKeyResponse)
+				typedResp, ok := resp.(*BatchVerifyTOTPResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*CreateKeyResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*BatchVerifyTOTPResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1383,7 +4762,7 @@ func (s *dragonAPIServer) serveCreateKeyProtobuf(ctx context.Context, resp http.
 	}
 
 	// Call service method
-	var respContent *CreateKeyResponse
+	var respContent *BatchVerifyTOTPResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1394,7 +4773,7 @@ func (s *dragonAPIServer) serveCreateKeyProtobuf(ctx context.Context, resp http.
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *CreateKeyResponse and nil error while calling CreateKey. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchVerifyTOTPResponse and nil error while calling BatchVerifyTOTP. nil responses are not supported"))
 		return
 	}
 
@@ -1418,7 +4797,7 @@ func (s *dragonAPIServer) serveCreateKeyProtobuf(ctx context.Context, resp http.
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveEncrypt(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveDeleteTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1426,9 +4805,9 @@ func (s *dragonAPIServer) serveEncrypt(ctx context.Context, resp http.ResponseWr
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveEncryptJSON(ctx, resp, req)
+		s.serveDeleteTOTPJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveEncryptProtobuf(ctx, resp, req)
+		s.serveDeleteTOTPProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1436,9 +4815,9 @@ func (s *dragonAPIServer) serveEncrypt(ctx context.Context, resp http.ResponseWr
 	}
 }
 
-func (s *dragonAPIServer) serveEncryptJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveDeleteTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Encrypt")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTOTP")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1451,29 +4830,29 @@ func (s *dragonAPIServer) serveEncryptJSON(ctx context.Context, resp http.Respon
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(EncryptRequest)
+	reqContent := new(DeleteTOTPRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.Encrypt
+	handler := s.DragonAPI.DeleteTOTP
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *EncryptRequest) (*EncryptResponse, error) {
+		handler = func(ctx context.Context, req *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*EncryptRequest)
+					typedReq, ok := req.(*DeleteTOTPRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*EncryptRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteTOTPRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Encrypt(ctx, typedReq)
+					return s.DragonAPI.DeleteTOTP(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*EncryptResponse)
+				typedResp, ok := resp.(*DeleteTOTPResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*EncryptResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTOTPResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1482,7 +4861,7 @@ func (s *dragonAPIServer) serveEncryptJSON(ctx context.Context, resp http.Respon
 	}
 
 	// Call service method
-	var respContent *EncryptResponse
+	var respContent *DeleteTOTPResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1493,7 +4872,7 @@ func (s *dragonAPIServer) serveEncryptJSON(ctx context.Context, resp http.Respon
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *EncryptResponse and nil error while calling Encrypt. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTOTPResponse and nil error while calling DeleteTOTP. nil responses are not supported"))
 		return
 	}
 
@@ -1519,42 +4898,42 @@ func (s *dragonAPIServer) serveEncryptJSON(ctx context.Context, resp http.Respon
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveEncryptProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveDeleteTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Encrypt")
+	ctx = ctxsetters.WithMethodName(ctx, "DeleteTOTP")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(EncryptRequest)
+	reqContent := new(DeleteTOTPRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.Encrypt
+	handler := s.DragonAPI.DeleteTOTP
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *EncryptRequest) (*EncryptResponse, error) {
+		handler = func(ctx context.Context, req *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*EncryptRequest)
+					typedReq, ok := req.(*DeleteTOTPRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*EncryptRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*DeleteTOTPRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Encrypt(ctx, typedReq)
+					return s.DragonAPI.DeleteTOTP(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*EncryptResponse)
+				typedResp, ok := resp.(*DeleteTOTPResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*EncryptResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTOTPResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1563,7 +4942,7 @@ func (s *dragonAPIServer) serveEncryptProtobuf(ctx context.Context, resp http.Re
 	}
 
 	// Call service method
-	var respContent *EncryptResponse
+	var respContent *DeleteTOTPResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1574,7 +4953,7 @@ func (s *dragonAPIServer) serveEncryptProtobuf(ctx context.Context, resp http.Re
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *EncryptResponse and nil error while calling Encrypt. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTOTPResponse and nil error while calling DeleteTOTP. nil responses are not supported"))
 		return
 	}
 
@@ -1598,7 +4977,7 @@ func (s *dragonAPIServer) serveEncryptProtobuf(ctx context.Context, resp http.Re
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveDecrypt(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetTOTPLockout(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1606,9 +4985,9 @@ func (s *dragonAPIServer) serveDecrypt(ctx context.Context, resp http.ResponseWr
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveDecryptJSON(ctx, resp, req)
+		s.serveGetTOTPLockoutJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveDecryptProtobuf(ctx, resp, req)
+		s.serveGetTOTPLockoutProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1616,9 +4995,9 @@ func (s *dragonAPIServer) serveDecrypt(ctx context.Context, resp http.ResponseWr
 	}
 }
 
-func (s *dragonAPIServer) serveDecryptJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetTOTPLockoutJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Decrypt")
+	ctx = ctxsetters.WithMethodName(ctx, "GetTOTPLockout")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1631,29 +5010,29 @@ func (s *dragonAPIServer) serveDecryptJSON(ctx context.Context, resp http.Respon
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(DecryptRequest)
+	reqContent := new(GetTOTPLockoutRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.Decrypt
+	handler := s.DragonAPI.GetTOTPLockout
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DecryptRequest) (*DecryptResponse, error) {
+		handler = func(ctx context.Context, req *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DecryptRequest)
+					typedReq, ok := req.(*GetTOTPLockoutRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DecryptRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetTOTPLockoutRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Decrypt(ctx, typedReq)
+					return s.DragonAPI.GetTOTPLockout(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DecryptResponse)
+				typedResp, ok := resp.(*GetTOTPLockoutResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DecryptResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTOTPLockoutResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1662,7 +5041,7 @@ func (s *dragonAPIServer) serveDecryptJSON(ctx context.Context, resp http.Respon
 	}
 
 	// Call service method
-	var respContent *DecryptResponse
+	var respContent *GetTOTPLockoutResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1673,7 +5052,7 @@ func (s *dragonAPIServer) serveDecryptJSON(ctx context.Context, resp http.Respon
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DecryptResponse and nil error while calling Decrypt. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetTOTPLockoutResponse and nil error while calling GetTOTPLockout. nil responses are not supported"))
 		return
 	}
 
@@ -1699,42 +5078,42 @@ func (s *dragonAPIServer) serveDecryptJSON(ctx context.Context, resp http.Respon
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveDecryptProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetTOTPLockoutProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Decrypt")
+	ctx = ctxsetters.WithMethodName(ctx, "GetTOTPLockout")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(DecryptRequest)
+	reqContent := new(GetTOTPLockoutRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.Decrypt
+	handler := s.DragonAPI.GetTOTPLockout
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DecryptRequest) (*DecryptResponse, error) {
+		handler = func(ctx context.Context, req *GetTOTPLockoutRequest) (*GetTOTPLockoutResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DecryptRequest)
+					typedReq, ok := req.(*GetTOTPLockoutRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DecryptRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetTOTPLockoutRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Decrypt(ctx, typedReq)
+					return s.DragonAPI.GetTOTPLockout(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DecryptResponse)
+				typedResp, ok := resp.(*GetTOTPLockoutResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DecryptResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetTOTPLockoutResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1743,7 +5122,7 @@ func (s *dragonAPIServer) serveDecryptProtobuf(ctx context.Context, resp http.Re
 	}
 
 	// Call service method
-	var respContent *DecryptResponse
+	var respContent *GetTOTPLockoutResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1754,7 +5133,7 @@ func (s *dragonAPIServer) serveDecryptProtobuf(ctx context.Context, resp http.Re
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DecryptResponse and nil error while calling Decrypt. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetTOTPLockoutResponse and nil error while calling GetTOTPLockout. nil responses are not supported"))
 		return
 	}
 
@@ -1778,7 +5157,7 @@ func (s *dragonAPIServer) serveDecryptProtobuf(ctx context.Context, resp http.Re
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveMAC(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveResetTOTPLockout(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1786,9 +5165,9 @@ func (s *dragonAPIServer) serveMAC(ctx context.Context, resp http.ResponseWriter
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveMACJSON(ctx, resp, req)
+		s.serveResetTOTPLockoutJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveMACProtobuf(ctx, resp, req)
+		s.serveResetTOTPLockoutProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1796,9 +5175,9 @@ func (s *dragonAPIServer) serveMAC(ctx context.Context, resp http.ResponseWriter
 	}
 }
 
-func (s *dragonAPIServer) serveMACJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveResetTOTPLockoutJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "MAC")
+	ctx = ctxsetters.WithMethodName(ctx, "ResetTOTPLockout")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1811,29 +5190,29 @@ func (s *dragonAPIServer) serveMACJSON(ctx context.Context, resp http.ResponseWr
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(MACRequest)
+	reqContent := new(ResetTOTPLockoutRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.MAC
+	handler := s.DragonAPI.ResetTOTPLockout
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *MACRequest) (*MACResponse, error) {
+		handler = func(ctx context.Context, req *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*MACRequest)
+					typedReq, ok := req.(*ResetTOTPLockoutRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*MACRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ResetTOTPLockoutRequest) when calling interceptor")
 					}
-					return s.DragonAPI.MAC(ctx, typedReq)
+					return s.DragonAPI.ResetTOTPLockout(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*MACResponse)
+				typedResp, ok := resp.(*ResetTOTPLockoutResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*MACResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ResetTOTPLockoutResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1842,7 +5221,7 @@ func (s *dragonAPIServer) serveMACJSON(ctx context.Context, resp http.ResponseWr
 	}
 
 	// Call service method
-	var respContent *MACResponse
+	var respContent *ResetTOTPLockoutResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1853,7 +5232,7 @@ func (s *dragonAPIServer) serveMACJSON(ctx context.Context, resp http.ResponseWr
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *MACResponse and nil error while calling MAC. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ResetTOTPLockoutResponse and nil error while calling ResetTOTPLockout. nil responses are not supported"))
 		return
 	}
 
@@ -1879,42 +5258,42 @@ func (s *dragonAPIServer) serveMACJSON(ctx context.Context, resp http.ResponseWr
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveMACProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveResetTOTPLockoutProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "MAC")
+	ctx = ctxsetters.WithMethodName(ctx, "ResetTOTPLockout")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(MACRequest)
+	reqContent := new(ResetTOTPLockoutRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.MAC
+	handler := s.DragonAPI.ResetTOTPLockout
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *MACRequest) (*MACResponse, error) {
+		handler = func(ctx context.Context, req *ResetTOTPLockoutRequest) (*ResetTOTPLockoutResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*MACRequest)
+					typedReq, ok := req.(*ResetTOTPLockoutRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*MACRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*ResetTOTPLockoutRequest) when calling interceptor")
 					}
-					return s.DragonAPI.MAC(ctx, typedReq)
+					return s.DragonAPI.ResetTOTPLockout(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*MACResponse)
+				typedResp, ok := resp.(*ResetTOTPLockoutResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*MACResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*ResetTOTPLockoutResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -1923,7 +5302,7 @@ func (s *dragonAPIServer) serveMACProtobuf(ctx context.Context, resp http.Respon
 	}
 
 	// Call service method
-	var respContent *MACResponse
+	var respContent *ResetTOTPLockoutResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -1934,7 +5313,7 @@ func (s *dragonAPIServer) serveMACProtobuf(ctx context.Context, resp http.Respon
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *MACResponse and nil error while calling MAC. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *ResetTOTPLockoutResponse and nil error while calling ResetTOTPLockout. nil responses are not supported"))
 		return
 	}
 
@@ -1958,7 +5337,7 @@ func (s *dragonAPIServer) serveMACProtobuf(ctx context.Context, resp http.Respon
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveSign(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetCacheStats(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -1966,9 +5345,9 @@ func (s *dragonAPIServer) serveSign(ctx context.Context, resp http.ResponseWrite
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveSignJSON(ctx, resp, req)
+		s.serveGetCacheStatsJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveSignProtobuf(ctx, resp, req)
+		s.serveGetCacheStatsProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -1976,9 +5355,9 @@ func (s *dragonAPIServer) serveSign(ctx context.Context, resp http.ResponseWrite
 	}
 }
 
-func (s *dragonAPIServer) serveSignJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetCacheStatsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Sign")
+	ctx = ctxsetters.WithMethodName(ctx, "GetCacheStats")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -1991,29 +5370,29 @@ func (s *dragonAPIServer) serveSignJSON(ctx context.Context, resp http.ResponseW
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(SignRequest)
+	reqContent := new(GetCacheStatsRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.Sign
+	handler := s.DragonAPI.GetCacheStats
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+		handler = func(ctx context.Context, req *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*SignRequest)
+					typedReq, ok := req.(*GetCacheStatsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*SignRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetCacheStatsRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Sign(ctx, typedReq)
+					return s.DragonAPI.GetCacheStats(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*SignResponse)
+				typedResp, ok := resp.(*GetCacheStatsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*SignResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetCacheStatsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2022,7 +5401,7 @@ func (s *dragonAPIServer) serveSignJSON(ctx context.Context, resp http.ResponseW
 	}
 
 	// Call service method
-	var respContent *SignResponse
+	var respContent *GetCacheStatsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2033,7 +5412,7 @@ func (s *dragonAPIServer) serveSignJSON(ctx context.Context, resp http.ResponseW
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *SignResponse and nil error while calling Sign. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetCacheStatsResponse and nil error while calling GetCacheStats. nil responses are not supported"))
 		return
 	}
 
@@ -2059,42 +5438,42 @@ func (s *dragonAPIServer) serveSignJSON(ctx context.Context, resp http.ResponseW
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveSignProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetCacheStatsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Sign")
+	ctx = ctxsetters.WithMethodName(ctx, "GetCacheStats")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(SignRequest)
+	reqContent := new(GetCacheStatsRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.Sign
+	handler := s.DragonAPI.GetCacheStats
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *SignRequest) (*SignResponse, error) {
+		handler = func(ctx context.Context, req *GetCacheStatsRequest) (*GetCacheStatsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*SignRequest)
+					typedReq, ok := req.(*GetCacheStatsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*SignRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetCacheStatsRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Sign(ctx, typedReq)
+					return s.DragonAPI.GetCacheStats(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*SignResponse)
+				typedResp, ok := resp.(*GetCacheStatsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*SignResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetCacheStatsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2103,7 +5482,7 @@ func (s *dragonAPIServer) serveSignProtobuf(ctx context.Context, resp http.Respo
 	}
 
 	// Call service method
-	var respContent *SignResponse
+	var respContent *GetCacheStatsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2114,7 +5493,7 @@ func (s *dragonAPIServer) serveSignProtobuf(ctx context.Context, resp http.Respo
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *SignResponse and nil error while calling Sign. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetCacheStatsResponse and nil error while calling GetCacheStats. nil responses are not supported"))
 		return
 	}
 
@@ -2138,7 +5517,7 @@ func (s *dragonAPIServer) serveSignProtobuf(ctx context.Context, resp http.Respo
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveVerify(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveInvalidateKeyRing(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -2146,9 +5525,9 @@ func (s *dragonAPIServer) serveVerify(ctx context.Context, resp http.ResponseWri
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveVerifyJSON(ctx, resp, req)
+		s.serveInvalidateKeyRingJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveVerifyProtobuf(ctx, resp, req)
+		s.serveInvalidateKeyRingProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -2156,9 +5535,9 @@ func (s *dragonAPIServer) serveVerify(ctx context.Context, resp http.ResponseWri
 	}
 }
 
-func (s *dragonAPIServer) serveVerifyJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveInvalidateKeyRingJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Verify")
+	ctx = ctxsetters.WithMethodName(ctx, "InvalidateKeyRing")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -2171,29 +5550,29 @@ func (s *dragonAPIServer) serveVerifyJSON(ctx context.Context, resp http.Respons
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(VerifyRequest)
+	reqContent := new(InvalidateKeyRingRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.Verify
+	handler := s.DragonAPI.InvalidateKeyRing
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+		handler = func(ctx context.Context, req *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*VerifyRequest)
+					typedReq, ok := req.(*InvalidateKeyRingRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*VerifyRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*InvalidateKeyRingRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Verify(ctx, typedReq)
+					return s.DragonAPI.InvalidateKeyRing(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*VerifyResponse)
+				typedResp, ok := resp.(*InvalidateKeyRingResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*VerifyResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*InvalidateKeyRingResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2202,7 +5581,7 @@ func (s *dragonAPIServer) serveVerifyJSON(ctx context.Context, resp http.Respons
 	}
 
 	// Call service method
-	var respContent *VerifyResponse
+	var respContent *InvalidateKeyRingResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2213,7 +5592,7 @@ func (s *dragonAPIServer) serveVerifyJSON(ctx context.Context, resp http.Respons
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyResponse and nil error while calling Verify. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *InvalidateKeyRingResponse and nil error while calling InvalidateKeyRing. nil responses are not supported"))
 		return
 	}
 
@@ -2239,42 +5618,42 @@ func (s *dragonAPIServer) serveVerifyJSON(ctx context.Context, resp http.Respons
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveVerifyProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveInvalidateKeyRingProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "Verify")
+	ctx = ctxsetters.WithMethodName(ctx, "InvalidateKeyRing")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(VerifyRequest)
+	reqContent := new(InvalidateKeyRingRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.Verify
+	handler := s.DragonAPI.InvalidateKeyRing
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *VerifyRequest) (*VerifyResponse, error) {
+		handler = func(ctx context.Context, req *InvalidateKeyRingRequest) (*InvalidateKeyRingResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*VerifyRequest)
+					typedReq, ok := req.(*InvalidateKeyRingRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*VerifyRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*InvalidateKeyRingRequest) when calling interceptor")
 					}
-					return s.DragonAPI.Verify(ctx, typedReq)
+					return s.DragonAPI.InvalidateKeyRing(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*VerifyResponse)
+				typedResp, ok := resp.(*InvalidateKeyRingResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*VerifyResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*InvalidateKeyRingResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2283,7 +5662,7 @@ func (s *dragonAPIServer) serveVerifyProtobuf(ctx context.Context, resp http.Res
 	}
 
 	// Call service method
-	var respContent *VerifyResponse
+	var respContent *InvalidateKeyRingResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2294,7 +5673,7 @@ func (s *dragonAPIServer) serveVerifyProtobuf(ctx context.Context, resp http.Res
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyResponse and nil error while calling Verify. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *InvalidateKeyRingResponse and nil error while calling InvalidateKeyRing. nil responses are not supported"))
 		return
 	}
 
@@ -2318,7 +5697,7 @@ func (s *dragonAPIServer) serveVerifyProtobuf(ctx context.Context, resp http.Res
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveGenerateTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetKeyPoolHealth(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -2326,9 +5705,9 @@ func (s *dragonAPIServer) serveGenerateTOTP(ctx context.Context, resp http.Respo
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveGenerateTOTPJSON(ctx, resp, req)
+		s.serveGetKeyPoolHealthJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveGenerateTOTPProtobuf(ctx, resp, req)
+		s.serveGetKeyPoolHealthProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -2336,9 +5715,9 @@ func (s *dragonAPIServer) serveGenerateTOTP(ctx context.Context, resp http.Respo
 	}
 }
 
-func (s *dragonAPIServer) serveGenerateTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetKeyPoolHealthJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "GenerateTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetKeyPoolHealth")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -2351,29 +5730,29 @@ func (s *dragonAPIServer) serveGenerateTOTPJSON(ctx context.Context, resp http.R
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(GenerateTOTPRequest)
+	reqContent := new(GetKeyPoolHealthRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.GenerateTOTP
+	handler := s.DragonAPI.GetKeyPoolHealth
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*GenerateTOTPRequest)
+					typedReq, ok := req.(*GetKeyPoolHealthRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*GenerateTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetKeyPoolHealthRequest) when calling interceptor")
 					}
-					return s.DragonAPI.GenerateTOTP(ctx, typedReq)
+					return s.DragonAPI.GetKeyPoolHealth(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*GenerateTOTPResponse)
+				typedResp, ok := resp.(*GetKeyPoolHealthResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*GenerateTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetKeyPoolHealthResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2382,7 +5761,7 @@ func (s *dragonAPIServer) serveGenerateTOTPJSON(ctx context.Context, resp http.R
 	}
 
 	// Call service method
-	var respContent *GenerateTOTPResponse
+	var respContent *GetKeyPoolHealthResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2393,7 +5772,7 @@ func (s *dragonAPIServer) serveGenerateTOTPJSON(ctx context.Context, resp http.R
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *GenerateTOTPResponse and nil error while calling GenerateTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetKeyPoolHealthResponse and nil error while calling GetKeyPoolHealth. nil responses are not supported"))
 		return
 	}
 
@@ -2419,42 +5798,42 @@ func (s *dragonAPIServer) serveGenerateTOTPJSON(ctx context.Context, resp http.R
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveGenerateTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetKeyPoolHealthProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "GenerateTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetKeyPoolHealth")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(GenerateTOTPRequest)
+	reqContent := new(GetKeyPoolHealthRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.GenerateTOTP
+	handler := s.DragonAPI.GetKeyPoolHealth
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *GenerateTOTPRequest) (*GenerateTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetKeyPoolHealthRequest) (*GetKeyPoolHealthResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*GenerateTOTPRequest)
+					typedReq, ok := req.(*GetKeyPoolHealthRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*GenerateTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetKeyPoolHealthRequest) when calling interceptor")
 					}
-					return s.DragonAPI.GenerateTOTP(ctx, typedReq)
+					return s.DragonAPI.GetKeyPoolHealth(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*GenerateTOTPResponse)
+				typedResp, ok := resp.(*GetKeyPoolHealthResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*GenerateTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetKeyPoolHealthResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2463,7 +5842,7 @@ func (s *dragonAPIServer) serveGenerateTOTPProtobuf(ctx context.Context, resp ht
 	}
 
 	// Call service method
-	var respContent *GenerateTOTPResponse
+	var respContent *GetKeyPoolHealthResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2474,7 +5853,7 @@ func (s *dragonAPIServer) serveGenerateTOTPProtobuf(ctx context.Context, resp ht
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *GenerateTOTPResponse and nil error while calling GenerateTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetKeyPoolHealthResponse and nil error while calling GetKeyPoolHealth. nil responses are not supported"))
 		return
 	}
 
@@ -2498,7 +5877,7 @@ func (s *dragonAPIServer) serveGenerateTOTPProtobuf(ctx context.Context, resp ht
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveVerifyTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetRootKeyGenerations(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -2506,9 +5885,9 @@ func (s *dragonAPIServer) serveVerifyTOTP(ctx context.Context, resp http.Respons
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveVerifyTOTPJSON(ctx, resp, req)
+		s.serveGetRootKeyGenerationsJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveVerifyTOTPProtobuf(ctx, resp, req)
+		s.serveGetRootKeyGenerationsProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -2516,9 +5895,9 @@ func (s *dragonAPIServer) serveVerifyTOTP(ctx context.Context, resp http.Respons
 	}
 }
 
-func (s *dragonAPIServer) serveVerifyTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetRootKeyGenerationsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "VerifyTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetRootKeyGenerations")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -2531,29 +5910,29 @@ func (s *dragonAPIServer) serveVerifyTOTPJSON(ctx context.Context, resp http.Res
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(VerifyTOTPRequest)
+	reqContent := new(GetRootKeyGenerationsRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.VerifyTOTP
+	handler := s.DragonAPI.GetRootKeyGenerations
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*VerifyTOTPRequest)
+					typedReq, ok := req.(*GetRootKeyGenerationsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*VerifyTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetRootKeyGenerationsRequest) when calling interceptor")
 					}
-					return s.DragonAPI.VerifyTOTP(ctx, typedReq)
+					return s.DragonAPI.GetRootKeyGenerations(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*VerifyTOTPResponse)
+				typedResp, ok := resp.(*GetRootKeyGenerationsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*VerifyTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetRootKeyGenerationsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2562,7 +5941,7 @@ func (s *dragonAPIServer) serveVerifyTOTPJSON(ctx context.Context, resp http.Res
 	}
 
 	// Call service method
-	var respContent *VerifyTOTPResponse
+	var respContent *GetRootKeyGenerationsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2573,7 +5952,7 @@ func (s *dragonAPIServer) serveVerifyTOTPJSON(ctx context.Context, resp http.Res
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyTOTPResponse and nil error while calling VerifyTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetRootKeyGenerationsResponse and nil error while calling GetRootKeyGenerations. nil responses are not supported"))
 		return
 	}
 
@@ -2599,42 +5978,42 @@ func (s *dragonAPIServer) serveVerifyTOTPJSON(ctx context.Context, resp http.Res
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveVerifyTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetRootKeyGenerationsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "VerifyTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetRootKeyGenerations")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(VerifyTOTPRequest)
+	reqContent := new(GetRootKeyGenerationsRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.VerifyTOTP
+	handler := s.DragonAPI.GetRootKeyGenerations
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *VerifyTOTPRequest) (*VerifyTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetRootKeyGenerationsRequest) (*GetRootKeyGenerationsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*VerifyTOTPRequest)
+					typedReq, ok := req.(*GetRootKeyGenerationsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*VerifyTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetRootKeyGenerationsRequest) when calling interceptor")
 					}
-					return s.DragonAPI.VerifyTOTP(ctx, typedReq)
+					return s.DragonAPI.GetRootKeyGenerations(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*VerifyTOTPResponse)
+				typedResp, ok := resp.(*GetRootKeyGenerationsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*VerifyTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetRootKeyGenerationsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2643,7 +6022,7 @@ func (s *dragonAPIServer) serveVerifyTOTPProtobuf(ctx context.Context, resp http
 	}
 
 	// Call service method
-	var respContent *VerifyTOTPResponse
+	var respContent *GetRootKeyGenerationsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2654,7 +6033,7 @@ func (s *dragonAPIServer) serveVerifyTOTPProtobuf(ctx context.Context, resp http
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *VerifyTOTPResponse and nil error while calling VerifyTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetRootKeyGenerationsResponse and nil error while calling GetRootKeyGenerations. nil responses are not supported"))
 		return
 	}
 
@@ -2678,7 +6057,7 @@ func (s *dragonAPIServer) serveVerifyTOTPProtobuf(ctx context.Context, resp http
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveBatchVerifyTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetAuditSinkStatus(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -2686,9 +6065,9 @@ func (s *dragonAPIServer) serveBatchVerifyTOTP(ctx context.Context, resp http.Re
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveBatchVerifyTOTPJSON(ctx, resp, req)
+		s.serveGetAuditSinkStatusJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveBatchVerifyTOTPProtobuf(ctx, resp, req)
+		s.serveGetAuditSinkStatusProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -2696,9 +6075,9 @@ func (s *dragonAPIServer) serveBatchVerifyTOTP(ctx context.Context, resp http.Re
 	}
 }
 
-func (s *dragonAPIServer) serveBatchVerifyTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetAuditSinkStatusJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "BatchVerifyTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetAuditSinkStatus")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -2711,29 +6090,29 @@ func (s *dragonAPIServer) serveBatchVerifyTOTPJSON(ctx context.Context, resp htt
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(BatchVerifyTOTPRequest)
+	reqContent := new(GetAuditSinkStatusRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.BatchVerifyTOTP
+	handler := s.DragonAPI.GetAuditSinkStatus
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*BatchVerifyTOTPRequest)
+					typedReq, ok := req.(*GetAuditSinkStatusRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*BatchVerifyTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetAuditSinkStatusRequest) when calling interceptor")
 					}
-					return s.DragonAPI.BatchVerifyTOTP(ctx, typedReq)
+					return s.DragonAPI.GetAuditSinkStatus(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*BatchVerifyTOTPResponse)
+				typedResp, ok := resp.(*GetAuditSinkStatusResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*BatchVerifyTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetAuditSinkStatusResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2742,7 +6121,7 @@ func (s *dragonAPIServer) serveBatchVerifyTOTPJSON(ctx context.Context, resp htt
 	}
 
 	// Call service method
-	var respContent *BatchVerifyTOTPResponse
+	var respContent *GetAuditSinkStatusResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2753,7 +6132,7 @@ func (s *dragonAPIServer) serveBatchVerifyTOTPJSON(ctx context.Context, resp htt
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchVerifyTOTPResponse and nil error while calling BatchVerifyTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetAuditSinkStatusResponse and nil error while calling GetAuditSinkStatus. nil responses are not supported"))
 		return
 	}
 
@@ -2779,42 +6158,42 @@ func (s *dragonAPIServer) serveBatchVerifyTOTPJSON(ctx context.Context, resp htt
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveBatchVerifyTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetAuditSinkStatusProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "BatchVerifyTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetAuditSinkStatus")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(BatchVerifyTOTPRequest)
+	reqContent := new(GetAuditSinkStatusRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.BatchVerifyTOTP
+	handler := s.DragonAPI.GetAuditSinkStatus
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *BatchVerifyTOTPRequest) (*BatchVerifyTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetAuditSinkStatusRequest) (*GetAuditSinkStatusResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*BatchVerifyTOTPRequest)
+					typedReq, ok := req.(*GetAuditSinkStatusRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*BatchVerifyTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetAuditSinkStatusRequest) when calling interceptor")
 					}
-					return s.DragonAPI.BatchVerifyTOTP(ctx, typedReq)
+					return s.DragonAPI.GetAuditSinkStatus(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*BatchVerifyTOTPResponse)
+				typedResp, ok := resp.(*GetAuditSinkStatusResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*BatchVerifyTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetAuditSinkStatusResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2823,7 +6202,7 @@ func (s *dragonAPIServer) serveBatchVerifyTOTPProtobuf(ctx context.Context, resp
 	}
 
 	// Call service method
-	var respContent *BatchVerifyTOTPResponse
+	var respContent *GetAuditSinkStatusResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2834,7 +6213,7 @@ func (s *dragonAPIServer) serveBatchVerifyTOTPProtobuf(ctx context.Context, resp
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *BatchVerifyTOTPResponse and nil error while calling BatchVerifyTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetAuditSinkStatusResponse and nil error while calling GetAuditSinkStatus. nil responses are not supported"))
 		return
 	}
 
@@ -2858,7 +6237,7 @@ func (s *dragonAPIServer) serveBatchVerifyTOTPProtobuf(ctx context.Context, resp
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveDeleteTOTP(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetDualRunStats(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	header := req.Header.Get("Content-Type")
 	i := strings.Index(header, ";")
 	if i == -1 {
@@ -2866,9 +6245,9 @@ func (s *dragonAPIServer) serveDeleteTOTP(ctx context.Context, resp http.Respons
 	}
 	switch strings.TrimSpace(strings.ToLower(header[:i])) {
 	case "application/json":
-		s.serveDeleteTOTPJSON(ctx, resp, req)
+		s.serveGetDualRunStatsJSON(ctx, resp, req)
 	case "application/protobuf":
-		s.serveDeleteTOTPProtobuf(ctx, resp, req)
+		s.serveGetDualRunStatsProtobuf(ctx, resp, req)
 	default:
 		msg := fmt.Sprintf("unexpected Content-Type: %q", req.Header.Get("Content-Type"))
 		twerr := badRouteError(msg, req.Method, req.URL.Path)
@@ -2876,9 +6255,9 @@ func (s *dragonAPIServer) serveDeleteTOTP(ctx context.Context, resp http.Respons
 	}
 }
 
-func (s *dragonAPIServer) serveDeleteTOTPJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetDualRunStatsJSON(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "DeleteTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetDualRunStats")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
@@ -2891,29 +6270,29 @@ func (s *dragonAPIServer) serveDeleteTOTPJSON(ctx context.Context, resp http.Res
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
-	reqContent := new(DeleteTOTPRequest)
+	reqContent := new(GetDualRunStatsRequest)
 	unmarshaler := protojson.UnmarshalOptions{DiscardUnknown: true}
 	if err = unmarshaler.Unmarshal(rawReqBody, reqContent); err != nil {
 		s.handleRequestBodyError(ctx, resp, "the json request could not be decoded", err)
 		return
 	}
 
-	handler := s.DragonAPI.DeleteTOTP
+	handler := s.DragonAPI.GetDualRunStats
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DeleteTOTPRequest)
+					typedReq, ok := req.(*GetDualRunStatsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DeleteTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetDualRunStatsRequest) when calling interceptor")
 					}
-					return s.DragonAPI.DeleteTOTP(ctx, typedReq)
+					return s.DragonAPI.GetDualRunStats(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DeleteTOTPResponse)
+				typedResp, ok := resp.(*GetDualRunStatsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetDualRunStatsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -2922,7 +6301,7 @@ func (s *dragonAPIServer) serveDeleteTOTPJSON(ctx context.Context, resp http.Res
 	}
 
 	// Call service method
-	var respContent *DeleteTOTPResponse
+	var respContent *GetDualRunStatsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -2933,7 +6312,7 @@ func (s *dragonAPIServer) serveDeleteTOTPJSON(ctx context.Context, resp http.Res
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTOTPResponse and nil error while calling DeleteTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetDualRunStatsResponse and nil error while calling GetDualRunStats. nil responses are not supported"))
 		return
 	}
 
@@ -2959,42 +6338,42 @@ func (s *dragonAPIServer) serveDeleteTOTPJSON(ctx context.Context, resp http.Res
 	callResponseSent(ctx, s.hooks)
 }
 
-func (s *dragonAPIServer) serveDeleteTOTPProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+func (s *dragonAPIServer) serveGetDualRunStatsProtobuf(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
 	var err error
-	ctx = ctxsetters.WithMethodName(ctx, "DeleteTOTP")
+	ctx = ctxsetters.WithMethodName(ctx, "GetDualRunStats")
 	ctx, err = callRequestRouted(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
 
-	buf, err := ioutil.ReadAll(req.Body)
+	buf, err := io.ReadAll(req.Body)
 	if err != nil {
 		s.handleRequestBodyError(ctx, resp, "failed to read request body", err)
 		return
 	}
-	reqContent := new(DeleteTOTPRequest)
+	reqContent := new(GetDualRunStatsRequest)
 	if err = proto.Unmarshal(buf, reqContent); err != nil {
 		s.writeError(ctx, resp, malformedRequestError("the protobuf request could not be decoded"))
 		return
 	}
 
-	handler := s.DragonAPI.DeleteTOTP
+	handler := s.DragonAPI.GetDualRunStats
 	if s.interceptor != nil {
-		handler = func(ctx context.Context, req *DeleteTOTPRequest) (*DeleteTOTPResponse, error) {
+		handler = func(ctx context.Context, req *GetDualRunStatsRequest) (*GetDualRunStatsResponse, error) {
 			resp, err := s.interceptor(
 				func(ctx context.Context, req interface{}) (interface{}, error) {
-					typedReq, ok := req.(*DeleteTOTPRequest)
+					typedReq, ok := req.(*GetDualRunStatsRequest)
 					if !ok {
-						return nil, twirp.InternalError("failed type assertion req.(*DeleteTOTPRequest) when calling interceptor")
+						return nil, twirp.InternalError("failed type assertion req.(*GetDualRunStatsRequest) when calling interceptor")
 					}
-					return s.DragonAPI.DeleteTOTP(ctx, typedReq)
+					return s.DragonAPI.GetDualRunStats(ctx, typedReq)
 				},
 			)(ctx, req)
 			if resp != nil {
-				typedResp, ok := resp.(*DeleteTOTPResponse)
+				typedResp, ok := resp.(*GetDualRunStatsResponse)
 				if !ok {
-					return nil, twirp.InternalError("failed type assertion resp.(*DeleteTOTPResponse) when calling interceptor")
+					return nil, twirp.InternalError("failed type assertion resp.(*GetDualRunStatsResponse) when calling interceptor")
 				}
 				return typedResp, err
 			}
@@ -3003,7 +6382,7 @@ func (s *dragonAPIServer) serveDeleteTOTPProtobuf(ctx context.Context, resp http
 	}
 
 	// Call service method
-	var respContent *DeleteTOTPResponse
+	var respContent *GetDualRunStatsResponse
 	func() {
 		defer ensurePanicResponses(ctx, resp, s.hooks)
 		respContent, err = handler(ctx, reqContent)
@@ -3014,7 +6393,7 @@ func (s *dragonAPIServer) serveDeleteTOTPProtobuf(ctx context.Context, resp http
 		return
 	}
 	if respContent == nil {
-		s.writeError(ctx, resp, twirp.InternalError("received a nil *DeleteTOTPResponse and nil error while calling DeleteTOTP. nil responses are not supported"))
+		s.writeError(ctx, resp, twirp.InternalError("received a nil *GetDualRunStatsResponse and nil error while calling GetDualRunStats. nil responses are not supported"))
 		return
 	}
 
@@ -3043,7 +6422,7 @@ func (s *dragonAPIServer) ServiceDescriptor() ([]byte, int) {
 }
 
 func (s *dragonAPIServer) ProtocGenTwirpVersion() string {
-	return "v8.1.0"
+	return "v8.1.3"
 }
 
 // PathPrefix returns the base service path, in the form: "/<prefix>/<package>.<Service>/"
@@ -3162,7 +6541,7 @@ func writeError(ctx context.Context, resp http.ResponseWriter, err error, hooks
 }
 
 // sanitizeBaseURL parses the the baseURL, and adds the "http" scheme if needed.
-// If the URL is unparsable, the baseURL is returned unchaged.
+// If the URL is unparsable, the baseURL is returned unchanged.
 func sanitizeBaseURL(baseURL string) string {
 	u, err := url.Parse(baseURL)
 	if err != nil {
@@ -3176,9 +6555,12 @@ func sanitizeBaseURL(baseURL string) string {
 
 // baseServicePath composes the path prefix for the service (without <Method>).
 // e.g.: baseServicePath("/twirp", "my.pkg", "MyService")
-//       returns => "/twirp/my.pkg.MyService/"
+//
+//	returns => "/twirp/my.pkg.MyService/"
+//
 // e.g.: baseServicePath("", "", "MyService")
-//       returns => "/MyService/"
+//
+//	returns => "/MyService/"
 func baseServicePath(prefix, pkg, service string) string {
 	fullServiceName := service
 	if pkg != "" {
@@ -3233,7 +6615,7 @@ func newRequest(ctx context.Context, url string, reqBody io.Reader, contentType
 	}
 	req.Header.Set("Accept", contentType)
 	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("Twirp-Version", "v8.1.0")
+	req.Header.Set("Twirp-Version", "v8.1.3")
 	return req, nil
 }
 
@@ -3284,7 +6666,7 @@ func errorFromResponse(resp *http.Response) twirp.Error {
 		return twirpErrorFromIntermediary(statusCode, msg, location)
 	}
 
-	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	respBodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return wrapInternal(err, "failed to read server error response body")
 	}
@@ -3474,13 +6856,7 @@ func doProtobufRequest(ctx context.Context, client HTTPClient, hooks *twirp.Clie
 	if err != nil {
 		return ctx, wrapInternal(err, "failed to do request")
 	}
-
-	defer func() {
-		cerr := resp.Body.Close()
-		if err == nil && cerr != nil {
-			err = wrapInternal(cerr, "failed to close response body")
-		}
-	}()
+	defer func() { _ = resp.Body.Close() }()
 
 	if err = ctx.Err(); err != nil {
 		return ctx, wrapInternal(err, "aborted because context was done")
@@ -3490,7 +6866,7 @@ func doProtobufRequest(ctx context.Context, client HTTPClient, hooks *twirp.Clie
 		return ctx, errorFromResponse(resp)
 	}
 
-	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	respBodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return ctx, wrapInternal(err, "failed to read response body")
 	}
@@ -3622,63 +6998,153 @@ func callClientError(ctx context.Context, h *twirp.ClientHooks, err twirp.Error)
 }
 
 var twirpFileDescriptor0 = []byte{
-	// 919 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x96, 0xdd, 0x72, 0xda, 0x46,
-	0x14, 0xc7, 0x8d, 0xa0, 0x60, 0x0e, 0x18, 0xe4, 0x8d, 0x27, 0xa1, 0x6a, 0xe2, 0x8f, 0x4d, 0x93,
-	0x7a, 0xda, 0x0e, 0x8c, 0xdd, 0xab, 0xde, 0xb4, 0xc5, 0xd8, 0xe3, 0xd1, 0x38, 0x10, 0x47, 0xa6,
-	0x9e, 0x71, 0x7b, 0xa1, 0x59, 0x4b, 0x5b, 0xbc, 0x63, 0x23, 0xc9, 0x92, 0x20, 0x51, 0x1f, 0xa1,
-	0x2f, 0xd4, 0xbb, 0xde, 0xf5, 0xbd, 0x3a, 0x5a, 0x2d, 0x42, 0x1f, 0x04, 0x68, 0x72, 0xb7, 0x7b,
-	0x74, 0xce, 0xef, 0xff, 0xdf, 0xaf, 0x03, 0xb0, 0x47, 0xfe, 0xb4, 0xed, 0x8e, 0xe9, 0x92, 0x91,
-	0x6d, 0x75, 0xa6, 0x47, 0x62, 0xa4, 0x13, 0x87, 0xb5, 0x1d, 0xd7, 0xf6, 0x6d, 0xd4, 0x08, 0x13,
-	0xda, 0x51, 0xb8, 0x3d, 0x3d, 0xc2, 0x7f, 0x17, 0x40, 0xee, 0xb9, 0x94, 0xf8, 0xf4, 0x82, 0x06,
-	0x1a, 0x7d, 0x9c, 0x50, 0xcf, 0x47, 0x5f, 0xc2, 0xe6, 0x3d, 0x0d, 0x74, 0x97, 0x59, 0xa3, 0x56,
-	0x61, 0xbf, 0x70, 0x58, 0xd5, 0x2a, 0xf7, 0x34, 0xd0, 0x98, 0x35, 0x42, 0x3f, 0x42, 0xc9, 0x0f,
-	0x1c, 0xda, 0x92, 0xf6, 0x0b, 0x87, 0x8d, 0xe3, 0x57, 0xed, 0x34, 0xae, 0x9d, 0x45, 0xb5, 0x87,
-	0x81, 0x43, 0x35, 0x5e, 0x82, 0xfb, 0x50, 0x0a, 0x67, 0x48, 0x86, 0xfa, 0xf0, 0xe6, 0xf2, 0x4c,
-	0x57, 0x07, 0xd7, 0xdd, 0x37, 0xea, 0xa9, 0xbc, 0x81, 0x9e, 0x40, 0x93, 0x47, 0xce, 0x06, 0x3d,
-	0xed, 0xe6, 0x72, 0xa8, 0xbe, 0x1d, 0xc8, 0x85, 0x38, 0xed, 0x4a, 0x3d, 0x1f, 0xa8, 0x83, 0x73,
-	0x59, 0x42, 0x75, 0xd8, 0xe4, 0x91, 0x7e, 0xb7, 0x27, 0x17, 0xf1, 0xbf, 0x12, 0x6c, 0x27, 0xe4,
-	0x3c, 0xc7, 0xb6, 0x3c, 0x8a, 0xae, 0xa1, 0x41, 0x2d, 0xc3, 0x0d, 0x1c, 0x9f, 0xd9, 0x96, 0x7e,
-	0x4f, 0x03, 0xbe, 0x80, 0xda, 0x71, 0x67, 0x89, 0xd3, 0xa8, 0xb4, 0x7d, 0x16, 0xd7, 0x85, 0xd1,
-	0x2d, 0x9a, 0x9c, 0xa2, 0x3e, 0xd4, 0x3c, 0x36, 0xb2, 0x98, 0x35, 0xe2, 0x50, 0x89, 0x43, 0xbf,
-	0x5f, 0x0d, 0xbd, 0x8a, 0x8a, 0xc2, 0x10, 0x78, 0xf1, 0x18, 0x75, 0xa1, 0x32, 0x26, 0x06, 0x47,
-	0x15, 0x39, 0xea, 0x70, 0x35, 0xaa, 0xdf, 0xed, 0x85, 0xd3, 0xf2, 0x98, 0x18, 0x17, 0x34, 0x50,
-	0x9a, 0xb0, 0x95, 0x72, 0xac, 0x7c, 0x07, 0x30, 0x57, 0x43, 0x2f, 0x00, 0x9c, 0xc9, 0xed, 0x03,
-	0x33, 0xe2, 0x4d, 0xa8, 0x6b, 0xd5, 0x28, 0x12, 0x26, 0x6f, 0x42, 0x39, 0xe2, 0xe1, 0x9f, 0xa1,
-	0x21, 0x38, 0x6b, 0x1c, 0x3f, 0x82, 0x92, 0x49, 0x7c, 0xc2, 0xd7, 0x5f, 0xd7, 0xf8, 0x18, 0x1f,
-	0x41, 0x33, 0x06, 0x88, 0x53, 0xd8, 0x05, 0x30, 0x98, 0x73, 0x47, 0x5d, 0x9f, 0x7e, 0xf0, 0x05,
-	0x23, 0x11, 0xc1, 0x17, 0xd0, 0x38, 0xa5, 0xeb, 0x6a, 0xa6, 0x61, 0x52, 0x0e, 0xf6, 0x0a, 0x9a,
-	0x31, 0x4c, 0xe8, 0xcf, 0x6c, 0x16, 0x12, 0x36, 0xbb, 0x00, 0xfd, 0x6e, 0x6f, 0x0d, 0xbd, 0x16,
-	0x54, 0xc6, 0xd4, 0xf3, 0xc8, 0x88, 0x8a, 0x65, 0xce, 0xa6, 0x78, 0x0f, 0x6a, 0x1c, 0x21, 0x54,
-	0x64, 0x28, 0xfa, 0x64, 0x56, 0x1e, 0x0e, 0xf1, 0x09, 0xd4, 0xc2, 0x23, 0xf8, 0x2c, 0x91, 0x77,
-	0x50, 0x8f, 0x18, 0x42, 0xe5, 0x39, 0x54, 0xc3, 0x8b, 0x43, 0xfc, 0x89, 0x4b, 0x05, 0x65, 0x1e,
-	0x40, 0x2f, 0x61, 0xcb, 0x25, 0xef, 0xf5, 0x79, 0x46, 0x44, 0xab, 0xbb, 0xe4, 0xfd, 0xd5, 0x2c,
-	0x86, 0x6f, 0x61, 0xeb, 0x9a, 0xba, 0xec, 0x8f, 0xe0, 0x73, 0x8c, 0xa5, 0x8d, 0x14, 0x33, 0x46,
-	0xf0, 0x6b, 0x68, 0xcc, 0x34, 0x84, 0xf1, 0x1d, 0xf8, 0x62, 0x4a, 0x1e, 0x98, 0xc9, 0x15, 0x36,
-	0xb5, 0x68, 0x82, 0xff, 0x2a, 0xc0, 0x93, 0x73, 0x6a, 0x51, 0x97, 0xf8, 0x74, 0xf8, 0x76, 0x78,
-	0xb9, 0x86, 0xa5, 0xa7, 0x50, 0x66, 0x9e, 0x37, 0xa1, 0xae, 0x38, 0x7c, 0x31, 0x43, 0x07, 0x50,
-	0x27, 0x86, 0x61, 0x4f, 0x2c, 0x5f, 0xb7, 0xc8, 0x78, 0xe6, 0xa9, 0x26, 0x62, 0x03, 0x32, 0xa6,
-	0xe1, 0x2b, 0x98, 0xa5, 0x30, 0xb3, 0x55, 0x8a, 0x4c, 0x8b, 0x88, 0x6a, 0xe2, 0x77, 0xb0, 0x93,
-	0xf6, 0x22, 0xac, 0x37, 0x40, 0x12, 0xbe, 0xab, 0x9a, 0xc4, 0xcc, 0xf0, 0xa4, 0x27, 0x2e, 0x13,
-	0xf2, 0xe1, 0x10, 0x3d, 0x83, 0xca, 0xa3, 0xab, 0x1b, 0xb6, 0x39, 0x93, 0x2d, 0x3f, 0xba, 0x3d,
-	0xdb, 0xa4, 0xf8, 0x11, 0xb6, 0xa3, 0x7d, 0x58, 0x73, 0x71, 0x91, 0x94, 0x14, 0x4b, 0xa5, 0x1d,
-	0x17, 0x33, 0x8e, 0xc3, 0x9b, 0xcd, 0x45, 0xa3, 0xa5, 0xf0, 0x31, 0xfe, 0x16, 0x50, 0x52, 0x72,
-	0xe9, 0xf6, 0x7f, 0x80, 0xa7, 0x27, 0xc4, 0x37, 0xee, 0xfe, 0x97, 0x47, 0x19, 0x8a, 0xcc, 0xf4,
-	0x5a, 0xd2, 0x7e, 0x31, 0x5c, 0x3e, 0x33, 0xbd, 0x4f, 0x71, 0x79, 0x03, 0xcf, 0x72, 0xca, 0xcb,
-	0xac, 0xa2, 0x43, 0x90, 0xf9, 0x40, 0xf7, 0xef, 0x5c, 0x7b, 0x32, 0xba, 0xd3, 0xe3, 0x7d, 0x6a,
-	0xf0, 0xf8, 0x30, 0x0a, 0xab, 0x26, 0xfe, 0x09, 0xb6, 0x4f, 0xe9, 0x03, 0x5d, 0xfb, 0x42, 0x65,
-	0xf6, 0x1c, 0xef, 0x00, 0x4a, 0xd6, 0x47, 0xae, 0x8e, 0xff, 0x29, 0x43, 0xf5, 0x94, 0xf7, 0xe3,
-	0xee, 0xa5, 0x8a, 0x34, 0xa8, 0xc6, 0x2d, 0x19, 0xed, 0xaf, 0xfa, 0xdd, 0x53, 0x0e, 0x56, 0xf6,
-	0x73, 0xbc, 0x81, 0xde, 0x40, 0x45, 0x74, 0x4e, 0xb4, 0x9b, 0xcd, 0x4f, 0xf7, 0x64, 0x65, 0xef,
-	0xa3, 0xdf, 0x93, 0x34, 0xd1, 0x07, 0xf3, 0xb4, 0x74, 0xb7, 0xcd, 0xd3, 0x32, 0x0d, 0x14, 0x6f,
-	0xa0, 0x5f, 0xa0, 0xd8, 0xef, 0xf6, 0x90, 0x92, 0xcd, 0x9c, 0xf7, 0x50, 0xe5, 0xab, 0x85, 0xdf,
-	0x62, 0x42, 0x0f, 0x4a, 0x61, 0x0b, 0x42, 0xb9, 0xb4, 0x44, 0x8b, 0x54, 0x9e, 0x2f, 0xfe, 0x18,
-	0x43, 0x54, 0x28, 0x47, 0x17, 0x06, 0xbd, 0xc8, 0x66, 0xa6, 0x5a, 0x9a, 0xb2, 0xfb, 0xb1, 0xcf,
-	0x31, 0xea, 0x77, 0xa8, 0x27, 0x1f, 0x3b, 0x7a, 0x99, 0xad, 0x58, 0xd0, 0x96, 0x94, 0xaf, 0x97,
-	0x27, 0xc5, 0xf0, 0x5f, 0x01, 0xe6, 0x17, 0x1b, 0x1d, 0x2c, 0x36, 0x93, 0x04, 0xe3, 0x65, 0x29,
-	0x31, 0xd6, 0x84, 0x66, 0xe6, 0xd1, 0xa0, 0xd7, 0xd9, 0xc2, 0xc5, 0xef, 0x59, 0xf9, 0x66, 0x65,
-	0x5e, 0xd2, 0xfc, 0xfc, 0xfe, 0xe7, 0xcd, 0xe7, 0xde, 0x56, 0xde, 0x7c, 0xfe, 0xf9, 0xe0, 0x8d,
-	0x93, 0x83, 0xdf, 0xf6, 0xa2, 0x34, 0x3a, 0xed, 0x10, 0x87, 0x75, 0x46, 0x62, 0xeb, 0x4c, 0xf1,
-	0xb7, 0x74, 0x7a, 0x74, 0x5b, 0xe6, 0xff, 0x4a, 0x7f, 0xf8, 0x2f, 0x00, 0x00, 0xff, 0xff, 0x3e,
-	0xc2, 0x47, 0xa4, 0xb8, 0x0a, 0x00, 0x00,
+	// 2357 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x59, 0x5b, 0x73, 0xdb, 0xc6,
+	0x15, 0x36, 0x48, 0x91, 0x12, 0x0f, 0x75, 0xa1, 0x36, 0xb6, 0x44, 0x43, 0x8e, 0x2e, 0x48, 0xe4,
+	0x28, 0xa9, 0x23, 0x57, 0xcc, 0xa4, 0x6d, 0x3a, 0x19, 0x4f, 0x69, 0x12, 0x51, 0x58, 0xeb, 0x66,
+	0x90, 0x76, 0xe3, 0x76, 0xa6, 0xec, 0x8a, 0x58, 0x91, 0xa8, 0x48, 0x80, 0x5e, 0x2c, 0x69, 0x33,
+	0x3f, 0xa1, 0xd3, 0x99, 0xf6, 0xb5, 0xff, 0x21, 0x33, 0x7d, 0xec, 0x73, 0x1f, 0xfa, 0x3f, 0xfa,
+	0xda, 0xbf, 0xd0, 0xa7, 0xce, 0x2e, 0x16, 0x17, 0x02, 0xbc, 0xd9, 0xe9, 0x1b, 0xce, 0x65, 0xbf,
+	0x73, 0xf6, 0x9c, 0xdd, 0xb3, 0x7b, 0x16, 0xb0, 0x87, 0xbf, 0x77, 0x9c, 0xc7, 0x26, 0xc5, 0x6d,
+	0xc7, 0x7e, 0x3c, 0x3c, 0x91, 0x5f, 0x4d, 0xdc, 0xb7, 0x8e, 0xfb, 0xd4, 0x61, 0x0e, 0x5a, 0xe7,
+	0x0a, 0xc7, 0x1e, 0xfb, 0x78, 0x78, 0xa2, 0xfd, 0x43, 0x81, 0x42, 0x85, 0x12, 0xcc, 0xc8, 0x33,
+	0x32, 0x32, 0xc8, 0xeb, 0x01, 0x71, 0x19, 0xba, 0x0f, 0x2b, 0xb7, 0x64, 0xd4, 0xa4, 0x96, 0xdd,
+	0x2e, 0x2a, 0xfb, 0xca, 0x51, 0xce, 0x58, 0xbe, 0x25, 0x23, 0xc3, 0xb2, 0xdb, 0xe8, 0x2b, 0x58,
+	0x62, 0xa3, 0x3e, 0x29, 0xa6, 0xf6, 0x95, 0xa3, 0xf5, 0xd2, 0xe1, 0xf1, 0x38, 0xdc, 0x71, 0x1c,
+	0xea, 0xb8, 0x31, 0xea, 0x13, 0x43, 0x0c, 0xd1, 0xce, 0x61, 0x89, 0x53, 0xa8, 0x00, 0xab, 0x8d,
+	0x57, 0x57, 0x7a, 0xb3, 0x76, 0xf1, 0xb2, 0x7c, 0x56, 0xab, 0x16, 0xee, 0xa0, 0x0f, 0x60, 0x43,
+	0x70, 0xf4, 0x8b, 0x8a, 0xf1, 0xea, 0xaa, 0x51, 0xbb, 0xbc, 0x28, 0x28, 0x81, 0x5a, 0xbd, 0x76,
+	0x7a, 0x51, 0xbb, 0x38, 0x2d, 0xa4, 0xd0, 0x2a, 0xac, 0x08, 0xce, 0x79, 0xb9, 0x52, 0x48, 0x6b,
+	0xff, 0x4a, 0xc1, 0x66, 0xc4, 0x9c, 0xdb, 0x77, 0x6c, 0x97, 0xa0, 0x97, 0xb0, 0x4e, 0xec, 0x16,
+	0x1d, 0xf5, 0x99, 0xe5, 0xd8, 0xcd, 0x5b, 0x32, 0x12, 0x13, 0xc8, 0x97, 0x1e, 0xcf, 0xf0, 0xd4,
+	0x1b, 0x7a, 0xac, 0x07, 0xe3, 0x38, 0x77, 0x8d, 0x44, 0x49, 0x74, 0x0e, 0x79, 0xd7, 0x6a, 0xdb,
+	0x96, 0xdd, 0x16, 0xa0, 0x29, 0x01, 0xfa, 0x68, 0x3e, 0x68, 0xdd, 0x1b, 0xc4, 0x59, 0xe0, 0x06,
+	0xdf, 0xa8, 0x0c, 0xcb, 0x3d, 0xdc, 0x12, 0x50, 0x69, 0x01, 0x75, 0x34, 0x1f, 0xea, 0xbc, 0x5c,
+	0xe1, 0x64, 0xb6, 0x87, 0x5b, 0xcf, 0xc8, 0x48, 0xdd, 0x80, 0xb5, 0x31, 0x8f, 0xd5, 0x9f, 0x00,
+	0x84, 0xd6, 0xd0, 0x87, 0x00, 0xfd, 0xc1, 0x75, 0xd7, 0x6a, 0x05, 0x41, 0x58, 0x35, 0x72, 0x1e,
+	0x87, 0x2b, 0xaf, 0x40, 0xd6, 0xc3, 0xd3, 0x7e, 0x03, 0xeb, 0x12, 0x67, 0x81, 0xf4, 0x23, 0x58,
+	0x32, 0x31, 0xc3, 0x62, 0xfe, 0xab, 0x86, 0xf8, 0x46, 0x5b, 0x90, 0xbd, 0x71, 0x1c, 0x46, 0xa8,
+	0x98, 0xca, 0xaa, 0x21, 0x29, 0xed, 0x04, 0x36, 0x02, 0x60, 0x99, 0x9d, 0x5d, 0x80, 0x96, 0xd5,
+	0xef, 0x10, 0xca, 0xc8, 0x5b, 0x26, 0xb1, 0x23, 0x1c, 0xed, 0x19, 0xac, 0x57, 0xc9, 0xa2, 0xbe,
+	0x8c, 0x83, 0xa5, 0x12, 0x60, 0x87, 0xb0, 0x51, 0x25, 0xe3, 0xf6, 0x7d, 0xf7, 0x95, 0xd0, 0x7d,
+	0xed, 0x0b, 0xd8, 0x3a, 0x25, 0x36, 0xa1, 0x98, 0x91, 0x2a, 0x66, 0x78, 0xa1, 0x6d, 0xa0, 0x7d,
+	0x07, 0xdb, 0x89, 0x41, 0xd2, 0xc6, 0x03, 0xc8, 0xf5, 0xbb, 0xd8, 0xb2, 0x83, 0x29, 0xae, 0x1a,
+	0x21, 0x03, 0xed, 0x41, 0xfe, 0x0d, 0xc5, 0xfd, 0x3e, 0x31, 0x83, 0x75, 0x94, 0x33, 0x40, 0xb2,
+	0x78, 0x3a, 0xea, 0x70, 0x4f, 0x7a, 0xbd, 0xb0, 0x37, 0xf3, 0x41, 0x7f, 0x06, 0x5b, 0x71, 0xd0,
+	0x45, 0xbc, 0xd5, 0xbe, 0x86, 0x42, 0x95, 0x50, 0x6b, 0x18, 0x2d, 0x0e, 0x77, 0x21, 0x63, 0xd9,
+	0xfd, 0x81, 0xaf, 0xed, 0x11, 0x3c, 0xb2, 0xae, 0xf5, 0xbd, 0x57, 0x17, 0x32, 0x86, 0xf8, 0xd6,
+	0x0e, 0x61, 0x33, 0x32, 0x5a, 0x1a, 0x2c, 0x40, 0x3a, 0x5c, 0x90, 0xfc, 0x53, 0x2b, 0x03, 0x9c,
+	0x97, 0x2b, 0x0b, 0x4c, 0xb3, 0x08, 0xcb, 0x3d, 0xe2, 0xba, 0xb8, 0x4d, 0xe4, 0xfa, 0xf3, 0x49,
+	0x6d, 0x0f, 0xf2, 0x02, 0x22, 0xb4, 0xc1, 0xb0, 0x3f, 0x9c, 0x7f, 0x6a, 0x4f, 0x21, 0xcf, 0xf7,
+	0xc6, 0x8f, 0x32, 0xf2, 0x1c, 0x56, 0x3d, 0x8c, 0x30, 0x74, 0x7c, 0x47, 0x63, 0x36, 0xa0, 0x44,
+	0xa2, 0x84, 0x0c, 0xf4, 0x11, 0xac, 0x51, 0xfc, 0xa6, 0x19, 0x6a, 0x78, 0x68, 0xab, 0x14, 0xbf,
+	0xa9, 0xfb, 0x3c, 0xed, 0x1a, 0xd6, 0x5e, 0x12, 0x6a, 0xdd, 0x8c, 0x7e, 0x8c, 0x63, 0xe3, 0x8e,
+	0xa4, 0x63, 0x8e, 0x68, 0x0f, 0x61, 0xdd, 0xb7, 0x21, 0x1d, 0xbf, 0x0b, 0x99, 0x21, 0xee, 0x5a,
+	0xa6, 0xb0, 0xb0, 0x62, 0x78, 0x84, 0xd6, 0x81, 0x0d, 0x4f, 0xef, 0xea, 0x99, 0xef, 0xcd, 0xec,
+	0x1a, 0xf2, 0xde, 0x1e, 0x1d, 0x41, 0x21, 0xb4, 0x34, 0xd3, 0xa7, 0x3f, 0x29, 0xf0, 0x81, 0xbf,
+	0xcf, 0x1a, 0x97, 0x8d, 0xab, 0x05, 0xc2, 0xb4, 0x05, 0x59, 0xcb, 0x75, 0x07, 0x84, 0xca, 0x6d,
+	0x20, 0x29, 0x74, 0x00, 0xab, 0xb8, 0xd5, 0x72, 0x06, 0x36, 0x6b, 0xda, 0xb8, 0xe7, 0x7b, 0x95,
+	0x97, 0xbc, 0x0b, 0xdc, 0x23, 0x7c, 0xba, 0xbe, 0x8a, 0x65, 0x16, 0x97, 0x3c, 0xb7, 0x25, 0xa7,
+	0x66, 0x6a, 0xcf, 0xe1, 0xee, 0xb8, 0x2f, 0xd2, 0xf5, 0x75, 0x48, 0x49, 0xbf, 0x73, 0x46, 0xca,
+	0x32, 0xf9, 0xea, 0x1b, 0x50, 0x4b, 0x9a, 0xe7, 0x9f, 0x68, 0x1b, 0x96, 0x5f, 0xd3, 0x66, 0xcb,
+	0x31, 0x7d, 0xb3, 0xd9, 0xd7, 0xb4, 0xe2, 0x98, 0x44, 0x7b, 0x0d, 0x9b, 0x5e, 0x24, 0x16, 0x9c,
+	0x9c, 0x67, 0x2a, 0x15, 0x98, 0x1a, 0xf7, 0x38, 0x1d, 0xf3, 0x98, 0x6f, 0x4a, 0x61, 0xd4, 0x9b,
+	0x8a, 0xf8, 0xd6, 0x30, 0xa0, 0xa8, 0xc9, 0x59, 0xe1, 0x47, 0x5f, 0xc2, 0x72, 0xd7, 0x69, 0xdd,
+	0x3a, 0x03, 0x26, 0x0f, 0xbc, 0x9d, 0xf8, 0x29, 0xc5, 0x41, 0xce, 0x3c, 0x15, 0xc3, 0xd7, 0xd5,
+	0xde, 0xc2, 0xd6, 0x53, 0xcc, 0x5a, 0x9d, 0x77, 0x9a, 0x5a, 0x01, 0xd2, 0x96, 0xe9, 0x16, 0x53,
+	0xfb, 0x69, 0x1e, 0x35, 0xcb, 0x74, 0xdf, 0x67, 0x72, 0x7f, 0x55, 0x60, 0x3b, 0x61, 0x7a, 0xe6,
+	0x14, 0x8f, 0xa0, 0x20, 0x3e, 0x9a, 0xac, 0x43, 0x9d, 0x41, 0xbb, 0xd3, 0x0c, 0xe2, 0xbb, 0x2e,
+	0xf8, 0x0d, 0x8f, 0x5d, 0x1b, 0x0b, 0x46, 0xfa, 0x1d, 0x82, 0xf1, 0x84, 0x17, 0xc1, 0x2e, 0x61,
+	0xe4, 0xfd, 0x52, 0xac, 0xdd, 0x05, 0x14, 0x1d, 0xef, 0x4d, 0x46, 0xfb, 0x8b, 0x02, 0xf9, 0x88,
+	0x39, 0xbe, 0xea, 0xb9, 0x41, 0xe2, 0xcf, 0x4e, 0x52, 0x48, 0x85, 0x95, 0x1b, 0x6c, 0x75, 0x07,
+	0x94, 0xb8, 0xb2, 0x34, 0x07, 0x34, 0xfa, 0x1c, 0x10, 0x25, 0x3d, 0x6c, 0x89, 0x4b, 0x0d, 0x66,
+	0x8c, 0xf4, 0xfa, 0xcc, 0x15, 0x73, 0xcb, 0x18, 0x9b, 0x81, 0xa4, 0x2c, 0x05, 0x3c, 0x1d, 0x6f,
+	0x2c, 0xdb, 0x74, 0xde, 0x34, 0x89, 0xed, 0xed, 0x8e, 0xb4, 0x91, 0xf3, 0x38, 0xba, 0xcd, 0x77,
+	0xc7, 0xbd, 0x53, 0xc2, 0xa2, 0x21, 0x98, 0x3f, 0xd7, 0xf1, 0x0c, 0xa7, 0xe2, 0x1b, 0xee, 0x12,
+	0xb6, 0xe2, 0x90, 0x32, 0x97, 0x91, 0x5c, 0x28, 0xef, 0x90, 0x8b, 0x3a, 0x6c, 0x1b, 0xc4, 0xfd,
+	0x3f, 0x7b, 0xa9, 0x42, 0x31, 0x09, 0x2a, 0xd3, 0xf4, 0x83, 0x02, 0x9b, 0xcf, 0xc8, 0xe8, 0xca,
+	0x71, 0xba, 0x15, 0xdc, 0xea, 0x90, 0x3a, 0xc3, 0xcc, 0xe5, 0x75, 0x73, 0x48, 0xa8, 0x6b, 0x39,
+	0xb6, 0x6f, 0x4a, 0x92, 0x5c, 0xd2, 0xc2, 0xad, 0x0e, 0x77, 0x22, 0x25, 0xf2, 0xe8, 0x93, 0x7c,
+	0xb5, 0x77, 0x2c, 0x99, 0x9e, 0x25, 0x43, 0x7c, 0xf3, 0xa4, 0xf7, 0x2c, 0xd7, 0x25, 0xae, 0xc8,
+	0xc6, 0x92, 0x21, 0x29, 0xf4, 0x73, 0xc8, 0xba, 0x1d, 0x4c, 0x4d, 0xb7, 0x98, 0xd9, 0x4f, 0x1f,
+	0xe5, 0x4b, 0x7b, 0x89, 0xbb, 0xa5, 0xf0, 0x85, 0xab, 0x08, 0x87, 0x0c, 0xa9, 0xae, 0xfd, 0x5d,
+	0x81, 0x8d, 0x98, 0x2c, 0x38, 0xd8, 0x95, 0xf0, 0x60, 0xe7, 0x86, 0x19, 0xa6, 0x6d, 0xc2, 0xe4,
+	0x9a, 0x92, 0x14, 0x5f, 0xbb, 0xec, 0x44, 0xae, 0xa0, 0x14, 0x3b, 0x11, 0x74, 0xa9, 0xb8, 0x24,
+	0xe9, 0x12, 0xa7, 0xaf, 0x4f, 0x8a, 0x19, 0x8f, 0xbe, 0x16, 0xf2, 0xeb, 0x52, 0x31, 0x2b, 0xe9,
+	0x12, 0xaf, 0x93, 0xd7, 0x27, 0x4d, 0x31, 0xcf, 0x65, 0x6f, 0x46, 0xd7, 0x27, 0xdf, 0xf2, 0x99,
+	0x72, 0x41, 0xc9, 0x13, 0xac, 0x48, 0x41, 0x89, 0x0b, 0xb4, 0x2d, 0x5e, 0x93, 0x59, 0x18, 0x5b,
+	0x99, 0x4e, 0x8d, 0xc1, 0xbd, 0x18, 0x5f, 0xae, 0x9c, 0x27, 0x90, 0xe3, 0x79, 0xee, 0x3b, 0x4e,
+	0xd7, 0x2d, 0x2a, 0x22, 0x3c, 0x07, 0xf1, 0xf0, 0x24, 0x32, 0x66, 0xac, 0xdc, 0x7a, 0x2c, 0x17,
+	0xed, 0x40, 0xee, 0xd6, 0xbc, 0x69, 0xb6, 0x70, 0xb7, 0xeb, 0xed, 0xa8, 0x25, 0x63, 0xe5, 0xd6,
+	0xbc, 0xa9, 0x70, 0x5a, 0xfb, 0x12, 0x8a, 0x35, 0x5b, 0x94, 0x0d, 0x79, 0x75, 0xb7, 0xec, 0xf6,
+	0x02, 0x97, 0xc9, 0x36, 0xdc, 0x9f, 0x30, 0x4c, 0x3a, 0x5c, 0x84, 0x65, 0x4a, 0x7a, 0xce, 0x50,
+	0x6e, 0xed, 0x8c, 0xe1, 0x93, 0xe8, 0xa7, 0x70, 0xd7, 0x47, 0x6c, 0xde, 0x58, 0x76, 0x9b, 0xd0,
+	0x3e, 0xb5, 0x6c, 0xff, 0x26, 0x8c, 0x24, 0xfa, 0x37, 0xa1, 0x44, 0xbb, 0xcf, 0x6f, 0xad, 0x4c,
+	0x4e, 0xef, 0x5b, 0x82, 0xbb, 0xac, 0xe3, 0x07, 0xec, 0xd7, 0x50, 0x4c, 0x8a, 0x42, 0x17, 0x3a,
+	0x82, 0x33, 0x92, 0xd5, 0xc5, 0x27, 0x79, 0x4d, 0x25, 0x94, 0x3a, 0xfe, 0x59, 0xeb, 0x11, 0xda,
+	0x2e, 0x3c, 0x38, 0x25, 0xcc, 0x70, 0x1c, 0x8e, 0x27, 0x8f, 0x4c, 0xcb, 0xb1, 0x83, 0xe4, 0xfc,
+	0x53, 0x81, 0x0f, 0xa7, 0x28, 0x48, 0x8b, 0x7f, 0x80, 0x7c, 0x3b, 0x64, 0xcb, 0x3c, 0x3d, 0x89,
+	0xe7, 0x69, 0x26, 0xc6, 0x71, 0x84, 0xa7, 0xdb, 0x8c, 0x8e, 0x8c, 0x28, 0xa4, 0xfa, 0x04, 0x0a,
+	0x71, 0x85, 0xe8, 0xd5, 0x34, 0x27, 0xae, 0xa6, 0xf2, 0xcc, 0x18, 0x10, 0x99, 0x69, 0x8f, 0xf8,
+	0x65, 0xea, 0x17, 0x8a, 0xb6, 0x03, 0xf7, 0x4f, 0x09, 0x2b, 0x0f, 0x4c, 0x8b, 0xd5, 0x2d, 0xfb,
+	0x96, 0x2f, 0x93, 0x41, 0x30, 0xc1, 0x3e, 0xa8, 0x93, 0x84, 0x91, 0x26, 0xc8, 0xb1, 0x6f, 0xac,
+	0xf6, 0x80, 0x06, 0xf5, 0x3a, 0xc2, 0xe1, 0x97, 0x27, 0x3c, 0xc4, 0x56, 0x17, 0x5f, 0x77, 0x89,
+	0x2c, 0x03, 0x21, 0x23, 0x0c, 0x79, 0x3a, 0x1a, 0xf2, 0xa2, 0x28, 0x95, 0xd5, 0x01, 0xee, 0x1a,
+	0x03, 0x7b, 0x6c, 0x27, 0xfc, 0xa0, 0xc0, 0x76, 0x42, 0x14, 0x26, 0x96, 0xd8, 0x1c, 0xd5, 0x77,
+	0xc3, 0x27, 0xa3, 0x25, 0x2a, 0x35, 0x5e, 0xa2, 0xf6, 0x20, 0xef, 0xe2, 0x5e, 0xbf, 0x4b, 0x9a,
+	0x14, 0x33, 0xef, 0x3e, 0xa3, 0x18, 0xe0, 0xb1, 0x0c, 0xcc, 0x08, 0xfa, 0x9a, 0xdf, 0x0a, 0x59,
+	0xc7, 0x31, 0x79, 0x59, 0xe2, 0x79, 0xd3, 0xe2, 0x79, 0x93, 0xbe, 0x9c, 0x0b, 0x2d, 0xcf, 0x23,
+	0x7f, 0x88, 0xf6, 0x6f, 0x05, 0x50, 0x52, 0x2e, 0x4a, 0x9d, 0x20, 0x65, 0x76, 0x24, 0xc5, 0xfd,
+	0xf4, 0x4c, 0xfb, 0x9b, 0xd1, 0x27, 0xd1, 0x3e, 0xe4, 0x4d, 0x6b, 0x48, 0x68, 0x9b, 0xd8, 0x2d,
+	0xe2, 0xd7, 0xcd, 0x28, 0x4b, 0x8c, 0xbd, 0xb5, 0x78, 0x8b, 0x24, 0xeb, 0xa7, 0x4f, 0xa2, 0x63,
+	0xf8, 0xa0, 0x4f, 0xad, 0x1e, 0xa6, 0xa3, 0xa6, 0x39, 0xf0, 0x96, 0x48, 0xb3, 0xe7, 0x8a, 0xc2,
+	0xa5, 0x18, 0x9b, 0x52, 0x54, 0x95, 0x92, 0x73, 0x17, 0x3d, 0x02, 0xe4, 0x76, 0x30, 0x3f, 0x1a,
+	0xa3, 0xea, 0x59, 0xa1, 0x5e, 0xf0, 0x24, 0xa1, 0xb6, 0x76, 0x0a, 0x45, 0xfd, 0x6d, 0xdf, 0xa1,
+	0xde, 0x02, 0xd1, 0x87, 0xc4, 0x0e, 0xb2, 0xc5, 0xb3, 0x8b, 0x6f, 0x78, 0x2b, 0xad, 0x78, 0x0b,
+	0x4e, 0x10, 0xe8, 0x1e, 0x3f, 0xdd, 0xdb, 0xe1, 0xe9, 0x93, 0xe9, 0x3a, 0xed, 0x9a, 0xa9, 0xfd,
+	0x39, 0x05, 0x10, 0x62, 0x44, 0xb4, 0x94, 0x88, 0x16, 0xbf, 0x02, 0xb8, 0x1c, 0xdd, 0x6e, 0xf9,
+	0xcb, 0x38, 0xa0, 0x79, 0x71, 0x67, 0x96, 0xbc, 0x0c, 0xa7, 0x0d, 0xf1, 0x1d, 0x09, 0xf5, 0xd2,
+	0x58, 0xa8, 0xa7, 0x95, 0x9b, 0xcc, 0xb4, 0x72, 0xc3, 0x91, 0x78, 0x9d, 0x24, 0x54, 0x84, 0x22,
+	0x67, 0x48, 0x0a, 0x1d, 0xc2, 0x7a, 0xcb, 0xa1, 0x94, 0x74, 0xbd, 0x50, 0x59, 0xa6, 0xa8, 0xf6,
+	0x39, 0x63, 0x2d, 0xc2, 0xad, 0x99, 0x7c, 0x38, 0x25, 0xee, 0xa0, 0xcb, 0x44, 0xcd, 0xcf, 0x19,
+	0x92, 0xf2, 0xf8, 0xd8, 0x75, 0xec, 0x62, 0xce, 0xe7, 0x73, 0x4a, 0xfb, 0x8f, 0x02, 0x19, 0x9d,
+	0xef, 0x86, 0xe0, 0x6a, 0xa8, 0x84, 0x57, 0xc3, 0x78, 0xb3, 0x92, 0x0b, 0x9b, 0x95, 0x10, 0x2f,
+	0x1d, 0xc5, 0xe3, 0x81, 0xc3, 0xb4, 0x3d, 0xe8, 0x11, 0x9b, 0xc9, 0x50, 0x04, 0x34, 0xfa, 0x0a,
+	0x56, 0x5a, 0x98, 0x91, 0xb6, 0x43, 0x47, 0x22, 0x00, 0xeb, 0xa5, 0x0f, 0xe3, 0xab, 0x5c, 0xb8,
+	0x52, 0x91, 0x4a, 0x46, 0xa0, 0xce, 0xb7, 0x37, 0x25, 0x8c, 0x8e, 0xc4, 0xf6, 0xce, 0x7a, 0xdb,
+	0x3b, 0x60, 0x2c, 0x18, 0x9b, 0xcf, 0xfe, 0x96, 0x82, 0xb5, 0x31, 0x03, 0x68, 0x17, 0x54, 0xdd,
+	0x30, 0x2e, 0x8d, 0x66, 0xa5, 0xdc, 0xd0, 0x4f, 0x2f, 0x8d, 0x57, 0xcd, 0x17, 0x17, 0xf5, 0x2b,
+	0xbd, 0x52, 0xfb, 0xa6, 0xa6, 0xf3, 0x37, 0x36, 0x0d, 0x76, 0x63, 0x72, 0xf9, 0xfe, 0xd6, 0x34,
+	0xf4, 0xe7, 0x2f, 0xf4, 0x7a, 0xa3, 0xa0, 0xa0, 0x3d, 0xd8, 0x99, 0xa2, 0x53, 0x2d, 0x37, 0xca,
+	0x85, 0x14, 0xda, 0x87, 0x07, 0x31, 0x85, 0x72, 0xa5, 0xa2, 0xd7, 0xeb, 0xcd, 0xaa, 0x7e, 0xc1,
+	0xcd, 0xa4, 0x27, 0xba, 0x51, 0x7e, 0x59, 0xae, 0x9d, 0x95, 0x9f, 0x9e, 0xe9, 0x85, 0x25, 0xf4,
+	0x31, 0xec, 0xc7, 0xe4, 0x55, 0xbd, 0x5c, 0x3d, 0xab, 0x5d, 0xe8, 0x4d, 0xfd, 0xbb, 0x8a, 0xae,
+	0x57, 0xf5, 0x6a, 0x21, 0x33, 0x79, 0x32, 0x2f, 0xae, 0xae, 0x2e, 0x8d, 0x86, 0x5e, 0x2d, 0x64,
+	0xd1, 0x0e, 0x6c, 0x27, 0x1c, 0x6d, 0xe8, 0xc6, 0x45, 0xf9, 0xac, 0xb0, 0x5c, 0xfa, 0xef, 0x06,
+	0xe4, 0xaa, 0x22, 0x0d, 0xe5, 0xab, 0x1a, 0x32, 0x20, 0x17, 0x3c, 0xa5, 0xa1, 0xfd, 0x79, 0xef,
+	0x95, 0xea, 0xc1, 0xdc, 0x77, 0x38, 0xed, 0x0e, 0x3a, 0x83, 0x65, 0xf9, 0xb2, 0x85, 0x76, 0x13,
+	0x69, 0x1f, 0x7b, 0x4b, 0x53, 0xf7, 0xa6, 0xca, 0xa3, 0x68, 0x55, 0x32, 0x05, 0xad, 0x4a, 0x66,
+	0xa3, 0xc5, 0x1e, 0xb8, 0xb4, 0x3b, 0xc8, 0x84, 0x8d, 0xd8, 0xcb, 0x14, 0x7a, 0x98, 0x3c, 0x38,
+	0x27, 0xbd, 0x77, 0xa9, 0x9f, 0xcc, 0xd5, 0x0b, 0xac, 0xe0, 0xe0, 0xa1, 0xce, 0x37, 0x72, 0x38,
+	0xc5, 0xb5, 0x98, 0x8d, 0x87, 0xf3, 0xd4, 0x02, 0x13, 0x06, 0xe4, 0x82, 0xd7, 0xa3, 0x64, 0xe2,
+	0xe2, 0xcf, 0x52, 0xea, 0xc1, 0x0c, 0x8d, 0x00, 0xf3, 0x57, 0x90, 0x3e, 0x2f, 0x57, 0x90, 0x1a,
+	0xd7, 0x0d, 0xdf, 0x9f, 0xd4, 0x9d, 0x89, 0xb2, 0x00, 0xa1, 0x02, 0x4b, 0xfc, 0xf9, 0x06, 0x25,
+	0xd4, 0x22, 0xcf, 0x4b, 0xea, 0x83, 0xc9, 0xc2, 0x00, 0xa4, 0x06, 0x59, 0xaf, 0x41, 0x45, 0x89,
+	0xaa, 0x31, 0xf6, 0x1c, 0xa4, 0xee, 0x4e, 0x13, 0x07, 0x50, 0x97, 0xb0, 0xe2, 0xbf, 0xa5, 0xa0,
+	0xbd, 0xc9, 0xda, 0xc1, 0x7b, 0x8e, 0xba, 0x3f, 0x5d, 0x21, 0x00, 0xfc, 0x1d, 0xac, 0x46, 0x5f,
+	0x39, 0xd0, 0x47, 0xd3, 0x16, 0x45, 0xa4, 0x9f, 0x55, 0x3f, 0x9e, 0xad, 0x14, 0x80, 0xbf, 0x00,
+	0x08, 0x3b, 0x73, 0x74, 0x30, 0xd9, 0x9d, 0x28, 0xb0, 0x36, 0x4b, 0x25, 0xba, 0xe6, 0x63, 0x5d,
+	0x7f, 0x72, 0xcd, 0x4f, 0x7e, 0x91, 0x50, 0x3f, 0x99, 0xab, 0x17, 0x75, 0x3e, 0xec, 0xc4, 0xd1,
+	0x84, 0xf5, 0x16, 0xeb, 0xf2, 0x55, 0x6d, 0x96, 0x4a, 0x74, 0x2b, 0x8d, 0x77, 0xb9, 0xc9, 0xad,
+	0x34, 0xb1, 0xb1, 0x56, 0x1f, 0xce, 0x53, 0x0b, 0x4c, 0xb4, 0xa1, 0x10, 0x6f, 0x51, 0x51, 0x62,
+	0xe2, 0x53, 0x3a, 0x63, 0xf5, 0x68, 0xbe, 0x62, 0x60, 0xe8, 0xf7, 0xb0, 0x36, 0xd6, 0x76, 0xa1,
+	0x09, 0x0b, 0x23, 0xd9, 0xad, 0xa9, 0x87, 0x73, 0xb4, 0x02, 0xfc, 0x3f, 0xc2, 0x66, 0xa2, 0x53,
+	0x42, 0x09, 0x07, 0xa7, 0xf5, 0x60, 0xea, 0xa7, 0x0b, 0x68, 0x46, 0x83, 0x16, 0xef, 0x88, 0xd0,
+	0x84, 0x0a, 0x39, 0xb1, 0x9d, 0x52, 0x8f, 0xe6, 0x2b, 0x06, 0x86, 0x86, 0x70, 0x6f, 0x62, 0x27,
+	0x83, 0x1e, 0x2d, 0xd8, 0xf0, 0x78, 0x26, 0x3f, 0x7f, 0xa7, 0xf6, 0x48, 0xbb, 0x83, 0x7a, 0x80,
+	0x92, 0x5d, 0x0a, 0xfa, 0x74, 0x02, 0xcc, 0xe4, 0x36, 0x47, 0xfd, 0x6c, 0x11, 0xd5, 0xf1, 0x83,
+	0x69, 0xac, 0x0f, 0x99, 0x74, 0x30, 0x4d, 0xea, 0x61, 0xd4, 0x4f, 0xe6, 0xea, 0xf9, 0x56, 0x9e,
+	0x1e, 0xfc, 0x76, 0xcf, 0xd3, 0x25, 0xc3, 0xc7, 0xb8, 0x6f, 0x3d, 0x96, 0x3d, 0x1f, 0x31, 0xe5,
+	0xaf, 0xd0, 0xe1, 0xc9, 0x75, 0x56, 0xfc, 0x09, 0xfd, 0xe2, 0x7f, 0x03, 0x00, 0xa6, 0x5b, 0x57,
+	0xc0, 0x2c, 0x1d, 0x00, 0x00,
 }
@@ -4,6 +4,6 @@ go 1.16
 
 require (
 	github.com/pkg/errors v0.9.1 // indirect
-	github.com/twitchtv/twirp v8.1.0+incompatible
+	github.com/twitchtv/twirp v8.1.3+incompatible
 	google.golang.org/protobuf v1.27.1
 )
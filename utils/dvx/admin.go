@@ -0,0 +1,128 @@
+package dvx
+
+import (
+	"sort"
+)
+
+// KeyPoolStatus describes the KeyPool of a version for operators.
+type KeyPoolStatus struct {
+	// Version is the major dvx version the KeyPool is registered for. For
+	// example: "dv2"
+	Version string `json:"version"`
+	// Caching reports whether the KeyPool caches keys (see CachingKeyPool).
+	Caching bool `json:"caching"`
+	// CacheHits and CacheMisses are the CacheStats of a caching KeyPool.
+	CacheHits   uint64 `json:"cache_hits"`
+	CacheMisses uint64 `json:"cache_misses"`
+	// Generation is the generation of the root key (see GenerationKeyPool),
+	// or 0 if it is unknown.
+	Generation uint64 `json:"generation"`
+	// Prefixes are the derivations per keyRing prefix of a
+	// PrefixStatsKeyPool.
+	Prefixes []PrefixStatus `json:"prefixes,omitempty"`
+	// Shards are the adaptive replacement caches per shard of an
+	// ARCStatsKeyPool, ordered by shard.
+	Shards []ShardStatus `json:"shards,omitempty"`
+}
+
+// PrefixStatus describes the derivations of keyRings with a prefix by a
+// PrefixStatsKeyPool.
+type PrefixStatus struct {
+	// Prefix is the keyRing prefix, or "" for all keyRings without a
+	// configured prefix. For example: "users/"
+	Prefix string `json:"prefix"`
+	// Bypass reports whether keys of the prefix bypass the cache.
+	Bypass bool `json:"bypass"`
+	// CacheHits and CacheMisses are the keys of the prefix served from the
+	// cache and derived on a cache miss.
+	CacheHits   uint64 `json:"cache_hits"`
+	CacheMisses uint64 `json:"cache_misses"`
+	// Direct is the amount of keys of the prefix derived without the cache.
+	Direct uint64 `json:"direct"`
+}
+
+// ShardStatus describes the adaptive replacement cache (ARC) of a shard of
+// an ARCStatsKeyPool. T1 holds the keys used once since they were derived, T2
+// the ones used at least twice. The ghost lists B1 and B2 remember which keys
+// were recently replaced from T1 and T2, without holding them, so keys
+// derived again after their replacement adapt Target.
+type ShardStatus struct {
+	// Size is the maximum amount of cached keys of the shard.
+	Size int `json:"size"`
+	// Target is the adaptive target size of T1.
+	Target int `json:"target"`
+	// T1, T2, B1 and B2 are the current sizes of the partitions.
+	T1 int `json:"t1"`
+	T2 int `json:"t2"`
+	B1 int `json:"b1"`
+	B2 int `json:"b2"`
+	// B1Hits and B2Hits are the amounts of keys derived again while they
+	// were in B1 and B2.
+	B1Hits uint64 `json:"b1_hits"`
+	B2Hits uint64 `json:"b2_hits"`
+}
+
+// KeyPools returns the status of every KeyPool of p, ordered by version.
+func (p *Protocol) KeyPools() []KeyPoolStatus {
+	pools := make([]KeyPoolStatus, 0, len(p.keys))
+	for version, pool := range p.keys {
+		status := KeyPoolStatus{Version: version}
+		if cp, ok := pool.(CachingKeyPool); ok {
+			status.Caching = true
+			status.CacheHits, status.CacheMisses = cp.CacheStats()
+		}
+		if gp, ok := pool.(GenerationKeyPool); ok {
+			status.Generation = gp.Generation()
+		}
+		if pp, ok := pool.(PrefixStatsKeyPool); ok {
+			pp.PrefixStats(func(prefix string, bypass bool, hits uint64, misses uint64, direct uint64) {
+				status.Prefixes = append(status.Prefixes, PrefixStatus{prefix, bypass, hits, misses, direct})
+			})
+		}
+		if ap, ok := pool.(ARCStatsKeyPool); ok {
+			ap.ARCStats(func(size, target, t1, t2, b1, b2 int, b1Hits, b2Hits uint64) {
+				status.Shards = append(status.Shards, ShardStatus{size, target, t1, t2, b1, b2, b1Hits, b2Hits})
+			})
+		}
+		pools = append(pools, status)
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].Version < pools[j].Version })
+	return pools
+}
+
+// Invalidate removes all cached keys of keyRing from the KeyPool instances of
+// p that implement InvalidatingKeyPool, for every version and purpose, and
+// returns the amount of removed keys. Later operations derive the keys again,
+// e.g. after the root key of the underlying KeyPool was rotated. Operations
+// running concurrently may cache the keys again.
+func (p *Protocol) Invalidate(keyRing string) int {
+	keyRingBytes := keyRingToBytes(keyRing)
+
+	removed := 0
+	for version, pool := range p.keys {
+		ip, ok := pool.(InvalidatingKeyPool)
+		if !ok {
+			continue
+		}
+
+		versions := []string{version}
+		if other := sharedKeyPoolVersion(version); other != "" {
+			if _, ok := p.keys[other]; !ok {
+				// the KeyPool also derives the keys of other (see keyPool)
+				versions = append(versions, other)
+			}
+		}
+		for _, v := range versions {
+			if v == "dv1" {
+				removed += ip.Invalidate(keyRingBytes)
+				continue
+			}
+			for _, purpose := range purposes {
+				// the label contains the kdf, so both have their own input
+				removed += ip.Invalidate(kdfInput(v, "kdf32", purpose, keyRingBytes))
+				removed += ip.Invalidate(kdfInput(v, "kdf64", purpose, keyRingBytes))
+			}
+		}
+	}
+	return removed
+}
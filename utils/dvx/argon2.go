@@ -0,0 +1,123 @@
+package dvx
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// MinSaltSize is the minimum length of salts accepted by KDF512, as
+// recommended for Argon2 by RFC 9106.
+const MinSaltSize = 16
+
+// Argon2Params are the cost parameters of the Argon2id derivation of KDF512.
+// They are stored next to outputs that must be derived again later (e.g. in
+// ciphers of EncryptWithPasswordParams), so they can be raised without
+// breaking existing outputs.
+type Argon2Params struct {
+	// Time is the amount of passes over the memory. For example: 1
+	Time uint32
+	// Memory is the amount of memory in KiB. For example: 64 * 1024
+	Memory uint32
+	// Threads is the degree of parallelism. For example: 4
+	Threads uint8
+}
+
+// DefaultArgon2Params are the parameters of KDF512 (t=1, m=64MiB, p=4), as
+// recommended by RFC 9106 for memory-constrained environments.
+var DefaultArgon2Params = Argon2Params{Time: 1, Memory: 64 * 1024, Threads: 4}
+
+const (
+	// argon2ParamsSize is the size of encoded Argon2Params
+	argon2ParamsSize = 4 + 4 + 1
+	// maxArgon2Time, maxArgon2Memory and maxArgon2Threads bound the
+	// parameters (8 passes over 256 MiB with 16 threads), so encoded
+	// parameters of a forged cipher passed to DecryptWithPassword can't make a
+	// derivation run for minutes, allocate gigabytes or start hundreds of
+	// goroutines. All are well above DefaultArgon2Params
+	maxArgon2Time    = 8
+	maxArgon2Memory  = 256 * 1024
+	maxArgon2Threads = 16
+)
+
+func (a Argon2Params) validate() error {
+	switch {
+	case a.Time < 1 || a.Time > maxArgon2Time:
+		return errorf(ErrInvalidKey, "dvx: argon2 time must be between 1 and %d", maxArgon2Time)
+	case a.Threads < 1 || a.Threads > maxArgon2Threads:
+		return errorf(ErrInvalidKey, "dvx: argon2 threads must be between 1 and %d", maxArgon2Threads)
+	case a.Memory < 8*uint32(a.Threads) || a.Memory > maxArgon2Memory:
+		return errorf(ErrInvalidKey, "dvx: argon2 memory must be between %d and %d KiB", 8*uint32(a.Threads), maxArgon2Memory)
+	}
+	return nil
+}
+
+// encode appends the parameters to dst as BE32(Time) || BE32(Memory) ||
+// Threads.
+func (a Argon2Params) encode(dst []byte) []byte {
+	var buf [argon2ParamsSize]byte
+	binary.BigEndian.PutUint32(buf[0:], a.Time)
+	binary.BigEndian.PutUint32(buf[4:], a.Memory)
+	buf[8] = a.Threads
+	return append(dst, buf[:]...)
+}
+
+// decodeArgon2Params decodes and validates parameters of encode.
+func decodeArgon2Params(buf []byte) (Argon2Params, error) {
+	if len(buf) < argon2ParamsSize {
+		return Argon2Params{}, errorf(ErrInvalidFormat, "dvx: argon2 parameters shorter (%d) than %d bytes", len(buf), argon2ParamsSize)
+	}
+	a := Argon2Params{
+		Time:    binary.BigEndian.Uint32(buf[0:]),
+		Memory:  binary.BigEndian.Uint32(buf[4:]),
+		Threads: buf[8],
+	}
+	if err := a.validate(); err != nil {
+		return Argon2Params{}, errorf(ErrInvalidFormat, "%v", err)
+	}
+	return a, nil
+}
+
+// KDF512WithParams is like KDF512, but with the cost parameters params.
+func (d DV1) KDF512WithParams(password []byte, salt []byte, params Argon2Params) (key []byte, err error) {
+	if len(salt) < MinSaltSize {
+		return nil, errorf(ErrInvalidKey, "dv1: salt must be at least %d bytes long", MinSaltSize)
+	}
+	if err = params.validate(); err != nil {
+		return nil, err
+	}
+	return argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, 64), nil
+}
+
+// CalibrateArgon2 returns parameters with memory KiB and threads, whose
+// derivation takes about target on this machine. It measures a derivation
+// with a single pass and scales Time linearly, with at least one and at most
+// 8 passes (the limit of DecryptWithPassword). The result depends on the load
+// of the machine, so it should be calibrated once (e.g. at deployment) and
+// stored, instead of on every start.
+func CalibrateArgon2(target time.Duration, memory uint32, threads uint8) (Argon2Params, error) {
+	params := Argon2Params{Time: 1, Memory: memory, Threads: threads}
+	if err := params.validate(); err != nil {
+		return Argon2Params{}, err
+	}
+
+	salt := make([]byte, MinSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return Argon2Params{}, errorf(ErrRandomness, "dvx: failed to read random %d bytes for salt: %v", MinSaltSize, err)
+	}
+	start := time.Now()
+	_ = argon2.IDKey([]byte("calibration"), salt, params.Time, params.Memory, params.Threads, 64)
+	pass := time.Since(start)
+
+	if pass > 0 && target > pass {
+		passes := uint32((target + pass/2) / pass)
+		if passes > maxArgon2Time {
+			passes = maxArgon2Time
+		}
+		params.Time = passes
+	}
+	return params, nil
+}
@@ -0,0 +1,186 @@
+package dvx
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"testing"
+)
+
+// benchSizes are the payload sizes of the primitive benchmarks, from single
+// tokens to large files.
+var benchSizes = []int{64, 1 << 10, 64 << 10, 1 << 20, 16 << 20}
+
+// benchPrimitives are the primitives compared by the benchmarks, so changes
+// of one version can be compared with the others.
+var benchPrimitives = []struct {
+	name      string
+	primitive Primitive
+}{
+	{"dv1", DV1{}},
+	{"dv2", DV2{}},
+}
+
+func benchSizeName(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%dMiB", size>>20)
+	case size >= 1<<10:
+		return fmt.Sprintf("%dKiB", size>>10)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+// benchRandom returns n random bytes.
+func benchRandom(b *testing.B, n int) []byte {
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, buf); err != nil {
+		b.Fatal(err)
+	}
+	return buf
+}
+
+// benchPayloads runs bench for every primitive and payload size, with the
+// throughput and allocations reported.
+func benchPayloads(b *testing.B, bench func(b *testing.B, primitive Primitive, payload []byte)) {
+	for _, p := range benchPrimitives {
+		for _, size := range benchSizes {
+			payload := benchRandom(b, size)
+			b.Run(p.name+"/"+benchSizeName(size), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				bench(b, p.primitive, payload)
+			})
+		}
+	}
+}
+
+func BenchmarkPrimitive_Encrypt(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		key := benchRandom(b, 32)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := primitive.Encrypt(key, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPrimitive_Decrypt(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		key := benchRandom(b, 32)
+		cipher, err := primitive.Encrypt(key, payload)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := primitive.Decrypt(key, cipher); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPrimitive_MAC256(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		key := benchRandom(b, 64)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := primitive.MAC256(key, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPrimitive_MAC512(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		key := benchRandom(b, 64)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := primitive.MAC512(key, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPrimitive_Sign(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := primitive.Sign(privateKey, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPrimitive_Verify(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			b.Fatal(err)
+		}
+		signature, err := primitive.Sign(privateKey, payload)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if valid, err := primitive.Verify(publicKey, payload, signature); err != nil || !valid {
+				b.Fatal("signature not valid", err)
+			}
+		}
+	})
+}
+
+// BenchmarkProtocol_Encrypt measures the overhead of Protocol on top of the
+// primitive: key derivation, encoding and statistics.
+func BenchmarkProtocol_Encrypt(b *testing.B) {
+	p := NewProtocol(map[string]KeyPool{Version: WrapDVXAsKeyPool(DV1{}, benchRandom(b, 64), nil)})
+	payload := benchRandom(b, 1<<10)
+
+	b.SetBytes(int64(len(payload)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Encrypt("keyring", payload); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkPrimitive_EncryptTo(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		key := benchRandom(b, 32)
+		dst := make([]byte, 0, CipherOverhead+len(payload))
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := primitive.(AppendPrimitive).EncryptTo(dst, key, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkPrimitive_MAC256To(b *testing.B) {
+	benchPayloads(b, func(b *testing.B, primitive Primitive, payload []byte) {
+		key := benchRandom(b, 64)
+		dst := make([]byte, 0, 32)
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := primitive.(AppendPrimitive).MAC256To(dst, key, payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,208 @@
+// Package bulk parallelizes independent dvx operations (e.g. the
+// re-encryption of a whole table) across all cores. Run reads jobs from a
+// channel, executes them with a pool of workers and writes the results to a
+// channel. The amount of jobs held in memory is bounded, so slow consumers
+// slow down the pipeline instead of letting it grow without limit.
+package bulk
+
+import (
+	"context"
+	"runtime"
+	"sync"
+
+	"azoo.dev/utils/dvx"
+)
+
+// Job is a single operation. Its ID and KeyRing are copied to the Result.
+type Job struct {
+	// ID identifies the job in the caller's storage. It is passed through
+	// unchanged.
+	ID string
+	// KeyRing is passed to the Op.
+	KeyRing string
+	// Data is the input of the Op, e.g. the plaintext for Encrypt or the
+	// token for Decrypt.
+	Data []byte
+}
+
+// Result is the outcome of a Job.
+type Result struct {
+	ID      string
+	KeyRing string
+	// Data is the output of the Op, e.g. the token for Encrypt or the
+	// plaintext for Decrypt.
+	Data []byte
+	// Err is the error of the Op. Failed jobs don't stop Run.
+	Err error
+}
+
+// Op executes a single Job. Ops are called concurrently by all workers, so
+// they must be safe for concurrent use.
+type Op func(ctx context.Context, keyRing string, data []byte) ([]byte, error)
+
+// Encrypt returns an Op that encrypts data to a dvx token.
+func Encrypt(c dvx.Crypto) Op {
+	return func(ctx context.Context, keyRing string, data []byte) ([]byte, error) {
+		token, err := c.EncryptContext(ctx, keyRing, data)
+		return []byte(token), err
+	}
+}
+
+// Decrypt returns an Op that decrypts a dvx token to its data.
+func Decrypt(c dvx.Crypto) Op {
+	return func(ctx context.Context, keyRing string, data []byte) ([]byte, error) {
+		return c.DecryptContext(ctx, keyRing, string(data))
+	}
+}
+
+// MAC returns an Op that computes the dvx tag of data.
+func MAC(c dvx.Crypto) Op {
+	return func(ctx context.Context, keyRing string, data []byte) ([]byte, error) {
+		tag, err := c.MACContext(ctx, keyRing, data)
+		return []byte(tag), err
+	}
+}
+
+// Rotate returns an Op that re-encrypts a dvx token from Protocol `from` to
+// Protocol `to`, like rotate.Token.
+func Rotate(from dvx.Crypto, to dvx.Crypto) Op {
+	return func(ctx context.Context, keyRing string, data []byte) ([]byte, error) {
+		plain, err := from.DecryptContext(ctx, keyRing, string(data))
+		if err != nil {
+			return nil, err
+		}
+		token, err := to.EncryptContext(ctx, keyRing, plain)
+		return []byte(token), err
+	}
+}
+
+// Config provides all options for Run.
+type Config struct {
+	// Workers is the amount of goroutines executing jobs. Defaults to
+	// runtime.GOMAXPROCS(0).
+	Workers int
+	// MaxInFlight is the maximum amount of jobs read from the input but whose
+	// results weren't received from the output yet. It bounds the memory of
+	// Run. Defaults to 4 times Workers.
+	MaxInFlight int
+	// Ordered emits the results in the order of their jobs. Otherwise results
+	// are emitted as soon as they are done, which keeps all workers busy even
+	// if single jobs are slow.
+	Ordered bool
+}
+
+func (c *Config) workers() int {
+	if c == nil || c.Workers <= 0 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return c.Workers
+}
+
+func (c *Config) maxInFlight() int {
+	if c == nil || c.MaxInFlight <= 0 {
+		return 4 * c.workers()
+	}
+	return c.MaxInFlight
+}
+
+type sequencedJob struct {
+	seq uint64
+	job Job
+}
+
+type sequencedResult struct {
+	seq    uint64
+	result Result
+}
+
+// Run executes op for every Job of jobs and writes a Result for every Job to
+// the returned channel. The channel is closed after jobs was closed and all
+// results were received. config may be nil.
+//
+// When ctx is done Run stops reading jobs, discards all pending results and
+// closes the returned channel, so callers must check ctx.Err() to tell a
+// canceled Run from a complete one. ctx is also passed to every Op.
+func Run(ctx context.Context, jobs <-chan Job, op Op, config *Config) <-chan Result {
+	out := make(chan Result)
+	go run(ctx, jobs, op, config, out)
+	return out
+}
+
+func run(ctx context.Context, jobs <-chan Job, op Op, config *Config, out chan<- Result) {
+	defer close(out)
+
+	maxInFlight := config.maxInFlight()
+	// every job takes a slot before it is read and releases it after its
+	// result was received
+	slots := make(chan struct{}, maxInFlight)
+	work := make(chan sequencedJob)
+	// done never blocks the workers, as at most maxInFlight jobs are pending
+	done := make(chan sequencedResult, maxInFlight)
+
+	var wg sync.WaitGroup
+	for i := 0; i < config.workers(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range work {
+				data, err := op(ctx, j.job.KeyRing, j.job.Data)
+				done <- sequencedResult{seq: j.seq, result: Result{ID: j.job.ID, KeyRing: j.job.KeyRing, Data: data, Err: err}}
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(work)
+			wg.Wait()
+			close(done)
+		}()
+
+		for seq := uint64(0); ; seq++ {
+			select {
+			case <-ctx.Done():
+				return
+			case slots <- struct{}{}:
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case job, ok := <-jobs:
+				if !ok {
+					return
+				}
+				work <- sequencedJob{seq: seq, job: job}
+			}
+		}
+	}()
+
+	emit := func(r Result) {
+		select {
+		case <-ctx.Done():
+		case out <- r:
+		}
+		<-slots
+	}
+
+	ordered := config != nil && config.Ordered
+	pending := make(map[uint64]Result)
+	var next uint64
+	for r := range done {
+		if !ordered {
+			emit(r.result)
+			continue
+		}
+
+		pending[r.seq] = r.result
+		for {
+			result, ok := pending[next]
+			if !ok {
+				break
+			}
+			delete(pending, next)
+			next++
+			emit(result)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package bulk
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+)
+
+func newProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, dvx.NopLogger)})
+}
+
+func feed(jobs ...Job) <-chan Job {
+	c := make(chan Job, len(jobs))
+	for _, j := range jobs {
+		c <- j
+	}
+	close(c)
+	return c
+}
+
+func TestRun_EncryptRotate(t *testing.T) {
+	from, to := newProtocol(t), newProtocol(t)
+	ctx := context.Background()
+
+	var jobs []Job
+	for i := 0; i < 100; i++ {
+		jobs = append(jobs, Job{ID: fmt.Sprint(i), KeyRing: "keyring", Data: []byte(fmt.Sprint("data-", i))})
+	}
+
+	var tokens []Job
+	for r := range Run(ctx, feed(jobs...), Encrypt(from), &Config{Workers: 4, Ordered: true}) {
+		require.NoError(t, r.Err)
+		assert.Equal(t, fmt.Sprint(len(tokens)), r.ID, "results are ordered")
+		tokens = append(tokens, Job{ID: r.ID, KeyRing: r.KeyRing, Data: r.Data})
+	}
+	require.Len(t, tokens, 100)
+
+	rotated := 0
+	for r := range Run(ctx, feed(tokens...), Rotate(from, to), nil) {
+		require.NoError(t, r.Err)
+		data, err := to.Decrypt(r.KeyRing, string(r.Data))
+		require.NoError(t, err)
+		assert.Equal(t, "data-"+r.ID, string(data))
+		rotated++
+	}
+	assert.Equal(t, 100, rotated)
+}
+
+func TestRun_Errors(t *testing.T) {
+	p := newProtocol(t)
+
+	var results []Result
+	for r := range Run(context.Background(), feed(Job{ID: "a", KeyRing: "keyring", Data: []byte("invalid")}), Decrypt(p), nil) {
+		results = append(results, r)
+	}
+	require.Len(t, results, 1)
+	assert.Equal(t, "a", results[0].ID)
+	assert.Error(t, results[0].Err)
+}
+
+func TestRun_MaxInFlight(t *testing.T) {
+	var read int32
+	jobs := make(chan Job)
+	go func() {
+		defer close(jobs)
+		for i := 0; i < 50; i++ {
+			jobs <- Job{ID: fmt.Sprint(i)}
+			atomic.AddInt32(&read, 1)
+		}
+	}()
+
+	var running, maxRunning int32
+	op := func(ctx context.Context, keyRing string, data []byte) ([]byte, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			m := atomic.LoadInt32(&maxRunning)
+			if n <= m || atomic.CompareAndSwapInt32(&maxRunning, m, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return data, nil
+	}
+
+	out := Run(context.Background(), jobs, op, &Config{Workers: 8, MaxInFlight: 3})
+	// the results aren't received yet, so only MaxInFlight jobs may be read
+	time.Sleep(20 * time.Millisecond)
+	assert.LessOrEqual(t, atomic.LoadInt32(&read), int32(3))
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(3))
+
+	n := 0
+	for range out {
+		n++
+	}
+	assert.Equal(t, 50, n)
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(3))
+}
+
+func TestRun_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	jobs := make(chan Job)
+	go func() {
+		for {
+			select {
+			case jobs <- Job{}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	out := Run(ctx, jobs, func(ctx context.Context, keyRing string, data []byte) ([]byte, error) {
+		return nil, errors.New("failed")
+	}, nil)
+	<-out
+	cancel()
+
+	// out is closed without reading the remaining jobs
+	done := make(chan struct{})
+	go func() {
+		for range out {
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run didn't stop after cancel")
+	}
+}
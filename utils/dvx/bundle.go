@@ -0,0 +1,258 @@
+package dvx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// BundleConfig configures Protocol.ExportVerificationBundle.
+type BundleConfig struct {
+	// Signer is the keyRing whose key signs the bundle. Its public key (see
+	// CreateSignKey) must reach the verifiers out of band, e.g. in their
+	// configuration, as it is the root of trust of NewVerifierFromBundle.
+	// For example: "bundles/edge"
+	Signer string
+	// KeyRings are the keyRings whose signatures can be verified with the
+	// bundle. For example: "services/billing"
+	KeyRings []string
+	// Versions are the versions of the exported public keys, so signatures
+	// created before a migration stay verifiable. Empty exports the keys of
+	// the version the Protocol signs with. For example: "dv1" and "dv2"
+	Versions []string
+	// Revoked are keyRings whose signatures are rejected with ErrRevoked,
+	// e.g. after their key leaked. Their public keys aren't exported.
+	Revoked []string
+	// TTL is the time after which verifiers reject the bundle, so they
+	// can't keep using outdated public keys and revocations forever. Zero
+	// disables the expiry. For example: 24 * time.Hour
+	TTL time.Duration
+}
+
+// VerificationBundle is the content of a bundle of
+// Protocol.ExportVerificationBundle.
+type VerificationBundle struct {
+	// Signer is the keyRing that signed the bundle.
+	Signer string `json:"signer"`
+	// Version is the version the exporting Protocol signs with.
+	Version string `json:"version"`
+	// IssuedAt is the time the bundle was exported.
+	IssuedAt time.Time `json:"issued_at"`
+	// ExpiresAt is the time after which the bundle is rejected, or nil if
+	// it doesn't expire.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// Keys are the exported public keys.
+	Keys []BundleKey `json:"keys"`
+	// Revoked are the keyRings whose signatures are rejected.
+	Revoked []string `json:"revoked,omitempty"`
+}
+
+// BundleKey is the public key of a keyRing for a version.
+type BundleKey struct {
+	KeyRing   string `json:"key_ring"`
+	Version   string `json:"version"`
+	PublicKey []byte `json:"public_key"`
+}
+
+// signedBundle is the encoding of a VerificationBundle with its signature of
+// SignJSON.
+type signedBundle struct {
+	Bundle    json.RawMessage `json:"bundle"`
+	Signature string          `json:"signature"`
+}
+
+// ExportVerificationBundle exports everything needed to verify signatures of
+// config.KeyRings offline: their public keys for every version of
+// config.Versions, the version metadata and the list of revoked keyRings. The
+// bundle is JSON, signed by config.Signer (see SignJSON), and can be
+// distributed over untrusted channels (e.g. a CDN) to edge services, which
+// verify signatures with NewVerifierFromBundle without any access to a
+// KeyPool.
+func (p *Protocol) ExportVerificationBundle(config *BundleConfig) (bundle []byte, err error) {
+	return p.ExportVerificationBundleContext(context.Background(), config)
+}
+
+// ExportVerificationBundleContext is like ExportVerificationBundle, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) ExportVerificationBundleContext(ctx context.Context, config *BundleConfig) (bundle []byte, err error) {
+	if config == nil || config.Signer == "" {
+		return nil, fmt.Errorf("dvx: verification bundle requires a signer")
+	}
+	if config.TTL < 0 {
+		return nil, fmt.Errorf("dvx: verification bundle TTL cannot be negative")
+	}
+	now, err := p.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	versions := config.Versions
+	if len(versions) == 0 {
+		versions = []string{p.writeVersion()}
+	}
+	b := &VerificationBundle{
+		Signer:   config.Signer,
+		Version:  p.writeVersion(),
+		IssuedAt: now.UTC(),
+		Keys:     []BundleKey{},
+		Revoked:  config.Revoked,
+	}
+	if config.TTL > 0 {
+		expiresAt := b.IssuedAt.Add(config.TTL)
+		b.ExpiresAt = &expiresAt
+	}
+
+	revoked := make(map[string]bool, len(config.Revoked))
+	for _, keyRing := range config.Revoked {
+		revoked[keyRing] = true
+	}
+	for _, keyRing := range config.KeyRings {
+		if revoked[keyRing] {
+			continue
+		}
+		keyRingBytes, err := p.keyRingInput(keyRing, OpCreateSignKey)
+		if err != nil {
+			return nil, err
+		}
+		for _, version := range versions {
+			if lookupVersion(version) == nil {
+				return nil, fmt.Errorf("dvx: version %q isn't supported", version)
+			}
+			_, publicKey, err := p.deriveSignKeyPair(ctx, keyRingBytes, version)
+			if err != nil {
+				return nil, err
+			}
+			b.Keys = append(b.Keys, BundleKey{KeyRing: keyRing, Version: version, PublicKey: publicKey})
+		}
+	}
+
+	content, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := p.SignJSONContext(ctx, config.Signer, json.RawMessage(content))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(signedBundle{Bundle: content, Signature: signature})
+}
+
+// Verifier verifies signatures with the public keys of a verification bundle
+// (see Protocol.ExportVerificationBundle), without access to a KeyPool. It is
+// safe for concurrent use.
+type Verifier struct {
+	bundle  *VerificationBundle
+	keys    map[string]map[string][]byte
+	revoked map[string]bool
+	clock   Clock
+}
+
+// NewVerifierFromBundle verifies the signature of bundle with the public key
+// of its signer keyRing (see BundleConfig.Signer) and returns a Verifier for
+// its public keys. It fails with ErrAuthentication if bundle wasn't signed by
+// signerPublicKey and with ErrExpired if bundle expired. For example:
+//   verifier, err := dvx.NewVerifierFromBundle(bundle, signerPublicKey)
+//   valid, err := verifier.Verify("services/billing", message, signature)
+func NewVerifierFromBundle(bundle []byte, signerPublicKey []byte) (*Verifier, error) {
+	var sb signedBundle
+	if err := json.Unmarshal(bundle, &sb); err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: invalid verification bundle: %w", err)
+	}
+	v, sig, footer, err := decodeExpectWithFooter(sb.Signature, Signed)
+	if err != nil {
+		return nil, err
+	}
+	message, err := CanonicalJSON(sb.Bundle)
+	if err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: invalid verification bundle: %w", err)
+	}
+	valid, err := primitiveOf(v).Verify(signerPublicKey, footerMessage(v, Signed, message, footer), sig)
+	if err != nil {
+		return nil, err
+	}
+	if !valid {
+		return nil, errorf(ErrAuthentication, "dvx: verification bundle signature is invalid")
+	}
+
+	b := &VerificationBundle{}
+	if err := json.Unmarshal(sb.Bundle, b); err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: invalid verification bundle: %w", err)
+	}
+	verifier := &Verifier{
+		bundle:  b,
+		keys:    make(map[string]map[string][]byte),
+		revoked: make(map[string]bool, len(b.Revoked)),
+	}
+	for _, key := range b.Keys {
+		if verifier.keys[key.KeyRing] == nil {
+			verifier.keys[key.KeyRing] = make(map[string][]byte)
+		}
+		verifier.keys[key.KeyRing][key.Version] = key.PublicKey
+	}
+	for _, keyRing := range b.Revoked {
+		verifier.revoked[keyRing] = true
+	}
+	if err := verifier.checkExpiry(context.Background()); err != nil {
+		return nil, err
+	}
+	return verifier, nil
+}
+
+// SetClock sets the Clock that checks the expiry of the bundle, like
+// Protocol.SetClock. SetClock must be called before v is used.
+func (v *Verifier) SetClock(clock Clock) {
+	v.clock = clock
+}
+
+// Bundle returns the content of the verified bundle. It must not be
+// modified.
+func (v *Verifier) Bundle() *VerificationBundle {
+	return v.bundle
+}
+
+// Verify verifies the signature of Protocol.Sign for message and keyRing with
+// the public key of keyRing in the bundle. It fails with ErrRevoked if keyRing
+// was revoked, with ErrExpired if the bundle expired and with
+// ErrKeyDerivation if the bundle has no public key of the signature's version
+// for keyRing.
+func (v *Verifier) Verify(keyRing string, message []byte, signature string) (valid bool, err error) {
+	return v.VerifyContext(context.Background(), keyRing, message, signature)
+}
+
+// VerifyContext is like Verify, but passes ctx to the Clock of v.
+func (v *Verifier) VerifyContext(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error) {
+	version, sig, footer, err := decodeExpectWithFooter(signature, Signed)
+	if err != nil {
+		return false, err
+	}
+	if err := v.checkExpiry(ctx); err != nil {
+		return false, err
+	}
+	if v.revoked[keyRing] {
+		return false, errorf(ErrRevoked, "dvx: keyRing was revoked by the verification bundle")
+	}
+	publicKey, ok := v.keys[keyRing][version]
+	if !ok {
+		return false, errorf(ErrKeyDerivation, "dvx: verification bundle has no %s public key for keyRing", version)
+	}
+	return primitiveOf(version).Verify(publicKey, footerMessage(version, Signed, message, footer), sig)
+}
+
+// checkExpiry fails with ErrExpired if the bundle expired.
+func (v *Verifier) checkExpiry(ctx context.Context) error {
+	if v.bundle.ExpiresAt == nil {
+		return nil
+	}
+	now := time.Now()
+	if v.clock != nil {
+		var err error
+		if now, err = v.clock(ctx); err != nil {
+			return fmt.Errorf("dvx: clock failed: %w", err)
+		}
+	}
+	if !now.Before(*v.bundle.ExpiresAt) {
+		return errorf(ErrExpired, "dvx: verification bundle expired at %s", v.bundle.ExpiresAt.Format(time.RFC3339))
+	}
+	return nil
+}
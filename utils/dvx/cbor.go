@@ -0,0 +1,243 @@
+package dvx
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+)
+
+// The subset of CBOR (RFC 8949) needed for COSE structures: integers, byte
+// and text strings, arrays, maps, tags and null. Values are encoded with the
+// core deterministic encoding requirements (shortest heads, sorted map keys),
+// indefinite lengths and floats aren't supported.
+
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	cborTagged = 6
+	cborSimple = 7
+
+	cborNull = 0xf6
+
+	// cborMaxDepth limits the nesting of decoded arrays, maps and tags
+	cborMaxDepth = 16
+)
+
+// cborTag is a tagged CBOR value.
+type cborTag struct {
+	number  uint64
+	content interface{}
+}
+
+// cborMapEntry is an entry of a CBOR map. Maps are encoded from a slice of
+// entries and decoded into map[interface{}]interface{}.
+type cborMapEntry struct {
+	key   interface{}
+	value interface{}
+}
+
+func cborAppendHead(buf []byte, major byte, n uint64) []byte {
+	switch {
+	case n < 24:
+		return append(buf, major<<5|byte(n))
+	case n <= 0xff:
+		return append(buf, major<<5|24, byte(n))
+	case n <= 0xffff:
+		return append(buf, major<<5|25, byte(n>>8), byte(n))
+	case n <= 0xffffffff:
+		var b [4]byte
+		binary.BigEndian.PutUint32(b[:], uint32(n))
+		return append(append(buf, major<<5|26), b[:]...)
+	default:
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], n)
+		return append(append(buf, major<<5|27), b[:]...)
+	}
+}
+
+// cborMarshal encodes v, which must consist of int, int64, uint64, []byte,
+// string, []interface{}, []cborMapEntry, cborTag and nil.
+func cborMarshal(v interface{}) []byte {
+	return cborAppend(nil, v)
+}
+
+func cborAppend(buf []byte, v interface{}) []byte {
+	switch v := v.(type) {
+	case nil:
+		return append(buf, cborNull)
+	case int:
+		return cborAppend(buf, int64(v))
+	case int64:
+		if v < 0 {
+			return cborAppendHead(buf, cborNegint, uint64(-1-v))
+		}
+		return cborAppendHead(buf, cborUint, uint64(v))
+	case uint64:
+		return cborAppendHead(buf, cborUint, v)
+	case []byte:
+		return append(cborAppendHead(buf, cborBytes, uint64(len(v))), v...)
+	case string:
+		return append(cborAppendHead(buf, cborText, uint64(len(v))), v...)
+	case []interface{}:
+		buf = cborAppendHead(buf, cborArray, uint64(len(v)))
+		for _, elem := range v {
+			buf = cborAppend(buf, elem)
+		}
+		return buf
+	case []cborMapEntry:
+		// deterministic encoding: keys sorted by their bytewise encoding
+		type encoded struct{ key, value []byte }
+		entries := make([]encoded, len(v))
+		for i, entry := range v {
+			entries[i] = encoded{cborMarshal(entry.key), cborMarshal(entry.value)}
+		}
+		sort.Slice(entries, func(i, j int) bool {
+			return bytes.Compare(entries[i].key, entries[j].key) < 0
+		})
+
+		buf = cborAppendHead(buf, cborMap, uint64(len(v)))
+		for _, entry := range entries {
+			buf = append(append(buf, entry.key...), entry.value...)
+		}
+		return buf
+	case cborTag:
+		return cborAppend(cborAppendHead(buf, cborTagged, v.number), v.content)
+	default:
+		panic("dvx: unsupported CBOR type")
+	}
+}
+
+// cborUnmarshal decodes a single CBOR value, that must span all of data.
+// Integers are decoded as int64, maps as map[interface{}]interface{}.
+func cborUnmarshal(data []byte) (interface{}, error) {
+	d := &cborDecoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(data) {
+		return nil, errorf(ErrInvalidFormat, "dvx: CBOR: %d trailing bytes", len(data)-d.off)
+	}
+	return v, nil
+}
+
+type cborDecoder struct {
+	data []byte
+	off  int
+}
+
+func (d *cborDecoder) head() (major byte, n uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, errorf(ErrInvalidFormat, "dvx: CBOR: unexpected end of data")
+	}
+	b := d.data[d.off]
+	d.off++
+	major, info := b>>5, b&0x1f
+
+	var size int
+	switch {
+	case info < 24:
+		return major, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	default:
+		return 0, 0, errorf(ErrInvalidFormat, "dvx: CBOR: unsupported additional information %d", info)
+	}
+
+	if len(d.data)-d.off < size {
+		return 0, 0, errorf(ErrInvalidFormat, "dvx: CBOR: unexpected end of data")
+	}
+	for _, b := range d.data[d.off : d.off+size] {
+		n = n<<8 | uint64(b)
+	}
+	d.off += size
+	return major, n, nil
+}
+
+func (d *cborDecoder) value(depth int) (interface{}, error) {
+	if depth > cborMaxDepth {
+		return nil, errorf(ErrInvalidFormat, "dvx: CBOR: nested too deeply")
+	}
+
+	major, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	switch major {
+	case cborUint, cborNegint:
+		if n > 1<<63-1 {
+			return nil, errorf(ErrInvalidFormat, "dvx: CBOR: integer overflows int64")
+		}
+		if major == cborNegint {
+			return -1 - int64(n), nil
+		}
+		return int64(n), nil
+	case cborBytes, cborText:
+		if uint64(len(d.data)-d.off) < n {
+			return nil, errorf(ErrInvalidFormat, "dvx: CBOR: unexpected end of data")
+		}
+		buf := d.data[d.off : d.off+int(n)]
+		d.off += int(n)
+		if major == cborText {
+			return string(buf), nil
+		}
+		return append([]byte{}, buf...), nil
+	case cborArray:
+		// every element needs at least one byte
+		if uint64(len(d.data)-d.off) < n {
+			return nil, errorf(ErrInvalidFormat, "dvx: CBOR: unexpected end of data")
+		}
+		array := make([]interface{}, n)
+		for i := range array {
+			if array[i], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return array, nil
+	case cborMap:
+		if uint64(len(d.data)-d.off)/2 < n {
+			return nil, errorf(ErrInvalidFormat, "dvx: CBOR: unexpected end of data")
+		}
+		m := make(map[interface{}]interface{}, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, errorf(ErrInvalidFormat, "dvx: CBOR: unsupported map key of type %T", key)
+			}
+			if _, ok := m[key]; ok {
+				return nil, errorf(ErrInvalidFormat, "dvx: CBOR: duplicate map key %v", key)
+			}
+			if m[key], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case cborTagged:
+		content, err := d.value(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return cborTag{number: n, content: content}, nil
+	default: // cborSimple
+		if n == cborNull&0x1f {
+			return nil, nil
+		}
+		return nil, errorf(ErrInvalidFormat, "dvx: CBOR: unsupported simple value or float")
+	}
+}
@@ -0,0 +1,124 @@
+package dvx
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"math/big"
+	"time"
+)
+
+// CreateCertificate issues an X.509 certificate for the Ed25519 key derived
+// for keyRing (see Signer), so dvx can act as a minimal internal PKI for
+// service identities without storing any private key.
+//
+// The certificate is signed by the key derived for parentKeyRing, whose
+// certificate is parent. If parent is nil, the certificate is self-signed
+// with the key of keyRing and parentKeyRing is ignored, e.g. for a root CA.
+//
+// template defines the certificate like for x509.CreateCertificate, but is
+// not modified. Its PublicKey is ignored. NotAfter must be set, for the other
+// fields CreateCertificate chooses defaults:
+//   - SerialNumber: 128 random bits
+//   - NotBefore: the current time
+//   - KeyUsage: CertSign and CRLSign for CAs (IsCA), DigitalSignature for
+//     leaf certificates
+//   - BasicConstraintsValid: true for CAs
+func (p *Protocol) CreateCertificate(keyRing string, template *x509.Certificate, parent *x509.Certificate, parentKeyRing string) (*x509.Certificate, error) {
+	return p.CreateCertificateContext(context.Background(), keyRing, template, parent, parentKeyRing)
+}
+
+// CreateCertificateContext is like CreateCertificate, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation of the
+// public keys.
+func (p *Protocol) CreateCertificateContext(ctx context.Context, keyRing string, template *x509.Certificate, parent *x509.Certificate, parentKeyRing string) (*x509.Certificate, error) {
+	if template.NotAfter.IsZero() {
+		return nil, errorf(ErrInvalidFormat, "dvx: certificate template must set NotAfter")
+	}
+
+	subject, err := p.SignerContext(ctx, keyRing)
+	if err != nil {
+		return nil, err
+	}
+
+	cert := certificateTemplate(template)
+	if cert.SerialNumber == nil {
+		cert.SerialNumber, err = rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+		if err != nil {
+			return nil, errorf(ErrRandomness, "dvx: failed to read random serial number: %v", err)
+		}
+	}
+
+	issuer := subject
+	if parent == nil {
+		parent = cert
+	} else {
+		issuer, err = p.SignerContext(ctx, parentKeyRing)
+		if err != nil {
+			return nil, err
+		}
+		if !certificateOf(parent, issuer) {
+			return nil, errorf(ErrInvalidKey, "dvx: parent certificate doesn't belong to the key of parentKeyRing")
+		}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, cert, parent, subject.Public(), issuer)
+	if err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: unable to create certificate: %v", err)
+	}
+	return x509.ParseCertificate(der)
+}
+
+// certificateTemplate returns a copy of template with the defaults of
+// CreateCertificate.
+func certificateTemplate(template *x509.Certificate) *x509.Certificate {
+	cert := *template
+	if cert.NotBefore.IsZero() {
+		cert.NotBefore = time.Now()
+	}
+	if cert.KeyUsage == 0 {
+		if cert.IsCA {
+			cert.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageCRLSign
+		} else {
+			cert.KeyUsage = x509.KeyUsageDigitalSignature
+		}
+	}
+	if cert.IsCA {
+		cert.BasicConstraintsValid = true
+	}
+	return &cert
+}
+
+// certificateOf reports whether cert is a certificate of the key of signer.
+func certificateOf(cert *x509.Certificate, signer crypto.Signer) bool {
+	key, ok := cert.PublicKey.(ed25519.PublicKey)
+	return ok && bytes.Equal(key, signer.Public().(ed25519.PublicKey))
+}
+
+// TLSCertificate returns a tls.Certificate of the key derived for keyRing, for
+// a certificate of CreateCertificate and the chain of its intermediate CAs
+// (without root), e.g. for tls.Config.Certificates. The private key is a
+// Signer, so it isn't held in memory.
+func (p *Protocol) TLSCertificate(keyRing string, cert *x509.Certificate, intermediates ...*x509.Certificate) (tls.Certificate, error) {
+	signer, err := p.Signer(keyRing)
+	if err != nil {
+		return tls.Certificate{}, err
+	}
+	if !certificateOf(cert, signer) {
+		return tls.Certificate{}, errorf(ErrInvalidKey, "dvx: certificate doesn't belong to the key of keyRing")
+	}
+
+	chain := [][]byte{cert.Raw}
+	for _, c := range intermediates {
+		chain = append(chain, c.Raw)
+	}
+	return tls.Certificate{
+		Certificate: chain,
+		PrivateKey:  signer,
+		Leaf:        cert,
+	}, nil
+}
@@ -0,0 +1,46 @@
+package dvx
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Clock returns the current time. A Protocol uses it for all decisions that
+// depend on the time: whether TOTP codes belong to the current period (see
+// VerifyTOTP), whether a time-locked cipher (see EncryptNotBefore) may
+// already be decrypted and whether tokens, sessions, revocations and
+// challenges are expired. Deployments can plug in a time source they trust
+// more than the local clock of the machine, e.g. an NTP-disciplined or
+// roughtime-verified one, and tests can freeze the time (see FixedClock).
+type Clock func(ctx context.Context) (time.Time, error)
+
+// FixedClock returns a Clock that always returns t, so tests can freeze the
+// time of a Protocol without changing global state.
+func FixedClock(t time.Time) Clock {
+	return func(context.Context) (time.Time, error) {
+		return t, nil
+	}
+}
+
+// SetClock replaces the Clock of p, which defaults to the local time of the
+// machine. Anyone able to change the local time can decrypt time-locked
+// ciphers early, replay expired tokens or reuse TOTP codes of past periods,
+// therefore they should be checked with a trusted Clock (e.g. backed by an
+// authenticated time source). Operations fail if the Clock fails. SetClock
+// must be called before p is used.
+func (p *Protocol) SetClock(clock Clock) {
+	p.clock = clock
+}
+
+// now returns the time of the Clock of p.
+func (p *Protocol) now(ctx context.Context) (time.Time, error) {
+	if p.clock == nil {
+		return time.Now(), nil
+	}
+	t, err := p.clock(ctx)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("dvx: clock failed: %w", err)
+	}
+	return t, nil
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/qr"
+)
+
+// cmdFlags are the flags shared by all commands that use a Protocol.
+type cmdFlags struct {
+	fs      *flag.FlagSet
+	root    *rootFlags
+	keyRing string
+	in      string
+	out     string
+}
+
+func newCmdFlags(name string) *cmdFlags {
+	f := &cmdFlags{fs: flag.NewFlagSet(name, flag.ContinueOnError)}
+	f.root = addRootFlags(f.fs)
+	f.fs.StringVar(&f.keyRing, "keyring", "", "keyRing used for the key derivation (required)")
+	f.fs.StringVar(&f.in, "in", "-", `input file. "-" reads from stdin`)
+	f.fs.StringVar(&f.out, "out", "-", `output file. "-" writes to stdout`)
+	return f
+}
+
+// parse parses args and creates the Protocol. The returned close function
+// closes the Protocol's KeyPool.
+func (f *cmdFlags) parse(args []string) (p *dvx.Protocol, close func(), err error) {
+	if err := f.fs.Parse(args); err != nil {
+		return nil, nil, err
+	}
+	if f.keyRing == "" {
+		return nil, nil, errors.New("-keyring is required")
+	}
+	return f.root.protocol()
+}
+
+func (f *cmdFlags) readInput(stdin io.Reader) ([]byte, error) {
+	if f.in == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(f.in)
+}
+
+// readToken reads a dvx string (ciphertext, signature, tag or totp id) from
+// the input. Surrounding whitespace (e.g. a trailing newline) is removed.
+func (f *cmdFlags) readToken(stdin io.Reader) (string, error) {
+	buf, err := f.readInput(stdin)
+	if err != nil {
+		return "", err
+	}
+	return string(bytes.TrimSpace(buf)), nil
+}
+
+func (f *cmdFlags) writeOutput(stdout io.Writer, data []byte) error {
+	if f.out == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(f.out, data, 0600)
+}
+
+func (f *cmdFlags) writeLine(stdout io.Writer, line string) error {
+	return f.writeOutput(stdout, []byte(line+"\n"))
+}
+
+func runKeygen(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	out := fs.String("out", "-", `output file. "-" writes to stdout`)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	root := make([]byte, 64)
+	if _, err := io.ReadFull(rand.Reader, root); err != nil {
+		return err
+	}
+
+	f := &cmdFlags{out: *out}
+	return f.writeLine(stdout, base64.StdEncoding.EncodeToString(root))
+}
+
+func runEncrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	f := newCmdFlags("encrypt")
+	p, close, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	data, err := f.readInput(stdin)
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := p.Encrypt(f.keyRing, data)
+	if err != nil {
+		return err
+	}
+	return f.writeLine(stdout, ciphertext)
+}
+
+func runDecrypt(args []string, stdin io.Reader, stdout io.Writer) error {
+	f := newCmdFlags("decrypt")
+	p, close, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	ciphertext, err := f.readToken(stdin)
+	if err != nil {
+		return err
+	}
+
+	data, err := p.Decrypt(f.keyRing, ciphertext)
+	if err != nil {
+		return err
+	}
+	return f.writeOutput(stdout, data)
+}
+
+func runSign(args []string, stdin io.Reader, stdout io.Writer) error {
+	f := newCmdFlags("sign")
+	publicKey := f.fs.Bool("public-key", false, "print the base64 encoded public key of the keyRing instead of signing the input")
+	p, close, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if *publicKey {
+		pk, err := p.CreateSignKey(f.keyRing)
+		if err != nil {
+			return err
+		}
+		return f.writeLine(stdout, base64.StdEncoding.EncodeToString(pk))
+	}
+
+	message, err := f.readInput(stdin)
+	if err != nil {
+		return err
+	}
+
+	signature, _, err := p.Sign(f.keyRing, message)
+	if err != nil {
+		return err
+	}
+	return f.writeLine(stdout, signature)
+}
+
+func runVerify(args []string, stdin io.Reader, stdout io.Writer) error {
+	f := newCmdFlags("verify")
+	signature := f.fs.String("signature", "", "signature created by sign (required)")
+	p, close, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if *signature == "" {
+		return errors.New("-signature is required")
+	}
+
+	message, err := f.readInput(stdin)
+	if err != nil {
+		return err
+	}
+
+	valid, err := p.Verify(f.keyRing, message, *signature)
+	if err != nil {
+		return err
+	}
+	return validResult(f, stdout, valid)
+}
+
+func runMAC(args []string, stdin io.Reader, stdout io.Writer) error {
+	f := newCmdFlags("mac")
+	p, close, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	message, err := f.readInput(stdin)
+	if err != nil {
+		return err
+	}
+
+	tag, err := p.MAC(f.keyRing, message)
+	if err != nil {
+		return err
+	}
+	return f.writeLine(stdout, tag)
+}
+
+func runTOTP(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New("missing totp command: generate or verify")
+	}
+
+	switch args[0] {
+	case "generate":
+		return runTOTPGenerate(args[1:], stdout)
+	case "verify":
+		return runTOTPVerify(args[1:], stdout)
+	default:
+		return fmt.Errorf("unknown totp command %q", args[0])
+	}
+}
+
+func runTOTPGenerate(args []string, stdout io.Writer) error {
+	f := newCmdFlags("totp generate")
+	issuer := f.fs.String("issuer", "", "issuer shown in the authenticator app")
+	accountName := f.fs.String("account-name", "", "account name shown in the authenticator app")
+	accountID := f.fs.String("account-id", "", "id of the account the secret is bound to (required)")
+	qrFile := f.fs.String("qr", "", "path of a PNG, EPS or PDF file (by extension) the QR-Code of the uri is written to")
+	p, close, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if *accountID == "" {
+		return errors.New("-account-id is required")
+	}
+
+	id, uri, err := p.GenerateTOTP(f.keyRing, *issuer, *accountName, *accountID)
+	if err != nil {
+		return err
+	}
+
+	if *qrFile != "" {
+		image, err := qrImage(*qrFile, uri)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*qrFile, image, 0600); err != nil {
+			return err
+		}
+	}
+
+	return f.writeOutput(stdout, []byte(id+"\n"+uri+"\n"))
+}
+
+// qrImage encodes uri as QR-Code in the format of the extension of path.
+// URIs too long for a scannable code (e.g. because of a long issuer) fail.
+func qrImage(path, uri string) ([]byte, error) {
+	if err := qr.CheckOTPAuth(uri, nil); err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".eps":
+		return qr.EPSRaw(uri)
+	case ".pdf":
+		return qr.PDFRaw(uri)
+	default:
+		return qr.PNGRaw(uri)
+	}
+}
+
+func runTOTPVerify(args []string, stdout io.Writer) error {
+	f := newCmdFlags("totp verify")
+	id := f.fs.String("id", "", "totp id returned by totp generate (required)")
+	accountID := f.fs.String("account-id", "", "id of the account the secret is bound to (required)")
+	code := f.fs.String("code", "", "code of the authenticator app (required)")
+	p, close, err := f.parse(args)
+	if err != nil {
+		return err
+	}
+	defer close()
+
+	if *id == "" || *accountID == "" || *code == "" {
+		return errors.New("-id, -account-id and -code are required")
+	}
+
+	valid, err := p.VerifyTOTP(f.keyRing, *id, *accountID, *code)
+	if err != nil {
+		return err
+	}
+	return validResult(f, stdout, valid)
+}
+
+// validResult writes the result of a verification and returns errInvalid if
+// it failed.
+func validResult(f *cmdFlags, stdout io.Writer, valid bool) error {
+	if !valid {
+		return errInvalid
+	}
+	return f.writeLine(stdout, "valid")
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/harwoeck/liblog/contract"
+
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/hsm"
+	"azoo.dev/utils/dvx/liblog"
+)
+
+// errDoctor is returned by doctor if at least one check failed.
+var errDoctor = errors.New("doctor found errors")
+
+// doctor collects the findings of all checks.
+type doctor struct {
+	findings []hsm.Finding
+}
+
+func (d *doctor) add(check string, severity hsm.Severity, format string, a ...interface{}) {
+	d.findings = append(d.findings, hsm.Finding{Check: check, Severity: severity, Message: fmt.Sprintf(format, a...)})
+}
+
+func (d *doctor) print(w io.Writer) error {
+	failed := false
+	for _, f := range d.findings {
+		fmt.Fprintf(w, "[%-7s] %-10s %s\n", f.Severity, f.Check, f.Message)
+		if f.Severity == hsm.SeverityError {
+			failed = true
+		}
+	}
+	if failed {
+		return errDoctor
+	}
+	return nil
+}
+
+func runDoctor(args []string, _ io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	root := addRootFlags(fs)
+	timeURL := fs.String("time-url", "https://www.cloudflare.com", `URL whose "Date" response header is used to check the clock skew. Empty disables the check`)
+	cacheSize := fs.Int("cache-size", 65536, "size of the tearc key cache")
+	cacheShards := fs.Int("cache-shards", 64, "amount of shards of the tearc key cache")
+	cacheMinTick := fs.Duration("cache-min-tick", 5*time.Second, "minimum time between tearc reaper runs")
+	cacheMaxTick := fs.Duration("cache-max-tick", 20*time.Second, "maximum time between tearc reaper runs")
+	cacheAliveFor := fs.Duration("cache-alive-time", 1*time.Minute, "time cached keys stay alive after their last usage")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	d := &doctor{}
+	d.checkEntropy()
+	d.checkClock(*timeURL)
+	d.checkCache(*cacheSize, *cacheShards, *cacheMinTick, *cacheMaxTick, *cacheAliveFor)
+	d.checkRoot(root)
+	return d.print(stdout)
+}
+
+func (d *doctor) checkEntropy() {
+	done := make(chan error, 1)
+	go func() {
+		_, err := io.ReadFull(rand.Reader, make([]byte, 64))
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			d.add("entropy", hsm.SeverityError, "reading from the CSPRNG failed: %v", err)
+			return
+		}
+	case <-time.After(2 * time.Second):
+		d.add("entropy", hsm.SeverityError, "reading from the CSPRNG blocks. The system's entropy pool isn't initialized yet (e.g. in early boot or minimal VMs). Consider a hardware RNG or haveged/rng-tools")
+		return
+	}
+
+	if buf, err := os.ReadFile("/proc/sys/kernel/random/entropy_avail"); err == nil {
+		d.add("entropy", hsm.SeverityOK, "CSPRNG available (entropy_avail %s)", strings.TrimSpace(string(buf)))
+		return
+	}
+	d.add("entropy", hsm.SeverityOK, "CSPRNG available")
+}
+
+func (d *doctor) checkClock(url string) {
+	if url == "" {
+		return
+	}
+
+	client := &http.Client{Timeout: 5 * time.Second}
+	start := time.Now()
+	resp, err := client.Head(url)
+	if err != nil {
+		d.add("clock", hsm.SeverityWarning, "unable to check the clock skew against %s: %v. TOTP codes are only accepted if the clock is synchronized (e.g. NTP)", url, err)
+		return
+	}
+	_ = resp.Body.Close()
+	rtt := time.Since(start)
+
+	remote, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		d.add("clock", hsm.SeverityWarning, "%s returned no valid Date header, unable to check the clock skew", url)
+		return
+	}
+
+	// the Date header has a resolution of one second and was created
+	// somewhere during the round trip
+	skew := start.Add(rtt / 2).Sub(remote)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	switch {
+	case skew >= 30*time.Second:
+		d.add("clock", hsm.SeverityError, "clock is off by about %s compared to %s. TOTP codes use a period of 30s and will be rejected. Synchronize the clock (e.g. NTP)", skew.Round(time.Second), url)
+	case skew >= 5*time.Second:
+		d.add("clock", hsm.SeverityWarning, "clock is off by about %s compared to %s. Synchronize the clock (e.g. NTP) before TOTP codes start to fail", skew.Round(time.Second), url)
+	default:
+		d.add("clock", hsm.SeverityOK, "clock is synchronized with %s (skew < 5s)", url)
+	}
+}
+
+func (d *doctor) checkCache(size, shards int, minTick, maxTick, aliveTime time.Duration) {
+	ok := true
+	fail := func(format string, a ...interface{}) {
+		d.add("cache", hsm.SeverityError, format, a...)
+		ok = false
+	}
+	warn := func(format string, a ...interface{}) {
+		d.add("cache", hsm.SeverityWarning, format, a...)
+	}
+
+	switch {
+	case size <= 0 || shards <= 0:
+		fail("-cache-size (%d) and -cache-shards (%d) must be greater than zero", size, shards)
+	case size%shards != 0:
+		fail("-cache-size (%d) must be a multiple of -cache-shards (%d)", size, shards)
+	case size/shards < 16:
+		warn("every shard holds only %d keys. Use fewer shards or a bigger cache", size/shards)
+	}
+	if minTick >= maxTick {
+		fail("-cache-min-tick (%s) must be less than -cache-max-tick (%s)", minTick, maxTick)
+	}
+	if aliveTime <= 0 {
+		fail("-cache-alive-time must be greater than zero")
+	} else if aliveTime > time.Hour {
+		warn("keys stay in memory for up to %s after their last usage. Consider a shorter -cache-alive-time", aliveTime)
+	}
+
+	if ok {
+		d.add("cache", hsm.SeverityOK, "%d keys in %d shards, alive for %s", size, shards, aliveTime)
+	}
+}
+
+func (d *doctor) checkRoot(r *rootFlags) {
+	if r.hsmModule != "" {
+		if os.Getenv(r.pinEnv) == "" {
+			d.add("login", hsm.SeverityWarning, "%s is empty", r.pinEnv)
+		}
+		d.findings = append(d.findings, hsm.Diagnose(&hsm.Config{
+			Module:       r.hsmModule,
+			Label:        r.hsmLabel,
+			UserPin:      os.Getenv(r.pinEnv),
+			RootKeyID:    r.hsmKeyID,
+			RootKeyLabel: r.hsmKeyLabel,
+		})...)
+		return
+	}
+
+	pool, err := r.keyPool(liblog.Wrap(contract.MustNewStd()))
+	if err != nil {
+		d.add("root key", hsm.SeverityError, "%v", err)
+		return
+	}
+	defer func() {
+		_ = pool.Close()
+	}()
+
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool})
+	if err := p.SelfTest(context.Background()); err != nil {
+		d.add("root key", hsm.SeverityError, "%v", err)
+		return
+	}
+	d.add("root key", hsm.SeverityOK, "root key is valid and passed the self-test (%s)", dvx.Version)
+}
@@ -0,0 +1,19 @@
+module azoo.dev/utils/dvx/cmd/dvx
+
+go 1.16
+
+require (
+	azoo.dev/utils/dvx v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx/hsm v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx/liblog v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/qr v0.0.0-00010101000000-000000000000
+	github.com/harwoeck/liblog/contract v1.1.2
+	github.com/stretchr/testify v1.7.0
+)
+
+replace (
+	azoo.dev/utils/dvx => ../..
+	azoo.dev/utils/dvx/hsm => ../../hsm
+	azoo.dev/utils/dvx/liblog => ../../liblog
+	azoo.dev/utils/qr => ../../../qr
+)
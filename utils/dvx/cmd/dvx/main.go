@@ -0,0 +1,113 @@
+// Command dvx provides the operations of a dvx Protocol for scripts and
+// shells:
+//
+//   dvx keygen
+//   dvx encrypt      -keyring <keyRing>                < data      > ciphertext
+//   dvx decrypt      -keyring <keyRing>                < ciphertext > data
+//   dvx sign         -keyring <keyRing>                < message   > signature
+//   dvx verify       -keyring <keyRing> -signature <s> < message
+//   dvx mac          -keyring <keyRing>                < message   > tag
+//   dvx totp generate -keyring <keyRing> -account-id <id> [-issuer <i>] [-account-name <n>] [-qr <file.png>]
+//   dvx totp verify   -keyring <keyRing> -account-id <id> -id <totp-id> -code <code>
+//   dvx rotate -from-root-file <old> -root-file <new> [-keyring <k>] [-format ndjson|csv] [-checkpoint <file>] [-dry-run] < records > rotated
+//   dvx doctor [-hsm-module <module>] [-time-url <url>] [-cache-* ...]
+//
+// Inputs are read from stdin (or -in) and results are written to stdout (or
+// -out), so commands can be chained with pipes. verify and totp verify exit
+// with status 2 if the signature or code is invalid.
+//
+// rotate reads (id, token) records as ndjson ({"id":..,"token":..,"key_ring":..})
+// or csv (id,token[,key_ring]) and re-encrypts every token from the old root
+// (-from-root-file, -from-root-env (DVX_FROM_ROOT_KEY) or -from-hsm-module with
+// DVX_FROM_HSM_PIN) to the new root. Instead of files, -plugin starts an
+// executable that provides the records on its stdout and receives the rotated
+// records on its stdin, e.g. to read and update them directly in a database.
+// With -checkpoint an interrupted rotation continues after the last saved
+// record.
+//
+// doctor checks the environment before a deployment or when onboarding a new
+// HSM: PKCS#11 module loadability, token discovery, mechanism support, login,
+// the root key, clock skew (for TOTP), entropy availability and the sanity of
+// the tearc cache configuration. Every finding includes a hint on how to fix
+// it.
+//
+// The root KeyPool is configured with the flags -root-file (a file containing
+// the base64 encoded 64 byte root key, as created by keygen), -root-env (the
+// name of an environment variable containing it, defaults to DVX_ROOT_KEY) or
+// -hsm-module for a PKCS#11 HSM, whose user pin is read from the environment
+// variable DVX_HSM_PIN.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errInvalid is returned by commands whose verification failed. It exits
+// with status 2, so scripts can distinguish it from other errors.
+var errInvalid = errors.New("invalid")
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string, stdin io.Reader, stdout io.Writer) error
+}
+
+var commands = []*command{
+	{"keygen", "create a new base64 encoded root key", runKeygen},
+	{"encrypt", "encrypt stdin", runEncrypt},
+	{"decrypt", "decrypt a ciphertext from stdin", runDecrypt},
+	{"sign", "sign stdin", runSign},
+	{"verify", "verify a signature of stdin", runVerify},
+	{"mac", "calculate a MAC tag of stdin", runMAC},
+	{"totp", "generate or verify TOTP secrets (totp generate|verify)", runTOTP},
+	{"rotate", "re-encrypt (id, token) records under a new root", runRotate},
+	{"doctor", "check the HSM, root key, clock, entropy and cache configuration", runDoctor},
+}
+
+func main() {
+	err := run(os.Args[1:], os.Stdin, os.Stdout)
+	switch {
+	case err == nil:
+	case errors.Is(err, errInvalid):
+		fmt.Fprintln(os.Stderr, "dvx: invalid")
+		os.Exit(2)
+	default:
+		fmt.Fprintf(os.Stderr, "dvx: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		usage(os.Stderr)
+		return errors.New("missing command")
+	}
+
+	for _, c := range commands {
+		if c.name == args[0] {
+			return c.run(args[1:], stdin, stdout)
+		}
+	}
+
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return nil
+	}
+
+	usage(os.Stderr)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: dvx <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, c := range commands {
+		fmt.Fprintf(w, "  %-8s %s\n", c.name, c.usage)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'dvx <command> -h' for the flags of a command.")
+}
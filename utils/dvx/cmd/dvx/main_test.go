@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func dvxRun(t *testing.T, stdin string, args ...string) (string, error) {
+	var stdout bytes.Buffer
+	err := run(args, strings.NewReader(stdin), &stdout)
+	return stdout.String(), err
+}
+
+func TestRun(t *testing.T) {
+	root, err := dvxRun(t, "", "keygen")
+	require.NoError(t, err)
+
+	rootFile := filepath.Join(t.TempDir(), "root")
+	require.NoError(t, os.WriteFile(rootFile, []byte(root), 0600))
+
+	ciphertext, err := dvxRun(t, "data", "encrypt", "-root-file", rootFile, "-keyring", "k")
+	require.NoError(t, err)
+
+	data, err := dvxRun(t, ciphertext, "decrypt", "-root-file", rootFile, "-keyring", "k")
+	require.NoError(t, err)
+	assert.Equal(t, "data", data)
+
+	signature, err := dvxRun(t, "message", "sign", "-root-file", rootFile, "-keyring", "k")
+	require.NoError(t, err)
+
+	_, err = dvxRun(t, "message", "verify", "-root-file", rootFile, "-keyring", "k", "-signature", strings.TrimSpace(signature))
+	require.NoError(t, err)
+
+	_, err = dvxRun(t, "other", "verify", "-root-file", rootFile, "-keyring", "k", "-signature", strings.TrimSpace(signature))
+	assert.True(t, errors.Is(err, errInvalid))
+
+	_, err = dvxRun(t, "data", "encrypt", "-root-file", rootFile)
+	assert.Error(t, err)
+}
+
+func TestRun_Doctor(t *testing.T) {
+	root, err := dvxRun(t, "", "keygen")
+	require.NoError(t, err)
+
+	rootFile := filepath.Join(t.TempDir(), "root")
+	require.NoError(t, os.WriteFile(rootFile, []byte(root), 0600))
+
+	out, err := dvxRun(t, "", "doctor", "-root-file", rootFile, "-time-url", "")
+	require.NoError(t, err)
+	assert.Contains(t, out, "root key is valid")
+
+	out, err = dvxRun(t, "", "doctor", "-root-file", rootFile, "-time-url", "", "-cache-shards", "7")
+	assert.True(t, errors.Is(err, errDoctor))
+	assert.Contains(t, out, "must be a multiple of -cache-shards")
+}
+
+func TestRun_Rotate(t *testing.T) {
+	dir := t.TempDir()
+	rootFiles := map[string]string{}
+	for _, name := range []string{"old", "new"} {
+		root, err := dvxRun(t, "", "keygen")
+		require.NoError(t, err)
+		rootFiles[name] = filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(rootFiles[name], []byte(root), 0600))
+	}
+
+	ciphertext, err := dvxRun(t, "data", "encrypt", "-root-file", rootFiles["old"], "-keyring", "k")
+	require.NoError(t, err)
+
+	rotated, err := dvxRun(t, "1,"+strings.TrimSpace(ciphertext)+"\n", "rotate", "-format", "csv", "-keyring", "k",
+		"-from-root-file", rootFiles["old"], "-root-file", rootFiles["new"])
+	require.NoError(t, err)
+
+	columns := strings.Split(strings.TrimSpace(rotated), ",")
+	require.Len(t, columns, 2)
+	assert.Equal(t, "1", columns[0])
+
+	data, err := dvxRun(t, columns[1], "decrypt", "-root-file", rootFiles["new"], "-keyring", "k")
+	require.NoError(t, err)
+	assert.Equal(t, "data", data)
+}
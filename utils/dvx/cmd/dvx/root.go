@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/harwoeck/liblog/contract"
+
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/hsm"
+	"azoo.dev/utils/dvx/liblog"
+)
+
+const (
+	envRootKey = "DVX_ROOT_KEY"
+	envHSMPin  = "DVX_HSM_PIN"
+)
+
+// rootFlags configure the root KeyPool of the Protocol used by a command.
+type rootFlags struct {
+	file   string
+	env    string
+	pinEnv string
+
+	hsmModule   string
+	hsmLabel    string
+	hsmKeyID    string
+	hsmKeyLabel string
+}
+
+func addRootFlags(fs *flag.FlagSet) *rootFlags {
+	return addPrefixedRootFlags(fs, "", envRootKey, envHSMPin)
+}
+
+// addPrefixedRootFlags adds the root flags with prefix, so a command can use
+// multiple roots (e.g. "-from-root-file" and "-root-file").
+func addPrefixedRootFlags(fs *flag.FlagSet, prefix string, rootEnv string, pinEnv string) *rootFlags {
+	r := &rootFlags{pinEnv: pinEnv}
+	fs.StringVar(&r.file, prefix+"root-file", "", "path to a file containing the base64 encoded 64 byte root key")
+	fs.StringVar(&r.env, prefix+"root-env", rootEnv, "name of the environment variable containing the base64 encoded 64 byte root key. Ignored if -"+prefix+"root-file or -"+prefix+"hsm-module is set")
+	fs.StringVar(&r.hsmModule, prefix+"hsm-module", "", "path to the PKCS#11 module. The user pin is read from "+pinEnv)
+	fs.StringVar(&r.hsmLabel, prefix+"hsm-label", "dvx", "label of the HSM token")
+	fs.StringVar(&r.hsmKeyID, prefix+"hsm-key-id", "dvx_root", "id of the HSM root key")
+	fs.StringVar(&r.hsmKeyLabel, prefix+"hsm-key-label", "dvx_root", "label of the HSM root key")
+	return r
+}
+
+// keyPool creates the root KeyPool. The caller must close it.
+func (r *rootFlags) keyPool(log dvx.Logger) (dvx.KeyPool, error) {
+	if r.hsmModule != "" {
+		return hsm.New(&hsm.Config{
+			Module:       r.hsmModule,
+			Label:        r.hsmLabel,
+			UserPin:      os.Getenv(r.pinEnv),
+			RootKeyID:    r.hsmKeyID,
+			RootKeyLabel: r.hsmKeyLabel,
+		}, log)
+	}
+
+	var encoded string
+	if r.file != "" {
+		buf, err := os.ReadFile(r.file)
+		if err != nil {
+			return nil, err
+		}
+		encoded = string(buf)
+	} else {
+		encoded = os.Getenv(r.env)
+		if encoded == "" {
+			return nil, fmt.Errorf("no root key: set the root file, HSM module or the environment variable %s", r.env)
+		}
+	}
+
+	root, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("root key is not valid base64: %w", err)
+	}
+	if len(root) != 64 {
+		return nil, fmt.Errorf("root key must be 64 bytes, got %d", len(root))
+	}
+
+	return dvx.WrapDVXAsKeyPool(dvx.DV1{}, root, log), nil
+}
+
+// protocol creates a Protocol with the root KeyPool. The returned close
+// function closes the KeyPool.
+func (r *rootFlags) protocol() (p *dvx.Protocol, close func(), err error) {
+	pool, err := r.keyPool(liblog.Wrap(contract.MustNewStd()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool}), func() {
+		_ = pool.Close()
+	}, nil
+}
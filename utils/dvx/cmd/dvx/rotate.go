@@ -0,0 +1,153 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"azoo.dev/utils/dvx/rotate"
+)
+
+const (
+	envFromRootKey = "DVX_FROM_ROOT_KEY"
+	envFromHSMPin  = "DVX_FROM_HSM_PIN"
+)
+
+func runRotate(args []string, stdin io.Reader, stdout io.Writer) error {
+	fs := flag.NewFlagSet("rotate", flag.ContinueOnError)
+	from := addPrefixedRootFlags(fs, "from-", envFromRootKey, envFromHSMPin)
+	to := addRootFlags(fs)
+	keyRing := fs.String("keyring", "", "keyRing of all records without their own key_ring")
+	format := fs.String("format", "ndjson", `format of input and output: "ndjson" or "csv"`)
+	in := fs.String("in", "-", `input file. "-" reads from stdin`)
+	out := fs.String("out", "-", `output file. "-" writes to stdout. Appended to if -checkpoint is set`)
+	plugin := fs.String("plugin", "", "executable that writes records as ndjson to its stdout and reads the rotated records as ndjson from its stdin. Replaces -in, -out and -format")
+	checkpoint := fs.String("checkpoint", "", "file the progress is saved to. A rotation with an existing checkpoint continues after the last saved record")
+	checkpointEvery := fs.Int64("checkpoint-every", 1000, "amount of records after which the progress is saved")
+	dryRun := fs.Bool("dry-run", false, "only check that all records can be rotated, without writing them")
+	skipErrors := fs.Bool("skip-errors", false, "report records that can't be rotated on stderr and continue")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	fromP, closeFrom, err := from.protocol()
+	if err != nil {
+		return fmt.Errorf("old root: %w", err)
+	}
+	defer closeFrom()
+
+	toP, closeTo, err := to.protocol()
+	if err != nil {
+		return fmt.Errorf("new root: %w", err)
+	}
+	defer closeTo()
+
+	config := &rotate.Config{
+		From:            fromP,
+		To:              toP,
+		KeyRing:         *keyRing,
+		DryRun:          *dryRun,
+		CheckpointEvery: *checkpointEvery,
+	}
+	if *checkpoint != "" {
+		config.Checkpoint = rotate.NewFileCheckpoint(*checkpoint)
+	}
+	if *skipErrors {
+		config.OnError = func(record rotate.Record, err error) error {
+			fmt.Fprintf(os.Stderr, "dvx: skipped: %v\n", err)
+			return nil
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var source rotate.Source
+	var sink rotate.Sink
+
+	if *plugin != "" {
+		cmd := exec.Command(*plugin)
+		cmd.Stderr = os.Stderr
+		pluginIn, err := cmd.StdinPipe()
+		if err != nil {
+			return err
+		}
+		pluginOut, err := cmd.StdoutPipe()
+		if err != nil {
+			return err
+		}
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("unable to start plugin: %w", err)
+		}
+
+		source = rotate.NewNDJSONSource(pluginOut)
+		sink = rotate.NewNDJSONSink(pluginIn)
+		defer func() {
+			_ = pluginIn.Close()
+			_ = cmd.Wait()
+		}()
+	} else {
+		r := stdin
+		if *in != "-" {
+			f, err := os.Open(*in)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r = f
+		}
+
+		w := stdout
+		if *out != "-" && !*dryRun {
+			flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+			if *checkpoint != "" {
+				flags = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+			}
+			f, err := os.OpenFile(*out, flags, 0600)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			w = f
+		}
+
+		switch *format {
+		case "ndjson":
+			source, sink = rotate.NewNDJSONSource(r), rotate.NewNDJSONSink(w)
+		case "csv":
+			source, sink = rotate.NewCSVSource(r), rotate.NewCSVSink(w)
+		default:
+			return fmt.Errorf("unknown format %q", *format)
+		}
+	}
+
+	result, err := rotate.Run(ctx, source, sink, config)
+
+	// write buffered records, even if Run failed, as they were rotated
+	// successfully
+	if f, ok := sink.(rotate.Flusher); ok {
+		if flushErr := f.Flush(); flushErr != nil && err == nil {
+			err = flushErr
+		}
+	}
+
+	if result != nil {
+		fmt.Fprintf(os.Stderr, "dvx: rotated %d, skipped %d (checkpoint), failed %d", result.Rotated, result.Skipped, result.Failed)
+		if *dryRun {
+			fmt.Fprint(os.Stderr, " (dry run)")
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+	if err != nil {
+		return err
+	}
+	if result.Failed > 0 {
+		return errors.New("not all records were rotated")
+	}
+	return nil
+}
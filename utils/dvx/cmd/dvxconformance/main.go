@@ -0,0 +1,212 @@
+// Command dvxconformance runs dvx operations for cross-language conformance
+// test suites, so implementations of dvx in other languages can be validated
+// against this reference implementation. It reads one JSON request per line
+// from stdin and writes one JSON response per request to stdout, in order:
+//
+//	{"id":"1","op":"encrypt","root":"<base64>","key_ring":"users","data":"ZGF0YQ=="}
+//	{"id":"1","output":"dv1.enc.…"}
+//
+// Byte fields (root, data, footer and public_key) are standard base64
+// encoded, root is the 64 byte root key of WrapDVXAsKeyPool. dvx strings
+// (ciphertexts, signatures, tags, tokens and totp-ids) are passed as input
+// and returned as output. Operations:
+//
+//	version                                      -> output (dvx.Version)
+//	encode          type_prefix, data, footer    -> output
+//	decode          input                        -> version, type_prefix, data, footer
+//	encrypt         root, key_ring, data, footer -> output
+//	decrypt         root, key_ring, input        -> data
+//	create_sign_key root, key_ring               -> public_key
+//	sign            root, key_ring, data, footer -> output
+//	verify          root, key_ring, data, input  -> valid
+//	verify_pk       public_key, data, input      -> valid
+//	mac             root, key_ring, data, footer -> output
+//	tokenize        root, key_ring, data         -> output
+//	detokenize      root, key_ring, input        -> data
+//	verify_totp     root, key_ring, input, account_id, code -> valid
+//
+// Failed operations return error and error_class (see dvx.ErrorClass)
+// instead, and the next request is processed. Malformed JSON aborts with
+// exit status 1.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"azoo.dev/utils/dvx"
+)
+
+// request is a single operation read from stdin.
+type request struct {
+	ID         string `json:"id"`
+	Op         string `json:"op"`
+	Root       []byte `json:"root,omitempty"`
+	KeyRing    string `json:"key_ring,omitempty"`
+	Data       []byte `json:"data,omitempty"`
+	Footer     []byte `json:"footer,omitempty"`
+	Input      string `json:"input,omitempty"`
+	PublicKey  []byte `json:"public_key,omitempty"`
+	TypePrefix string `json:"type_prefix,omitempty"`
+	AccountID  string `json:"account_id,omitempty"`
+	Code       string `json:"code,omitempty"`
+}
+
+// response is the result of a request written to stdout.
+type response struct {
+	ID         string `json:"id"`
+	Output     string `json:"output,omitempty"`
+	Data       []byte `json:"data,omitempty"`
+	Valid      *bool  `json:"valid,omitempty"`
+	PublicKey  []byte `json:"public_key,omitempty"`
+	Version    string `json:"version,omitempty"`
+	TypePrefix string `json:"type_prefix,omitempty"`
+	Footer     []byte `json:"footer,omitempty"`
+	Error      string `json:"error,omitempty"`
+	ErrorClass string `json:"error_class,omitempty"`
+}
+
+func main() {
+	if err := run(os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "dvxconformance: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(stdin io.Reader, stdout io.Writer) error {
+	s := &server{
+		public:    dvx.NewProtocol(nil),
+		protocols: make(map[string]*dvx.Protocol),
+	}
+	defer s.close()
+
+	dec := json.NewDecoder(stdin)
+	enc := json.NewEncoder(stdout)
+	for {
+		var req request
+		if err := dec.Decode(&req); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("malformed request: %w", err)
+		}
+
+		res, err := s.handle(&req)
+		if err != nil {
+			res = &response{Error: err.Error(), ErrorClass: dvx.ErrorClass(err)}
+		}
+		res.ID = req.ID
+		if err = enc.Encode(res); err != nil {
+			return err
+		}
+	}
+}
+
+// server caches a Protocol per root key, as suites usually send many
+// requests for the same root. public is a Protocol without KeyPool for
+// verify_pk.
+type server struct {
+	public    *dvx.Protocol
+	protocols map[string]*dvx.Protocol
+	pools     []dvx.KeyPool
+}
+
+func (s *server) protocol(root []byte) (*dvx.Protocol, error) {
+	if len(root) != 64 {
+		return nil, fmt.Errorf("root must be 64 bytes, got %d", len(root))
+	}
+	if p, ok := s.protocols[string(root)]; ok {
+		return p, nil
+	}
+
+	pool := dvx.WrapDVXAsKeyPool(dvx.DV1{}, root, nil)
+	p := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool})
+	s.protocols[string(root)] = p
+	s.pools = append(s.pools, pool)
+	return p, nil
+}
+
+func (s *server) close() {
+	for _, pool := range s.pools {
+		_ = pool.Close()
+	}
+}
+
+// rootOps are the operations that derive keys from the root of the request.
+var rootOps = map[string]bool{
+	"encrypt":         true,
+	"decrypt":         true,
+	"create_sign_key": true,
+	"sign":            true,
+	"verify":          true,
+	"mac":             true,
+	"tokenize":        true,
+	"detokenize":      true,
+	"verify_totp":     true,
+}
+
+func (s *server) handle(req *request) (*response, error) {
+	switch req.Op {
+	case "version":
+		return &response{Output: dvx.Version}, nil
+	case "encode":
+		return &response{Output: dvx.EncodeWithFooter(dvx.TypePrefix(req.TypePrefix), req.Data, req.Footer)}, nil
+	case "decode":
+		version, typePrefix, data, footer, err := dvx.DecodeWithFooter(req.Input)
+		if err != nil {
+			return nil, err
+		}
+		return &response{Version: version, TypePrefix: string(typePrefix), Data: data, Footer: footer}, nil
+	case "verify_pk":
+		valid, err := s.public.VerifyPK(req.PublicKey, req.Data, req.Input)
+		if err != nil {
+			return nil, err
+		}
+		return &response{Valid: &valid}, nil
+	}
+	if !rootOps[req.Op] {
+		return nil, fmt.Errorf("unknown op %q", req.Op)
+	}
+
+	p, err := s.protocol(req.Root)
+	if err != nil {
+		return nil, err
+	}
+	res := &response{}
+	switch req.Op {
+	case "encrypt":
+		if len(req.Footer) == 0 {
+			// EncryptWithFooter always uses dv2, Encrypt uses dvx.Version
+			res.Output, err = p.Encrypt(req.KeyRing, req.Data)
+		} else {
+			res.Output, err = p.EncryptWithFooter(req.KeyRing, req.Data, req.Footer)
+		}
+	case "decrypt":
+		res.Data, err = p.Decrypt(req.KeyRing, req.Input)
+	case "create_sign_key":
+		res.PublicKey, err = p.CreateSignKey(req.KeyRing)
+	case "sign":
+		res.Output, _, err = p.SignWithFooter(req.KeyRing, req.Data, req.Footer)
+	case "verify":
+		var valid bool
+		valid, err = p.Verify(req.KeyRing, req.Data, req.Input)
+		res.Valid = &valid
+	case "mac":
+		res.Output, err = p.MACWithFooter(req.KeyRing, req.Data, req.Footer)
+	case "tokenize":
+		res.Output, err = p.Tokenize(req.KeyRing, string(req.Data))
+	case "detokenize":
+		var value string
+		value, err = p.Detokenize(req.KeyRing, req.Input)
+		res.Data = []byte(value)
+	case "verify_totp":
+		var valid bool
+		valid, err = p.VerifyTOTP(req.KeyRing, req.Input, req.AccountID, req.Code)
+		res.Valid = &valid
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func conformance(t *testing.T, requests ...map[string]interface{}) []response {
+	var stdin bytes.Buffer
+	for _, req := range requests {
+		require.NoError(t, json.NewEncoder(&stdin).Encode(req))
+	}
+	var stdout bytes.Buffer
+	require.NoError(t, run(&stdin, &stdout))
+
+	var responses []response
+	dec := json.NewDecoder(&stdout)
+	for dec.More() {
+		var res response
+		require.NoError(t, dec.Decode(&res))
+		responses = append(responses, res)
+	}
+	require.Len(t, responses, len(requests))
+	return responses
+}
+
+func TestRun(t *testing.T) {
+	root := bytes.Repeat([]byte{1}, 64)
+	res := conformance(t,
+		map[string]interface{}{"id": "1", "op": "encrypt", "root": root, "key_ring": "users", "data": []byte("data")},
+		map[string]interface{}{"id": "2", "op": "create_sign_key", "root": root, "key_ring": "users"},
+		map[string]interface{}{"id": "3", "op": "sign", "root": root, "key_ring": "users", "data": []byte("message")},
+		map[string]interface{}{"id": "4", "op": "mac", "root": root, "key_ring": "users", "data": []byte("message")},
+		map[string]interface{}{"id": "5", "op": "unknown"},
+		map[string]interface{}{"id": "6", "op": "decode", "input": "dv9.enc.AA"},
+	)
+	assert.Equal(t, "1", res[0].ID)
+	assert.True(t, strings.HasPrefix(res[0].Output, "dv1.enc."))
+	assert.Len(t, res[1].PublicKey, 32)
+	assert.Contains(t, res[4].Error, "unknown op")
+	assert.Equal(t, "invalid_format", res[5].ErrorClass)
+
+	// outputs of one run are inputs of the next, like those of another
+	// implementation
+	res = conformance(t,
+		map[string]interface{}{"id": "7", "op": "decrypt", "root": root, "key_ring": "users", "input": res[0].Output},
+		map[string]interface{}{"id": "8", "op": "verify_pk", "public_key": res[1].PublicKey, "data": []byte("message"), "input": res[2].Output},
+		map[string]interface{}{"id": "9", "op": "verify", "root": root, "key_ring": "other", "data": []byte("message"), "input": res[2].Output},
+		map[string]interface{}{"id": "10", "op": "mac", "root": root, "key_ring": "users", "data": []byte("message")},
+		map[string]interface{}{"id": "11", "op": "decrypt", "root": root, "key_ring": "other", "input": res[0].Output},
+	)
+	assert.Equal(t, []byte("data"), res[0].Data)
+	require.NotNil(t, res[1].Valid)
+	assert.True(t, *res[1].Valid)
+	require.NotNil(t, res[2].Valid)
+	assert.False(t, *res[2].Valid)
+	assert.Equal(t, "authentication", res[4].ErrorClass)
+
+	assert.Error(t, run(strings.NewReader("{"), &bytes.Buffer{}))
+}
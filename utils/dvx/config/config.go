@@ -0,0 +1,341 @@
+// Package config builds a complete dvx.Protocol (root KeyPool, optional tearc
+// cache) from a single YAML or JSON configuration file, that can be overridden
+// with environment variables. Secrets (root key and HSM user pin) are never
+// part of the configuration itself, but referenced with a Secret.
+//
+// A configuration with an HSM root and a tearc cache:
+//
+//   root:
+//     type: hsm
+//     hsm:
+//       module: /usr/lib/softhsm/libsofthsm2.so
+//       label: dvx
+//       user_pin: env:DVX_HSM_PIN
+//       root_key_id: dvx_root
+//       root_key_label: dvx_root
+//   cache:
+//     size: 65536
+//     shards: 64
+//     bucket_min_tick: 5s
+//     bucket_max_tick: 20s
+//     alive_time: 1m
+//     max_lifetime: 1h
+//
+// Audit events of the root KeyPool ("loaded key") are written to the "audit"
+// child of the logger passed to Protocol.
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/hsm"
+	"azoo.dev/utils/dvx/tearc"
+)
+
+const (
+	// RootDVX derives keys with dvx.WrapDVXAsKeyPool from a 64 byte root key.
+	RootDVX = "dvx"
+	// RootHSM derives keys with a PKCS#11 HSM (azoo.dev/utils/dvx/hsm).
+	RootHSM = "hsm"
+)
+
+// Config is the configuration of a dvx.Protocol.
+type Config struct {
+	// Root configures the root KeyPool.
+	Root Root `json:"root" yaml:"root"`
+	// Cache configures the tearc caching layer in front of the root KeyPool.
+	// If nil every key is derived by the root KeyPool.
+	Cache *Cache `json:"cache,omitempty" yaml:"cache,omitempty"`
+}
+
+// Root configures the root KeyPool.
+type Root struct {
+	// Type is either RootDVX or RootHSM. Defaults to RootDVX.
+	Type string `json:"type" yaml:"type"`
+	// Key references the base64 encoded 64 byte root key of RootDVX. Defaults
+	// to "env:DVX_ROOT_KEY".
+	Key Secret `json:"key,omitempty" yaml:"key,omitempty"`
+	// HSM configures RootHSM.
+	HSM *HSM `json:"hsm,omitempty" yaml:"hsm,omitempty"`
+}
+
+// HSM configures a RootHSM. See (azoo.dev/utils/dvx/hsm).Config.
+type HSM struct {
+	// URI is a PKCS#11 URI (RFC 7512) that replaces Module (module-path),
+	// Label (token), RootKeyID (id) and RootKeyLabel (object), e.g. as
+	// emitted by provisioning tooling. It can't contain a pin-value, the pin
+	// is read from its pin-source or UserPin. Without module-path the module
+	// is read from the environment variable PKCS11_MODULE_PATH. For example:
+	// "pkcs11:token=dvx;object=dvx_root;id=dvx_root_1?module-path=/usr/lib/softhsm/libsofthsm2.so"
+	URI string `json:"uri,omitempty" yaml:"uri,omitempty"`
+	// Module is the path to your PKCS#11 module. For example:
+	// "/usr/lib/softhsm/libsofthsm2.so"
+	Module string `json:"module" yaml:"module"`
+	// Label is the label of the token. For example: "dvx"
+	Label string `json:"label" yaml:"label"`
+	// UserPin references the pin of your user (not security officer!).
+	// Defaults to "env:DVX_HSM_PIN".
+	UserPin Secret `json:"user_pin,omitempty" yaml:"user_pin,omitempty"`
+	// RootKeyID is the ID of your root key. For example: "dvx_root"
+	RootKeyID string `json:"root_key_id" yaml:"root_key_id"`
+	// RootKeyLabel is the label of your root key. For example: "dvx_root"
+	RootKeyLabel string `json:"root_key_label" yaml:"root_key_label"`
+	// RootKeySelection selects the root key if multiple keys have the
+	// RootKeyLabel: "" (unique), "id", "newest" or "generation". Optional.
+	// For example: "newest"
+	RootKeySelection string `json:"root_key_selection,omitempty" yaml:"root_key_selection,omitempty"`
+	// RootKeyGeneration is the generation of the root key for the selection
+	// "generation". For example: 2
+	RootKeyGeneration uint64 `json:"root_key_generation,omitempty" yaml:"root_key_generation,omitempty"`
+	// ReadOnly opens read-only sessions, for user pins that may only use the
+	// root key. Optional. For example: true
+	ReadOnly bool `json:"read_only,omitempty" yaml:"read_only,omitempty"`
+	// RootKeyRecheckInterval is the time after which the handle of the root
+	// key is checked again. Optional. For example: 5m
+	RootKeyRecheckInterval time.Duration `json:"root_key_recheck_interval,omitempty" yaml:"root_key_recheck_interval,omitempty"`
+	// MaxSignPartSize is the maximum size of the data passed to the HSM in
+	// a single call, longer derivation inputs are signed in parts. Optional.
+	// For example: 1024
+	MaxSignPartSize int `json:"max_sign_part_size,omitempty" yaml:"max_sign_part_size,omitempty"`
+}
+
+// Cache configures the tearc caching layer. See
+// (azoo.dev/utils/dvx/tearc).Config. Durations are strings like "5s" or "1m".
+type Cache struct {
+	// Size is the amount of cached keys. For example: 65536
+	Size int `json:"size" yaml:"size"`
+	// Shards is the amount of shards. Size must be a multiple of it. For
+	// example: 64
+	Shards int `json:"shards" yaml:"shards"`
+	// BucketMinTick is the minimum amount of time between cache reaper runs.
+	// For example: 5s
+	BucketMinTick time.Duration `json:"bucket_min_tick" yaml:"bucket_min_tick"`
+	// BucketMaxTick is the maximum amount of time between cache reaper runs.
+	// For example: 20s
+	BucketMaxTick time.Duration `json:"bucket_max_tick" yaml:"bucket_max_tick"`
+	// AliveTime specifies how long cached keys stay alive at maximum. For
+	// example: 1m
+	AliveTime time.Duration `json:"alive_time" yaml:"alive_time"`
+	// MaxLifetime specifies how long cached keys stay alive at maximum, even
+	// if they are used constantly. Optional. For example: 1h
+	MaxLifetime time.Duration `json:"max_lifetime,omitempty" yaml:"max_lifetime,omitempty"`
+	// Bypass lists keyRing prefixes whose keys are never cached. Optional.
+	// For example: ["unwrap/"]
+	Bypass []string `json:"bypass,omitempty" yaml:"bypass,omitempty"`
+	// StatsPrefixes lists keyRing prefixes whose derivations are counted
+	// separately. Optional. For example: ["users/", "totp/"]
+	StatsPrefixes []string `json:"stats_prefixes,omitempty" yaml:"stats_prefixes,omitempty"`
+}
+
+// Validate checks config for missing and invalid values, without resolving
+// any Secret.
+func (c *Config) Validate() error {
+	switch c.Root.Type {
+	case RootDVX:
+		if err := c.Root.Key.validate(); err != nil {
+			return fmt.Errorf("config: root.key: %w", err)
+		}
+	case RootHSM:
+		h := c.Root.HSM
+		if h == nil {
+			return errors.New("config: root.hsm is required for root type \"hsm\"")
+		}
+		for _, field := range []struct{ name, value string }{
+			{"module", h.Module},
+			{"label", h.Label},
+			{"root_key_id", h.RootKeyID},
+			{"root_key_label", h.RootKeyLabel},
+		} {
+			if h.URI != "" && field.value != "" {
+				return fmt.Errorf("config: root.hsm.%s cannot be combined with root.hsm.uri", field.name)
+			}
+			if h.URI == "" && field.value == "" {
+				return fmt.Errorf("config: root.hsm.%s is required", field.name)
+			}
+		}
+		if strings.Contains(h.URI, "pin-value=") {
+			return errors.New("config: root.hsm.uri cannot contain a pin-value, use its pin-source or root.hsm.user_pin")
+		}
+		if err := h.UserPin.validate(); err != nil {
+			return fmt.Errorf("config: root.hsm.user_pin: %w", err)
+		}
+		switch hsm.KeySelection(h.RootKeySelection) {
+		case hsm.SelectUnique, hsm.SelectByID, hsm.SelectNewest:
+		case hsm.SelectGeneration:
+			if h.RootKeyGeneration == 0 {
+				return errors.New("config: root.hsm.root_key_generation is required for root_key_selection \"generation\"")
+			}
+		default:
+			return fmt.Errorf("config: unknown root.hsm.root_key_selection %q (supported: %q, %q, %q)", h.RootKeySelection, hsm.SelectByID, hsm.SelectNewest, hsm.SelectGeneration)
+		}
+		if h.RootKeyRecheckInterval < 0 {
+			return errors.New("config: root.hsm.root_key_recheck_interval must not be negative")
+		}
+		if h.MaxSignPartSize < 0 {
+			return errors.New("config: root.hsm.max_sign_part_size must not be negative")
+		}
+	default:
+		return fmt.Errorf("config: unknown root type %q (supported: %q, %q)", c.Root.Type, RootDVX, RootHSM)
+	}
+
+	if cache := c.Cache; cache != nil {
+		switch {
+		case cache.Size <= 0 || cache.Shards <= 0:
+			return errors.New("config: cache.size and cache.shards must be greater than zero")
+		case cache.Size%cache.Shards != 0:
+			return fmt.Errorf("config: cache.size (%d) must be a multiple of cache.shards (%d)", cache.Size, cache.Shards)
+		case cache.BucketMinTick <= 0 || cache.BucketMinTick >= cache.BucketMaxTick:
+			return fmt.Errorf("config: cache.bucket_min_tick (%s) must be greater than zero and less than cache.bucket_max_tick (%s)", cache.BucketMinTick, cache.BucketMaxTick)
+		case cache.AliveTime <= 0:
+			return errors.New("config: cache.alive_time must be greater than zero")
+		case cache.MaxLifetime < 0:
+			return errors.New("config: cache.max_lifetime must not be negative")
+		}
+	}
+
+	return nil
+}
+
+// setDefaults fills in all optional values.
+func (c *Config) setDefaults() {
+	if c.Root.Type == "" {
+		c.Root.Type = RootDVX
+	}
+	if c.Root.Key == "" {
+		c.Root.Key = "env:DVX_ROOT_KEY"
+	}
+	if c.Root.HSM != nil && c.Root.HSM.UserPin == "" {
+		c.Root.HSM.UserPin = "env:DVX_HSM_PIN"
+	}
+}
+
+// KeyPool creates the root KeyPool and wraps it with the tearc cache, if one
+// is configured. The caller must close the returned KeyPool.
+func (c *Config) KeyPool(log dvx.Logger) (dvx.KeyPool, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var pool dvx.KeyPool
+	switch c.Root.Type {
+	case RootDVX:
+		encoded, err := c.Root.Key.Resolve()
+		if err != nil {
+			return nil, fmt.Errorf("config: root.key: %w", err)
+		}
+		root, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("config: root.key is not valid base64: %w", err)
+		}
+		if len(root) != 64 {
+			return nil, fmt.Errorf("config: root.key must be 64 bytes, got %d", len(root))
+		}
+		pool = dvx.WrapDVXAsKeyPool(dvx.DV1{}, root, log)
+	case RootHSM:
+		var pin string
+		var err error
+		// the pin-source of the URI replaces user_pin
+		if !strings.Contains(c.Root.HSM.URI, "pin-source=") {
+			pin, err = c.Root.HSM.UserPin.Resolve()
+			if err != nil {
+				return nil, fmt.Errorf("config: root.hsm.user_pin: %w", err)
+			}
+		}
+		pool, err = hsm.New(&hsm.Config{
+			URI:               c.Root.HSM.URI,
+			Module:            c.Root.HSM.Module,
+			Label:             c.Root.HSM.Label,
+			UserPin:           pin,
+			RootKeyID:         c.Root.HSM.RootKeyID,
+			RootKeyLabel:      c.Root.HSM.RootKeyLabel,
+			KeySelection:      hsm.KeySelection(c.Root.HSM.RootKeySelection),
+			RootKeyGeneration: c.Root.HSM.RootKeyGeneration,
+			ReadOnly:          c.Root.HSM.ReadOnly,
+			RecheckInterval:   c.Root.HSM.RootKeyRecheckInterval,
+			MaxSignPartSize:   c.Root.HSM.MaxSignPartSize,
+		}, log)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.Cache == nil {
+		return pool, nil
+	}
+
+	cached, err := tearc.New(&tearc.Config{
+		Size:          c.Cache.Size,
+		Shards:        c.Cache.Shards,
+		BucketMinTick: c.Cache.BucketMinTick,
+		BucketMaxTick: c.Cache.BucketMaxTick,
+		AliveTime:     c.Cache.AliveTime,
+		MaxLifetime:   c.Cache.MaxLifetime,
+		Bypass:        c.Cache.Bypass,
+		StatsPrefixes: c.Cache.StatsPrefixes,
+	}, pool, log)
+	if err != nil {
+		_ = pool.Close()
+		return nil, err
+	}
+	return cached, nil
+}
+
+// Protocol creates a dvx.Protocol that uses the KeyPool created by KeyPool
+// for dvx.Version. The returned io.Closer closes the KeyPool.
+func (c *Config) Protocol(log dvx.Logger) (*dvx.Protocol, io.Closer, error) {
+	pool, err := c.KeyPool(log)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool}), pool, nil
+}
+
+// Secret references a secret value outside of the configuration. Supported
+// references are "env:NAME" for the environment variable NAME and
+// "file:PATH" for the content of the file at PATH (e.g. a mounted Kubernetes
+// secret). Secrets can't be part of the configuration itself.
+type Secret string
+
+func (s Secret) validate() error {
+	switch {
+	case strings.HasPrefix(string(s), "env:") && len(s) > len("env:"):
+		return nil
+	case strings.HasPrefix(string(s), "file:") && len(s) > len("file:"):
+		return nil
+	default:
+		return errors.New(`secrets must be referenced with "env:NAME" or "file:PATH"`)
+	}
+}
+
+// Resolve returns the referenced secret with surrounding whitespace removed.
+// It fails if the secret is empty.
+func (s Secret) Resolve() (string, error) {
+	if err := s.validate(); err != nil {
+		return "", err
+	}
+
+	var value string
+	if name := strings.TrimPrefix(string(s), "env:"); name != string(s) {
+		value = os.Getenv(name)
+	} else {
+		buf, err := os.ReadFile(strings.TrimPrefix(string(s), "file:"))
+		if err != nil {
+			return "", err
+		}
+		value = string(buf)
+	}
+
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("secret %q is empty", string(s))
+	}
+	return value, nil
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	yamlConfig := `
+root:
+  type: hsm
+  hsm:
+    module: /usr/lib/softhsm/libsofthsm2.so
+    label: dvx
+    root_key_id: dvx_root
+    root_key_label: dvx_root
+cache:
+  size: 64
+  shards: 4
+  bucket_min_tick: 5s
+  bucket_max_tick: 20s
+  alive_time: 1m
+  max_lifetime: 1h
+`
+	jsonConfig := `{"root":{"type":"hsm","hsm":{"module":"/usr/lib/softhsm/libsofthsm2.so","label":"dvx","root_key_id":"dvx_root","root_key_label":"dvx_root"}},
+"cache":{"size":64,"shards":4,"bucket_min_tick":"5s","bucket_max_tick":"20s","alive_time":"1m","max_lifetime":"1h"}}`
+
+	for _, data := range []string{yamlConfig, jsonConfig} {
+		c, err := Parse([]byte(data))
+		require.NoError(t, err)
+		assert.Equal(t, RootHSM, c.Root.Type)
+		assert.Equal(t, Secret("env:DVX_HSM_PIN"), c.Root.HSM.UserPin)
+		assert.Equal(t, &Cache{64, 4, 5 * time.Second, 20 * time.Second, time.Minute, time.Hour, nil, nil}, c.Cache)
+	}
+}
+
+func TestParse_URI(t *testing.T) {
+	c, err := Parse([]byte("root: {type: hsm, hsm: {uri: 'pkcs11:token=dvx;object=dvx_root;id=dvx_root?pin-source=/run/pin'}}"))
+	require.NoError(t, err)
+	assert.Equal(t, "pkcs11:token=dvx;object=dvx_root;id=dvx_root?pin-source=/run/pin", c.Root.HSM.URI)
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"unknown field":  "root: {type: dvx, pin: 1234}",
+		"unknown type":   "root: {type: kms}",
+		"literal secret": "root: {key: 7GR61MdEDy0kMPkzhXB9xQ}",
+		"missing hsm":    "root: {type: hsm}",
+		"key selection":  "root: {type: hsm, hsm: {module: m, label: l, root_key_id: i, root_key_label: l, root_key_selection: oldest}}",
+		"key generation": "root: {type: hsm, hsm: {module: m, label: l, root_key_id: i, root_key_label: l, root_key_selection: generation}}",
+		"key recheck":    "root: {type: hsm, hsm: {module: m, label: l, root_key_id: i, root_key_label: l, root_key_recheck_interval: -1m}}",
+		"sign part size": "root: {type: hsm, hsm: {module: m, label: l, root_key_id: i, root_key_label: l, max_sign_part_size: -1}}",
+		"uri and label":  "root: {type: hsm, hsm: {uri: 'pkcs11:token=dvx;object=o;id=i', label: l}}",
+		"uri pin-value":  "root: {type: hsm, hsm: {uri: 'pkcs11:token=dvx;object=o;id=i?pin-value=1234'}}",
+		"cache shards":   "cache: {size: 10, shards: 3, bucket_min_tick: 1s, bucket_max_tick: 2s, alive_time: 1m}",
+		"cache ticks":    "cache: {size: 8, shards: 2, bucket_min_tick: 2s, bucket_max_tick: 1s, alive_time: 1m}",
+		"cache lifetime": "cache: {size: 8, shards: 2, bucket_min_tick: 1s, bucket_max_tick: 2s, alive_time: 1m, max_lifetime: -1h}",
+	} {
+		_, err := Parse([]byte(data))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	root := make([]byte, 64)
+	_, err := rand.Read(root)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	keyFile := filepath.Join(dir, "root.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(root)+"\n"), 0600))
+	configFile := filepath.Join(dir, "dvx.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("root:\n  key: env:DVX_CONFIG_TEST_UNSET\n"), 0600))
+
+	// environment variables override the file
+	for key, value := range map[string]string{
+		"DVX_ROOT_KEY_REF":     "file:" + keyFile,
+		"DVX_CACHE_SIZE":       "64",
+		"DVX_CACHE_SHARDS":     "4",
+		"DVX_CACHE_MIN_TICK":   "1s",
+		"DVX_CACHE_MAX_TICK":   "2s",
+		"DVX_CACHE_ALIVE_TIME": "1m",
+	} {
+		require.NoError(t, os.Setenv(key, value))
+		defer os.Unsetenv(key)
+	}
+
+	c, err := Load(configFile)
+	require.NoError(t, err)
+	assert.Equal(t, RootDVX, c.Root.Type)
+	assert.Equal(t, Secret("file:"+keyFile), c.Root.Key)
+
+	p, closer, err := c.Protocol(nil)
+	require.NoError(t, err)
+	defer closer.Close()
+
+	ciphertext, err := p.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	data, err := p.Decrypt("keyring", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+}
+
+func TestSecret_Resolve(t *testing.T) {
+	_, err := Secret("env:DVX_CONFIG_TEST_UNSET").Resolve()
+	assert.Error(t, err)
+	_, err = Secret("1234").Resolve()
+	assert.Error(t, err)
+}
@@ -0,0 +1,18 @@
+module azoo.dev/utils/dvx/config
+
+go 1.16
+
+require (
+	azoo.dev/utils/dvx v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx/hsm v0.0.0-00010101000000-000000000000
+	azoo.dev/utils/dvx/tearc v0.0.0-00010101000000-000000000000
+	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+replace (
+	azoo.dev/utils/dvx => ../
+	azoo.dev/utils/dvx/hsm => ../hsm
+	azoo.dev/utils/dvx/tearc => ../tearc
+	azoo.dev/utils/tearc => ../../tearc
+)
@@ -0,0 +1,139 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Parse decodes a YAML or JSON configuration, sets default values and
+// validates it. Unknown fields are rejected.
+func Parse(data []byte) (*Config, error) {
+	c := &Config{}
+	if err := decode(data, c); err != nil {
+		return nil, err
+	}
+
+	c.setDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Load reads the YAML or JSON configuration file at path and overrides its
+// values with the environment variables below. If path is empty the
+// configuration consists of environment variables only.
+//
+//   DVX_ROOT_TYPE                root.type
+//   DVX_ROOT_KEY_REF             root.key
+//   DVX_HSM_MODULE               root.hsm.module
+//   DVX_HSM_LABEL                root.hsm.label
+//   DVX_HSM_USER_PIN_REF         root.hsm.user_pin
+//   DVX_HSM_ROOT_KEY_ID          root.hsm.root_key_id
+//   DVX_HSM_ROOT_KEY_LABEL       root.hsm.root_key_label
+//   DVX_HSM_ROOT_KEY_SELECTION   root.hsm.root_key_selection
+//   DVX_HSM_ROOT_KEY_GENERATION  root.hsm.root_key_generation
+//   DVX_HSM_READ_ONLY            root.hsm.read_only
+//   DVX_HSM_ROOT_KEY_RECHECK     root.hsm.root_key_recheck_interval
+//   DVX_CACHE_SIZE               cache.size
+//   DVX_CACHE_SHARDS             cache.shards
+//   DVX_CACHE_MIN_TICK           cache.bucket_min_tick
+//   DVX_CACHE_MAX_TICK           cache.bucket_max_tick
+//   DVX_CACHE_ALIVE_TIME         cache.alive_time
+//   DVX_CACHE_MAX_LIFETIME       cache.max_lifetime
+//
+// The variables ending in _REF contain Secret references, not the secrets
+// themselves.
+func Load(path string) (*Config, error) {
+	c := &Config{}
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("config: %w", err)
+		}
+		if err := decode(data, c); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := c.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	c.setDefaults()
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func decode(data []byte, c *Config) error {
+	// JSON is a subset of YAML, so the YAML decoder handles both formats
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(c); err != nil {
+		return fmt.Errorf("config: unable to decode: %w", err)
+	}
+	return nil
+}
+
+// applyEnv overrides the values of c with the environment variables
+// documented at Load.
+func (c *Config) applyEnv(lookup func(key string) (string, bool)) error {
+	hsm := func() *HSM {
+		if c.Root.HSM == nil {
+			c.Root.HSM = &HSM{}
+		}
+		return c.Root.HSM
+	}
+	cache := func() *Cache {
+		if c.Cache == nil {
+			c.Cache = &Cache{}
+		}
+		return c.Cache
+	}
+
+	for _, v := range []struct {
+		key string
+		set func(value string) error
+	}{
+		{"DVX_ROOT_TYPE", func(value string) error { c.Root.Type = value; return nil }},
+		{"DVX_ROOT_KEY_REF", func(value string) error { c.Root.Key = Secret(value); return nil }},
+		{"DVX_HSM_MODULE", func(value string) error { hsm().Module = value; return nil }},
+		{"DVX_HSM_LABEL", func(value string) error { hsm().Label = value; return nil }},
+		{"DVX_HSM_USER_PIN_REF", func(value string) error { hsm().UserPin = Secret(value); return nil }},
+		{"DVX_HSM_ROOT_KEY_ID", func(value string) error { hsm().RootKeyID = value; return nil }},
+		{"DVX_HSM_ROOT_KEY_LABEL", func(value string) error { hsm().RootKeyLabel = value; return nil }},
+		{"DVX_HSM_ROOT_KEY_SELECTION", func(value string) error { hsm().RootKeySelection = value; return nil }},
+		{"DVX_HSM_ROOT_KEY_GENERATION", func(value string) (err error) {
+			hsm().RootKeyGeneration, err = strconv.ParseUint(value, 10, 64)
+			return
+		}},
+		{"DVX_HSM_READ_ONLY", func(value string) (err error) { hsm().ReadOnly, err = strconv.ParseBool(value); return }},
+		{"DVX_HSM_ROOT_KEY_RECHECK", func(value string) (err error) {
+			hsm().RootKeyRecheckInterval, err = time.ParseDuration(value)
+			return
+		}},
+		{"DVX_CACHE_SIZE", func(value string) (err error) { cache().Size, err = strconv.Atoi(value); return }},
+		{"DVX_CACHE_SHARDS", func(value string) (err error) { cache().Shards, err = strconv.Atoi(value); return }},
+		{"DVX_CACHE_MIN_TICK", func(value string) (err error) { cache().BucketMinTick, err = time.ParseDuration(value); return }},
+		{"DVX_CACHE_MAX_TICK", func(value string) (err error) { cache().BucketMaxTick, err = time.ParseDuration(value); return }},
+		{"DVX_CACHE_ALIVE_TIME", func(value string) (err error) { cache().AliveTime, err = time.ParseDuration(value); return }},
+		{"DVX_CACHE_MAX_LIFETIME", func(value string) (err error) { cache().MaxLifetime, err = time.ParseDuration(value); return }},
+	} {
+		value, ok := lookup(v.key)
+		if !ok {
+			continue
+		}
+		if err := v.set(value); err != nil {
+			return fmt.Errorf("config: invalid value of %s: %w", v.key, err)
+		}
+	}
+
+	return nil
+}
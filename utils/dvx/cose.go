@@ -0,0 +1,373 @@
+package dvx
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// COSE (RFC 9052 and RFC 9053) message tags and algorithms used by dvx.
+const (
+	// COSETagEncrypt0 is the CBOR tag of COSE_Encrypt0 messages
+	COSETagEncrypt0 = 16
+	// COSETagSign1 is the CBOR tag of COSE_Sign1 messages
+	COSETagSign1 = 18
+	// COSEAlgEdDSA is the COSE algorithm of signatures (Ed25519)
+	COSEAlgEdDSA = -8
+	// COSEAlgChaCha20Poly1305 is the COSE algorithm of ciphers. COSE doesn't
+	// register XChaCha20-Poly1305, therefore COSE ciphers use a 12 byte
+	// nonce and a key of their own (see Protocol.EncryptCOSE).
+	COSEAlgChaCha20Poly1305 = 24
+)
+
+// COSE header and key parameter labels.
+const (
+	coseHeaderAlg = 1
+	coseHeaderKID = 4
+	coseHeaderIV  = 5
+
+	coseKeyKty   = 1
+	coseKeyAlg   = 3
+	coseKeyCrv   = -1
+	coseKeyX     = -2
+	coseKtyOKP   = 1
+	coseCrvEd255 = 6
+)
+
+// COSEMessage is a parsed COSE_Sign1 or COSE_Encrypt0 message (see
+// ParseCOSE). Its signature or cipher isn't verified.
+type COSEMessage struct {
+	// Tag is COSETagSign1 or COSETagEncrypt0. Untagged messages are
+	// identified by their structure.
+	Tag uint64
+	// Algorithm is the algorithm of the protected header.
+	Algorithm int64
+	// KeyID is the "kid" header parameter, if present.
+	KeyID []byte
+	// IV is the "IV" header parameter of COSE_Encrypt0 messages.
+	IV []byte
+	// Content is the payload of COSE_Sign1 or the ciphertext of
+	// COSE_Encrypt0 messages.
+	Content []byte
+	// Signature is the signature of COSE_Sign1 messages.
+	Signature []byte
+
+	protected []byte
+}
+
+// ParseCOSE parses a tagged or untagged COSE_Sign1 or COSE_Encrypt0 message,
+// e.g. to read the KeyID before selecting the keyRing.
+func ParseCOSE(data []byte) (*COSEMessage, error) {
+	v, err := cborUnmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &COSEMessage{}
+	if tag, ok := v.(cborTag); ok {
+		if tag.number != COSETagSign1 && tag.number != COSETagEncrypt0 {
+			return nil, errorf(ErrInvalidFormat, "dvx: COSE: unsupported tag %d", tag.number)
+		}
+		m.Tag, v = tag.number, tag.content
+	}
+
+	array, ok := v.([]interface{})
+	switch {
+	case !ok:
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: message isn't an array")
+	case m.Tag == 0 && len(array) == 4:
+		m.Tag = COSETagSign1
+	case m.Tag == 0 && len(array) == 3:
+		m.Tag = COSETagEncrypt0
+	}
+	if m.Tag == 0 || (m.Tag == COSETagSign1 && len(array) != 4) || (m.Tag == COSETagEncrypt0 && len(array) != 3) {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: invalid amount of elements (%d)", len(array))
+	}
+
+	var protected map[interface{}]interface{}
+	m.protected, ok = array[0].([]byte)
+	if !ok {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: protected header isn't a byte string")
+	}
+	if len(m.protected) > 0 {
+		v, err := cborUnmarshal(m.protected)
+		if err != nil {
+			return nil, err
+		}
+		if protected, ok = v.(map[interface{}]interface{}); !ok {
+			return nil, errorf(ErrInvalidFormat, "dvx: COSE: protected header isn't a map")
+		}
+	}
+	unprotected, ok := array[1].(map[interface{}]interface{})
+	if !ok {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: unprotected header isn't a map")
+	}
+
+	if m.Algorithm, ok = protected[int64(coseHeaderAlg)].(int64); !ok {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: protected header has no algorithm")
+	}
+	for _, header := range []map[interface{}]interface{}{protected, unprotected} {
+		if kid, ok := header[int64(coseHeaderKID)].([]byte); ok {
+			m.KeyID = kid
+		}
+		if iv, ok := header[int64(coseHeaderIV)].([]byte); ok {
+			m.IV = iv
+		}
+	}
+
+	if m.Content, ok = array[2].([]byte); !ok {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: content isn't a byte string (detached content isn't supported)")
+	}
+	if m.Tag == COSETagSign1 {
+		if m.Signature, ok = array[3].([]byte); !ok {
+			return nil, errorf(ErrInvalidFormat, "dvx: COSE: signature isn't a byte string")
+		}
+	}
+
+	return m, nil
+}
+
+// coseProtected returns the encoded protected header with alg.
+func coseProtected(alg int64) []byte {
+	return cborMarshal([]cborMapEntry{{int64(coseHeaderAlg), alg}})
+}
+
+// coseUnprotected returns the unprotected header with the optional keyID
+// and iv.
+func coseUnprotected(keyID []byte, iv []byte) []cborMapEntry {
+	header := []cborMapEntry{}
+	if len(keyID) > 0 {
+		header = append(header, cborMapEntry{int64(coseHeaderKID), keyID})
+	}
+	if len(iv) > 0 {
+		header = append(header, cborMapEntry{int64(coseHeaderIV), iv})
+	}
+	return header
+}
+
+// coseSigStructure returns the Sig_structure of a COSE_Sign1 message without
+// external additional data, which is the signed message.
+func coseSigStructure(protected []byte, payload []byte) []byte {
+	return cborMarshal([]interface{}{"Signature1", protected, []byte{}, payload})
+}
+
+// coseEncStructure returns the Enc_structure of a COSE_Encrypt0 message
+// without external additional data, which is the additional data of the
+// AEAD.
+func coseEncStructure(protected []byte) []byte {
+	return cborMarshal([]interface{}{"Encrypt0", protected, []byte{}})
+}
+
+// SignCOSE is like Sign, but returns a tagged COSE_Sign1 message (EdDSA)
+// with payload embedded. keyID is added to the unprotected header, if it isn't
+// empty. The signature can be verified with VerifyCOSE, VerifyCOSEPK or any
+// COSE implementation with the public key of CreateSignKey (see
+// MarshalCOSEKey).
+func (p *Protocol) SignCOSE(keyRing string, payload []byte, keyID []byte) (message []byte, err error) {
+	return p.SignCOSEContext(context.Background(), keyRing, payload, keyID)
+}
+
+// SignCOSEContext is like SignCOSE, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) SignCOSEContext(ctx context.Context, keyRing string, payload []byte, keyID []byte) (message []byte, err error) {
+	protected := coseProtected(COSEAlgEdDSA)
+	_, sig, err := p.SignContext(ctx, keyRing, coseSigStructure(protected, payload))
+	if err != nil {
+		return nil, err
+	}
+
+	return cborMarshal(cborTag{COSETagSign1, []interface{}{
+		protected,
+		coseUnprotected(keyID, nil),
+		payload,
+		sig,
+	}}), nil
+}
+
+// VerifyCOSE is like Verify, but verifies a COSE_Sign1 message and returns its
+// payload. payload is nil if the message isn't valid.
+func (p *Protocol) VerifyCOSE(keyRing string, message []byte) (payload []byte, valid bool, err error) {
+	return p.VerifyCOSEContext(context.Background(), keyRing, message)
+}
+
+// VerifyCOSEContext is like VerifyCOSE, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) VerifyCOSEContext(ctx context.Context, keyRing string, message []byte) (payload []byte, valid bool, err error) {
+	m, err := parseCOSESign1(message)
+	if err != nil {
+		return nil, false, err
+	}
+
+	valid, err = p.VerifyContext(ctx, keyRing, coseSigStructure(m.protected, m.Content), EncodeVersion(p.writeVersion(), Signed, m.Signature))
+	if err != nil || !valid {
+		return nil, false, err
+	}
+	return m.Content, true, nil
+}
+
+// VerifyCOSEPK is like VerifyPK, but verifies a COSE_Sign1 message and returns
+// its payload. payload is nil if the message isn't valid.
+func (p *Protocol) VerifyCOSEPK(publicKey []byte, message []byte) (payload []byte, valid bool, err error) {
+	m, err := parseCOSESign1(message)
+	if err != nil {
+		return nil, false, err
+	}
+
+	valid, err = p.VerifyPK(publicKey, coseSigStructure(m.protected, m.Content), EncodeVersion(p.writeVersion(), Signed, m.Signature))
+	if err != nil || !valid {
+		return nil, false, err
+	}
+	return m.Content, true, nil
+}
+
+func parseCOSESign1(message []byte) (*COSEMessage, error) {
+	m, err := ParseCOSE(message)
+	if err != nil {
+		return nil, err
+	}
+	if m.Tag != COSETagSign1 {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: not a COSE_Sign1 message")
+	}
+	if m.Algorithm != COSEAlgEdDSA {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: unsupported algorithm %d", m.Algorithm)
+	}
+	return m, nil
+}
+
+// EncryptCOSE derives a secret key `sk` using the keyRing and subsequently
+// encrypts data using `sk` as tagged COSE_Encrypt0 message. keyID is added to
+// the unprotected header, if it isn't empty.
+//
+// COSE doesn't register XChaCha20-Poly1305, so EncryptCOSE uses
+// ChaCha20-Poly1305 (COSEAlgChaCha20Poly1305) with a random 12 byte nonce and
+// a key derived for its own purpose. Because of the shorter nonce a keyRing
+// shouldn't encrypt more than 2^32 COSE messages.
+func (p *Protocol) EncryptCOSE(keyRing string, data []byte, keyID []byte) (message []byte, err error) {
+	return p.EncryptCOSEContext(context.Background(), keyRing, data, keyID)
+}
+
+// EncryptCOSEContext is like EncryptCOSE, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) EncryptCOSEContext(ctx context.Context, keyRing string, data []byte, keyID []byte) (message []byte, err error) {
+	defer p.done(OpEncrypt, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpEncrypt)
+	if err != nil {
+		return nil, err
+	}
+	if err = p.checkEncryptionContext(keyRing, nil); err != nil {
+		return nil, err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, featureVersion, purposeCOSE)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, errorf(ErrRandomness, "dvx: failed to read random %d bytes for nonce: %v", chacha20poly1305.NonceSize, err)
+	}
+
+	protected := coseProtected(COSEAlgChaCha20Poly1305)
+	aead, _ := chacha20poly1305.New(key) // err is always nil
+	cipher := aead.Seal(nil, nonce, data, coseEncStructure(protected))
+
+	atomic.AddUint64(&p.stats.bytesEncrypted, uint64(len(data)))
+	return cborMarshal(cborTag{COSETagEncrypt0, []interface{}{
+		protected,
+		coseUnprotected(keyID, nonce),
+		cipher,
+	}}), nil
+}
+
+// DecryptCOSE derives a secret key `sk` using the keyRing and subsequently
+// decrypts a COSE_Encrypt0 message of EncryptCOSE using `sk`.
+func (p *Protocol) DecryptCOSE(keyRing string, message []byte) (data []byte, err error) {
+	return p.DecryptCOSEContext(context.Background(), keyRing, message)
+}
+
+// DecryptCOSEContext is like DecryptCOSE, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) DecryptCOSEContext(ctx context.Context, keyRing string, message []byte) (data []byte, err error) {
+	defer p.done(OpDecrypt, time.Now(), &err)
+
+	m, err := ParseCOSE(message)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case m.Tag != COSETagEncrypt0:
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: not a COSE_Encrypt0 message")
+	case m.Algorithm != COSEAlgChaCha20Poly1305:
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: unsupported algorithm %d", m.Algorithm)
+	case len(m.IV) != chacha20poly1305.NonceSize:
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE: IV must be %d bytes long", chacha20poly1305.NonceSize)
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpDecrypt)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, featureVersion, purposeCOSE)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, _ := chacha20poly1305.New(key) // err is always nil
+	data, err = aead.Open(nil, m.IV, m.Content, coseEncStructure(m.protected))
+	if err != nil {
+		return nil, errorf(ErrAuthentication, "dvx: COSE: open failed: %v", err)
+	}
+
+	atomic.AddUint64(&p.stats.bytesDecrypted, uint64(len(data)))
+	return data, nil
+}
+
+// MarshalCOSEKey encodes an Ed25519 public key (e.g. of CreateSignKey) as
+// COSE_Key (OKP, Ed25519), the format of public keys in WebAuthn and most
+// COSE implementations.
+func MarshalCOSEKey(publicKey []byte) ([]byte, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, errorf(ErrInvalidKey, "dvx: public key must be %d bytes long", ed25519.PublicKeySize)
+	}
+
+	return cborMarshal([]cborMapEntry{
+		{int64(coseKeyKty), int64(coseKtyOKP)},
+		{int64(coseKeyAlg), int64(COSEAlgEdDSA)},
+		{int64(coseKeyCrv), int64(coseCrvEd255)},
+		{int64(coseKeyX), publicKey},
+	}), nil
+}
+
+// ParseCOSEKey decodes an Ed25519 public key encoded as COSE_Key.
+func ParseCOSEKey(data []byte) (ed25519.PublicKey, error) {
+	v, err := cborUnmarshal(data)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := v.(map[interface{}]interface{})
+	if !ok {
+		return nil, errorf(ErrInvalidFormat, "dvx: COSE_Key isn't a map")
+	}
+
+	if kty, _ := key[int64(coseKeyKty)].(int64); kty != coseKtyOKP {
+		return nil, errorf(ErrInvalidKey, "dvx: COSE_Key has unsupported key type %v", key[int64(coseKeyKty)])
+	}
+	if crv, _ := key[int64(coseKeyCrv)].(int64); crv != coseCrvEd255 {
+		return nil, errorf(ErrInvalidKey, "dvx: COSE_Key has unsupported curve %v", key[int64(coseKeyCrv)])
+	}
+	if alg, ok := key[int64(coseKeyAlg)]; ok && alg != int64(COSEAlgEdDSA) {
+		return nil, errorf(ErrInvalidKey, "dvx: COSE_Key has unsupported algorithm %v", alg)
+	}
+	x, _ := key[int64(coseKeyX)].([]byte)
+	if len(x) != ed25519.PublicKeySize {
+		return nil, errorf(ErrInvalidKey, "dvx: COSE_Key public key must be %d bytes long", ed25519.PublicKeySize)
+	}
+	return ed25519.PublicKey(x), nil
+}
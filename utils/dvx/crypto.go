@@ -0,0 +1,40 @@
+package dvx
+
+import (
+	"context"
+)
+
+// Crypto is the method set of Protocol for encryption, signatures, MACs, TOTP
+// and tokens. Code that depends on Crypto instead of *Protocol can be tested
+// without key material, e.g. with the deterministic Fake of
+// azoo.dev/utils/dvx/dvxtest. See Protocol for the documentation of every
+// method.
+type Crypto interface {
+	Encrypt(keyRing string, data []byte) (ciphertext string, err error)
+	EncryptContext(ctx context.Context, keyRing string, data []byte) (ciphertext string, err error)
+	Decrypt(keyRing string, ciphertext string) (data []byte, err error)
+	DecryptContext(ctx context.Context, keyRing string, ciphertext string) (data []byte, err error)
+
+	CreateSignKey(keyRing string) (publicKey []byte, err error)
+	CreateSignKeyContext(ctx context.Context, keyRing string) (publicKey []byte, err error)
+	Sign(keyRing string, message []byte) (signature string, rawSignature []byte, err error)
+	SignContext(ctx context.Context, keyRing string, message []byte) (signature string, rawSignature []byte, err error)
+	Verify(keyRing string, message []byte, signature string) (valid bool, err error)
+	VerifyContext(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error)
+	VerifyPK(publicKey []byte, message []byte, signature string) (valid bool, err error)
+
+	MAC(keyRing string, message []byte) (tag string, err error)
+	MACContext(ctx context.Context, keyRing string, message []byte) (tag string, err error)
+
+	GenerateTOTP(keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error)
+	GenerateTOTPContext(ctx context.Context, keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error)
+	VerifyTOTP(keyRing string, id string, accountID string, code string) (valid bool, err error)
+	VerifyTOTPContext(ctx context.Context, keyRing string, id string, accountID string, code string) (valid bool, err error)
+
+	Tokenize(keyRing string, value string) (token string, err error)
+	TokenizeContext(ctx context.Context, keyRing string, value string) (token string, err error)
+	Detokenize(keyRing string, token string) (value string, err error)
+	DetokenizeContext(ctx context.Context, keyRing string, token string) (value string, err error)
+}
+
+var _ Crypto = (*Protocol)(nil)
@@ -0,0 +1,229 @@
+package dvx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SetDiagnostics enables or disables the diagnostic APIs of p (e.g.
+// DescribeDerivation), which are disabled by default. They never reveal key
+// material, but describe the internals of p and should only be enabled for
+// debugging or security reviews. SetDiagnostics must be called before p is
+// used.
+func (p *Protocol) SetDiagnostics(enabled bool) {
+	p.diagnostics = enabled
+}
+
+// Derivation describes a single key derivation of a DerivationReport.
+type Derivation struct {
+	// Purpose is the purpose of the derived key. For example: "enc"
+	Purpose string
+	// KDF is the derivation of the KeyPool. For example: "kdf32"
+	KDF string
+	// Label is prepended to the keyRing to form the input of the KeyPool.
+	// For example: "dv2/kdf32/enc\x00"
+	Label string
+	// KeyLength is the length of the derived key in bytes.
+	KeyLength int
+	// Use describes what the derived key is used for. For example:
+	// "XChaCha20-Poly1305 key"
+	Use string
+	// Steps are further derivations from the derived key, in order. For
+	// example: "MAC512(key, raw-id) -> intermediate (64 bytes)"
+	Steps []string
+	// Operations are the methods of Protocol that derive the key.
+	Operations []string
+}
+
+// DerivationReport describes how Protocol derives keys for a keyRing (see
+// DescribeDerivation).
+type DerivationReport struct {
+	// KeyRing is the described keyRing.
+	KeyRing string
+	// KeyRingEncoding is "base64" if keyRing has a prefix and the base64
+	// encoded bytes after it are passed to the KeyPool, otherwise "raw".
+	KeyRingEncoding string
+	// KeyRingLength is the length of the keyRing bytes passed to the KeyPool.
+	KeyRingLength int
+	// Version is the version of the described derivations. For example:
+	// "dv2"
+	Version string
+	// KeyPool is the type of the KeyPool of Version. For example:
+	// "*hsm.hsm"
+	KeyPool string
+	// Derivations are all key derivations for the keyRing. Their inputs are
+	// separated by their labels.
+	Derivations []Derivation
+}
+
+// String renders r as a tree from the root key of the KeyPool over every
+// derivation to the final keys.
+func (r *DerivationReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "root (KeyPool %s, version %s)\n", r.KeyPool, r.Version)
+	fmt.Fprintf(&b, "  keyRing %q (%s, %d bytes)\n", r.KeyRing, r.KeyRingEncoding, r.KeyRingLength)
+	for _, d := range r.Derivations {
+		fmt.Fprintf(&b, "  %s(%q || keyRing) -> %s (%d bytes): %s\n", d.KDF, d.Label, d.Purpose, d.KeyLength, d.Use)
+		for _, step := range d.Steps {
+			fmt.Fprintf(&b, "    %s\n", step)
+		}
+		fmt.Fprintf(&b, "    used by %s\n", strings.Join(d.Operations, ", "))
+	}
+	return b.String()
+}
+
+// derivations lists every key derivation of Protocol for a keyRing. It must be
+// updated together with the calls of kdf32 and kdf64.
+var derivations = []Derivation{
+	{
+		Purpose:    purposeEncrypt,
+		KDF:        "kdf32",
+		KeyLength:  32,
+		Use:        "XChaCha20-Poly1305 key",
+		Operations: []string{"Encrypt", "EncryptWithFooter", "EncryptNotBefore", "Decrypt"},
+	},
+	{
+		Purpose:    purposeSign,
+		KDF:        "kdf32",
+		KeyLength:  32,
+		Use:        "Ed25519 seed",
+		Operations: []string{"CreateSignKey", "Sign", "SignWithFooter", "SignJSON", "SignCOSE", "SignSSH", "Signer", "CreateCertificate", "Verify", "VerifyJSON", "VerifyCOSE", "VerifySSH"},
+	},
+	{
+		Purpose:    purposeMAC,
+		KDF:        "kdf64",
+		KeyLength:  64,
+		Use:        "Blake2b-512 MAC key",
+		Operations: []string{"MAC", "MACWithFooter"},
+	},
+	{
+		Purpose:   purposeTOTP,
+		KDF:       "kdf64",
+		KeyLength: 64,
+		Use:       "TOTP key",
+		Steps: []string{
+			"MAC512(key, raw-id) -> intermediate (64 bytes)",
+			"MAC256(intermediate, accountID) -> totp-sk (32 bytes)",
+		},
+		Operations: []string{"GenerateTOTP", "VerifyTOTP"},
+	},
+	{
+		Purpose:    purposeTokenize,
+		KDF:        "kdf32",
+		KeyLength:  32,
+		Use:        "XChaCha20-Poly1305 key of tokens",
+		Operations: []string{"Tokenize", "Detokenize"},
+	},
+	{
+		Purpose:    purposeTokenize,
+		KDF:        "kdf64",
+		KeyLength:  64,
+		Use:        "Blake2b-256 MAC key of synthetic nonces",
+		Operations: []string{"Tokenize", "Detokenize"},
+	},
+	{
+		Purpose:   purposeRatchet,
+		KDF:       "kdf64",
+		KeyLength: 64,
+		Use:       "chain key of index 0",
+		Steps: []string{
+			"MAC512(ck[i], 0x01) -> ck[i+1] (64 bytes)",
+			"MAC256(ck[i], 0x02) -> mk[i] (32 bytes, XChaCha20-Poly1305 key)",
+		},
+		Operations: []string{"NewRatchet"},
+	},
+	{
+		Purpose:    purposeCOSE,
+		KDF:        "kdf32",
+		KeyLength:  32,
+		Use:        "ChaCha20-Poly1305 key",
+		Operations: []string{"EncryptCOSE", "DecryptCOSE"},
+	},
+	{
+		Purpose:    purposeFile,
+		KDF:        "kdf32",
+		KeyLength:  32,
+		Use:        "XChaCha20-Poly1305 key of dvxfile chunks",
+		Operations: []string{"NewFileWriter", "NewFileReader", "SaveSecretsFile", "LoadSecretsFile"},
+	},
+	{
+		Purpose:    purposeOneTime,
+		KDF:        "kdf64",
+		KeyLength:  64,
+		Use:        "Blake2b-256 MAC key of one-time tokens",
+		Operations: []string{"IssueOneTimeToken", "RedeemOneTimeToken"},
+	},
+	{
+		Purpose:    purposeSession,
+		KDF:        "kdf32",
+		KeyLength:  32,
+		Use:        "XChaCha20-Poly1305 key of sessions",
+		Operations: []string{"SealSession", "OpenSession"},
+	},
+	{
+		Purpose:    purposeTOTPRevocation,
+		KDF:        "kdf64",
+		KeyLength:  64,
+		Use:        "Blake2b-256 MAC key of TOTP revocations",
+		Operations: []string{"RevokeTOTP", "VerifyTOTPRevocation", "VerifyTOTP"},
+	},
+	{
+		Purpose:   purposeWebAuthn,
+		KDF:       "kdf64",
+		KeyLength: 64,
+		Use:       "WebAuthn challenge key",
+		Steps: []string{
+			"MAC512(key, rpID) -> relying party key (64 bytes)",
+			"MAC256(relying party key, challenge || binding) -> tag (32 bytes)",
+		},
+		Operations: []string{"IssueWebAuthnChallenge", "VerifyWebAuthnChallenge"},
+	},
+	{
+		Purpose:   purposeID,
+		KDF:       "kdf64",
+		KeyLength: 64,
+		Use:       "Blake2b-256 MAC key of derived IDs (always derived with dv2)",
+		Steps: []string{
+			"MAC256(key, \"dv2\" || \"id\" || name) -> first 16 bytes are the UUID (version 8)",
+		},
+		Operations: []string{"DeriveID"},
+	},
+}
+
+// DescribeDerivation returns how p derives keys for keyRing with dv2: from
+// the root key of the KeyPool over the input of every derivation to the
+// length and use of the final keys, so security reviewers can verify the
+// separation of keys without reading the source. It never derives a key and
+// the report contains no key material. dv1 keys (used by Encrypt, Sign, MAC
+// and GenerateTOTP unless p uses WithVersion("dv2")) are derived from the
+// raw keyRing instead (see kdfInput).
+//
+// DescribeDerivation fails unless diagnostics are enabled (see
+// SetDiagnostics).
+func (p *Protocol) DescribeDerivation(keyRing string) (*DerivationReport, error) {
+	if !p.diagnostics {
+		return nil, errors.New("dvx: DescribeDerivation requires diagnostics (see SetDiagnostics)")
+	}
+
+	keyRingBytes := keyRingToBytes(keyRing)
+	encoding := "raw"
+	if string(keyRingBytes) != keyRing {
+		encoding = "base64"
+	}
+
+	r := &DerivationReport{
+		KeyRing:         keyRing,
+		KeyRingEncoding: encoding,
+		KeyRingLength:   len(keyRingBytes),
+		Version:         featureVersion,
+		KeyPool:         fmt.Sprintf("%T", p.keyPool(featureVersion)),
+	}
+	for _, d := range derivations {
+		d.Label = string(kdfInput(featureVersion, d.KDF, d.Purpose, nil))
+		d.Steps = append([]string(nil), d.Steps...)
+		d.Operations = append([]string(nil), d.Operations...)
+		r.Derivations = append(r.Derivations, d)
+	}
+	return r, nil
+}
@@ -0,0 +1,71 @@
+package dvx
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+)
+
+// idVersion is the version whose keys derive IDs. It doesn't follow Version,
+// so IDs stay stable when the Protocol moves to a new version.
+const idVersion = "dv2"
+
+// DeriveID derives a secret key `sk` using the keyRing and returns a stable
+// identifier of name within keyRing: a UUID (RFC 9562 version 8, custom
+// format) calculated with a keyed MAC of name. Equal keyRings and names
+// always result in equal IDs, so pseudonymous IDs (e.g. of users in an
+// analytics dataset or a partner integration) can be generated wherever the
+// root key is available, without a lookup table mapping them to their names.
+// Without the key, IDs reveal nothing about name and IDs of different
+// keyRings can't be linked.
+//
+// IDs aren't reversible: store name next to them if it must be recovered
+// (e.g. encrypted). They are always derived with dv2 keys, so they don't
+// change when the Protocol encrypts with a newer Version, but they change
+// when the root key is rotated.
+func (p *Protocol) DeriveID(keyRing string, name string) (id string, err error) {
+	return p.DeriveIDContext(context.Background(), keyRing, name)
+}
+
+// DeriveIDContext is like DeriveID, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) DeriveIDContext(ctx context.Context, keyRing string, name string) (id string, err error) {
+	defer p.done(OpDeriveID, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpDeriveID)
+	if err != nil {
+		return "", err
+	}
+	key, err := p.kdf64(ctx, keyRingBytes, idVersion, purposeID)
+	if err != nil {
+		return "", err
+	}
+
+	msg := make([]byte, 0, len(idVersion)+len(purposeID)+len(name))
+	msg = append(append(append(msg, idVersion...), purposeID...), name...)
+	tag, err := primitiveOf(idVersion).MAC256(key, msg)
+	if err != nil {
+		return "", err
+	}
+
+	return formatUUIDv8(tag[:16]), nil
+}
+
+// formatUUIDv8 sets the version (8) and variant (RFC 9562) bits of the 16
+// bytes of uuid and returns its canonical, lower-case string form.
+func formatUUIDv8(uuid []byte) string {
+	uuid[6] = uuid[6]&0x0f | 0x80
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	var buf [36]byte
+	hex.Encode(buf[0:8], uuid[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], uuid[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], uuid[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], uuid[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:36], uuid[10:16])
+	return string(buf[:])
+}
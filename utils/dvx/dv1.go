@@ -3,10 +3,8 @@ package dvx
 import (
 	"crypto/ed25519"
 	"crypto/rand"
-	"fmt"
 	"io"
 
-	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/blake2b"
 	"golang.org/x/crypto/chacha20poly1305"
 )
@@ -14,77 +12,145 @@ import (
 type DV1 struct {
 }
 
+// KDF512 derives a 64 byte key from password with Argon2id and the
+// DefaultArgon2Params. salt must be at least MinSaltSize bytes long.
 func (d DV1) KDF512(password []byte, salt []byte) (key []byte, err error) {
-	return argon2.IDKey(password, salt, 1, 64*1024, 4, 64), nil
+	return d.KDF512WithParams(password, salt, DefaultArgon2Params)
 }
 
 func (d DV1) MAC256(key []byte, message []byte) (tag []byte, err error) {
+	return d.MAC256To(make([]byte, 0, blake2b.Size256), key, message)
+}
+
+func (d DV1) MAC512(key []byte, message []byte) (tag []byte, err error) {
+	return d.MAC512To(make([]byte, 0, blake2b.Size), key, message)
+}
+
+// MAC256To appends the 32 byte tag of message to dst (see AppendPrimitive).
+func (d DV1) MAC256To(dst []byte, key []byte, message []byte) (tag []byte, err error) {
 	if len(key) != blake2b.Size {
-		return nil, fmt.Errorf("dv1: mac key must be %d bytes long", blake2b.Size)
+		return nil, errorf(ErrInvalidKey, "dv1: mac key must be %d bytes long", blake2b.Size)
 	}
-	h, _ := blake2b.New256(key) // err is always nil
-	h.Write(message)
-	return h.Sum(nil), nil
+	return appendMAC(dst, blake2b.Size256, key, message), nil
 }
 
-func (d DV1) MAC512(key []byte, message []byte) (tag []byte, err error) {
+// MAC512To appends the 64 byte tag of message to dst (see AppendPrimitive).
+func (d DV1) MAC512To(dst []byte, key []byte, message []byte) (tag []byte, err error) {
 	if len(key) != blake2b.Size {
-		return nil, fmt.Errorf("dv1: mac key must be %d bytes long", blake2b.Size)
+		return nil, errorf(ErrInvalidKey, "dv1: mac key must be %d bytes long", blake2b.Size)
 	}
-	h, _ := blake2b.New512(key) // err is always nil
-	h.Write(message)
-	return h.Sum(nil), nil
+	return appendMAC(dst, blake2b.Size, key, message), nil
 }
 
 func (d DV1) Encrypt(key []byte, data []byte) (cipher []byte, err error) {
+	return seal("dv1", key, data, nil)
+}
+
+func (d DV1) Decrypt(key []byte, cipher []byte) (data []byte, err error) {
+	return open("dv1", key, cipher, nil)
+}
+
+// EncryptTo appends the cipher of data to dst (see AppendPrimitive).
+func (d DV1) EncryptTo(dst []byte, key []byte, data []byte) (cipher []byte, err error) {
+	return sealTo(dst, "dv1", key, data, nil)
+}
+
+// DecryptTo appends the data of cipher to dst (see AppendPrimitive).
+func (d DV1) DecryptTo(dst []byte, key []byte, cipher []byte) (data []byte, err error) {
+	return openTo(dst, "dv1", key, cipher, nil)
+}
+
+// seal encrypts data with XChaCha20-Poly1305 and a random nonce. The version,
+// nonce and footer (see EncodeWithFooter) are authenticated as additional
+// data. Without footer the additional data is the same as before footers
+// existed.
+func seal(version string, key []byte, data []byte, footer []byte) (cipher []byte, err error) {
+	return sealTo(make([]byte, 0, CipherOverhead+len(data)), version, key, data, footer)
+}
+
+// sealTo is seal, but appends the cipher to dst.
+func sealTo(dst []byte, version string, key []byte, data []byte, footer []byte) (cipher []byte, err error) {
 	if len(key) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("dv1: key must be %d bytes long", chacha20poly1305.KeySize)
+		return nil, errorf(ErrInvalidKey, "%s: key must be %d bytes long", version, chacha20poly1305.KeySize)
 	}
 
-	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	n := len(dst)
+	dst = grow(dst, CipherOverhead+len(data))
+	nonce := dst[n : n+chacha20poly1305.NonceSizeX]
 	_, err = io.ReadFull(rand.Reader, nonce)
 	if err != nil {
-		return nil, fmt.Errorf("dv1: failed to read random %d bytes for nonceKey: %v", chacha20poly1305.NonceSizeX, err)
+		return nil, errorf(ErrRandomness, "%s: failed to read random %d bytes for nonceKey: %v", version, chacha20poly1305.NonceSizeX, err)
 	}
+	checkNonce(version, nonce)
 
+	var aad [sealAADMaxSize]byte
 	aead, _ := chacha20poly1305.NewX(key) // err is always nil
-	encrypted := aead.Seal(data[:0], nonce, data, append([]byte(Version), nonce...))
-	return append(nonce, encrypted...), nil
+	sealed := aead.Seal(nonce, nonce, data, appendSealAAD(aad[:0], version, nonce, footer))
+	return dst[:n+len(sealed)], nil
 }
 
-func (d DV1) Decrypt(key []byte, cipher []byte) (data []byte, err error) {
+// open decrypts a cipher created by seal with the same version and footer.
+func open(version string, key []byte, cipher []byte, footer []byte) (data []byte, err error) {
+	return openTo(nil, version, key, cipher, footer)
+}
+
+// openTo is open, but appends the data to dst.
+func openTo(dst []byte, version string, key []byte, cipher []byte, footer []byte) (data []byte, err error) {
 	if len(key) != chacha20poly1305.KeySize {
-		return nil, fmt.Errorf("dv1: key must be %d bytse long", chacha20poly1305.KeySize)
+		return nil, errorf(ErrInvalidKey, "%s: key must be %d bytes long", version, chacha20poly1305.KeySize)
 	}
 	if len(cipher) < chacha20poly1305.NonceSizeX {
-		return nil, fmt.Errorf("dv1: cipher shorter (%d) than needed for nonce (%d)", len(cipher), chacha20poly1305.NonceSizeX)
+		return nil, errorf(ErrInvalidFormat, "%s: cipher shorter (%d) than needed for nonce (%d)", version, len(cipher), chacha20poly1305.NonceSizeX)
 	}
 
 	nonce := cipher[:chacha20poly1305.NonceSizeX]
 	encrypted := cipher[chacha20poly1305.NonceSizeX:]
 
+	var aad [sealAADMaxSize]byte
 	aead, _ := chacha20poly1305.NewX(key) // err is always nil
-	data, err = aead.Open(nil, nonce, encrypted, append([]byte(Version), nonce...))
+	data, err = aead.Open(dst, nonce, encrypted, appendSealAAD(aad[:0], version, nonce, footer))
 	if err != nil {
-		return nil, fmt.Errorf("dv1: open failed: %v", err)
+		return nil, errorf(ErrAuthentication, "%s: open failed: %v", version, err)
 	}
 
 	return
 }
 
+// grow returns b with at least n bytes of free capacity, like bytes.Buffer.Grow.
+func grow(b []byte, n int) []byte {
+	if cap(b)-len(b) >= n {
+		return b
+	}
+	g := make([]byte, len(b), len(b)+n)
+	copy(g, b)
+	return g
+}
+
+// sealAADMaxSize is the size of the additional data of seal for versions of
+// 3 bytes and footers of up to 64 bytes. Larger additional data is allocated.
+const sealAADMaxSize = 3 + chacha20poly1305.NonceSizeX + 64
+
+// appendSealAAD appends the additional data of seal to dst. The version and
+// nonce have a fixed size, so the footer is appended without a length.
+func appendSealAAD(dst []byte, version string, nonce []byte, footer []byte) []byte {
+	dst = append(dst, version...)
+	dst = append(dst, nonce...)
+	return append(dst, footer...)
+}
+
 func (d DV1) Sign(privateKey []byte, message []byte) (signature []byte, err error) {
 	if len(privateKey) != ed25519.PrivateKeySize {
-		return nil, fmt.Errorf("dv1: private key must be %d bytes long", ed25519.PrivateKeySize)
+		return nil, errorf(ErrInvalidKey, "dv1: private key must be %d bytes long", ed25519.PrivateKeySize)
 	}
 	return ed25519.Sign(privateKey, message), nil
 }
 
 func (d DV1) Verify(publicKey []byte, message []byte, signature []byte) (valid bool, err error) {
 	if len(publicKey) != ed25519.PublicKeySize {
-		return false, fmt.Errorf("dv1: public key must be %d bytes long", ed25519.PublicKeySize)
+		return false, errorf(ErrInvalidKey, "dv1: public key must be %d bytes long", ed25519.PublicKeySize)
 	}
 	if len(signature) != ed25519.SignatureSize {
-		return false, fmt.Errorf("dv1: signature must be %d bytes long", ed25519.SignatureSize)
+		return false, errorf(ErrInvalidFormat, "dv1: signature must be %d bytes long", ed25519.SignatureSize)
 	}
 	return ed25519.Verify(publicKey, message, signature), nil
 }
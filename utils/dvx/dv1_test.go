@@ -5,9 +5,11 @@ import (
 	"crypto/rand"
 	"io"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
 )
 
 func TestDV1_EncryptDecrypt(t *testing.T) {
@@ -62,6 +64,118 @@ func TestDV1_MAC512(t *testing.T) {
 	assert.Equal(t, tag1, tag2)
 }
 
+func TestDV1_MACTo(t *testing.T) {
+	key := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, key)
+	require.NoError(t, err)
+
+	// the pooled hasher must match a new keyed hasher, also for messages
+	// longer than a block
+	for _, data := range [][]byte{nil, []byte("message"), make([]byte, 1000)} {
+		h, _ := blake2b.New256(key)
+		h.Write(data)
+		tag, err := DV1{}.MAC256To([]byte("prefix"), key, data)
+		require.NoError(t, err)
+		assert.Equal(t, append([]byte("prefix"), h.Sum(nil)...), tag)
+
+		h, _ = blake2b.New512(key)
+		h.Write(data)
+		tag, err = DV1{}.MAC512To(nil, key, data)
+		require.NoError(t, err)
+		assert.Equal(t, h.Sum(nil), tag)
+	}
+
+	_, err = DV1{}.MAC256To(nil, key[:32], []byte("message"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestDV1_EncryptDecryptTo(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, key)
+	require.NoError(t, err)
+
+	data := []byte("some random data")
+	for _, p := range []AppendPrimitive{DV1{}, DV2{}} {
+		cipher, err := p.EncryptTo([]byte("prefix"), key, data)
+		require.NoError(t, err)
+		assert.Equal(t, "prefix", string(cipher[:6]))
+		assert.Len(t, cipher, 6+CipherOverhead+len(data))
+
+		plain, err := p.(Primitive).Decrypt(key, cipher[6:])
+		require.NoError(t, err)
+		assert.Equal(t, data, plain)
+
+		plain, err = p.DecryptTo([]byte("prefix"), key, cipher[6:])
+		require.NoError(t, err)
+		assert.Equal(t, "prefix"+string(data), string(plain))
+	}
+}
+
+func TestDV1_AppendZeroAllocs(t *testing.T) {
+	encKey := make([]byte, 32)
+	macKey := make([]byte, 64)
+	data := make([]byte, 1024)
+	cipherBuf := make([]byte, 0, CipherOverhead+len(data))
+	dataBuf := make([]byte, 0, len(data))
+	tagBuf := make([]byte, 0, 64)
+
+	cipher, err := DV2{}.EncryptTo(cipherBuf, encKey, data)
+	require.NoError(t, err)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_, _ = DV2{}.EncryptTo(cipherBuf, encKey, data)
+		_, _ = DV2{}.DecryptTo(dataBuf, encKey, cipher)
+		_, _ = DV2{}.MAC256To(tagBuf, macKey, data)
+		_, _ = DV2{}.MAC512To(tagBuf, macKey, data)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestDV1_KDF512(t *testing.T) {
+	salt := make([]byte, MinSaltSize)
+
+	key, err := DV1{}.KDF512([]byte("password"), salt)
+	require.NoError(t, err)
+	assert.Len(t, key, 64)
+	same, err := DV1{}.KDF512WithParams([]byte("password"), salt, DefaultArgon2Params)
+	require.NoError(t, err)
+	assert.Equal(t, key, same)
+
+	cheap, err := DV1{}.KDF512WithParams([]byte("password"), salt, Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 1})
+	require.NoError(t, err)
+	assert.NotEqual(t, key, cheap)
+
+	_, err = DV1{}.KDF512([]byte("password"), salt[:8])
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = DV1{}.KDF512WithParams([]byte("password"), salt, Argon2Params{Time: 1, Memory: 4, Threads: 1})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = DV1{}.KDF512WithParams([]byte("password"), salt, Argon2Params{Time: 0, Memory: 8 * 1024, Threads: 1})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = DV1{}.KDF512WithParams([]byte("password"), salt, Argon2Params{Time: 9, Memory: 8 * 1024, Threads: 1})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = DV1{}.KDF512WithParams([]byte("password"), salt, Argon2Params{Time: 1, Memory: 256*1024 + 1, Threads: 1})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = DV1{}.KDF512WithParams([]byte("password"), salt, Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 0})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = DV1{}.KDF512WithParams([]byte("password"), salt, Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 17})
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestCalibrateArgon2(t *testing.T) {
+	params, err := CalibrateArgon2(time.Millisecond, 1024, 1)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1024), params.Memory)
+	assert.Equal(t, uint8(1), params.Threads)
+	assert.GreaterOrEqual(t, params.Time, uint32(1))
+
+	slow, err := CalibrateArgon2(200*time.Millisecond, 1024, 1)
+	require.NoError(t, err)
+	assert.Greater(t, slow.Time, params.Time)
+
+	_, err = CalibrateArgon2(time.Second, 1, 1)
+	assert.Error(t, err)
+}
+
 func TestDV1_SignVerify(t *testing.T) {
 	key := make([]byte, ed25519.SeedSize)
 	_, err := io.ReadFull(rand.Reader, key)
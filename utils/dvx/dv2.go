@@ -0,0 +1,26 @@
+package dvx
+
+// DV2 uses the same primitives as DV1, but authenticates the version "dv2"
+// as part of the additional data of ciphers. Together with DV2, Protocol
+// separates the domains of all key derivations (see Protocol).
+type DV2 struct {
+	DV1
+}
+
+func (d DV2) Encrypt(key []byte, data []byte) (cipher []byte, err error) {
+	return seal("dv2", key, data, nil)
+}
+
+func (d DV2) Decrypt(key []byte, cipher []byte) (data []byte, err error) {
+	return open("dv2", key, cipher, nil)
+}
+
+// EncryptTo appends the cipher of data to dst (see AppendPrimitive).
+func (d DV2) EncryptTo(dst []byte, key []byte, data []byte) (cipher []byte, err error) {
+	return sealTo(dst, "dv2", key, data, nil)
+}
+
+// DecryptTo appends the data of cipher to dst (see AppendPrimitive).
+func (d DV2) DecryptTo(dst []byte, key []byte, cipher []byte) (data []byte, err error) {
+	return openTo(dst, "dv2", key, cipher, nil)
+}
@@ -1,5 +1,7 @@
 package dvx
 
+import "golang.org/x/crypto/chacha20poly1305"
+
 // Primitive is a low level cryptographic contract.
 type Primitive interface {
 	KDF512(password []byte, salt []byte) (key []byte, err error)
@@ -10,3 +12,19 @@ type Primitive interface {
 	Sign(privateKey []byte, message []byte) (signature []byte, err error)
 	Verify(publicKey []byte, message []byte, signature []byte) (valid bool, err error)
 }
+
+// CipherOverhead is the amount of bytes a cipher of Encrypt is longer than
+// its data: the nonce and the 16 byte Poly1305 authentication tag.
+const CipherOverhead = chacha20poly1305.NonceSizeX + 16
+
+// AppendPrimitive is implemented by Primitives (like DV1 and DV2) that append
+// their results to a buffer of the caller, so services can reuse buffers in
+// hot paths. With enough free capacity in dst (CipherOverhead plus the length
+// of data for EncryptTo, 32 or 64 bytes for MAC256To and MAC512To) they don't
+// allocate. dst must not overlap with the input.
+type AppendPrimitive interface {
+	MAC256To(dst []byte, key []byte, message []byte) (tag []byte, err error)
+	MAC512To(dst []byte, key []byte, message []byte) (tag []byte, err error)
+	EncryptTo(dst []byte, key []byte, data []byte) (cipher []byte, err error)
+	DecryptTo(dst []byte, key []byte, cipher []byte) (data []byte, err error)
+}
@@ -0,0 +1,293 @@
+// Package dvxtest provides Fake, a deterministic in-memory implementation of
+// dvx.Crypto for unit tests of code that depends on dvx. It needs no key
+// material and uses no random nonces: equal inputs always result in equal
+// ciphertexts, signatures, tags, TOTP ids and tokens, so tests can compare
+// them with fixed expectations.
+//
+// Fake doesn't protect anything. Ciphertexts and tokens contain their data in
+// plaintext, together with a checksum that binds them to their keyRing, and
+// signatures can be forged by anyone. Never use it outside of tests.
+package dvxtest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"fmt"
+	"sync"
+
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/totp"
+)
+
+// checksumSize is the size of the checksums of ciphertexts and tokens
+const checksumSize = 8
+
+// Call is a recorded call of a Fake method.
+type Call struct {
+	// Method is the name of the called method without the Context suffix.
+	// For example: "Encrypt"
+	Method string
+	// KeyRing is the keyRing passed to the method. It is empty for VerifyPK.
+	KeyRing string
+}
+
+// Fake is a deterministic dvx.Crypto that records its calls. The zero value is
+// ready to use and safe for concurrent use.
+//
+// Like dvx.Protocol, Decrypt, Detokenize and Verify fail for another keyRing
+// than the one used to create the ciphertext, token or signature, and
+// methods with a context return ctx.Err() if ctx is done. Errors belong to
+// the error classes of dvx (e.g. dvx.ErrAuthentication).
+type Fake struct {
+	// Err is returned by every call, if it isn't nil, e.g. to test the error
+	// handling of callers. Calls are recorded nevertheless.
+	Err error
+	// TOTPCode is the only code VerifyTOTP accepts. Defaults to "000000".
+	TOTPCode string
+
+	mu    sync.Mutex
+	calls []Call
+}
+
+var _ dvx.Crypto = (*Fake)(nil)
+
+// Calls returns all recorded calls in order.
+func (f *Fake) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Call(nil), f.calls...)
+}
+
+// Reset removes all recorded calls.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+}
+
+// record records a call and returns the error it should fail with.
+func (f *Fake) record(ctx context.Context, method string, keyRing string) error {
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Method: method, KeyRing: keyRing})
+	f.mu.Unlock()
+
+	if f.Err != nil {
+		return f.Err
+	}
+	return ctx.Err()
+}
+
+// digest returns a hash of purpose, keyRing and data with size bytes.
+func digest(purpose string, keyRing string, data []byte, size int) []byte {
+	buf := make([]byte, 0, len(purpose)+len(keyRing)+len(data)+2)
+	buf = append(append(buf, purpose...), 0)
+	buf = append(append(buf, keyRing...), 0)
+	buf = append(buf, data...)
+
+	if size <= sha256.Size {
+		sum := sha256.Sum256(buf)
+		return sum[:size]
+	}
+	sum := sha512.Sum512(buf)
+	return sum[:size]
+}
+
+// seal returns the checksum of data and data itself.
+func seal(purpose string, keyRing string, data []byte) []byte {
+	return append(digest(purpose, keyRing, data, checksumSize), data...)
+}
+
+// open verifies the checksum of sealed and returns its data.
+func open(purpose string, keyRing string, sealed []byte) ([]byte, error) {
+	if len(sealed) < checksumSize {
+		return nil, fmt.Errorf("dvxtest: %s too short: %w", purpose, dvx.ErrInvalidFormat)
+	}
+	data := sealed[checksumSize:]
+	if subtle.ConstantTimeCompare(sealed[:checksumSize], digest(purpose, keyRing, data, checksumSize)) != 1 {
+		return nil, fmt.Errorf("dvxtest: %s of another keyRing: %w", purpose, dvx.ErrAuthentication)
+	}
+	return append([]byte(nil), data...), nil
+}
+
+func (f *Fake) Encrypt(keyRing string, data []byte) (ciphertext string, err error) {
+	return f.EncryptContext(context.Background(), keyRing, data)
+}
+
+// EncryptContext returns a ciphertext of the form
+// "dv1.enc.<checksum || data>", with data in plaintext.
+func (f *Fake) EncryptContext(ctx context.Context, keyRing string, data []byte) (ciphertext string, err error) {
+	if err := f.record(ctx, "Encrypt", keyRing); err != nil {
+		return "", err
+	}
+	return dvx.Encode(dvx.Encrypted, seal("enc", keyRing, data)), nil
+}
+
+func (f *Fake) Decrypt(keyRing string, ciphertext string) (data []byte, err error) {
+	return f.DecryptContext(context.Background(), keyRing, ciphertext)
+}
+
+func (f *Fake) DecryptContext(ctx context.Context, keyRing string, ciphertext string) (data []byte, err error) {
+	if err := f.record(ctx, "Decrypt", keyRing); err != nil {
+		return nil, err
+	}
+	_, sealed, err := dvx.DecodeExpect(ciphertext, dvx.Encrypted)
+	if err != nil {
+		return nil, err
+	}
+	return open("enc", keyRing, sealed)
+}
+
+func (f *Fake) CreateSignKey(keyRing string) (publicKey []byte, err error) {
+	return f.CreateSignKeyContext(context.Background(), keyRing)
+}
+
+// CreateSignKeyContext returns a 32 byte public key, that is a hash of
+// keyRing.
+func (f *Fake) CreateSignKeyContext(ctx context.Context, keyRing string) (publicKey []byte, err error) {
+	if err := f.record(ctx, "CreateSignKey", keyRing); err != nil {
+		return nil, err
+	}
+	return signKey(keyRing), nil
+}
+
+func signKey(keyRing string) []byte {
+	return digest("sig", keyRing, nil, 32)
+}
+
+func signature(publicKey []byte, message []byte) []byte {
+	return digest("sig", string(publicKey), message, 64)
+}
+
+func (f *Fake) Sign(keyRing string, message []byte) (signature string, rawSignature []byte, err error) {
+	return f.SignContext(context.Background(), keyRing, message)
+}
+
+// SignContext returns a 64 byte signature, that is a hash of the public key
+// of keyRing (see CreateSignKey) and message.
+func (f *Fake) SignContext(ctx context.Context, keyRing string, message []byte) (sig string, rawSignature []byte, err error) {
+	if err := f.record(ctx, "Sign", keyRing); err != nil {
+		return "", nil, err
+	}
+	rawSignature = signature(signKey(keyRing), message)
+	return dvx.Encode(dvx.Signed, rawSignature), rawSignature, nil
+}
+
+func (f *Fake) Verify(keyRing string, message []byte, signature string) (valid bool, err error) {
+	return f.VerifyContext(context.Background(), keyRing, message, signature)
+}
+
+func (f *Fake) VerifyContext(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error) {
+	if err := f.record(ctx, "Verify", keyRing); err != nil {
+		return false, err
+	}
+	return verify(signKey(keyRing), message, signature)
+}
+
+func (f *Fake) VerifyPK(publicKey []byte, message []byte, signature string) (valid bool, err error) {
+	if err := f.record(context.Background(), "VerifyPK", ""); err != nil {
+		return false, err
+	}
+	return verify(publicKey, message, signature)
+}
+
+func verify(publicKey []byte, message []byte, sig string) (valid bool, err error) {
+	_, raw, err := dvx.DecodeExpect(sig, dvx.Signed)
+	if err != nil {
+		return false, err
+	}
+	return subtle.ConstantTimeCompare(raw, signature(publicKey, message)) == 1, nil
+}
+
+func (f *Fake) MAC(keyRing string, message []byte) (tag string, err error) {
+	return f.MACContext(context.Background(), keyRing, message)
+}
+
+// MACContext returns a 64 byte tag, that is a hash of keyRing and message.
+func (f *Fake) MACContext(ctx context.Context, keyRing string, message []byte) (tag string, err error) {
+	if err := f.record(ctx, "MAC", keyRing); err != nil {
+		return "", err
+	}
+	return dvx.Encode(dvx.Tagged, digest("mac", keyRing, message, 64)), nil
+}
+
+func (f *Fake) GenerateTOTP(keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error) {
+	return f.GenerateTOTPContext(context.Background(), keyRing, issuer, accountName, accountID)
+}
+
+// GenerateTOTPContext returns an id, that is a hash of keyRing and accountID,
+// and a valid URI with a secret derived from id. VerifyTOTP only accepts
+// TOTPCode though.
+func (f *Fake) GenerateTOTPContext(ctx context.Context, keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error) {
+	if err := f.record(ctx, "GenerateTOTP", keyRing); err != nil {
+		return "", "", err
+	}
+
+	rawID := totpID(keyRing, accountID)
+	uri = (&totp.TOTP{
+		Secret:      digest("totp", keyRing, rawID, 32),
+		Algorithm:   "SHA256",
+		Digits:      6,
+		Period:      30,
+		Issuer:      issuer,
+		AccountName: accountName,
+	}).URI()
+	return dvx.Encode(dvx.TOTP, rawID), uri, nil
+}
+
+func totpID(keyRing string, accountID string) []byte {
+	return digest("totp", keyRing, []byte(accountID), 32)
+}
+
+func (f *Fake) VerifyTOTP(keyRing string, id string, accountID string, code string) (valid bool, err error) {
+	return f.VerifyTOTPContext(context.Background(), keyRing, id, accountID, code)
+}
+
+// VerifyTOTPContext accepts TOTPCode (default "000000") for ids that
+// GenerateTOTP returned for keyRing and accountID.
+func (f *Fake) VerifyTOTPContext(ctx context.Context, keyRing string, id string, accountID string, code string) (valid bool, err error) {
+	if err := f.record(ctx, "VerifyTOTP", keyRing); err != nil {
+		return false, err
+	}
+	_, rawID, err := dvx.DecodeExpect(id, dvx.TOTP)
+	if err != nil {
+		return false, err
+	}
+
+	expected := f.TOTPCode
+	if expected == "" {
+		expected = "000000"
+	}
+	return bytes.Equal(rawID, totpID(keyRing, accountID)) && code == expected, nil
+}
+
+func (f *Fake) Tokenize(keyRing string, value string) (token string, err error) {
+	return f.TokenizeContext(context.Background(), keyRing, value)
+}
+
+// TokenizeContext returns a token of the form "dv2.tok.<checksum || value>",
+// with value in plaintext.
+func (f *Fake) TokenizeContext(ctx context.Context, keyRing string, value string) (token string, err error) {
+	if err := f.record(ctx, "Tokenize", keyRing); err != nil {
+		return "", err
+	}
+	return dvx.EncodeVersion("dv2", dvx.Tokenized, seal("tok", keyRing, []byte(value))), nil
+}
+
+func (f *Fake) Detokenize(keyRing string, token string) (value string, err error) {
+	return f.DetokenizeContext(context.Background(), keyRing, token)
+}
+
+func (f *Fake) DetokenizeContext(ctx context.Context, keyRing string, token string) (value string, err error) {
+	if err := f.record(ctx, "Detokenize", keyRing); err != nil {
+		return "", err
+	}
+	_, sealed, err := dvx.DecodeExpect(token, dvx.Tokenized)
+	if err != nil {
+		return "", err
+	}
+	data, err := open("tok", keyRing, sealed)
+	return string(data), err
+}
@@ -0,0 +1,166 @@
+package dvxtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+	"azoo.dev/utils/dvx/fieldcrypt"
+)
+
+func TestFake_Encrypt(t *testing.T) {
+	f := &Fake{}
+
+	c1, err := f.Encrypt("users/1", []byte("secret"))
+	require.NoError(t, err)
+	c2, err := f.Encrypt("users/1", []byte("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, c1, c2)
+	assert.Equal(t, "dv1.enc.", c1[:8])
+
+	data, err := f.Decrypt("users/1", c1)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), data)
+
+	_, err = f.Decrypt("users/2", c1)
+	assert.True(t, errors.Is(err, dvx.ErrAuthentication))
+
+	_, err = f.Decrypt("users/1", "dv2.enc.AA")
+	assert.True(t, errors.Is(err, dvx.ErrInvalidFormat))
+}
+
+func TestFake_Sign(t *testing.T) {
+	f := &Fake{}
+
+	pk, err := f.CreateSignKey("signer")
+	require.NoError(t, err)
+	assert.Len(t, pk, 32)
+
+	signature, raw, err := f.Sign("signer", []byte("message"))
+	require.NoError(t, err)
+	assert.Len(t, raw, 64)
+
+	valid, err := f.Verify("signer", []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = f.VerifyPK(pk, []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = f.Verify("other", []byte("message"), signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	valid, err = f.Verify("signer", []byte("changed"), signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+}
+
+func TestFake_MAC(t *testing.T) {
+	f := &Fake{}
+
+	t1, err := f.MAC("mac", []byte("message"))
+	require.NoError(t, err)
+	t2, err := f.MAC("mac", []byte("message"))
+	require.NoError(t, err)
+	t3, err := f.MAC("other", []byte("message"))
+	require.NoError(t, err)
+	assert.Equal(t, t1, t2)
+	assert.NotEqual(t, t1, t3)
+}
+
+func TestFake_TOTP(t *testing.T) {
+	f := &Fake{}
+
+	id, uri, err := f.GenerateTOTP("totp", "azoo", "user@example.com", "1")
+	require.NoError(t, err)
+	assert.Contains(t, uri, "otpauth://totp/")
+
+	valid, err := f.VerifyTOTP("totp", id, "1", "000000")
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = f.VerifyTOTP("totp", id, "2", "000000")
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	f.TOTPCode = "123456"
+	valid, err = f.VerifyTOTP("totp", id, "1", "000000")
+	require.NoError(t, err)
+	assert.False(t, valid)
+	valid, err = f.VerifyTOTP("totp", id, "1", "123456")
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+func TestFake_Tokenize(t *testing.T) {
+	f := &Fake{}
+
+	token, err := f.Tokenize("cards", "4111111111111111")
+	require.NoError(t, err)
+
+	value, err := f.Detokenize("cards", token)
+	require.NoError(t, err)
+	assert.Equal(t, "4111111111111111", value)
+
+	_, err = f.Detokenize("other", token)
+	assert.True(t, errors.Is(err, dvx.ErrAuthentication))
+}
+
+func TestFake_Calls(t *testing.T) {
+	f := &Fake{}
+
+	c, err := f.Encrypt("a", []byte("data"))
+	require.NoError(t, err)
+	_, err = f.DecryptContext(context.Background(), "a", c)
+	require.NoError(t, err)
+	_, err = f.VerifyPK(nil, nil, "dv2.sig.AA")
+	require.NoError(t, err)
+
+	assert.Equal(t, []Call{
+		{Method: "Encrypt", KeyRing: "a"},
+		{Method: "Decrypt", KeyRing: "a"},
+		{Method: "VerifyPK"},
+	}, f.Calls())
+
+	f.Reset()
+	assert.Empty(t, f.Calls())
+}
+
+func TestFake_Err(t *testing.T) {
+	f := &Fake{Err: dvx.ErrKeyDerivation}
+
+	_, err := f.Encrypt("a", []byte("data"))
+	assert.True(t, errors.Is(err, dvx.ErrKeyDerivation))
+	assert.Len(t, f.Calls(), 1)
+
+	f.Err = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = f.EncryptContext(ctx, "a", []byte("data"))
+	assert.True(t, errors.Is(err, context.Canceled))
+}
+
+func TestFake_Fieldcrypt(t *testing.T) {
+	type user struct {
+		ID    string
+		Email string `dvx:"encrypt,keyring=users/{ID}/email"`
+	}
+
+	f := &Fake{}
+	u := &user{ID: "1", Email: "user@example.com"}
+	require.NoError(t, fieldcrypt.Encrypt(context.Background(), f, u))
+	assert.NotEqual(t, "user@example.com", u.Email)
+
+	require.NoError(t, fieldcrypt.Decrypt(context.Background(), f, u))
+	assert.Equal(t, "user@example.com", u.Email)
+	assert.Equal(t, []Call{
+		{Method: "Encrypt", KeyRing: "users/1/email"},
+		{Method: "Decrypt", KeyRing: "users/1/email"},
+	}, f.Calls())
+}
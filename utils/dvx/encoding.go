@@ -18,36 +18,110 @@ const (
 	Tagged TypePrefix = "tag"
 	// TOTP is the TypePrefix for a TOTP selector id
 	TOTP TypePrefix = "totp"
+	// Tokenized is the TypePrefix for a deterministic token (see
+	// Protocol.Tokenize)
+	Tokenized TypePrefix = "tok"
+	// TimeLocked is the TypePrefix for encrypted content, that can't be
+	// decrypted before a point in time (see Protocol.EncryptNotBefore)
+	TimeLocked TypePrefix = "tlk"
+	// PasswordEncrypted is the TypePrefix for content encrypted with a key
+	// derived from a password (see EncryptWithPassword)
+	PasswordEncrypted TypePrefix = "penc"
+	// OneTime is the TypePrefix for a one-time token (see
+	// Protocol.IssueOneTimeToken)
+	OneTime TypePrefix = "ott"
+	// Sealed is the TypePrefix for a sealed session cookie (see
+	// Protocol.SealSession)
+	Sealed TypePrefix = "ses"
+	// TOTPRevoked is the TypePrefix for a revocation of a TOTP selector id
+	// (see Protocol.RevokeTOTP)
+	TOTPRevoked TypePrefix = "trev"
+	// WebAuthnChallenge is the TypePrefix for a WebAuthn challenge (see
+	// Protocol.IssueWebAuthnChallenge)
+	WebAuthnChallenge TypePrefix = "wac"
 )
 
 // Encode encodes a TypePrefix and associated data according to the current
-// major DVX version (DV1)
+// major DVX version (Version)
 func Encode(typePrefix TypePrefix, data []byte) string {
-	return fmt.Sprintf("%s.%s.%s", Version, typePrefix, base64.RawURLEncoding.EncodeToString(data))
+	return EncodeVersion(Version, typePrefix, data)
+}
+
+// EncodeVersion is like Encode, but encodes for version, e.g. a version of
+// RegisterPrimitive. version isn't validated.
+func EncodeVersion(version string, typePrefix TypePrefix, data []byte) string {
+	return fmt.Sprintf("%s.%s.%s", version, typePrefix, base64.RawURLEncoding.EncodeToString(data))
+}
+
+// EncodeWithFooter is like Encode, but encodes for dv2, the first version
+// with footers, and appends footer as fourth part:
+//   <version>.<type_prefix>.<data>.<footer>
+// The footer isn't encrypted, but Protocol authenticates it as part of
+// ciphers, signatures and tags (see Protocol.EncryptWithFooter). An empty
+// footer results in the same string as EncodeVersion("dv2", …).
+func EncodeWithFooter(typePrefix TypePrefix, data []byte, footer []byte) string {
+	return encodeVersionWithFooter(featureVersion, typePrefix, data, footer)
+}
+
+// encodeVersionWithFooter is like EncodeWithFooter, but encodes for version.
+func encodeVersionWithFooter(version string, typePrefix TypePrefix, data []byte, footer []byte) string {
+	s := EncodeVersion(version, typePrefix, data)
+	if len(footer) == 0 {
+		return s
+	}
+	return s + "." + base64.RawURLEncoding.EncodeToString(footer)
 }
 
 // Decode decodes a DVX string s into it's major version, TypePrefix,
 // associated data. If any errors occur Decode returns a descriptive
-// error.
+// error. DVX strings with a footer are rejected (see DecodeWithFooter).
 func Decode(s string) (version string, typePrefix TypePrefix, data []byte, err error) {
-	parts := strings.SplitN(s, ".", 3)
-	if len(parts) != 3 {
-		return "", "", nil, fmt.Errorf("dvx: invalid format. 3 parts expected")
+	version, typePrefix, data, footer, err := DecodeWithFooter(s)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if footer != nil {
+		return "", "", nil, errorf(ErrInvalidFormat, "dvx: invalid format. Unexpected footer")
+	}
+	return
+}
+
+// DecodeWithFooter is like Decode, but additionally accepts DVX strings with
+// a footer (see EncodeWithFooter) and returns it. footer is nil if s doesn't
+// have one. The footer isn't verified, e.g. it can be used to select the
+// keyRing before a Protocol authenticates it. Strings of EncodeCompact are
+// detected and decoded, too.
+func DecodeWithFooter(s string) (version string, typePrefix TypePrefix, data []byte, footer []byte, err error) {
+	if isCompact(s) {
+		version, typePrefix, data, err = decodeCompact(s)
+		return version, typePrefix, data, nil, err
+	}
+
+	parts := strings.SplitN(s, ".", 4)
+	if len(parts) < 3 {
+		return "", "", nil, nil, errorf(ErrInvalidFormat, "dvx: invalid format. 3 parts expected")
 	}
 
 	version = parts[0]
-	if version != "dv1" {
-		return "", "", nil, fmt.Errorf("dvx: invalid format. Unknown version: %q", version)
+	if lookupVersion(version) == nil {
+		return "", "", nil, nil, errorf(ErrInvalidFormat, "dvx: invalid format. Unknown version: %q", version)
 	}
 
 	typePrefix = TypePrefix(parts[1])
-	if typePrefix != Encrypted && typePrefix != Signed && typePrefix != Tagged && typePrefix != TOTP {
-		return "", "", nil, fmt.Errorf("dvx: invalid format. Unknown typePrefix: %q", typePrefix)
+	if typePrefix != Encrypted && typePrefix != Signed && typePrefix != Tagged && typePrefix != TOTP && typePrefix != Tokenized && typePrefix != TimeLocked && typePrefix != PasswordEncrypted && typePrefix != OneTime && typePrefix != Sealed && typePrefix != TOTPRevoked && typePrefix != WebAuthnChallenge {
+		return "", "", nil, nil, errorf(ErrInvalidFormat, "dvx: invalid format. Unknown typePrefix: %q", typePrefix)
 	}
 
 	data, err = base64.RawURLEncoding.DecodeString(parts[2])
 	if err != nil {
-		return "", "", nil, fmt.Errorf("dvx: invalid format. Data not raw base64url: %v", err)
+		return "", "", nil, nil, errorf(ErrInvalidFormat, "dvx: invalid format. Data not raw base64url: %v", err)
+	}
+
+	if len(parts) == 4 {
+		footer, err = base64.RawURLEncoding.DecodeString(parts[3])
+		if err != nil || len(footer) == 0 {
+			return "", "", nil, nil, errorf(ErrInvalidFormat, "dvx: invalid format. Footer not raw base64url or empty")
+		}
 	}
 
 	return
@@ -62,7 +136,20 @@ func DecodeExpect(s string, expected TypePrefix) (version string, data []byte, e
 		return "", nil, err
 	}
 	if p != expected {
-		return "", nil, fmt.Errorf("dvx: invalid format. Incorrect typePrefix")
+		return "", nil, errorf(ErrInvalidFormat, "dvx: invalid format. Incorrect typePrefix")
 	}
 	return v, d, nil
 }
+
+// decodeExpectWithFooter is like DecodeExpect, but accepts DVX strings with a
+// footer.
+func decodeExpectWithFooter(s string, expected TypePrefix) (version string, data []byte, footer []byte, err error) {
+	v, p, d, f, err := DecodeWithFooter(s)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	if p != expected {
+		return "", nil, nil, errorf(ErrInvalidFormat, "dvx: invalid format. Incorrect typePrefix")
+	}
+	return v, d, f, nil
+}
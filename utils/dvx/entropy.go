@@ -0,0 +1,161 @@
+package dvx
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"math/bits"
+	"sync"
+	"time"
+)
+
+const (
+	// entropySampleSize is the size of the samples of the entropy check:
+	// the 20000 bits of the statistical tests of FIPS 140-2.
+	entropySampleSize = 2500
+	// entropyTimeout is the time in which reading a sample must complete.
+	// getrandom blocks until the entropy pool of the kernel is initialized,
+	// which can take minutes in minimal VMs and containers without a
+	// hardware RNG.
+	entropyTimeout = time.Second
+	// entropyMaxRun is the length from which a run of equal bits fails the
+	// long run test.
+	entropyMaxRun = 26
+)
+
+// entropyHealth is the result of the last entropy check of SelfTest. It is
+// shared by the copies of WithVersion.
+type entropyHealth struct {
+	mu  sync.RWMutex
+	err error
+}
+
+func (e *entropyHealth) set(err error) {
+	e.mu.Lock()
+	e.err = err
+	e.mu.Unlock()
+}
+
+// selfTestEntropy runs testEntropy on crypto/rand and records its result for
+// checkEntropy. A ctx that is done before the check completes doesn't change
+// the recorded result.
+func (p *Protocol) selfTestEntropy(ctx context.Context) error {
+	err := testEntropy(ctx, rand.Reader, entropyTimeout)
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if p.entropy != nil {
+		p.entropy.set(err)
+	}
+	if err != nil {
+		return errorf(ErrSelfTest, "dvx: self-test entropy: %v", err)
+	}
+	return nil
+}
+
+// checkEntropy fails with ErrRandomness if the last entropy check of
+// SelfTest failed, so no tokens are generated with suspect randomness.
+func (p *Protocol) checkEntropy() error {
+	if p.entropy == nil {
+		return nil
+	}
+	p.entropy.mu.RLock()
+	err := p.entropy.err
+	p.entropy.mu.RUnlock()
+	if err != nil {
+		return errorf(ErrRandomness, "dvx: refusing to generate tokens with suspect entropy: %v", err)
+	}
+	return nil
+}
+
+// testEntropy reads two samples from r and checks that neither read blocks
+// for longer than timeout, the samples differ and at least one of them passes
+// the monobit, poker and long run tests of FIPS 140-2. A healthy source fails
+// a test with a probability of about 1e-6, a broken one (e.g. returning
+// zeros, a counter or a repeated buffer) fails all of them.
+func testEntropy(ctx context.Context, r io.Reader, timeout time.Duration) error {
+	a, err := readEntropy(ctx, r, timeout)
+	if err != nil {
+		return err
+	}
+	b, err := readEntropy(ctx, r, timeout)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(a, b) {
+		return fmt.Errorf("two samples of %d random bytes are equal", entropySampleSize)
+	}
+	if err := testSample(a); err != nil {
+		if testSample(b) != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readEntropy reads a sample from r and fails if this takes longer than
+// timeout. A blocked read keeps its go routine until it returns.
+func readEntropy(ctx context.Context, r io.Reader, timeout time.Duration) ([]byte, error) {
+	sample := make([]byte, entropySampleSize)
+	done := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		_, err := io.ReadFull(r, sample)
+		done <- err
+	}()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	select {
+	case err := <-done:
+		if err != nil {
+			return nil, fmt.Errorf("reading randomness failed: %v", err)
+		}
+		return sample, nil
+	case <-t.C:
+		return nil, fmt.Errorf("reading %d random bytes blocked for %v (e.g. getrandom waiting for the entropy pool of the kernel)", entropySampleSize, time.Since(start).Round(time.Millisecond))
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// testSample runs the monobit, poker and long run tests of FIPS 140-2 on the
+// 20000 bits of sample.
+func testSample(sample []byte) error {
+	ones := 0
+	var nibbles [16]int
+	for _, b := range sample {
+		ones += bits.OnesCount8(b)
+		nibbles[b>>4]++
+		nibbles[b&0x0f]++
+	}
+	if ones <= 9725 || ones >= 10275 {
+		return fmt.Errorf("random bytes failed the monobit test (%d of 20000 bits set)", ones)
+	}
+
+	sum := 0
+	for _, n := range nibbles {
+		sum += n * n
+	}
+	if poker := 16.0/5000.0*float64(sum) - 5000; poker <= 2.16 || poker >= 46.17 {
+		return fmt.Errorf("random bytes failed the poker test (%.2f)", poker)
+	}
+
+	run, last := 0, -1
+	for _, b := range sample {
+		for i := 7; i >= 0; i-- {
+			bit := int(b>>uint(i)) & 1
+			if bit == last {
+				run++
+			} else {
+				run, last = 1, bit
+			}
+			if run >= entropyMaxRun {
+				return fmt.Errorf("random bytes failed the long run test (%d equal bits)", run)
+			}
+		}
+	}
+	return nil
+}
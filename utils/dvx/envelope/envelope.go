@@ -0,0 +1,290 @@
+// Package envelope encrypts data to recipients whose private keys never
+// leave a hardware token, so operators can hold break-glass decryption keys
+// that don't live on any server:
+//
+//   // seal to a YubiKey PIV slot (9d) and a FIDO2 authenticator
+//   sealed, err := envelope.Seal(data,
+//     &envelope.PIVRecipient{PublicKey: pub},
+//     &envelope.FIDO2Recipient{CredentialID: credID, Device: authenticator})
+//
+//   // open with the YubiKey
+//   data, err := envelope.Open(sealed, &envelope.PIVIdentity{Key: key, PublicKey: pub})
+//
+// Every envelope has a random file key, which encrypts the data and is
+// wrapped separately for every recipient. Any one of them can open the
+// envelope. The package doesn't talk to tokens itself: PIVIdentity uses an
+// ECDHKey (implemented by the private keys of PIV libraries like
+// github.com/go-piv/piv-go), FIDO2Recipient and FIDO2Identity an
+// HMACSecretDevice (an adapter around libfido2 or a platform API), so the
+// dvx module stays free of PC/SC and USB HID dependencies.
+//
+// An envelope is a header followed by the encrypted data:
+//
+//   dvxenvelope v1
+//   -> <type> <arguments …> <wrapped file key>
+//   …
+//   ---
+//   <nonce><ciphertext>
+//
+// Arguments and wrapped file keys are base64 (raw URL encoding). The data is
+// encrypted with XChaCha20-Poly1305 under a key derived from the file key,
+// with the whole header as additional data, so no recipient can be removed,
+// added or changed without failing Open.
+package envelope
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const (
+	// magic is the first line of every envelope
+	magic = "dvxenvelope v1"
+	// headerEnd is the line that terminates the header
+	headerEnd = "---"
+	// fileKeySize is the size of the random file key of an envelope
+	fileKeySize = 32
+	// maxStanzas limits the amount of recipients Open processes, so a
+	// crafted header can't trigger an unbounded amount of token operations
+	maxStanzas = 64
+)
+
+// ErrNoIdentity is returned by Open if none of the identities can unwrap the
+// file key of the envelope.
+var ErrNoIdentity = errors.New("envelope: no identity matches a recipient of the envelope")
+
+// ErrIncorrectIdentity is returned by Identity.Unwrap for stanzas of other
+// recipients, so Open tries the next one.
+var ErrIncorrectIdentity = errors.New("envelope: stanza is for another recipient")
+
+// Stanza is the file key wrapped for one recipient.
+type Stanza struct {
+	// Type identifies the Recipient implementation. For example: "piv-p256"
+	Type string
+	// Args are the public arguments needed to unwrap Body, e.g. an ephemeral
+	// public key.
+	Args [][]byte
+	// Body is the wrapped file key.
+	Body []byte
+}
+
+// Recipient wraps the file key of an envelope for one holder.
+type Recipient interface {
+	Wrap(fileKey []byte) (*Stanza, error)
+}
+
+// Identity unwraps the file key from a Stanza created for it. For stanzas of
+// other recipients it returns ErrIncorrectIdentity.
+type Identity interface {
+	Unwrap(stanza *Stanza) (fileKey []byte, err error)
+}
+
+// Seal encrypts data to all recipients. Each of them can Open the envelope
+// on its own.
+func Seal(data []byte, recipients ...Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("envelope: at least one recipient is required")
+	}
+	if len(recipients) > maxStanzas {
+		return nil, fmt.Errorf("envelope: at most %d recipients are supported", maxStanzas)
+	}
+
+	fileKey := make([]byte, fileKeySize)
+	if _, err := io.ReadFull(rand.Reader, fileKey); err != nil {
+		return nil, fmt.Errorf("envelope: failed to read random file key: %w", err)
+	}
+	defer wipe(fileKey)
+
+	header := strings.Builder{}
+	header.WriteString(magic + "\n")
+	for _, r := range recipients {
+		stanza, err := r.Wrap(fileKey)
+		if err != nil {
+			return nil, fmt.Errorf("envelope: failed to wrap file key: %w", err)
+		}
+		if err := writeStanza(&header, stanza); err != nil {
+			return nil, err
+		}
+	}
+	header.WriteString(headerEnd + "\n")
+
+	aead, err := chacha20poly1305.NewX(deriveKey(fileKey, nil, "payload"))
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %w", err)
+	}
+	out := make([]byte, header.Len()+aead.NonceSize(), header.Len()+aead.NonceSize()+len(data)+aead.Overhead())
+	copy(out, header.String())
+	nonce := out[header.Len():]
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("envelope: failed to read random nonce: %w", err)
+	}
+	return aead.Seal(out, nonce, data, out[:header.Len()]), nil
+}
+
+// Open decrypts an envelope of Seal with the first identity that matches
+// one of its recipients. If no identity matches, it fails with ErrNoIdentity,
+// if a matching identity fails (e.g. the token isn't attached) with its
+// error.
+func Open(envelope []byte, identities ...Identity) ([]byte, error) {
+	stanzas, headerLen, err := parseHeader(envelope)
+	if err != nil {
+		return nil, err
+	}
+
+	var fileKey []byte
+	var unwrapErr error
+unwrap:
+	for _, stanza := range stanzas {
+		for _, id := range identities {
+			key, err := id.Unwrap(stanza)
+			if errors.Is(err, ErrIncorrectIdentity) {
+				continue
+			}
+			if err != nil {
+				if unwrapErr == nil {
+					unwrapErr = fmt.Errorf("envelope: failed to unwrap file key: %w", err)
+				}
+				continue
+			}
+			if len(key) != fileKeySize {
+				return nil, fmt.Errorf("envelope: unwrapped file key has %d bytes", len(key))
+			}
+			fileKey = key
+			break unwrap
+		}
+	}
+	if fileKey == nil {
+		if unwrapErr != nil {
+			return nil, unwrapErr
+		}
+		return nil, ErrNoIdentity
+	}
+	defer wipe(fileKey)
+
+	aead, err := chacha20poly1305.NewX(deriveKey(fileKey, nil, "payload"))
+	if err != nil {
+		return nil, fmt.Errorf("envelope: %w", err)
+	}
+	rest := envelope[headerLen:]
+	if len(rest) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("envelope: envelope is too short")
+	}
+	data, err := aead.Open(nil, rest[:aead.NonceSize()], rest[aead.NonceSize():], envelope[:headerLen])
+	if err != nil {
+		return nil, errors.New("envelope: failed to decrypt envelope (invalid file key or modified envelope)")
+	}
+	return data, nil
+}
+
+// writeStanza appends the header line of stanza to b.
+func writeStanza(b *strings.Builder, stanza *Stanza) error {
+	if stanza.Type == "" || strings.ContainsAny(stanza.Type, " \n") {
+		return fmt.Errorf("envelope: invalid stanza type %q", stanza.Type)
+	}
+	b.WriteString("-> ")
+	b.WriteString(stanza.Type)
+	for _, arg := range stanza.Args {
+		b.WriteByte(' ')
+		b.WriteString(encode(arg))
+	}
+	b.WriteByte(' ')
+	b.WriteString(encode(stanza.Body))
+	b.WriteByte('\n')
+	return nil
+}
+
+// parseHeader parses the stanzas of the header of envelope and returns the
+// length of the header.
+func parseHeader(envelope []byte) (stanzas []*Stanza, headerLen int, err error) {
+	rest := envelope
+	line := func() (string, bool) {
+		i := bytes.IndexByte(rest, '\n')
+		if i == -1 {
+			return "", false
+		}
+		l := string(rest[:i])
+		rest = rest[i+1:]
+		return l, true
+	}
+
+	if l, ok := line(); !ok || l != magic {
+		return nil, 0, errors.New("envelope: not a dvx envelope")
+	}
+	for {
+		l, ok := line()
+		if !ok {
+			return nil, 0, errors.New("envelope: header isn't terminated")
+		}
+		if l == headerEnd {
+			break
+		}
+		if len(stanzas) == maxStanzas {
+			return nil, 0, fmt.Errorf("envelope: more than %d recipients", maxStanzas)
+		}
+
+		fields := strings.Split(l, " ")
+		if len(fields) < 3 || fields[0] != "->" || fields[1] == "" {
+			return nil, 0, fmt.Errorf("envelope: invalid stanza %q", l)
+		}
+		stanza := &Stanza{Type: fields[1]}
+		for _, field := range fields[2:] {
+			v, err := decode(field)
+			if err != nil {
+				return nil, 0, fmt.Errorf("envelope: invalid stanza %q: %w", l, err)
+			}
+			stanza.Args = append(stanza.Args, v)
+		}
+		stanza.Body = stanza.Args[len(stanza.Args)-1]
+		stanza.Args = stanza.Args[:len(stanza.Args)-1]
+		stanzas = append(stanzas, stanza)
+	}
+	if len(stanzas) == 0 {
+		return nil, 0, errors.New("envelope: envelope has no recipients")
+	}
+	return stanzas, len(envelope) - len(rest), nil
+}
+
+// deriveKey derives a 32 byte key from secret with HKDF-SHA256 and the
+// purpose as info.
+func deriveKey(secret []byte, salt []byte, purpose string) []byte {
+	key := make([]byte, chacha20poly1305.KeySize)
+	_, _ = io.ReadFull(hkdf.New(sha256.New, secret, salt, []byte(magic+" "+purpose)), key) // never fails for 32 bytes
+	return key
+}
+
+// wrapFileKey encrypts fileKey with wrapKey. Every wrapKey is used once,
+// therefore the nonce is zero.
+func wrapFileKey(wrapKey []byte, fileKey []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, aead.NonceSize()), fileKey, nil), nil
+}
+
+// unwrapFileKey reverses wrapFileKey.
+func unwrapFileKey(wrapKey []byte, body []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(wrapKey)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, aead.NonceSize()), body, nil)
+	if err != nil {
+		return nil, errors.New("wrapped file key doesn't authenticate")
+	}
+	return fileKey, nil
+}
+
+func wipe(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
@@ -0,0 +1,124 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAuthenticator implements HMACSecretDevice with a secret per
+// credential, like the hmac-secret extension of a FIDO2 authenticator.
+type fakeAuthenticator struct {
+	secret []byte
+	err    error
+	calls  int
+}
+
+func (a *fakeAuthenticator) HMACSecret(credentialID []byte, salt []byte) ([]byte, error) {
+	a.calls++
+	if a.err != nil {
+		return nil, a.err
+	}
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write(credentialID)
+	mac.Write(salt)
+	return mac.Sum(nil), nil
+}
+
+func newPIV(t *testing.T) (*PIVRecipient, *PIVIdentity) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return &PIVRecipient{PublicKey: &key.PublicKey}, &PIVIdentity{Key: SoftwareECDHKey(key), PublicKey: &key.PublicKey}
+}
+
+func TestSealOpen(t *testing.T) {
+	data := []byte("break-glass root key")
+	pivRecipient, pivIdentity := newPIV(t)
+	_, otherPIV := newPIV(t)
+	authenticator := &fakeAuthenticator{secret: []byte("authenticator secret")}
+	fido2Recipient := &FIDO2Recipient{CredentialID: []byte("credential"), Device: authenticator}
+	fido2Identity := &FIDO2Identity{CredentialID: []byte("credential"), Device: authenticator}
+
+	sealed, err := Seal(data, pivRecipient, fido2Recipient)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(sealed, []byte("dvxenvelope v1\n-> piv-p256 ")))
+	assert.False(t, bytes.Contains(sealed, data))
+	assert.Equal(t, 1, authenticator.calls)
+
+	// every recipient opens the envelope on its own
+	opened, err := Open(sealed, pivIdentity)
+	require.NoError(t, err)
+	assert.Equal(t, data, opened)
+	opened, err = Open(sealed, otherPIV, fido2Identity)
+	require.NoError(t, err)
+	assert.Equal(t, data, opened)
+
+	// other identities don't match
+	_, err = Open(sealed, otherPIV, &FIDO2Identity{CredentialID: []byte("other"), Device: authenticator})
+	assert.True(t, errors.Is(err, ErrNoIdentity))
+
+	// errors of a matching token are returned
+	failing := &FIDO2Identity{CredentialID: []byte("credential"), Device: &fakeAuthenticator{err: errors.New("no touch")}}
+	_, err = Open(sealed, failing)
+	assert.EqualError(t, err, "envelope: failed to unwrap file key: no touch")
+
+	// a different authenticator with the same credential ID can't unwrap
+	wrong := &FIDO2Identity{CredentialID: []byte("credential"), Device: &fakeAuthenticator{secret: []byte("other")}}
+	_, err = Open(sealed, wrong)
+	assert.Error(t, err)
+}
+
+func TestOpen_Modified(t *testing.T) {
+	pivRecipient, pivIdentity := newPIV(t)
+	otherRecipient, _ := newPIV(t)
+	sealed, err := Seal([]byte("data"), pivRecipient, otherRecipient)
+	require.NoError(t, err)
+
+	// removing a recipient fails, as the header is authenticated
+	lines := bytes.SplitN(sealed, []byte("\n"), 4)
+	withoutRecipient := append(append(append([]byte{}, lines[0]...), '\n'), append(append(lines[1], '\n'), lines[3]...)...)
+	_, err = Open(withoutRecipient, pivIdentity)
+	assert.EqualError(t, err, "envelope: failed to decrypt envelope (invalid file key or modified envelope)")
+
+	// modified payload
+	modified := append([]byte{}, sealed...)
+	modified[len(modified)-1] ^= 1
+	_, err = Open(modified, pivIdentity)
+	assert.Error(t, err)
+
+	for name, envelope := range map[string]string{
+		"empty":         "",
+		"magic":         "dvxenvelope v2\n---\n",
+		"unterminated":  "dvxenvelope v1\n-> piv-p256 AAAA AAAA AAAA\n",
+		"no recipients": "dvxenvelope v1\n---\n",
+		"stanza":        "dvxenvelope v1\n-> piv-p256\n---\n",
+		"base64":        "dvxenvelope v1\n-> piv-p256 !!!! AAAA\n---\n",
+	} {
+		_, err := Open([]byte(envelope), pivIdentity)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestSeal_Invalid(t *testing.T) {
+	_, err := Seal([]byte("data"))
+	assert.Error(t, err)
+
+	_, err = Seal([]byte("data"), &PIVRecipient{})
+	assert.Error(t, err)
+
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, err = Seal([]byte("data"), &PIVRecipient{PublicKey: &p384.PublicKey})
+	assert.Error(t, err)
+
+	_, err = Seal([]byte("data"), &FIDO2Recipient{Device: &fakeAuthenticator{}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,232 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Stanza types of the recipients of this package.
+const (
+	// TypePIV is the stanza type of PIVRecipient.
+	TypePIV = "piv-p256"
+	// TypeFIDO2 is the stanza type of FIDO2Recipient.
+	TypeFIDO2 = "fido2-hmac-secret"
+)
+
+// pivTagSize is the size of the recipient tag of TypePIV stanzas.
+const pivTagSize = 4
+
+// hmacSecretSaltSize is the size of the salt of the FIDO2 hmac-secret
+// extension.
+const hmacSecretSaltSize = 32
+
+// ECDHKey is a P-256 private key that computes ECDH shared secrets, but
+// doesn't reveal itself, like the key of the PIV key management slot (9d)
+// of a YubiKey. *piv.ECDSAPrivateKey of github.com/go-piv/piv-go implements
+// it.
+type ECDHKey interface {
+	// SharedKey returns the X coordinate of the product of the private key
+	// and peer.
+	SharedKey(peer *ecdsa.PublicKey) ([]byte, error)
+}
+
+// PIVRecipient wraps the file key for the holder of a P-256 key, e.g. in the
+// PIV key management slot (9d) of a YubiKey: ECDH of an ephemeral key with
+// PublicKey, HKDF-SHA256 and ChaCha20-Poly1305. Sealing doesn't need the
+// token.
+type PIVRecipient struct {
+	// PublicKey is the P-256 public key of the slot, e.g. from its
+	// attestation certificate.
+	PublicKey *ecdsa.PublicKey
+}
+
+// Wrap implements Recipient.
+func (r *PIVRecipient) Wrap(fileKey []byte) (*Stanza, error) {
+	recipient, err := marshalP256(r.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	ephemeral, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := SoftwareECDHKey(ephemeral).SharedKey(r.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(shared)
+
+	ephemeralBytes := elliptic.MarshalCompressed(elliptic.P256(), ephemeral.X, ephemeral.Y)
+	body, err := wrapFileKey(pivWrapKey(shared, ephemeralBytes, recipient), fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Stanza{Type: TypePIV, Args: [][]byte{pivTag(recipient), ephemeralBytes}, Body: body}, nil
+}
+
+// PIVIdentity unwraps TypePIV stanzas of PublicKey with Key.
+type PIVIdentity struct {
+	// Key computes the shared secret on the token.
+	Key ECDHKey
+	// PublicKey is the public key of Key.
+	PublicKey *ecdsa.PublicKey
+}
+
+// Unwrap implements Identity.
+func (id *PIVIdentity) Unwrap(stanza *Stanza) ([]byte, error) {
+	if stanza.Type != TypePIV {
+		return nil, ErrIncorrectIdentity
+	}
+	recipient, err := marshalP256(id.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(stanza.Args) != 2 || subtle.ConstantTimeCompare(stanza.Args[0], pivTag(recipient)) != 1 {
+		return nil, ErrIncorrectIdentity
+	}
+
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), stanza.Args[1])
+	if x == nil {
+		return nil, errors.New("invalid ephemeral key in stanza")
+	}
+	shared, err := id.Key.SharedKey(&ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y})
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(shared)
+
+	return unwrapFileKey(pivWrapKey(shared, stanza.Args[1], recipient), stanza.Body)
+}
+
+// SoftwareECDHKey returns an ECDHKey of a P-256 private key in memory, e.g.
+// for a backup key stored offline, or for tests.
+func SoftwareECDHKey(key *ecdsa.PrivateKey) ECDHKey {
+	return softwareECDHKey{key: key}
+}
+
+type softwareECDHKey struct {
+	key *ecdsa.PrivateKey
+}
+
+func (k softwareECDHKey) SharedKey(peer *ecdsa.PublicKey) ([]byte, error) {
+	if k.key.Curve != elliptic.P256() || peer.Curve != elliptic.P256() {
+		return nil, errors.New("keys must be on P-256")
+	}
+	if !peer.Curve.IsOnCurve(peer.X, peer.Y) {
+		return nil, errors.New("peer key isn't on P-256")
+	}
+	x, _ := peer.Curve.ScalarMult(peer.X, peer.Y, k.key.D.Bytes())
+	return x.FillBytes(make([]byte, 32)), nil
+}
+
+func marshalP256(key *ecdsa.PublicKey) ([]byte, error) {
+	if key == nil || key.Curve != elliptic.P256() {
+		return nil, errors.New("PIV keys must be on P-256")
+	}
+	return elliptic.MarshalCompressed(elliptic.P256(), key.X, key.Y), nil
+}
+
+// pivTag identifies the recipient of a stanza without revealing its public
+// key in full.
+func pivTag(recipient []byte) []byte {
+	sum := sha256.Sum256(recipient)
+	return sum[:pivTagSize]
+}
+
+func pivWrapKey(shared []byte, ephemeral []byte, recipient []byte) []byte {
+	return deriveKey(shared, append(append([]byte{}, ephemeral...), recipient...), TypePIV)
+}
+
+// HMACSecretDevice evaluates the hmac-secret extension of a FIDO2
+// authenticator (CTAP2), which returns a secret of 32 bytes per credential
+// and salt that never leaves the authenticator otherwise. Implementations
+// usually require a touch (user presence) and possibly the PIN.
+type HMACSecretDevice interface {
+	HMACSecret(credentialID []byte, salt []byte) ([]byte, error)
+}
+
+// FIDO2Recipient wraps the file key with the hmac-secret of a credential of
+// a FIDO2 authenticator. Unlike PIVRecipient, sealing needs the
+// authenticator, as hmac-secret is symmetric.
+type FIDO2Recipient struct {
+	// CredentialID is the ID of a credential created with the hmac-secret
+	// extension enabled.
+	CredentialID []byte
+	// Device is the authenticator holding the credential.
+	Device HMACSecretDevice
+}
+
+// Wrap implements Recipient.
+func (r *FIDO2Recipient) Wrap(fileKey []byte) (*Stanza, error) {
+	if len(r.CredentialID) == 0 {
+		return nil, errors.New("FIDO2 credential ID is empty")
+	}
+	salt := make([]byte, hmacSecretSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	secret, err := hmacSecret(r.Device, r.CredentialID, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(secret)
+
+	body, err := wrapFileKey(deriveKey(secret, salt, TypeFIDO2), fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Stanza{Type: TypeFIDO2, Args: [][]byte{r.CredentialID, salt}, Body: body}, nil
+}
+
+// FIDO2Identity unwraps TypeFIDO2 stanzas of CredentialID with Device.
+type FIDO2Identity struct {
+	// CredentialID is the ID of the credential of FIDO2Recipient.
+	CredentialID []byte
+	// Device is the authenticator holding the credential.
+	Device HMACSecretDevice
+}
+
+// Unwrap implements Identity.
+func (id *FIDO2Identity) Unwrap(stanza *Stanza) ([]byte, error) {
+	if stanza.Type != TypeFIDO2 || len(stanza.Args) != 2 || !bytes.Equal(stanza.Args[0], id.CredentialID) {
+		return nil, ErrIncorrectIdentity
+	}
+	salt := stanza.Args[1]
+	if len(salt) != hmacSecretSaltSize {
+		return nil, fmt.Errorf("invalid hmac-secret salt of %d bytes", len(salt))
+	}
+	secret, err := hmacSecret(id.Device, id.CredentialID, salt)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(secret)
+
+	return unwrapFileKey(deriveKey(secret, salt, TypeFIDO2), stanza.Body)
+}
+
+func hmacSecret(device HMACSecretDevice, credentialID []byte, salt []byte) ([]byte, error) {
+	secret, err := device.HMACSecret(credentialID, salt)
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) != 32 {
+		return nil, fmt.Errorf("hmac-secret has %d instead of 32 bytes", len(secret))
+	}
+	return secret, nil
+}
+
+func encode(buf []byte) string {
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+func decode(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,77 @@
+package dvx
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvalidFormat is the class of errors caused by malformed dvx strings
+	// or raw ciphers, signatures and tags.
+	ErrInvalidFormat = errors.New("dvx: invalid format")
+	// ErrInvalidKey is the class of errors caused by keys that don't fit the
+	// requirements of a Primitive.
+	ErrInvalidKey = errors.New("dvx: invalid key")
+	// ErrAuthentication is the class of errors caused by ciphers that failed
+	// authentication, e.g. because they were tampered with or a different
+	// keyRing was used.
+	ErrAuthentication = errors.New("dvx: authentication failed")
+	// ErrRandomness is the class of errors caused by a failing CSPRNG.
+	ErrRandomness = errors.New("dvx: reading randomness failed")
+	// ErrKeyDerivation is the class of errors returned by a KeyPool.
+	ErrKeyDerivation = errors.New("dvx: key derivation failed")
+	// ErrSelfTest is the class of errors returned by Protocol.SelfTest.
+	ErrSelfTest = errors.New("dvx: self-test failed")
+	// ErrTimeLocked is the class of errors caused by time-locked ciphers (see
+	// Protocol.EncryptNotBefore) that can't be decrypted yet.
+	ErrTimeLocked = errors.New("dvx: time-locked")
+	// ErrKeyRingPolicy is the class of errors caused by keyRings that violate
+	// the KeyRingPolicy of a Protocol.
+	ErrKeyRingPolicy = errors.New("dvx: keyRing policy violated")
+	// ErrExpired is the class of errors caused by authenticated tokens that
+	// expired (see Protocol.RedeemOneTimeToken and Protocol.OpenSession).
+	ErrExpired = errors.New("dvx: expired")
+	// ErrRevoked is the class of errors caused by authenticated state that
+	// was revoked (see Protocol.RevokeTOTP).
+	ErrRevoked = errors.New("dvx: revoked")
+)
+
+// classError is an error that belongs to one of the error classes above. It
+// keeps its own message, but can be checked with errors.Is against its class.
+type classError struct {
+	class error
+	msg   string
+	cause error
+}
+
+func (e *classError) Error() string {
+	return e.msg
+}
+
+func (e *classError) Is(target error) bool {
+	return e.class == target
+}
+
+func (e *classError) Unwrap() error {
+	return e.cause
+}
+
+// errorf formats an error message like fmt.Errorf and assigns it to class.
+func errorf(class error, format string, a ...interface{}) error {
+	err := fmt.Errorf(format, a...)
+	return &classError{
+		class: class,
+		msg:   err.Error(),
+		cause: errors.Unwrap(err),
+	}
+}
+
+// keyDerivationError assigns err returned by a KeyPool to ErrKeyDerivation.
+// The class of err itself (if any) stays accessible through errors.Is.
+func keyDerivationError(err error) error {
+	return &classError{
+		class: ErrKeyDerivation,
+		msg:   err.Error(),
+		cause: err,
+	}
+}
@@ -5,6 +5,7 @@ go 1.16
 require (
 	azoo.dev/utils/dvx v0.0.0-20210830122933-a2fbee6dbd6c
 	azoo.dev/utils/dvx/hsm v0.0.0-20210830122933-a2fbee6dbd6c
+	azoo.dev/utils/dvx/liblog v0.0.0-20210830122933-a2fbee6dbd6c
 	azoo.dev/utils/dvx/tearc v0.0.0-20210830122933-a2fbee6dbd6c
 	azoo.dev/utils/qr v0.0.0-20210830122933-a2fbee6dbd6c
 	azoo.dev/utils/tearc v0.0.0-20210830122933-a2fbee6dbd6c // indirect
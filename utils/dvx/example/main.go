@@ -14,6 +14,7 @@ import (
 
 	"azoo.dev/utils/dvx"
 	"azoo.dev/utils/dvx/hsm"
+	"azoo.dev/utils/dvx/liblog"
 	"azoo.dev/utils/dvx/tearc"
 	"azoo.dev/utils/qr"
 )
@@ -76,7 +77,7 @@ func initDVXProtocol(generateRoot bool) *dvx.Protocol {
 			root = buffer
 		}
 
-		rootPool = dvx.WrapDVXAsKeyPool(dvx.DV1{}, root, contract.MustNewStd())
+		rootPool = dvx.WrapDVXAsKeyPool(dvx.DV1{}, root, liblog.Wrap(contract.MustNewStd()))
 	case "2":
 		var err error
 		rootPool, err = hsm.New(&hsm.Config{
@@ -85,7 +86,7 @@ func initDVXProtocol(generateRoot bool) *dvx.Protocol {
 			UserPin:      "1234",
 			RootKeyID:    "dvx_root",
 			RootKeyLabel: "dvx_root",
-		}, contract.MustNewStd())
+		}, liblog.Wrap(contract.MustNewStd()))
 		if err != nil {
 			fmt.Println(err)
 			os.Exit(1)
@@ -101,7 +102,7 @@ func initDVXProtocol(generateRoot bool) *dvx.Protocol {
 		BucketMinTick: 5 * time.Second,
 		BucketMaxTick: 20 * time.Second,
 		AliveTime:     1 * time.Minute,
-	}, rootPool, contract.MustNewStd())
+	}, rootPool, liblog.Wrap(contract.MustNewStd()))
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
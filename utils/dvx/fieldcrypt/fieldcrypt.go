@@ -0,0 +1,261 @@
+// Package fieldcrypt encrypts and decrypts annotated fields of structs in
+// place with a dvx.Protocol. Every field is encrypted with its own keyRing,
+// which can be built from other fields of the same struct:
+//
+//   type User struct {
+//     ID    string
+//     Email string `dvx:"encrypt,keyring=users/{ID}/email"`
+//     Notes []byte `dvx:"encrypt,keyring=users/{ID}"`
+//   }
+//
+// Encrypt replaces the plaintext of Email and Notes with dvx ciphertexts
+// (like "dv1.enc.…"), Decrypt reverses it. Encrypted fields must be of type
+// string or []byte. Fields referenced in a keyring ({ID}) are formatted with
+// fmt.Sprint and must not be encrypted themselves. Fields of struct or
+// pointer to struct type without a tag are processed recursively, and their
+// keyrings reference their own fields.
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"azoo.dev/utils/dvx"
+)
+
+// TagName is the name of the struct tag read by fieldcrypt.
+const TagName = "dvx"
+
+// Encrypt encrypts all fields of the struct v points to, that are tagged with
+// `dvx:"encrypt,keyring=…"`, in place. Empty fields are left unchanged. If an
+// error occurs some fields may already be encrypted.
+func Encrypt(ctx context.Context, p dvx.Crypto, v interface{}) error {
+	return walk(v, func(keyRing string, field reflect.Value) error {
+		token, err := p.EncryptContext(ctx, keyRing, fieldBytes(field))
+		if err != nil {
+			return err
+		}
+		setField(field, []byte(token))
+		return nil
+	})
+}
+
+// Decrypt decrypts all fields of the struct v points to, that are tagged with
+// `dvx:"encrypt,keyring=…"`, in place. Empty fields are left unchanged. If an
+// error occurs some fields may already be decrypted.
+func Decrypt(ctx context.Context, p dvx.Crypto, v interface{}) error {
+	return walk(v, func(keyRing string, field reflect.Value) error {
+		data, err := p.DecryptContext(ctx, keyRing, string(fieldBytes(field)))
+		if err != nil {
+			return err
+		}
+		setField(field, data)
+		return nil
+	})
+}
+
+func fieldBytes(field reflect.Value) []byte {
+	if field.Kind() == reflect.String {
+		return []byte(field.String())
+	}
+	return field.Bytes()
+}
+
+func setField(field reflect.Value, buf []byte) {
+	if field.Kind() == reflect.String {
+		field.SetString(string(buf))
+	} else {
+		field.SetBytes(buf)
+	}
+}
+
+// walk calls fn for every non-empty encrypted field of the struct v points to
+// and its nested structs.
+func walk(v interface{}, fn func(keyRing string, field reflect.Value) error) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("fieldcrypt: v must be a non-nil pointer to a struct, got %T", v)
+	}
+	return walkStruct(rv.Elem(), fn)
+}
+
+func walkStruct(v reflect.Value, fn func(keyRing string, field reflect.Value) error) error {
+	info, err := structInfoOf(v.Type())
+	if err != nil {
+		return err
+	}
+
+	for _, f := range info.fields {
+		field := v.Field(f.index)
+		if field.Len() == 0 {
+			continue
+		}
+		if err := fn(f.keyRing(v), field); err != nil {
+			return fmt.Errorf("fieldcrypt: %s.%s: %w", v.Type().Name(), f.name, err)
+		}
+	}
+
+	for _, index := range info.nested {
+		field := v.Field(index)
+		if field.Kind() == reflect.Ptr {
+			if field.IsNil() {
+				continue
+			}
+			field = field.Elem()
+		}
+		if err := walkStruct(field, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// structInfo describes the encrypted and nested fields of a struct type.
+type structInfo struct {
+	fields []encryptedField
+	nested []int
+}
+
+type encryptedField struct {
+	index int
+	name  string
+	// parts of the keyring template. Every part is either a literal or the
+	// index of the referenced field
+	parts []keyRingPart
+}
+
+type keyRingPart struct {
+	literal string
+	field   int
+}
+
+func (f *encryptedField) keyRing(v reflect.Value) string {
+	var sb strings.Builder
+	for _, part := range f.parts {
+		if part.field < 0 {
+			sb.WriteString(part.literal)
+		} else {
+			sb.WriteString(fmt.Sprint(v.Field(part.field).Interface()))
+		}
+	}
+	return sb.String()
+}
+
+// structInfos caches the structInfo of every struct type (reflect.Type ->
+// *structInfo)
+var structInfos sync.Map
+
+func structInfoOf(t reflect.Type) (*structInfo, error) {
+	if info, ok := structInfos.Load(t); ok {
+		return info.(*structInfo), nil
+	}
+
+	info, err := parseStruct(t)
+	if err != nil {
+		return nil, err
+	}
+	structInfos.Store(t, info)
+	return info, nil
+}
+
+func parseStruct(t reflect.Type) (*structInfo, error) {
+	info := &structInfo{}
+	encrypted := make(map[string]bool)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(TagName)
+		if !ok || tag == "-" {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct && sf.PkgPath == "" {
+				info.nested = append(info.nested, i)
+			}
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			return nil, fmt.Errorf("fieldcrypt: %s.%s: encrypted fields must be exported", t.Name(), sf.Name)
+		}
+		if sf.Type.Kind() != reflect.String && !(sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.Uint8) {
+			return nil, fmt.Errorf("fieldcrypt: %s.%s: encrypted fields must be of type string or []byte, not %s", t.Name(), sf.Name, sf.Type)
+		}
+
+		f, err := parseTag(t, sf, tag)
+		if err != nil {
+			return nil, err
+		}
+		info.fields = append(info.fields, f)
+		encrypted[sf.Name] = true
+	}
+
+	// keyrings must not depend on encrypted fields, as they differ between
+	// encryption and decryption
+	for _, f := range info.fields {
+		for _, part := range f.parts {
+			if part.field >= 0 && encrypted[t.Field(part.field).Name] {
+				return nil, fmt.Errorf("fieldcrypt: %s.%s: keyring references the encrypted field %s", t.Name(), f.name, t.Field(part.field).Name)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// parseTag parses a tag like "encrypt,keyring=users/{ID}".
+func parseTag(t reflect.Type, sf reflect.StructField, tag string) (encryptedField, error) {
+	f := encryptedField{index: sf.Index[0], name: sf.Name}
+	errorf := func(format string, a ...interface{}) (encryptedField, error) {
+		return encryptedField{}, fmt.Errorf("fieldcrypt: %s.%s: %s", t.Name(), sf.Name, fmt.Sprintf(format, a...))
+	}
+
+	options := strings.Split(tag, ",")
+	if options[0] != "encrypt" {
+		return errorf("unknown action %q (supported: \"encrypt\")", options[0])
+	}
+
+	template := ""
+	for _, option := range options[1:] {
+		switch {
+		case strings.HasPrefix(option, "keyring="):
+			template = strings.TrimPrefix(option, "keyring=")
+		default:
+			return errorf("unknown option %q", option)
+		}
+	}
+	if template == "" {
+		return errorf("keyring is required")
+	}
+
+	for template != "" {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			f.parts = append(f.parts, keyRingPart{literal: template, field: -1})
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			return errorf("unterminated placeholder in keyring")
+		}
+		end += start
+
+		if start > 0 {
+			f.parts = append(f.parts, keyRingPart{literal: template[:start], field: -1})
+		}
+		name := template[start+1 : end]
+		ref, ok := t.FieldByName(name)
+		if !ok || len(ref.Index) != 1 || ref.PkgPath != "" {
+			return errorf("keyring references unknown or unexported field %q", name)
+		}
+		f.parts = append(f.parts, keyRingPart{field: ref.Index[0]})
+		template = template[end+1:]
+	}
+
+	return f, nil
+}
@@ -0,0 +1,104 @@
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+)
+
+func newProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, dvx.NopLogger)})
+}
+
+type address struct {
+	Country string
+	Street  string `dvx:"encrypt,keyring=addresses/{Country}"`
+}
+
+type user struct {
+	ID      int
+	Name    string
+	Email   string `dvx:"encrypt,keyring=users/{ID}/email"`
+	Notes   []byte `dvx:"encrypt,keyring=users/{ID}"`
+	Empty   string `dvx:"encrypt,keyring=users/{ID}"`
+	Address *address
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	p := newProtocol(t)
+	ctx := context.Background()
+
+	u := &user{
+		ID:      42,
+		Name:    "Jane",
+		Email:   "jane@example.com",
+		Notes:   []byte("notes"),
+		Address: &address{Country: "AT", Street: "Street 1"},
+	}
+	require.NoError(t, Encrypt(ctx, p, u))
+	assert.Equal(t, "Jane", u.Name)
+	assert.True(t, strings.HasPrefix(u.Email, dvx.Version+".enc."))
+	assert.True(t, strings.HasPrefix(string(u.Notes), dvx.Version+".enc."))
+	assert.Equal(t, "", u.Empty)
+	assert.True(t, strings.HasPrefix(u.Address.Street, dvx.Version+".enc."))
+
+	// every field is encrypted with its own keyring
+	email, err := p.Decrypt("users/42/email", u.Email)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", string(email))
+
+	require.NoError(t, Decrypt(ctx, p, u))
+	assert.Equal(t, &user{
+		ID:      42,
+		Name:    "Jane",
+		Email:   "jane@example.com",
+		Notes:   []byte("notes"),
+		Address: &address{Country: "AT", Street: "Street 1"},
+	}, u)
+
+	// the keyring is bound to the referenced fields
+	require.NoError(t, Encrypt(ctx, p, u))
+	u.ID = 43
+	err = Decrypt(ctx, p, u)
+	assert.True(t, errors.Is(err, dvx.ErrAuthentication))
+}
+
+func TestInvalid(t *testing.T) {
+	p := newProtocol(t)
+	ctx := context.Background()
+
+	for name, v := range map[string]interface{}{
+		"no pointer": user{},
+		"nil":        (*user)(nil),
+		"type": &struct {
+			Value int `dvx:"encrypt,keyring=values"`
+		}{},
+		"no keyring": &struct {
+			Value string `dvx:"encrypt"`
+		}{},
+		"unknown field": &struct {
+			Value string `dvx:"encrypt,keyring=values/{ID}"`
+		}{},
+		"encrypted reference": &struct {
+			ID    string `dvx:"encrypt,keyring=ids"`
+			Value string `dvx:"encrypt,keyring=values/{ID}"`
+		}{},
+		"unknown action": &struct {
+			Value string `dvx:"hash,keyring=values"`
+		}{},
+	} {
+		assert.Error(t, Encrypt(ctx, p, v), name)
+	}
+}
@@ -0,0 +1,400 @@
+package dvx
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Constants of the dvxfile format (see NewFileWriter).
+const (
+	// fileMagic is the first field of the header of every dvxfile
+	fileMagic = "dvxfile"
+	// fileFormat is the revision of the dvxfile format
+	fileFormat = "v1"
+	// FileChunkSize is the maximum amount of plaintext bytes per chunk of a
+	// dvxfile.
+	FileChunkSize = 64 * 1024
+	// fileNoncePrefixSize is the size of the random part of chunk nonces
+	fileNoncePrefixSize = chacha20poly1305.NonceSizeX - 8
+	// fileKeyIDSize is the size of the key-id in the header
+	fileKeyIDSize = 8
+)
+
+// fileHeader is the first line of a dvxfile.
+type fileHeader struct {
+	version     string
+	keyID       []byte
+	noncePrefix []byte
+	keyRing     string
+}
+
+func (h fileHeader) String() string {
+	return strings.Join([]string{
+		fileMagic,
+		fileFormat,
+		h.version,
+		base64.RawURLEncoding.EncodeToString(h.keyID),
+		base64.RawURLEncoding.EncodeToString(h.noncePrefix),
+		base64.RawURLEncoding.EncodeToString([]byte(h.keyRing)),
+	}, " ")
+}
+
+func parseFileHeader(line string) (h fileHeader, err error) {
+	fields := strings.Split(line, " ")
+	if len(fields) != 6 || fields[0] != fileMagic {
+		return h, errorf(ErrInvalidFormat, "dvx: not a dvxfile")
+	}
+	if fields[1] != fileFormat {
+		return h, errorf(ErrInvalidFormat, "dvx: unsupported dvxfile format %q", fields[1])
+	}
+	if fields[2] != featureVersion {
+		return h, errorf(ErrInvalidFormat, "dvx: unsupported dvxfile version %q", fields[2])
+	}
+
+	h.version = fields[2]
+	h.keyID, err = base64.RawURLEncoding.DecodeString(fields[3])
+	if err != nil || len(h.keyID) != fileKeyIDSize {
+		return h, errorf(ErrInvalidFormat, "dvx: invalid key-id in dvxfile header")
+	}
+	h.noncePrefix, err = base64.RawURLEncoding.DecodeString(fields[4])
+	if err != nil || len(h.noncePrefix) != fileNoncePrefixSize {
+		return h, errorf(ErrInvalidFormat, "dvx: invalid nonce in dvxfile header")
+	}
+	keyRing, err := base64.RawURLEncoding.DecodeString(fields[5])
+	if err != nil {
+		return h, errorf(ErrInvalidFormat, "dvx: invalid keyRing in dvxfile header")
+	}
+	h.keyRing = string(keyRing)
+	return h, nil
+}
+
+// fileKeyID returns the key-id of key. It identifies the key a dvxfile was
+// encrypted with (e.g. after the root key was rotated), without revealing it.
+func fileKeyID(key []byte) []byte {
+	sum := blake2b.Sum256(append([]byte(fileMagic+" key-id\x00"), key...))
+	return sum[:fileKeyIDSize]
+}
+
+// fileNonce returns the nonce of chunk index.
+func fileNonce(prefix []byte, index uint64) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint64(nonce[fileNoncePrefixSize:], index)
+	return nonce
+}
+
+// fileAAD returns the additional data of a chunk: the header line and whether
+// the chunk is the last one, so chunks can't be moved to another file and a
+// truncated file is detected.
+func fileAAD(header string, final bool) []byte {
+	aad := make([]byte, 0, len(header)+1)
+	aad = append(aad, header...)
+	if final {
+		return append(aad, 1)
+	}
+	return append(aad, 0)
+}
+
+// fileWriter encrypts a dvxfile chunk by chunk.
+type fileWriter struct {
+	p      *Protocol
+	w      io.Writer
+	header string
+	nonce  []byte
+	aead   cipher.AEAD
+	buf    []byte
+	index  uint64
+	closed bool
+}
+
+// NewFileWriter writes the header of a dvxfile to w and returns a writer that
+// encrypts everything written to it with a key derived from keyRing. Close
+// must be called to write the last chunk, it doesn't close w.
+//
+// A dvxfile is a text file, so it can be kept in git and edited with
+// line-based tools. Its first line is the header
+//   dvxfile v1 <version> <key_id> <nonce> <keyring>
+// followed by one line per chunk of at most FileChunkSize plaintext bytes,
+// all encoded as base64 url strings without padding. Chunks are encrypted
+// with XChaCha20-Poly1305, a nonce of the random header nonce and the index
+// of the chunk, and the header and a flag for the last chunk as additional
+// data. The key-id identifies the derived key (e.g. to tell files of a
+// rotated root key apart), but reveals nothing about it.
+func (p *Protocol) NewFileWriter(ctx context.Context, w io.Writer, keyRing string) (io.WriteCloser, error) {
+	keyRingBytes, err := p.keyRingInput(keyRing, OpEncrypt)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, featureVersion, purposeFile)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+
+	h := fileHeader{
+		version:     featureVersion,
+		keyID:       fileKeyID(key),
+		noncePrefix: make([]byte, fileNoncePrefixSize),
+		keyRing:     keyRing,
+	}
+	if _, err = io.ReadFull(rand.Reader, h.noncePrefix); err != nil {
+		return nil, errorf(ErrRandomness, "%s: failed to read random %d bytes for nonce: %v", featureVersion, fileNoncePrefixSize, err)
+	}
+
+	aead, _ := chacha20poly1305.NewX(key) // err is always nil
+	fw := &fileWriter{
+		p:      p,
+		w:      w,
+		header: h.String(),
+		nonce:  h.noncePrefix,
+		aead:   aead,
+	}
+	if _, err = io.WriteString(w, fw.header+"\n"); err != nil {
+		return nil, err
+	}
+	return fw, nil
+}
+
+// Write encrypts all full chunks of the buffered data, except the last one,
+// that might be the final chunk.
+func (fw *fileWriter) Write(data []byte) (n int, err error) {
+	if fw.closed {
+		return 0, errorf(ErrInvalidFormat, "dvx: write to closed dvxfile")
+	}
+
+	fw.buf = append(fw.buf, data...)
+	for len(fw.buf) > FileChunkSize {
+		if err = fw.writeChunk(fw.buf[:FileChunkSize], false); err != nil {
+			return 0, err
+		}
+		fw.buf = fw.buf[FileChunkSize:]
+	}
+	return len(data), nil
+}
+
+// Close encrypts the remaining data as last chunk. Without it the file is
+// rejected as truncated.
+func (fw *fileWriter) Close() error {
+	if fw.closed {
+		return nil
+	}
+	fw.closed = true
+	err := fw.writeChunk(fw.buf, true)
+	wipe(fw.buf)
+	fw.buf = nil
+	return err
+}
+
+func (fw *fileWriter) writeChunk(chunk []byte, final bool) error {
+	sealed := fw.aead.Seal(nil, fileNonce(fw.nonce, fw.index), chunk, fileAAD(fw.header, final))
+	fw.index++
+
+	if _, err := io.WriteString(fw.w, base64.RawURLEncoding.EncodeToString(sealed)+"\n"); err != nil {
+		return err
+	}
+	atomic.AddUint64(&fw.p.stats.bytesEncrypted, uint64(len(chunk)))
+	return nil
+}
+
+// fileReader decrypts a dvxfile chunk by chunk.
+type fileReader struct {
+	p      *Protocol
+	r      *bufio.Reader
+	header string
+	nonce  []byte
+	aead   cipher.AEAD
+	buf    []byte
+	index  uint64
+	final  bool
+}
+
+// NewFileReader reads the header of a dvxfile (see NewFileWriter) from r and
+// returns a reader of its decrypted content. The file must have been
+// encrypted for keyRing. Every chunk is authenticated before its data is
+// returned. A file that was truncated, reordered or extended results in an
+// error of class ErrAuthentication, but data of the chunks before it may
+// already have been returned.
+func (p *Protocol) NewFileReader(ctx context.Context, r io.Reader, keyRing string) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	line, err := br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\n")
+	h, err := parseFileHeader(line)
+	if err != nil {
+		return nil, err
+	}
+	if h.keyRing != keyRing {
+		return nil, errorf(ErrAuthentication, "dvx: dvxfile was encrypted for keyRing %q", h.keyRing)
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpDecrypt)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, h.version, purposeFile)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	if subtle.ConstantTimeCompare(fileKeyID(key), h.keyID) != 1 {
+		return nil, errorf(ErrAuthentication, "dvx: dvxfile was encrypted with another key (key-id %s)", base64.RawURLEncoding.EncodeToString(h.keyID))
+	}
+
+	aead, _ := chacha20poly1305.NewX(key) // err is always nil
+	return &fileReader{
+		p:      p,
+		r:      br,
+		header: line,
+		nonce:  h.noncePrefix,
+		aead:   aead,
+	}, nil
+}
+
+func (fr *fileReader) Read(data []byte) (n int, err error) {
+	for len(fr.buf) == 0 {
+		if fr.final {
+			return 0, fr.expectEOF()
+		}
+		if err = fr.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n = copy(data, fr.buf)
+	fr.buf = fr.buf[n:]
+	return n, nil
+}
+
+// readChunk decrypts the next chunk into fr.buf.
+func (fr *fileReader) readChunk() error {
+	line, err := fr.r.ReadString('\n')
+	if err == io.EOF && line == "" {
+		return errorf(ErrAuthentication, "dvx: dvxfile is truncated after %d chunks", fr.index)
+	}
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	sealed, err := base64.RawURLEncoding.DecodeString(strings.TrimSuffix(line, "\n"))
+	if err != nil {
+		return errorf(ErrInvalidFormat, "dvx: invalid chunk %d of dvxfile: %v", fr.index, err)
+	}
+
+	nonce := fileNonce(fr.nonce, fr.index)
+	fr.buf, err = fr.aead.Open(nil, nonce, sealed, fileAAD(fr.header, false))
+	if err != nil {
+		fr.buf, err = fr.aead.Open(nil, nonce, sealed, fileAAD(fr.header, true))
+		if err != nil {
+			return errorf(ErrAuthentication, "dvx: chunk %d of dvxfile failed authentication", fr.index)
+		}
+		fr.final = true
+	}
+	fr.index++
+
+	atomic.AddUint64(&fr.p.stats.bytesDecrypted, uint64(len(fr.buf)))
+	return nil
+}
+
+// expectEOF returns io.EOF if nothing follows the last chunk.
+func (fr *fileReader) expectEOF() error {
+	rest, err := fr.r.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if rest != "" {
+		return errorf(ErrAuthentication, "dvx: dvxfile has data after its last chunk")
+	}
+	return io.EOF
+}
+
+// SaveSecretsFile encrypts secrets as JSON object into the dvxfile at path
+// (see NewFileWriter), with a key derived from keyRing. The file is replaced
+// atomically and is only readable by its owner.
+//
+// Encrypted secrets files can be committed to git next to the configuration
+// of an application. Only services that can derive keys for keyRing from the
+// KeyPool (e.g. backed by an HSM) are able to read them.
+func (p *Protocol) SaveSecretsFile(ctx context.Context, path string, keyRing string, secrets map[string]string) (err error) {
+	defer p.done(OpEncrypt, time.Now(), &err)
+
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	defer wipe(data)
+
+	var buf bytes.Buffer
+	w, err := p.NewFileWriter(ctx, &buf, keyRing)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(data); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// LoadSecretsFile decrypts the secrets of the dvxfile at path, that was
+// written by SaveSecretsFile with the same keyRing.
+func (p *Protocol) LoadSecretsFile(ctx context.Context, path string, keyRing string) (secrets map[string]string, err error) {
+	defer p.done(OpDecrypt, time.Now(), &err)
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := p.NewFileReader(ctx, f, keyRing)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(data)
+
+	if err = json.Unmarshal(data, &secrets); err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: secrets of dvxfile aren't a JSON object: %v", err)
+	}
+	return secrets, nil
+}
@@ -0,0 +1,51 @@
+package dvx
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"strings"
+)
+
+// fingerprintSize is the size of the hash of KeyRingFingerprint.
+const fingerprintSize = 16
+
+// fingerprintEncoding encodes fingerprints as lower case base32 without
+// padding, so they are safe in file names, URLs and case-insensitive indexes.
+var fingerprintEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// KeyRingFingerprint returns a short stable identifier of keyRing: the first
+// 16 bytes of the SHA-256 hash of its bytes, encoded as 26 lower case base32
+// characters. Like the key derivation it decodes the base64 part of keyRings
+// (see Protocol), so keyRings that derive the same keys have the same
+// fingerprint. SHA-256 is used instead of Blake2b, so KeyPool implementations
+// in other modules (e.g. hsm) compute equal fingerprints with the standard
+// library only.
+//
+// Fingerprints are used in place of raw keyRings in audit logs and admin
+// APIs, so they can be stored, logged and indexed without revealing the
+// keyRing (e.g. user-ids it contains). The hash isn't keyed: guessable
+// keyRings can be recovered from their fingerprint by trying all candidates.
+func KeyRingFingerprint(keyRing string) string {
+	return fingerprint(keyRingToBytes(keyRing))
+}
+
+// fingerprint returns the KeyRingFingerprint of the bytes of a keyRing.
+func fingerprint(keyRing []byte) string {
+	h := sha256.New()
+	h.Write([]byte("dvx keyRing fingerprint\x00"))
+	h.Write(keyRing)
+	return fingerprintEncoding.EncodeToString(h.Sum(nil)[:fingerprintSize])
+}
+
+// splitKDFInput splits the input of a KeyPool (see kdfInput) into its label
+// and the bytes of the keyRing. Inputs of dv1 have no label. A dv1 keyRing
+// that looks like a label followed by a zero byte is split as well, which
+// only affects its fingerprint in logs.
+func splitKDFInput(input []byte) (label string, keyRing []byte) {
+	i := bytes.IndexByte(input, 0)
+	if i == -1 || !strings.HasPrefix(string(input[:i]), "dv") || strings.Count(string(input[:i]), "/") != 2 {
+		return "", input
+	}
+	return string(input[:i]), input[i+1:]
+}
@@ -0,0 +1,126 @@
+package dvx
+
+import (
+	"context"
+	"encoding/binary"
+	"sync/atomic"
+	"time"
+)
+
+// EncryptWithFooter is like Encrypt, but always uses dv2 and attaches footer
+// to the ciphertext (see EncodeWithFooter). The footer isn't encrypted, so it
+// can carry routing metadata like a key-id, tenant or purpose, but it is
+// authenticated as part of the additional data: Decrypt fails if it was
+// changed or removed.
+func (p *Protocol) EncryptWithFooter(keyRing string, data []byte, footer []byte) (ciphertext string, err error) {
+	return p.EncryptWithFooterContext(context.Background(), keyRing, data, footer)
+}
+
+// EncryptWithFooterContext is like EncryptWithFooter, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) EncryptWithFooterContext(ctx context.Context, keyRing string, data []byte, footer []byte) (ciphertext string, err error) {
+	defer p.done(OpEncrypt, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpEncrypt)
+	if err != nil {
+		return "", err
+	}
+	if err = p.checkEncryptionContext(keyRing, footer); err != nil {
+		return "", err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, featureVersion, purposeEncrypt)
+	if err != nil {
+		return "", err
+	}
+
+	size := len(data)
+	cipher, err := seal(featureVersion, key, data, footer)
+	if err != nil {
+		return "", err
+	}
+
+	p.usage.add(keyRing, keyRingBytes)
+	atomic.AddUint64(&p.stats.bytesEncrypted, uint64(size))
+	return EncodeWithFooter(Encrypted, cipher, footer), nil
+}
+
+// SignWithFooter is like Sign, but attaches footer to the signature (see
+// EncodeWithFooter) and signs it together with message. Verify and VerifyPK
+// fail if the footer was changed or removed.
+func (p *Protocol) SignWithFooter(keyRing string, message []byte, footer []byte) (signature string, rawSignature []byte, err error) {
+	return p.SignWithFooterContext(context.Background(), keyRing, message, footer)
+}
+
+// SignWithFooterContext is like SignWithFooter, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) SignWithFooterContext(ctx context.Context, keyRing string, message []byte, footer []byte) (signature string, rawSignature []byte, err error) {
+	defer p.done(OpSign, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpSign)
+	if err != nil {
+		return "", nil, err
+	}
+	version := p.writeVersion()
+	key, err := p.deriveSignKey(ctx, keyRingBytes, version)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sig, err := primitiveOf(version).Sign(key, footerMessage(version, Signed, message, footer))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return encodeVersionWithFooter(version, Signed, sig, footer), sig, nil
+}
+
+// MACWithFooter is like MAC, but attaches footer to the tag (see
+// EncodeWithFooter) and authenticates it together with message. Tags are
+// verified by calculating them again, therefore the footer of a received tag
+// (see DecodeWithFooter) must be passed to MACWithFooter for the comparison.
+func (p *Protocol) MACWithFooter(keyRing string, message []byte, footer []byte) (tag string, err error) {
+	return p.MACWithFooterContext(context.Background(), keyRing, message, footer)
+}
+
+// MACWithFooterContext is like MACWithFooter, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) MACWithFooterContext(ctx context.Context, keyRing string, message []byte, footer []byte) (tag string, err error) {
+	defer p.done(OpMAC, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpMAC)
+	if err != nil {
+		return "", err
+	}
+	version := p.writeVersion()
+	key, err := p.kdf64(ctx, keyRingBytes, version, purposeMAC)
+	if err != nil {
+		return "", err
+	}
+
+	buffer, err := primitiveOf(version).MAC512(key, footerMessage(version, Tagged, message, footer))
+	if err != nil {
+		return "", err
+	}
+
+	return encodeVersionWithFooter(version, Tagged, buffer, footer), nil
+}
+
+// footerMessage returns the message that is signed or tagged together with
+// footer. Without footer it is message itself. Otherwise, the version and
+// TypePrefix, message and footer are each prefixed with their length (as
+// little-endian uint64), so bytes can't be moved between message and footer.
+func footerMessage(version string, typePrefix TypePrefix, message []byte, footer []byte) []byte {
+	if len(footer) == 0 {
+		return message
+	}
+
+	label := version + "." + string(typePrefix)
+	buf := make([]byte, 0, 3*8+len(label)+len(message)+len(footer))
+	var size [8]byte
+	for _, part := range [][]byte{[]byte(label), message, footer} {
+		binary.LittleEndian.PutUint64(size[:], uint64(len(part)))
+		buf = append(buf, size[:]...)
+		buf = append(buf, part...)
+	}
+	return buf
+}
@@ -3,7 +3,6 @@ module azoo.dev/utils/dvx
 go 1.16
 
 require (
-	github.com/harwoeck/liblog/contract v1.1.2
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20210711020723-a769d52b0f97
 )
@@ -0,0 +1,82 @@
+package dvx
+
+import (
+	"encoding"
+	"encoding/binary"
+	"hash"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// blake2bIV is the initialization vector of BLAKE2b (RFC 7693, section 2.6).
+var blake2bIV = [8]uint64{
+	0x6a09e667f3bcc908, 0xbb67ae8584caa73b, 0x3c6ef372fe94f82b, 0xa54ff53a5f1d36f1,
+	0x510e527fade682d1, 0x9b05688c2b3e6c1f, 0x1f83d9abfb41bd6b, 0x5be0cd19137e2179,
+}
+
+const (
+	// blake2bStateMagic and blake2bStateSize describe the state of
+	// blake2b.digest as written by its MarshalBinary.
+	blake2bStateMagic = "b2b"
+	blake2bStateSize  = len(blake2bStateMagic) + 8*8 + 2*8 + 1 + blake2b.BlockSize + 1
+)
+
+// macHasher is a reusable keyed BLAKE2b hasher. blake2b can only set the key
+// of a new hasher, so the keyed initial state is restored with
+// UnmarshalBinary instead.
+type macHasher struct {
+	h     hash.Hash
+	state [blake2bStateSize]byte
+}
+
+var macHashers = sync.Pool{
+	New: func() interface{} {
+		h, _ := blake2b.New512(nil) // err is always nil without key
+		return &macHasher{h: h}
+	},
+}
+
+// reset sets the state of m to a new hasher of size bytes with key, as
+// created by blake2b.New. key must be blake2b.Size bytes long.
+func (m *macHasher) reset(size int, key []byte) {
+	s := m.state[:0]
+	s = append(s, blake2bStateMagic...)
+	for i, v := range blake2bIV {
+		if i == 0 {
+			// parameter block: digest size, key length, fanout and depth
+			v ^= uint64(size) | uint64(len(key))<<8 | 1<<16 | 1<<24
+		}
+		s = appendUint64(s, v)
+	}
+	s = appendUint64(s, 0)
+	s = appendUint64(s, 0)
+	s = append(s, byte(size))
+	// the key is the first block, padded with zeros
+	n := len(s)
+	s = s[:n+blake2b.BlockSize]
+	copy(s[n:], key)
+	for i := n + len(key); i < len(s); i++ {
+		s[i] = 0
+	}
+	s = append(s, byte(blake2b.BlockSize))
+
+	_ = m.h.(encoding.BinaryUnmarshaler).UnmarshalBinary(s) // err is always nil for valid states
+}
+
+func appendUint64(b []byte, v uint64) []byte {
+	var a [8]byte
+	binary.BigEndian.PutUint64(a[:], v)
+	return append(b, a[:]...)
+}
+
+// appendMAC appends the keyed BLAKE2b hash of size bytes of message to dst.
+// It uses a pooled hasher and doesn't allocate if dst has enough capacity.
+func appendMAC(dst []byte, size int, key []byte, message []byte) []byte {
+	m := macHashers.Get().(*macHasher)
+	m.reset(size, key)
+	m.h.Write(message)
+	dst = m.h.Sum(dst)
+	macHashers.Put(m)
+	return dst
+}
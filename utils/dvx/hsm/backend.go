@@ -0,0 +1,33 @@
+package hsm
+
+import (
+	"github.com/miekg/pkcs11"
+)
+
+// Backend is the subset of *pkcs11.Ctx used by this package, after the
+// module was initialized. It allows replacing the PKCS#11 module with an
+// in-process fake (see azoo.dev/utils/dvx/hsm/hsmtest), so the HSM KeyPool
+// and its users can be tested without SoftHSM.
+type Backend interface {
+	Finalize() error
+	Destroy()
+	GetInfo() (pkcs11.Info, error)
+	GetSlotList(tokenPresent bool) ([]uint, error)
+	GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error)
+	GetMechanismList(slotID uint) ([]*pkcs11.Mechanism, error)
+	OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error)
+	CloseSession(sh pkcs11.SessionHandle) error
+	Login(sh pkcs11.SessionHandle, userType uint, pin string) error
+	Logout(sh pkcs11.SessionHandle) error
+	FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error
+	FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error)
+	FindObjectsFinal(sh pkcs11.SessionHandle) error
+	GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error)
+	GenerateKey(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, temp []*pkcs11.Attribute) (pkcs11.ObjectHandle, error)
+	SignInit(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error
+	Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error)
+	SignUpdate(sh pkcs11.SessionHandle, message []byte) error
+	SignFinal(sh pkcs11.SessionHandle) ([]byte, error)
+}
+
+var _ Backend = (*pkcs11.Ctx)(nil)
@@ -0,0 +1,194 @@
+package hsm
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miekg/pkcs11"
+)
+
+// Severity classifies a Finding.
+type Severity int
+
+const (
+	// SeverityOK marks a passed check.
+	SeverityOK Severity = iota
+	// SeverityWarning marks a check that passed, but needs attention.
+	SeverityWarning
+	// SeverityError marks a failed check. New will fail with this
+	// configuration.
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityOK:
+		return "ok"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return "error"
+	}
+}
+
+// Finding is the result of a single check run by Diagnose.
+type Finding struct {
+	// Check is the name of the check. For example: "mechanisms"
+	Check string
+	// Severity is the result of the check.
+	Severity Severity
+	// Message describes the result. Failed checks contain a hint on how to
+	// fix them.
+	Message string
+}
+
+// Diagnose checks whether New would succeed with config, without generating
+// a root key or deriving any keys. It checks, in this order, whether the
+// PKCS#11 module can be loaded, the token can be found, the required
+// mechanisms are supported, the user can log in and whether the root key
+// exists and is selected unambiguously (see KeySelection). Diagnose stops at the first failed check other checks depend on.
+func Diagnose(config *Config) []Finding {
+	var findings []Finding
+	add := func(check string, severity Severity, format string, a ...interface{}) {
+		findings = append(findings, Finding{check, severity, fmt.Sprintf(format, a...)})
+	}
+
+	// uri
+	config, err := resolveURI(config)
+	if err != nil {
+		add("uri", SeverityError, "%v", err)
+		return findings
+	}
+
+	// module
+	ctx := config.Backend
+	if ctx == nil {
+		module := pkcs11.New(config.Module)
+		if module == nil {
+			add("module", SeverityError, "unable to load PKCS#11 module %q. Check that the path exists and the module is built for this platform", config.Module)
+			return findings
+		}
+		defer module.Destroy()
+
+		if err := module.Initialize(); err != nil {
+			add("module", SeverityError, "unable to initialize PKCS#11 module %q: %v", config.Module, err)
+			return findings
+		}
+		defer func() {
+			_ = module.Finalize()
+		}()
+		ctx = module
+	}
+
+	info, err := ctx.GetInfo()
+	if err != nil {
+		add("module", SeverityWarning, "module loaded, but reading its info failed: %v", err)
+	} else {
+		add("module", SeverityOK, "loaded %s (%s, cryptoki %d.%d)", strings.TrimSpace(info.LibraryDescription), strings.TrimSpace(info.ManufacturerID), info.CryptokiVersion.Major, info.CryptokiVersion.Minor)
+	}
+
+	// token
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		add("token", SeverityError, "unable to list slots: %v", err)
+		return findings
+	}
+
+	var labels []string
+	slot, found := uint(0), false
+	for _, s := range slots {
+		ti, err := ctx.GetTokenInfo(s)
+		if err != nil {
+			add("token", SeverityWarning, "unable to read token info of slot %d: %v", s, err)
+			continue
+		}
+		labels = append(labels, fmt.Sprintf("%q (slot %d)", ti.Label, s))
+		if ti.Label == config.Label && !found {
+			slot, found = s, true
+			add("token", SeverityOK, "found token %q in slot %d: %s %s, serial %s", ti.Label, s, strings.TrimSpace(ti.ManufacturerID), strings.TrimSpace(ti.Model), strings.TrimSpace(ti.SerialNumber))
+		}
+	}
+	if !found {
+		if len(labels) == 0 {
+			add("token", SeverityError, "no initialized token found. Initialize a token with label %q (e.g. softhsm2-util --init-token --free --label %q)", config.Label, config.Label)
+		} else {
+			add("token", SeverityError, "no token with label %q found. Available tokens: %s", config.Label, strings.Join(labels, ", "))
+		}
+		return findings
+	}
+
+	// mechanisms
+	mechanisms, err := ctx.GetMechanismList(slot)
+	if err != nil {
+		add("mechanisms", SeverityError, "unable to list mechanisms of slot %d: %v", slot, err)
+		return findings
+	}
+	supported := make(map[uint]bool, len(mechanisms))
+	for _, m := range mechanisms {
+		supported[m.Mechanism] = true
+	}
+	var missing []string
+	for _, m := range []struct {
+		mechanism uint
+		name      string
+	}{
+		{pkcs11.CKM_SHA256_HMAC, "CKM_SHA256_HMAC"},
+		{pkcs11.CKM_SHA512_HMAC, "CKM_SHA512_HMAC"},
+		{pkcs11.CKM_GENERIC_SECRET_KEY_GEN, "CKM_GENERIC_SECRET_KEY_GEN"},
+	} {
+		if !supported[m.mechanism] {
+			missing = append(missing, m.name)
+		}
+	}
+	if len(missing) > 0 {
+		add("mechanisms", SeverityError, "slot %d doesn't support %s", slot, strings.Join(missing, ", "))
+		return findings
+	}
+	add("mechanisms", SeverityOK, "slot %d supports CKM_SHA256_HMAC, CKM_SHA512_HMAC and CKM_GENERIC_SECRET_KEY_GEN", slot)
+
+	// login
+	session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION)
+	if err != nil {
+		add("login", SeverityError, "unable to open session: %v", err)
+		return findings
+	}
+	defer func() {
+		_ = ctx.CloseSession(session)
+	}()
+
+	err = ctx.Login(session, pkcs11.CKU_USER, config.UserPin)
+	if err != nil && err.Error() != "pkcs11: 0x100: CKR_USER_ALREADY_LOGGED_IN" {
+		add("login", SeverityError, "login as user failed: %v. Check the user pin (not the security officer pin)", err)
+		return findings
+	}
+	defer func() {
+		_ = ctx.Logout(session)
+	}()
+	add("login", SeverityOK, "logged in as user")
+
+	// root key
+	keys, err := (&hsm{ctx: ctx, config: config}).findKeys(session)
+	if err != nil {
+		add("root key", SeverityError, "unable to search for root key: %v", err)
+		return findings
+	}
+	if len(keys) == 0 && config.ReadOnly {
+		add("root key", SeverityError, "no root key with label %q found. Read-only sessions can't generate it. Import or generate the root key first", config.RootKeyLabel)
+		return findings
+	}
+	if len(keys) == 0 {
+		add("root key", SeverityWarning, "no root key with label %q found. It will be generated by the first call to New. Make sure this is intended and the token is backed up afterwards", config.RootKeyLabel)
+		return findings
+	}
+	key, err := selectKey(config, keys)
+	switch {
+	case err != nil && config.KeySelection == SelectUnique && len(keys) > 1:
+		add("root key", SeverityError, "multiple objects with label %q found: %s. Use a unique root key label or select a key with KeySelection", config.RootKeyLabel, describeKeys(keys))
+	case err != nil:
+		add("root key", SeverityError, "%v", err)
+	default:
+		add("root key", SeverityOK, "found root key %q with id %q (generation %d)", config.RootKeyLabel, key.ID, key.Generation)
+	}
+
+	return findings
+}
@@ -0,0 +1,50 @@
+package hsm
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base32"
+	"hash"
+	"strings"
+)
+
+// fingerprintSize is the size of the hash of fingerprints.
+const fingerprintSize = 16
+
+// fingerprintEncoding encodes fingerprints as lower case base32 without
+// padding, so they are safe in file names, URLs and case-insensitive indexes.
+var fingerprintEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// fingerprint returns the fingerprint of the bytes of a keyRing. It is copied
+// from the parent project azoo.dev/utils/dvx (see dvx.KeyRingFingerprint), so
+// logs of all KeyPool implementations contain equal fingerprints.
+func fingerprint(keyRing []byte) string {
+	h := newFingerprint()
+	h.Write(keyRing)
+	return sumFingerprint(h)
+}
+
+// newFingerprint returns a hash that calculates the fingerprint of the bytes
+// written to it (see sumFingerprint).
+func newFingerprint() hash.Hash {
+	h := sha256.New()
+	h.Write([]byte("dvx keyRing fingerprint\x00"))
+	return h
+}
+
+// sumFingerprint returns the fingerprint of a hash of newFingerprint.
+func sumFingerprint(h hash.Hash) string {
+	return fingerprintEncoding.EncodeToString(h.Sum(nil)[:fingerprintSize])
+}
+
+// splitKDFInput splits the input of a KeyPool into its label and the bytes of
+// the keyRing. Inputs of dv1 have no label. A dv1 keyRing that looks like a
+// label followed by a zero byte is split as well, which only affects its
+// fingerprint in logs.
+func splitKDFInput(input []byte) (label string, keyRing []byte) {
+	i := bytes.IndexByte(input, 0)
+	if i == -1 || !strings.HasPrefix(string(input[:i]), "dv") || strings.Count(string(input[:i]), "/") != 2 {
+		return "", input
+	}
+	return string(input[:i]), input[i+1:]
+}
@@ -3,6 +3,6 @@ module azoo.dev/utils/dvx/hsm
 go 1.16
 
 require (
-	github.com/harwoeck/liblog/contract v1.1.2
 	github.com/miekg/pkcs11 v1.0.3
+	github.com/stretchr/testify v1.7.0
 )
@@ -13,10 +13,13 @@
 package hsm
 
 import (
-	"encoding/hex"
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
-	logger "github.com/harwoeck/liblog/contract"
 	"github.com/miekg/pkcs11"
 )
 
@@ -33,8 +36,9 @@ type KeyPool interface {
 	Close() error
 }
 
-// Config provides all options for an HSM. Every field is required. Not
-// providing valid configuration values results in unspecified behaviour.
+// Config provides all options for an HSM. Every field is required, unless
+// URI replaces it. Not providing valid configuration values results in
+// unspecified behaviour.
 // No checks are carried out!
 type Config struct {
 	// Module is the path to your PKCS#11 module.
@@ -49,15 +53,94 @@ type Config struct {
 	RootKeyID string
 	// RootKeyLabel is the label of your root key.
 	RootKeyLabel string
+	// KeySelection selects the root key if multiple keys have the
+	// RootKeyLabel. Optional, defaults to SelectUnique.
+	//   Example: SelectNewest
+	KeySelection KeySelection
+	// RootKeyGeneration is the generation of the root key for
+	// SelectGeneration (see RootKey.Generation).
+	//   Example: 2
+	RootKeyGeneration uint64
+	// ReadOnly opens all sessions read-only instead of read-write. HMAC
+	// derivations only use the root key, so deployments can provision a PIN
+	// that is limited to using the key (least privilege). New fails instead
+	// of generating a root key if none exists. Keys with
+	// CKA_ALWAYS_AUTHENTICATE additionally receive a CKU_CONTEXT_SPECIFIC
+	// login with UserPin before every derivation, regardless of ReadOnly.
+	ReadOnly bool
+	// RecheckInterval is the time after which the next derivation checks
+	// that the cached handle of the root key still refers to the key
+	// snapshotted by New (see KeyInfo). Handles that became invalid (e.g.
+	// after HSM maintenance) are resolved again. Optional, 0 disables the
+	// check.
+	//   Example: 5 * time.Minute
+	RecheckInterval time.Duration
+	// MaxSignPartSize is the maximum size of the data passed to the HSM in
+	// a single call. Longer derivation inputs are signed in parts of this
+	// size with C_SignUpdate and C_SignFinal instead of a single C_Sign, as
+	// HSMs limit the size of messages (e.g. AWS CloudHSM). Optional,
+	// defaults to DefaultMaxSignPartSize.
+	//   Example: 1024
+	MaxSignPartSize int
+	// URI is a PKCS#11 URI (RFC 7512), e.g. emitted by provisioning
+	// tooling, that replaces Label (token), RootKeyLabel (object) and
+	// RootKeyID (id). The pin is its pin-value or pin-source (a file),
+	// otherwise UserPin, and the module its module-path, otherwise Module or
+	// the environment variable PKCS11_MODULE_PATH (see ModulePathEnv). The
+	// type must be secret-key if present, other attributes aren't supported.
+	// Optional.
+	//   Example: "pkcs11:token=dvx;object=dvx_root;id=dvx_root_1?module-path=/usr/lib/softhsm/libsofthsm2.so&pin-source=file:/run/secrets/hsm-pin"
+	URI string
+	// Backend replaces the PKCS#11 module loaded from Module, e.g. with the
+	// fake of azoo.dev/utils/dvx/hsm/hsmtest in tests. It must already be
+	// initialized and is finalized by Close. Optional.
+	Backend Backend
 }
 
-// New creates a new HSM instance and returns it as a KeyPool interface
-func New(config *Config, log logger.Logger) (keyPool KeyPool, err error) {
-	log = log.Named("hsm")
+// DefaultMaxSignPartSize is the default of Config.MaxSignPartSize. It is well
+// below the message size limits of common HSMs.
+const DefaultMaxSignPartSize = 4096
+
+// StreamingKeyPool is implemented by the KeyPool of New. KDF32Reader and
+// KDF64Reader are like KDF32 and KDF64, but read the derivation input from r
+// and pass it to the HSM in parts (see Config.MaxSignPartSize), so inputs of
+// any size are derived without buffering them. Their audit logs contain the
+// fingerprint of the whole input, as its label can't be split off.
+//
+// r is read while a session is open and the signing operation is initialized
+// on the HSM, so a slow or blocking r holds a session of the HSM until it
+// returns. It should read from memory or local files. Readers of network
+// connections must enforce their own deadline (e.g. net.Conn.SetReadDeadline)
+// before they are passed. Read errors abort the derivation.
+type StreamingKeyPool interface {
+	KeyPool
+	KDF32Reader(r io.Reader) (key []byte, err error)
+	KDF64Reader(r io.Reader) (key []byte, err error)
+}
+
+var _ StreamingKeyPool = (*hsm)(nil)
+
+// New creates a new HSM instance and returns it as a KeyPool interface. If log
+// is nil nothing is logged.
+func New(config *Config, log Logger) (keyPool KeyPool, err error) {
+	switch config.KeySelection {
+	case SelectUnique, SelectByID, SelectNewest, SelectGeneration:
+	default:
+		return nil, fmt.Errorf("hsmpool: unknown key selection %q", config.KeySelection)
+	}
+	config, err = resolveURI(config)
+	if err != nil {
+		return nil, err
+	}
+	if config.MaxSignPartSize < 0 {
+		return nil, fmt.Errorf("hsmpool: max sign part size cannot be negative")
+	}
+
+	log = named(log, "hsm")
 
 	hsm := &hsm{
 		log:      log,
-		auditLog: log.Named("audit"),
+		auditLog: named(log, "audit"),
 		config:   config,
 	}
 
@@ -80,6 +163,11 @@ func New(config *Config, log logger.Logger) (keyPool KeyPool, err error) {
 	if err != nil {
 		return nil, err
 	}
+	if !found && config.ReadOnly {
+		hsm.logoutSession(hsm.keySession)
+		hsm.closeSession(hsm.keySession)
+		return nil, fmt.Errorf("hsmpool: no root key with label %q found and read-only sessions can't generate one", config.RootKeyLabel)
+	}
 	if !found {
 		// logout and close session -> new one will get created during generate
 		hsm.logoutSession(hsm.keySession)
@@ -92,31 +180,47 @@ func New(config *Config, log logger.Logger) (keyPool KeyPool, err error) {
 		}
 	}
 
+	err = hsm.snapshotKey()
+	if err != nil {
+		return nil, err
+	}
+
 	return hsm, nil
 }
 
 type hsm struct {
-	log        logger.Logger
-	auditLog   logger.Logger
+	log        Logger
+	auditLog   Logger
 	config     *Config
-	ctx        *pkcs11.Ctx
+	ctx        Backend
 	slot       uint
 	keySession pkcs11.SessionHandle
-	key        pkcs11.ObjectHandle
+	token      pkcs11.TokenInfo
+
+	// mu guards key and info, which are replaced when the handle of key is
+	// resolved again (see recheck)
+	mu   sync.RWMutex
+	key  RootKey
+	info KeyInfo
 }
 
 func (h *hsm) initCtx() error {
+	if h.config.Backend != nil {
+		h.ctx = h.config.Backend
+		return nil
+	}
+
 	ctx := pkcs11.New(h.config.Module)
 	if ctx == nil {
 		return fmt.Errorf("hsmpool: failed to create new pkcs11 link")
 	}
-	h.ctx = ctx
-
-	err := h.ctx.Initialize()
+	err := ctx.Initialize()
 	if err != nil {
+		ctx.Destroy()
 		return fmt.Errorf("hsmpool: failed to init: %w", err)
 	}
 
+	h.ctx = ctx
 	return nil
 }
 
@@ -137,13 +241,14 @@ func (h *hsm) selectSlot() error {
 		}
 
 		selectedSlot = si
+		h.token = ti
 		h.log.Info("found HSM slot",
-			logger.NewField("label", h.config.Label),
-			logger.NewField("manufacturer_id", ti.ManufacturerID),
-			logger.NewField("model", ti.Model),
-			logger.NewField("serial_number", ti.SerialNumber),
-			logger.NewField("hardware_version", fmt.Sprintf("%d.%d", ti.HardwareVersion.Major, ti.HardwareVersion.Minor)),
-			logger.NewField("firmware_version", fmt.Sprintf("%d.%d", ti.FirmwareVersion.Major, ti.FirmwareVersion.Minor)))
+			"label", h.config.Label,
+			"manufacturer_id", ti.ManufacturerID,
+			"model", ti.Model,
+			"serial_number", ti.SerialNumber,
+			"hardware_version", fmt.Sprintf("%d.%d", ti.HardwareVersion.Major, ti.HardwareVersion.Minor),
+			"firmware_version", fmt.Sprintf("%d.%d", ti.FirmwareVersion.Major, ti.FirmwareVersion.Minor))
 	}
 	if selectedSlot == 0 {
 		return fmt.Errorf("hsmpool: slot with label %q not found", h.config.Label)
@@ -182,8 +287,8 @@ func (h *hsm) closeSession(session pkcs11.SessionHandle) {
 	err := h.ctx.CloseSession(session)
 	if err != nil {
 		h.log.Warn("close of session failed",
-			logger.NewField("error", err),
-			logger.NewField("session_id", session))
+			"error", err,
+			"session_id", session)
 	}
 }
 
@@ -191,18 +296,22 @@ func (h *hsm) logoutSession(session pkcs11.SessionHandle) {
 	err := h.ctx.Logout(session)
 	if err != nil {
 		h.log.Warn("logout of session failed",
-			logger.NewField("error", err),
-			logger.NewField("session_id", session))
+			"error", err,
+			"session_id", session)
 	}
 }
 
 func (h *hsm) inSession(finishAfterUse bool, callback func(session pkcs11.SessionHandle) error) (pkcs11.SessionHandle, error) {
 	// open new session
-	session, err := h.ctx.OpenSession(h.slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+	flags := uint(pkcs11.CKF_SERIAL_SESSION)
+	if !h.config.ReadOnly {
+		flags |= pkcs11.CKF_RW_SESSION
+	}
+	session, err := h.ctx.OpenSession(h.slot, flags)
 	if err != nil {
 		return 0, fmt.Errorf("hsmpool: failed to open session: %w", err)
 	}
-	h.log.Debug("using session", logger.NewField("session_id", session))
+	h.log.Debug("using session", "session_id", session)
 
 	// defer closing of session
 	if finishAfterUse {
@@ -227,33 +336,33 @@ func (h *hsm) inSession(finishAfterUse bool, callback func(session pkcs11.Sessio
 	return session, callback(session)
 }
 
+// findAndSetKey selects the root key among all keys with the RootKeyLabel
+// (see KeySelection). It only reports found as false if there is no key with
+// the RootKeyLabel at all, so a missing generation or ID never results in a
+// newly generated root key.
 func (h *hsm) findAndSetKey() (found bool, err error) {
 	h.keySession, err = h.inSession(false, func(session pkcs11.SessionHandle) error {
-		err := h.ctx.FindObjectsInit(session, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_LABEL, h.config.RootKeyLabel)})
+		keys, err := h.findKeys(session)
 		if err != nil {
-			return fmt.Errorf("hsmpool: failed to init find objects: %w", err)
+			return err
 		}
-
-		objHandles, _, err := h.ctx.FindObjects(session, 1)
-		if err != nil {
-			return fmt.Errorf("hsmpool: failed to find objects: %w", err)
+		if len(keys) == 0 {
+			return nil
 		}
 
-		err = h.ctx.FindObjectsFinal(session)
+		key, err := selectKey(h.config, keys)
 		if err != nil {
-			return fmt.Errorf("hsmpool: failed to finalize object search: %w", err)
-		}
-
-		if len(objHandles) == 0 {
-			return nil
-		} else if len(objHandles) > 1 {
-			return fmt.Errorf("hsmpool: invalid amount of object handles returned from find: %d", len(objHandles))
-		} else {
-			h.key = objHandles[0]
-			found = true
+			return err
 		}
-
-		h.log.Debug("selected key handle", logger.NewField("key_handle", h.key))
+		h.key = key
+		found = true
+
+		h.log.Debug("selected key handle",
+			"key_handle", key.handle,
+			"key_id", key.ID,
+			"generation", key.Generation,
+			"always_authenticate", key.AlwaysAuthenticate,
+			"candidates", len(keys))
 		return nil
 	})
 	if err != nil {
@@ -291,25 +400,94 @@ func (h *hsm) generateKey() (err error) {
 			return fmt.Errorf("hsmpool: failed to generate key: %w", err)
 		}
 
-		h.key = obj
-		h.log.Debug("key object handle generated successfully", logger.NewField("key_handle", h.key))
+		h.key = RootKey{
+			Label:      h.config.RootKeyLabel,
+			ID:         h.config.RootKeyID,
+			Generation: keyGeneration(h.config.RootKeyID),
+			handle:     obj,
+		}
+		h.log.Debug("key object handle generated successfully", "key_handle", obj)
 
 		return nil
 	})
 	return
 }
 
-func (h *hsm) kdf(keyRing []byte, hsmMechanism uint, keyLen int) (key []byte, err error) {
-	_, err = h.inSession(true, func(session pkcs11.SessionHandle) error {
-		err = h.ctx.SignInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(hsmMechanism, nil)}, h.key)
+// sign signs the input read from r with rootKey and hsmMechanism. Inputs up
+// to MaxSignPartSize are signed with a single C_Sign, longer ones in parts
+// with C_SignUpdate and C_SignFinal. The parts after the first are read
+// while the operation is active, so r must not block (see
+// StreamingKeyPool). After errors the operation ends when kdf closes the
+// session.
+func (h *hsm) sign(session pkcs11.SessionHandle, rootKey RootKey, hsmMechanism uint, r io.Reader) ([]byte, error) {
+	size := h.config.MaxSignPartSize
+	if size == 0 {
+		size = DefaultMaxSignPartSize
+	}
+	br := bufio.NewReaderSize(r, size+1)
+	head, err := br.Peek(size + 1)
+	if err != nil && err != io.EOF {
+		return nil, fmt.Errorf("hsmpool: failed to read input: %w", err)
+	}
+
+	err = h.ctx.SignInit(session, []*pkcs11.Mechanism{pkcs11.NewMechanism(hsmMechanism, nil)}, rootKey.handle)
+	if err != nil {
+		return nil, fmt.Errorf("hsmpool: failed to init sign: %w", err)
+	}
+
+	// keys with CKA_ALWAYS_AUTHENTICATE must be authorized for every
+	// operation, right after it was initialized
+	if rootKey.AlwaysAuthenticate {
+		err = h.ctx.Login(session, pkcs11.CKU_CONTEXT_SPECIFIC, h.config.UserPin)
 		if err != nil {
-			return fmt.Errorf("hsmpool: failed to init sign: %w", err)
+			return nil, fmt.Errorf("hsmpool: failed to login context specific: %w", err)
 		}
+	}
 
+	if len(head) <= size {
+		mac, err := h.ctx.Sign(session, head)
+		if err != nil {
+			return nil, fmt.Errorf("hsmpool: sign failed: %w", err)
+		}
+		return mac, nil
+	}
+
+	part := make([]byte, size)
+	for {
+		n, err := io.ReadFull(br, part)
+		if n > 0 {
+			if err := h.ctx.SignUpdate(session, part[:n]); err != nil {
+				return nil, fmt.Errorf("hsmpool: sign update failed: %w", err)
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("hsmpool: failed to read input: %w", err)
+		}
+	}
+	mac, err := h.ctx.SignFinal(session)
+	if err != nil {
+		return nil, fmt.Errorf("hsmpool: sign final failed: %w", err)
+	}
+	return mac, nil
+}
+
+// kdf derives a key of keyLen bytes from the input read from r.
+func (h *hsm) kdf(r io.Reader, hsmMechanism uint, keyLen int) (key []byte, err error) {
+	if err = h.recheck(); err != nil {
+		return nil, err
+	}
+	h.mu.RLock()
+	rootKey := h.key
+	h.mu.RUnlock()
+
+	_, err = h.inSession(true, func(session pkcs11.SessionHandle) error {
 		// sign keyRing -> resulting mac-tag is our derived key
-		mac, err := h.ctx.Sign(session, keyRing)
+		mac, err := h.sign(session, rootKey, hsmMechanism, r)
 		if err != nil {
-			return fmt.Errorf("hsmpool: sign failed: %w", err)
+			return err
 		}
 
 		// check mac length
@@ -323,20 +501,71 @@ func (h *hsm) kdf(keyRing []byte, hsmMechanism uint, keyLen int) (key []byte, er
 	if err != nil {
 		return nil, err
 	}
+	return key, nil
+}
+
+func (h *hsm) kdfBytes(keyRing []byte, hsmMechanism uint, keyLen int) (key []byte, err error) {
+	key, err = h.kdf(bytes.NewReader(keyRing), hsmMechanism, keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	label, keyRingBytes := splitKDFInput(keyRing)
+	h.auditLog.Info("loaded key",
+		"key_len", keyLen,
+		"label", label,
+		"key_ring_fingerprint", fingerprint(keyRingBytes))
+	return
+}
+
+func (h *hsm) kdfReader(r io.Reader, hsmMechanism uint, keyLen int) (key []byte, err error) {
+	fp := newFingerprint()
+	counter := &countingWriter{}
+	key, err = h.kdf(io.TeeReader(r, io.MultiWriter(fp, counter)), hsmMechanism, keyLen)
+	if err != nil {
+		return nil, err
+	}
 
 	h.auditLog.Info("loaded key",
-		logger.NewField("key_len", keyLen),
-		logger.NewField("key_ring", string(keyRing)),
-		logger.NewField("key_ring_hex", hex.EncodeToString(keyRing)))
+		"key_len", keyLen,
+		"input_len", counter.n,
+		"key_ring_fingerprint", sumFingerprint(fp))
 	return
 }
 
 func (h *hsm) KDF32(keyRing []byte) (key []byte, err error) {
-	return h.kdf(keyRing, pkcs11.CKM_SHA256_HMAC, 32)
+	return h.kdfBytes(keyRing, pkcs11.CKM_SHA256_HMAC, 32)
 }
 
 func (h *hsm) KDF64(keyRing []byte) (key []byte, err error) {
-	return h.kdf(keyRing, pkcs11.CKM_SHA512_HMAC, 64)
+	return h.kdfBytes(keyRing, pkcs11.CKM_SHA512_HMAC, 64)
+}
+
+func (h *hsm) KDF32Reader(r io.Reader) (key []byte, err error) {
+	return h.kdfReader(r, pkcs11.CKM_SHA256_HMAC, 32)
+}
+
+func (h *hsm) KDF64Reader(r io.Reader) (key []byte, err error) {
+	return h.kdfReader(r, pkcs11.CKM_SHA512_HMAC, 64)
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// Generation returns the generation of the selected root key (see
+// RootKey.Generation). It implements (azoo.dev/utils/dvx).GenerationKeyPool,
+// so 0 means the generation is unknown.
+func (h *hsm) Generation() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.key.Generation
 }
 
 func (h *hsm) Close() error {
@@ -345,7 +574,7 @@ func (h *hsm) Close() error {
 
 	err := h.ctx.Finalize()
 	if err != nil {
-		h.log.Warn("finalize failed", logger.NewField("error", err))
+		h.log.Warn("finalize failed", "error", err)
 	}
 
 	h.ctx.Destroy()
@@ -0,0 +1,437 @@
+package hsm_test
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/miekg/pkcs11"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx/hsm"
+	"azoo.dev/utils/dvx/hsm/hsmtest"
+)
+
+func testConfig(fake *hsmtest.Fake) *hsm.Config {
+	return &hsm.Config{
+		Label:        "dvx",
+		UserPin:      "1234",
+		RootKeyID:    "dvx_root_1",
+		RootKeyLabel: "dvx_root",
+		Backend:      fake,
+	}
+}
+
+func TestNew_GeneratesKey(t *testing.T) {
+	fake := hsmtest.New("dvx", "1234")
+	pool, err := hsm.New(testConfig(fake), nil)
+	require.NoError(t, err)
+
+	key32, err := pool.KDF32([]byte("keyRing"))
+	require.NoError(t, err)
+	assert.Len(t, key32, 32)
+	again, err := pool.KDF32([]byte("keyRing"))
+	require.NoError(t, err)
+	assert.Equal(t, key32, again)
+
+	key64, err := pool.KDF64([]byte("keyRing"))
+	require.NoError(t, err)
+	assert.Len(t, key64, 64)
+
+	info := pool.(hsm.InfoKeyPool).Info()
+	assert.Equal(t, "dvx_root_1", info.ID)
+	assert.Equal(t, uint64(1), info.Generation)
+	assert.Equal(t, "CKK_GENERIC_SECRET", info.KeyType)
+	assert.Equal(t, uint64(64), info.ValueLen)
+	assert.True(t, info.Sensitive)
+	assert.False(t, info.Extractable)
+	assert.NotEmpty(t, info.CheckValue)
+
+	require.NoError(t, pool.Close())
+	assert.Equal(t, 0, fake.OpenSessions())
+}
+
+func TestNew_ExistingKey(t *testing.T) {
+	root := bytes.Repeat([]byte{7}, 64)
+	fake := hsmtest.New("dvx", "1234")
+	fake.AddKey("dvx_root", "dvx_root_1", root)
+
+	pool, err := hsm.New(testConfig(fake), nil)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	mac := hmac.New(sha256.New, root)
+	mac.Write([]byte("keyRing"))
+	key, err := pool.KDF32([]byte("keyRing"))
+	require.NoError(t, err)
+	assert.Equal(t, mac.Sum(nil), key)
+}
+
+func TestNew_Errors(t *testing.T) {
+	for name, setup := range map[string]func(fake *hsmtest.Fake, config *hsm.Config){
+		"wrong pin":   func(fake *hsmtest.Fake, config *hsm.Config) { config.UserPin = "0000" },
+		"wrong token": func(fake *hsmtest.Fake, config *hsm.Config) { config.Label = "other" },
+		"mechanism":   func(fake *hsmtest.Fake, config *hsm.Config) { fake.RemoveMechanism(pkcs11.CKM_SHA512_HMAC) },
+		"slot list": func(fake *hsmtest.Fake, config *hsm.Config) {
+			fake.FailOn("GetSlotList", pkcs11.Error(pkcs11.CKR_DEVICE_ERROR))
+		},
+		"generate": func(fake *hsmtest.Fake, config *hsm.Config) {
+			fake.FailOn("GenerateKey", pkcs11.Error(pkcs11.CKR_DEVICE_MEMORY))
+		},
+		"read-only":     func(fake *hsmtest.Fake, config *hsm.Config) { config.ReadOnly = true },
+		"key selection": func(fake *hsmtest.Fake, config *hsm.Config) { config.KeySelection = "oldest" },
+		"ambiguous key": func(fake *hsmtest.Fake, config *hsm.Config) {
+			fake.AddKey("dvx_root", "dvx_root_1", make([]byte, 64))
+			fake.AddKey("dvx_root", "dvx_root_2", make([]byte, 64))
+		},
+	} {
+		fake := hsmtest.New("dvx", "1234")
+		config := testConfig(fake)
+		setup(fake, config)
+
+		_, err := hsm.New(config, nil)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestNew_URI(t *testing.T) {
+	root := bytes.Repeat([]byte{7}, 64)
+	fake := hsmtest.New("dvx token", "1234")
+	fake.AddKey("dvx_root", "dvx_root_1", root)
+
+	pinFile := filepath.Join(t.TempDir(), "pin")
+	require.NoError(t, os.WriteFile(pinFile, []byte("1234\n"), 0600))
+
+	for _, uri := range []string{
+		"pkcs11:token=dvx%20token;object=dvx_root;id=%64%76%78_root_1?pin-value=1234",
+		"pkcs11:token=dvx%20token;object=dvx_root;id=dvx_root_1;type=secret-key?pin-source=file:" + pinFile,
+		"pkcs11:token=dvx%20token;object=dvx_root;id=dvx_root_1",
+	} {
+		pool, err := hsm.New(&hsm.Config{URI: uri, UserPin: userPin(uri), Backend: fake}, nil)
+		require.NoError(t, err, uri)
+		mac := hmac.New(sha256.New, root)
+		mac.Write([]byte("keyRing"))
+		key, err := pool.KDF32([]byte("keyRing"))
+		require.NoError(t, err)
+		assert.Equal(t, mac.Sum(nil), key)
+		require.NoError(t, pool.Close())
+	}
+
+	for _, config := range []*hsm.Config{
+		{URI: "token=dvx", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root?pin-value=1234", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root;id=1;type=private?pin-value=1234", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root;id=1;serial=42?pin-value=1234", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root;id=1?pin-value=1234&pin-source=/run/pin", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root;id=1?pin-source=%7C/bin/pin", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root;id=1?pin-value=1234", Label: "dvx", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root;id=1?pin-value=1234", UserPin: "1234", Backend: fake},
+		{URI: "pkcs11:token=dvx;object=dvx_root;id=1?pin-value=1234"},
+	} {
+		_, err := hsm.New(config, nil)
+		assert.Error(t, err, config.URI)
+	}
+}
+
+// userPin returns the UserPin for uri, which is only allowed without a pin
+// attribute.
+func userPin(uri string) string {
+	if strings.Contains(uri, "pin-") {
+		return ""
+	}
+	return "1234"
+}
+
+func TestNew_KeySelection(t *testing.T) {
+	fake := hsmtest.New("dvx", "1234")
+	fake.AddKey("dvx_root", "dvx_root_1", bytes.Repeat([]byte{1}, 64))
+	fake.AddKey("dvx_root", "dvx_root_2", bytes.Repeat([]byte{2}, 64))
+
+	config := testConfig(fake)
+	config.KeySelection = hsm.SelectNewest
+	config.ReadOnly = true
+	pool, err := hsm.New(config, nil)
+	require.NoError(t, err)
+	defer pool.Close()
+	assert.Equal(t, "dvx_root_2", pool.(hsm.InfoKeyPool).Info().ID)
+
+	keys, err := hsm.ListRootKeys(testConfig(fake), nil)
+	require.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
+
+// recorder records the flags of sessions and the user types of logins of the
+// Fake. Context specific logins fail with contextLoginErr, if set.
+type recorder struct {
+	*hsmtest.Fake
+	flags           []uint
+	logins          []uint
+	contextLoginErr error
+}
+
+func newRecorder(fake *hsmtest.Fake, config *hsm.Config) *recorder {
+	r := &recorder{Fake: fake}
+	config.Backend = r
+	return r
+}
+
+func (r *recorder) OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error) {
+	r.flags = append(r.flags, flags)
+	return r.Fake.OpenSession(slotID, flags)
+}
+
+func (r *recorder) Login(sh pkcs11.SessionHandle, userType uint, pin string) error {
+	r.logins = append(r.logins, userType)
+	if userType == pkcs11.CKU_CONTEXT_SPECIFIC && r.contextLoginErr != nil {
+		return r.contextLoginErr
+	}
+	return r.Fake.Login(sh, userType, pin)
+}
+
+// contextLogins returns the amount of context specific logins.
+func (r *recorder) contextLogins() int {
+	n := 0
+	for _, userType := range r.logins {
+		if userType == pkcs11.CKU_CONTEXT_SPECIFIC {
+			n++
+		}
+	}
+	return n
+}
+
+func TestNew_ReadOnly(t *testing.T) {
+	for _, readOnly := range []bool{false, true} {
+		fake := hsmtest.New("dvx", "1234")
+		fake.AddKey("dvx_root", "dvx_root_1", make([]byte, 64))
+		config := testConfig(fake)
+		config.ReadOnly = readOnly
+		recorder := newRecorder(fake, config)
+
+		pool, err := hsm.New(config, nil)
+		require.NoError(t, err)
+		_, err = pool.KDF32([]byte("keyRing"))
+		require.NoError(t, err)
+		require.NoError(t, pool.Close())
+
+		require.NotEmpty(t, recorder.flags)
+		for _, flags := range recorder.flags {
+			assert.NotZero(t, flags&pkcs11.CKF_SERIAL_SESSION, readOnly)
+			assert.Equal(t, !readOnly, flags&pkcs11.CKF_RW_SESSION != 0, readOnly)
+		}
+	}
+}
+
+func TestKDF_AlwaysAuthenticate(t *testing.T) {
+	root := bytes.Repeat([]byte{3}, 64)
+	fake := hsmtest.New("dvx", "1234")
+	fake.AddKey("dvx_root", "dvx_root_1", root, pkcs11.NewAttribute(pkcs11.CKA_ALWAYS_AUTHENTICATE, true))
+	config := testConfig(fake)
+	config.ReadOnly = true
+	recorder := newRecorder(fake, config)
+
+	pool, err := hsm.New(config, nil)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// every derivation receives a context specific login
+	logins := recorder.contextLogins()
+	for i := 0; i < 2; i++ {
+		mac := hmac.New(sha256.New, root)
+		mac.Write([]byte("keyRing"))
+		key, err := pool.KDF32([]byte("keyRing"))
+		require.NoError(t, err)
+		assert.Equal(t, mac.Sum(nil), key)
+	}
+	assert.Equal(t, logins+2, recorder.contextLogins())
+
+	sessions := fake.OpenSessions()
+	recorder.contextLoginErr = pkcs11.Error(pkcs11.CKR_PIN_LOCKED)
+	_, err = pool.KDF32([]byte("keyRing"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "hsmpool: failed to login context specific")
+	assert.True(t, errors.Is(err, pkcs11.Error(pkcs11.CKR_PIN_LOCKED)))
+	assert.Equal(t, sessions, fake.OpenSessions())
+
+	recorder.contextLoginErr = nil
+	_, err = pool.KDF32([]byte("keyRing"))
+	assert.NoError(t, err)
+}
+
+func TestKDF_NoContextSpecificLogin(t *testing.T) {
+	fake := hsmtest.New("dvx", "1234")
+	fake.AddKey("dvx_root", "dvx_root_1", make([]byte, 64))
+	config := testConfig(fake)
+	recorder := newRecorder(fake, config)
+	recorder.contextLoginErr = pkcs11.Error(pkcs11.CKR_PIN_LOCKED)
+
+	pool, err := hsm.New(config, nil)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	// keys without CKA_ALWAYS_AUTHENTICATE don't login context specific
+	_, err = pool.KDF32([]byte("keyRing"))
+	assert.NoError(t, err)
+	assert.Zero(t, recorder.contextLogins())
+}
+
+func TestKDF_SignFailure(t *testing.T) {
+	fake := hsmtest.New("dvx", "1234")
+	pool, err := hsm.New(testConfig(fake), nil)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	fake.FailOn("Sign", pkcs11.Error(pkcs11.CKR_DEVICE_REMOVED))
+	_, err = pool.KDF32([]byte("keyRing"))
+	assert.Error(t, err)
+
+	fake.FailOn("Sign", nil)
+	_, err = pool.KDF32([]byte("keyRing"))
+	assert.NoError(t, err)
+}
+
+func TestKDF_MultiPart(t *testing.T) {
+	root := bytes.Repeat([]byte{7}, 64)
+	fake := hsmtest.New("dvx", "1234")
+	fake.AddKey("dvx_root", "dvx_root_1", root, pkcs11.NewAttribute(pkcs11.CKA_ALWAYS_AUTHENTICATE, true))
+	fake.SetMaxMessageSize(64)
+
+	config := testConfig(fake)
+	config.MaxSignPartSize = 64
+	pool, err := hsm.New(config, nil)
+	require.NoError(t, err)
+	defer pool.Close()
+
+	for _, size := range []int{0, 63, 64, 65, 128, 1000} {
+		input := bytes.Repeat([]byte("k"), size)
+		mac := hmac.New(sha256.New, root)
+		mac.Write(input)
+		key, err := pool.KDF32(input)
+		require.NoError(t, err, size)
+		assert.Equal(t, mac.Sum(nil), key, size)
+	}
+
+	input := bytes.Repeat([]byte("keyRing"), 100000)
+	mac := hmac.New(sha512.New, root)
+	mac.Write(input)
+	key, err := pool.(hsm.StreamingKeyPool).KDF64Reader(bytes.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, mac.Sum(nil), key)
+
+	fake.FailOn("SignUpdate", pkcs11.Error(pkcs11.CKR_DEVICE_REMOVED))
+	_, err = pool.KDF32(input)
+	assert.Error(t, err)
+	_, err = pool.KDF32([]byte("keyRing"))
+	assert.NoError(t, err)
+
+	// parts larger than the message size of the HSM fail
+	large, err := hsm.New(&hsm.Config{
+		Label:           "dvx",
+		UserPin:         "1234",
+		RootKeyID:       "dvx_root_1",
+		RootKeyLabel:    "dvx_root",
+		MaxSignPartSize: 128,
+		Backend:         fake,
+	}, nil)
+	require.NoError(t, err)
+	defer large.Close()
+	_, err = large.KDF32(bytes.Repeat([]byte("k"), 100))
+	assert.Error(t, err)
+}
+
+func TestKDFReader_Parts(t *testing.T) {
+	root := bytes.Repeat([]byte{3}, 64)
+	fake := hsmtest.New("dvx", "1234")
+	fake.AddKey("dvx_root", "dvx_root_1", root)
+	fake.SetMaxMessageSize(64)
+
+	config := testConfig(fake)
+	config.MaxSignPartSize = 64
+	pool, err := hsm.New(config, nil)
+	require.NoError(t, err)
+	defer pool.Close()
+	streaming := pool.(hsm.StreamingKeyPool)
+	sessions := fake.OpenSessions()
+
+	// inputs larger than MaxSignPartSize never reach C_Sign, only
+	// C_SignUpdate and C_SignFinal
+	fake.FailOn("Sign", pkcs11.Error(pkcs11.CKR_FUNCTION_FAILED))
+	input := bytes.Repeat([]byte("0123456789"), 100)
+	mac := hmac.New(sha256.New, root)
+	mac.Write(input)
+	key, err := streaming.KDF32Reader(bytes.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, mac.Sum(nil), key)
+	_, err = streaming.KDF32Reader(bytes.NewReader(input[:64]))
+	assert.Error(t, err)
+	fake.FailOn("Sign", nil)
+
+	fake.FailOn("SignFinal", pkcs11.Error(pkcs11.CKR_FUNCTION_FAILED))
+	_, err = streaming.KDF32Reader(bytes.NewReader(input))
+	assert.Error(t, err)
+	_, err = streaming.KDF32Reader(bytes.NewReader(input[:64]))
+	assert.NoError(t, err)
+	fake.FailOn("SignFinal", nil)
+
+	// read errors after the operation was initialized abort it and release
+	// the session
+	readErr := errors.New("connection reset")
+	_, err = streaming.KDF32Reader(io.MultiReader(bytes.NewReader(input), iotest.ErrReader(readErr)))
+	assert.ErrorIs(t, err, readErr)
+	assert.Equal(t, sessions, fake.OpenSessions())
+
+	key, err = streaming.KDF32Reader(bytes.NewReader(input))
+	require.NoError(t, err)
+	assert.Equal(t, mac.Sum(nil), key)
+}
+
+func TestKDF_Recheck(t *testing.T) {
+	fake := hsmtest.New("dvx", "1234")
+	handle := fake.AddKey("dvx_root", "dvx_root_1", bytes.Repeat([]byte{1}, 64))
+
+	config := testConfig(fake)
+	config.RecheckInterval = time.Nanosecond
+	pool, err := hsm.New(config, nil)
+	require.NoError(t, err)
+	defer pool.Close()
+	key, err := pool.KDF32([]byte("keyRing"))
+	require.NoError(t, err)
+
+	// invalid handles are resolved again
+	fake.Reload()
+	again, err := pool.KDF32([]byte("keyRing"))
+	require.NoError(t, err)
+	assert.Equal(t, key, again)
+	assert.NotEqual(t, uint(handle), pool.(hsm.InfoKeyPool).Info().Handle)
+
+	// a different key with the same label and id is rejected
+	fake.RemoveObject(pkcs11.ObjectHandle(pool.(hsm.InfoKeyPool).Info().Handle))
+	fake.AddKey("dvx_root", "dvx_root_1", bytes.Repeat([]byte{2}, 64))
+	_, err = pool.KDF32([]byte("keyRing"))
+	assert.Error(t, err)
+}
+
+func TestDiagnose(t *testing.T) {
+	fake := hsmtest.New("dvx", "1234")
+	findings := hsm.Diagnose(testConfig(fake))
+	require.NotEmpty(t, findings)
+	last := findings[len(findings)-1]
+	assert.Equal(t, "root key", last.Check)
+	assert.Equal(t, hsm.SeverityWarning, last.Severity)
+
+	fake.RemoveMechanism(pkcs11.CKM_SHA256_HMAC)
+	findings = hsm.Diagnose(testConfig(fake))
+	last = findings[len(findings)-1]
+	assert.Equal(t, "mechanisms", last.Check)
+	assert.Equal(t, hsm.SeverityError, last.Severity)
+	assert.Contains(t, last.Message, "CKM_SHA256_HMAC")
+}
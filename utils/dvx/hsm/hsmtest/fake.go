@@ -0,0 +1,660 @@
+// Package hsmtest provides an in-process fake of a PKCS#11 token for tests of
+// azoo.dev/utils/dvx/hsm and its users, so they run fast and without SoftHSM.
+//
+// The fake implements hsm.Backend with a single slot holding one token. It
+// supports secret keys with single- and multi-part SHA256-HMAC and
+// SHA512-HMAC signatures, but nothing else, and allows injecting errors into
+// every method:
+//
+//   fake := hsmtest.New("dvx", "1234")
+//   fake.AddKey("dvx_root", "dvx_root_1", key)
+//   pool, err := hsm.New(&hsm.Config{
+//       Label:        "dvx",
+//       UserPin:      "1234",
+//       RootKeyID:    "dvx_root_1",
+//       RootKeyLabel: "dvx_root",
+//       Backend:      fake,
+//   }, nil)
+package hsmtest
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"hash"
+	"sort"
+	"sync"
+
+	"azoo.dev/utils/dvx/hsm"
+	"github.com/miekg/pkcs11"
+)
+
+var _ hsm.Backend = (*Fake)(nil)
+
+// Slot is the ID of the slot of the fake token.
+const Slot uint = 1
+
+// Fake is an in-process PKCS#11 token. Its zero value isn't usable, create
+// instances with New. Fake is safe for concurrent use.
+type Fake struct {
+	mu         sync.Mutex
+	label      string
+	userPin    string
+	mechanisms map[uint]bool
+	failures   map[string]error
+
+	objects    map[pkcs11.ObjectHandle]*object
+	nextObject pkcs11.ObjectHandle
+
+	sessions    map[pkcs11.SessionHandle]*session
+	nextSession pkcs11.SessionHandle
+	loggedIn    bool
+
+	// maxMessageSize is the limit of SetMaxMessageSize
+	maxMessageSize int
+}
+
+// object is a key object. attributes contain the encoded values of
+// pkcs11.NewAttribute.
+type object struct {
+	attributes map[uint][]byte
+	value      []byte
+}
+
+type session struct {
+	flags uint
+	// found are the remaining results of FindObjects, if finding
+	found   []pkcs11.ObjectHandle
+	finding bool
+	// sign is the operation started by SignInit
+	sign *signOperation
+}
+
+type signOperation struct {
+	hash          func() hash.Hash
+	key           *object
+	authenticated bool
+	// mac is the HMAC of a multi-part operation, started by SignUpdate
+	mac hash.Hash
+}
+
+// New returns a Fake with a token labeled label, which accepts userPin for
+// logins. All mechanisms used by azoo.dev/utils/dvx/hsm are supported.
+func New(label string, userPin string) *Fake {
+	return &Fake{
+		label:   label,
+		userPin: userPin,
+		mechanisms: map[uint]bool{
+			pkcs11.CKM_SHA256_HMAC:            true,
+			pkcs11.CKM_SHA512_HMAC:            true,
+			pkcs11.CKM_GENERIC_SECRET_KEY_GEN: true,
+		},
+		failures:    make(map[string]error),
+		objects:     make(map[pkcs11.ObjectHandle]*object),
+		nextObject:  1,
+		sessions:    make(map[pkcs11.SessionHandle]*session),
+		nextSession: 1,
+	}
+}
+
+// AddKey adds a secret key with label, id and value to the token, like a key
+// imported by an operator. attributes override the defaults of the key (e.g.
+// pkcs11.NewAttribute(pkcs11.CKA_ALWAYS_AUTHENTICATE, true)). It returns the
+// handle of the key.
+func (f *Fake) AddKey(label string, id string, value []byte, attributes ...*pkcs11.Attribute) pkcs11.ObjectHandle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	template := append([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, label),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, id),
+	}, attributes...)
+	return f.addObject(template, value)
+}
+
+// addObject adds a secret key with value and the attributes of template.
+func (f *Fake) addObject(template []*pkcs11.Attribute, value []byte) pkcs11.ObjectHandle {
+	checkValue := sha1.Sum(value)
+	obj := &object{
+		attributes: make(map[uint][]byte),
+		value:      append([]byte(nil), value...),
+	}
+	for _, a := range []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_GENERIC_SECRET),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ALWAYS_AUTHENTICATE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, len(value)),
+		pkcs11.NewAttribute(pkcs11.CKA_CHECK_VALUE, checkValue[:3]),
+	} {
+		obj.attributes[a.Type] = a.Value
+	}
+	for _, a := range template {
+		obj.attributes[a.Type] = a.Value
+	}
+
+	handle := f.nextObject
+	f.nextObject++
+	f.objects[handle] = obj
+	return handle
+}
+
+// RemoveObject removes the object with handle from the token.
+func (f *Fake) RemoveObject(handle pkcs11.ObjectHandle) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.objects, handle)
+}
+
+// Reload assigns new handles to all objects, like tokens do after a restart
+// or maintenance, so previous handles become invalid.
+func (f *Fake) Reload() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	handles := make([]pkcs11.ObjectHandle, 0, len(f.objects))
+	for handle := range f.objects {
+		handles = append(handles, handle)
+	}
+	sort.Slice(handles, func(i, j int) bool { return handles[i] < handles[j] })
+
+	objects := make(map[pkcs11.ObjectHandle]*object, len(f.objects))
+	for _, handle := range handles {
+		objects[f.nextObject] = f.objects[handle]
+		f.nextObject++
+	}
+	f.objects = objects
+}
+
+// RemoveMechanism removes mechanism from the supported mechanisms of the
+// token.
+func (f *Fake) RemoveMechanism(mechanism uint) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.mechanisms, mechanism)
+}
+
+// SetMaxMessageSize limits the size of the data passed to Sign and
+// SignUpdate to size bytes, like the message size limits of some HSMs (e.g.
+// AWS CloudHSM). Larger data fails with CKR_DATA_LEN_RANGE. Zero removes the
+// limit.
+func (f *Fake) SetMaxMessageSize(size int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.maxMessageSize = size
+}
+
+// FailOn makes all following calls of the method named method (e.g. "Sign")
+// fail with err. A nil err removes the failure.
+func (f *Fake) FailOn(method string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err == nil {
+		delete(f.failures, method)
+		return
+	}
+	f.failures[method] = err
+}
+
+// OpenSessions returns the amount of open sessions, e.g. to check that all
+// sessions were closed.
+func (f *Fake) OpenSessions() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.sessions)
+}
+
+// Finalize implements hsm.Backend.
+func (f *Fake) Finalize() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.failures["Finalize"]
+}
+
+// Destroy implements hsm.Backend.
+func (f *Fake) Destroy() {}
+
+// GetInfo implements hsm.Backend.
+func (f *Fake) GetInfo() (pkcs11.Info, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failures["GetInfo"]; err != nil {
+		return pkcs11.Info{}, err
+	}
+	return pkcs11.Info{
+		CryptokiVersion:    pkcs11.Version{Major: 2, Minor: 40},
+		ManufacturerID:     "azoo",
+		LibraryDescription: "hsmtest fake",
+	}, nil
+}
+
+// GetSlotList implements hsm.Backend.
+func (f *Fake) GetSlotList(tokenPresent bool) ([]uint, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.failures["GetSlotList"]; err != nil {
+		return nil, err
+	}
+	return []uint{Slot}, nil
+}
+
+// GetTokenInfo implements hsm.Backend.
+func (f *Fake) GetTokenInfo(slotID uint) (pkcs11.TokenInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("GetTokenInfo", slotID); err != nil {
+		return pkcs11.TokenInfo{}, err
+	}
+	return pkcs11.TokenInfo{
+		Label:          f.label,
+		ManufacturerID: "azoo",
+		Model:          "hsmtest",
+		SerialNumber:   "0000000000000001",
+	}, nil
+}
+
+// GetMechanismList implements hsm.Backend.
+func (f *Fake) GetMechanismList(slotID uint) ([]*pkcs11.Mechanism, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("GetMechanismList", slotID); err != nil {
+		return nil, err
+	}
+
+	mechanisms := make([]*pkcs11.Mechanism, 0, len(f.mechanisms))
+	for m := range f.mechanisms {
+		mechanisms = append(mechanisms, pkcs11.NewMechanism(m, nil))
+	}
+	sort.Slice(mechanisms, func(i, j int) bool { return mechanisms[i].Mechanism < mechanisms[j].Mechanism })
+	return mechanisms, nil
+}
+
+// OpenSession implements hsm.Backend.
+func (f *Fake) OpenSession(slotID uint, flags uint) (pkcs11.SessionHandle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if err := f.check("OpenSession", slotID); err != nil {
+		return 0, err
+	}
+	if flags&pkcs11.CKF_SERIAL_SESSION == 0 {
+		return 0, pkcs11.Error(pkcs11.CKR_SESSION_PARALLEL_NOT_SUPPORTED)
+	}
+
+	sh := f.nextSession
+	f.nextSession++
+	f.sessions[sh] = &session{flags: flags}
+	return sh, nil
+}
+
+// CloseSession implements hsm.Backend.
+func (f *Fake) CloseSession(sh pkcs11.SessionHandle) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.session("CloseSession", sh); err != nil {
+		return err
+	}
+
+	delete(f.sessions, sh)
+	// closing the last session logs out the application
+	if len(f.sessions) == 0 {
+		f.loggedIn = false
+	}
+	return nil
+}
+
+// Login implements hsm.Backend. Like real tokens, the login state is shared
+// by all sessions.
+func (f *Fake) Login(sh pkcs11.SessionHandle, userType uint, pin string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("Login", sh)
+	if err != nil {
+		return err
+	}
+
+	switch userType {
+	case pkcs11.CKU_USER:
+		if f.loggedIn {
+			return pkcs11.Error(pkcs11.CKR_USER_ALREADY_LOGGED_IN)
+		}
+		if pin != f.userPin {
+			return pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)
+		}
+		f.loggedIn = true
+	case pkcs11.CKU_CONTEXT_SPECIFIC:
+		if s.sign == nil {
+			return pkcs11.Error(pkcs11.CKR_OPERATION_NOT_INITIALIZED)
+		}
+		if pin != f.userPin {
+			return pkcs11.Error(pkcs11.CKR_PIN_INCORRECT)
+		}
+		s.sign.authenticated = true
+	default:
+		return pkcs11.Error(pkcs11.CKR_USER_TYPE_INVALID)
+	}
+	return nil
+}
+
+// Logout implements hsm.Backend.
+func (f *Fake) Logout(sh pkcs11.SessionHandle) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.session("Logout", sh); err != nil {
+		return err
+	}
+	if !f.loggedIn {
+		return pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)
+	}
+	f.loggedIn = false
+	return nil
+}
+
+// FindObjectsInit implements hsm.Backend. Without login no objects are found,
+// as all keys are private.
+func (f *Fake) FindObjectsInit(sh pkcs11.SessionHandle, temp []*pkcs11.Attribute) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("FindObjectsInit", sh)
+	if err != nil {
+		return err
+	}
+	if s.finding {
+		return pkcs11.Error(pkcs11.CKR_OPERATION_ACTIVE)
+	}
+
+	s.found = nil
+	s.finding = true
+	if !f.loggedIn {
+		return nil
+	}
+	for handle, obj := range f.objects {
+		if obj.matches(temp) {
+			s.found = append(s.found, handle)
+		}
+	}
+	sort.Slice(s.found, func(i, j int) bool { return s.found[i] < s.found[j] })
+	return nil
+}
+
+// FindObjects implements hsm.Backend.
+func (f *Fake) FindObjects(sh pkcs11.SessionHandle, max int) ([]pkcs11.ObjectHandle, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("FindObjects", sh)
+	if err != nil {
+		return nil, false, err
+	}
+	if !s.finding {
+		return nil, false, pkcs11.Error(pkcs11.CKR_OPERATION_NOT_INITIALIZED)
+	}
+
+	n := len(s.found)
+	if n > max {
+		n = max
+	}
+	found := s.found[:n]
+	s.found = s.found[n:]
+	return found, false, nil
+}
+
+// FindObjectsFinal implements hsm.Backend.
+func (f *Fake) FindObjectsFinal(sh pkcs11.SessionHandle) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("FindObjectsFinal", sh)
+	if err != nil {
+		return err
+	}
+	if !s.finding {
+		return pkcs11.Error(pkcs11.CKR_OPERATION_NOT_INITIALIZED)
+	}
+	s.found = nil
+	s.finding = false
+	return nil
+}
+
+// GetAttributeValue implements hsm.Backend. Like *pkcs11.Ctx it fails as a
+// whole if one of the attributes isn't available.
+func (f *Fake) GetAttributeValue(sh pkcs11.SessionHandle, o pkcs11.ObjectHandle, a []*pkcs11.Attribute) ([]*pkcs11.Attribute, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.session("GetAttributeValue", sh); err != nil {
+		return nil, err
+	}
+	obj, err := f.object(o)
+	if err != nil {
+		return nil, err
+	}
+
+	attributes := make([]*pkcs11.Attribute, 0, len(a))
+	for _, attribute := range a {
+		if attribute.Type == pkcs11.CKA_VALUE {
+			return nil, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_SENSITIVE)
+		}
+		value, ok := obj.attributes[attribute.Type]
+		if !ok {
+			return nil, pkcs11.Error(pkcs11.CKR_ATTRIBUTE_TYPE_INVALID)
+		}
+		attributes = append(attributes, &pkcs11.Attribute{Type: attribute.Type, Value: append([]byte(nil), value...)})
+	}
+	return attributes, nil
+}
+
+// GenerateKey implements hsm.Backend. It only supports
+// CKM_GENERIC_SECRET_KEY_GEN with a CKA_VALUE_LEN in temp.
+func (f *Fake) GenerateKey(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, temp []*pkcs11.Attribute) (pkcs11.ObjectHandle, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("GenerateKey", sh)
+	if err != nil {
+		return 0, err
+	}
+	if len(m) != 1 || m[0].Mechanism != pkcs11.CKM_GENERIC_SECRET_KEY_GEN || !f.mechanisms[m[0].Mechanism] {
+		return 0, pkcs11.Error(pkcs11.CKR_MECHANISM_INVALID)
+	}
+	if s.flags&pkcs11.CKF_RW_SESSION == 0 {
+		return 0, pkcs11.Error(pkcs11.CKR_SESSION_READ_ONLY)
+	}
+	if !f.loggedIn {
+		return 0, pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)
+	}
+
+	var valueLen uint64
+	for _, a := range temp {
+		if a.Type == pkcs11.CKA_VALUE_LEN {
+			valueLen = decodeUint(a.Value)
+		}
+	}
+	if valueLen == 0 {
+		return 0, pkcs11.Error(pkcs11.CKR_TEMPLATE_INCOMPLETE)
+	}
+
+	value := make([]byte, valueLen)
+	if _, err := rand.Read(value); err != nil {
+		return 0, pkcs11.Error(pkcs11.CKR_FUNCTION_FAILED)
+	}
+	return f.addObject(temp, value), nil
+}
+
+// SignInit implements hsm.Backend. It only supports CKM_SHA256_HMAC and
+// CKM_SHA512_HMAC.
+func (f *Fake) SignInit(sh pkcs11.SessionHandle, m []*pkcs11.Mechanism, o pkcs11.ObjectHandle) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("SignInit", sh)
+	if err != nil {
+		return err
+	}
+	if s.sign != nil {
+		return pkcs11.Error(pkcs11.CKR_OPERATION_ACTIVE)
+	}
+	if !f.loggedIn {
+		return pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)
+	}
+	obj, err := f.object(o)
+	if err != nil {
+		return pkcs11.Error(pkcs11.CKR_KEY_HANDLE_INVALID)
+	}
+	if !decodeBool(obj.attributes[pkcs11.CKA_SIGN]) {
+		return pkcs11.Error(pkcs11.CKR_KEY_FUNCTION_NOT_PERMITTED)
+	}
+
+	if len(m) != 1 || !f.mechanisms[m[0].Mechanism] {
+		return pkcs11.Error(pkcs11.CKR_MECHANISM_INVALID)
+	}
+	switch m[0].Mechanism {
+	case pkcs11.CKM_SHA256_HMAC:
+		s.sign = &signOperation{hash: sha256.New, key: obj}
+	case pkcs11.CKM_SHA512_HMAC:
+		s.sign = &signOperation{hash: sha512.New, key: obj}
+	default:
+		return pkcs11.Error(pkcs11.CKR_MECHANISM_INVALID)
+	}
+	return nil
+}
+
+// Sign implements hsm.Backend. Keys with CKA_ALWAYS_AUTHENTICATE require a
+// CKU_CONTEXT_SPECIFIC login after SignInit.
+func (f *Fake) Sign(sh pkcs11.SessionHandle, message []byte) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("Sign", sh)
+	if err != nil {
+		return nil, err
+	}
+	op := s.sign
+	// Sign always finishes the operation
+	s.sign = nil
+	if err := f.checkSign(op, message); err != nil {
+		return nil, err
+	}
+	if op.mac != nil {
+		return nil, pkcs11.Error(pkcs11.CKR_OPERATION_ACTIVE)
+	}
+
+	mac := hmac.New(op.hash, op.key.value)
+	mac.Write(message)
+	return mac.Sum(nil), nil
+}
+
+// SignUpdate implements hsm.Backend. It continues a multi-part operation,
+// which SignFinal finishes. Errors finish the operation.
+func (f *Fake) SignUpdate(sh pkcs11.SessionHandle, message []byte) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("SignUpdate", sh)
+	if err != nil {
+		return err
+	}
+	op := s.sign
+	if err := f.checkSign(op, message); err != nil {
+		s.sign = nil
+		return err
+	}
+
+	if op.mac == nil {
+		op.mac = hmac.New(op.hash, op.key.value)
+	}
+	op.mac.Write(message)
+	return nil
+}
+
+// SignFinal implements hsm.Backend. It finishes the operation and returns the
+// HMAC of all parts passed to SignUpdate.
+func (f *Fake) SignFinal(sh pkcs11.SessionHandle) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	s, err := f.session("SignFinal", sh)
+	if err != nil {
+		return nil, err
+	}
+	op := s.sign
+	s.sign = nil
+	if err := f.checkSign(op, nil); err != nil {
+		return nil, err
+	}
+
+	if op.mac == nil {
+		op.mac = hmac.New(op.hash, op.key.value)
+	}
+	return op.mac.Sum(nil), nil
+}
+
+// checkSign checks that op was initialized and authenticated, and that
+// message doesn't exceed the maximum message size.
+func (f *Fake) checkSign(op *signOperation, message []byte) error {
+	if op == nil {
+		return pkcs11.Error(pkcs11.CKR_OPERATION_NOT_INITIALIZED)
+	}
+	if decodeBool(op.key.attributes[pkcs11.CKA_ALWAYS_AUTHENTICATE]) && !op.authenticated {
+		return pkcs11.Error(pkcs11.CKR_USER_NOT_LOGGED_IN)
+	}
+	if f.maxMessageSize > 0 && len(message) > f.maxMessageSize {
+		return pkcs11.Error(pkcs11.CKR_DATA_LEN_RANGE)
+	}
+	return nil
+}
+
+// check returns the injected failure of method, or an error if slotID isn't
+// the Slot of the fake.
+func (f *Fake) check(method string, slotID uint) error {
+	if err := f.failures[method]; err != nil {
+		return err
+	}
+	if slotID != Slot {
+		return pkcs11.Error(pkcs11.CKR_SLOT_ID_INVALID)
+	}
+	return nil
+}
+
+// session returns the injected failure of method, or the session sh.
+func (f *Fake) session(method string, sh pkcs11.SessionHandle) (*session, error) {
+	if err := f.failures[method]; err != nil {
+		return nil, err
+	}
+	s, ok := f.sessions[sh]
+	if !ok {
+		return nil, pkcs11.Error(pkcs11.CKR_SESSION_HANDLE_INVALID)
+	}
+	return s, nil
+}
+
+// object returns the object with handle o.
+func (f *Fake) object(o pkcs11.ObjectHandle) (*object, error) {
+	obj, ok := f.objects[o]
+	if !ok {
+		return nil, pkcs11.Error(pkcs11.CKR_OBJECT_HANDLE_INVALID)
+	}
+	return obj, nil
+}
+
+// matches reports whether obj has all attributes of temp.
+func (obj *object) matches(temp []*pkcs11.Attribute) bool {
+	for _, a := range temp {
+		if value, ok := obj.attributes[a.Type]; !ok || !bytes.Equal(value, a.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeUint decodes a CK_ULONG in native (little-endian) byte order.
+func decodeUint(value []byte) uint64 {
+	var n uint64
+	for i := len(value) - 1; i >= 0; i-- {
+		n = n<<8 | uint64(value[i])
+	}
+	return n
+}
+
+// decodeBool decodes a CK_BBOOL.
+func decodeBool(value []byte) bool {
+	return len(value) == 1 && value[0] != 0
+}
@@ -0,0 +1,248 @@
+package hsm
+
+import (
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/miekg/pkcs11"
+)
+
+// InfoKeyPool is a KeyPool that reports the root key it is bound to. The
+// KeyPool returned by New implements it.
+type InfoKeyPool interface {
+	KeyPool
+	// Info returns the snapshot of the root key.
+	Info() KeyInfo
+}
+
+// KeyInfo is a snapshot of the token and root key a KeyPool is bound to. It
+// is taken by New and compared on every recheck (see
+// Config.RecheckInterval), so operators can confirm the service still uses the
+// intended key object, e.g. after HSM maintenance.
+type KeyInfo struct {
+	// TokenLabel is the label of the token.
+	TokenLabel string
+	// TokenSerial is the serial number of the token.
+	TokenSerial string
+	// Slot is the ID of the slot of the token.
+	Slot uint
+	// Label is the CKA_LABEL of the root key.
+	Label string
+	// ID is the CKA_ID of the root key. For example: "dvx_root_2"
+	ID string
+	// Generation is the generation of the root key (see RootKey.Generation).
+	Generation uint64
+	// Handle is the object handle of the root key. It may change when the
+	// handle is resolved again.
+	Handle uint
+	// KeyType is the name of the CKA_KEY_TYPE of the root key, or its value in
+	// hex for unknown types. For example: "CKK_GENERIC_SECRET"
+	KeyType string
+	// ValueLen is the CKA_VALUE_LEN of the root key in bytes, or 0 if the
+	// token doesn't report it. For example: 32
+	ValueLen uint64
+	// Sensitive is the CKA_SENSITIVE of the root key.
+	Sensitive bool
+	// Extractable is the CKA_EXTRACTABLE of the root key.
+	Extractable bool
+	// AlwaysAuthenticate is the CKA_ALWAYS_AUTHENTICATE of the root key.
+	AlwaysAuthenticate bool
+	// CheckValue is the CKA_CHECK_VALUE of the root key in hex, or empty if
+	// the token doesn't report it. It identifies the key material without
+	// revealing it.
+	CheckValue string
+	// CheckedAt is the time the snapshot was taken.
+	CheckedAt time.Time
+}
+
+// keyTypes are the names of CKA_KEY_TYPE values of HMAC capable keys.
+var keyTypes = map[uint64]string{
+	pkcs11.CKK_GENERIC_SECRET: "CKK_GENERIC_SECRET",
+	pkcs11.CKK_SHA256_HMAC:    "CKK_SHA256_HMAC",
+	pkcs11.CKK_SHA512_HMAC:    "CKK_SHA512_HMAC",
+	pkcs11.CKK_AES:            "CKK_AES",
+}
+
+// Info returns the snapshot of the root key. It implements InfoKeyPool.
+func (h *hsm) Info() KeyInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.info
+}
+
+// snapshotKey takes the snapshot of the selected root key during New.
+func (h *hsm) snapshotKey() error {
+	_, err := h.inSession(true, func(session pkcs11.SessionHandle) (err error) {
+		h.info, err = h.snapshot(session, h.key)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	h.log.Info("bound root key",
+		"token_serial", h.info.TokenSerial,
+		"key_label", h.info.Label,
+		"key_id", h.info.ID,
+		"key_handle", h.info.Handle,
+		"key_type", h.info.KeyType,
+		"value_len", h.info.ValueLen,
+		"sensitive", h.info.Sensitive,
+		"extractable", h.info.Extractable,
+		"check_value", h.info.CheckValue)
+	if h.info.Extractable || !h.info.Sensitive {
+		h.log.Warn("root key can be extracted from the HSM",
+			"sensitive", h.info.Sensitive,
+			"extractable", h.info.Extractable)
+	}
+	return nil
+}
+
+// snapshot reads the attributes of key. It fails if the handle of key isn't
+// valid anymore.
+func (h *hsm) snapshot(session pkcs11.SessionHandle, key RootKey) (KeyInfo, error) {
+	info := KeyInfo{
+		TokenLabel:  h.token.Label,
+		TokenSerial: h.token.SerialNumber,
+		Slot:        h.slot,
+		Generation:  key.Generation,
+		Handle:      uint(key.handle),
+		CheckedAt:   time.Now(),
+	}
+
+	// attributes every key object has. Optional attributes are read on their
+	// own, as tokens without support for one fail the whole call
+	attributes, err := h.ctx.GetAttributeValue(session, key.handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, nil),
+	})
+	if err != nil {
+		return KeyInfo{}, fmt.Errorf("hsmpool: failed to read attributes of root key %d: %w", key.handle, err)
+	}
+	for _, a := range attributes {
+		switch a.Type {
+		case pkcs11.CKA_LABEL:
+			info.Label = string(a.Value)
+		case pkcs11.CKA_ID:
+			info.ID = string(a.Value)
+		case pkcs11.CKA_KEY_TYPE:
+			keyType := attributeUint(a.Value)
+			info.KeyType = keyTypes[keyType]
+			if info.KeyType == "" {
+				info.KeyType = fmt.Sprintf("0x%x", keyType)
+			}
+		}
+	}
+
+	if value := h.readAttribute(session, key.handle, pkcs11.CKA_VALUE_LEN); value != nil {
+		info.ValueLen = attributeUint(value)
+	}
+	info.Sensitive = attributeBool(h.readAttribute(session, key.handle, pkcs11.CKA_SENSITIVE))
+	info.Extractable = attributeBool(h.readAttribute(session, key.handle, pkcs11.CKA_EXTRACTABLE))
+	info.AlwaysAuthenticate = attributeBool(h.readAttribute(session, key.handle, pkcs11.CKA_ALWAYS_AUTHENTICATE))
+	info.CheckValue = hex.EncodeToString(h.readAttribute(session, key.handle, pkcs11.CKA_CHECK_VALUE))
+	return info, nil
+}
+
+// readAttribute reads the attribute typ of handle, or returns nil if the token
+// doesn't support it.
+func (h *hsm) readAttribute(session pkcs11.SessionHandle, handle pkcs11.ObjectHandle, typ uint) []byte {
+	attributes, err := h.ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{pkcs11.NewAttribute(typ, nil)})
+	if err != nil || len(attributes) != 1 {
+		return nil
+	}
+	return attributes[0].Value
+}
+
+// attributeUint decodes a CK_ULONG attribute value. PKCS#11 modules use the
+// native byte order, which is little-endian on all supported platforms.
+func attributeUint(value []byte) uint64 {
+	var n uint64
+	for i := len(value) - 1; i >= 0; i-- {
+		n = n<<8 | uint64(value[i])
+	}
+	return n
+}
+
+// attributeBool decodes a CK_BBOOL attribute value.
+func attributeBool(value []byte) bool {
+	return len(value) == 1 && value[0] != 0
+}
+
+// sameKey reports whether a and b describe the same key object, regardless of
+// its handle. CheckValue is only compared if both snapshots have one.
+func sameKey(a, b KeyInfo) bool {
+	if a.CheckValue != "" && b.CheckValue != "" && a.CheckValue != b.CheckValue {
+		return false
+	}
+	return a.Label == b.Label &&
+		a.ID == b.ID &&
+		a.KeyType == b.KeyType &&
+		a.ValueLen == b.ValueLen
+}
+
+// recheck verifies the handle of the root key once RecheckInterval elapsed
+// since the last snapshot. An invalid handle is resolved again with the
+// KeySelection. recheck fails if the handle refers to a different key than
+// the snapshot of New, so no keys are derived from an unintended root key.
+func (h *hsm) recheck() error {
+	if h.config.RecheckInterval <= 0 {
+		return nil
+	}
+	h.mu.RLock()
+	due := time.Since(h.info.CheckedAt) >= h.config.RecheckInterval
+	h.mu.RUnlock()
+	if !due {
+		return nil
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	// another derivation may have finished the recheck in the meantime
+	if time.Since(h.info.CheckedAt) < h.config.RecheckInterval {
+		return nil
+	}
+
+	_, err := h.inSession(true, func(session pkcs11.SessionHandle) error {
+		key := h.key
+		info, err := h.snapshot(session, key)
+		if err != nil {
+			h.log.Warn("root key handle invalid. Resolving it again",
+				"key_handle", key.handle,
+				"error", err)
+
+			keys, err := h.findKeys(session)
+			if err != nil {
+				return err
+			}
+			if len(keys) == 0 {
+				return fmt.Errorf("hsmpool: root key with label %q not found anymore", h.config.RootKeyLabel)
+			}
+			key, err = selectKey(h.config, keys)
+			if err != nil {
+				return err
+			}
+			info, err = h.snapshot(session, key)
+			if err != nil {
+				return err
+			}
+		}
+
+		if !sameKey(h.info, info) {
+			return fmt.Errorf("hsmpool: root key changed: bound to id %q (type %s, check value %q), found id %q (type %s, check value %q)",
+				h.info.ID, h.info.KeyType, h.info.CheckValue, info.ID, info.KeyType, info.CheckValue)
+		}
+		if key.handle != h.key.handle {
+			h.log.Info("root key handle resolved again",
+				"previous_key_handle", h.key.handle,
+				"key_handle", key.handle,
+				"key_id", key.ID)
+		}
+		h.key = key
+		h.info = info
+		return nil
+	})
+	return err
+}
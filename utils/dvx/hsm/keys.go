@@ -0,0 +1,195 @@
+package hsm
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/miekg/pkcs11"
+)
+
+// KeySelection selects the root key among all keys with the RootKeyLabel.
+// Tokens often keep multiple generations of a key under the same label, which
+// differ in their CKA_ID.
+type KeySelection string
+
+const (
+	// SelectUnique requires exactly one key with the RootKeyLabel and fails
+	// if there are more. It is the default.
+	SelectUnique KeySelection = ""
+	// SelectByID selects the key with the RootKeyLabel and the RootKeyID.
+	SelectByID KeySelection = "id"
+	// SelectNewest selects the key with the RootKeyLabel and the highest
+	// generation (see RootKey.Generation).
+	SelectNewest KeySelection = "newest"
+	// SelectGeneration selects the key with the RootKeyLabel and the
+	// generation RootKeyGeneration.
+	SelectGeneration KeySelection = "generation"
+)
+
+// maxRootKeys limits the amount of keys with the RootKeyLabel that are
+// considered by the selection.
+const maxRootKeys = 64
+
+// RootKey is a key found on the token by ListRootKeys.
+type RootKey struct {
+	// Label is the CKA_LABEL of the key.
+	Label string
+	// ID is the CKA_ID of the key. For example: "dvx_root_2"
+	ID string
+	// Generation is the number at the end of ID, or 0 if ID doesn't end with
+	// a number. For example: 2 for "dvx_root_2"
+	Generation uint64
+	// AlwaysAuthenticate reports whether the key has CKA_ALWAYS_AUTHENTICATE
+	// set, so every use requires a CKU_CONTEXT_SPECIFIC login.
+	AlwaysAuthenticate bool
+
+	handle pkcs11.ObjectHandle
+}
+
+// ListRootKeys returns all keys with the RootKeyLabel of config on the token,
+// without generating a root key or deriving any keys, e.g. to find out which
+// generations exist before configuring a KeySelection.
+func ListRootKeys(config *Config, log Logger) (keys []RootKey, err error) {
+	h := &hsm{
+		log:    named(log, "hsm"),
+		config: config,
+	}
+	if err = h.initCtx(); err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = h.ctx.Finalize()
+		h.ctx.Destroy()
+	}()
+	if err = h.selectSlot(); err != nil {
+		return nil, err
+	}
+
+	_, err = h.inSession(true, func(session pkcs11.SessionHandle) error {
+		keys, err = h.findKeys(session)
+		return err
+	})
+	return keys, err
+}
+
+// findKeys returns all keys with the RootKeyLabel.
+func (h *hsm) findKeys(session pkcs11.SessionHandle) ([]RootKey, error) {
+	err := h.ctx.FindObjectsInit(session, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_LABEL, h.config.RootKeyLabel)})
+	if err != nil {
+		return nil, fmt.Errorf("hsmpool: failed to init find objects: %w", err)
+	}
+
+	objHandles, _, err := h.ctx.FindObjects(session, maxRootKeys)
+	if err != nil {
+		_ = h.ctx.FindObjectsFinal(session)
+		return nil, fmt.Errorf("hsmpool: failed to find objects: %w", err)
+	}
+
+	err = h.ctx.FindObjectsFinal(session)
+	if err != nil {
+		return nil, fmt.Errorf("hsmpool: failed to finalize object search: %w", err)
+	}
+
+	keys := make([]RootKey, 0, len(objHandles))
+	for _, handle := range objHandles {
+		attributes, err := h.ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+			pkcs11.NewAttribute(pkcs11.CKA_LABEL, nil),
+			pkcs11.NewAttribute(pkcs11.CKA_ID, nil),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("hsmpool: failed to read attributes of object %d: %w", handle, err)
+		}
+
+		key := RootKey{handle: handle}
+		for _, a := range attributes {
+			switch a.Type {
+			case pkcs11.CKA_LABEL:
+				key.Label = string(a.Value)
+			case pkcs11.CKA_ID:
+				key.ID = string(a.Value)
+			}
+		}
+		key.Generation = keyGeneration(key.ID)
+		key.AlwaysAuthenticate = h.readAlwaysAuthenticate(session, handle)
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// readAlwaysAuthenticate reads CKA_ALWAYS_AUTHENTICATE of handle. It is read on
+// its own, as tokens without support for the attribute fail the whole
+// GetAttributeValue call, and treated as false in that case.
+func (h *hsm) readAlwaysAuthenticate(session pkcs11.SessionHandle, handle pkcs11.ObjectHandle) bool {
+	attributes, err := h.ctx.GetAttributeValue(session, handle, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_ALWAYS_AUTHENTICATE, nil),
+	})
+	if err != nil || len(attributes) != 1 {
+		return false
+	}
+	return len(attributes[0].Value) == 1 && attributes[0].Value[0] != 0
+}
+
+// keyGeneration returns the number at the end of id, or 0 if id doesn't end
+// with a number.
+func keyGeneration(id string) uint64 {
+	i := len(id)
+	for i > 0 && id[i-1] >= '0' && id[i-1] <= '9' {
+		i--
+	}
+	generation, err := strconv.ParseUint(id[i:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return generation
+}
+
+// selectKey selects the root key among keys according to the KeySelection of
+// config. keys must not be empty.
+func selectKey(config *Config, keys []RootKey) (RootKey, error) {
+	var candidates []RootKey
+	switch config.KeySelection {
+	case SelectUnique:
+		candidates = keys
+	case SelectByID:
+		for _, key := range keys {
+			if key.ID == config.RootKeyID {
+				candidates = append(candidates, key)
+			}
+		}
+	case SelectNewest:
+		for _, key := range keys {
+			if len(candidates) == 0 || key.Generation > candidates[0].Generation {
+				candidates = []RootKey{key}
+			} else if key.Generation == candidates[0].Generation {
+				candidates = append(candidates, key)
+			}
+		}
+	case SelectGeneration:
+		for _, key := range keys {
+			if key.Generation == config.RootKeyGeneration {
+				candidates = append(candidates, key)
+			}
+		}
+	default:
+		return RootKey{}, fmt.Errorf("hsmpool: unknown key selection %q", config.KeySelection)
+	}
+
+	switch len(candidates) {
+	case 0:
+		return RootKey{}, fmt.Errorf("hsmpool: no key with label %q matches key selection %q. Found: %s", config.RootKeyLabel, config.KeySelection, describeKeys(keys))
+	case 1:
+		return candidates[0], nil
+	default:
+		return RootKey{}, fmt.Errorf("hsmpool: %d keys with label %q match key selection %q: %s", len(candidates), config.RootKeyLabel, config.KeySelection, describeKeys(candidates))
+	}
+}
+
+// describeKeys lists the IDs and generations of keys for error messages.
+func describeKeys(keys []RootKey) string {
+	described := make([]string, 0, len(keys))
+	for _, key := range keys {
+		described = append(described, fmt.Sprintf("id %q (generation %d)", key.ID, key.Generation))
+	}
+	return strings.Join(described, ", ")
+}
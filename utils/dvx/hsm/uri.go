@@ -0,0 +1,148 @@
+package hsm
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// ModulePathEnv is the environment variable with the path of the PKCS#11
+// module, if neither the URI (module-path) nor Config.Module specify it.
+const ModulePathEnv = "PKCS11_MODULE_PATH"
+
+// resolveURI returns a copy of config with the fields specified by its URI
+// (see Config.URI), or config itself if it has no URI.
+func resolveURI(config *Config) (*Config, error) {
+	if config.URI == "" {
+		return config, nil
+	}
+	if config.Label != "" || config.RootKeyID != "" || config.RootKeyLabel != "" {
+		return nil, fmt.Errorf("hsmpool: URI cannot be combined with Label, RootKeyID and RootKeyLabel")
+	}
+
+	path, query, err := parseURI(config.URI)
+	if err != nil {
+		return nil, err
+	}
+
+	c := *config
+	for name, value := range path {
+		switch name {
+		case "token":
+			c.Label = value
+		case "object":
+			c.RootKeyLabel = value
+		case "id":
+			c.RootKeyID = value
+		case "type":
+			if value != "secret-key" {
+				return nil, fmt.Errorf("hsmpool: URI type must be %q, but is %q", "secret-key", value)
+			}
+		default:
+			return nil, fmt.Errorf("hsmpool: URI attribute %q isn't supported", name)
+		}
+	}
+	for name, value := range query {
+		switch name {
+		case "module-path":
+			if c.Module != "" {
+				return nil, fmt.Errorf("hsmpool: URI module-path cannot be combined with Module")
+			}
+			c.Module = value
+		case "pin-value", "pin-source":
+			if c.UserPin != "" {
+				return nil, fmt.Errorf("hsmpool: URI %s cannot be combined with UserPin or another pin attribute", name)
+			}
+			if name == "pin-value" {
+				c.UserPin = value
+			} else if c.UserPin, err = readPinSource(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, fmt.Errorf("hsmpool: URI query attribute %q isn't supported", name)
+		}
+	}
+
+	if c.Label == "" || c.RootKeyLabel == "" || c.RootKeyID == "" {
+		return nil, fmt.Errorf("hsmpool: URI requires the attributes token, object and id")
+	}
+	if c.Module == "" && c.Backend == nil {
+		c.Module = os.Getenv(ModulePathEnv)
+		if c.Module == "" {
+			return nil, fmt.Errorf("hsmpool: URI has no module-path and %s isn't set", ModulePathEnv)
+		}
+	}
+	return &c, nil
+}
+
+// parseURI parses a PKCS#11 URI (RFC 7512) into its path and query
+// attributes, with percent-encoded values decoded.
+func parseURI(uri string) (path map[string]string, query map[string]string, err error) {
+	const scheme = "pkcs11:"
+	if !strings.HasPrefix(strings.ToLower(uri), scheme) {
+		return nil, nil, fmt.Errorf("hsmpool: URI must start with %q", scheme)
+	}
+	rest := uri[len(scheme):]
+	var rawQuery string
+	if i := strings.IndexByte(rest, '?'); i != -1 {
+		rest, rawQuery = rest[:i], rest[i+1:]
+	}
+
+	if path, err = parseURIAttributes(rest, ";"); err != nil {
+		return nil, nil, err
+	}
+	if query, err = parseURIAttributes(rawQuery, "&"); err != nil {
+		return nil, nil, err
+	}
+	return path, query, nil
+}
+
+// parseURIAttributes parses the attributes name=value of s separated by sep.
+// Attributes must not be repeated.
+func parseURIAttributes(s string, sep string) (map[string]string, error) {
+	attributes := make(map[string]string)
+	if s == "" {
+		return attributes, nil
+	}
+	for _, attribute := range strings.Split(s, sep) {
+		i := strings.IndexByte(attribute, '=')
+		if i == -1 {
+			return nil, fmt.Errorf("hsmpool: URI attribute %q has no value", attribute)
+		}
+		name := attribute[:i]
+		// unlike url.QueryUnescape, PathUnescape keeps "+", which RFC 7512
+		// doesn't treat as a space
+		value, err := url.PathUnescape(attribute[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("hsmpool: URI attribute %q has an invalid value: %w", name, err)
+		}
+		if _, ok := attributes[name]; ok {
+			return nil, fmt.Errorf("hsmpool: URI attribute %q is repeated", name)
+		}
+		attributes[name] = value
+	}
+	return attributes, nil
+}
+
+// readPinSource reads the pin from source, a file path or file: URI.
+// Trailing line breaks are removed.
+func readPinSource(source string) (string, error) {
+	path := source
+	if strings.HasPrefix(source, "file:") {
+		u, err := url.Parse(source)
+		if err != nil {
+			return "", fmt.Errorf("hsmpool: invalid URI pin-source: %w", err)
+		}
+		path = u.Path
+	}
+	if path == "" || strings.HasPrefix(source, "|") {
+		return "", fmt.Errorf("hsmpool: URI pin-source must be a file, but is %q", source)
+	}
+
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("hsmpool: failed to read URI pin-source: %w", err)
+	}
+	return strings.TrimRight(string(buf), "\r\n"), nil
+}
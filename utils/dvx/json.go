@@ -0,0 +1,217 @@
+package dvx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// SignJSON is like Sign, but signs the canonical JSON encoding of v (see
+// CanonicalJSON). Signatures stay valid when the signed document is
+// re-marshaled, re-ordered or re-formatted in between, as long as its values
+// don't change.
+func (p *Protocol) SignJSON(keyRing string, v interface{}) (signature string, err error) {
+	return p.SignJSONContext(context.Background(), keyRing, v)
+}
+
+// SignJSONContext is like SignJSON, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) SignJSONContext(ctx context.Context, keyRing string, v interface{}) (signature string, err error) {
+	message, err := CanonicalJSON(v)
+	if err != nil {
+		return "", err
+	}
+
+	signature, _, err = p.SignContext(ctx, keyRing, message)
+	return signature, err
+}
+
+// VerifyJSON is like Verify, but verifies a signature of SignJSON for the
+// canonical JSON encoding of v. Received documents can be passed as
+// json.RawMessage.
+func (p *Protocol) VerifyJSON(keyRing string, v interface{}, signature string) (valid bool, err error) {
+	return p.VerifyJSONContext(context.Background(), keyRing, v, signature)
+}
+
+// VerifyJSONContext is like VerifyJSON, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) VerifyJSONContext(ctx context.Context, keyRing string, v interface{}, signature string) (valid bool, err error) {
+	message, err := CanonicalJSON(v)
+	if err != nil {
+		return false, err
+	}
+
+	return p.VerifyContext(ctx, keyRing, message, signature)
+}
+
+// CanonicalJSON marshals v with encoding/json and returns the canonical form
+// of the result, following the JSON Canonicalization Scheme (RFC 8785):
+//   - no whitespace
+//   - object members sorted by the UTF-16 code units of their names
+//   - numbers normalized to their shortest representation as IEEE 754 double
+//     (e.g. 1.0, 1e0 and 1 are all encoded as 1)
+//   - strings only escape '"', '\' and control characters
+//
+// Integers beyond ±2^53 can't be represented exactly as double and should
+// be encoded as strings (e.g. with the ",string" option of encoding/json).
+func CanonicalJSON(v interface{}) ([]byte, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("dvx: unable to marshal JSON: %w", err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: invalid JSON: %v", err)
+	}
+
+	out := &bytes.Buffer{}
+	if err := writeCanonicalJSON(out, doc); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+func writeCanonicalJSON(out *bytes.Buffer, v interface{}) error {
+	switch v := v.(type) {
+	case nil:
+		out.WriteString("null")
+	case bool:
+		out.WriteString(strconv.FormatBool(v))
+	case json.Number:
+		n, err := canonicalNumber(v)
+		if err != nil {
+			return err
+		}
+		out.WriteString(n)
+	case string:
+		writeCanonicalString(out, v)
+	case []interface{}:
+		out.WriteByte('[')
+		for i, elem := range v {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			if err := writeCanonicalJSON(out, elem); err != nil {
+				return err
+			}
+		}
+		out.WriteByte(']')
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return lessUTF16(names[i], names[j])
+		})
+
+		out.WriteByte('{')
+		for i, name := range names {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			writeCanonicalString(out, name)
+			out.WriteByte(':')
+			if err := writeCanonicalJSON(out, v[name]); err != nil {
+				return err
+			}
+		}
+		out.WriteByte('}')
+	default:
+		return fmt.Errorf("dvx: unexpected JSON value of type %T", v)
+	}
+	return nil
+}
+
+// canonicalNumber formats n like ECMAScript's Number.prototype.toString,
+// as required by RFC 8785.
+func canonicalNumber(n json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil || math.IsInf(f, 0) {
+		return "", errorf(ErrInvalidFormat, "dvx: JSON number %s can't be represented as double", n)
+	}
+	if f == 0 {
+		// includes -0
+		return "0", nil
+	}
+
+	if abs := math.Abs(f); abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(f, 'f', -1, 64), nil
+	}
+
+	// Go formats exponents with at least two digits (1e-07), ECMAScript
+	// without leading zeros (1e-7)
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	idx := strings.IndexByte(s, 'e')
+	mantissa, sign, exponent := s[:idx], s[idx+1], strings.TrimLeft(s[idx+2:], "0")
+	return mantissa + "e" + string(sign) + exponent, nil
+}
+
+// writeCanonicalString writes s as JSON string. Only '"', '\' and control
+// characters are escaped, the latter with their short form if available.
+// s is always valid UTF-8, as encoding/json replaces invalid bytes.
+func writeCanonicalString(out *bytes.Buffer, s string) {
+	const hex = "0123456789abcdef"
+
+	out.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r == '\b':
+			out.WriteString(`\b`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\f':
+			out.WriteString(`\f`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r < 0x20:
+			out.WriteString(`\u00`)
+			out.WriteByte(hex[r>>4])
+			out.WriteByte(hex[r&0xf])
+		default:
+			out.WriteRune(r)
+		}
+	}
+	out.WriteByte('"')
+}
+
+// lessUTF16 compares a and b by their UTF-16 code units.
+func lessUTF16(a, b string) bool {
+	if isBMP(a) && isBMP(b) {
+		// without surrogates the order of code units equals the byte order
+		// of UTF-8
+		return a < b
+	}
+
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
+	}
+	return len(ua) < len(ub)
+}
+
+// isBMP reports whether all runes of s are in the Basic Multilingual Plane.
+func isBMP(s string) bool {
+	for _, r := range s {
+		if r > 0xffff {
+			return false
+		}
+	}
+	return true
+}
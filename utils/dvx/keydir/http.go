@@ -0,0 +1,103 @@
+package keydir
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// JWK is an Ed25519 public key as JSON Web Key (RFC 8037).
+type JWK struct {
+	// KeyType is always "OKP".
+	KeyType string `json:"kty"`
+	// Curve is always "Ed25519".
+	Curve string `json:"crv"`
+	// X is the public key, base64 url encoded without padding.
+	X string `json:"x"`
+	// KeyID is the keyRing of the public key.
+	KeyID string `json:"kid"`
+	// Use is always "sig".
+	Use string `json:"use"`
+	// Algorithm is always "EdDSA".
+	Algorithm string `json:"alg"`
+}
+
+// JWKS is a JSON Web Key Set (RFC 7517).
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// NewJWK returns the JWK of the public key of keyRing.
+func NewJWK(keyRing string, publicKey ed25519.PublicKey) JWK {
+	return JWK{
+		KeyType:   "OKP",
+		Curve:     "Ed25519",
+		X:         base64.RawURLEncoding.EncodeToString(publicKey),
+		KeyID:     keyRing,
+		Use:       "sig",
+		Algorithm: "EdDSA",
+	}
+}
+
+// JWKS returns the key set of all keyRings of Config.KeyRings and of all
+// cached keyRings.
+func (d *Directory) JWKS(ctx context.Context) (*JWKS, error) {
+	set := &JWKS{Keys: []JWK{}}
+	for _, keyRing := range d.listed() {
+		publicKey, err := d.PublicKey(ctx, keyRing)
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, NewJWK(keyRing, publicKey))
+	}
+	return set, nil
+}
+
+// Handler serves the public keys of d as JSON. Requests with the query
+// parameter "kid" receive the JWK of that keyRing (status 404 if it isn't
+// published), others the key set of JWKS. Keys that can't be derived result
+// in status 500.
+func (d *Directory) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "keydir: method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var v interface{}
+		if keyRing := req.URL.Query().Get("kid"); keyRing != "" {
+			publicKey, err := d.PublicKey(req.Context(), keyRing)
+			if errors.Is(err, ErrNotPublished) {
+				http.Error(w, "keydir: key not found", http.StatusNotFound)
+				return
+			}
+			if err != nil {
+				http.Error(w, "keydir: unable to derive key", http.StatusInternalServerError)
+				return
+			}
+			v = NewJWK(keyRing, publicKey)
+		} else {
+			set, err := d.JWKS(req.Context())
+			if err != nil {
+				http.Error(w, "keydir: unable to derive keys", http.StatusInternalServerError)
+				return
+			}
+			v = set
+		}
+
+		body, err := json.Marshal(v)
+		if err != nil {
+			http.Error(w, "keydir: unable to encode keys", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(body)
+	})
+}
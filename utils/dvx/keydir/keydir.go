@@ -0,0 +1,167 @@
+// Package keydir publishes the public keys of dvx keyRings, so verifiers
+// across a fleet never need access to a KeyPool. A Directory derives the
+// public key of a keyRing (see dvx.Protocol.CreateSignKey) on its first
+// request and caches it. Only keyRings matching one of the configured
+// patterns are published, so clients can't trigger derivations for
+// arbitrary keyRings.
+//
+// Directory.PublicKey returns a single key to Go code, Directory.Handler
+// serves the keys as JSON Web Key Set (RFC 7517, keys of type "OKP" and curve
+// "Ed25519" as in RFC 8037), e.g. under /.well-known/jwks.json.
+package keydir
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotPublished is returned for keyRings that don't match a pattern of the
+// Directory.
+var ErrNotPublished = errors.New("keydir: keyRing isn't published")
+
+// Protocol derives the public keys of a Directory, e.g. a *dvx.Protocol.
+type Protocol interface {
+	CreateSignKeyContext(ctx context.Context, keyRing string) (publicKey []byte, err error)
+}
+
+// Config configures a Directory.
+type Config struct {
+	// Protocol derives the public keys.
+	Protocol Protocol
+	// Patterns are the published keyRings, as patterns of path.Match. For
+	// example: "services/*"
+	Patterns []string
+	// KeyRings are listed in the key set of Handler, even if they weren't
+	// requested yet. They must match Patterns. Other keyRings are listed
+	// after their first request. For example: "services/billing"
+	KeyRings []string
+	// TTL is the time after which a cached public key is derived again.
+	// Zero caches keys until Invalidate is called. For example: time.Hour
+	TTL time.Duration
+}
+
+// Directory derives and caches the public keys of keyRings. It is safe for
+// concurrent use.
+type Directory struct {
+	protocol Protocol
+	patterns []string
+	keyRings []string
+	ttl      time.Duration
+
+	mu    sync.RWMutex
+	cache map[string]entry
+}
+
+// entry is a cached public key.
+type entry struct {
+	publicKey ed25519.PublicKey
+	expires   time.Time
+}
+
+// New creates a Directory for config.
+func New(config *Config) (*Directory, error) {
+	if config.Protocol == nil {
+		return nil, errors.New("keydir: config.Protocol must not be nil")
+	}
+	if len(config.Patterns) == 0 {
+		return nil, errors.New("keydir: config.Patterns must not be empty")
+	}
+	for _, pattern := range config.Patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("keydir: invalid pattern %q: %w", pattern, err)
+		}
+	}
+	if config.TTL < 0 {
+		return nil, fmt.Errorf("keydir: config.TTL cannot be %s", config.TTL)
+	}
+
+	d := &Directory{
+		protocol: config.Protocol,
+		patterns: append([]string(nil), config.Patterns...),
+		keyRings: append([]string(nil), config.KeyRings...),
+		ttl:      config.TTL,
+		cache:    make(map[string]entry),
+	}
+	for _, keyRing := range d.keyRings {
+		if !d.Published(keyRing) {
+			return nil, fmt.Errorf("keydir: keyRing %q doesn't match a pattern", keyRing)
+		}
+	}
+	return d, nil
+}
+
+// Published reports whether keyRing matches a pattern of d.
+func (d *Directory) Published(keyRing string) bool {
+	for _, pattern := range d.patterns {
+		if ok, _ := path.Match(pattern, keyRing); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// PublicKey returns the public key of keyRing, from the cache or derived by
+// the Protocol. It fails with ErrNotPublished if keyRing doesn't match a
+// pattern.
+func (d *Directory) PublicKey(ctx context.Context, keyRing string) (ed25519.PublicKey, error) {
+	if !d.Published(keyRing) {
+		return nil, fmt.Errorf("%w: %q", ErrNotPublished, keyRing)
+	}
+
+	d.mu.RLock()
+	e, ok := d.cache[keyRing]
+	d.mu.RUnlock()
+	if ok && (e.expires.IsZero() || time.Now().Before(e.expires)) {
+		return e.publicKey, nil
+	}
+
+	publicKey, err := d.protocol.CreateSignKeyContext(ctx, keyRing)
+	if err != nil {
+		return nil, err
+	}
+
+	e = entry{publicKey: ed25519.PublicKey(publicKey)}
+	if d.ttl > 0 {
+		e.expires = time.Now().Add(d.ttl)
+	}
+	d.mu.Lock()
+	d.cache[keyRing] = e
+	d.mu.Unlock()
+	return e.publicKey, nil
+}
+
+// Invalidate removes all cached public keys. It must be called after the root
+// key of the KeyPool was rotated (see dvx.RotatingKeyPool), so the keys of
+// the new root are published.
+func (d *Directory) Invalidate() {
+	d.mu.Lock()
+	d.cache = make(map[string]entry)
+	d.mu.Unlock()
+}
+
+// listed returns the configured keyRings and the other cached keyRings in
+// order.
+func (d *Directory) listed() []string {
+	seen := make(map[string]bool, len(d.keyRings))
+	keyRings := append([]string(nil), d.keyRings...)
+	for _, keyRing := range keyRings {
+		seen[keyRing] = true
+	}
+
+	d.mu.RLock()
+	for keyRing := range d.cache {
+		if !seen[keyRing] {
+			keyRings = append(keyRings, keyRing)
+		}
+	}
+	d.mu.RUnlock()
+
+	sort.Strings(keyRings[len(d.keyRings):])
+	return keyRings
+}
@@ -0,0 +1,128 @@
+package keydir
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+)
+
+// countingProtocol counts the derived public keys.
+type countingProtocol struct {
+	*dvx.Protocol
+	calls int
+}
+
+func (c *countingProtocol) CreateSignKeyContext(ctx context.Context, keyRing string) ([]byte, error) {
+	c.calls++
+	return c.Protocol.CreateSignKeyContext(ctx, keyRing)
+}
+
+func newPool(t *testing.T) dvx.KeyPool {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	return dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, dvx.NopLogger)
+}
+
+func TestDirectory(t *testing.T) {
+	pool := newPool(t)
+	protocol := &countingProtocol{Protocol: dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: pool})}
+
+	_, err := New(&Config{Protocol: protocol})
+	assert.Error(t, err)
+	_, err = New(&Config{Protocol: protocol, Patterns: []string{"services/*"}, KeyRings: []string{"users/1"}})
+	assert.Error(t, err)
+
+	d, err := New(&Config{Protocol: protocol, Patterns: []string{"services/*"}, KeyRings: []string{"services/billing"}})
+	require.NoError(t, err)
+
+	expected, err := protocol.Protocol.CreateSignKey("services/api")
+	require.NoError(t, err)
+	publicKey, err := d.PublicKey(context.Background(), "services/api")
+	require.NoError(t, err)
+	assert.Equal(t, expected, []byte(publicKey))
+	_, err = d.PublicKey(context.Background(), "services/api")
+	require.NoError(t, err)
+	assert.Equal(t, 1, protocol.calls)
+
+	_, err = d.PublicKey(context.Background(), "users/1")
+	assert.True(t, errors.Is(err, ErrNotPublished))
+
+	set, err := d.JWKS(context.Background())
+	require.NoError(t, err)
+	require.Len(t, set.Keys, 2)
+	assert.Equal(t, "services/billing", set.Keys[0].KeyID)
+	assert.Equal(t, "services/api", set.Keys[1].KeyID)
+	assert.Equal(t, "OKP", set.Keys[1].KeyType)
+
+	// after a rotation of the root key, the keys of the new root are published
+	rootKey := make([]byte, 64)
+	_, err = io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	require.NoError(t, pool.(dvx.RotatingKeyPool).Rotate(rootKey))
+	d.Invalidate()
+	rotated, err := d.PublicKey(context.Background(), "services/api")
+	require.NoError(t, err)
+	assert.NotEqual(t, publicKey, rotated)
+}
+
+func TestDirectory_TTL(t *testing.T) {
+	protocol := &countingProtocol{Protocol: dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: newPool(t)})}
+	d, err := New(&Config{Protocol: protocol, Patterns: []string{"*"}, TTL: time.Millisecond})
+	require.NoError(t, err)
+
+	_, err = d.PublicKey(context.Background(), "a")
+	require.NoError(t, err)
+	time.Sleep(2 * time.Millisecond)
+	_, err = d.PublicKey(context.Background(), "a")
+	require.NoError(t, err)
+	assert.Equal(t, 2, protocol.calls)
+}
+
+func TestDirectory_Handler(t *testing.T) {
+	protocol := dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: newPool(t)})
+	d, err := New(&Config{Protocol: protocol, Patterns: []string{"services/*"}, KeyRings: []string{"services/api"}})
+	require.NoError(t, err)
+	server := httptest.NewServer(d.Handler())
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	var set JWKS
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&set))
+	require.Len(t, set.Keys, 1)
+
+	publicKey, err := protocol.CreateSignKey("services/api")
+	require.NoError(t, err)
+	assert.Equal(t, NewJWK("services/api", publicKey), set.Keys[0])
+
+	resp, err = http.Get(server.URL + "?kid=services/api")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var key JWK
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&key))
+	assert.Equal(t, set.Keys[0], key)
+
+	resp, err = http.Get(server.URL + "?kid=users/1")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Post(server.URL, "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
@@ -0,0 +1,105 @@
+package dvx
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+	"time"
+)
+
+// SignWithKey calculates a signature for message with a caller-provided
+// Ed25519 private key, instead of deriving one from a keyRing. privateKey is
+// either a 32 byte seed or a 64 byte private key (see ParsePrivateKey for
+// other encodings). The returned signature string has the same format as one
+// of Sign and can be verified with VerifyPK and the public key of privateKey.
+// SignWithKey doesn't use any KeyPool.
+func (p *Protocol) SignWithKey(privateKey []byte, message []byte) (signature string, rawSignature []byte, err error) {
+	defer p.done(OpSignWithKey, time.Now(), &err)
+
+	key, err := privateKeyFromBytes(privateKey)
+	if err != nil {
+		return "", nil, err
+	}
+
+	sig, err := primitiveOf(Version).Sign(key, message)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return Encode(Signed, sig), sig, nil
+}
+
+func privateKeyFromBytes(buf []byte) (ed25519.PrivateKey, error) {
+	switch len(buf) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(buf), nil
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(buf), nil
+	default:
+		return nil, errorf(ErrInvalidKey, "dvx: private key must be a %d byte seed or a %d byte private key", ed25519.SeedSize, ed25519.PrivateKeySize)
+	}
+}
+
+// ParsePrivateKey parses an Ed25519 private key generated outside of dvx, for
+// usage with SignWithKey. data is either a PEM block of type "PRIVATE KEY"
+// (PKCS #8, as written by `openssl genpkey -algorithm ed25519`), a DER
+// encoded PKCS #8 key, a 32 byte seed or a 64 byte private key.
+func ParsePrivateKey(data []byte) (ed25519.PrivateKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "PRIVATE KEY" {
+			return nil, errorf(ErrInvalidKey, "dvx: unsupported PEM block type %q. Expected \"PRIVATE KEY\"", block.Type)
+		}
+		data = block.Bytes
+	} else if key, err := privateKeyFromBytes(data); err == nil {
+		return key, nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(data)
+	if err != nil {
+		return nil, errorf(ErrInvalidKey, "dvx: unable to parse PKCS #8 private key: %v", err)
+	}
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, errorf(ErrInvalidKey, "dvx: private key is a %T, not an Ed25519 key", key)
+	}
+	return privateKey, nil
+}
+
+// ParsePublicKey parses an Ed25519 public key for usage with VerifyPK. data is
+// either a PEM block of type "PUBLIC KEY" (PKIX, as written by `openssl pkey
+// -pubout`), a DER encoded PKIX key or a 32 byte public key.
+func ParsePublicKey(data []byte) (ed25519.PublicKey, error) {
+	if block, _ := pem.Decode(data); block != nil {
+		if block.Type != "PUBLIC KEY" {
+			return nil, errorf(ErrInvalidKey, "dvx: unsupported PEM block type %q. Expected \"PUBLIC KEY\"", block.Type)
+		}
+		data = block.Bytes
+	} else if len(data) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(data), nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(data)
+	if err != nil {
+		return nil, errorf(ErrInvalidKey, "dvx: unable to parse PKIX public key: %v", err)
+	}
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, errorf(ErrInvalidKey, "dvx: public key is a %T, not an Ed25519 key", key)
+	}
+	return publicKey, nil
+}
+
+// MarshalPublicKey encodes an Ed25519 public key (e.g. of CreateSignKey) as
+// PEM block of type "PUBLIC KEY", which can be read by ParsePublicKey and
+// other tools like openssl.
+func MarshalPublicKey(publicKey []byte) ([]byte, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return nil, errorf(ErrInvalidKey, "dvx: public key must be %d bytes long", ed25519.PublicKeySize)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(ed25519.PublicKey(publicKey))
+	if err != nil {
+		return nil, errorf(ErrInvalidKey, "dvx: unable to marshal public key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), nil
+}
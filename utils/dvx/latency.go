@@ -0,0 +1,170 @@
+package dvx
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// Categories of a LatencyBudget, as passed to LatencyBudget.OnExceeded.
+const (
+	CategoryKDF       = "kdf"
+	CategoryAEAD      = "aead"
+	CategorySignature = "signature"
+	CategoryMAC       = "mac"
+)
+
+// LatencyBudget configures the logging of slow operations (see
+// Protocol.SetLatencyBudget), so a pathologically slow KeyPool (e.g. an
+// overloaded HSM) is visible at the crypto layer instead of only as timeouts
+// of downstream requests. A zero budget disables the respective category.
+type LatencyBudget struct {
+	// KDF is the budget of a single key derivation of a KeyPool. For example:
+	// 50 * time.Millisecond
+	KDF time.Duration
+	// AEAD is the budget of encryptions, decryptions and tokenizations,
+	// including their key derivations. For example: 100 * time.Millisecond
+	AEAD time.Duration
+	// Signature is the budget of operations that sign or verify signatures
+	// and create signing keys, including their key derivations. For example:
+	// 100 * time.Millisecond
+	Signature time.Duration
+	// MAC is the budget of MAC and TOTP operations, including their key
+	// derivations. For example: 100 * time.Millisecond
+	MAC time.Duration
+	// Abort cancels key derivations that exceed KDF, instead of only logging
+	// them. The context passed to KeyPool instances implementing
+	// ContextKeyPool gets a deadline, others can't be canceled. The operation
+	// fails with an error of class ErrKeyDerivation that wraps
+	// context.DeadlineExceeded.
+	Abort bool
+	// Logger receives a warning for every operation and key derivation that
+	// exceeded its budget, with the key "logger" set to "dvx_latency".
+	// Optional.
+	Logger Logger
+	// OnExceeded is called synchronously after an operation (e.g. OpEncrypt)
+	// or key derivation ("kdf32" or "kdf64") of category exceeded its budget,
+	// e.g. to record a metric. Optional.
+	OnExceeded func(category string, operation string, duration time.Duration, budget time.Duration)
+}
+
+// SetLatencyBudget enables the logging of operations and key derivations of p
+// that take longer than their budget. Stats reports their amount as
+// SlowOperations. SetLatencyBudget must be called before p is used.
+func (p *Protocol) SetLatencyBudget(budget *LatencyBudget) {
+	p.latency = &latency{
+		budget: *budget,
+		log:    named(budget.Logger, "dvx_latency"),
+	}
+}
+
+// done counts op in the statistics of p and checks its duration since start
+// against the latency budget. It is meant to be deferred with a pointer to a
+// named return value.
+func (p *Protocol) done(op string, start time.Time, err *error) {
+	p.stats.done(op, err)
+	p.latency.observe(op, start)
+}
+
+// latency checks the durations of operations against a LatencyBudget. A nil
+// *latency checks nothing.
+type latency struct {
+	budget LatencyBudget
+	log    Logger
+	// slow is updated atomically
+	slow uint64
+}
+
+// category returns the category of op and its budget.
+func (l *latency) category(op string) (category string, budget time.Duration) {
+	switch op {
+	case OpEncrypt, OpDecrypt, OpTokenize, OpDetokenize, OpSealSession, OpOpenSession:
+		return CategoryAEAD, l.budget.AEAD
+	case OpCreateSignKey, OpSign, OpSignWithKey, OpVerify, OpVerifyPK:
+		return CategorySignature, l.budget.Signature
+	case OpMAC, OpGenerateTOTP, OpVerifyTOTP, OpIssueOneTimeToken, OpRedeemOneTimeToken,
+		OpRevokeTOTP, OpVerifyTOTPRevocation, OpIssueWebAuthnChallenge, OpVerifyWebAuthnChallenge, OpDeriveID:
+		return CategoryMAC, l.budget.MAC
+	}
+	return "", 0
+}
+
+func (l *latency) observe(op string, start time.Time) {
+	if l == nil {
+		return
+	}
+
+	category, budget := l.category(op)
+	if duration := time.Since(start); budget > 0 && duration > budget {
+		l.exceeded("operation exceeded latency budget", category, op, duration, budget)
+	}
+}
+
+func (l *latency) exceeded(msg string, category string, op string, duration time.Duration, budget time.Duration) {
+	atomic.AddUint64(&l.slow, 1)
+	l.log.Warn(msg,
+		"category", category,
+		"operation", op,
+		"duration", duration,
+		"budget", budget)
+	if l.budget.OnExceeded != nil {
+		l.budget.OnExceeded(category, op, duration, budget)
+	}
+}
+
+// slowOperations returns the amount of operations and key derivations that
+// exceeded their budget.
+func (l *latency) slowOperations() uint64 {
+	if l == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&l.slow)
+}
+
+// kdfTimer measures a single key derivation.
+type kdfTimer struct {
+	l      *latency
+	start  time.Time
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// startKDF starts measuring a key derivation. The returned context must be
+// passed to the KeyPool. With LatencyBudget.Abort it is canceled after the
+// KDF budget.
+func (l *latency) startKDF(ctx context.Context) (context.Context, kdfTimer) {
+	if l == nil || l.budget.KDF <= 0 {
+		return ctx, kdfTimer{}
+	}
+
+	t := kdfTimer{l: l, start: time.Now(), ctx: ctx}
+	if l.budget.Abort {
+		ctx, t.cancel = context.WithTimeout(ctx, l.budget.KDF)
+	}
+	return ctx, t
+}
+
+// done checks the duration of the key derivation kdf (e.g. "kdf32") and
+// returns err, or an error describing the abort if the budget caused the
+// KeyPool to fail.
+func (t kdfTimer) done(kdf string, err error) error {
+	if t.l == nil {
+		return err
+	}
+	if t.cancel != nil {
+		t.cancel()
+	}
+
+	duration := time.Since(t.start)
+	if duration <= t.l.budget.KDF {
+		return err
+	}
+	t.l.exceeded("key derivation exceeded latency budget", CategoryKDF, kdf, duration, t.l.budget.KDF)
+
+	if err != nil && t.cancel != nil && t.ctx.Err() == nil {
+		// the deadline of the budget canceled the derivation, not the caller
+		return fmt.Errorf("dvx: key derivation exceeded latency budget of %s: %w", t.l.budget.KDF, context.DeadlineExceeded)
+	}
+	return err
+}
@@ -0,0 +1,8 @@
+module azoo.dev/utils/dvx/liblog
+
+go 1.16
+
+require (
+	github.com/harwoeck/liblog/contract v1.1.2
+	github.com/stretchr/testify v1.7.0
+)
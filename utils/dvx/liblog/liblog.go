@@ -0,0 +1,80 @@
+// Package liblog adapts a github.com/harwoeck/liblog/contract.Logger to the
+// Logger interface of azoo.dev/utils/dvx and its KeyPool implementations. It
+// has its own Go module, so only users of liblog depend on it.
+package liblog
+
+import (
+	"fmt"
+
+	"github.com/harwoeck/liblog/contract"
+)
+
+// Logger is the minimal logging interface used by dvx. It is copied from the
+// parent project azoo.dev/utils/dvx
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// Wrap returns a Logger that writes every entry to log. keysAndValues are
+// converted to contract.Field. The key "logger", which dvx uses for the name
+// of the component, is passed to log.Named instead. If log is nil, Wrap
+// returns nil, which dvx treats as a Logger that discards every entry.
+func Wrap(log contract.Logger) Logger {
+	if log == nil {
+		return nil
+	}
+	return &adapter{log: log}
+}
+
+type adapter struct {
+	log contract.Logger
+}
+
+func (a *adapter) Debug(msg string, keysAndValues ...interface{}) {
+	log, fields := a.convert(keysAndValues)
+	log.Debug(msg, fields...)
+}
+
+func (a *adapter) Info(msg string, keysAndValues ...interface{}) {
+	log, fields := a.convert(keysAndValues)
+	log.Info(msg, fields...)
+}
+
+func (a *adapter) Warn(msg string, keysAndValues ...interface{}) {
+	log, fields := a.convert(keysAndValues)
+	log.Warn(msg, fields...)
+}
+
+func (a *adapter) Error(msg string, keysAndValues ...interface{}) {
+	log, fields := a.convert(keysAndValues)
+	log.Error(msg, fields...)
+}
+
+// convert turns keysAndValues into fields. Keys that aren't strings are
+// formatted with fmt.Sprint and a missing last value is reported as
+// "!MISSING", like log/slog does.
+func (a *adapter) convert(keysAndValues []interface{}) (contract.Logger, []contract.Field) {
+	log := a.log
+	fields := make([]contract.Field, 0, (len(keysAndValues)+1)/2)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key, ok := keysAndValues[i].(string)
+		if !ok {
+			key = fmt.Sprint(keysAndValues[i])
+		}
+
+		var value interface{} = "!MISSING"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+
+		if name, ok := value.(string); ok && key == "logger" {
+			log = log.Named(name)
+			continue
+		}
+		fields = append(fields, contract.NewField(key, value))
+	}
+	return log, fields
+}
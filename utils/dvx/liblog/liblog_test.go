@@ -0,0 +1,34 @@
+package liblog
+
+import (
+	"testing"
+
+	"github.com/harwoeck/liblog/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	contract.Logger
+	name   string
+	msg    string
+	fields []contract.Field
+}
+
+func (r *recorder) Named(name string) contract.Logger {
+	r.name = name
+	return r
+}
+
+func (r *recorder) Info(msg string, fields ...contract.Field) {
+	r.msg = msg
+	r.fields = fields
+}
+
+func TestWrap(t *testing.T) {
+	r := &recorder{}
+	Wrap(r).Info("loaded key", "logger", "hsm.audit", "key_len", 32, "dangling")
+
+	assert.Equal(t, "hsm.audit", r.name)
+	assert.Equal(t, "loaded key", r.msg)
+	assert.Equal(t, []contract.Field{contract.NewField("key_len", 32), contract.NewField("dangling", "!MISSING")}, r.fields)
+}
@@ -0,0 +1,81 @@
+package dvx
+
+import (
+	"context"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// MACWriter computes the tag of a message written to it in parts, so large
+// files can be authenticated without reading them into memory. It is
+// created by Protocol.NewMACWriter. A MACWriter isn't safe for concurrent
+// use.
+type MACWriter struct {
+	m      *macHasher
+	tag    string
+	closed bool
+	// version is the version the MAC key was derived for.
+	version string
+}
+
+// NewMACWriter returns a MACWriter for keyRing. The tag of everything written
+// to it (see MACWriter.Sum) is the same tag as Protocol.MAC returns for the
+// whole message at once. Close must be called to release the MACWriter.
+func (p *Protocol) NewMACWriter(keyRing string) (*MACWriter, error) {
+	return p.NewMACWriterContext(context.Background(), keyRing)
+}
+
+// NewMACWriterContext is like NewMACWriter, but passes ctx to the KeyPool (see
+// ContextKeyPool).
+func (p *Protocol) NewMACWriterContext(ctx context.Context, keyRing string) (*MACWriter, error) {
+	keyRingBytes, err := p.keyRingInput(keyRing, OpMAC)
+	if err != nil {
+		return nil, err
+	}
+	version := p.writeVersion()
+	key, err := p.kdf64(ctx, keyRingBytes, version, purposeMAC)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+
+	if len(key) != blake2b.Size {
+		return nil, errorf(ErrInvalidKey, "%s: mac key must be %d bytes long", version, blake2b.Size)
+	}
+	m := macHashers.Get().(*macHasher)
+	m.reset(blake2b.Size, key)
+	return &MACWriter{m: m, version: version}, nil
+}
+
+// Write adds data to the message. It never returns an error, unless the
+// MACWriter is closed.
+func (w *MACWriter) Write(data []byte) (n int, err error) {
+	if w.closed {
+		return 0, errorf(ErrInvalidFormat, "dvx: write to closed MACWriter")
+	}
+	return w.m.h.Write(data)
+}
+
+// Sum returns the tag of the message written so far, encoded like the tags
+// of Protocol.MAC. It doesn't change the state, so more data can be written
+// afterwards. After Close it returns the tag of the whole message.
+func (w *MACWriter) Sum() string {
+	if w.closed {
+		return w.tag
+	}
+	var buf [blake2b.Size]byte
+	return EncodeVersion(w.version, Tagged, w.m.h.Sum(buf[:0]))
+}
+
+// Close computes the final tag (see Sum) and releases the MACWriter.
+func (w *MACWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.tag = w.Sum()
+	w.closed = true
+	wipe(w.m.state[:])
+	macHashers.Put(w.m)
+	w.m = nil
+	return nil
+}
@@ -0,0 +1,36 @@
+//go:build dvxnoncecheck
+// +build dvxnoncecheck
+
+package dvx
+
+import (
+	"fmt"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// nonceCheck records every random nonce of Encrypt (and the other users of
+// seal, e.g. EncryptNotBefore) in this process. It is only built with the
+// build tag dvxnoncecheck, e.g. "go test -tags dvxnoncecheck ./...", as it
+// keeps every nonce in memory.
+var nonceCheck = struct {
+	sync.Mutex
+	seen map[[chacha20poly1305.NonceSizeX]byte]string
+}{seen: make(map[[chacha20poly1305.NonceSizeX]byte]string)}
+
+// checkNonce panics if nonce was already used by an earlier call in this
+// process. Random 24 byte nonces never repeat, unless the CSPRNG is broken
+// (e.g. a container without entropy or a replaced rand.Reader), which must
+// not go unnoticed.
+func checkNonce(version string, nonce []byte) {
+	var key [chacha20poly1305.NonceSizeX]byte
+	copy(key[:], nonce)
+
+	nonceCheck.Lock()
+	defer nonceCheck.Unlock()
+	if first, ok := nonceCheck.seen[key]; ok {
+		panic(fmt.Sprintf("dvx: nonce %x of %s was already used by %s. The CSPRNG of this process is broken", nonce, version, first))
+	}
+	nonceCheck.seen[key] = version
+}
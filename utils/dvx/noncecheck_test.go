@@ -0,0 +1,33 @@
+//go:build dvxnoncecheck
+// +build dvxnoncecheck
+
+package dvx
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func TestCheckNonce(t *testing.T) {
+	key := make([]byte, 32)
+	_, err := DV1{}.Encrypt(key, []byte("data"))
+	require.NoError(t, err)
+
+	// a broken CSPRNG returns the same nonce twice
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+	_, err = io.ReadFull(rand.Reader, nonce)
+	require.NoError(t, err)
+	reader := rand.Reader
+	defer func() { rand.Reader = reader }()
+	rand.Reader = bytes.NewReader(append(nonce, nonce...))
+
+	_, err = DV1{}.Encrypt(key, []byte("data"))
+	require.NoError(t, err)
+	assert.Panics(t, func() { _, _ = DV2{}.Encrypt(key, []byte("data")) })
+}
@@ -0,0 +1,163 @@
+package dvx
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"time"
+)
+
+const (
+	// oneTimeIDSize is the size of the random redemption-id of one-time
+	// tokens.
+	oneTimeIDSize = 16
+	// oneTimeTagSize is the size of the MAC of one-time tokens.
+	oneTimeTagSize = 32
+	// oneTimeHeaderSize is the size of the expiry and redemption-id in front
+	// of the subject.
+	oneTimeHeaderSize = 8 + oneTimeIDSize
+)
+
+// OneTimeToken is a one-time token redeemed by Protocol.RedeemOneTimeToken.
+type OneTimeToken struct {
+	// Subject is the subject the token was issued for. For example: a user-id
+	// or email address
+	Subject string
+	// RedemptionID identifies the token. It is random and unique for every
+	// issued token. Callers must persist it (e.g. with a unique constraint)
+	// at least until ExpiresAt and reject tokens whose RedemptionID was
+	// already redeemed, as RedeemOneTimeToken itself is stateless.
+	RedemptionID string
+	// ExpiresAt is the time after which the token isn't redeemable anymore,
+	// with a resolution of seconds.
+	ExpiresAt time.Time
+}
+
+// IssueOneTimeToken derives a secret key `sk` using the keyRing and issues a
+// compact token for subject (e.g. for magic links or email verification),
+// that RedeemOneTimeToken accepts until ttl elapsed. The token embeds the
+// expiry, a random redemption-id and subject, which are authenticated with a
+// keyed MAC, but not encrypted: subject is readable by anyone holding the
+// token.
+func (p *Protocol) IssueOneTimeToken(keyRing string, subject string, ttl time.Duration) (token string, err error) {
+	return p.IssueOneTimeTokenContext(context.Background(), keyRing, subject, ttl)
+}
+
+// IssueOneTimeTokenContext is like IssueOneTimeToken, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) IssueOneTimeTokenContext(ctx context.Context, keyRing string, subject string, ttl time.Duration) (token string, err error) {
+	defer p.done(OpIssueOneTimeToken, time.Now(), &err)
+
+	if err = p.checkEntropy(); err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		return "", errorf(ErrInvalidFormat, "dvx: ttl of one-time token must be positive")
+	}
+	now, err := p.now(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpIssueOneTimeToken)
+	if err != nil {
+		return "", err
+	}
+	key, err := p.kdf64(ctx, keyRingBytes, featureVersion, purposeOneTime)
+	if err != nil {
+		return "", err
+	}
+
+	data := make([]byte, oneTimeHeaderSize, oneTimeHeaderSize+len(subject)+oneTimeTagSize)
+	binary.BigEndian.PutUint64(data, uint64(now.Add(ttl).Unix()))
+	if _, err = io.ReadFull(rand.Reader, data[8:oneTimeHeaderSize]); err != nil {
+		return "", errorf(ErrRandomness, "%s: failed to read random %d bytes for redemption-id: %v", featureVersion, oneTimeIDSize, err)
+	}
+	data = append(data, subject...)
+
+	data, err = appendOneTimeTag(data, featureVersion, key)
+	if err != nil {
+		return "", err
+	}
+	return EncodeVersion(featureVersion, OneTime, data), nil
+}
+
+// RedeemOneTimeToken derives a secret key `sk` using the keyRing and verifies
+// token. It fails with ErrAuthentication if token wasn't issued for keyRing or
+// was changed, and with ErrExpired once the Clock of p reached its expiry.
+//
+// RedeemOneTimeToken doesn't remember redeemed tokens: every valid token is
+// accepted until it expires. Single use must be enforced by the caller with
+// the returned RedemptionID.
+func (p *Protocol) RedeemOneTimeToken(keyRing string, token string) (*OneTimeToken, error) {
+	return p.RedeemOneTimeTokenContext(context.Background(), keyRing, token)
+}
+
+// RedeemOneTimeTokenContext is like RedeemOneTimeToken, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) RedeemOneTimeTokenContext(ctx context.Context, keyRing string, token string) (ott *OneTimeToken, err error) {
+	defer p.done(OpRedeemOneTimeToken, time.Now(), &err)
+
+	v, data, err := DecodeExpect(token, OneTime)
+	if err != nil {
+		return nil, err
+	}
+	if v == "dv1" {
+		return nil, errorf(ErrInvalidFormat, "dvx: dv1 doesn't support one-time tokens")
+	}
+	if len(data) < oneTimeHeaderSize+oneTimeTagSize {
+		return nil, errorf(ErrInvalidFormat, "%s: one-time token shorter (%d) than needed for expiry, redemption-id and tag (%d)", v, len(data), oneTimeHeaderSize+oneTimeTagSize)
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpRedeemOneTimeToken)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.kdf64(ctx, keyRingBytes, v, purposeOneTime)
+	if err != nil {
+		return nil, err
+	}
+
+	body := data[:len(data)-oneTimeTagSize]
+	expected, err := appendOneTimeTag(append([]byte(nil), body...), v, key)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expected, data) != 1 {
+		return nil, errorf(ErrAuthentication, "%s: one-time token verification failed", v)
+	}
+
+	// the expiry is only checked after the tag, so unauthenticated tokens
+	// never reveal whether they are expired
+	expiresAt := time.Unix(int64(binary.BigEndian.Uint64(body)), 0)
+	now, err := p.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(expiresAt) {
+		return nil, errorf(ErrExpired, "dvx: one-time token expired at %s", expiresAt.UTC().Format(time.RFC3339))
+	}
+
+	return &OneTimeToken{
+		Subject:      string(body[oneTimeHeaderSize:]),
+		RedemptionID: base64.RawURLEncoding.EncodeToString(body[8:oneTimeHeaderSize]),
+		ExpiresAt:    expiresAt,
+	}, nil
+}
+
+// appendOneTimeTag appends the MAC of a one-time token to data. The MAC is
+// calculated over the version and TypePrefix followed by data, so tags of
+// other TypePrefixes are never valid one-time tokens.
+func appendOneTimeTag(data []byte, version string, key []byte) ([]byte, error) {
+	msg := make([]byte, 0, len(version)+len(OneTime)+len(data))
+	msg = append(append(append(msg, version...), OneTime...), data...)
+
+	tag, err := primitiveOf(version).MAC256(key, msg)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, tag...), nil
+}
@@ -0,0 +1,104 @@
+package dvx
+
+import (
+	"crypto/rand"
+	"io"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// passwordSaltSize is the size of the random salt of password-encrypted
+// ciphers.
+const passwordSaltSize = MinSaltSize
+
+// EncryptWithPassword encrypts data with a key derived from password, for
+// content held by users (e.g. exports), that must be decryptable without a
+// KeyPool. It uses the DefaultArgon2Params (see EncryptWithPasswordParams).
+func EncryptWithPassword(password []byte, data []byte) (ciphertext string, err error) {
+	return EncryptWithPasswordParams(password, data, DefaultArgon2Params)
+}
+
+// EncryptWithPasswordParams is like EncryptWithPassword, but derives the key
+// with params. The key is derived with the KDF512 of the current version
+// (Argon2id) and a random salt. Both the parameters and the salt are stored
+// in the cipher and authenticated with it:
+//
+//	<version>.penc.<params || salt || nonce || encrypted>
+//
+// The derivation is deliberately slow and memory-hard, so it shouldn't be
+// called in hot paths.
+func EncryptWithPasswordParams(password []byte, data []byte, params Argon2Params) (ciphertext string, err error) {
+	if len(password) == 0 {
+		return "", errorf(ErrInvalidKey, "%s: password must not be empty", featureVersion)
+	}
+
+	header := params.encode(make([]byte, 0, argon2ParamsSize+passwordSaltSize+CipherOverhead+len(data)))
+	salt := header[argon2ParamsSize : argon2ParamsSize+passwordSaltSize]
+	if _, err = io.ReadFull(rand.Reader, salt); err != nil {
+		return "", errorf(ErrRandomness, "%s: failed to read random %d bytes for salt: %v", featureVersion, passwordSaltSize, err)
+	}
+	header = header[:argon2ParamsSize+passwordSaltSize]
+
+	key, err := passwordKey(featureVersion, password, salt, params)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(key)
+
+	cipher, err := sealTo(header, featureVersion, key, data, passwordAAD(header))
+	if err != nil {
+		return "", err
+	}
+	return EncodeVersion(featureVersion, PasswordEncrypted, cipher), nil
+}
+
+// DecryptWithPassword decrypts a cipher of EncryptWithPassword with the same
+// password, using the parameters stored in the cipher. A wrong password fails
+// with ErrAuthentication. Ciphers whose parameters exceed 8 passes, 256 MiB
+// of memory or 16 threads are rejected with ErrInvalidFormat before the
+// derivation, so forged ciphers can't exhaust the memory or CPU of the caller.
+func DecryptWithPassword(password []byte, ciphertext string) (data []byte, err error) {
+	version, cipher, err := DecodeExpect(ciphertext, PasswordEncrypted)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipher) < argon2ParamsSize+passwordSaltSize {
+		return nil, errorf(ErrInvalidFormat, "%s: cipher shorter (%d) than needed for parameters and salt (%d)", version, len(cipher), argon2ParamsSize+passwordSaltSize)
+	}
+	params, err := decodeArgon2Params(cipher)
+	if err != nil {
+		return nil, err
+	}
+	header := cipher[:argon2ParamsSize+passwordSaltSize]
+
+	key, err := passwordKey(version, password, header[argon2ParamsSize:], params)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+
+	return open(version, key, cipher[len(header):], passwordAAD(header))
+}
+
+// passwordKey derives the encryption key of password and salt with the
+// KDF512 of version.
+func passwordKey(version string, password []byte, salt []byte, params Argon2Params) ([]byte, error) {
+	kdf, ok := primitiveOf(version).(interface {
+		KDF512WithParams(password []byte, salt []byte, params Argon2Params) ([]byte, error)
+	})
+	if !ok {
+		return nil, errorf(ErrInvalidFormat, "%s: password-based encryption isn't supported", version)
+	}
+	key, err := kdf.KDF512WithParams(password, salt, params)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+	return append([]byte(nil), key[:chacha20poly1305.KeySize]...), nil
+}
+
+// passwordAAD returns the footer of seal for password-encrypted ciphers, so
+// the TypePrefix, parameters and salt are authenticated.
+func passwordAAD(header []byte) []byte {
+	return append([]byte(PasswordEncrypted), header...)
+}
@@ -0,0 +1,206 @@
+package dvx
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// KeyRingPolicy validates keyRings before a Protocol derives keys for them
+// (see Protocol.SetKeyRingPolicy). Organizations use it to reject free-form
+// keyRings, that make rotation and authorization of keys impossible later.
+type KeyRingPolicy interface {
+	// Validate returns an error if keyRing violates the policy. Protocol
+	// assigns it to the class ErrKeyRingPolicy. keyRing is the keyRing keys
+	// are derived from: keyRings of the form "label:base64" are decoded first.
+	Validate(keyRing string) error
+}
+
+// KeyRingPolicyFunc adapts a function to a KeyRingPolicy.
+type KeyRingPolicyFunc func(keyRing string) error
+
+func (f KeyRingPolicyFunc) Validate(keyRing string) error {
+	return f(keyRing)
+}
+
+// SetKeyRingPolicy sets the KeyRingPolicy of p. Every operation with a keyRing
+// (including Decrypt and Verify) fails with ErrKeyRingPolicy if the keyRing
+// violates policy, before a key is derived. Multiple policies are combined
+// with AllKeyRingPolicies. SetKeyRingPolicy must be called before p is used.
+func (p *Protocol) SetKeyRingPolicy(policy KeyRingPolicy) {
+	p.policy = policy
+}
+
+// RequireEncryptionContext requires an encryption context for ciphers of
+// keyRings starting with one of prefixes, so every cipher is bound to the
+// record it belongs to (e.g. its table and primary key) and can't be moved to
+// another one. The context is the authenticated footer of EncryptWithFooter,
+// which fails with ErrKeyRingPolicy for these keyRings if the footer is empty.
+// Encrypt, EncryptNotBefore and EncryptCOSE can't bind a context and always
+// fail for them. Decryption isn't affected, so existing ciphers stay
+// readable. Like a KeyRingPolicy, prefixes are matched against the decoded
+// keyRing of "label:base64" keyRings. RequireEncryptionContext must be called
+// before p is used. For example: "tenants/", "payments/"
+func (p *Protocol) RequireEncryptionContext(prefixes ...string) {
+	p.contextPrefixes = prefixes
+}
+
+// checkEncryptionContext fails with ErrKeyRingPolicy if keyRing requires an
+// encryption context (see RequireEncryptionContext), but footer is empty.
+func (p *Protocol) checkEncryptionContext(keyRing string, footer []byte) error {
+	if len(footer) > 0 {
+		return nil
+	}
+	keyRingBytes := keyRingToBytes(keyRing)
+	for _, prefix := range p.contextPrefixes {
+		if bytes.HasPrefix(keyRingBytes, []byte(prefix)) {
+			return errorf(ErrKeyRingPolicy, "dvx: keyRing %q requires an encryption context (see RequireEncryptionContext)", keyRing)
+		}
+	}
+	return nil
+}
+
+// KeyUsagePolicy maps keyRing prefixes to the operations (see OpEncrypt,
+// OpMAC, ...) allowed for keyRings starting with them, so a keyRing
+// designated for one purpose can't be used to derive keys of another one (see
+// Protocol.SetKeyUsagePolicy). The longest matching prefix applies, keyRings
+// without a matching prefix allow all operations. Like a KeyRingPolicy,
+// prefixes are matched against the decoded keyRing of "label:base64"
+// keyRings. For example:
+//   dvx.KeyUsagePolicy{
+//     "audit/":          {dvx.OpMAC},
+//     "users/":          {dvx.OpEncrypt, dvx.OpDecrypt},
+//     "users/webhooks/": {dvx.OpSign, dvx.OpCreateSignKey},
+//   }
+type KeyUsagePolicy map[string][]string
+
+// keyUsageRule is the allowed operations of a prefix of a KeyUsagePolicy.
+type keyUsageRule struct {
+	prefix string
+	ops    map[string]bool
+}
+
+// SetKeyUsagePolicy sets the KeyUsagePolicy of p. Every operation with a
+// keyRing fails with ErrKeyRingPolicy if it isn't allowed for the keyRing,
+// before a key is derived. Operations creating readers and writers (e.g.
+// NewFileWriter, NewMACWriter) count as the operation of their data (e.g.
+// OpEncrypt, OpMAC), ratchets require OpEncrypt or OpDecrypt and
+// ExportVerificationBundle requires OpCreateSignKey for the exported keyRings.
+// It fails for unknown operations. SetKeyUsagePolicy must be called before p
+// is used.
+func (p *Protocol) SetKeyUsagePolicy(policy KeyUsagePolicy) error {
+	rules := make([]keyUsageRule, 0, len(policy))
+	for prefix, ops := range policy {
+		rule := keyUsageRule{prefix: prefix, ops: make(map[string]bool, len(ops))}
+		for _, op := range ops {
+			if !knownOperation(op) {
+				return fmt.Errorf("dvx: unknown operation %q for keyRing prefix %q", op, prefix)
+			}
+			rule.ops[op] = true
+		}
+		rules = append(rules, rule)
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		return len(rules[i].prefix) > len(rules[j].prefix)
+	})
+	p.usagePolicy = rules
+	return nil
+}
+
+// knownOperation reports whether op is one of the operations of Stats.
+func knownOperation(op string) bool {
+	for _, known := range statsOperations {
+		if op == known {
+			return true
+		}
+	}
+	return false
+}
+
+// keyRingInput returns the bytes of keyRing (see keyRingToBytes), after
+// validating them against the KeyRingPolicy of p and checking that the
+// KeyUsagePolicy of p allows one of ops for keyRing.
+func (p *Protocol) keyRingInput(keyRing string, ops ...string) ([]byte, error) {
+	keyRingBytes := keyRingToBytes(keyRing)
+	if p.policy != nil {
+		if err := p.policy.Validate(string(keyRingBytes)); err != nil {
+			return nil, &classError{
+				class: ErrKeyRingPolicy,
+				msg:   "dvx: keyRing rejected by policy: " + err.Error(),
+				cause: err,
+			}
+		}
+	}
+	for _, rule := range p.usagePolicy {
+		if !bytes.HasPrefix(keyRingBytes, []byte(rule.prefix)) {
+			continue
+		}
+		for _, op := range ops {
+			if rule.ops[op] {
+				return keyRingBytes, nil
+			}
+		}
+		return nil, errorf(ErrKeyRingPolicy, "dvx: %s isn't allowed for keyRing %q by the KeyUsagePolicy (prefix %q)", strings.Join(ops, "/"), keyRing, rule.prefix)
+	}
+	return keyRingBytes, nil
+}
+
+// AllKeyRingPolicies returns a KeyRingPolicy that requires keyRings to satisfy
+// all policies. It returns the error of the first violated one.
+func AllKeyRingPolicies(policies ...KeyRingPolicy) KeyRingPolicy {
+	return KeyRingPolicyFunc(func(keyRing string) error {
+		for _, policy := range policies {
+			if err := policy.Validate(keyRing); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// MaxKeyRingLength returns a KeyRingPolicy that rejects empty keyRings and
+// keyRings longer than max bytes.
+func MaxKeyRingLength(max int) KeyRingPolicy {
+	return KeyRingPolicyFunc(func(keyRing string) error {
+		if keyRing == "" {
+			return errors.New("keyRing is empty")
+		}
+		if len(keyRing) > max {
+			return fmt.Errorf("keyRing is longer than %d bytes", max)
+		}
+		return nil
+	})
+}
+
+// KeyRingPrefixes returns a KeyRingPolicy that only accepts keyRings starting
+// with one of prefixes. For example: "users/", "payments/"
+func KeyRingPrefixes(prefixes ...string) KeyRingPolicy {
+	return KeyRingPolicyFunc(func(keyRing string) error {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(keyRing, prefix) {
+				return nil
+			}
+		}
+		return fmt.Errorf("keyRing %q doesn't start with an allowed prefix (%s)", keyRing, strings.Join(prefixes, ", "))
+	})
+}
+
+// KeyRingTenantSegment returns a KeyRingPolicy that requires a tenant segment
+// in keyRings: the segment at index (zero-based) of the keyRing split by "/"
+// must be present, non-empty and accepted by valid. A nil valid accepts
+// every non-empty segment. For example, KeyRingTenantSegment(1, nil) accepts
+// "users/tenant-a/42", but rejects "users" and "users//42".
+func KeyRingTenantSegment(index int, valid func(tenant string) bool) KeyRingPolicy {
+	return KeyRingPolicyFunc(func(keyRing string) error {
+		segments := strings.Split(keyRing, "/")
+		if index >= len(segments) || segments[index] == "" {
+			return fmt.Errorf("keyRing %q has no tenant segment at index %d", keyRing, index)
+		}
+		if valid != nil && !valid(segments[index]) {
+			return fmt.Errorf("keyRing %q has an invalid tenant segment %q", keyRing, segments[index])
+		}
+		return nil
+	})
+}
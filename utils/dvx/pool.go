@@ -1,9 +1,9 @@
 package dvx
 
 import (
-	"encoding/base64"
-
-	logger "github.com/harwoeck/liblog/contract"
+	"context"
+	"errors"
+	"sync"
 )
 
 // KeyPool is an interface for a key derivation loader.
@@ -18,30 +18,96 @@ type KeyPool interface {
 	Close() error
 }
 
+// ContextKeyPool is an optional interface for KeyPool implementations that
+// accept a context.Context. The Context methods of Protocol pass their ctx to
+// it, so deadlines and request scoped values (e.g. tracing information) reach
+// the KeyPool.
+type ContextKeyPool interface {
+	KeyPool
+	// KDF32Context is like KDF32, but with a context.Context.
+	KDF32Context(ctx context.Context, keyRing []byte) (key []byte, err error)
+	// KDF64Context is like KDF64, but with a context.Context.
+	KDF64Context(ctx context.Context, keyRing []byte) (key []byte, err error)
+}
+
+// RotatingKeyPool is an optional interface for KeyPool implementations whose
+// root key can be replaced at runtime. The KeyPool returned by
+// WrapDVXAsKeyPool implements it.
+type RotatingKeyPool interface {
+	KeyPool
+	// Rotate replaces the root key with newRoot. Derivations running
+	// concurrently finish with the previous root key, all later ones use
+	// newRoot. KeyPool instances that cache derived keys (e.g. tearc) on top
+	// of it must be recreated afterwards.
+	Rotate(newRoot []byte) error
+}
+
+// GenerationKeyPool is an optional interface for KeyPool implementations that
+// count the root keys they used. The KeyPool returned by WrapDVXAsKeyPool
+// implements it and tearc passes it through.
+type GenerationKeyPool interface {
+	KeyPool
+	// Generation returns the generation of the current root key. It starts
+	// at 1 and is incremented by every rotation (see RotatingKeyPool). 0
+	// means the generation is unknown.
+	Generation() uint64
+}
+
+// InvalidatingKeyPool is an optional interface for KeyPool implementations
+// that cache keys (e.g. tearc). Protocol.Invalidate uses it to remove the
+// keys of a keyRing before they expire.
+type InvalidatingKeyPool interface {
+	KeyPool
+	// Invalidate removes the cached keys (of all sizes) of the KeyPool input
+	// keyRing and returns their amount.
+	Invalidate(keyRing []byte) int
+}
+
 // WrapDVXAsKeyPool provides a KeyPool implementation by using the
 // Primitive.MAC256 and Primitive.MAC512 functions as key-derivation-functions.
 // The passed rootKey is used as key for the MAC-constructions. A passed keyRing
-// is used a message during derivation.
-func WrapDVXAsKeyPool(dvx Primitive, rootKey []byte, log logger.Logger) KeyPool {
-	return &dvxWrapper{dvx, rootKey, log.Named("dvx_keypool").Named("audit")}
+// is used a message during derivation. If log is nil nothing is logged.
+//
+// rootKey is copied into a SecureBuffer, so the caller can wipe it afterwards.
+// The copy is destroyed on Close and on Rotate (see RotatingKeyPool).
+func WrapDVXAsKeyPool(dvx Primitive, rootKey []byte, log Logger) KeyPool {
+	return &dvxWrapper{
+		dvx:        dvx,
+		rootKey:    secureCopy(rootKey),
+		auditLog:   named(log, "dvx_keypool.audit"),
+		generation: 1,
+	}
 }
 
 type dvxWrapper struct {
 	dvx      Primitive
-	rootKey  []byte
-	auditLog logger.Logger
+	auditLog Logger
+
+	// mu guards rootKey and generation. Derivations hold a read lock, so
+	// Rotate and Close never wipe a key that is still in use.
+	mu         sync.RWMutex
+	rootKey    *SecureBuffer
+	generation uint64
+	closed     bool
 }
 
 func (d *dvxWrapper) kdf(keyRing []byte, mac func(key []byte, data []byte) (tag []byte, err error)) (key []byte, err error) {
-	key, err = mac(d.rootKey, keyRing)
+	d.mu.RLock()
+	if d.closed {
+		d.mu.RUnlock()
+		return nil, errors.New("dvx: KeyPool is closed")
+	}
+	key, err = mac(d.rootKey.Bytes(), keyRing)
+	d.mu.RUnlock()
 	if err != nil {
 		return nil, err
 	}
 
+	label, keyRingBytes := splitKDFInput(keyRing)
 	d.auditLog.Info("loaded key",
-		logger.NewField("key_len", len(key)),
-		logger.NewField("key_ring", base64.RawStdEncoding.EncodeToString(keyRing)),
-		logger.NewField("key_ring_str", string(keyRing)))
+		"key_len", len(key),
+		"label", label,
+		"key_ring_fingerprint", fingerprint(keyRingBytes))
 	return
 }
 
@@ -53,7 +119,44 @@ func (d *dvxWrapper) KDF64(keyRing []byte) (key []byte, err error) {
 	return d.kdf(keyRing, d.dvx.MAC512)
 }
 
+func (d *dvxWrapper) Rotate(newRoot []byte) error {
+	// the Primitive rejects keys it can't use, before the current root key is
+	// replaced
+	if _, err := d.dvx.MAC256(newRoot, nil); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return errors.New("dvx: KeyPool is closed")
+	}
+
+	d.rootKey.Destroy()
+	d.rootKey = secureCopy(newRoot)
+	d.generation++
+	d.auditLog.Info("rotated root key", "generation", d.generation)
+	return nil
+}
+
+func (d *dvxWrapper) Generation() uint64 {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.generation
+}
+
 func (d *dvxWrapper) Close() error {
-	d.rootKey = nil
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.rootKey.Destroy()
+	d.closed = true
 	return nil
 }
+
+// wipe overwrites buf with zeros.
+func wipe(buf []byte) {
+	for i := range buf {
+		buf[i] = 0
+	}
+}
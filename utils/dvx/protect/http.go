@@ -0,0 +1,166 @@
+package protect
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Handler protects the JSON payloads of next. Requests to configured routes
+// (URL paths) with request fields must have a JSON body, whose request
+// fields are dvx ciphertexts, otherwise they are rejected with status 400.
+// Responses of routes with response fields are buffered and their fields
+// encrypted. A response that isn't JSON is replaced with status 500, so
+// plaintext never leaves the handler. Modified payloads are encoded again,
+// which may change the order of object keys.
+func (p *Protector) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r, ok := p.routes[req.URL.Path]
+		if !ok {
+			next.ServeHTTP(w, req)
+			return
+		}
+		ctx := req.Context()
+
+		if len(r.request) > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := io.ReadAll(req.Body)
+			_ = req.Body.Close()
+			if err != nil {
+				http.Error(w, "protect: unable to read request", http.StatusBadRequest)
+				return
+			}
+			body, err = transformJSON(body, r.request, p.decrypter(ctx, r.keyRing))
+			if err != nil {
+				http.Error(w, fmt.Sprintf("protect: invalid request: %v", err), http.StatusBadRequest)
+				return
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+			req.ContentLength = int64(len(body))
+			req.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		}
+
+		if len(r.response) == 0 {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		buf := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(buf, req)
+		p.writeResponse(ctx, w, buf, r)
+	})
+}
+
+func (p *Protector) writeResponse(ctx context.Context, w http.ResponseWriter, buf *bufferedResponse, r route) {
+	body := buf.body.Bytes()
+	if len(body) > 0 {
+		var err error
+		body, err = transformJSON(body, r.response, p.encrypter(ctx, r.keyRing))
+		if err != nil {
+			http.Error(w, "protect: unable to protect response", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	for key, values := range buf.header {
+		w.Header()[key] = values
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+	w.WriteHeader(buf.status)
+	_, _ = w.Write(body)
+}
+
+// bufferedResponse is a http.ResponseWriter that keeps the response in
+// memory.
+type bufferedResponse struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if !b.wroteHeader {
+		b.status = status
+		b.wroteHeader = true
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.wroteHeader = true
+	return b.body.Write(p)
+}
+
+// transformJSON applies fn to all fields at paths in the JSON document data.
+// Protected fields must be strings.
+func transformJSON(data []byte, paths [][]string, fn transformFunc) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("body isn't valid JSON: %w", err)
+	}
+
+	for _, path := range paths {
+		var err error
+		if doc, err = transformValue(doc, path, fn); err != nil {
+			return nil, fmt.Errorf("field %s: %w", strings.Join(path, "."), err)
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+func transformValue(v interface{}, path []string, fn transformFunc) (interface{}, error) {
+	switch x := v.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		for i := range x {
+			var err error
+			if x[i], err = transformValue(x[i], path, fn); err != nil {
+				return nil, err
+			}
+		}
+		return x, nil
+	case map[string]interface{}:
+		if len(path) == 0 {
+			break
+		}
+		child, ok := x[path[0]]
+		if !ok {
+			return x, nil
+		}
+		child, err := transformValue(child, path[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		x[path[0]] = child
+		return x, nil
+	case string:
+		if len(path) > 0 {
+			return x, nil
+		}
+		if x == "" {
+			return x, nil
+		}
+		value, err := fn([]byte(x))
+		if err != nil {
+			return nil, err
+		}
+		return string(value), nil
+	}
+
+	if len(path) == 0 {
+		return nil, fmt.Errorf("unsupported type %T (supported: string)", v)
+	}
+	return v, nil
+}
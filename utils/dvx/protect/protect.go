@@ -0,0 +1,237 @@
+// Package protect enforces that sensitive fields never cross a service
+// boundary in plaintext. Per route, request fields arrive encrypted (dvx
+// ciphertexts) and are decrypted before the handler runs, while response
+// fields are encrypted before they leave the service. Every route has its own
+// keyRing.
+//
+// Protector.Handler protects JSON payloads of net/http handlers.
+// Protector.Call protects request and response messages (structs) of RPC
+// frameworks and can be adapted to their interceptors, e.g. for Twirp:
+//
+//   func(next twirp.Method) twirp.Method {
+//     return func(ctx context.Context, req interface{}) (interface{}, error) {
+//       method, _ := twirp.MethodName(ctx)
+//       return protector.Call(ctx, method, req, next)
+//     }
+//   }
+//
+// or for gRPC:
+//
+//   func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+//     return protector.Call(ctx, info.FullMethod, req, handler)
+//   }
+package protect
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"azoo.dev/utils/dvx"
+)
+
+// Config configures the protected routes.
+type Config struct {
+	// Protocol encrypts and decrypts the protected fields.
+	Protocol dvx.Crypto
+	// Routes maps routes to their protected fields. Routes are URL paths for
+	// Handler and method names for Call. Requests to other routes aren't
+	// modified. For example: "/invoices"
+	Routes map[string]Route
+}
+
+// Route configures the protected fields of a route. Fields are paths of field
+// names separated by dots. Every path segment matches either a JSON object
+// key, a Go struct field name or the name in its json tag. Paths through
+// arrays (slices) apply to every element. For example: "customer.iban"
+type Route struct {
+	// KeyRing is the keyRing of all fields of the route. For example:
+	// "billing/invoices"
+	KeyRing string
+	// Request are the fields, that are decrypted before the handler is
+	// called. Requests with plaintext values in these fields are rejected.
+	Request []string
+	// Response are the fields, that are encrypted before the response is
+	// returned.
+	Response []string
+}
+
+// Protector protects the fields of the configured routes.
+type Protector struct {
+	protocol dvx.Crypto
+	routes   map[string]route
+}
+
+// route is a Route with split field paths.
+type route struct {
+	keyRing  string
+	request  [][]string
+	response [][]string
+}
+
+// New creates a Protector for config.
+func New(config *Config) (*Protector, error) {
+	if config.Protocol == nil {
+		return nil, errors.New("protect: config.Protocol must not be nil")
+	}
+
+	p := &Protector{protocol: config.Protocol, routes: make(map[string]route, len(config.Routes))}
+	for name, r := range config.Routes {
+		if r.KeyRing == "" {
+			return nil, fmt.Errorf("protect: route %q: KeyRing is required", name)
+		}
+
+		split := func(fields []string) ([][]string, error) {
+			paths := make([][]string, len(fields))
+			for i, field := range fields {
+				paths[i] = strings.Split(field, ".")
+				for _, segment := range paths[i] {
+					if segment == "" {
+						return nil, fmt.Errorf("protect: route %q: invalid field %q", name, field)
+					}
+				}
+			}
+			return paths, nil
+		}
+
+		var err error
+		rr := route{keyRing: r.KeyRing}
+		if rr.request, err = split(r.Request); err != nil {
+			return nil, err
+		}
+		if rr.response, err = split(r.Response); err != nil {
+			return nil, err
+		}
+		p.routes[name] = rr
+	}
+
+	return p, nil
+}
+
+// transformFunc replaces the value of a protected field.
+type transformFunc func(value []byte) ([]byte, error)
+
+func (p *Protector) decrypter(ctx context.Context, keyRing string) transformFunc {
+	return func(value []byte) ([]byte, error) {
+		return p.protocol.DecryptContext(ctx, keyRing, string(value))
+	}
+}
+
+func (p *Protector) encrypter(ctx context.Context, keyRing string) transformFunc {
+	return func(value []byte) ([]byte, error) {
+		token, err := p.protocol.EncryptContext(ctx, keyRing, value)
+		return []byte(token), err
+	}
+}
+
+// Call decrypts the request fields of route in req, calls next and encrypts
+// the response fields of route in its response. req and the response must be
+// pointers to structs (like generated protobuf messages), protected fields of
+// type string or []byte. req is modified in place. Calls to routes without
+// configuration are passed to next unchanged.
+func (p *Protector) Call(ctx context.Context, route string, req interface{}, next func(ctx context.Context, req interface{}) (interface{}, error)) (interface{}, error) {
+	r, ok := p.routes[route]
+	if !ok {
+		return next(ctx, req)
+	}
+
+	for _, path := range r.request {
+		if err := transformStruct(reflect.ValueOf(req), path, p.decrypter(ctx, r.keyRing)); err != nil {
+			return nil, fmt.Errorf("protect: %s: request field %s: %w", route, strings.Join(path, "."), err)
+		}
+	}
+
+	resp, err := next(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	for _, path := range r.response {
+		if err := transformStruct(reflect.ValueOf(resp), path, p.encrypter(ctx, r.keyRing)); err != nil {
+			return nil, fmt.Errorf("protect: %s: response field %s: %w", route, strings.Join(path, "."), err)
+		}
+	}
+	return resp, nil
+}
+
+// transformStruct applies fn to the field at path in v. Missing, nil and
+// empty fields are skipped.
+func transformStruct(v reflect.Value, path []string, fn transformFunc) error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	if len(path) == 0 && !v.CanSet() && (v.Kind() == reflect.String || v.Kind() == reflect.Slice) {
+		return errors.New("field can't be modified. Messages must be passed as pointers")
+	}
+	if len(path) == 0 {
+		switch {
+		case v.Kind() == reflect.String:
+			if v.Len() == 0 {
+				return nil
+			}
+			value, err := fn([]byte(v.String()))
+			if err != nil {
+				return err
+			}
+			v.SetString(string(value))
+			return nil
+		case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8:
+			if v.Len() == 0 {
+				return nil
+			}
+			value, err := fn(v.Bytes())
+			if err != nil {
+				return err
+			}
+			v.SetBytes(value)
+			return nil
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := transformStruct(v.Index(i), path, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if len(path) == 0 {
+			break
+		}
+		field, ok := structField(v, path[0])
+		if !ok {
+			return nil
+		}
+		return transformStruct(field, path[1:], fn)
+	}
+
+	if len(path) == 0 {
+		return fmt.Errorf("unsupported type %s (supported: string, []byte)", v.Type())
+	}
+	return nil
+}
+
+// structField returns the exported field of v whose name or json tag name is
+// name.
+func structField(v reflect.Value, name string) (reflect.Value, bool) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		jsonName := strings.Split(sf.Tag.Get("json"), ",")[0]
+		if sf.Name == name || jsonName == name {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
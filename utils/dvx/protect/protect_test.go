@@ -0,0 +1,135 @@
+package protect
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+)
+
+func newProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, dvx.NopLogger)})
+}
+
+func TestHandler(t *testing.T) {
+	p := newProtocol(t)
+	protector, err := New(&Config{
+		Protocol: p,
+		Routes: map[string]Route{
+			"/customers": {KeyRing: "customers", Request: []string{"iban"}, Response: []string{"customers.iban"}},
+		},
+	})
+	require.NoError(t, err)
+
+	handler := protector.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Name string `json:"name"`
+			IBAN string `json:"iban"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "AT611904300234573201", req.IBAN)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"customers":[{"name":"Jane","iban":"AT611904300234573201"},{"name":"John"}],"count":2}`)
+	}))
+
+	// the request field arrives encrypted and the response field leaves
+	// encrypted
+	token, err := p.Encrypt("customers", []byte("AT611904300234573201"))
+	require.NoError(t, err)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"name":"Jane","iban":"`+token+`"}`)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Customers []struct {
+			Name string `json:"name"`
+			IBAN string `json:"iban"`
+		} `json:"customers"`
+		Count int `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 2, resp.Count)
+	assert.Equal(t, "John", resp.Customers[1].Name)
+	iban, err := p.Decrypt("customers", resp.Customers[0].IBAN)
+	require.NoError(t, err)
+	assert.Equal(t, "AT611904300234573201", string(iban))
+
+	// plaintext request fields are rejected
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/customers", strings.NewReader(`{"iban":"AT611904300234573201"}`)))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandler_NonJSONResponse(t *testing.T) {
+	protector, err := New(&Config{
+		Protocol: newProtocol(t),
+		Routes:   map[string]Route{"/secret": {KeyRing: "secret", Response: []string{"secret"}}},
+	})
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	protector.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "secret: plaintext")
+	})).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secret", nil))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "plaintext")
+}
+
+type customer struct {
+	Name string `json:"name,omitempty"`
+	IBAN string `json:"iban,omitempty"`
+}
+
+type listCustomersRequest struct {
+	Filter *customer
+}
+
+type listCustomersResponse struct {
+	Customers []*customer
+}
+
+func TestCall(t *testing.T) {
+	p := newProtocol(t)
+	protector, err := New(&Config{
+		Protocol: p,
+		Routes: map[string]Route{
+			"ListCustomers": {KeyRing: "customers", Request: []string{"Filter.iban"}, Response: []string{"Customers.IBAN"}},
+		},
+	})
+	require.NoError(t, err)
+
+	token, err := p.Encrypt("customers", []byte("AT611904300234573201"))
+	require.NoError(t, err)
+	resp, err := protector.Call(context.Background(), "ListCustomers", &listCustomersRequest{Filter: &customer{IBAN: token}},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.Equal(t, "AT611904300234573201", req.(*listCustomersRequest).Filter.IBAN)
+			return &listCustomersResponse{Customers: []*customer{{Name: "Jane", IBAN: "AT611904300234573201"}, nil}}, nil
+		})
+	require.NoError(t, err)
+
+	iban, err := p.Decrypt("customers", resp.(*listCustomersResponse).Customers[0].IBAN)
+	require.NoError(t, err)
+	assert.Equal(t, "AT611904300234573201", string(iban))
+
+	// plaintext request fields are rejected before the handler is called
+	_, err = protector.Call(context.Background(), "ListCustomers", &listCustomersRequest{Filter: &customer{IBAN: "AT611904300234573201"}},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			t.Fatal("handler called with plaintext request")
+			return nil, nil
+		})
+	assert.Error(t, err)
+}
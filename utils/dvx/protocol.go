@@ -1,41 +1,74 @@
 package dvx
 
 import (
+	"context"
 	"crypto/ed25519"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
 	"io"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	"azoo.dev/utils/dvx/totp"
 )
 
 const (
-	// Version is the version header of the current Protocol implementation. It
+	// Version is the version header of the default Protocol implementation. It
 	// is the lower-cased string name of the underlying Primitive.
 	Version string = "dv1"
 )
 
+// featureVersion is the version of all operations added after DV1 (footers,
+// time-locked ciphers, tokens, sessions, ratchets, dvxfiles, COSE_Encrypt0,
+// …). They rely on the separated key derivations of DV2, so they always use
+// it, regardless of Version and WithVersion.
+const featureVersion = "dv2"
+
 // Protocol is an implementation of the current major dvx version. It can
-// decrypt and verify ciphers, signatures and tags from all previous major
-// versions.
+// decrypt and verify ciphers, signatures and tags from all major versions.
+//
+// Encrypt, Sign, CreateSignKey, MAC and GenerateTOTP use Version (DV1), so
+// ciphers, tags, derived keys and public keys don't change when callers
+// upgrade. DV2 is opt-in with WithVersion("dv2"):
+//   p, err := dvx.NewProtocol(keyPools).WithVersion("dv2")
 //
-// This means the Protocol implementation under azoo.dev/utils/dvx will always
-// use DV1 as it's Primitive. When DV2 will eventually get released, it will be
-// hosted under azoo.dev/utils/dvx/v2, and it's Protocol will use DV2 as it's
-// Primitive, but will be able to decrypt and verify DV1 content.
+// DV2 separates the domains of key derivations: instead of the raw keyRing,
+// the KeyPool receives the keyRing prefixed with a label of the derivation and
+// its purpose, like "dv2/kdf32/enc\x00" or "dv2/kdf64/mac\x00". Therefore,
+// keys derived for the same keyRing, but different sizes or purposes (enc,
+// sig, mac and totp), are never derived from the same input, and inputs of
+// dvx can't collide with those of other protocols using the same root key.
+// Operations added after DV1 always use DV2.
 //
 // The interface of Protocol is closely tied to that of Dragon (originally it's
 // parent project), but may be used directly in non-Dragon scenarios or to
 // locally verify signatures (VerifyPK) without the need to contact a Dragon
 // server.
 type Protocol struct {
-	keys map[string]KeyPool
+	keys           map[string]KeyPool
+	stats          *stats
+	clock          Clock
+	usage          *keyUsage
+	latency        *latency
+	policy         KeyRingPolicy
+	diagnostics    bool
+	totpRevocation TOTPRevocationCheck
+	entropy        *entropyHealth
+	// version overrides Version for the operations listed in WithVersion.
+	version string
+	// contextPrefixes are the keyRing prefixes of RequireEncryptionContext.
+	contextPrefixes []string
+	// usagePolicy is the KeyUsagePolicy of SetKeyUsagePolicy, ordered by
+	// the length of the prefixes (longest first).
+	usagePolicy []keyUsageRule
 }
 
 // NewProtocol creates a new Protocol from a map of KeyPool. The map specifies
-// different KeyPool for major DVX versions.
+// different KeyPool for major DVX versions. As DV1 and DV2 only differ in the
+// input passed to the KeyPool, the KeyPool of one of them is also used for
+// the other if the map doesn't contain one for it.
 //
 // Therefore, a valid map would be:
 //   map[string]dvx.KeyPool{
@@ -43,11 +76,162 @@ type Protocol struct {
 //   }
 func NewProtocol(keyPools map[string]KeyPool) *Protocol {
 	return &Protocol{
-		keys: keyPools,
+		keys:    keyPools,
+		stats:   &stats{},
+		entropy: &entropyHealth{},
+	}
+}
+
+// WithVersion returns a copy of p that encrypts (Encrypt), creates signing
+// keys (CreateSignKey), signs (Sign) and tags (MAC) with version instead of
+// Version, e.g. to compare the behavior and latency of two versions during a
+// migration (see the dual-run mode of Dragon). All other operations still
+// use Version, decryptions and verifications accept all versions like those
+// of p.
+//
+// The copy shares the KeyPools and settings of p, but has its own Stats and
+// doesn't track key usage. version must be supported (see RegisterPrimitive)
+// and p must have a KeyPool for it.
+func (p *Protocol) WithVersion(version string) (*Protocol, error) {
+	if lookupVersion(version) == nil {
+		return nil, fmt.Errorf("dvx: version %q isn't supported", version)
+	}
+	if p.keyPool(version) == nil {
+		return nil, fmt.Errorf("dvx: no KeyPool for version %q", version)
+	}
+
+	q := *p
+	q.version = version
+	q.stats = &stats{}
+	q.usage = nil
+	return &q, nil
+}
+
+// writeVersion returns the version p encrypts, signs and tags with.
+func (p *Protocol) writeVersion() string {
+	if p.version == "" {
+		return Version
+	}
+	return p.version
+}
+
+func (p *Protocol) kdf32(ctx context.Context, keyRing []byte, version string, purpose string) (key []byte, err error) {
+	pool, err := p.pool(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	keyRing = kdfInput(version, "kdf32", purpose, keyRing)
+	ctx, timer := p.latency.startKDF(ctx)
+	if cp, ok := pool.(ContextKeyPool); ok {
+		key, err = cp.KDF32Context(ctx, keyRing)
+	} else {
+		key, err = pool.KDF32(keyRing)
+	}
+	err = timer.done("kdf32", err)
+	if err != nil {
+		return nil, keyDerivationError(err)
+	}
+	return key, nil
+}
+
+func (p *Protocol) kdf64(ctx context.Context, keyRing []byte, version string, purpose string) (key []byte, err error) {
+	pool, err := p.pool(ctx, version)
+	if err != nil {
+		return nil, err
+	}
+
+	keyRing = kdfInput(version, "kdf64", purpose, keyRing)
+	ctx, timer := p.latency.startKDF(ctx)
+	if cp, ok := pool.(ContextKeyPool); ok {
+		key, err = cp.KDF64Context(ctx, keyRing)
+	} else {
+		key, err = pool.KDF64(keyRing)
+	}
+	err = timer.done("kdf64", err)
+	if err != nil {
+		return nil, keyDerivationError(err)
+	}
+	return key, nil
+}
+
+// pool returns the KeyPool for version, unless ctx is already done.
+func (p *Protocol) pool(ctx context.Context, version string) (KeyPool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	pool := p.keyPool(version)
+	if pool == nil {
+		return nil, errorf(ErrKeyDerivation, "dvx: no KeyPool for version %q", version)
+	}
+
+	atomic.AddUint64(&p.stats.kdfCalls, 1)
+	return pool, nil
+}
+
+// keyPool returns the KeyPool for version, or nil if p has none. DV1 and DV2
+// share their KeyPool (see NewProtocol).
+func (p *Protocol) keyPool(version string) KeyPool {
+	pool, ok := p.keys[version]
+	if !ok {
+		if other := sharedKeyPoolVersion(version); other != "" {
+			pool = p.keys[other]
+		}
 	}
+	return pool
 }
 
-func (p *Protocol) keyRingToBytes(keyRing string) []byte {
+// sharedKeyPoolVersion returns the version whose KeyPool version uses if p
+// has none for it, or "" for versions without a shared KeyPool.
+func sharedKeyPoolVersion(version string) string {
+	switch version {
+	case "dv1":
+		return "dv2"
+	case "dv2":
+		return "dv1"
+	default:
+		return ""
+	}
+}
+
+// Purposes of derived keys. Since DV2 they are part of the KeyPool input (see
+// kdfInput).
+const (
+	purposeEncrypt        = "enc"
+	purposeSign           = "sig"
+	purposeMAC            = "mac"
+	purposeTOTP           = "totp"
+	purposeTokenize       = "tok"
+	purposeRatchet        = "rat"
+	purposeCOSE           = "cose"
+	purposeFile           = "file"
+	purposeOneTime        = "ott"
+	purposeSession        = "ses"
+	purposeTOTPRevocation = "totprev"
+	purposeWebAuthn       = "wac"
+	purposeID             = "id"
+	purposeSelfTest       = "self-test"
+)
+
+// purposes are all purposes of keys derived for keyRings of callers.
+var purposes = [...]string{purposeEncrypt, purposeSign, purposeMAC, purposeTOTP, purposeTokenize, purposeRatchet, purposeCOSE, purposeFile, purposeOneTime, purposeSession, purposeTOTPRevocation, purposeWebAuthn, purposeID}
+
+// kdfInput returns the input passed to the KeyPool to derive a key for
+// keyRing. DV1 passes keyRing unchanged, while newer versions prefix it with a
+// label of the version, kdf and purpose, terminated by a zero byte. As labels
+// never contain zero bytes, inputs of different labels never collide.
+func kdfInput(version string, kdf string, purpose string, keyRing []byte) []byte {
+	if version == "dv1" {
+		return keyRing
+	}
+
+	label := version + "/" + kdf + "/" + purpose + "\x00"
+	input := make([]byte, 0, len(label)+len(keyRing))
+	return append(append(input, label...), keyRing...)
+}
+
+func keyRingToBytes(keyRing string) []byte {
 	idx := strings.IndexRune(keyRing, ':')
 	if idx == -1 {
 		return []byte(keyRing)
@@ -67,57 +251,108 @@ func (p *Protocol) keyRingToBytes(keyRing string) []byte {
 // Encrypt derives a secret key `sk` using the keyRing and subsequently
 // encrypts data using `sk`.
 func (p *Protocol) Encrypt(keyRing string, data []byte) (ciphertext string, err error) {
-	key, err := p.keys[Version].KDF32(p.keyRingToBytes(keyRing))
+	return p.EncryptContext(context.Background(), keyRing, data)
+}
+
+// EncryptContext is like Encrypt, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) EncryptContext(ctx context.Context, keyRing string, data []byte) (ciphertext string, err error) {
+	defer p.done(OpEncrypt, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpEncrypt)
+	if err != nil {
+		return "", err
+	}
+	if err = p.checkEncryptionContext(keyRing, nil); err != nil {
+		return "", err
+	}
+	version := p.writeVersion()
+	key, err := p.kdf32(ctx, keyRingBytes, version, purposeEncrypt)
 	if err != nil {
 		return "", err
 	}
 
-	cipher, err := DV1{}.Encrypt(key, data)
+	size := len(data)
+	cipher, err := primitiveOf(version).Encrypt(key, data)
 	if err != nil {
 		return "", err
 	}
 
-	return Encode(Encrypted, cipher), nil
+	p.usage.add(keyRing, keyRingBytes)
+	atomic.AddUint64(&p.stats.bytesEncrypted, uint64(size))
+	return EncodeVersion(version, Encrypted, cipher), nil
 }
 
-func (p *Protocol) decrypt(keyRing []byte, cipher []byte, version string) (data []byte, err error) {
-	switch version {
-	case "dv1":
-		key, err := p.keys[version].KDF32(keyRing)
-		if err != nil {
-			return nil, err
-		}
+func (p *Protocol) decrypt(ctx context.Context, keyRing []byte, cipher []byte, footer []byte, version string) (data []byte, err error) {
+	// footers are authenticated by the AEAD of dv2, which the Primitives of
+	// other versions don't expose
+	if footer != nil && version != "dv2" {
+		return nil, errorf(ErrInvalidFormat, "dvx: %s doesn't support footers", version)
+	}
 
-		data, err = DV1{}.Decrypt(key, cipher)
-		if err != nil {
-			return nil, err
-		}
+	key, err := p.kdf32(ctx, keyRing, version, purposeEncrypt)
+	if err != nil {
+		return nil, err
 	}
-	return
+
+	if footer != nil {
+		return open(version, key, cipher, footer)
+	}
+	return primitiveOf(version).Decrypt(key, cipher)
 }
 
 // Decrypt derives a secret key `sk` using the keyRing and subsequently
-// decrypts ciphertext using `sk`.
+// decrypts ciphertext using `sk`. Ciphers of EncryptNotBefore are only
+// decrypted once the Clock of p reached their timestamp.
 func (p *Protocol) Decrypt(keyRing string, ciphertext string) (data []byte, err error) {
-	v, d, err := DecodeExpect(ciphertext, Encrypted)
+	return p.DecryptContext(context.Background(), keyRing, ciphertext)
+}
+
+// DecryptContext is like Decrypt, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) DecryptContext(ctx context.Context, keyRing string, ciphertext string) (data []byte, err error) {
+	defer p.done(OpDecrypt, time.Now(), &err)
+
+	v, t, d, f, err := DecodeWithFooter(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	keyRingBytes, err := p.keyRingInput(keyRing, OpDecrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case t == Encrypted:
+		data, err = p.decrypt(ctx, keyRingBytes, d, f, v)
+	case t == TimeLocked && f != nil:
+		return nil, errorf(ErrInvalidFormat, "dvx: time-locked ciphers don't support footers")
+	case t == TimeLocked:
+		data, err = p.decryptTimeLocked(ctx, keyRingBytes, d, v)
+	default:
+		return nil, errorf(ErrInvalidFormat, "dvx: invalid format. Incorrect typePrefix")
+	}
 	if err != nil {
 		return nil, err
 	}
 
-	return p.decrypt(p.keyRingToBytes(keyRing), d, v)
+	atomic.AddUint64(&p.stats.bytesDecrypted, uint64(len(data)))
+	return data, nil
 }
 
-func (p *Protocol) deriveSignKey(keyRing []byte, version string) (privateKey []byte, err error) {
-	switch version {
-	case "dv1":
-		seed, err := p.keys[Version].KDF32(keyRing)
-		if err != nil {
-			return nil, err
-		}
+func (p *Protocol) deriveSignKey(ctx context.Context, keyRing []byte, version string) (privateKey []byte, err error) {
+	privateKey, _, err = p.deriveSignKeyPair(ctx, keyRing, version)
+	return
+}
 
-		privateKey = ed25519.NewKeyFromSeed(seed)
+// deriveSignKeyPair derives the seed of keyRing and returns the key pair of
+// version for it (see PrimitiveOptions.SignKey).
+func (p *Protocol) deriveSignKeyPair(ctx context.Context, keyRing []byte, version string) (privateKey []byte, publicKey []byte, err error) {
+	seed, err := p.kdf32(ctx, keyRing, version, purposeSign)
+	if err != nil {
+		return nil, nil, err
 	}
-	return
+	return signKeyFromSeed(version, seed)
 }
 
 // CreateSignKey derives a private key using the keyRing and returns its
@@ -125,7 +360,19 @@ func (p *Protocol) deriveSignKey(keyRing []byte, version string) (privateKey []b
 // with VerifyPK to verify signatures created with Sign using the same
 // keyRing.
 func (p *Protocol) CreateSignKey(keyRing string) (publicKey []byte, err error) {
-	privateKey, err := p.deriveSignKey(p.keyRingToBytes(keyRing), Version)
+	return p.CreateSignKeyContext(context.Background(), keyRing)
+}
+
+// CreateSignKeyContext is like CreateSignKey, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) CreateSignKeyContext(ctx context.Context, keyRing string) (publicKey []byte, err error) {
+	defer p.done(OpCreateSignKey, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpCreateSignKey)
+	if err != nil {
+		return nil, err
+	}
+	privateKey, err := p.deriveSignKey(ctx, keyRingBytes, p.writeVersion())
 	if err != nil {
 		return nil, err
 	}
@@ -136,40 +383,44 @@ func (p *Protocol) CreateSignKey(keyRing string) (publicKey []byte, err error) {
 // Sign derives a private key using the keyRing and subsequently calculates
 // a signature for data.
 func (p *Protocol) Sign(keyRing string, message []byte) (signature string, rawSignature []byte, err error) {
-	key, err := p.deriveSignKey(p.keyRingToBytes(keyRing), Version)
+	return p.SignContext(context.Background(), keyRing, message)
+}
+
+// SignContext is like Sign, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) SignContext(ctx context.Context, keyRing string, message []byte) (signature string, rawSignature []byte, err error) {
+	defer p.done(OpSign, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpSign)
+	if err != nil {
+		return "", nil, err
+	}
+	version := p.writeVersion()
+	key, err := p.deriveSignKey(ctx, keyRingBytes, version)
 	if err != nil {
 		return "", nil, err
 	}
 
-	sig, err := DV1{}.Sign(key, message)
+	sig, err := primitiveOf(version).Sign(key, message)
 	if err != nil {
 		return "", nil, err
 	}
 
-	return Encode(Signed, sig), sig, nil
+	return EncodeVersion(version, Signed, sig), sig, nil
 }
 
 func (p *Protocol) verifyPK(publicKey []byte, message []byte, signature []byte, version string) (valid bool, err error) {
-	switch version {
-	case "dv1":
-		valid, err = DV1{}.Verify(publicKey, message, signature)
-		if err != nil {
-			return false, err
-		}
+	valid, err = primitiveOf(version).Verify(publicKey, message, signature)
+	if err != nil {
+		return false, err
 	}
 	return
 }
 
-func (p *Protocol) verify(keyRing []byte, message []byte, signature []byte, version string) (valid bool, err error) {
-	var publicKey []byte
-
-	switch version {
-	case "dv1":
-		privateKey, err := p.deriveSignKey(keyRing, "dv1")
-		if err != nil {
-			return false, err
-		}
-		publicKey = ed25519.PrivateKey(privateKey).Public().(ed25519.PublicKey)
+func (p *Protocol) verify(ctx context.Context, keyRing []byte, message []byte, signature []byte, version string) (valid bool, err error) {
+	_, publicKey, err := p.deriveSignKeyPair(ctx, keyRing, version)
+	if err != nil {
+		return false, err
 	}
 
 	return p.verifyPK(publicKey, message, signature, version)
@@ -178,12 +429,24 @@ func (p *Protocol) verify(keyRing []byte, message []byte, signature []byte, vers
 // Verify derives a private key using the keyRing and subsequently uses its
 // public key counterpart to verify the signature for data.
 func (p *Protocol) Verify(keyRing string, message []byte, signature string) (valid bool, err error) {
-	v, sig, err := DecodeExpect(signature, Signed)
+	return p.VerifyContext(context.Background(), keyRing, message, signature)
+}
+
+// VerifyContext is like Verify, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) VerifyContext(ctx context.Context, keyRing string, message []byte, signature string) (valid bool, err error) {
+	defer p.done(OpVerify, time.Now(), &err)
+
+	v, sig, footer, err := decodeExpectWithFooter(signature, Signed)
+	if err != nil {
+		return false, err
+	}
+	keyRingBytes, err := p.keyRingInput(keyRing, OpVerify)
 	if err != nil {
 		return false, err
 	}
 
-	return p.verify(p.keyRingToBytes(keyRing), message, sig, v)
+	return p.verify(ctx, keyRingBytes, footerMessage(v, Signed, message, footer), sig, v)
 }
 
 // VerifyPK uses the provided public key directly to verify the signature for
@@ -192,49 +455,57 @@ func (p *Protocol) Verify(keyRing string, message []byte, signature string) (val
 // DVX signature string without access to the KeyPool and respectively private
 // key counterparts.
 func (p *Protocol) VerifyPK(publicKey []byte, message []byte, signature string) (valid bool, err error) {
-	v, signatureBuf, err := DecodeExpect(signature, Signed)
+	defer p.done(OpVerifyPK, time.Now(), &err)
+
+	v, signatureBuf, footer, err := decodeExpectWithFooter(signature, Signed)
 	if err != nil {
 		return false, err
 	}
 
-	return p.verifyPK(publicKey, message, signatureBuf, v)
+	return p.verifyPK(publicKey, footerMessage(v, Signed, message, footer), signatureBuf, v)
 }
 
 // MAC derives a secret key `sk` using the keyRing and subsequently calculates
 // a MAC tag of data using `sk`.
 func (p *Protocol) MAC(keyRing string, message []byte) (tag string, err error) {
-	key, err := p.keys[Version].KDF64(p.keyRingToBytes(keyRing))
+	return p.MACContext(context.Background(), keyRing, message)
+}
+
+// MACContext is like MAC, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) MACContext(ctx context.Context, keyRing string, message []byte) (tag string, err error) {
+	defer p.done(OpMAC, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpMAC)
+	if err != nil {
+		return "", err
+	}
+	version := p.writeVersion()
+	key, err := p.kdf64(ctx, keyRingBytes, version, purposeMAC)
 	if err != nil {
 		return "", err
 	}
 
-	buffer, err := DV1{}.MAC512(key, message)
+	buffer, err := primitiveOf(version).MAC512(key, message)
 	if err != nil {
 		return "", err
 	}
 
-	return Encode(Tagged, buffer), nil
+	return EncodeVersion(version, Tagged, buffer), nil
 }
 
-func (p *Protocol) deriveTOTPKey(keyRing []byte, rawID []byte, accountID string, version string) (key []byte, err error) {
-	switch version {
-	case "dv1":
-		totpSK, err := p.keys[Version].KDF64(keyRing)
-		if err != nil {
-			return nil, err
-		}
-
-		intermediate, err := DV1{}.MAC512(totpSK, rawID)
-		if err != nil {
-			return nil, err
-		}
+func (p *Protocol) deriveTOTPKey(ctx context.Context, keyRing []byte, rawID []byte, accountID string, version string) (key []byte, err error) {
+	totpSK, err := p.kdf64(ctx, keyRing, version, purposeTOTP)
+	if err != nil {
+		return nil, err
+	}
 
-		key, err = DV1{}.MAC256(intermediate, []byte(accountID))
-		if err != nil {
-			return nil, err
-		}
+	intermediate, err := primitiveOf(version).MAC512(totpSK, rawID)
+	if err != nil {
+		return nil, err
 	}
-	return
+
+	return primitiveOf(version).MAC256(intermediate, []byte(accountID))
 }
 
 // GenerateTOTP derives a secret key `sk` using the keyRing. Afterwards, it
@@ -260,14 +531,29 @@ func (p *Protocol) deriveTOTPKey(keyRing []byte, rawID []byte, accountID string,
 // directly passed to azoo.dev/utils/qr generator to create a QR-image of the
 // uri for easy end-user set up.
 func (p *Protocol) GenerateTOTP(keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error) {
-	rawID := make([]byte, 32)
+	return p.GenerateTOTPContext(context.Background(), keyRing, issuer, accountName, accountID)
+}
+
+// GenerateTOTPContext is like GenerateTOTP, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) GenerateTOTPContext(ctx context.Context, keyRing string, issuer string, accountName string, accountID string) (id string, uri string, err error) {
+	defer p.done(OpGenerateTOTP, time.Now(), &err)
+
+	if err = p.checkEntropy(); err != nil {
+		return "", "", err
+	}
+	rawID := make([]byte, totpIDSize)
 	_, err = io.ReadFull(rand.Reader, rawID)
 	if err != nil {
-		return "", "", fmt.Errorf("dvx: cannot generate totp id: %v", err)
+		return "", "", errorf(ErrRandomness, "dvx: cannot generate totp id: %v", err)
 	}
 	id = Encode(TOTP, rawID)
 
-	key, err := p.deriveTOTPKey(p.keyRingToBytes(keyRing), rawID, accountID, Version)
+	keyRingBytes, err := p.keyRingInput(keyRing, OpGenerateTOTP)
+	if err != nil {
+		return "", "", err
+	}
+	key, err := p.deriveTOTPKey(ctx, keyRingBytes, rawID, accountID, Version)
 	if err != nil {
 		return "", "", err
 	}
@@ -286,26 +572,43 @@ func (p *Protocol) GenerateTOTP(keyRing string, issuer string, accountName strin
 
 // VerifyTOTP derives a totp-secret-key `totp-sk` using the same procedure as
 // described in GenerateTOTP and subsequently uses it to verify the provided
-// code in constant-time.
+// code of the current period of the Clock of p (see SetClock) in
+// constant-time. With a TOTPRevocationCheck (see SetTOTPRevocationCheck)
+// revoked ids fail with ErrRevoked before the code is verified.
 func (p *Protocol) VerifyTOTP(keyRing string, id string, accountID string, code string) (valid bool, err error) {
+	return p.VerifyTOTPContext(context.Background(), keyRing, id, accountID, code)
+}
+
+// VerifyTOTPContext is like VerifyTOTP, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) VerifyTOTPContext(ctx context.Context, keyRing string, id string, accountID string, code string) (valid bool, err error) {
+	defer p.done(OpVerifyTOTP, time.Now(), &err)
+
 	v, rawID, err := DecodeExpect(id, TOTP)
 	if err != nil {
 		return false, err
 	}
 
-	key, err := p.deriveTOTPKey(p.keyRingToBytes(keyRing), rawID, accountID, v)
+	keyRingBytes, err := p.keyRingInput(keyRing, OpVerifyTOTP)
 	if err != nil {
 		return false, err
 	}
-
-	switch v {
-	case "dv1":
-		valid, err = (&totp.TOTP{
-			Secret:    key,
-			Algorithm: "SHA256",
-			Digits:    6,
-			Period:    30,
-		}).Verify(code)
+	if err = p.checkTOTPRevocation(ctx, keyRingBytes, id, rawID); err != nil {
+		return false, err
 	}
-	return
+	key, err := p.deriveTOTPKey(ctx, keyRingBytes, rawID, accountID, v)
+	if err != nil {
+		return false, err
+	}
+	now, err := p.now(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return (&totp.TOTP{
+		Secret:    key,
+		Algorithm: "SHA256",
+		Digits:    6,
+		Period:    30,
+	}).VerifyAt(code, now, 0)
 }
@@ -1,23 +1,62 @@
 package dvx
 
 import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ed25519"
 	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
 	"io"
+	"math/big"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
-	logger "github.com/harwoeck/liblog/contract"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"azoo.dev/utils/dvx/totp"
 )
 
+// testLogger writes all log entries to t.Log.
+type testLogger struct {
+	t *testing.T
+}
+
+func (l testLogger) Debug(msg string, keysAndValues ...interface{}) {
+	l.t.Log(append([]interface{}{"DEBUG", msg}, keysAndValues...)...)
+}
+
+func (l testLogger) Info(msg string, keysAndValues ...interface{}) {
+	l.t.Log(append([]interface{}{"INFO", msg}, keysAndValues...)...)
+}
+
+func (l testLogger) Warn(msg string, keysAndValues ...interface{}) {
+	l.t.Log(append([]interface{}{"WARN", msg}, keysAndValues...)...)
+}
+
+func (l testLogger) Error(msg string, keysAndValues ...interface{}) {
+	l.t.Log(append([]interface{}{"ERROR", msg}, keysAndValues...)...)
+}
+
 func newProtocol(t *testing.T) *Protocol {
 	rootKey := make([]byte, 64)
 	_, err := io.ReadFull(rand.Reader, rootKey)
 	require.Nil(t, err)
 
-	p := NewProtocol(map[string]KeyPool{Version: WrapDVXAsKeyPool(DV1{}, rootKey, logger.MustNewStd())})
+	p := NewProtocol(map[string]KeyPool{Version: WrapDVXAsKeyPool(DV1{}, rootKey, testLogger{t})})
 	require.NotNil(t, p)
 
 	return p
@@ -77,6 +116,47 @@ func TestProtocol_UseB64KeyRing(t *testing.T) {
 	assert.NotEqual(t, "data", string(data))
 }
 
+func TestProtocol_WithVersion(t *testing.T) {
+	p := newProtocol(t)
+	dv2, err := p.WithVersion("dv2")
+	require.NoError(t, err)
+
+	ciphertext, err := dv2.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "dv2.enc."))
+	data, err := p.Decrypt("keyring", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	signature, _, err := dv2.Sign("keyring", []byte("message"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(signature, "dv2.sig."))
+	valid, err := p.Verify("keyring", []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	publicKey, err := dv2.CreateSignKey("keyring")
+	require.NoError(t, err)
+	valid, err = p.VerifyPK(publicKey, []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	tag, err := dv2.MAC("keyring", []byte("message"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(tag, "dv2.tag."))
+	current, err := p.MAC("keyring", []byte("message"))
+	require.NoError(t, err)
+	assert.NotEqual(t, current, tag)
+
+	// the copy counts its own operations
+	assert.Equal(t, uint64(1), dv2.Stats().Operations[OpEncrypt])
+	assert.Zero(t, p.Stats().Operations[OpEncrypt])
+
+	_, err = p.WithVersion("dv9")
+	assert.Error(t, err)
+	_, err = NewProtocol(map[string]KeyPool{}).WithVersion("dv2")
+	assert.Error(t, err)
+}
+
 func TestProtocol_TOTP(t *testing.T) {
 	p := newProtocol(t)
 
@@ -119,3 +199,1623 @@ func TestProtocol_TOTP(t *testing.T) {
 	require.NoError(t, err)
 	assert.False(t, notValid)
 }
+
+func TestProtocol_TOTPClock(t *testing.T) {
+	p := newProtocol(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.SetClock(FixedClock(now))
+
+	totpID, uri, err := p.GenerateTOTP("totp", "i", "a1", "a1-id")
+	require.NoError(t, err)
+	client, err := totp.ParseFromURI(uri)
+	require.NoError(t, err)
+
+	// codes are verified for the period of the Clock, not the local time
+	code, err := client.GenerateAt(now)
+	require.NoError(t, err)
+	valid, err := p.VerifyTOTP("totp", totpID, "a1-id", code)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	code, err = client.GenerateAt(now.Add(-time.Minute))
+	require.NoError(t, err)
+	valid, err = p.VerifyTOTP("totp", totpID, "a1-id", code)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	p.SetClock(func(ctx context.Context) (time.Time, error) {
+		return time.Time{}, errors.New("time source unreachable")
+	})
+	_, err = p.VerifyTOTP("totp", totpID, "a1-id", code)
+	assert.Error(t, err)
+}
+
+func TestProtocol_RevokeTOTP(t *testing.T) {
+	p := newProtocol(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.SetClock(func(ctx context.Context) (time.Time, error) {
+		return now, nil
+	})
+	revocations := make(map[string]string)
+	p.SetTOTPRevocationCheck(func(ctx context.Context, id string) (string, error) {
+		return revocations[id], nil
+	})
+
+	totpID, uri, err := p.GenerateTOTP("totp", "i", "a1", "a1-id")
+	require.NoError(t, err)
+	otherID, _, err := p.GenerateTOTP("totp", "i", "a1", "a1-id")
+	require.NoError(t, err)
+	client, err := totp.ParseFromURI(uri)
+	require.NoError(t, err)
+	code, err := client.GenerateAt(now)
+	require.NoError(t, err)
+
+	valid, err := p.VerifyTOTP("totp", totpID, "a1-id", code)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	revocation, err := p.RevokeTOTP("totp", totpID)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(revocation, featureVersion+".trev."))
+
+	rev, err := p.VerifyTOTPRevocation("totp", totpID, revocation)
+	require.NoError(t, err)
+	assert.Equal(t, totpID, rev.ID)
+	assert.True(t, now.Equal(rev.RevokedAt))
+	_, err = p.VerifyTOTPRevocation("totp", otherID, revocation)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.VerifyTOTPRevocation("other", totpID, revocation)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	revocations[totpID] = revocation
+	valid, err = p.VerifyTOTP("totp", totpID, "a1-id", code)
+	assert.True(t, errors.Is(err, ErrRevoked))
+	assert.False(t, valid)
+	assert.Equal(t, uint64(1), p.Stats().Failures["revoked"])
+
+	// revocations of other ids are rejected instead of ignored
+	revocations[otherID] = revocation
+	_, err = p.VerifyTOTP("totp", otherID, "a1-id", code)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	_, err = p.RevokeTOTP("totp", revocation)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestProtocol_MACWriter(t *testing.T) {
+	p := newProtocol(t)
+
+	message := make([]byte, 3000)
+	_, err := io.ReadFull(rand.Reader, message)
+	require.NoError(t, err)
+	tag, err := p.MAC("keyring", message)
+	require.NoError(t, err)
+
+	w, err := p.NewMACWriter("keyring")
+	require.NoError(t, err)
+	for i := 0; i < len(message); i += 1000 {
+		_, err = w.Write(message[i : i+1000])
+		require.NoError(t, err)
+	}
+	assert.Equal(t, tag, w.Sum())
+	require.NoError(t, w.Close())
+	assert.Equal(t, tag, w.Sum(), "the tag is kept after Close")
+
+	_, err = w.Write(message)
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	other, err := p.NewMACWriter("other")
+	require.NoError(t, err)
+	defer other.Close()
+	_, _ = other.Write(message)
+	assert.NotEqual(t, tag, other.Sum())
+
+	_, err = NewProtocol(nil).NewMACWriter("keyring")
+	assert.Error(t, err)
+}
+
+func TestEncryptWithPassword(t *testing.T) {
+	cipher, err := EncryptWithPassword([]byte("correct horse"), []byte("export"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(cipher, featureVersion+".penc."))
+
+	data, err := DecryptWithPassword([]byte("correct horse"), cipher)
+	require.NoError(t, err)
+	assert.Equal(t, "export", string(data))
+
+	_, err = DecryptWithPassword([]byte("wrong horse"), cipher)
+	assert.ErrorIs(t, err, ErrAuthentication)
+
+	// the parameters are stored in the cipher and authenticated
+	params := Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 1}
+	cipher, err = EncryptWithPasswordParams([]byte("correct horse"), []byte("export"), params)
+	require.NoError(t, err)
+	data, err = DecryptWithPassword([]byte("correct horse"), cipher)
+	require.NoError(t, err)
+	assert.Equal(t, "export", string(data))
+
+	_, _, raw, _ := Decode(cipher)
+	raw[3] = 2
+	_, err = DecryptWithPassword([]byte("correct horse"), EncodeVersion(featureVersion, PasswordEncrypted, raw))
+	assert.ErrorIs(t, err, ErrAuthentication)
+	raw[3] = 0
+	_, err = DecryptWithPassword([]byte("correct horse"), EncodeVersion(featureVersion, PasswordEncrypted, raw))
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	// forged parameters can't make the derivation expensive
+	forged := (Argon2Params{Time: 1, Memory: 4 << 20, Threads: 1}).encode(nil)
+	_, err = DecryptWithPassword([]byte("correct horse"), EncodeVersion(featureVersion, PasswordEncrypted, append(forged, raw[argon2ParamsSize:]...)))
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	forged = (Argon2Params{Time: 64, Memory: 8 * 1024, Threads: 1}).encode(nil)
+	_, err = DecryptWithPassword([]byte("correct horse"), EncodeVersion(featureVersion, PasswordEncrypted, append(forged, raw[argon2ParamsSize:]...)))
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+	forged = (Argon2Params{Time: 1, Memory: 8 * 1024, Threads: 255}).encode(nil)
+	_, err = DecryptWithPassword([]byte("correct horse"), EncodeVersion(featureVersion, PasswordEncrypted, append(forged, raw[argon2ParamsSize:]...)))
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+
+	_, err = EncryptWithPassword(nil, []byte("export"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, err = DecryptWithPassword([]byte("correct horse"), Encode(Encrypted, raw))
+	assert.ErrorIs(t, err, ErrInvalidFormat)
+}
+
+func TestProtocol_ErrorClasses(t *testing.T) {
+	p := newProtocol(t)
+
+	_, err := p.Decrypt("keyring", "dv1.enc")
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	assert.Equal(t, "dvx: invalid format. 3 parts expected", err.Error())
+
+	ciphertext, err := p.Encrypt("keyring_a", []byte("data"))
+	require.NoError(t, err)
+	_, err = p.Decrypt("keyring_b", ciphertext)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	_, err = NewProtocol(nil).MAC("keyring", []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyDerivation))
+
+	broken := NewProtocol(map[string]KeyPool{Version: WrapDVXAsKeyPool(DV1{}, []byte("short"), testLogger{t})})
+	_, err = broken.MAC("keyring", []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyDerivation))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+type ctxKey struct{}
+
+type contextPool struct {
+	KeyPool
+	values []interface{}
+}
+
+func (c *contextPool) KDF32Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	c.values = append(c.values, ctx.Value(ctxKey{}))
+	return c.KDF32(keyRing)
+}
+
+func (c *contextPool) KDF64Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	c.values = append(c.values, ctx.Value(ctxKey{}))
+	return c.KDF64(keyRing)
+}
+
+func TestProtocol_Context(t *testing.T) {
+	pool := &contextPool{KeyPool: newProtocol(t).keys[Version]}
+	p := NewProtocol(map[string]KeyPool{Version: pool})
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+	ciphertext, err := p.EncryptContext(ctx, "keyring", []byte("data"))
+	require.NoError(t, err)
+	_, err = p.MACContext(ctx, "keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"value", "value"}, pool.values)
+
+	// methods without context use context.Background
+	data, err := p.Decrypt("keyring", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	assert.Nil(t, pool.values[2])
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = p.DecryptContext(canceled, "keyring", ciphertext)
+	assert.True(t, errors.Is(err, context.Canceled))
+	assert.Len(t, pool.values, 3)
+}
+
+type randomPool struct {
+	KeyPool
+}
+
+func (randomPool) KDF32(keyRing []byte) (key []byte, err error) {
+	key = make([]byte, 32)
+	_, err = io.ReadFull(rand.Reader, key)
+	return
+}
+
+// testPrimitive is DV1 registered as out-of-tree Primitive "dvtest".
+type testPrimitive struct {
+	DV1
+}
+
+func init() {
+	if err := RegisterPrimitive("dvtest", testPrimitive{}, &PrimitiveOptions{
+		SelfTest: func(primitive Primitive) error { return nil },
+	}); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegisterPrimitive(t *testing.T) {
+	for _, version := range []string{"dv1", "dv2", "dvtest", "", "d", "dv.3", "dv/3", "DV3"} {
+		assert.Error(t, RegisterPrimitive(version, testPrimitive{}, nil), version)
+	}
+	assert.Error(t, RegisterPrimitive("dv3", nil, nil))
+
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+	pool := WrapDVXAsKeyPool(DV1{}, rootKey, testLogger{t})
+	p := NewProtocol(map[string]KeyPool{Version: pool, "dvtest": pool})
+
+	// ciphers of dvtest are decrypted with the key derived for its label
+	key, err := pool.KDF32(kdfInput("dvtest", "kdf32", purposeEncrypt, []byte("keyring")))
+	require.NoError(t, err)
+	cipher, err := testPrimitive{}.Encrypt(key, []byte("data"))
+	require.NoError(t, err)
+	ciphertext := EncodeVersion("dvtest", Encrypted, cipher)
+
+	v, _, _, err := Decode(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, "dvtest", v)
+	data, err := p.Decrypt("keyring", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	_, err = p.Decrypt("other", ciphertext)
+	assert.Error(t, err)
+	_, err = p.Decrypt("keyring", ciphertext+"."+base64.RawURLEncoding.EncodeToString([]byte("footer")))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	// and its keys aren't those of dv2
+	_, err = p.Decrypt("keyring", Encode(Encrypted, cipher))
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	assert.NoError(t, p.SelfTest(context.Background()))
+}
+
+func TestProtocol_SelfTest(t *testing.T) {
+	p := newProtocol(t)
+	assert.NoError(t, p.SelfTest(context.Background()))
+
+	broken := NewProtocol(map[string]KeyPool{Version: WrapDVXAsKeyPool(DV1{}, []byte("short"), testLogger{t})})
+	err := broken.SelfTest(context.Background())
+	assert.True(t, errors.Is(err, ErrSelfTest))
+	assert.True(t, errors.Is(err, ErrKeyDerivation))
+
+	nonDeterministic := NewProtocol(map[string]KeyPool{Version: randomPool{p.keys[Version]}})
+	err = nonDeterministic.SelfTest(context.Background())
+	assert.True(t, errors.Is(err, ErrSelfTest))
+}
+
+type blockingReader struct{}
+
+func (blockingReader) Read([]byte) (int, error) {
+	select {}
+}
+
+func TestProtocol_Entropy(t *testing.T) {
+	ctx := context.Background()
+	assert.NoError(t, testEntropy(ctx, rand.Reader, entropyTimeout))
+
+	repeat := func(a, b byte) io.Reader {
+		return bytes.NewReader(append(bytes.Repeat([]byte{a}, entropySampleSize), bytes.Repeat([]byte{b}, entropySampleSize)...))
+	}
+	err := testEntropy(ctx, repeat(0x00, 0x01), entropyTimeout)
+	assert.Contains(t, err.Error(), "monobit")
+	err = testEntropy(ctx, repeat(0x0f, 0xf0), entropyTimeout)
+	assert.Contains(t, err.Error(), "poker")
+	err = testEntropy(ctx, repeat(0x00, 0x00), entropyTimeout)
+	assert.Contains(t, err.Error(), "equal")
+	err = testEntropy(ctx, io.MultiReader(bytes.NewReader(make([]byte, entropySampleSize)), rand.Reader), entropyTimeout)
+	assert.NoError(t, err, "a single failed sample is tolerated")
+	err = testEntropy(ctx, blockingReader{}, 10*time.Millisecond)
+	assert.Contains(t, err.Error(), "blocked")
+	err = testEntropy(ctx, bytes.NewReader(nil), entropyTimeout)
+	assert.Contains(t, err.Error(), "EOF")
+
+	p := newProtocol(t)
+	p.entropy.set(errors.New("getrandom blocked"))
+	_, err = p.IssueOneTimeToken("magic-links", "jane@example.com", time.Minute)
+	assert.True(t, errors.Is(err, ErrRandomness))
+	_, _, err = p.GenerateTOTP("totp", "azoo", "jane", "1")
+	assert.True(t, errors.Is(err, ErrRandomness))
+	_, err = p.SealSession("sessions", map[string]string{"sub": "jane"}, time.Minute)
+	assert.True(t, errors.Is(err, ErrRandomness))
+
+	require.NoError(t, p.SelfTest(ctx))
+	_, err = p.IssueOneTimeToken("magic-links", "jane@example.com", time.Minute)
+	assert.NoError(t, err)
+}
+
+type cachingPool struct {
+	KeyPool
+}
+
+func (cachingPool) CacheStats() (hits uint64, misses uint64) {
+	return 3, 1
+}
+
+func TestProtocol_Stats(t *testing.T) {
+	p := NewProtocol(map[string]KeyPool{Version: cachingPool{newProtocol(t).keys[Version]}})
+
+	ciphertext, err := p.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	_, err = p.Decrypt("keyring", ciphertext)
+	require.NoError(t, err)
+	_, err = p.Decrypt("other", ciphertext)
+	require.Error(t, err)
+	_, err = p.Decrypt("keyring", "dv1.enc")
+	require.Error(t, err)
+
+	stats := p.Stats()
+	assert.Equal(t, uint64(1), stats.Operations[OpEncrypt])
+	assert.Equal(t, uint64(3), stats.Operations[OpDecrypt])
+	assert.Equal(t, uint64(0), stats.Operations[OpMAC])
+	assert.Equal(t, uint64(1), stats.Failures["authentication"])
+	assert.Equal(t, uint64(1), stats.Failures["invalid_format"])
+	assert.Equal(t, uint64(4), stats.BytesEncrypted)
+	assert.Equal(t, uint64(4), stats.BytesDecrypted)
+	assert.Equal(t, uint64(3), stats.KDFCalls)
+	assert.Equal(t, uint64(3), stats.KDFCacheHits)
+}
+
+// invalidatingPool records the inputs passed to Invalidate.
+type invalidatingPool struct {
+	cachingPool
+	invalidated []string
+}
+
+func (i *invalidatingPool) Invalidate(keyRing []byte) int {
+	i.invalidated = append(i.invalidated, string(keyRing))
+	return 1
+}
+
+// prefixPool reports fixed PrefixStats.
+type prefixPool struct {
+	cachingPool
+}
+
+func (prefixPool) PrefixStats(visit func(prefix string, bypass bool, hits uint64, misses uint64, direct uint64)) {
+	visit("unwrap/", true, 0, 0, 5)
+	visit("", false, 3, 1, 0)
+}
+
+func TestProtocol_PrefixStats(t *testing.T) {
+	p := NewProtocol(map[string]KeyPool{Version: prefixPool{cachingPool{newProtocol(t).keys[Version]}}})
+
+	assert.Equal(t, uint64(5), p.Stats().KDFCacheBypasses)
+	assert.Equal(t, []KeyPoolStatus{{
+		Version:     Version,
+		Caching:     true,
+		CacheHits:   3,
+		CacheMisses: 1,
+		Prefixes: []PrefixStatus{
+			{Prefix: "unwrap/", Bypass: true, Direct: 5},
+			{Prefix: "", CacheHits: 3, CacheMisses: 1},
+		},
+	}}, p.KeyPools())
+}
+
+// arcPool reports fixed ARCStats of two shards.
+type arcPool struct {
+	cachingPool
+}
+
+func (arcPool) ARCStats(visit func(size, target, t1, t2, b1, b2 int, b1Hits, b2Hits uint64)) {
+	visit(4, 1, 1, 3, 2, 0, 5, 0)
+	visit(4, 0, 0, 0, 0, 0, 0, 0)
+}
+
+func TestProtocol_ARCStats(t *testing.T) {
+	p := NewProtocol(map[string]KeyPool{Version: arcPool{cachingPool{newProtocol(t).keys[Version]}}})
+
+	assert.Equal(t, []ShardStatus{
+		{Size: 4, Target: 1, T1: 1, T2: 3, B1: 2, B1Hits: 5},
+		{Size: 4},
+	}, p.KeyPools()[0].Shards)
+}
+
+func TestProtocol_KeyPools(t *testing.T) {
+	pool := &invalidatingPool{cachingPool: cachingPool{newProtocol(t).keys[Version]}}
+	p := NewProtocol(map[string]KeyPool{Version: pool})
+
+	assert.Equal(t, []KeyPoolStatus{{Version: Version, Caching: true, CacheHits: 3, CacheMisses: 1}}, p.KeyPools())
+	assert.Equal(t, []KeyPoolStatus{{Version: Version, Generation: 1}}, newProtocol(t).KeyPools())
+
+	// dv1 keys and the keys of all dv2 purposes and sizes are invalidated
+	assert.Equal(t, 1+2*len(purposes), p.Invalidate("keyring"))
+	assert.Contains(t, pool.invalidated, "keyring")
+	assert.Contains(t, pool.invalidated, "dv2/kdf32/enc\x00keyring")
+	assert.Contains(t, pool.invalidated, "dv2/kdf64/mac\x00keyring")
+	assert.Equal(t, 0, newProtocol(t).Invalidate("keyring"))
+}
+
+func TestProtocol_TrackKeyUsage(t *testing.T) {
+	p := newProtocol(t)
+	type reached struct {
+		keyRing                string
+		encryptions, threshold uint64
+	}
+	var calls []reached
+	p.TrackKeyUsage(&KeyUsageConfig{
+		Thresholds: []uint64{3, 2},
+		Logger:     testLogger{t},
+		OnThreshold: func(keyRing string, encryptions uint64, threshold uint64) {
+			calls = append(calls, reached{keyRing, encryptions, threshold})
+		},
+	})
+
+	for i := 0; i < 3; i++ {
+		_, err := p.Encrypt("a", []byte("data"))
+		require.NoError(t, err)
+	}
+	_, err := p.EncryptNotBefore("b:"+base64.RawStdEncoding.EncodeToString([]byte("b")), []byte("data"), time.Now())
+	require.NoError(t, err)
+	_, err = p.Encrypt("b", []byte("data"))
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(3), p.KeyUsage("a"))
+	assert.Equal(t, uint64(2), p.KeyUsage("b"))
+	assert.Equal(t, uint64(0), p.KeyUsage("c"))
+	assert.Equal(t, []reached{{"a", 2, 2}, {"a", 3, 3}, {"b", 2, 2}}, calls)
+
+	stats := p.Stats()
+	assert.Equal(t, uint64(3), stats.MaxKeyUsage)
+	assert.Equal(t, uint64(2), stats.KeysOverThreshold)
+}
+
+func TestWrapDVXAsKeyPool_NilLogger(t *testing.T) {
+	for _, log := range []Logger{nil, NopLogger} {
+		pool := WrapDVXAsKeyPool(DV1{}, make([]byte, 64), log)
+		key, err := pool.KDF32([]byte("keyring"))
+		require.NoError(t, err)
+		assert.Len(t, key, 32)
+	}
+}
+
+func TestWrapDVXAsKeyPool_Rotate(t *testing.T) {
+	root := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, root)
+	require.NoError(t, err)
+
+	pool := WrapDVXAsKeyPool(DV1{}, root, nil)
+	before, err := pool.KDF32([]byte("keyring"))
+	require.NoError(t, err)
+
+	// the root key is copied
+	root[0] ^= 0xff
+	key, err := pool.KDF32([]byte("keyring"))
+	require.NoError(t, err)
+	assert.Equal(t, before, key)
+
+	rotating, ok := pool.(RotatingKeyPool)
+	require.True(t, ok)
+	assert.True(t, errors.Is(rotating.Rotate([]byte("short")), ErrInvalidKey))
+	assert.Equal(t, uint64(1), pool.(GenerationKeyPool).Generation())
+	require.NoError(t, rotating.Rotate(root))
+	assert.Equal(t, uint64(2), pool.(GenerationKeyPool).Generation())
+	after, err := pool.KDF32([]byte("keyring"))
+	require.NoError(t, err)
+	assert.NotEqual(t, before, after)
+
+	require.NoError(t, pool.Close())
+	_, err = pool.KDF32([]byte("keyring"))
+	assert.Error(t, err)
+	assert.Error(t, rotating.Rotate(root))
+}
+
+func TestProtocol_DV1(t *testing.T) {
+	p := newProtocol(t)
+	pool := p.keys[Version]
+
+	// DV1 derives keys from the raw keyRing
+	key, err := pool.KDF32([]byte("keyring"))
+	require.NoError(t, err)
+	cipher, err := DV1{}.Encrypt(key, []byte("data"))
+	require.NoError(t, err)
+
+	data, err := p.Decrypt("keyring", "dv1.enc."+base64.RawURLEncoding.EncodeToString(cipher))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	// DV1 is the default of Encrypt, Sign and MAC
+	ciphertext, err := p.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "dv1.enc."))
+	seed, err := pool.KDF32([]byte("keyring"))
+	require.NoError(t, err)
+	publicKey, err := p.CreateSignKey("keyring")
+	require.NoError(t, err)
+	assert.Equal(t, ed25519.NewKeyFromSeed(seed).Public(), ed25519.PublicKey(publicKey))
+
+	// DV2 ciphers can't be decrypted as DV1
+	dv2, err := p.WithVersion("dv2")
+	require.NoError(t, err)
+	ciphertext, err = dv2.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, "dv2.enc."))
+	_, err = p.Decrypt("keyring", "dv1"+strings.TrimPrefix(ciphertext, "dv2"))
+	assert.True(t, errors.Is(err, ErrAuthentication))
+}
+
+func TestKDFInput(t *testing.T) {
+	assert.Equal(t, []byte("keyring"), kdfInput("dv1", "kdf32", purposeEncrypt, []byte("keyring")))
+	assert.Equal(t, []byte("dv2/kdf32/enc\x00keyring"), kdfInput("dv2", "kdf32", purposeEncrypt, []byte("keyring")))
+	assert.NotEqual(t, kdfInput("dv2", "kdf32", purposeEncrypt, []byte("keyring")), kdfInput("dv2", "kdf64", purposeEncrypt, []byte("keyring")))
+	assert.NotEqual(t, kdfInput("dv2", "kdf32", purposeEncrypt, []byte("keyring")), kdfInput("dv2", "kdf32", purposeSign, []byte("keyring")))
+}
+
+func TestProtocol_SignWithKey(t *testing.T) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	require.NoError(t, err)
+
+	// keys generated elsewhere, e.g. by openssl
+	parsed, err := ParsePrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.Equal(t, privateKey, parsed)
+	encodedPublicKey, err := MarshalPublicKey(publicKey)
+	require.NoError(t, err)
+	parsedPublicKey, err := ParsePublicKey(encodedPublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey, parsedPublicKey)
+
+	// a Protocol without KeyPool signs and verifies
+	p := NewProtocol(nil)
+	for _, key := range [][]byte{privateKey, privateKey.Seed()} {
+		signature, _, err := p.SignWithKey(key, []byte("message"))
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(signature, Version+".sig."))
+
+		valid, err := p.VerifyPK(parsedPublicKey, []byte("message"), signature)
+		require.NoError(t, err)
+		assert.True(t, valid)
+	}
+
+	_, _, err = p.SignWithKey([]byte("short"), []byte("message"))
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	_, err = ParsePrivateKey(encodedPublicKey)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+}
+
+func TestProtocol_Tokenize(t *testing.T) {
+	p := newProtocol(t)
+
+	// tokens are stable per keyRing
+	a, err := p.Tokenize("users", "jane@example.com")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(a, featureVersion+".tok."))
+	b, err := p.Tokenize("users", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	c, err := p.Tokenize("customers", "jane@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+
+	value, err := p.Detokenize("users", a)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", value)
+
+	_, err = p.Detokenize("customers", a)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.Detokenize("users", strings.Replace(a, ".tok.", ".enc.", 1))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestProtocol_DeriveID(t *testing.T) {
+	p := newProtocol(t)
+
+	// IDs are stable per keyRing and name
+	a, err := p.DeriveID("users", "jane@example.com")
+	require.NoError(t, err)
+	assert.Regexp(t, `^[0-9a-f]{8}-[0-9a-f]{4}-8[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, a)
+	b, err := p.DeriveID("users", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, a, b)
+	c, err := p.DeriveID("customers", "jane@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, c)
+	d, err := p.DeriveID("users", "john@example.com")
+	require.NoError(t, err)
+	assert.NotEqual(t, a, d)
+
+	// IDs must never change for the same root key
+	fixed := NewProtocol(map[string]KeyPool{Version: WrapDVXAsKeyPool(DV1{}, make([]byte, 64), testLogger{t})})
+	id, err := fixed.DeriveID("users", "jane@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "393aeb33-48ca-8a7c-952b-fc1216e990b9", id)
+	assert.Equal(t, uint64(1), fixed.Stats().Operations[OpDeriveID])
+}
+
+func TestProtocol_VerificationBundle(t *testing.T) {
+	p := newProtocol(t)
+	now := time.Now()
+	p.SetClock(FixedClock(now))
+
+	bundle, err := p.ExportVerificationBundle(&BundleConfig{
+		Signer:   "bundles/edge",
+		KeyRings: []string{"services/billing", "services/leaked"},
+		Versions: []string{"dv1", "dv2"},
+		Revoked:  []string{"services/leaked"},
+		TTL:      time.Hour,
+	})
+	require.NoError(t, err)
+	signerPublicKey, err := p.CreateSignKey("bundles/edge")
+	require.NoError(t, err)
+
+	verifier, err := NewVerifierFromBundle(bundle, signerPublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, Version, verifier.Bundle().Version)
+	assert.Len(t, verifier.Bundle().Keys, 2)
+
+	// signatures of both versions are verified without a KeyPool
+	signature, _, err := p.Sign("services/billing", []byte("message"))
+	require.NoError(t, err)
+	valid, err := verifier.Verify("services/billing", []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = verifier.Verify("services/billing", []byte("other message"), signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+	dv1, err := p.WithVersion("dv1")
+	require.NoError(t, err)
+	signature, _, err = dv1.Sign("services/billing", []byte("message"))
+	require.NoError(t, err)
+	valid, err = verifier.Verify("services/billing", []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	// revoked and unknown keyRings are rejected
+	signature, _, err = p.Sign("services/leaked", []byte("message"))
+	require.NoError(t, err)
+	_, err = verifier.Verify("services/leaked", []byte("message"), signature)
+	assert.ErrorIs(t, err, ErrRevoked)
+	_, err = verifier.Verify("services/other", []byte("message"), signature)
+	assert.ErrorIs(t, err, ErrKeyDerivation)
+
+	// expired bundles are rejected
+	verifier.SetClock(FixedClock(now.Add(2 * time.Hour)))
+	_, err = verifier.Verify("services/billing", []byte("message"), signature)
+	assert.ErrorIs(t, err, ErrExpired)
+
+	// bundles of other signers and changed bundles are rejected
+	otherPublicKey, err := p.CreateSignKey("bundles/other")
+	require.NoError(t, err)
+	_, err = NewVerifierFromBundle(bundle, otherPublicKey)
+	assert.ErrorIs(t, err, ErrAuthentication)
+	changed := bytes.Replace(bundle, []byte("services/leaked"), []byte("services/leakex"), 1)
+	_, err = NewVerifierFromBundle(changed, signerPublicKey)
+	assert.ErrorIs(t, err, ErrAuthentication)
+
+	_, err = p.ExportVerificationBundle(&BundleConfig{KeyRings: []string{"services/billing"}})
+	assert.Error(t, err)
+	_, err = p.ExportVerificationBundle(&BundleConfig{Signer: "bundles/edge", Versions: []string{"dv9"}, KeyRings: []string{"services/billing"}})
+	assert.Error(t, err)
+}
+
+func TestProtocol_EncryptNotBefore(t *testing.T) {
+	p := newProtocol(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.SetClock(func(ctx context.Context) (time.Time, error) {
+		return now, nil
+	})
+
+	ciphertext, err := p.EncryptNotBefore("keyring", []byte("data"), now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(ciphertext, featureVersion+".tlk."))
+
+	_, err = p.Decrypt("keyring", ciphertext)
+	assert.True(t, errors.Is(err, ErrTimeLocked))
+	assert.Equal(t, "dvx: cipher is locked until 2030-01-01T13:00:00Z", err.Error())
+	assert.Equal(t, uint64(1), p.Stats().Failures["time_locked"])
+
+	now = now.Add(time.Hour)
+	data, err := p.Decrypt("keyring", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	// the timestamp is authenticated
+	_, typePrefix, cipher, err := Decode(ciphertext)
+	require.NoError(t, err)
+	cipher[7]--
+	_, err = p.Decrypt("keyring", EncodeVersion(featureVersion, typePrefix, cipher))
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	// errors of the clock are returned
+	p.SetClock(func(ctx context.Context) (time.Time, error) {
+		return time.Time{}, io.ErrUnexpectedEOF
+	})
+	_, err = p.Decrypt("keyring", ciphertext)
+	assert.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+}
+
+func TestProtocol_OneTimeToken(t *testing.T) {
+	p := newProtocol(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.SetClock(func(ctx context.Context) (time.Time, error) {
+		return now, nil
+	})
+
+	token, err := p.IssueOneTimeToken("magic-links", "jane@example.com", 15*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(token, featureVersion+".ott."))
+
+	ott, err := p.RedeemOneTimeToken("magic-links", token)
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", ott.Subject)
+	assert.Equal(t, now.Add(15*time.Minute).Unix(), ott.ExpiresAt.Unix())
+	assert.Len(t, ott.RedemptionID, 22)
+
+	// every token has its own redemption-id
+	other, err := p.IssueOneTimeToken("magic-links", "jane@example.com", 15*time.Minute)
+	require.NoError(t, err)
+	otherOTT, err := p.RedeemOneTimeToken("magic-links", other)
+	require.NoError(t, err)
+	assert.NotEqual(t, ott.RedemptionID, otherOTT.RedemptionID)
+
+	_, err = p.RedeemOneTimeToken("sessions", token)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, typePrefix, data, err := Decode(token)
+	require.NoError(t, err)
+	data[7]++
+	_, err = p.RedeemOneTimeToken("magic-links", EncodeVersion(featureVersion, typePrefix, data))
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	tag, err := p.MAC("magic-links", []byte("jane@example.com"))
+	require.NoError(t, err)
+	_, err = p.RedeemOneTimeToken("magic-links", tag)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	now = now.Add(15 * time.Minute)
+	_, err = p.RedeemOneTimeToken("magic-links", token)
+	assert.True(t, errors.Is(err, ErrExpired))
+	assert.Equal(t, "dvx: one-time token expired at 2030-01-01T12:15:00Z", err.Error())
+	assert.Equal(t, uint64(1), p.Stats().Failures["expired"])
+
+	_, err = p.IssueOneTimeToken("magic-links", "jane@example.com", 0)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestProtocol_WebAuthnChallenge(t *testing.T) {
+	p := newProtocol(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.SetClock(func(ctx context.Context) (time.Time, error) {
+		return now, nil
+	})
+
+	challenge, err := p.IssueWebAuthnChallenge("passkeys", "example.com", "session-1", 5*time.Minute)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(challenge, featureVersion+".wac."))
+	// clientDataJSON contains the challenge base64url encoded
+	clientChallenge := base64.RawURLEncoding.EncodeToString([]byte(challenge))
+
+	expiresAt, err := p.VerifyWebAuthnChallenge("passkeys", "example.com", "session-1", clientChallenge)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(5*time.Minute).Unix(), expiresAt.Unix())
+	_, err = p.VerifyWebAuthnChallenge("passkeys", "example.com", "session-1", base64.URLEncoding.EncodeToString([]byte(challenge)))
+	assert.NoError(t, err)
+
+	// challenges are bound to keyRing, relying party and binding
+	_, err = p.VerifyWebAuthnChallenge("other", "example.com", "session-1", clientChallenge)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.VerifyWebAuthnChallenge("passkeys", "example.org", "session-1", clientChallenge)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.VerifyWebAuthnChallenge("passkeys", "example.com", "session-2", clientChallenge)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.VerifyWebAuthnChallenge("passkeys", "example.com", "session-1", "not base64!")
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	now = now.Add(5 * time.Minute)
+	_, err = p.VerifyWebAuthnChallenge("passkeys", "example.com", "session-1", clientChallenge)
+	assert.True(t, errors.Is(err, ErrExpired))
+
+	_, err = p.IssueWebAuthnChallenge("passkeys", "", "session-1", time.Minute)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestProtocol_SealSession(t *testing.T) {
+	p := newProtocol(t)
+	now := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	p.SetClock(func(ctx context.Context) (time.Time, error) {
+		return now, nil
+	})
+
+	type claims struct {
+		UserID string `json:"uid"`
+		Admin  bool   `json:"adm"`
+	}
+
+	cookie, err := p.SealSession("sessions/2030", claims{UserID: "u-1", Admin: true}, time.Hour)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(cookie, featureVersion+".ses."))
+	// expiry, nonce and tag add 48 bytes to the claims
+	assert.Len(t, cookie, len("dv2.ses.")+base64.RawURLEncoding.EncodedLen(48+len(`{"uid":"u-1","adm":true}`)))
+
+	var c claims
+	session, err := p.OpenSession("sessions/2030", cookie, &c)
+	require.NoError(t, err)
+	assert.Equal(t, claims{UserID: "u-1", Admin: true}, c)
+	assert.Equal(t, "sessions/2030", session.KeyRing)
+	assert.True(t, now.Add(time.Hour).Equal(session.ExpiresAt))
+	assert.False(t, session.Reseal)
+
+	// cookies of previous keyRings are opened, but should be resealed
+	session, err = p.OpenSession("sessions/2031", cookie, &c, "sessions/2030")
+	require.NoError(t, err)
+	assert.Equal(t, "sessions/2030", session.KeyRing)
+	assert.True(t, session.Reseal)
+
+	_, err = p.OpenSession("sessions/2031", cookie, &c)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, typePrefix, cipher, err := Decode(cookie)
+	require.NoError(t, err)
+	cipher[7]++
+	_, err = p.OpenSession("sessions/2030", EncodeVersion(featureVersion, typePrefix, cipher), &c)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	now = now.Add(time.Hour)
+	_, err = p.OpenSession("sessions/2030", cookie, &c)
+	assert.True(t, errors.Is(err, ErrExpired))
+
+	_, err = p.SealSession("sessions/2030", strings.Repeat("a", MaxSessionSize), time.Hour)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, err = p.SealSession("sessions/2030", make(chan int), time.Hour)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestRatchet(t *testing.T) {
+	p := newProtocol(t)
+	sender, err := p.NewRatchet("stream")
+	require.NoError(t, err)
+	receiver, err := p.NewRatchet("stream")
+	require.NoError(t, err)
+
+	var ciphertexts []string
+	for i := 0; i < 3; i++ {
+		ciphertext, index, err := sender.EncryptNext([]byte{byte(i)})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(i), index)
+		ciphertexts = append(ciphertexts, ciphertext)
+	}
+	assert.Equal(t, uint64(3), sender.Index())
+
+	// every message has its own key
+	_, err = receiver.DecryptAt(1, ciphertexts[0])
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.Decrypt("stream", ciphertexts[0])
+	assert.True(t, errors.Is(err, ErrAuthentication))
+
+	// out of order
+	data, err := receiver.DecryptAt(2, ciphertexts[2])
+	require.NoError(t, err)
+	assert.Equal(t, []byte{2}, data)
+	data, err = receiver.DecryptAt(0, ciphertexts[0])
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0}, data)
+
+	// discarded keys
+	require.NoError(t, receiver.Advance())
+	_, err = receiver.DecryptAt(0, ciphertexts[0])
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+	data, err = receiver.DecryptAt(1, ciphertexts[1])
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1}, data)
+
+	_, err = receiver.DecryptAt(MaxRatchetSkip+2, ciphertexts[1])
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	require.NoError(t, receiver.Close())
+	_, err = receiver.DecryptAt(1, ciphertexts[1])
+	assert.Error(t, err)
+	assert.Error(t, receiver.Advance())
+}
+
+func TestSecureBuffer(t *testing.T) {
+	_, err := NewSecureBuffer(0)
+	assert.Error(t, err)
+
+	secret := []byte("plaintext secret")
+	b, err := NewSecureBufferFrom(secret)
+	require.NoError(t, err)
+	assert.Equal(t, make([]byte, len(secret)), secret)
+	assert.Equal(t, []byte("plaintext secret"), b.Bytes())
+	t.Logf("locked: %v", b.Locked())
+
+	data, err := io.ReadAll(b.Reader())
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plaintext secret"), data)
+
+	w := b.Writer()
+	_, err = w.Write([]byte("PLAINTEXT"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("PLAINTEXT secret"), b.Bytes())
+	n, err := w.Write([]byte(" SECRET!"))
+	assert.Equal(t, 7, n)
+	assert.Equal(t, io.ErrShortBuffer, err)
+	assert.Equal(t, []byte("PLAINTEXT SECRET"), b.Bytes())
+
+	b.Wipe()
+	assert.Equal(t, make([]byte, len(secret)), b.Bytes())
+
+	r := b.Reader()
+	b.Destroy()
+	b.Destroy()
+	assert.Equal(t, 0, b.Len())
+	_, err = r.Read(make([]byte, 1))
+	assert.Error(t, err)
+	_, err = b.Writer().Write([]byte{1})
+	assert.Error(t, err)
+}
+
+func TestProtocol_Footer(t *testing.T) {
+	p := newProtocol(t)
+	footer := []byte(`{"kid":"users"}`)
+
+	ciphertext, err := p.EncryptWithFooter("keyring", []byte("data"), footer)
+	require.NoError(t, err)
+	v, typePrefix, _, f, err := DecodeWithFooter(ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, featureVersion, v)
+	assert.Equal(t, Encrypted, typePrefix)
+	assert.Equal(t, footer, f)
+	data, err := p.Decrypt("keyring", ciphertext)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	signature, _, err := p.SignWithFooter("keyring", []byte("message"), footer)
+	require.NoError(t, err)
+	valid, err := p.Verify("keyring", []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	publicKey, err := p.CreateSignKey("keyring")
+	require.NoError(t, err)
+	valid, err = p.VerifyPK(publicKey, []byte("message"), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	tag, err := p.MACWithFooter("keyring", []byte("message"), footer)
+	require.NoError(t, err)
+	plain, err := p.MAC("keyring", []byte("message"))
+	require.NoError(t, err)
+	_, _, _, f, err = DecodeWithFooter(tag)
+	require.NoError(t, err)
+	assert.Equal(t, footer, f)
+	// the footer is part of the tag
+	assert.NotEqual(t, plain, tag[:strings.LastIndex(tag, ".")])
+
+	// without footer the results are the same as without the footer methods
+	tag2, err := p.MACWithFooter("keyring", []byte("message"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, plain, tag2)
+
+	// footers are authenticated
+	changed := EncodeWithFooter(Encrypted, mustDecode(t, ciphertext), []byte(`{"kid":"admin"}`))
+	_, err = p.Decrypt("keyring", changed)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.Decrypt("keyring", ciphertext[:strings.LastIndex(ciphertext, ".")])
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	changed = EncodeWithFooter(Signed, mustDecode(t, signature), []byte(`{"kid":"admin"}`))
+	valid, err = p.Verify("keyring", []byte("message"), changed)
+	require.NoError(t, err)
+	assert.False(t, valid)
+	// bytes can't be moved between message and footer
+	changed = EncodeWithFooter(Signed, mustDecode(t, signature), footer[1:])
+	valid, err = p.Verify("keyring", []byte("message{"), changed)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	// Decode rejects footers
+	_, _, _, err = Decode(ciphertext)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, _, _, _, err = DecodeWithFooter(ciphertext + ".")
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func mustDecode(t *testing.T, s string) []byte {
+	_, _, data, _, err := DecodeWithFooter(s)
+	require.NoError(t, err)
+	return data
+}
+
+func TestCompact(t *testing.T) {
+	p := newProtocol(t)
+
+	tag, err := p.MAC("keyring", []byte("message"))
+	require.NoError(t, err)
+	compact, err := Compact(tag)
+	require.NoError(t, err)
+	assert.NotContains(t, compact, ".")
+	assert.Less(t, len(compact), len(tag))
+	v, typePrefix, data, err := Decode(compact)
+	require.NoError(t, err)
+	assert.Equal(t, Version, v)
+	assert.Equal(t, Tagged, typePrefix)
+	assert.Equal(t, mustDecode(t, tag), data)
+	expanded, err := Expand(compact)
+	require.NoError(t, err)
+	assert.Equal(t, tag, expanded)
+	assert.Equal(t, compact, EncodeCompact(Tagged, data))
+
+	// Protocol accepts both forms
+	ciphertext, err := p.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	compact, err = Compact(ciphertext)
+	require.NoError(t, err)
+	data, err = p.Decrypt("keyring", compact)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+
+	// dv1 and every TypePrefix have codes
+	for code := 1; code < len(compactTypePrefixes); code++ {
+		s, err := Compact(EncodeVersion("dv1", compactTypePrefixes[code], []byte("data")))
+		require.NoError(t, err)
+		v, typePrefix, _, err := Decode(s)
+		require.NoError(t, err)
+		assert.Equal(t, "dv1", v)
+		assert.Equal(t, compactTypePrefixes[code], typePrefix)
+	}
+
+	_, err = Compact(EncodeWithFooter(Tagged, []byte("data"), []byte("footer")))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, err = Compact(EncodeVersion("dvtest", Tagged, []byte("data")))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	for _, s := range []string{"", "AA", "8A", "IA", "!!"} {
+		_, _, _, err = Decode(s)
+		assert.True(t, errors.Is(err, ErrInvalidFormat), s)
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	for _, c := range []struct {
+		in, out string
+	}{
+		{`{"b": 1, "a": [true, null, "x"]}`, `{"a":[true,null,"x"],"b":1}`},
+		{`[1.0, 1e0, -0, 0.5, 1e21, 1e-7, 123456789012, 1.5e-7]`, `[1,1,0,0.5,1e+21,1e-7,123456789012,1.5e-7]`},
+		{`"<&> \u0001\b\"\\é"`, `"<&>` + " " + `\u0001\b\"\\é"`},
+		{`{"😀": 1, "דּ": 2}`, `{"` + "\U0001F600" + `":1,"` + "דּ" + `":2}`},
+	} {
+		out, err := CanonicalJSON(json.RawMessage(c.in))
+		require.NoError(t, err)
+		assert.Equal(t, c.out, string(out))
+	}
+}
+
+func TestProtocol_SignJSON(t *testing.T) {
+	p := newProtocol(t)
+
+	type document struct {
+		Name   string  `json:"name"`
+		Amount float64 `json:"amount"`
+		Tags   []string
+	}
+	signature, err := p.SignJSON("keyring", document{Name: "jane", Amount: 10, Tags: []string{"a"}})
+	require.NoError(t, err)
+
+	// the same document after a round trip through another system
+	valid, err := p.VerifyJSON("keyring", json.RawMessage(`{
+		"Tags": ["a"],
+		"amount": 10.00,
+		"name": "jane"
+	}`), signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	valid, err = p.VerifyJSON("keyring", map[string]interface{}{"name": "jane", "amount": 11, "Tags": []string{"a"}}, signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	_, err = p.SignJSON("keyring", json.RawMessage(`{"a": 1e400}`))
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestProtocol_COSE(t *testing.T) {
+	p := newProtocol(t)
+
+	// Sig_structure and protected header as in RFC 9052
+	assert.Equal(t, "a10127", hex.EncodeToString(coseProtected(COSEAlgEdDSA)))
+	assert.Equal(t, "846a5369676e61747572653143a101274047"+hex.EncodeToString([]byte("payload")),
+		hex.EncodeToString(coseSigStructure(coseProtected(COSEAlgEdDSA), []byte("payload"))))
+
+	message, err := p.SignCOSE("keyring", []byte("payload"), []byte("kid-1"))
+	require.NoError(t, err)
+	m, err := ParseCOSE(message)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(COSETagSign1), m.Tag)
+	assert.Equal(t, int64(COSEAlgEdDSA), m.Algorithm)
+	assert.Equal(t, []byte("kid-1"), m.KeyID)
+	assert.Equal(t, []byte("payload"), m.Content)
+
+	payload, valid, err := p.VerifyCOSE("keyring", message)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	assert.Equal(t, []byte("payload"), payload)
+
+	// verification with the plain Ed25519 signature over the Sig_structure,
+	// like any COSE implementation does
+	publicKey, err := p.CreateSignKey("keyring")
+	require.NoError(t, err)
+	coseKey, err := MarshalCOSEKey(publicKey)
+	require.NoError(t, err)
+	parsedKey, err := ParseCOSEKey(coseKey)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(parsedKey, coseSigStructure(m.protected, m.Content), m.Signature))
+	_, valid, err = p.VerifyCOSEPK(parsedKey, message)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, valid, err = p.VerifyCOSE("other", message)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	message, err = p.EncryptCOSE("keyring", []byte("data"), nil)
+	require.NoError(t, err)
+	m, err = ParseCOSE(message)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(COSETagEncrypt0), m.Tag)
+	assert.Len(t, m.IV, 12)
+	assert.Nil(t, m.KeyID)
+	data, err := p.DecryptCOSE("keyring", message)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	_, err = p.DecryptCOSE("other", message)
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = p.DecryptCOSE("keyring", message[:len(message)-1])
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+	_, _, err = p.VerifyCOSE("keyring", message)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+}
+
+func TestProtocol_SignSSH(t *testing.T) {
+	p := newProtocol(t)
+	message := []byte("release artifact")
+
+	signature, err := p.SignSSH("ci", "file", message)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(signature), "-----BEGIN SSH SIGNATURE-----\n"))
+
+	valid, err := p.VerifySSH("ci", "file", message, signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = p.VerifySSH("ci", "git", message, signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+	valid, err = p.VerifySSH("other", "file", message, signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+	valid, err = p.VerifySSH("ci", "file", []byte("modified"), signature)
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	publicKey, err := p.CreateSignKey("ci")
+	require.NoError(t, err)
+	authorizedKey, err := MarshalSSHPublicKey(publicKey)
+	require.NoError(t, err)
+	parsed, err := ParseSSHPublicKey(authorizedKey + " ci@example")
+	require.NoError(t, err)
+	assert.Equal(t, ed25519.PublicKey(publicKey), parsed)
+
+	sshKeygen, err := exec.LookPath("ssh-keygen")
+	if err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+	dir := t.TempDir()
+
+	// dvx -> ssh-keygen
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "allowed_signers"), []byte("ci@example "+authorizedKey+"\n"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "msg.sig"), signature, 0600))
+	cmd := exec.Command(sshKeygen, "-Y", "verify", "-f", filepath.Join(dir, "allowed_signers"), "-I", "ci@example", "-n", "file", "-s", filepath.Join(dir, "msg.sig"))
+	cmd.Stdin = bytes.NewReader(message)
+	out, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	// ssh-keygen -> dvx
+	key := filepath.Join(dir, "id_ed25519")
+	out, err = exec.Command(sshKeygen, "-q", "-t", "ed25519", "-N", "", "-f", key).CombinedOutput()
+	require.NoError(t, err, string(out))
+	cmd = exec.Command(sshKeygen, "-Y", "sign", "-f", key, "-n", "file")
+	cmd.Stdin = bytes.NewReader(message)
+	signature, err = cmd.Output()
+	require.NoError(t, err)
+	line, err := os.ReadFile(key + ".pub")
+	require.NoError(t, err)
+	publicKey, err = ParseSSHPublicKey(string(line))
+	require.NoError(t, err)
+	valid, err = p.VerifySSHPK(publicKey, "file", message, signature)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}
+
+type slowPool struct {
+	KeyPool
+	delay time.Duration
+}
+
+func (s slowPool) KDF32Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	select {
+	case <-time.After(s.delay):
+		return s.KDF32(keyRing)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s slowPool) KDF64Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	return s.KDF64(keyRing)
+}
+
+func TestProtocol_SetLatencyBudget(t *testing.T) {
+	type exceeded struct {
+		category, operation string
+	}
+	var calls []exceeded
+	budget := &LatencyBudget{
+		KDF:    20 * time.Millisecond,
+		AEAD:   20 * time.Millisecond,
+		MAC:    20 * time.Millisecond,
+		Logger: testLogger{t},
+		OnExceeded: func(category string, operation string, duration time.Duration, budget time.Duration) {
+			assert.Greater(t, int64(duration), int64(budget))
+			calls = append(calls, exceeded{category, operation})
+		},
+	}
+	pool := newProtocol(t).keys[Version]
+
+	// operations within their budget aren't reported
+	p := NewProtocol(map[string]KeyPool{Version: slowPool{pool, 0}})
+	p.SetLatencyBudget(budget)
+	_, err := p.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+
+	// slow key derivations are reported with their operation
+	p = NewProtocol(map[string]KeyPool{Version: slowPool{pool, 50 * time.Millisecond}})
+	p.SetLatencyBudget(budget)
+	_, err = p.Encrypt("keyring", []byte("data"))
+	require.NoError(t, err)
+	_, err = p.MAC("keyring", []byte("data"))
+	require.NoError(t, err)
+	assert.Equal(t, []exceeded{{CategoryKDF, "kdf32"}, {CategoryAEAD, OpEncrypt}}, calls)
+	assert.Equal(t, uint64(2), p.Stats().SlowOperations)
+
+	// Abort cancels slow key derivations
+	budget.Abort = true
+	p.SetLatencyBudget(budget)
+	_, err = p.Encrypt("keyring", []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyDerivation))
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	_, err = p.MAC("keyring", []byte("data"))
+	assert.NoError(t, err)
+}
+
+func TestProtocol_SecretsFile(t *testing.T) {
+	p := newProtocol(t)
+	path := filepath.Join(t.TempDir(), "secrets.dvx")
+
+	secrets := map[string]string{"db_password": "hunter2", "api_key": "abc"}
+	require.NoError(t, p.SaveSecretsFile(context.Background(), path, "config/prod", secrets))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "dvxfile v1 dv2 "))
+	assert.NotContains(t, string(content), "hunter2")
+
+	loaded, err := p.LoadSecretsFile(context.Background(), path, "config/prod")
+	require.NoError(t, err)
+	assert.Equal(t, secrets, loaded)
+
+	_, err = p.LoadSecretsFile(context.Background(), path, "config/dev")
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	_, err = newProtocol(t).LoadSecretsFile(context.Background(), path, "config/prod")
+	assert.True(t, errors.Is(err, ErrAuthentication))
+}
+
+func TestProtocol_FileStream(t *testing.T) {
+	p := newProtocol(t)
+
+	data := make([]byte, 2*FileChunkSize+10)
+	_, err := io.ReadFull(rand.Reader, data)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	w, err := p.NewFileWriter(context.Background(), &buf, "files")
+	require.NoError(t, err)
+	_, err = w.Write(data[:100])
+	require.NoError(t, err)
+	_, err = w.Write(data[100:])
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	lines := strings.SplitAfter(buf.String(), "\n")
+	require.Len(t, lines, 5) // header, 3 chunks and the empty string after the last newline
+
+	read := func(content string) ([]byte, error) {
+		r, err := p.NewFileReader(context.Background(), strings.NewReader(content), "files")
+		if err != nil {
+			return nil, err
+		}
+		return io.ReadAll(r)
+	}
+
+	decrypted, err := read(buf.String())
+	require.NoError(t, err)
+	assert.Equal(t, data, decrypted)
+
+	// truncated
+	_, err = read(strings.Join(lines[:3], ""))
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	// reordered
+	_, err = read(lines[0] + lines[2] + lines[1] + lines[3])
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	// extended
+	_, err = read(buf.String() + lines[1])
+	assert.True(t, errors.Is(err, ErrAuthentication))
+	// not a dvxfile
+	_, err = read("secret=value\n")
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	// an empty file consists of the header and an empty last chunk
+	buf.Reset()
+	w, err = p.NewFileWriter(context.Background(), &buf, "files")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	decrypted, err = read(buf.String())
+	require.NoError(t, err)
+	assert.Empty(t, decrypted)
+}
+
+func TestProtocol_Signer(t *testing.T) {
+	p := newProtocol(t)
+
+	signer, err := p.Signer("signer")
+	require.NoError(t, err)
+	publicKey, err := p.CreateSignKey("signer")
+	require.NoError(t, err)
+	assert.Equal(t, ed25519.PublicKey(publicKey), signer.Public())
+
+	sig, err := signer.Sign(rand.Reader, []byte("message"), crypto.Hash(0))
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(publicKey, []byte("message"), sig))
+	valid, err := p.VerifyPK(publicKey, []byte("message"), Encode(Signed, sig))
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	_, err = signer.Sign(rand.Reader, []byte("message"), crypto.SHA256)
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	// certificates of crypto/x509 are signed through the crypto.Signer
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "dvx"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	assert.NoError(t, cert.CheckSignatureFrom(cert))
+}
+
+func TestProtocol_CreateCertificate(t *testing.T) {
+	p := newProtocol(t)
+
+	_, err := p.CreateCertificate("pki/root", &x509.Certificate{}, nil, "")
+	assert.True(t, errors.Is(err, ErrInvalidFormat))
+
+	root, err := p.CreateCertificate("pki/root", &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "root"},
+		NotAfter: time.Now().Add(time.Hour),
+		IsCA:     true,
+	}, nil, "")
+	require.NoError(t, err)
+	assert.NoError(t, root.CheckSignatureFrom(root))
+
+	leaf, err := p.CreateCertificate("services/api", &x509.Certificate{
+		Subject:     pkix.Name{CommonName: "api"},
+		DNSNames:    []string{"api.internal"},
+		NotAfter:    time.Now().Add(time.Hour),
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, root, "pki/root")
+	require.NoError(t, err)
+	publicKey, err := p.CreateSignKey("services/api")
+	require.NoError(t, err)
+	assert.Equal(t, ed25519.PublicKey(publicKey), leaf.PublicKey)
+
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	_, err = leaf.Verify(x509.VerifyOptions{DNSName: "api.internal", Roots: roots})
+	assert.NoError(t, err)
+
+	_, err = p.CreateCertificate("services/api", &x509.Certificate{NotAfter: time.Now().Add(time.Hour)}, root, "pki/other")
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	// TLS handshake with the derived key of the leaf certificate
+	cert, err := p.TLSCertificate("services/api", leaf)
+	require.NoError(t, err)
+	_, err = p.TLSCertificate("services/other", leaf)
+	assert.True(t, errors.Is(err, ErrInvalidKey))
+
+	clientConn, serverConn := net.Pipe()
+	defer clientConn.Close()
+	defer serverConn.Close()
+	server := tls.Server(serverConn, &tls.Config{Certificates: []tls.Certificate{cert}})
+	go func() { _ = server.Handshake() }()
+	client := tls.Client(clientConn, &tls.Config{RootCAs: roots, ServerName: "api.internal"})
+	require.NoError(t, client.Handshake())
+	assert.Equal(t, leaf.Raw, client.ConnectionState().PeerCertificates[0].Raw)
+}
+
+func TestProtocol_DescribeDerivation(t *testing.T) {
+	p := newProtocol(t)
+
+	_, err := p.DescribeDerivation("keyring")
+	assert.Error(t, err)
+
+	p.SetDiagnostics(true)
+	report, err := p.DescribeDerivation("users:" + base64.RawStdEncoding.EncodeToString([]byte{1, 2, 3}))
+	require.NoError(t, err)
+	assert.Equal(t, "base64", report.KeyRingEncoding)
+	assert.Equal(t, 3, report.KeyRingLength)
+	assert.Equal(t, featureVersion, report.Version)
+	assert.Equal(t, "*dvx.dvxWrapper", report.KeyPool)
+
+	// every derivation has its own KeyPool input
+	inputs := map[string]bool{}
+	for _, d := range report.Derivations {
+		input := d.KDF + "|" + d.Label
+		assert.False(t, inputs[input], input)
+		inputs[input] = true
+		assert.Equal(t, d.KDF == "kdf32", d.KeyLength == 32)
+	}
+	assert.Contains(t, report.String(), `kdf32("dv2/kdf32/enc\x00" || keyRing) -> enc (32 bytes)`)
+}
+
+func TestKeyRingFingerprint(t *testing.T) {
+	fp := KeyRingFingerprint("users/42")
+	assert.Len(t, fp, 26)
+	assert.Equal(t, strings.ToLower(fp), fp)
+	assert.Equal(t, fp, KeyRingFingerprint("users/42"))
+	assert.NotEqual(t, fp, KeyRingFingerprint("users/43"))
+
+	// keyRings with equal bytes have equal fingerprints
+	assert.Equal(t, KeyRingFingerprint("a:dG90cA"), KeyRingFingerprint("b:dG90cA"))
+	assert.Equal(t, KeyRingFingerprint("totp"), KeyRingFingerprint("a:dG90cA"))
+
+	// KeyPool inputs are split into label and keyRing
+	label, keyRing := splitKDFInput(kdfInput(featureVersion, "kdf32", purposeEncrypt, []byte("users/42")))
+	assert.Equal(t, "dv2/kdf32/enc", label)
+	assert.Equal(t, fp, fingerprint(keyRing))
+	label, keyRing = splitKDFInput([]byte("users/42"))
+	assert.Empty(t, label)
+	assert.Equal(t, fp, fingerprint(keyRing))
+}
+
+func TestProtocol_SetKeyRingPolicy(t *testing.T) {
+	p := newProtocol(t)
+	p.SetKeyRingPolicy(AllKeyRingPolicies(
+		MaxKeyRingLength(32),
+		KeyRingPrefixes("users/", "payments/"),
+		KeyRingTenantSegment(1, func(tenant string) bool { return strings.HasPrefix(tenant, "t-") }),
+	))
+
+	cipher, err := p.Encrypt("users/t-1/email", []byte("data"))
+	require.NoError(t, err)
+	_, err = p.Decrypt("users/t-1/email", cipher)
+	require.NoError(t, err)
+
+	for _, keyRing := range []string{
+		"",
+		"free-form",
+		"users",
+		"users//email",
+		"users/1/email",
+		"payments/t-1/" + strings.Repeat("a", 32),
+	} {
+		_, err = p.Encrypt(keyRing, []byte("data"))
+		assert.True(t, errors.Is(err, ErrKeyRingPolicy), keyRing)
+		_, err = p.Decrypt(keyRing, cipher)
+		assert.True(t, errors.Is(err, ErrKeyRingPolicy), keyRing)
+		_, err = p.MAC(keyRing, []byte("data"))
+		assert.True(t, errors.Is(err, ErrKeyRingPolicy), keyRing)
+	}
+
+	// rejected keyRings never reach the KeyPool
+	assert.Equal(t, uint64(2), p.Stats().KDFCalls)
+	assert.Equal(t, uint64(18), p.Stats().Failures["key_ring_policy"])
+
+	// "label:base64" keyRings are validated after decoding
+	labeled := func(label, keyRing string) string {
+		return label + ":" + base64.RawStdEncoding.EncodeToString([]byte(keyRing))
+	}
+	data, err := p.Decrypt(labeled("x", "users/t-1/email"), cipher)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	for _, keyRing := range []string{
+		labeled("users/t-1", "free-form"),
+		labeled("users/t-1/email", "users/1/email"),
+		labeled("users/t-1", "users/t-1/"+strings.Repeat("a", 32)),
+	} {
+		_, err = p.Encrypt(keyRing, []byte("data"))
+		assert.True(t, errors.Is(err, ErrKeyRingPolicy), keyRing)
+	}
+}
+
+func TestProtocol_SetKeyUsagePolicy(t *testing.T) {
+	p := newProtocol(t)
+	assert.Error(t, p.SetKeyUsagePolicy(KeyUsagePolicy{"audit/": {"hash"}}))
+	require.NoError(t, p.SetKeyUsagePolicy(KeyUsagePolicy{
+		"audit/":      {OpMAC},
+		"audit/logs/": {OpSign, OpCreateSignKey},
+	}))
+
+	_, err := p.MAC("audit/events", []byte("data"))
+	require.NoError(t, err)
+	_, err = p.Encrypt("audit/events", []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+	_, err = p.Tokenize("audit/events", "data")
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+	_, err = p.NewFileWriter(context.Background(), io.Discard, "audit/events")
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+
+	// the longest prefix applies
+	_, _, err = p.Sign("audit/logs/1", []byte("data"))
+	require.NoError(t, err)
+	_, err = p.MAC("audit/logs/1", []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+
+	_, err = p.Encrypt("users/1", []byte("data"))
+	require.NoError(t, err)
+
+	// rejected operations never reach the KeyPool
+	assert.Equal(t, uint64(3), p.Stats().KDFCalls)
+
+	// prefixes are matched against the decoded keyRing
+	_, err = p.Encrypt("x:"+base64.RawStdEncoding.EncodeToString([]byte("audit/foo")), []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+	_, _, err = p.Sign("audit/logs/1:"+base64.RawStdEncoding.EncodeToString([]byte("audit/foo")), []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+}
+
+func TestProtocol_RequireEncryptionContext(t *testing.T) {
+	p := newProtocol(t)
+	legacy, err := p.Encrypt("tenants/a", []byte("data"))
+	require.NoError(t, err)
+	p.RequireEncryptionContext("tenants/", "payments/")
+
+	cipher, err := p.EncryptWithFooter("tenants/a", []byte("data"), []byte("users/42"))
+	require.NoError(t, err)
+	data, err := p.Decrypt("tenants/a", cipher)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), data)
+	_, err = p.Decrypt("tenants/a", legacy)
+	assert.NoError(t, err)
+
+	_, err = p.EncryptWithFooter("tenants/a", []byte("data"), nil)
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+	_, err = p.Encrypt("payments/b", []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+	_, err = p.EncryptNotBefore("tenants/a", []byte("data"), time.Now())
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+	_, err = p.EncryptCOSE("tenants/a", []byte("data"), nil)
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+
+	// prefixes are matched against the decoded keyRing
+	_, err = p.Encrypt("x:"+base64.RawStdEncoding.EncodeToString([]byte("payments/b")), []byte("data"))
+	assert.True(t, errors.Is(err, ErrKeyRingPolicy))
+	_, err = p.Encrypt("payments/b:"+base64.RawStdEncoding.EncodeToString([]byte("users/a")), []byte("data"))
+	assert.NoError(t, err)
+
+	_, err = p.Encrypt("users/a", []byte("data"))
+	assert.NoError(t, err)
+}
@@ -0,0 +1,205 @@
+package dvx
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// MaxRatchetSkip is the maximum amount of message keys Ratchet.DecryptAt
+// derives ahead of the current index of a Ratchet. It limits the work an
+// attacker controlled index can cause. Receivers that join a stream late
+// call Ratchet.Advance until they reach it.
+const MaxRatchetSkip = 1 << 16
+
+// Ratchet derives a chain of per-message keys (KDF chain) from a single key
+// derived for a base keyRing, so long-lived streams of messages don't encrypt
+// all of them with the same key. Message i is encrypted with the message key
+// of index i, and every step of the chain discards (overwrites) the chain key
+// it was derived from. Therefore, a Ratchet whose memory is compromised can't
+// decrypt the messages before its current index (forward secrecy).
+//
+// Note that every Ratchet of the same keyRing starts with the same chain key,
+// so anyone who is able to derive keys from the KeyPool can recreate the
+// whole chain. Forward secrecy only applies to the state of a Ratchet, not to
+// the KeyPool.
+//
+// Sender and receiver each create a Ratchet for the same keyRing. The sender
+// encrypts with EncryptNext and transmits the returned index with the
+// ciphertext, the receiver decrypts with DecryptAt. A Ratchet is safe for
+// concurrent use. The chain key is kept in a SecureBuffer, which Close
+// destroys.
+type Ratchet struct {
+	p       *Protocol
+	version string
+
+	mu       sync.Mutex
+	index    uint64
+	chainKey *SecureBuffer
+}
+
+// errRatchetClosed is returned by a Ratchet after Close.
+var errRatchetClosed = errors.New("dvx: ratchet is closed")
+
+// Constants that separate the derivation of the next chain key from the
+// derivation of a message key.
+var (
+	ratchetChainConstant   = []byte{0x01}
+	ratchetMessageConstant = []byte{0x02}
+)
+
+// NewRatchet derives the chain key of index 0 using the keyRing and returns a
+// Ratchet starting at this index.
+func (p *Protocol) NewRatchet(keyRing string) (*Ratchet, error) {
+	return p.NewRatchetContext(context.Background(), keyRing)
+}
+
+// NewRatchetContext is like NewRatchet, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) NewRatchetContext(ctx context.Context, keyRing string) (*Ratchet, error) {
+	keyRingBytes, err := p.keyRingInput(keyRing, OpEncrypt, OpDecrypt)
+	if err != nil {
+		return nil, err
+	}
+	chainKey, err := p.kdf64(ctx, keyRingBytes, featureVersion, purposeRatchet)
+	if err != nil {
+		return nil, err
+	}
+
+	defer wipe(chainKey)
+
+	return &Ratchet{
+		p:        p,
+		version:  featureVersion,
+		chainKey: secureCopy(chainKey),
+	}, nil
+}
+
+// Index returns the index of the next message key of r. Keys of lower
+// indices are already discarded.
+func (r *Ratchet) Index() uint64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.index
+}
+
+// Advance discards the message key of the current index and moves r to the
+// next one.
+func (r *Ratchet) Advance() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.advance()
+}
+
+// Close destroys the chain key of r. r can't be used afterwards.
+func (r *Ratchet) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.chainKey.Destroy()
+	return nil
+}
+
+// advance overwrites the chain key with the next one. r.mu must be held.
+func (r *Ratchet) advance() error {
+	if r.chainKey.Len() == 0 {
+		return errRatchetClosed
+	}
+	next, err := DV1{}.MAC512(r.chainKey.Bytes(), ratchetChainConstant)
+	if err != nil {
+		return err
+	}
+
+	copy(r.chainKey.Bytes(), next)
+	wipe(next)
+	r.index++
+	return nil
+}
+
+// EncryptNext encrypts data with the message key of the current index and
+// advances r afterwards. The returned index must be passed to DecryptAt.
+func (r *Ratchet) EncryptNext(data []byte) (ciphertext string, index uint64, err error) {
+	defer r.p.done(OpEncrypt, time.Now(), &err)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.chainKey.Len() == 0 {
+		return "", 0, errRatchetClosed
+	}
+	key, err := DV1{}.MAC256(r.chainKey.Bytes(), ratchetMessageConstant)
+	if err != nil {
+		return "", 0, err
+	}
+	defer wipe(key)
+
+	size := len(data)
+	cipher, err := primitiveOf(r.version).Encrypt(key, data)
+	if err != nil {
+		return "", 0, err
+	}
+
+	index = r.index
+	if err := r.advance(); err != nil {
+		return "", 0, err
+	}
+
+	atomic.AddUint64(&r.p.stats.bytesEncrypted, uint64(size))
+	return EncodeVersion(r.version, Encrypted, cipher), index, nil
+}
+
+// DecryptAt decrypts ciphertext with the message key of index. index must not
+// be lower than Index, as those keys are already discarded, or higher than
+// Index plus MaxRatchetSkip. DecryptAt doesn't advance r, so messages between
+// Index and index can still be decrypted afterwards. Call Advance to discard
+// keys of messages that were processed.
+func (r *Ratchet) DecryptAt(index uint64, ciphertext string) (data []byte, err error) {
+	defer r.p.done(OpDecrypt, time.Now(), &err)
+
+	v, cipher, err := DecodeExpect(ciphertext, Encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if v != r.version {
+		return nil, errorf(ErrInvalidFormat, "dvx: ratchet of %s can't decrypt %s ciphers", r.version, v)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch {
+	case index < r.index:
+		return nil, errorf(ErrInvalidKey, "dvx: ratchet key of index %d is already discarded (current index %d)", index, r.index)
+	case index-r.index > MaxRatchetSkip:
+		return nil, errorf(ErrInvalidKey, "dvx: ratchet index %d is more than %d ahead of current index %d", index, MaxRatchetSkip, r.index)
+	}
+
+	if r.chainKey.Len() == 0 {
+		return nil, errRatchetClosed
+	}
+	chainKey := append([]byte{}, r.chainKey.Bytes()...)
+	defer func() { wipe(chainKey) }()
+	for i := r.index; i < index; i++ {
+		next, err := DV1{}.MAC512(chainKey, ratchetChainConstant)
+		if err != nil {
+			return nil, err
+		}
+		wipe(chainKey)
+		chainKey = next
+	}
+
+	key, err := DV1{}.MAC256(chainKey, ratchetMessageConstant)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+
+	data, err = primitiveOf(r.version).Decrypt(key, cipher)
+	if err != nil {
+		return nil, err
+	}
+
+	atomic.AddUint64(&r.p.stats.bytesDecrypted, uint64(len(data)))
+	return data, nil
+}
@@ -0,0 +1,113 @@
+package dvx
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+	"sync/atomic"
+)
+
+// PrimitiveOptions configures a Primitive registered with RegisterPrimitive.
+// The zero value is valid.
+type PrimitiveOptions struct {
+	// SignKey converts the 32 byte seed derived for the purpose "sig" into
+	// the private key passed to Primitive.Sign and the public key passed to
+	// Primitive.Verify. Nil uses Ed25519 keys, like DV1 and DV2.
+	SignKey func(seed []byte) (privateKey []byte, publicKey []byte, err error)
+	// SelfTest runs known-answer tests of the Primitive for
+	// Protocol.SelfTest. Nil only tests the KeyPool of the version.
+	SelfTest func(primitive Primitive) error
+}
+
+// registeredPrimitive is a version of the registry.
+type registeredPrimitive struct {
+	primitive Primitive
+	options   PrimitiveOptions
+}
+
+var (
+	// registry maps every supported version to its registeredPrimitive
+	// (map[string]*registeredPrimitive). RegisterPrimitive replaces the map
+	// instead of changing it, so it is read without locks.
+	registry atomic.Value
+	// registryMu serializes RegisterPrimitive calls.
+	registryMu sync.Mutex
+
+	// versionPattern restricts version names to characters that are neither
+	// part of the encoding ('.') nor of the labels of key derivations ('/'
+	// and zero bytes).
+	versionPattern = regexp.MustCompile(`^[a-z][a-z0-9]{1,15}$`)
+)
+
+func init() {
+	registry.Store(map[string]*registeredPrimitive{
+		"dv1": {primitive: DV1{}},
+		"dv2": {primitive: DV2{}},
+	})
+}
+
+// RegisterPrimitive registers the out-of-tree Primitive p under version (e.g.
+// an organization specific variant using national cryptographic standards),
+// so Decode accepts strings of version, EncodeVersion encodes them and
+// Protocol decrypts and verifies them with p. Like DV2, keys of version are
+// derived for inputs labeled with version, kdf and purpose (see
+// DescribeDerivation), from the KeyPool passed to NewProtocol for version.
+// Protocol still encrypts, signs and tags with Version.
+//
+// version must consist of 2 to 16 lower-case letters and digits, starting
+// with a letter. The built-in versions dv1 and dv2 and versions that are
+// already registered can't be registered (again). Ciphers of registered
+// versions don't support footers (see EncodeWithFooter).
+// RegisterPrimitive is safe for concurrent use, but should be called from an
+// init function, before any string of version is decoded.
+func RegisterPrimitive(version string, p Primitive, opts *PrimitiveOptions) error {
+	if !versionPattern.MatchString(version) {
+		return fmt.Errorf("dvx: version %q must consist of 2 to 16 lower-case letters and digits, starting with a letter", version)
+	}
+	if p == nil {
+		return errors.New("dvx: Primitive must not be nil")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	current := registry.Load().(map[string]*registeredPrimitive)
+	if _, ok := current[version]; ok {
+		return fmt.Errorf("dvx: version %q is already registered", version)
+	}
+
+	next := make(map[string]*registeredPrimitive, len(current)+1)
+	for v, r := range current {
+		next[v] = r
+	}
+	next[version] = &registeredPrimitive{primitive: p}
+	if opts != nil {
+		next[version].options = *opts
+	}
+	registry.Store(next)
+	return nil
+}
+
+// lookupVersion returns the registeredPrimitive of version, or nil if version
+// isn't supported.
+func lookupVersion(version string) *registeredPrimitive {
+	return registry.Load().(map[string]*registeredPrimitive)[version]
+}
+
+// primitiveOf returns the Primitive of version, which must be supported (e.g.
+// returned by Decode).
+func primitiveOf(version string) Primitive {
+	return lookupVersion(version).primitive
+}
+
+// signKeyFromSeed returns the key pair of version for seed.
+func signKeyFromSeed(version string, seed []byte) (privateKey []byte, publicKey []byte, err error) {
+	if r := lookupVersion(version); r != nil && r.options.SignKey != nil {
+		return r.options.SignKey(seed)
+	}
+
+	privateKey = ed25519.NewKeyFromSeed(seed)
+	return privateKey, ed25519.PrivateKey(privateKey).Public().(ed25519.PublicKey), nil
+}
@@ -0,0 +1,187 @@
+package dvx
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/binary"
+	"time"
+)
+
+const (
+	// totpIDSize is the size of the random raw-id of TOTP ids (see
+	// Protocol.GenerateTOTP).
+	totpIDSize = 32
+	// revocationTagSize is the size of the MAC of TOTP revocations.
+	revocationTagSize = 32
+	// revocationHeaderSize is the size of the revocation time and raw-id in
+	// front of the tag.
+	revocationHeaderSize = 8 + totpIDSize
+)
+
+// TOTPRevocation is a TOTP revocation verified by
+// Protocol.VerifyTOTPRevocation.
+type TOTPRevocation struct {
+	// ID is the revoked totp-id.
+	ID string
+	// RevokedAt is the time the revocation was issued, with a resolution of
+	// seconds.
+	RevokedAt time.Time
+}
+
+// TOTPRevocationCheck returns the revocation of RevokeTOTP stored for the
+// totp-id id, or an empty string if id wasn't revoked (see
+// Protocol.SetTOTPRevocationCheck). Errors abort the verification of the code.
+type TOTPRevocationCheck func(ctx context.Context, id string) (revocation string, err error)
+
+// SetTOTPRevocationCheck sets the TOTPRevocationCheck of p. VerifyTOTP calls it
+// before verifying a code and fails with ErrRevoked if it returns a valid
+// revocation for the totp-id, so disabling 2FA is represented by a verifiable
+// state instead of only a deleted database row. Revocations that weren't
+// issued under the keyRing for the totp-id fail with ErrAuthentication.
+// SetTOTPRevocationCheck must be called before p is used.
+func (p *Protocol) SetTOTPRevocationCheck(check TOTPRevocationCheck) {
+	p.totpRevocation = check
+}
+
+// RevokeTOTP derives a secret key `sk` using the keyRing and issues a
+// revocation of the totp-id id (see GenerateTOTP). The revocation contains the
+// raw-id and the time of the revocation, authenticated with a keyed MAC. It
+// should be stored next to the id (e.g. as soft-delete marker of the row), so
+// VerifyTOTP with a TOTPRevocationCheck rejects the id, even if a restored
+// backup or a manipulated row still contains it.
+func (p *Protocol) RevokeTOTP(keyRing string, id string) (revocation string, err error) {
+	return p.RevokeTOTPContext(context.Background(), keyRing, id)
+}
+
+// RevokeTOTPContext is like RevokeTOTP, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) RevokeTOTPContext(ctx context.Context, keyRing string, id string) (revocation string, err error) {
+	defer p.done(OpRevokeTOTP, time.Now(), &err)
+
+	_, rawID, err := DecodeExpect(id, TOTP)
+	if err != nil {
+		return "", err
+	}
+	if len(rawID) != totpIDSize {
+		return "", errorf(ErrInvalidFormat, "dvx: totp-id has invalid length (%d). Expected %d", len(rawID), totpIDSize)
+	}
+	now, err := p.now(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpRevokeTOTP)
+	if err != nil {
+		return "", err
+	}
+	key, err := p.kdf64(ctx, keyRingBytes, featureVersion, purposeTOTPRevocation)
+	if err != nil {
+		return "", err
+	}
+
+	data := make([]byte, 8, revocationHeaderSize+revocationTagSize)
+	binary.BigEndian.PutUint64(data, uint64(now.Unix()))
+	data = append(data, rawID...)
+
+	data, err = appendRevocationTag(data, featureVersion, key)
+	if err != nil {
+		return "", err
+	}
+	return EncodeVersion(featureVersion, TOTPRevoked, data), nil
+}
+
+// VerifyTOTPRevocation derives a secret key `sk` using the keyRing and verifies
+// that revocation was issued by RevokeTOTP for the totp-id id. It fails with
+// ErrAuthentication if revocation wasn't issued under keyRing, was changed or
+// revokes a different id.
+func (p *Protocol) VerifyTOTPRevocation(keyRing string, id string, revocation string) (*TOTPRevocation, error) {
+	return p.VerifyTOTPRevocationContext(context.Background(), keyRing, id, revocation)
+}
+
+// VerifyTOTPRevocationContext is like VerifyTOTPRevocation, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) VerifyTOTPRevocationContext(ctx context.Context, keyRing string, id string, revocation string) (rev *TOTPRevocation, err error) {
+	defer p.done(OpVerifyTOTPRevocation, time.Now(), &err)
+
+	_, rawID, err := DecodeExpect(id, TOTP)
+	if err != nil {
+		return nil, err
+	}
+	keyRingBytes, err := p.keyRingInput(keyRing, OpVerifyTOTPRevocation)
+	if err != nil {
+		return nil, err
+	}
+	return p.verifyTOTPRevocation(ctx, keyRingBytes, id, rawID, revocation)
+}
+
+// verifyTOTPRevocation verifies revocation for the raw-id of id.
+func (p *Protocol) verifyTOTPRevocation(ctx context.Context, keyRing []byte, id string, rawID []byte, revocation string) (*TOTPRevocation, error) {
+	v, data, err := DecodeExpect(revocation, TOTPRevoked)
+	if err != nil {
+		return nil, err
+	}
+	if v == "dv1" {
+		return nil, errorf(ErrInvalidFormat, "dvx: dv1 doesn't support totp revocations")
+	}
+	if len(data) != revocationHeaderSize+revocationTagSize {
+		return nil, errorf(ErrInvalidFormat, "%s: totp revocation has invalid length (%d). Expected %d", v, len(data), revocationHeaderSize+revocationTagSize)
+	}
+
+	key, err := p.kdf64(ctx, keyRing, v, purposeTOTPRevocation)
+	if err != nil {
+		return nil, err
+	}
+
+	// the tag is recomputed over the raw-id of id, so revocations of other
+	// ids never verify
+	body := make([]byte, 8, revocationHeaderSize+revocationTagSize)
+	copy(body, data)
+	body = append(body, rawID...)
+	expected, err := appendRevocationTag(body, v, key)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(expected, data) != 1 {
+		return nil, errorf(ErrAuthentication, "%s: totp revocation verification failed", v)
+	}
+
+	return &TOTPRevocation{
+		ID:        id,
+		RevokedAt: time.Unix(int64(binary.BigEndian.Uint64(data)), 0),
+	}, nil
+}
+
+// checkTOTPRevocation calls the TOTPRevocationCheck of p (if any) for id and
+// fails with ErrRevoked if it returns a valid revocation.
+func (p *Protocol) checkTOTPRevocation(ctx context.Context, keyRing []byte, id string, rawID []byte) error {
+	if p.totpRevocation == nil {
+		return nil
+	}
+	revocation, err := p.totpRevocation(ctx, id)
+	if err != nil {
+		return err
+	}
+	if revocation == "" {
+		return nil
+	}
+
+	rev, err := p.verifyTOTPRevocation(ctx, keyRing, id, rawID, revocation)
+	if err != nil {
+		return err
+	}
+	return errorf(ErrRevoked, "dvx: totp-id revoked at %s", rev.RevokedAt.UTC().Format(time.RFC3339))
+}
+
+// appendRevocationTag appends the MAC of a TOTP revocation to data. Like
+// appendOneTimeTag, the MAC is calculated over the version and TypePrefix
+// followed by data.
+func appendRevocationTag(data []byte, version string, key []byte) ([]byte, error) {
+	msg := make([]byte, 0, len(version)+len(TOTPRevoked)+len(data))
+	msg = append(append(append(msg, version...), TOTPRevoked...), data...)
+
+	tag, err := primitiveOf(version).MAC256(key, msg)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, tag...), nil
+}
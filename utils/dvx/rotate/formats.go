@@ -0,0 +1,126 @@
+package rotate
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// NewCSVSource reads records from CSV rows with the columns "id,token" and an
+// optional third column "key_ring".
+func NewCSVSource(r io.Reader) Source {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	return &csvSource{r: cr}
+}
+
+type csvSource struct {
+	r   *csv.Reader
+	row int
+}
+
+func (s *csvSource) Next() (Record, error) {
+	row, err := s.r.Read()
+	if err != nil {
+		return Record{}, err
+	}
+	s.row++
+	if len(row) < 2 || len(row) > 3 {
+		return Record{}, fmt.Errorf("row %d: expected 2 or 3 columns, got %d", s.row, len(row))
+	}
+
+	record := Record{ID: row[0], Token: row[1]}
+	if len(row) == 3 {
+		record.KeyRing = row[2]
+	}
+	return record, nil
+}
+
+// NewCSVSink writes records as CSV rows "id,token", plus "key_ring" for
+// records with a KeyRing.
+func NewCSVSink(w io.Writer) Sink {
+	return &csvSink{w: csv.NewWriter(w)}
+}
+
+type csvSink struct {
+	w *csv.Writer
+}
+
+func (s *csvSink) Write(record Record) error {
+	row := []string{record.ID, record.Token}
+	if record.KeyRing != "" {
+		row = append(row, record.KeyRing)
+	}
+	return s.w.Write(row)
+}
+
+func (s *csvSink) Flush() error {
+	s.w.Flush()
+	return s.w.Error()
+}
+
+// NewNDJSONSource reads records from newline delimited JSON objects with the
+// fields "id", "token" and optionally "key_ring".
+func NewNDJSONSource(r io.Reader) Source {
+	return &ndjsonSource{d: json.NewDecoder(r)}
+}
+
+type ndjsonSource struct {
+	d *json.Decoder
+}
+
+func (s *ndjsonSource) Next() (record Record, err error) {
+	err = s.d.Decode(&record)
+	return
+}
+
+// NewNDJSONSink writes records as newline delimited JSON objects.
+func NewNDJSONSink(w io.Writer) Sink {
+	bw := bufio.NewWriter(w)
+	return &ndjsonSink{w: bw, e: json.NewEncoder(bw)}
+}
+
+type ndjsonSink struct {
+	w *bufio.Writer
+	e *json.Encoder
+}
+
+func (s *ndjsonSink) Write(record Record) error {
+	return s.e.Encode(&record)
+}
+
+func (s *ndjsonSink) Flush() error {
+	return s.w.Flush()
+}
+
+// NewFileCheckpoint stores the progress in the file at path.
+func NewFileCheckpoint(path string) Checkpoint {
+	return fileCheckpoint(path)
+}
+
+type fileCheckpoint string
+
+func (f fileCheckpoint) Load() (int64, error) {
+	buf, err := os.ReadFile(string(f))
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(buf)), 10, 64)
+}
+
+func (f fileCheckpoint) Save(processed int64) error {
+	// write to a temporary file first, so the checkpoint is never corrupted
+	tmp := string(f) + ".tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(processed, 10)+"\n"), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, string(f))
+}
@@ -0,0 +1,193 @@
+// Package rotate re-encrypts dvx ciphertexts from one Protocol to another,
+// e.g. after the root key of a KeyPool was replaced. It reads records from a
+// Source, re-encrypts their tokens and writes them to a Sink. Progress can be
+// saved with a Checkpoint, so an interrupted rotation continues where it
+// stopped.
+package rotate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"azoo.dev/utils/dvx"
+)
+
+// Record is a single ciphertext that should be rotated.
+type Record struct {
+	// ID identifies the record in the caller's storage. It is passed through
+	// unchanged.
+	ID string `json:"id"`
+	// Token is the dvx ciphertext (see dvx.Protocol.Encrypt).
+	Token string `json:"token"`
+	// KeyRing is the keyRing the token was encrypted with. If empty
+	// Config.KeyRing is used.
+	KeyRing string `json:"key_ring,omitempty"`
+}
+
+// Source provides the records to rotate. Next returns io.EOF after the last
+// record.
+type Source interface {
+	Next() (Record, error)
+}
+
+// Sink receives every rotated record. If a Sink buffers records it should
+// implement Flusher, which is called before every checkpoint.
+type Sink interface {
+	Write(record Record) error
+}
+
+// Flusher is implemented by Sinks that buffer records.
+type Flusher interface {
+	Flush() error
+}
+
+// Checkpoint persists the amount of records already processed.
+type Checkpoint interface {
+	// Load returns the amount of processed records of a previous run, or zero
+	// if there is none.
+	Load() (processed int64, err error)
+	// Save persists the amount of processed records. All records written to
+	// the Sink before must be durable when Save is called.
+	Save(processed int64) error
+}
+
+// Config provides all options for Run.
+type Config struct {
+	// From is the Protocol that decrypts the existing tokens (old root).
+	From dvx.Crypto
+	// To is the Protocol that encrypts the new tokens (newest root).
+	To dvx.Crypto
+	// KeyRing is used for all records without their own KeyRing.
+	KeyRing string
+	// DryRun only decrypts and re-encrypts every record, without writing it to
+	// the Sink or saving checkpoints. It can be used to verify that all tokens
+	// can be rotated.
+	DryRun bool
+	// Checkpoint saves the progress. If nil, no progress is saved and every
+	// run starts with the first record.
+	Checkpoint Checkpoint
+	// CheckpointEvery is the amount of records after which the progress is
+	// saved. For example: 1000
+	CheckpointEvery int64
+	// OnError is called for every record that can't be rotated. If it returns
+	// nil the record is skipped, otherwise Run stops and returns the error. If
+	// OnError is nil, Run stops at the first failing record.
+	OnError func(record Record, err error) error
+}
+
+// Result summarizes a Run.
+type Result struct {
+	// Skipped is the amount of records skipped because of the Checkpoint.
+	Skipped int64
+	// Rotated is the amount of records rotated in this run.
+	Rotated int64
+	// Failed is the amount of records that couldn't be rotated and were
+	// skipped by Config.OnError.
+	Failed int64
+}
+
+// Token re-encrypts a single token from Protocol `from` to Protocol `to`.
+func Token(ctx context.Context, from dvx.Crypto, to dvx.Crypto, keyRing string, token string) (string, error) {
+	data, err := from.DecryptContext(ctx, keyRing, token)
+	if err != nil {
+		return "", err
+	}
+	return to.EncryptContext(ctx, keyRing, data)
+}
+
+// Run rotates all records of source and writes them to sink. It stops as soon
+// as ctx is done and saves the progress of all records handled until then.
+func Run(ctx context.Context, source Source, sink Sink, config *Config) (result *Result, err error) {
+	if config.From == nil || config.To == nil {
+		return nil, fmt.Errorf("rotate: From and To must be set")
+	}
+
+	result = &Result{}
+
+	var skip int64
+	if config.Checkpoint != nil && !config.DryRun {
+		skip, err = config.Checkpoint.Load()
+		if err != nil {
+			return nil, fmt.Errorf("rotate: unable to load checkpoint: %w", err)
+		}
+	}
+
+	var processed int64
+	save := func() error {
+		if config.Checkpoint == nil || config.DryRun {
+			return nil
+		}
+		if f, ok := sink.(Flusher); ok {
+			if err := f.Flush(); err != nil {
+				return fmt.Errorf("rotate: unable to flush sink: %w", err)
+			}
+		}
+		if err := config.Checkpoint.Save(processed); err != nil {
+			return fmt.Errorf("rotate: unable to save checkpoint: %w", err)
+		}
+		return nil
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return result, firstError(err, save())
+		}
+
+		record, err := source.Next()
+		if errors.Is(err, io.EOF) {
+			return result, save()
+		}
+		if err != nil {
+			return result, firstError(fmt.Errorf("rotate: unable to read record: %w", err), save())
+		}
+
+		if processed < skip {
+			processed++
+			result.Skipped++
+			continue
+		}
+
+		keyRing := record.KeyRing
+		if keyRing == "" {
+			keyRing = config.KeyRing
+		}
+
+		token, err := Token(ctx, config.From, config.To, keyRing, record.Token)
+		if err != nil {
+			err = fmt.Errorf("rotate: record %q: %w", record.ID, err)
+			if config.OnError == nil {
+				return result, firstError(err, save())
+			}
+			if err := config.OnError(record, err); err != nil {
+				return result, firstError(err, save())
+			}
+			result.Failed++
+		} else {
+			if !config.DryRun {
+				record.Token = token
+				if err := sink.Write(record); err != nil {
+					return result, firstError(fmt.Errorf("rotate: unable to write record %q: %w", record.ID, err), save())
+				}
+			}
+			result.Rotated++
+		}
+
+		processed++
+		if config.CheckpointEvery > 0 && processed%config.CheckpointEvery == 0 {
+			if err := save(); err != nil {
+				return result, err
+			}
+		}
+	}
+}
+
+func firstError(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package rotate
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+)
+
+func newProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, dvx.NopLogger)})
+}
+
+type sliceSink []Record
+
+func (s *sliceSink) Write(record Record) error {
+	*s = append(*s, record)
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	from, to := newProtocol(t), newProtocol(t)
+
+	var input bytes.Buffer
+	sink := NewNDJSONSink(&input)
+	for _, id := range []string{"a", "b", "c"} {
+		token, err := from.Encrypt("keyring", []byte("data-"+id))
+		require.NoError(t, err)
+		require.NoError(t, sink.Write(Record{ID: id, Token: token}))
+	}
+	require.NoError(t, sink.(Flusher).Flush())
+
+	var out sliceSink
+	result, err := Run(context.Background(), NewNDJSONSource(bytes.NewReader(input.Bytes())), &out, &Config{From: from, To: to, KeyRing: "keyring"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Rotated)
+	require.Len(t, out, 3)
+
+	for _, r := range out {
+		data, err := to.Decrypt("keyring", r.Token)
+		require.NoError(t, err)
+		assert.Equal(t, "data-"+r.ID, string(data))
+
+		_, err = from.Decrypt("keyring", r.Token)
+		assert.Error(t, err)
+	}
+
+	// dry run doesn't write anything
+	out = nil
+	result, err = Run(context.Background(), NewNDJSONSource(bytes.NewReader(input.Bytes())), &out, &Config{From: from, To: to, KeyRing: "keyring", DryRun: true})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), result.Rotated)
+	assert.Empty(t, out)
+}
+
+func TestRun_CheckpointAndErrors(t *testing.T) {
+	from, to := newProtocol(t), newProtocol(t)
+
+	tokenA, err := from.Encrypt("keyring", []byte("a"))
+	require.NoError(t, err)
+	tokenC, err := from.Encrypt("other", []byte("c"))
+	require.NoError(t, err)
+	input := "a," + tokenA + "\nb,dv1.enc.invalid\nc," + tokenC + ",other\n"
+
+	checkpoint := NewFileCheckpoint(filepath.Join(t.TempDir(), "checkpoint"))
+
+	// stops at the invalid record and saves the progress of record a
+	var out bytes.Buffer
+	result, err := Run(context.Background(), NewCSVSource(strings.NewReader(input)), NewCSVSink(&out), &Config{
+		From: from, To: to, KeyRing: "keyring", Checkpoint: checkpoint, CheckpointEvery: 100,
+	})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, dvx.ErrInvalidFormat))
+	assert.Equal(t, int64(1), result.Rotated)
+	assert.Equal(t, 1, strings.Count(out.String(), "\n"))
+
+	processed, err := checkpoint.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), processed)
+
+	// continues after record a and skips the invalid record
+	var failed []string
+	result, err = Run(context.Background(), NewCSVSource(strings.NewReader(input)), NewCSVSink(&out), &Config{
+		From: from, To: to, KeyRing: "keyring", Checkpoint: checkpoint, CheckpointEvery: 100,
+		OnError: func(record Record, err error) error {
+			failed = append(failed, record.ID)
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Result{Skipped: 1, Rotated: 1, Failed: 1}, *result)
+	assert.Equal(t, []string{"b"}, failed)
+
+	records := NewCSVSource(&out)
+	for _, id := range []string{"a", "c"} {
+		r, err := records.Next()
+		require.NoError(t, err)
+		assert.Equal(t, id, r.ID)
+
+		data, err := to.Decrypt(map[string]string{"a": "keyring", "c": "other"}[id], r.Token)
+		require.NoError(t, err)
+		assert.Equal(t, id, string(data))
+	}
+
+	processed, err = checkpoint.Load()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), processed)
+}
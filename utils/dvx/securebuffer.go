@@ -0,0 +1,180 @@
+package dvx
+
+import (
+	"errors"
+	"io"
+	"runtime"
+)
+
+// SecureBuffer is a fixed size buffer outside of the Go heap for secrets that
+// must be held in plaintext for a short time, like a derived key or the
+// result of Decrypt before it is used. The garbage collector never moves or
+// copies its contents. Where supported (Linux, macOS and the BSDs) the memory
+// is
+//
+//   - locked, so it isn't written to swap (see Locked). Locking fails if it
+//     would exceed RLIMIT_MEMLOCK, in which case the memory is used unlocked.
+//   - surrounded by inaccessible guard pages, so reading or writing past its
+//     end crashes the program instead of leaking or overwriting other memory.
+//     The contents are placed at the end of their pages.
+//
+// On other platforms (e.g. js/wasm) a SecureBuffer is an ordinary slice that
+// is wiped on Destroy.
+//
+// Destroy wipes and releases the memory. Bytes, Reader and Writer must not be
+// used afterwards or concurrently with Destroy. A SecureBuffer that is
+// garbage collected without Destroy is destroyed by a finalizer, but callers
+// should not rely on it.
+type SecureBuffer struct {
+	mem       []byte
+	data      []byte
+	locked    bool
+	destroyed bool
+	// heap is set if secureCopy fell back to the Go heap
+	heap bool
+}
+
+// errSecureBufferDestroyed is returned by readers and writers of a destroyed
+// SecureBuffer.
+var errSecureBufferDestroyed = errors.New("dvx: SecureBuffer is destroyed")
+
+// NewSecureBuffer allocates a SecureBuffer of size bytes, filled with zeros.
+func NewSecureBuffer(size int) (*SecureBuffer, error) {
+	if size < 1 {
+		return nil, errors.New("dvx: size of SecureBuffer must be positive")
+	}
+	mem, data, locked, err := allocateSecure(size)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &SecureBuffer{
+		mem:    mem,
+		data:   data,
+		locked: locked,
+	}
+	runtime.SetFinalizer(b, (*SecureBuffer).Destroy)
+	return b, nil
+}
+
+// NewSecureBufferFrom allocates a SecureBuffer of len(src) bytes, copies src
+// into it and wipes src.
+func NewSecureBufferFrom(src []byte) (*SecureBuffer, error) {
+	b, err := NewSecureBuffer(len(src))
+	if err != nil {
+		return nil, err
+	}
+	copy(b.data, src)
+	wipe(src)
+	return b, nil
+}
+
+// secureCopy is like NewSecureBufferFrom, but doesn't wipe src and falls back
+// to a SecureBuffer on the Go heap if src is empty or the memory can't be
+// allocated.
+func secureCopy(src []byte) *SecureBuffer {
+	if len(src) == 0 {
+		return &SecureBuffer{heap: true}
+	}
+	mem, data, locked, err := allocateSecure(len(src))
+	if err != nil {
+		b := &SecureBuffer{data: append([]byte{}, src...), heap: true}
+		b.mem = b.data
+		return b
+	}
+	copy(data, src)
+
+	b := &SecureBuffer{
+		mem:    mem,
+		data:   data,
+		locked: locked,
+	}
+	runtime.SetFinalizer(b, (*SecureBuffer).Destroy)
+	return b
+}
+
+// Bytes returns the contents of b. The slice is valid until Destroy and must
+// not be appended to or retained afterwards. Bytes returns nil after Destroy.
+func (b *SecureBuffer) Bytes() []byte {
+	return b.data
+}
+
+// Len returns the size of b, or 0 after Destroy.
+func (b *SecureBuffer) Len() int {
+	return len(b.data)
+}
+
+// Locked reports whether the memory of b is locked, so it isn't written to
+// swap.
+func (b *SecureBuffer) Locked() bool {
+	return b.locked
+}
+
+// Wipe overwrites the contents of b with zeros. b can still be used
+// afterwards.
+func (b *SecureBuffer) Wipe() {
+	wipe(b.data)
+}
+
+// Destroy wipes b and releases its memory. Calling Destroy more than once has
+// no effect.
+func (b *SecureBuffer) Destroy() {
+	if b.destroyed {
+		return
+	}
+	b.destroyed = true
+	runtime.SetFinalizer(b, nil)
+
+	wipe(b.data)
+	if !b.heap {
+		freeSecure(b.mem, b.locked)
+	}
+	b.mem, b.data, b.locked = nil, nil, false
+}
+
+// Reader returns an io.Reader of the contents of b, starting at the first
+// byte. It reads directly from the memory of b and doesn't copy it.
+func (b *SecureBuffer) Reader() io.Reader {
+	return &secureReader{b: b}
+}
+
+// Writer returns an io.Writer that overwrites the contents of b, starting at
+// the first byte. Writes beyond the size of b write as much as fits and fail
+// with io.ErrShortBuffer.
+func (b *SecureBuffer) Writer() io.Writer {
+	return &secureWriter{b: b}
+}
+
+type secureReader struct {
+	b   *SecureBuffer
+	off int
+}
+
+func (r *secureReader) Read(p []byte) (int, error) {
+	if r.b.destroyed {
+		return 0, errSecureBufferDestroyed
+	}
+	if r.off >= len(r.b.data) {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b.data[r.off:])
+	r.off += n
+	return n, nil
+}
+
+type secureWriter struct {
+	b   *SecureBuffer
+	off int
+}
+
+func (w *secureWriter) Write(p []byte) (int, error) {
+	if w.b.destroyed {
+		return 0, errSecureBufferDestroyed
+	}
+	n := copy(w.b.data[w.off:], p)
+	w.off += n
+	if n < len(p) {
+		return n, io.ErrShortBuffer
+	}
+	return n, nil
+}
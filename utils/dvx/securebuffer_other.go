@@ -0,0 +1,14 @@
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package dvx
+
+// allocateSecure allocates size bytes on the Go heap, as memory can't be
+// locked or guarded on this platform.
+func allocateSecure(size int) (mem []byte, data []byte, locked bool, err error) {
+	mem = make([]byte, size)
+	return mem, mem, false, nil
+}
+
+// freeSecure has nothing to release on this platform.
+func freeSecure(mem []byte, locked bool) {}
@@ -0,0 +1,43 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+// +build darwin dragonfly freebsd linux netbsd openbsd
+
+package dvx
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// allocateSecure maps size bytes rounded up to whole pages, between two guard
+// pages without access. data is placed at the end of the inner pages, so
+// overflows hit the guard page directly.
+func allocateSecure(size int) (mem []byte, data []byte, locked bool, err error) {
+	page := os.Getpagesize()
+	inner := (size + page - 1) / page * page
+
+	mem, err = syscall.Mmap(-1, 0, inner+2*page, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_ANON|syscall.MAP_PRIVATE)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("dvx: failed to map %d bytes for SecureBuffer: %v", inner+2*page, err)
+	}
+	if err = syscall.Mprotect(mem[:page], syscall.PROT_NONE); err == nil {
+		err = syscall.Mprotect(mem[page+inner:], syscall.PROT_NONE)
+	}
+	if err != nil {
+		_ = syscall.Munmap(mem)
+		return nil, nil, false, fmt.Errorf("dvx: failed to protect guard pages of SecureBuffer: %v", err)
+	}
+
+	// locking fails if RLIMIT_MEMLOCK is exceeded. The memory is still usable
+	locked = syscall.Mlock(mem[page:page+inner]) == nil
+	return mem, mem[page+inner-size : page+inner : page+inner], locked, nil
+}
+
+// freeSecure unlocks and unmaps mem of allocateSecure.
+func freeSecure(mem []byte, locked bool) {
+	page := os.Getpagesize()
+	if locked {
+		_ = syscall.Munlock(mem[page : len(mem)-page])
+	}
+	_ = syscall.Munmap(mem)
+}
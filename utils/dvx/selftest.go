@@ -0,0 +1,162 @@
+package dvx
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/hex"
+	"fmt"
+)
+
+// Known-answer test vectors of DV1 and DV2. All keys are the bytes 0x00,
+// 0x01, ... and the message is selfTestMessage. DV2 only differs in the
+// cipher, as the version is part of its additional data.
+var (
+	selfTestMessage = []byte("dvx self-test")
+
+	dv1MAC256KAT    = mustDecodeHex("0e04db216bc4c87988225b51940c8c7e7bb06c265955eab09f8cd6071381e698")
+	dv1MAC512KAT    = mustDecodeHex("4ab9ce06611d88b221c6444d38c9ac03a11b66e03aeda51cc43d6e808c3ee8b1099f33dbf1561e7ae07ed8d2faf3e19d308d5e9362d0ee67fc312be46b72af97")
+	dv1CipherKAT    = mustDecodeHex("000102030405060708090a0b0c0d0e0f1011121314151617fab4775fe3b7e1c81e3043bdbf896a80beb845ef965fb0bbb244b7f2d3")
+	dv2CipherKAT    = mustDecodeHex("000102030405060708090a0b0c0d0e0f1011121314151617fab4775fe3b7e1c81e3043bdbf54de9ca1dc5b22a270de50ea8a22f07e")
+	dv1SignatureKAT = mustDecodeHex("25f8ea5ef61799b0cdb9dab2b1ad3c3141134b160cd68ea4f38c12a70aa86ea86e4e2a0fdb680b05db8da207565f9ade8bd6d310a11aabc65167f79b1d1e2302")
+)
+
+func mustDecodeHex(s string) []byte {
+	buf, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return buf
+}
+
+// SelfTest checks the entropy of crypto/rand, runs known-answer tests for the
+// Primitive of every version with a registered KeyPool (encrypt/decrypt round
+// trip and decryption of a known cipher, MAC, sign/verify) and checks that
+// every KeyPool is reachable and derives deterministic keys of the correct
+// size. It returns nil if all tests pass, otherwise an error of class
+// ErrSelfTest describing the first failed test.
+//
+// The entropy check fails if reading from crypto/rand fails or blocks for
+// more than a second (e.g. getrandom waiting for the entropy pool of the
+// kernel in a minimal container image), or if the read bytes fail simple
+// statistical tests. Until a later SelfTest passes it, p refuses to generate
+// tokens (GenerateTOTP, IssueOneTimeToken, IssueWebAuthnChallenge and
+// SealSession fail with ErrRandomness).
+//
+// SelfTest is meant for startup gating and readiness probes. It doesn't
+// expose any key material, but every call derives two keys from each KeyPool.
+func (p *Protocol) SelfTest(ctx context.Context) error {
+	if err := p.selfTestEntropy(ctx); err != nil {
+		return err
+	}
+	for version, pool := range p.keys {
+		if pool == nil {
+			return errorf(ErrSelfTest, "dvx: self-test %s: no KeyPool", version)
+		}
+
+		switch version {
+		case "dv1":
+			if err := selfTestPrimitive(DV1{}, dv1CipherKAT); err != nil {
+				return errorf(ErrSelfTest, "dvx: self-test %s: %v", version, err)
+			}
+		case "dv2":
+			if err := selfTestPrimitive(DV2{}, dv2CipherKAT); err != nil {
+				return errorf(ErrSelfTest, "dvx: self-test %s: %v", version, err)
+			}
+		default:
+			r := lookupVersion(version)
+			if r == nil {
+				return errorf(ErrSelfTest, "dvx: self-test %s: unknown version", version)
+			}
+			if r.options.SelfTest != nil {
+				if err := r.options.SelfTest(r.primitive); err != nil {
+					return errorf(ErrSelfTest, "dvx: self-test %s: %v", version, err)
+				}
+			}
+		}
+
+		if err := p.selfTestKeyPool(ctx, version); err != nil {
+			return errorf(ErrSelfTest, "dvx: self-test %s: %w", version, err)
+		}
+	}
+	return nil
+}
+
+func selfTestPrimitive(primitive Primitive, cipherKAT []byte) error {
+	key := make([]byte, 64)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	// MAC
+	tag, err := primitive.MAC256(key, selfTestMessage)
+	if err != nil || !bytes.Equal(tag, dv1MAC256KAT) {
+		return fmt.Errorf("MAC256 known-answer test failed (err: %v)", err)
+	}
+	tag, err = primitive.MAC512(key, selfTestMessage)
+	if err != nil || !bytes.Equal(tag, dv1MAC512KAT) {
+		return fmt.Errorf("MAC512 known-answer test failed (err: %v)", err)
+	}
+
+	// encryption: the nonce is random, therefore the known answer is only
+	// checked for decryption
+	data, err := primitive.Decrypt(key[:32], cipherKAT)
+	if err != nil || !bytes.Equal(data, selfTestMessage) {
+		return fmt.Errorf("Decrypt known-answer test failed (err: %v)", err)
+	}
+	cipher, err := primitive.Encrypt(key[:32], append([]byte{}, selfTestMessage...))
+	if err != nil {
+		return fmt.Errorf("Encrypt failed: %v", err)
+	}
+	data, err = primitive.Decrypt(key[:32], cipher)
+	if err != nil || !bytes.Equal(data, selfTestMessage) {
+		return fmt.Errorf("Encrypt/Decrypt round trip failed (err: %v)", err)
+	}
+
+	// signatures
+	privateKey := ed25519.NewKeyFromSeed(key[:32])
+	signature, err := primitive.Sign(privateKey, selfTestMessage)
+	if err != nil || !bytes.Equal(signature, dv1SignatureKAT) {
+		return fmt.Errorf("Sign known-answer test failed (err: %v)", err)
+	}
+	valid, err := primitive.Verify(privateKey.Public().(ed25519.PublicKey), selfTestMessage, signature)
+	if err != nil || !valid {
+		return fmt.Errorf("Verify known-answer test failed (err: %v)", err)
+	}
+	signature[0] ^= 0xff
+	valid, err = primitive.Verify(privateKey.Public().(ed25519.PublicKey), selfTestMessage, signature)
+	if err != nil || valid {
+		return fmt.Errorf("Verify accepted a modified signature (err: %v)", err)
+	}
+
+	return nil
+}
+
+// selfTestKeyPool derives the same 32 and 64 byte key twice and checks that
+// the results are equal.
+func (p *Protocol) selfTestKeyPool(ctx context.Context, version string) error {
+	keyRing := []byte("dvx-self-test")
+	for _, kdf := range []struct {
+		size int
+		kdf  func(ctx context.Context, keyRing []byte, version string, purpose string) ([]byte, error)
+	}{
+		{32, p.kdf32},
+		{64, p.kdf64},
+	} {
+		a, err := kdf.kdf(ctx, keyRing, version, purposeSelfTest)
+		if err != nil {
+			return err
+		}
+		b, err := kdf.kdf(ctx, keyRing, version, purposeSelfTest)
+		if err != nil {
+			return err
+		}
+		if len(a) != kdf.size {
+			return fmt.Errorf("KeyPool returned a %d byte key instead of %d bytes", len(a), kdf.size)
+		}
+		if !bytes.Equal(a, b) {
+			return fmt.Errorf("KeyPool returned different %d byte keys for the same keyRing", kdf.size)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package dvx
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// MaxSessionSize is the maximum length of sealed sessions, as browsers
+	// reject cookies larger than 4096 bytes.
+	MaxSessionSize = 4096
+
+	// sessionExpirySize is the size of the big-endian Unix expiry in front of
+	// sealed sessions.
+	sessionExpirySize = 8
+)
+
+// Session describes a session opened by Protocol.OpenSession.
+type Session struct {
+	// KeyRing is the keyRing that opened the session.
+	KeyRing string
+	// ExpiresAt is the time after which the session isn't accepted anymore,
+	// with a resolution of seconds.
+	ExpiresAt time.Time
+	// Reseal reports that the session wasn't sealed with the current keyRing
+	// and version. Callers should seal the claims again and replace the
+	// cookie, so previous keyRings can be retired.
+	Reseal bool
+}
+
+// SealSession derives a secret key `sk` using the keyRing and encrypts claims
+// (marshaled with encoding/json) together with an expiry of ttl into a value
+// for web session cookies, like gorilla/securecookie. The value only contains
+// characters allowed in cookies and doesn't depend on cookie attributes (e.g.
+// SameSite or Domain), which remain the responsibility of the caller. Values
+// longer than MaxSessionSize fail with ErrInvalidFormat.
+//
+// Sessions of different cookies should be sealed under different keyRings
+// (e.g. "sessions/sid" and "sessions/csrf"), so one can't be replayed as the
+// other.
+func (p *Protocol) SealSession(keyRing string, claims interface{}, ttl time.Duration) (cookie string, err error) {
+	return p.SealSessionContext(context.Background(), keyRing, claims, ttl)
+}
+
+// SealSessionContext is like SealSession, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) SealSessionContext(ctx context.Context, keyRing string, claims interface{}, ttl time.Duration) (cookie string, err error) {
+	defer p.done(OpSealSession, time.Now(), &err)
+
+	if err = p.checkEntropy(); err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		return "", errorf(ErrInvalidFormat, "dvx: ttl of session must be positive")
+	}
+	data, err := json.Marshal(claims)
+	if err != nil {
+		return "", errorf(ErrInvalidFormat, "dvx: marshal of session claims failed: %w", err)
+	}
+	now, err := p.now(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpSealSession)
+	if err != nil {
+		return "", err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, featureVersion, purposeSession)
+	if err != nil {
+		return "", err
+	}
+
+	header := make([]byte, sessionExpirySize, sessionExpirySize+CipherOverhead+len(data))
+	binary.BigEndian.PutUint64(header, uint64(now.Add(ttl).Unix()))
+	cipher, err := sealTo(header, featureVersion, key, data, sessionAAD(header))
+	if err != nil {
+		return "", err
+	}
+
+	cookie = EncodeVersion(featureVersion, Sealed, cipher)
+	if len(cookie) > MaxSessionSize {
+		return "", errorf(ErrInvalidFormat, "dvx: sealed session (%d) exceeds MaxSessionSize (%d)", len(cookie), MaxSessionSize)
+	}
+	atomic.AddUint64(&p.stats.bytesEncrypted, uint64(len(data)))
+	return cookie, nil
+}
+
+// OpenSession derives a secret key `sk` using the keyRing, decrypts a cookie
+// of SealSession and unmarshals its claims into claims (with
+// encoding/json). It fails with ErrAuthentication if cookie wasn't sealed
+// under keyRing or was changed, and with ErrExpired once the Clock of p
+// reached its expiry.
+//
+// To rotate keyRings, seal with the new keyRing and pass the old ones as
+// previous: cookies of previous keyRings are still opened, but reported with
+// Session.Reseal.
+func (p *Protocol) OpenSession(keyRing string, cookie string, claims interface{}, previous ...string) (*Session, error) {
+	return p.OpenSessionContext(context.Background(), keyRing, cookie, claims, previous...)
+}
+
+// OpenSessionContext is like OpenSession, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) OpenSessionContext(ctx context.Context, keyRing string, cookie string, claims interface{}, previous ...string) (session *Session, err error) {
+	defer p.done(OpOpenSession, time.Now(), &err)
+
+	if len(cookie) > MaxSessionSize {
+		return nil, errorf(ErrInvalidFormat, "dvx: session (%d) exceeds MaxSessionSize (%d)", len(cookie), MaxSessionSize)
+	}
+	v, cipher, err := DecodeExpect(cookie, Sealed)
+	if err != nil {
+		return nil, err
+	}
+	if v == "dv1" {
+		return nil, errorf(ErrInvalidFormat, "dvx: dv1 doesn't support sessions")
+	}
+	if len(cipher) < sessionExpirySize {
+		return nil, errorf(ErrInvalidFormat, "%s: session shorter (%d) than needed for expiry (%d)", v, len(cipher), sessionExpirySize)
+	}
+	header := cipher[:sessionExpirySize]
+
+	var data []byte
+	for i, kr := range append([]string{keyRing}, previous...) {
+		data, err = p.openSession(ctx, kr, v, header, cipher[sessionExpirySize:])
+		if errors.Is(err, ErrAuthentication) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		session = &Session{KeyRing: kr, Reseal: i > 0 || v != featureVersion}
+		break
+	}
+	if session == nil {
+		return nil, err
+	}
+
+	// the expiry is only checked after decryption, so unauthenticated
+	// sessions never reveal whether they are expired
+	session.ExpiresAt = time.Unix(int64(binary.BigEndian.Uint64(header)), 0)
+	now, err := p.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(session.ExpiresAt) {
+		return nil, errorf(ErrExpired, "dvx: session expired at %s", session.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+
+	if err = json.Unmarshal(data, claims); err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: unmarshal of session claims failed: %w", err)
+	}
+	atomic.AddUint64(&p.stats.bytesDecrypted, uint64(len(data)))
+	return session, nil
+}
+
+// openSession decrypts the cipher of a session with the key of keyRing.
+func (p *Protocol) openSession(ctx context.Context, keyRing string, version string, header []byte, cipher []byte) ([]byte, error) {
+	keyRingBytes, err := p.keyRingInput(keyRing, OpOpenSession)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, version, purposeSession)
+	if err != nil {
+		return nil, err
+	}
+	return open(version, key, cipher, sessionAAD(header))
+}
+
+// sessionAAD returns the footer of seal for sessions, so the TypePrefix and
+// expiry are authenticated.
+func sessionAAD(header []byte) []byte {
+	return append([]byte(Sealed), header...)
+}
@@ -0,0 +1,74 @@
+package dvx
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"io"
+	"time"
+)
+
+// Signer returns a crypto.Signer of the key derived for keyRing, so it can be
+// used by consumers of the standard library interface, e.g.
+// x509.CreateCertificate or ssh.NewSignerFromSigner of golang.org/x/crypto.
+// Its Public method returns the ed25519.PublicKey of CreateSignKey, and
+// signatures of its Sign method are the raw signatures of Sign.
+//
+// The Signer doesn't keep the private key: it is derived from the KeyPool
+// for every signature and overwritten afterwards. After the root key of the
+// KeyPool was rotated, a new Signer must be created, as the public key of an
+// existing one isn't updated.
+func (p *Protocol) Signer(keyRing string) (crypto.Signer, error) {
+	return p.SignerContext(context.Background(), keyRing)
+}
+
+// SignerContext is like Signer, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation of the
+// public key. Signatures use context.Background(), as crypto.Signer has no
+// context.
+func (p *Protocol) SignerContext(ctx context.Context, keyRing string) (crypto.Signer, error) {
+	publicKey, err := p.CreateSignKeyContext(ctx, keyRing)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signer{
+		p:         p,
+		keyRing:   keyRing,
+		publicKey: ed25519.PublicKey(publicKey),
+	}, nil
+}
+
+// signer implements crypto.Signer for a keyRing of a Protocol.
+type signer struct {
+	p         *Protocol
+	keyRing   string
+	publicKey ed25519.PublicKey
+}
+
+func (s *signer) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+// Sign signs message with Ed25519. Like ed25519.PrivateKey it only supports
+// unhashed messages (opts.HashFunc() must be zero) and ignores rand, as
+// Ed25519 signatures are deterministic.
+func (s *signer) Sign(rand io.Reader, message []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	defer s.p.done(OpSign, time.Now(), &err)
+
+	if opts.HashFunc() != crypto.Hash(0) {
+		return nil, errorf(ErrInvalidFormat, "dvx: Ed25519 signer doesn't support pre-hashed messages (%s)", opts.HashFunc())
+	}
+
+	keyRingBytes, err := s.p.keyRingInput(s.keyRing, OpSign)
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.p.deriveSignKey(context.Background(), keyRingBytes, Version)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+
+	return primitiveOf(Version).Sign(key, message)
+}
@@ -0,0 +1,252 @@
+package sqlcrypt
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+
+	"azoo.dev/utils/dvx"
+)
+
+// conn wraps a driver.Conn. Optional interfaces of the wrapped connection are
+// used if available, otherwise database/sql falls back to Prepare.
+type conn struct {
+	conn    driver.Conn
+	columns *columns
+}
+
+func (c *conn) Prepare(query string) (driver.Stmt, error) {
+	return c.PrepareContext(context.Background(), query)
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	var s driver.Stmt
+	var err error
+	if cp, ok := c.conn.(driver.ConnPrepareContext); ok {
+		s, err = cp.PrepareContext(ctx, query)
+	} else {
+		s, err = c.conn.Prepare(query)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &stmt{stmt: s, statement: analyze(query), columns: c.columns}, nil
+}
+
+func (c *conn) Close() error {
+	return c.conn.Close()
+}
+
+func (c *conn) Begin() (driver.Tx, error) {
+	return c.BeginTx(context.Background(), driver.TxOptions{})
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if cb, ok := c.conn.(driver.ConnBeginTx); ok {
+		return cb.BeginTx(ctx, opts)
+	}
+	if opts.Isolation != 0 || opts.ReadOnly {
+		return nil, errors.New("sqlcrypt: driver doesn't support transaction options")
+	}
+	return c.conn.Begin()
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	e, ok := c.conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	args, err := c.columns.encryptArgs(ctx, analyze(query), args)
+	if err != nil {
+		return nil, err
+	}
+	return e.ExecContext(ctx, query, args)
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	q, ok := c.conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+
+	s := analyze(query)
+	args, err := c.columns.encryptArgs(ctx, s, args)
+	if err != nil {
+		return nil, err
+	}
+	r, err := q.QueryContext(ctx, query, args)
+	if err != nil {
+		return nil, err
+	}
+	return c.columns.wrapRows(ctx, s, r), nil
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if p, ok := c.conn.(driver.Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if r, ok := c.conn.(driver.SessionResetter); ok {
+		return r.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if v, ok := c.conn.(driver.Validator); ok {
+		return v.IsValid()
+	}
+	return true
+}
+
+func (c *conn) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := c.conn.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+type stmt struct {
+	stmt      driver.Stmt
+	statement *statement
+	columns   *columns
+}
+
+func (s *stmt) Close() error {
+	return s.stmt.Close()
+}
+
+func (s *stmt) NumInput() int {
+	return s.stmt.NumInput()
+}
+
+func (s *stmt) Exec(args []driver.Value) (driver.Result, error) {
+	return s.ExecContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) Query(args []driver.Value) (driver.Rows, error) {
+	return s.QueryContext(context.Background(), namedValues(args))
+}
+
+func (s *stmt) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
+	args, err := s.columns.encryptArgs(ctx, s.statement, args)
+	if err != nil {
+		return nil, err
+	}
+
+	if e, ok := s.stmt.(driver.StmtExecContext); ok {
+		return e.ExecContext(ctx, args)
+	}
+	values, err := driverValues(args)
+	if err != nil {
+		return nil, err
+	}
+	return s.stmt.Exec(values)
+}
+
+func (s *stmt) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
+	args, err := s.columns.encryptArgs(ctx, s.statement, args)
+	if err != nil {
+		return nil, err
+	}
+
+	var r driver.Rows
+	if q, ok := s.stmt.(driver.StmtQueryContext); ok {
+		r, err = q.QueryContext(ctx, args)
+	} else {
+		var values []driver.Value
+		if values, err = driverValues(args); err == nil {
+			r, err = s.stmt.Query(values)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return s.columns.wrapRows(ctx, s.statement, r), nil
+}
+
+func (s *stmt) CheckNamedValue(nv *driver.NamedValue) error {
+	if n, ok := s.stmt.(driver.NamedValueChecker); ok {
+		return n.CheckNamedValue(nv)
+	}
+	return driver.ErrSkip
+}
+
+func namedValues(args []driver.Value) []driver.NamedValue {
+	named := make([]driver.NamedValue, len(args))
+	for i, arg := range args {
+		named[i] = driver.NamedValue{Ordinal: i + 1, Value: arg}
+	}
+	return named
+}
+
+func driverValues(args []driver.NamedValue) ([]driver.Value, error) {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		if arg.Name != "" {
+			return nil, fmt.Errorf("sqlcrypt: driver doesn't support named parameters")
+		}
+		values[i] = arg.Value
+	}
+	return values, nil
+}
+
+// wrapRows returns r unchanged, if none of its columns is encrypted.
+func (c *columns) wrapRows(ctx context.Context, s *statement, r driver.Rows) driver.Rows {
+	keyRings := c.decryptColumns(s, r.Columns())
+	if keyRings == nil {
+		return r
+	}
+	return &rows{rows: r, ctx: ctx, protocol: c.protocol, keyRings: keyRings}
+}
+
+// rows decrypts the values of encrypted columns.
+type rows struct {
+	rows     driver.Rows
+	ctx      context.Context
+	protocol dvx.Crypto
+	// keyRings of the encrypted columns, empty for other columns
+	keyRings []string
+}
+
+func (r *rows) Columns() []string {
+	return r.rows.Columns()
+}
+
+func (r *rows) Close() error {
+	return r.rows.Close()
+}
+
+func (r *rows) Next(dest []driver.Value) error {
+	if err := r.rows.Next(dest); err != nil {
+		return err
+	}
+
+	for i, keyRing := range r.keyRings {
+		if keyRing == "" || i >= len(dest) || dest[i] == nil {
+			continue
+		}
+
+		var ciphertext string
+		switch v := dest[i].(type) {
+		case string:
+			ciphertext = v
+		case []byte:
+			ciphertext = string(v)
+		default:
+			return fmt.Errorf("sqlcrypt: column %s: unsupported result type %T", r.rows.Columns()[i], dest[i])
+		}
+
+		data, err := r.protocol.DecryptContext(r.ctx, keyRing, ciphertext)
+		if err != nil {
+			return fmt.Errorf("sqlcrypt: column %s: %w", r.rows.Columns()[i], err)
+		}
+		dest[i] = data
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package sqlcrypt
+
+import (
+	"strconv"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenIdent tokenKind = iota
+	tokenPlaceholder
+	tokenPunct
+	tokenLiteral
+)
+
+type token struct {
+	kind tokenKind
+	// text is the lower case identifier (without quotes) or punctuation
+	text string
+	// ordinal is the zero based position of a placeholder in the arguments
+	ordinal int
+}
+
+// tokenize splits query into the tokens needed by analyze. String literals
+// and comments are skipped, placeholders are either "?" (numbered in order of
+// appearance) or "$N" (numbered by N).
+func tokenize(query string) []token {
+	var tokens []token
+	next := 0
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return tokens
+			}
+			i += end + 1
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 4
+		case c == '\'':
+			// string literal with '' as escaped quote
+			i++
+			for i < len(query) {
+				if query[i] == '\'' {
+					if i+1 < len(query) && query[i+1] == '\'' {
+						i += 2
+						continue
+					}
+					break
+				}
+				i++
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenLiteral})
+		case c == '"' || c == '`' || isIdentStart(c):
+			// (quoted) identifier, possibly qualified like "users"."email"
+			var sb strings.Builder
+			for i < len(query) {
+				if query[i] == '"' || query[i] == '`' {
+					end := strings.IndexByte(query[i+1:], query[i])
+					if end < 0 {
+						end = len(query) - i - 1
+					}
+					sb.WriteString(query[i+1 : i+1+end])
+					i += end + 2
+				} else if isIdentStart(query[i]) {
+					start := i
+					for i < len(query) && isIdentPart(query[i]) {
+						i++
+					}
+					sb.WriteString(query[start:i])
+				} else {
+					break
+				}
+				if i < len(query) && query[i] == '.' {
+					sb.WriteByte('.')
+					i++
+					continue
+				}
+				break
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: strings.ToLower(sb.String())})
+		case c == '?':
+			tokens = append(tokens, token{kind: tokenPlaceholder, ordinal: next})
+			next++
+			i++
+		case c == '$' && i+1 < len(query) && isDigit(query[i+1]):
+			start := i + 1
+			i++
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+			n, _ := strconv.Atoi(query[start:i])
+			tokens = append(tokens, token{kind: tokenPlaceholder, ordinal: n - 1})
+		case isDigit(c):
+			for i < len(query) && (isDigit(query[i]) || query[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenLiteral})
+		default:
+			tokens = append(tokens, token{kind: tokenPunct, text: string(c)})
+			i++
+		}
+	}
+
+	return tokens
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// statement is the result of analyze.
+type statement struct {
+	// table is the first table of the statement (INSERT INTO, UPDATE, FROM)
+	table string
+	// params maps the ordinal of placeholders to the (possibly qualified)
+	// column they are assigned to or compared with
+	params map[int]string
+}
+
+// analyze finds the columns of placeholders in query. It recognizes the
+// column lists of INSERT statements and the patterns `column = placeholder`
+// (in SET and WHERE clauses, in both directions) and `column IN
+// (placeholder, ...)`. Placeholders in other expressions have no column.
+func analyze(query string) *statement {
+	tokens := tokenize(query)
+	s := &statement{params: make(map[int]string)}
+
+	is := func(i int, kind tokenKind, text string) bool {
+		return i >= 0 && i < len(tokens) && tokens[i].kind == kind && tokens[i].text == text
+	}
+
+	// table
+	for i := range tokens {
+		if is(i-1, tokenIdent, "into") || is(i-1, tokenIdent, "update") || is(i-1, tokenIdent, "from") {
+			if tokens[i].kind == tokenIdent {
+				s.table = tokens[i].text
+				break
+			}
+		}
+	}
+
+	// column list and values of INSERT statements
+	if len(tokens) > 2 && is(0, tokenIdent, "insert") {
+		i := 0
+		for i < len(tokens) && !is(i, tokenPunct, "(") && !is(i, tokenIdent, "values") {
+			i++
+		}
+		var columns []string
+		if is(i, tokenPunct, "(") {
+			for i++; i < len(tokens) && !is(i, tokenPunct, ")"); i++ {
+				if tokens[i].kind == tokenIdent {
+					columns = append(columns, tokens[i].text)
+				}
+			}
+		}
+		for i < len(tokens) && !is(i, tokenIdent, "values") {
+			i++
+		}
+
+		// every tuple after VALUES
+		depth, position := 0, 0
+		for i++; i < len(tokens); i++ {
+			switch {
+			case is(i, tokenPunct, "("):
+				depth++
+				if depth == 1 {
+					position = 0
+				}
+			case is(i, tokenPunct, ")"):
+				depth--
+			case is(i, tokenPunct, ",") && depth == 1:
+				position++
+			case tokens[i].kind == tokenPlaceholder && depth == 1 && position < len(columns):
+				s.params[tokens[i].ordinal] = columns[position]
+			}
+			if depth == 0 && !is(i, tokenPunct, ",") && !is(i, tokenPunct, ")") {
+				// end of the VALUES list (e.g. ON CONFLICT or RETURNING)
+				break
+			}
+		}
+	}
+
+	// column = placeholder, placeholder = column and column IN (placeholder, ...)
+	for i := range tokens {
+		switch {
+		case tokens[i].kind == tokenPlaceholder && is(i-1, tokenPunct, "=") && i >= 2 && tokens[i-2].kind == tokenIdent:
+			s.params[tokens[i].ordinal] = tokens[i-2].text
+		case tokens[i].kind == tokenPlaceholder && is(i+1, tokenPunct, "=") && i+2 < len(tokens) && tokens[i+2].kind == tokenIdent:
+			s.params[tokens[i].ordinal] = tokens[i+2].text
+		case tokens[i].kind == tokenIdent && is(i+1, tokenIdent, "in") && is(i+2, tokenPunct, "("):
+			for j := i + 3; j < len(tokens) && !is(j, tokenPunct, ")"); j++ {
+				if tokens[j].kind == tokenPlaceholder {
+					s.params[tokens[j].ordinal] = tokens[i].text
+				}
+			}
+		}
+	}
+
+	return s
+}
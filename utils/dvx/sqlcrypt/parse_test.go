@@ -0,0 +1,34 @@
+package sqlcrypt
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnalyze(t *testing.T) {
+	for query, expected := range map[string]*statement{
+		"INSERT INTO users (id, email, email_bidx) VALUES (?, ?, ?)": {
+			table:  "users",
+			params: map[int]string{0: "id", 1: "email", 2: "email_bidx"},
+		},
+		`INSERT INTO "users" ("id", "email") VALUES ($1, lower($2)), ($3, $4) RETURNING id`: {
+			table:  "users",
+			params: map[int]string{0: "id", 2: "id", 3: "email"},
+		},
+		"UPDATE users SET email = $2, name = 'it''s ?' WHERE id = $1": {
+			table:  "users",
+			params: map[int]string{1: "email", 0: "id"},
+		},
+		"SELECT u.email FROM users u -- where x = ?\nWHERE ? = u.email_bidx OR id IN (?, ?)": {
+			table:  "users",
+			params: map[int]string{0: "u.email_bidx", 1: "id", 2: "id"},
+		},
+		"DELETE FROM users WHERE email LIKE ?": {
+			table:  "users",
+			params: map[int]string{},
+		},
+	} {
+		assert.Equal(t, expected, analyze(query), query)
+	}
+}
@@ -0,0 +1,223 @@
+// Package sqlcrypt wraps a database/sql driver to transparently encrypt
+// parameters and decrypt results of configured columns with a dvx.Protocol.
+//
+//   connector := sqlcrypt.WrapConnector(pgConnector, &sqlcrypt.Config{
+//     Protocol: protocol,
+//     Columns: map[string]sqlcrypt.Column{
+//       "users.email":      {KeyRing: "users.email"},
+//       "users.email_bidx": {KeyRing: "users.email", BlindIndex: true},
+//     },
+//   })
+//   db := sql.OpenDB(connector)
+//
+//   db.Exec("INSERT INTO users (id, email, email_bidx) VALUES ($1, $2, $3)", id, email, email)
+//   db.QueryRow("SELECT email FROM users WHERE email_bidx = $1", email).Scan(&email)
+//
+// Parameters of encrypted columns are replaced by dvx ciphertexts, and those
+// of blind index columns by a MAC tag of their value (see Protocol.MAC). As a
+// MAC is deterministic, blind index columns can be searched for equality with
+// the plaintext value. A blind index is populated by passing the plaintext
+// for both columns, like in the INSERT above. Results of encrypted columns
+// are decrypted.
+//
+// sqlcrypt doesn't parse SQL completely. It assigns a placeholder ("?" or
+// "$N") to a column only in the column list of an INSERT statement and in
+// the patterns `column = placeholder` and `column IN (placeholder, ...)`.
+// Parameters used in any other way (e.g. `LIKE`, functions or sub queries)
+// are passed unchanged. Result columns are matched by their name, so columns
+// must not be renamed with AS.
+package sqlcrypt
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"strings"
+
+	"azoo.dev/utils/dvx"
+)
+
+// Config configures the encrypted columns.
+type Config struct {
+	// Protocol encrypts, decrypts and calculates blind indexes.
+	Protocol dvx.Crypto
+	// Columns maps "table.column" to its configuration. Names are case
+	// insensitive. For example: "users.email"
+	Columns map[string]Column
+}
+
+// Column configures an encrypted or blind index column.
+type Column struct {
+	// KeyRing is the keyRing of the column. For example: "users.email"
+	KeyRing string
+	// BlindIndex replaces parameters with their MAC instead of encrypting
+	// them. Results of blind index columns are returned unchanged.
+	BlindIndex bool
+}
+
+// columns is the normalized form of Config.Columns.
+type columns struct {
+	protocol dvx.Crypto
+	// byName maps "table.column" to its configuration
+	byName map[string]Column
+	// byColumn maps column names to their configuration, if they are unique
+	// across all tables
+	byColumn map[string]*Column
+}
+
+func newColumns(config *Config) *columns {
+	c := &columns{
+		protocol: config.Protocol,
+		byName:   make(map[string]Column, len(config.Columns)),
+		byColumn: make(map[string]*Column),
+	}
+
+	ambiguous := make(map[string]bool)
+	for name, column := range config.Columns {
+		name = strings.ToLower(name)
+		c.byName[name] = column
+
+		column := column
+		short := name[strings.LastIndexByte(name, '.')+1:]
+		if _, ok := c.byColumn[short]; ok {
+			ambiguous[short] = true
+		}
+		c.byColumn[short] = &column
+	}
+	for short := range ambiguous {
+		delete(c.byColumn, short)
+	}
+
+	return c
+}
+
+// lookup returns the configuration of column, which is either qualified with
+// a table name or belongs to table. Unqualified columns of other tables (e.g.
+// in JOIN statements) are found, if their name is unique across all tables.
+func (c *columns) lookup(table string, column string) (name string, config Column, ok bool) {
+	if dot := strings.LastIndexByte(column, '.'); dot >= 0 {
+		if config, ok = c.byName[column]; ok {
+			return column, config, true
+		}
+		// the qualifier is an alias
+		column = column[dot+1:]
+	}
+
+	name = table + "." + column
+	if config, ok = c.byName[name]; ok {
+		return name, config, true
+	}
+	if config := c.byColumn[column]; config != nil {
+		return column, *config, true
+	}
+	return "", Column{}, false
+}
+
+// encryptArgs replaces the values of args that belong to configured columns.
+func (c *columns) encryptArgs(ctx context.Context, s *statement, args []driver.NamedValue) ([]driver.NamedValue, error) {
+	var encrypted []driver.NamedValue
+	for i, arg := range args {
+		column, ok := s.params[arg.Ordinal-1]
+		if !ok {
+			continue
+		}
+		name, config, ok := c.lookup(s.table, column)
+		if !ok || arg.Value == nil {
+			continue
+		}
+
+		var data []byte
+		switch v := arg.Value.(type) {
+		case string:
+			data = []byte(v)
+		case []byte:
+			data = v
+		default:
+			return nil, fmt.Errorf("sqlcrypt: %s: unsupported parameter type %T (supported: string, []byte)", name, arg.Value)
+		}
+
+		var value string
+		var err error
+		if config.BlindIndex {
+			value, err = c.protocol.MACContext(ctx, config.KeyRing, data)
+		} else {
+			value, err = c.protocol.EncryptContext(ctx, config.KeyRing, data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sqlcrypt: %s: %w", name, err)
+		}
+
+		// copy args on the first change, as they belong to the caller
+		if encrypted == nil {
+			encrypted = append([]driver.NamedValue(nil), args...)
+		}
+		encrypted[i].Value = value
+	}
+
+	if encrypted == nil {
+		return args, nil
+	}
+	return encrypted, nil
+}
+
+// decryptColumns returns the keyRings of the encrypted columns among the
+// result columns of s, or nil if there are none.
+func (c *columns) decryptColumns(s *statement, names []string) []string {
+	var keyRings []string
+	for i, column := range names {
+		_, config, ok := c.lookup(s.table, strings.ToLower(column))
+		if !ok || config.BlindIndex {
+			continue
+		}
+		if keyRings == nil {
+			keyRings = make([]string, len(names))
+		}
+		keyRings[i] = config.KeyRing
+	}
+	return keyRings
+}
+
+// WrapConnector wraps connector, so all connections encrypt and decrypt the
+// columns configured in config. Use it with sql.OpenDB.
+func WrapConnector(connector driver.Connector, config *Config) driver.Connector {
+	return &wrappedConnector{
+		connector: connector,
+		driver:    &wrappedDriver{driver: connector.Driver(), columns: newColumns(config)},
+	}
+}
+
+// Wrap wraps drv, so all connections encrypt and decrypt the columns
+// configured in config. Register it with sql.Register.
+func Wrap(drv driver.Driver, config *Config) driver.Driver {
+	return &wrappedDriver{driver: drv, columns: newColumns(config)}
+}
+
+type wrappedDriver struct {
+	driver  driver.Driver
+	columns *columns
+}
+
+func (d *wrappedDriver) Open(name string) (driver.Conn, error) {
+	c, err := d.driver.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{conn: c, columns: d.columns}, nil
+}
+
+type wrappedConnector struct {
+	connector driver.Connector
+	driver    *wrappedDriver
+}
+
+func (c *wrappedConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{conn: dc, columns: c.driver.columns}, nil
+}
+
+func (c *wrappedConnector) Driver() driver.Driver {
+	return c.driver
+}
@@ -0,0 +1,118 @@
+package sqlcrypt
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"azoo.dev/utils/dvx"
+)
+
+func newProtocol(t *testing.T) *dvx.Protocol {
+	rootKey := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, rootKey)
+	require.NoError(t, err)
+
+	return dvx.NewProtocol(map[string]dvx.KeyPool{dvx.Version: dvx.WrapDVXAsKeyPool(dvx.DV1{}, rootKey, dvx.NopLogger)})
+}
+
+// fakeDB is a driver.Connector that stores the arguments of every Exec as a
+// row (id, email, email_bidx). Queries return all rows whose email_bidx
+// equals the first argument.
+type fakeDB struct {
+	rows [][]driver.Value
+}
+
+func (db *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: db}, nil }
+func (db *fakeDB) Driver() driver.Driver                        { return nil }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, driver.ErrSkip }
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.rows = append(s.db.rows, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	r := &fakeRows{}
+	for _, row := range s.db.rows {
+		if row[2] == args[0] {
+			r.rows = append(r.rows, row)
+		}
+	}
+	return r, nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "email", "email_bidx"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestWrapConnector(t *testing.T) {
+	fake := &fakeDB{}
+	db := sql.OpenDB(WrapConnector(fake, &Config{
+		Protocol: newProtocol(t),
+		Columns: map[string]Column{
+			"users.email":      {KeyRing: "users.email"},
+			"Users.Email_Bidx": {KeyRing: "users.email", BlindIndex: true},
+		},
+	}))
+	defer db.Close()
+
+	for _, email := range []string{"jane@example.com", "john@example.com"} {
+		_, err := db.Exec("INSERT INTO users (id, email, email_bidx) VALUES (?, ?, ?)", strings.Split(email, "@")[0], email, email)
+		require.NoError(t, err)
+	}
+
+	// the database only sees ciphertexts and blind indexes
+	require.Len(t, fake.rows, 2)
+	assert.Equal(t, "jane", fake.rows[0][0])
+	assert.True(t, strings.HasPrefix(fake.rows[0][1].(string), dvx.Version+".enc."))
+	assert.True(t, strings.HasPrefix(fake.rows[0][2].(string), dvx.Version+".tag."))
+	assert.NotEqual(t, fake.rows[0][2], fake.rows[1][2])
+
+	// blind indexes are searchable with the plaintext and results decrypted
+	var id, email, bidx string
+	require.NoError(t, db.QueryRow("SELECT id, email, email_bidx FROM users WHERE email_bidx = ?", "john@example.com").Scan(&id, &email, &bidx))
+	assert.Equal(t, "john", id)
+	assert.Equal(t, "john@example.com", email)
+	assert.Equal(t, fake.rows[1][2], bidx)
+
+	// unsupported parameter types of encrypted columns fail
+	_, err := db.Exec("INSERT INTO users (id, email, email_bidx) VALUES (?, ?, ?)", "x", 42, "x")
+	assert.Error(t, err)
+}
@@ -0,0 +1,252 @@
+package dvx
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"strings"
+	"time"
+)
+
+// The SSHSIG format of OpenSSH (PROTOCOL.sshsig), as created by
+// `ssh-keygen -Y sign` and verified by `ssh-keygen -Y verify`.
+const (
+	sshsigMagic     = "SSHSIG"
+	sshsigVersion   = 1
+	sshsigPEMType   = "SSH SIGNATURE"
+	sshKeyTypeEd255 = "ssh-ed25519"
+)
+
+// SignSSH derives a private key using the keyRing and returns an armored
+// SSHSIG signature of message (hash sha512), that `ssh-keygen -Y verify`
+// accepts for the given namespace (e.g. "file" or "git") and the public key
+// of MarshalSSHPublicKey in its allowed signers file:
+//   ssh-keygen -Y verify -f allowed_signers -I principal -n file -s msg.sig < msg
+func (p *Protocol) SignSSH(keyRing string, namespace string, message []byte) (signature []byte, err error) {
+	return p.SignSSHContext(context.Background(), keyRing, namespace, message)
+}
+
+// SignSSHContext is like SignSSH, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) SignSSHContext(ctx context.Context, keyRing string, namespace string, message []byte) (signature []byte, err error) {
+	defer p.done(OpSign, time.Now(), &err)
+
+	if namespace == "" {
+		return nil, errorf(ErrInvalidFormat, "dvx: SSHSIG namespace must not be empty")
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpSign)
+	if err != nil {
+		return nil, err
+	}
+	key, err := p.deriveSignKey(ctx, keyRingBytes, Version)
+	if err != nil {
+		return nil, err
+	}
+	publicKey := ed25519.PrivateKey(key).Public().(ed25519.PublicKey)
+
+	hash := sha512.Sum512(message)
+	sig, err := primitiveOf(Version).Sign(key, sshsigSignedData([]byte(namespace), nil, []byte("sha512"), hash[:]))
+	if err != nil {
+		return nil, err
+	}
+
+	blob := make([]byte, len(sshsigMagic)+4)
+	copy(blob, sshsigMagic)
+	binary.BigEndian.PutUint32(blob[len(sshsigMagic):], sshsigVersion)
+	blob = sshAppendString(blob, sshPublicKeyBlob(publicKey))
+	blob = sshAppendString(blob, []byte(namespace))
+	blob = sshAppendString(blob, nil)
+	blob = sshAppendString(blob, []byte("sha512"))
+	blob = sshAppendString(blob, sshAppendString(sshAppendString(nil, []byte(sshKeyTypeEd255)), sig))
+
+	return sshArmor(blob), nil
+}
+
+// VerifySSH derives a private key using the keyRing and subsequently uses its
+// public key counterpart to verify an armored SSHSIG signature of message for
+// namespace.
+func (p *Protocol) VerifySSH(keyRing string, namespace string, message []byte, signature []byte) (valid bool, err error) {
+	return p.VerifySSHContext(context.Background(), keyRing, namespace, message, signature)
+}
+
+// VerifySSHContext is like VerifySSH, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) VerifySSHContext(ctx context.Context, keyRing string, namespace string, message []byte, signature []byte) (valid bool, err error) {
+	defer p.done(OpVerify, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpVerify)
+	if err != nil {
+		return false, err
+	}
+	key, err := p.deriveSignKey(ctx, keyRingBytes, Version)
+	if err != nil {
+		return false, err
+	}
+
+	return verifySSH(ed25519.PrivateKey(key).Public().(ed25519.PublicKey), namespace, message, signature)
+}
+
+// VerifySSHPK is like VerifySSH, but uses the provided Ed25519 public key
+// directly, e.g. to verify signatures of `ssh-keygen -Y sign` with a key
+// parsed by ParseSSHPublicKey.
+func (p *Protocol) VerifySSHPK(publicKey []byte, namespace string, message []byte, signature []byte) (valid bool, err error) {
+	defer p.done(OpVerifyPK, time.Now(), &err)
+
+	return verifySSH(publicKey, namespace, message, signature)
+}
+
+func verifySSH(publicKey []byte, namespace string, message []byte, signature []byte) (valid bool, err error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return false, errorf(ErrInvalidKey, "dvx: public key must be %d bytes long", ed25519.PublicKeySize)
+	}
+
+	block, _ := pem.Decode(signature)
+	if block == nil || block.Type != sshsigPEMType {
+		return false, errorf(ErrInvalidFormat, "dvx: SSHSIG: no %q PEM block", sshsigPEMType)
+	}
+	blob := block.Bytes
+	if !bytes.HasPrefix(blob, []byte(sshsigMagic)) || len(blob) < len(sshsigMagic)+4 {
+		return false, errorf(ErrInvalidFormat, "dvx: SSHSIG: invalid magic")
+	}
+	blob = blob[len(sshsigMagic):]
+	if v := binary.BigEndian.Uint32(blob); v != sshsigVersion {
+		return false, errorf(ErrInvalidFormat, "dvx: SSHSIG: unsupported version %d", v)
+	}
+	blob = blob[4:]
+
+	var fields [5][]byte
+	for i := range fields {
+		if fields[i], blob, err = sshReadString(blob); err != nil {
+			return false, err
+		}
+	}
+	if len(blob) != 0 {
+		return false, errorf(ErrInvalidFormat, "dvx: SSHSIG: %d trailing bytes", len(blob))
+	}
+	keyBlob, sigNamespace, reserved, hashAlgorithm, sigBlob := fields[0], fields[1], fields[2], fields[3], fields[4]
+
+	// the signature must be of the expected key and namespace, otherwise a
+	// signature for another purpose (e.g. "git") would be accepted
+	if subtle.ConstantTimeCompare(keyBlob, sshPublicKeyBlob(publicKey)) != 1 {
+		return false, nil
+	}
+	if string(sigNamespace) != namespace {
+		return false, nil
+	}
+
+	var hash []byte
+	switch string(hashAlgorithm) {
+	case "sha256":
+		h := sha256.Sum256(message)
+		hash = h[:]
+	case "sha512":
+		h := sha512.Sum512(message)
+		hash = h[:]
+	default:
+		return false, errorf(ErrInvalidFormat, "dvx: SSHSIG: unsupported hash algorithm %q", hashAlgorithm)
+	}
+
+	sigType, sigBlob, err := sshReadString(sigBlob)
+	if err != nil {
+		return false, err
+	}
+	sig, rest, err := sshReadString(sigBlob)
+	if err != nil {
+		return false, err
+	}
+	if string(sigType) != sshKeyTypeEd255 || len(rest) != 0 {
+		return false, errorf(ErrInvalidFormat, "dvx: SSHSIG: unsupported signature type %q", sigType)
+	}
+
+	return DV1{}.Verify(publicKey, sshsigSignedData(sigNamespace, reserved, hashAlgorithm, hash), sig)
+}
+
+// sshsigSignedData returns the blob that is signed by SSHSIG signatures.
+func sshsigSignedData(namespace []byte, reserved []byte, hashAlgorithm []byte, hash []byte) []byte {
+	data := []byte(sshsigMagic)
+	data = sshAppendString(data, namespace)
+	data = sshAppendString(data, reserved)
+	data = sshAppendString(data, hashAlgorithm)
+	return sshAppendString(data, hash)
+}
+
+// MarshalSSHPublicKey encodes an Ed25519 public key (e.g. of CreateSignKey)
+// in the authorized_keys format ("ssh-ed25519 AAAA…"), as used in allowed
+// signers files of `ssh-keygen -Y verify`.
+func MarshalSSHPublicKey(publicKey []byte) (string, error) {
+	if len(publicKey) != ed25519.PublicKeySize {
+		return "", errorf(ErrInvalidKey, "dvx: public key must be %d bytes long", ed25519.PublicKeySize)
+	}
+	return sshKeyTypeEd255 + " " + base64.StdEncoding.EncodeToString(sshPublicKeyBlob(publicKey)), nil
+}
+
+// ParseSSHPublicKey decodes an Ed25519 public key in the authorized_keys
+// format ("ssh-ed25519 AAAA… comment"), e.g. the content of id_ed25519.pub.
+func ParseSSHPublicKey(line string) (ed25519.PublicKey, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 || fields[0] != sshKeyTypeEd255 {
+		return nil, errorf(ErrInvalidKey, "dvx: not an %s public key", sshKeyTypeEd255)
+	}
+
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return nil, errorf(ErrInvalidFormat, "dvx: SSH public key isn't base64: %v", err)
+	}
+	keyType, blob, err := sshReadString(blob)
+	if err != nil {
+		return nil, err
+	}
+	key, rest, err := sshReadString(blob)
+	if err != nil {
+		return nil, err
+	}
+	if string(keyType) != sshKeyTypeEd255 || len(key) != ed25519.PublicKeySize || len(rest) != 0 {
+		return nil, errorf(ErrInvalidKey, "dvx: invalid %s public key", sshKeyTypeEd255)
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+// sshPublicKeyBlob returns the SSH wire encoding of an Ed25519 public key.
+func sshPublicKeyBlob(publicKey []byte) []byte {
+	return sshAppendString(sshAppendString(nil, []byte(sshKeyTypeEd255)), publicKey)
+}
+
+// sshAppendString appends s as SSH string (uint32 length and bytes).
+func sshAppendString(buf []byte, s []byte) []byte {
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(s)))
+	return append(append(buf, size[:]...), s...)
+}
+
+// sshReadString reads an SSH string from the start of buf.
+func sshReadString(buf []byte) (s []byte, rest []byte, err error) {
+	if len(buf) < 4 {
+		return nil, nil, errorf(ErrInvalidFormat, "dvx: SSH string too short")
+	}
+	size := binary.BigEndian.Uint32(buf)
+	if uint64(len(buf)-4) < uint64(size) {
+		return nil, nil, errorf(ErrInvalidFormat, "dvx: SSH string too short")
+	}
+	return buf[4 : 4+size], buf[4+size:], nil
+}
+
+// sshArmor encodes blob as PEM block with the line length of ssh-keygen.
+func sshArmor(blob []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+	out := &bytes.Buffer{}
+	out.WriteString("-----BEGIN " + sshsigPEMType + "-----\n")
+	for len(encoded) > 70 {
+		out.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	out.WriteString(encoded + "\n")
+	out.WriteString("-----END " + sshsigPEMType + "-----\n")
+	return out.Bytes()
+}
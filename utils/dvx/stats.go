@@ -0,0 +1,204 @@
+package dvx
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+)
+
+// Operation names used as keys in Stats.Operations and Stats.Failures.
+const (
+	OpEncrypt       = "encrypt"
+	OpDecrypt       = "decrypt"
+	OpCreateSignKey = "create_sign_key"
+	OpSign          = "sign"
+	OpSignWithKey   = "sign_with_key"
+	OpVerify        = "verify"
+	OpVerifyPK      = "verify_pk"
+	OpMAC           = "mac"
+	OpGenerateTOTP  = "generate_totp"
+	OpVerifyTOTP    = "verify_totp"
+	OpTokenize      = "tokenize"
+	OpDetokenize    = "detokenize"
+
+	OpIssueOneTimeToken  = "issue_one_time_token"
+	OpRedeemOneTimeToken = "redeem_one_time_token"
+	OpSealSession        = "seal_session"
+	OpOpenSession        = "open_session"
+
+	OpRevokeTOTP           = "revoke_totp"
+	OpVerifyTOTPRevocation = "verify_totp_revocation"
+
+	OpIssueWebAuthnChallenge  = "issue_webauthn_challenge"
+	OpVerifyWebAuthnChallenge = "verify_webauthn_challenge"
+
+	OpDeriveID = "derive_id"
+)
+
+// ErrorClass returns the name of the class of err, as used in
+// Stats.Failures: "invalid_format", "invalid_key", "authentication",
+// "randomness", "key_derivation", "self_test", "time_locked",
+// "key_ring_policy", "expired", "revoked", "context" (context canceled or deadline exceeded) or
+// "other".
+func ErrorClass(err error) string {
+	switch {
+	// key derivation errors keep the class of the KeyPool error (see
+	// keyDerivationError), therefore they are checked first
+	case errors.Is(err, ErrKeyDerivation):
+		return "key_derivation"
+	case errors.Is(err, ErrInvalidFormat):
+		return "invalid_format"
+	case errors.Is(err, ErrInvalidKey):
+		return "invalid_key"
+	case errors.Is(err, ErrAuthentication):
+		return "authentication"
+	case errors.Is(err, ErrRandomness):
+		return "randomness"
+	case errors.Is(err, ErrSelfTest):
+		return "self_test"
+	case errors.Is(err, ErrTimeLocked):
+		return "time_locked"
+	case errors.Is(err, ErrKeyRingPolicy):
+		return "key_ring_policy"
+	case errors.Is(err, ErrExpired):
+		return "expired"
+	case errors.Is(err, ErrRevoked):
+		return "revoked"
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return "context"
+	default:
+		return "other"
+	}
+}
+
+var (
+	statsOperations   = [...]string{OpEncrypt, OpDecrypt, OpCreateSignKey, OpSign, OpSignWithKey, OpVerify, OpVerifyPK, OpMAC, OpGenerateTOTP, OpVerifyTOTP, OpTokenize, OpDetokenize, OpIssueOneTimeToken, OpRedeemOneTimeToken, OpSealSession, OpOpenSession, OpRevokeTOTP, OpVerifyTOTPRevocation, OpIssueWebAuthnChallenge, OpVerifyWebAuthnChallenge, OpDeriveID}
+	statsErrorClasses = [...]string{"invalid_format", "invalid_key", "authentication", "randomness", "key_derivation", "self_test", "time_locked", "key_ring_policy", "expired", "revoked", "context", "other"}
+)
+
+// Stats is a snapshot of the counters of a Protocol since its creation.
+type Stats struct {
+	// Operations is the amount of calls per operation (e.g. OpEncrypt),
+	// including failed ones.
+	Operations map[string]uint64 `json:"operations"`
+	// Failures is the amount of failed calls per ErrorClass.
+	Failures map[string]uint64 `json:"failures"`
+	// BytesEncrypted is the total size of all successfully encrypted data.
+	BytesEncrypted uint64 `json:"bytes_encrypted"`
+	// BytesDecrypted is the total size of all successfully decrypted data.
+	BytesDecrypted uint64 `json:"bytes_decrypted"`
+	// KDFCalls is the amount of keys requested from KeyPool instances.
+	KDFCalls uint64 `json:"kdf_calls"`
+	// KDFCacheHits is the amount of KDFCalls served by a cache, without
+	// deriving the key again (see CachingKeyPool).
+	KDFCacheHits uint64 `json:"kdf_cache_hits"`
+	// KDFCacheBypasses is the amount of KDFCalls that bypassed a cache and
+	// were derived by the underlying KeyPool (see PrefixStatsKeyPool).
+	// Together with the cache misses it is the load of the underlying
+	// KeyPool (e.g. an HSM).
+	KDFCacheBypasses uint64 `json:"kdf_cache_bypasses"`
+	// MaxKeyUsage is the highest amount of encryptions under a single key
+	// (see Protocol.TrackKeyUsage).
+	MaxKeyUsage uint64 `json:"max_key_usage"`
+	// KeysOverThreshold is the amount of keys that reached the lowest of
+	// KeyUsageConfig.Thresholds.
+	KeysOverThreshold uint64 `json:"keys_over_threshold"`
+	// SlowOperations is the amount of operations and key derivations that
+	// exceeded their budget (see Protocol.SetLatencyBudget).
+	SlowOperations uint64 `json:"slow_operations"`
+}
+
+// CachingKeyPool is an optional interface for KeyPool implementations that
+// cache keys. Protocol.Stats reports its hits as Stats.KDFCacheHits.
+type CachingKeyPool interface {
+	KeyPool
+	// CacheStats returns the amount of keys served from the cache (hits) and
+	// derived by the underlying KeyPool (misses).
+	CacheStats() (hits uint64, misses uint64)
+}
+
+// PrefixStatsKeyPool is an optional interface for caching KeyPool
+// implementations that bypass the cache for some keyRings (e.g. tearc with
+// Bypass). Protocol.KeyPools reports its counters per prefix as
+// KeyPoolStatus.Prefixes.
+type PrefixStatsKeyPool interface {
+	KeyPool
+	// PrefixStats calls visit for every keyRing prefix with the amount of
+	// keys served from the cache (hits), derived by the underlying KeyPool on
+	// a cache miss (misses) and derived by the underlying KeyPool without
+	// the cache (direct). bypass reports whether keys of the prefix bypass
+	// the cache.
+	PrefixStats(visit func(prefix string, bypass bool, hits uint64, misses uint64, direct uint64))
+}
+
+// ARCStatsKeyPool is an optional interface for caching KeyPool
+// implementations backed by a sharded adaptive replacement cache (e.g.
+// tearc). Protocol.KeyPools reports its shards as KeyPoolStatus.Shards.
+type ARCStatsKeyPool interface {
+	KeyPool
+	// ARCStats calls visit for every shard, in order, with its size, the
+	// target size of T1, the sizes of T1, T2, B1 and B2 and the hits of the
+	// ghost lists B1 and B2 (see ShardStatus).
+	ARCStats(visit func(size, target, t1, t2, b1, b2 int, b1Hits, b2Hits uint64))
+}
+
+// stats holds the counters of a Protocol. All fields are updated atomically.
+type stats struct {
+	bytesEncrypted uint64
+	bytesDecrypted uint64
+	kdfCalls       uint64
+	operations     [len(statsOperations)]uint64
+	failures       [len(statsErrorClasses)]uint64
+}
+
+// done counts an operation and its failure, if *err isn't nil. It is meant
+// to be deferred with a pointer to a named return value.
+func (s *stats) done(op string, err *error) {
+	for i, name := range statsOperations {
+		if name == op {
+			atomic.AddUint64(&s.operations[i], 1)
+			break
+		}
+	}
+	if *err == nil {
+		return
+	}
+	class := ErrorClass(*err)
+	for i, name := range statsErrorClasses {
+		if name == class {
+			atomic.AddUint64(&s.failures[i], 1)
+			break
+		}
+	}
+}
+
+// Stats returns a snapshot of the counters of p.
+func (p *Protocol) Stats() Stats {
+	s := Stats{
+		Operations:     make(map[string]uint64, len(statsOperations)),
+		Failures:       make(map[string]uint64, len(statsErrorClasses)),
+		BytesEncrypted: atomic.LoadUint64(&p.stats.bytesEncrypted),
+		BytesDecrypted: atomic.LoadUint64(&p.stats.bytesDecrypted),
+		KDFCalls:       atomic.LoadUint64(&p.stats.kdfCalls),
+	}
+	s.MaxKeyUsage, s.KeysOverThreshold = p.usage.stats()
+	s.SlowOperations = p.latency.slowOperations()
+	for i, name := range statsOperations {
+		s.Operations[name] = atomic.LoadUint64(&p.stats.operations[i])
+	}
+	for i, name := range statsErrorClasses {
+		s.Failures[name] = atomic.LoadUint64(&p.stats.failures[i])
+	}
+	for _, pool := range p.keys {
+		if cp, ok := pool.(CachingKeyPool); ok {
+			hits, _ := cp.CacheStats()
+			s.KDFCacheHits += hits
+		}
+		if pp, ok := pool.(PrefixStatsKeyPool); ok {
+			pp.PrefixStats(func(prefix string, bypass bool, hits uint64, misses uint64, direct uint64) {
+				s.KDFCacheBypasses += direct
+			})
+		}
+	}
+	return s
+}
@@ -0,0 +1,18 @@
+//go:build !js && !wasip1
+// +build !js,!wasip1
+
+package dvx
+
+import "expvar"
+
+// PublishExpvar publishes Stats as expvar variable name, so it is served as
+// JSON by the expvar handler (/debug/vars). Like expvar.Publish it panics if
+// name is already in use.
+//
+// PublishExpvar isn't available for js/wasm and wasip1, as expvar depends on
+// net/http.
+func (p *Protocol) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return p.Stats()
+	}))
+}
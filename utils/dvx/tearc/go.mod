@@ -4,5 +4,4 @@ go 1.16
 
 require (
 	azoo.dev/utils/tearc v0.0.0-20210830120504-67a26b8ff2a3
-	github.com/harwoeck/liblog/contract v1.1.2
 )
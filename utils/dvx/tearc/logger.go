@@ -0,0 +1,53 @@
+package tearc
+
+// Logger is the minimal logging interface used by KeyPool implementations.
+// keysAndValues are alternating keys (strings) and values. It is implemented
+// by *slog.Logger (log/slog). It is copied from the parent project
+// azoo.dev/utils/dvx
+type Logger interface {
+	Debug(msg string, keysAndValues ...interface{})
+	Info(msg string, keysAndValues ...interface{})
+	Warn(msg string, keysAndValues ...interface{})
+	Error(msg string, keysAndValues ...interface{})
+}
+
+// named returns a Logger that adds the key "logger" with name to every
+// entry. Names of nested calls are joined with a dot (e.g. "hsm.audit").
+// A nil log results in a Logger that discards every entry.
+func named(log Logger, name string) Logger {
+	if log == nil {
+		return nopLogger{}
+	}
+	if n, ok := log.(*namedLogger); ok {
+		return &namedLogger{log: n.log, name: n.name + "." + name}
+	}
+	return &namedLogger{log: log, name: name}
+}
+
+type namedLogger struct {
+	log  Logger
+	name string
+}
+
+func (n *namedLogger) Debug(msg string, keysAndValues ...interface{}) {
+	n.log.Debug(msg, append([]interface{}{"logger", n.name}, keysAndValues...)...)
+}
+
+func (n *namedLogger) Info(msg string, keysAndValues ...interface{}) {
+	n.log.Info(msg, append([]interface{}{"logger", n.name}, keysAndValues...)...)
+}
+
+func (n *namedLogger) Warn(msg string, keysAndValues ...interface{}) {
+	n.log.Warn(msg, append([]interface{}{"logger", n.name}, keysAndValues...)...)
+}
+
+func (n *namedLogger) Error(msg string, keysAndValues ...interface{}) {
+	n.log.Error(msg, append([]interface{}{"logger", n.name}, keysAndValues...)...)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...interface{}) {}
+func (nopLogger) Info(string, ...interface{})  {}
+func (nopLogger) Warn(string, ...interface{})  {}
+func (nopLogger) Error(string, ...interface{}) {}
@@ -11,10 +11,14 @@
 package tearc
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
-	logger "github.com/harwoeck/liblog/contract"
-
 	"azoo.dev/utils/tearc"
 )
 
@@ -31,6 +35,27 @@ type KeyPool interface {
 	Close() error
 }
 
+// ContextKeyPool is an optional interface for KeyPool implementations that
+// accept a context.Context. It is copied from the parent project
+// azoo.dev/utils/dvx
+type ContextKeyPool interface {
+	KeyPool
+	// KDF32Context is like KDF32, but with a context.Context.
+	KDF32Context(ctx context.Context, keyRing []byte) (key []byte, err error)
+	// KDF64Context is like KDF64, but with a context.Context.
+	KDF64Context(ctx context.Context, keyRing []byte) (key []byte, err error)
+}
+
+type loadObserverKey struct{}
+
+// WithLoadObserver returns a copy of ctx, that makes KeyPool instances created
+// by New call observe every time a key derivation with this ctx misses the
+// cache and loads the key from the underlying KeyPool. It can be used to
+// record cache hits per request (e.g. as tracing attribute).
+func WithLoadObserver(ctx context.Context, observe func()) context.Context {
+	return context.WithValue(ctx, loadObserverKey{}, observe)
+}
+
 // Config provides all options for a tearc KeyPool. Every field is required.
 // Not providing valid configuration values results in unspecified behaviour.
 // No checks are carried out!
@@ -40,32 +65,93 @@ type Config struct {
 	// Shards is the amount of shards used in the underlying tearc Cache. For
 	// example: 64
 	Shards int
-	// BucketMinTick is the minimum amount of time between bucket reaper runs.
+	// BucketMinTick is the minimum amount of time between cache reaper runs.
 	// For example: 1 * time.Second
 	BucketMinTick time.Duration
-	// BucketMaxTick is the maximum amount of time between bucket reaper runs.
+	// BucketMaxTick is the maximum amount of time between cache reaper runs.
 	// For example: 10 * time.Second
 	BucketMaxTick time.Duration
-	// AliveTime specifies how long cached keys should stay alive (in RAM) at
-	// maximum. They may get replaced sooner by page replacement (ARC). For
-	// example: 1 * time.Minute
+	// AliveTime specifies how long cached keys should stay alive (in RAM)
+	// after their last usage. They may get replaced sooner by page replacement
+	// (ARC). For example: 1 * time.Minute
 	AliveTime time.Duration
+	// MaxLifetime specifies how long cached keys stay alive (in RAM) at
+	// maximum, even if they are used constantly. Afterwards they are derived
+	// again by the underlying KeyPool. This field is optional, zero disables
+	// the limit. For example: 1 * time.Hour
+	MaxLifetime time.Duration
+	// MemoryPressure enables the eviction of cached keys when the process
+	// approaches its memory limit. This field is optional. For example:
+	// &tearc.MemoryPressure{Limit: 512 << 20, Threshold: 0.9, Evict: 0.25}
+	MemoryPressure *tearc.MemoryPressure
+	// Policy selects which derived keys are admitted to a full cache. With
+	// tearc.PolicyTinyLFU keys that are derived once (e.g. per-user TOTP
+	// keyRings used at login) don't evict often used keys. This field is
+	// optional, the default admits every key. For example: tearc.PolicyTinyLFU
+	Policy tearc.Policy
+	// MaxConcurrentLoads bounds the amount of concurrent key derivations of
+	// the underlying KeyPool on cache misses, e.g. to protect a rate-limited
+	// HSM during a cold start. This field is optional. For example:
+	// &tearc.LoadLimit{Global: 32, PerShard: 2}
+	MaxConcurrentLoads *tearc.LoadLimit
+	// Sharding selects how keys are mapped to the shards of the cache. This
+	// field is optional, the default is the fastest mapping. For example:
+	// tearc.ShardingJump
+	Sharding tearc.Sharding
+	// Bypass lists keyRing prefixes whose keys are never cached, but always
+	// derived by the underlying KeyPool, e.g. for keyRings that must not stay
+	// in memory. Prefixes are matched against the keyRing of the caller,
+	// without the label of the derivation. This field is optional. For
+	// example: []string{"unwrap/"}
+	Bypass []string
+	// StatsPrefixes lists keyRing prefixes whose derivations PrefixStats
+	// reports separately, in addition to those of Bypass. This field is
+	// optional. For example: []string{"users/", "totp/"}
+	StatsPrefixes []string
 }
 
 // New creates a new tearc Cache and wraps it as a KeyPool instance with the
-// underlying KeyPool `pool` as actual loader.
-func New(config *Config, pool KeyPool, log logger.Logger) (KeyPool, error) {
+// underlying KeyPool `pool` as actual loader. Cached keys are overwritten
+// with zeros when they are evicted and every call returns its own copy of a
+// key, which the caller may wipe after usage. The returned KeyPool also
+// implements ContextKeyPool and passes the context on to `pool` if it
+// implements ContextKeyPool itself. Its cache hits and misses are reported
+// by CacheStats (see (azoo.dev/utils/dvx).CachingKeyPool) and the cached keys
+// of a keyRing can be removed with Invalidate (see
+// (azoo.dev/utils/dvx).InvalidatingKeyPool). The cache can be changed while
+// the KeyPool is in use with Reconfigurable. Keys of keyRings with a prefix
+// of Bypass are derived by `pool` on every call, and PrefixStats reports
+// cached and direct derivations per prefix (see
+// (azoo.dev/utils/dvx).PrefixStatsKeyPool). ARCStats reports the partitions
+// of the cache per shard (see (azoo.dev/utils/dvx).ARCStatsKeyPool). If log
+// is nil nothing is logged.
+func New(config *Config, pool KeyPool, log Logger) (KeyPool, error) {
 	w := &wrapper{
-		log:    log.Named("tearc"),
-		config: config,
-		src:    pool,
+		log:       named(log, "tearc"),
+		src:       pool,
+		aliveTime: int64(config.AliveTime),
+	}
+	for _, prefix := range config.Bypass {
+		w.addPrefix(prefix, true)
+	}
+	for _, prefix := range config.StatsPrefixes {
+		w.addPrefix(prefix, false)
 	}
+	// derivations of keyRings without a prefix are counted under ""
+	w.counters = make([]prefixCounters, len(w.prefixes)+1)
 
 	var err error
 	w.cache, err = tearc.NewCache(config.Size, config.Shards, w.get, w.evict,
 		&tearc.BucketConfig{
-			MinTick: config.BucketMinTick,
-			MaxTick: config.BucketMaxTick,
+			MinTick:            config.BucketMinTick,
+			MaxTick:            config.BucketMaxTick,
+			MaxLifetime:        config.MaxLifetime,
+			Zeroize:            true,
+			CopyOnRead:         true,
+			MemoryPressure:     config.MemoryPressure,
+			Policy:             config.Policy,
+			MaxConcurrentLoads: config.MaxConcurrentLoads,
+			Sharding:           config.Sharding,
 		}, log)
 	if err != nil {
 		return nil, err
@@ -74,48 +160,224 @@ func New(config *Config, pool KeyPool, log logger.Logger) (KeyPool, error) {
 	return w, nil
 }
 
+// Reconfigurable is implemented by the KeyPool returned by New. It changes
+// the cache of a running KeyPool, e.g. to react to memory pressure or a
+// changed throughput of the underlying KeyPool, without dropping all cached
+// keys at once.
+type Reconfigurable interface {
+	// Resize changes the size of the cache (see tearc.Cache.Resize). When
+	// shrinking, only the least recently used keys are evicted.
+	Resize(size int) error
+	// SetTicks replaces BucketMinTick and BucketMaxTick.
+	SetTicks(minTick time.Duration, maxTick time.Duration) error
+	// SetAliveTime replaces AliveTime for keys derived afterwards. Cached
+	// keys keep their AliveTime until they are derived again.
+	SetAliveTime(aliveTime time.Duration)
+}
+
 type wrapper struct {
-	log    logger.Logger
-	config *Config
-	src    KeyPool
-	cache  tearc.Cache
+	// requests, loads and aliveTime are updated atomically and must stay
+	// 64-bit aligned
+	requests  uint64
+	loads     uint64
+	aliveTime int64
+
+	log   Logger
+	src   KeyPool
+	cache tearc.Cache
+
+	// prefixes are the prefixes of Bypass and StatsPrefixes. counters has
+	// one entry per prefix and a last one for all other keyRings
+	prefixes []string
+	bypass   []bool
+	counters []prefixCounters
+}
+
+// prefixCounters count the derivations of keyRings with a prefix. All fields
+// are updated atomically.
+type prefixCounters struct {
+	requests uint64
+	loads    uint64
+	direct   uint64
+}
+
+// addPrefix adds prefix, unless it was already added.
+func (w *wrapper) addPrefix(prefix string, bypass bool) {
+	for _, p := range w.prefixes {
+		if p == prefix {
+			return
+		}
+	}
+	w.prefixes = append(w.prefixes, prefix)
+	w.bypass = append(w.bypass, bypass)
+}
+
+// prefix returns the index of the longest prefix of keyRing (the input of a
+// KeyPool), or len(w.prefixes) if none matches.
+func (w *wrapper) prefix(keyRing []byte) int {
+	_, keyRingBytes := splitKDFInput(keyRing)
+	match := len(w.prefixes)
+	for i, prefix := range w.prefixes {
+		if bytes.HasPrefix(keyRingBytes, []byte(prefix)) && (match == len(w.prefixes) || len(prefix) > len(w.prefixes[match])) {
+			match = i
+		}
+	}
+	return match
 }
 
-func (w *wrapper) get(key string, loadInfo interface{}) (value interface{}, evictIn time.Duration, err error) {
-	switch loadInfo.(int) {
-	case 32:
-		w.log.Debug("loading 32 byte key", logger.NewField("key", key))
-		value, err = w.src.KDF32([]byte(key))
-	case 64:
-		w.log.Debug("loading 64 byte key", logger.NewField("key", key))
-		value, err = w.src.KDF64([]byte(key))
+// cacheKey returns the key of a derived key in the tearc Cache. KDF32 and
+// KDF64 may be called with equal keyRings (dv1 passes the keyRing of the
+// caller to both), so their keys are cached separately.
+func cacheKey(size int, keyRing []byte) string {
+	return strconv.Itoa(size) + ":" + string(keyRing)
+}
+
+func (w *wrapper) get(key string, lc tearc.LoaderContext) (value interface{}, ttl tearc.TTL, err error) {
+	atomic.AddUint64(&w.loads, 1)
+	if observe, ok := lc.Context.Value(loadObserverKey{}).(func()); ok {
+		observe()
 	}
+
+	keyRing := []byte(key[strings.IndexByte(key, ':')+1:])
+	atomic.AddUint64(&w.counters[w.prefix(keyRing)].loads, 1)
+	w.log.Debug(fmt.Sprintf("loading %d byte key", lc.Length), logKey(keyRing)...)
+	value, err = w.derive(lc.Context, lc.Length, keyRing)
 	if err != nil {
-		return nil, 0, err
+		return nil, tearc.TTL{}, err
 	}
 
-	evictIn = w.config.AliveTime
+	ttl = tearc.TTL{Idle: time.Duration(atomic.LoadInt64(&w.aliveTime))}
 	return
 }
 
-func (w *wrapper) evict(key string) {
-	w.log.Info("evicted key from cache", logger.NewField("key", key))
+// derive derives the key of keyRing with length bytes by the underlying
+// KeyPool.
+func (w *wrapper) derive(ctx context.Context, length int, keyRing []byte) ([]byte, error) {
+	cp, isContextPool := w.src.(ContextKeyPool)
+	switch {
+	case length == 32 && isContextPool:
+		return cp.KDF32Context(ctx, keyRing)
+	case length == 32:
+		return w.src.KDF32(keyRing)
+	case length == 64 && isContextPool:
+		return cp.KDF64Context(ctx, keyRing)
+	case length == 64:
+		return w.src.KDF64(keyRing)
+	default:
+		return nil, fmt.Errorf("tearc: unsupported key length %d", length)
+	}
 }
 
-func (w *wrapper) KDF32(keyRing []byte) (key []byte, err error) {
-	value, err := w.cache.Get(string(keyRing), 32)
+// kdf returns the key of keyRing with length bytes from the cache, or derives
+// it directly if its prefix bypasses the cache.
+func (w *wrapper) kdf(ctx context.Context, length int, keyRing []byte) ([]byte, error) {
+	i := w.prefix(keyRing)
+	if i < len(w.prefixes) && w.bypass[i] {
+		atomic.AddUint64(&w.counters[i].direct, 1)
+		return w.derive(ctx, length, keyRing)
+	}
+
+	atomic.AddUint64(&w.requests, 1)
+	atomic.AddUint64(&w.counters[i].requests, 1)
+	value, err := w.cache.Get(cacheKey(length, keyRing), tearc.LoaderContext{Context: ctx, Length: length})
 	if err != nil {
 		return nil, err
 	}
 	return value.([]byte), nil
 }
 
+func (w *wrapper) evict(key string) {
+	w.log.Info("evicted key from cache", logKey([]byte(key[strings.IndexByte(key, ':')+1:]))...)
+}
+
+// logKey returns the keys and values that identify the cached key of keyRing
+// in logs, without revealing keyRing.
+func logKey(keyRing []byte) []interface{} {
+	label, keyRingBytes := splitKDFInput(keyRing)
+	return []interface{}{"label", label, "key_ring_fingerprint", fingerprint(keyRingBytes)}
+}
+
+func (w *wrapper) KDF32(keyRing []byte) (key []byte, err error) {
+	return w.KDF32Context(context.Background(), keyRing)
+}
+
+func (w *wrapper) KDF32Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	return w.kdf(ctx, 32, keyRing)
+}
+
 func (w *wrapper) KDF64(keyRing []byte) (key []byte, err error) {
-	value, err := w.cache.Get(string(keyRing), 64)
-	if err != nil {
-		return nil, err
+	return w.KDF64Context(context.Background(), keyRing)
+}
+
+func (w *wrapper) KDF64Context(ctx context.Context, keyRing []byte) (key []byte, err error) {
+	return w.kdf(ctx, 64, keyRing)
+}
+
+// CacheStats implements (azoo.dev/utils/dvx).CachingKeyPool.
+func (w *wrapper) CacheStats() (hits uint64, misses uint64) {
+	misses = atomic.LoadUint64(&w.loads)
+	requests := atomic.LoadUint64(&w.requests)
+	if requests < misses {
+		return 0, misses
 	}
-	return value.([]byte), nil
+	return requests - misses, misses
+}
+
+// PrefixStats implements (azoo.dev/utils/dvx).PrefixStatsKeyPool. Keys of
+// keyRings without a prefix of Bypass or StatsPrefixes are reported under "".
+func (w *wrapper) PrefixStats(visit func(prefix string, bypass bool, hits uint64, misses uint64, direct uint64)) {
+	for i := range w.counters {
+		prefix, bypass := "", false
+		if i < len(w.prefixes) {
+			prefix, bypass = w.prefixes[i], w.bypass[i]
+		}
+		misses := atomic.LoadUint64(&w.counters[i].loads)
+		requests := atomic.LoadUint64(&w.counters[i].requests)
+		hits := uint64(0)
+		if requests > misses {
+			hits = requests - misses
+		}
+		visit(prefix, bypass, hits, misses, atomic.LoadUint64(&w.counters[i].direct))
+	}
+}
+
+// ARCStats implements (azoo.dev/utils/dvx).ARCStatsKeyPool.
+func (w *wrapper) ARCStats(visit func(size, target, t1, t2, b1, b2 int, b1Hits, b2Hits uint64)) {
+	for _, s := range w.cache.Stats() {
+		visit(s.Size, s.Target, s.T1, s.T2, s.B1, s.B2, s.B1Hits, s.B2Hits)
+	}
+}
+
+// Invalidate implements (azoo.dev/utils/dvx).InvalidatingKeyPool.
+func (w *wrapper) Invalidate(keyRing []byte) int {
+	removed := 0
+	for _, size := range []int{32, 64} {
+		if w.cache.Remove(cacheKey(size, keyRing)) {
+			removed++
+		}
+	}
+	return removed
+}
+
+// Generation implements (azoo.dev/utils/dvx).GenerationKeyPool by returning
+// the generation of the underlying KeyPool, or 0 if it has none.
+func (w *wrapper) Generation() uint64 {
+	if gp, ok := w.src.(interface{ Generation() uint64 }); ok {
+		return gp.Generation()
+	}
+	return 0
+}
+
+func (w *wrapper) Resize(size int) error {
+	return w.cache.Resize(size)
+}
+
+func (w *wrapper) SetTicks(minTick time.Duration, maxTick time.Duration) error {
+	return w.cache.SetTicks(minTick, maxTick)
+}
+
+func (w *wrapper) SetAliveTime(aliveTime time.Duration) {
+	atomic.StoreInt64(&w.aliveTime, int64(aliveTime))
 }
 
 func (w *wrapper) Close() error {
@@ -0,0 +1,139 @@
+package dvx
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// EncryptNotBefore is like Encrypt, but binds notBefore into the additional
+// data of the cipher: Decrypt refuses to decrypt it with ErrTimeLocked until
+// the Clock of the Protocol reaches notBefore. The timestamp (with a
+// resolution of seconds) is readable by anyone holding the cipher, but it
+// can't be changed without failing authentication.
+//
+// Time-locked ciphers are protected by the same key as ciphers of Encrypt.
+// Everyone who can derive that key is able to decrypt them without checking
+// the time, so the lock only holds for decryption through a Protocol.
+func (p *Protocol) EncryptNotBefore(keyRing string, data []byte, notBefore time.Time) (ciphertext string, err error) {
+	return p.EncryptNotBeforeContext(context.Background(), keyRing, data, notBefore)
+}
+
+// EncryptNotBeforeContext is like EncryptNotBefore, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) EncryptNotBeforeContext(ctx context.Context, keyRing string, data []byte, notBefore time.Time) (ciphertext string, err error) {
+	defer p.done(OpEncrypt, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpEncrypt)
+	if err != nil {
+		return "", err
+	}
+	if err = p.checkEncryptionContext(keyRing, nil); err != nil {
+		return "", err
+	}
+	key, err := p.kdf32(ctx, keyRingBytes, featureVersion, purposeEncrypt)
+	if err != nil {
+		return "", err
+	}
+
+	size := len(data)
+	cipher, err := sealTimeLocked(featureVersion, key, data, notBefore.Unix())
+	if err != nil {
+		return "", err
+	}
+
+	p.usage.add(keyRing, keyRingBytes)
+	atomic.AddUint64(&p.stats.bytesEncrypted, uint64(size))
+	return EncodeVersion(featureVersion, TimeLocked, cipher), nil
+}
+
+// decryptTimeLocked checks the timestamp of a cipher created by
+// sealTimeLocked against the Clock of p and decrypts it.
+func (p *Protocol) decryptTimeLocked(ctx context.Context, keyRing []byte, cipher []byte, version string) (data []byte, err error) {
+	if version == "dv1" {
+		return nil, errorf(ErrInvalidFormat, "dvx: dv1 doesn't support time-locked ciphers")
+	}
+	if len(cipher) < timeLockSize {
+		return nil, errorf(ErrInvalidFormat, "%s: cipher shorter (%d) than needed for timestamp (%d)", version, len(cipher), timeLockSize)
+	}
+
+	notBefore := time.Unix(int64(binary.BigEndian.Uint64(cipher)), 0)
+	now, err := p.now(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(notBefore) {
+		return nil, errorf(ErrTimeLocked, "dvx: cipher is locked until %s", notBefore.UTC().Format(time.RFC3339))
+	}
+
+	key, err := p.kdf32(ctx, keyRing, version, purposeEncrypt)
+	if err != nil {
+		return nil, err
+	}
+
+	return openTimeLocked(version, key, cipher)
+}
+
+// timeLockSize is the size of the big-endian Unix timestamp in front of
+// time-locked ciphers.
+const timeLockSize = 8
+
+// timeLockAAD returns the additional data of time-locked ciphers. The
+// TypePrefix separates them from ciphers of seal, which could otherwise be
+// reinterpreted with a timestamp prepended.
+func timeLockAAD(version string, timestamp []byte, nonce []byte) []byte {
+	aad := make([]byte, 0, len(version)+len(TimeLocked)+len(timestamp)+len(nonce))
+	aad = append(aad, version...)
+	aad = append(aad, TimeLocked...)
+	aad = append(aad, timestamp...)
+	return append(aad, nonce...)
+}
+
+// sealTimeLocked is like seal, but prefixes the cipher with notBefore and
+// authenticates it as part of the additional data.
+func sealTimeLocked(version string, key []byte, data []byte, notBefore int64) (cipher []byte, err error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, errorf(ErrInvalidKey, "%s: key must be %d bytes long", version, chacha20poly1305.KeySize)
+	}
+
+	aead, _ := chacha20poly1305.NewX(key) // err is always nil
+	cipher = make([]byte, timeLockSize+chacha20poly1305.NonceSizeX, timeLockSize+chacha20poly1305.NonceSizeX+len(data)+aead.Overhead())
+	binary.BigEndian.PutUint64(cipher, uint64(notBefore))
+	timestamp, nonce := cipher[:timeLockSize], cipher[timeLockSize:]
+
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, errorf(ErrRandomness, "%s: failed to read random %d bytes for nonceKey: %v", version, chacha20poly1305.NonceSizeX, err)
+	}
+	checkNonce(version, nonce)
+
+	return aead.Seal(cipher, nonce, data, timeLockAAD(version, timestamp, nonce)), nil
+}
+
+// openTimeLocked decrypts a cipher created by sealTimeLocked with the same
+// version. It doesn't check the timestamp.
+func openTimeLocked(version string, key []byte, cipher []byte) (data []byte, err error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, errorf(ErrInvalidKey, "%s: key must be %d bytes long", version, chacha20poly1305.KeySize)
+	}
+	if len(cipher) < timeLockSize+chacha20poly1305.NonceSizeX {
+		return nil, errorf(ErrInvalidFormat, "%s: cipher shorter (%d) than needed for timestamp and nonce (%d)", version, len(cipher), timeLockSize+chacha20poly1305.NonceSizeX)
+	}
+
+	timestamp := cipher[:timeLockSize]
+	nonce := cipher[timeLockSize : timeLockSize+chacha20poly1305.NonceSizeX]
+	encrypted := cipher[timeLockSize+chacha20poly1305.NonceSizeX:]
+
+	aead, _ := chacha20poly1305.NewX(key) // err is always nil
+	data, err = aead.Open(nil, nonce, encrypted, timeLockAAD(version, timestamp, nonce))
+	if err != nil {
+		return nil, errorf(ErrAuthentication, "%s: open failed: %v", version, err)
+	}
+
+	return
+}
@@ -0,0 +1,135 @@
+package dvx
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Tokenize derives a secret key `sk` using the keyRing and subsequently
+// encrypts value deterministically: equal values and keyRings always result
+// in equal tokens, so datasets can be joined on tokenized values (e.g. for
+// pseudonymization of PII). Tokens reveal whether two values are equal, but
+// nothing else about them.
+//
+// The nonce of the encryption is synthetic (SIV): a keyed MAC of value. It
+// doubles as checksum, that Detokenize verifies after decryption.
+func (p *Protocol) Tokenize(keyRing string, value string) (token string, err error) {
+	return p.TokenizeContext(context.Background(), keyRing, value)
+}
+
+// TokenizeContext is like Tokenize, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) TokenizeContext(ctx context.Context, keyRing string, value string) (token string, err error) {
+	defer p.done(OpTokenize, time.Now(), &err)
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpTokenize)
+	if err != nil {
+		return "", err
+	}
+	key, macKey, err := p.tokenKeys(ctx, keyRingBytes, featureVersion)
+	if err != nil {
+		return "", err
+	}
+
+	cipher, err := sealDeterministic(featureVersion, key, macKey, []byte(value))
+	if err != nil {
+		return "", err
+	}
+
+	return EncodeVersion(featureVersion, Tokenized, cipher), nil
+}
+
+// Detokenize derives a secret key `sk` using the keyRing and subsequently
+// decrypts token using `sk`.
+func (p *Protocol) Detokenize(keyRing string, token string) (value string, err error) {
+	return p.DetokenizeContext(context.Background(), keyRing, token)
+}
+
+// DetokenizeContext is like Detokenize, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) DetokenizeContext(ctx context.Context, keyRing string, token string) (value string, err error) {
+	defer p.done(OpDetokenize, time.Now(), &err)
+
+	v, cipher, err := DecodeExpect(token, Tokenized)
+	if err != nil {
+		return "", err
+	}
+	if v == "dv1" {
+		return "", errorf(ErrInvalidFormat, "dvx: dv1 doesn't support tokens")
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpDetokenize)
+	if err != nil {
+		return "", err
+	}
+	key, macKey, err := p.tokenKeys(ctx, keyRingBytes, v)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := openDeterministic(v, key, macKey, cipher)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// tokenKeys derives the encryption key and the key of the synthetic nonce.
+func (p *Protocol) tokenKeys(ctx context.Context, keyRing []byte, version string) (key []byte, macKey []byte, err error) {
+	key, err = p.kdf32(ctx, keyRing, version, purposeTokenize)
+	if err != nil {
+		return nil, nil, err
+	}
+	macKey, err = p.kdf64(ctx, keyRing, version, purposeTokenize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, macKey, nil
+}
+
+// syntheticNonce returns the nonce of sealDeterministic: the first bytes of
+// the keyed BLAKE2b-256 MAC of data.
+func syntheticNonce(macKey []byte, data []byte) ([]byte, error) {
+	tag, err := DV1{}.MAC256(macKey, data)
+	if err != nil {
+		return nil, err
+	}
+	return tag[:chacha20poly1305.NonceSizeX], nil
+}
+
+// sealDeterministic is like seal, but uses a synthetic nonce instead of a
+// random one, so equal data results in equal ciphers.
+func sealDeterministic(version string, key []byte, macKey []byte, data []byte) (cipher []byte, err error) {
+	if len(key) != chacha20poly1305.KeySize {
+		return nil, errorf(ErrInvalidKey, "%s: key must be %d bytes long", version, chacha20poly1305.KeySize)
+	}
+
+	nonce, err := syntheticNonce(macKey, data)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, _ := chacha20poly1305.NewX(key) // err is always nil
+	return aead.Seal(nonce, nonce, data, append([]byte(version), nonce...)), nil
+}
+
+// openDeterministic decrypts a cipher created by sealDeterministic and
+// verifies its synthetic nonce.
+func openDeterministic(version string, key []byte, macKey []byte, cipher []byte) (data []byte, err error) {
+	data, err = open(version, key, cipher, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := syntheticNonce(macKey, data)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(nonce, cipher[:chacha20poly1305.NonceSizeX]) != 1 {
+		return nil, errorf(ErrAuthentication, "%s: token checksum mismatch", version)
+	}
+	return data, nil
+}
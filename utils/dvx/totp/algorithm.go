@@ -0,0 +1,74 @@
+package totp
+
+import (
+	"crypto"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"hash"
+	"sync"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+// algorithms maps the names of TOTP.Algorithm to the hash of their HMAC. It
+// is guarded by algorithmsMu.
+var (
+	algorithmsMu sync.RWMutex
+	algorithms   = map[string]func() hash.Hash{
+		"SHA1":        sha1.New,
+		"SHA256":      sha256.New,
+		"SHA512":      sha512.New,
+		"SHA3-256":    sha3.New256,
+		"SHA3-512":    sha3.New512,
+		"BLAKE2b-256": newBLAKE2b256,
+		"BLAKE2b-512": newBLAKE2b512,
+	}
+)
+
+func newBLAKE2b256() hash.Hash {
+	h, _ := blake2b.New256(nil) // err is always nil without key
+	return h
+}
+
+func newBLAKE2b512() hash.Hash {
+	h, _ := blake2b.New512(nil) // err is always nil without key
+	return h
+}
+
+// standardAlgorithm reports whether algorithm is part of the Key Uri Format
+// (see TOTP.URI).
+func standardAlgorithm(algorithm string) bool {
+	return algorithm == "SHA1" || algorithm == "SHA256" || algorithm == "SHA512"
+}
+
+// RegisterAlgorithm makes the HMAC hash h available as TOTP.Algorithm name.
+// Besides the standard algorithms "SHA1", "SHA256" and "SHA512", the
+// algorithms "SHA3-256", "SHA3-512", "BLAKE2b-256" and "BLAKE2b-512" are
+// registered by default. Names can't be registered twice. TOTPs with
+// algorithms other than the standard ones can be generated and verified,
+// but not represented as URI.
+func RegisterAlgorithm(name string, h func() hash.Hash) error {
+	if name == "" || h == nil {
+		return fmt.Errorf("dvx/totp: algorithm name and hash are required")
+	}
+
+	algorithmsMu.Lock()
+	defer algorithmsMu.Unlock()
+	if _, ok := algorithms[name]; ok {
+		return fmt.Errorf("dvx/totp: algorithm %q is already registered", name)
+	}
+	algorithms[name] = h
+	return nil
+}
+
+// RegisterHash is like RegisterAlgorithm, but registers a crypto.Hash, whose
+// implementation must be linked into the binary.
+func RegisterHash(name string, h crypto.Hash) error {
+	if !h.Available() {
+		return fmt.Errorf("dvx/totp: hash %s isn't available", h)
+	}
+	return RegisterAlgorithm(name, h.New)
+}
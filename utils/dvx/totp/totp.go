@@ -2,14 +2,10 @@ package totp
 
 import (
 	"crypto/hmac"
-	"crypto/sha1"
-	"crypto/sha256"
-	"crypto/sha512"
 	"crypto/subtle"
 	"encoding/base32"
 	"encoding/binary"
 	"fmt"
-	"hash"
 	"math"
 	"net/url"
 	"strconv"
@@ -95,7 +91,7 @@ func ParseFromURI(uri string) (*TOTP, error) {
 			}
 		case "algorithm":
 			a := values[0]
-			if a != "SHA1" && a != "SHA256" && a != "SHA512" {
+			if !standardAlgorithm(a) {
 				return nil, fmt.Errorf("dvx/totp: invalid algorithm selected")
 			}
 			t.Algorithm = a
@@ -132,7 +128,14 @@ type TOTP struct {
 
 // URI formats the TOTP object as specified in
 // https://github.com/google/google-authenticator/wiki/Key-Uri-Format
+//
+// The format only knows the algorithms "SHA1", "SHA256" and "SHA512". For
+// other algorithms (see RegisterAlgorithm) URI returns an empty string, as
+// authenticators would calculate codes with a different algorithm.
 func (t *TOTP) URI() string {
+	if !standardAlgorithm(t.Algorithm) {
+		return ""
+	}
 	issuer := url.PathEscape(t.Issuer)
 
 	b := strings.Builder{}
@@ -157,7 +160,15 @@ func (t *TOTP) URI() string {
 	return b.String()
 }
 
+// Generate returns the code of the current period.
 func (t *TOTP) Generate() (string, error) {
+	return t.GenerateAt(time.Now())
+}
+
+// GenerateAt returns the code of the period containing now. Callers with
+// their own time source (e.g. an NTP-disciplined clock) pass its time,
+// tests a fixed one.
+func (t *TOTP) GenerateAt(now time.Time) (string, error) {
 	if len(t.Secret) == 0 {
 		return "", fmt.Errorf("dvx/totp: secret is emtpy")
 	}
@@ -165,23 +176,22 @@ func (t *TOTP) Generate() (string, error) {
 		return "", fmt.Errorf("dvx/totp: invalid period selection")
 	}
 
-	counter := time.Now().Unix() / int64(t.Period)
+	counter := now.Unix() / int64(t.Period)
 
-	return generateOTP(t.Secret, t.Algorithm, t.Digits, counter)
+	return generate(t.Secret, t.Algorithm, t.Digits, counter)
 }
 
+// generate is generateOTP, replaced by tests to count its calls.
+var generate = generateOTP
+
 func generateOTP(secret []byte, algorithm string, digits int, counter int64) (code string, err error) {
-	var mac hash.Hash
-	switch algorithm {
-	case "SHA1":
-		mac = hmac.New(sha1.New, secret)
-	case "SHA256":
-		mac = hmac.New(sha256.New, secret)
-	case "SHA512":
-		mac = hmac.New(sha512.New, secret)
-	default:
+	algorithmsMu.RLock()
+	newHash, ok := algorithms[algorithm]
+	algorithmsMu.RUnlock()
+	if !ok {
 		return "", fmt.Errorf("dvx/totp: invalid algorithm selection")
 	}
+	mac := hmac.New(newHash, secret)
 
 	counterBytes := make([]byte, 8)
 	binary.BigEndian.PutUint64(counterBytes, uint64(counter))
@@ -211,11 +221,42 @@ func generateOTP(secret []byte, algorithm string, digits int, counter int64) (co
 	return
 }
 
+// Verify reports whether code is the code of the current period.
 func (t *TOTP) Verify(code string) (valid bool, err error) {
-	expected, err := t.Generate()
-	if err != nil {
+	return t.VerifyWithSkew(code, 0)
+}
+
+// VerifyWithSkew reports whether code is the code of the current period or of
+// one of the skew periods before and after it, to tolerate clock drift of
+// authenticators. For example, a skew of 1 accepts the codes of 3 periods.
+//
+// The codes of all periods are calculated and compared in constant time, so
+// the duration of VerifyWithSkew reveals neither whether nor in which period
+// code matched.
+func (t *TOTP) VerifyWithSkew(code string, skew int) (valid bool, err error) {
+	return t.VerifyAt(code, time.Now(), skew)
+}
+
+// VerifyAt is like VerifyWithSkew, but uses the period containing now
+// instead of the current one (see GenerateAt).
+func (t *TOTP) VerifyAt(code string, now time.Time, skew int) (valid bool, err error) {
+	if skew < 0 {
+		return false, fmt.Errorf("dvx/totp: skew cannot be negative")
+	}
+	if len(t.Secret) == 0 || t.Period != 30 {
 		return false, nil
 	}
 
-	return subtle.ConstantTimeCompare([]byte(expected), []byte(code)) == 1, nil
+	counter := now.Unix() / int64(t.Period)
+	match := 0
+	for i := -skew; i <= skew; i++ {
+		expected, err := generate(t.Secret, t.Algorithm, t.Digits, counter+int64(i))
+		if err != nil {
+			// depends on the configuration of t only, not on code
+			return false, nil
+		}
+		match |= subtle.ConstantTimeCompare([]byte(expected), []byte(code))
+	}
+
+	return match == 1, nil
 }
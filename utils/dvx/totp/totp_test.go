@@ -1,10 +1,16 @@
 package totp
 
 import (
+	"crypto"
+	"math"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/sha3"
 )
 
 type testCase struct {
@@ -48,3 +54,127 @@ func TestTOTP_URI(t *testing.T) {
 		})
 	}
 }
+
+func TestTOTP_GenerateAt(t *testing.T) {
+	// RFC 6238 test vectors of SHA1
+	totp := &TOTP{Secret: []byte("12345678901234567890"), Algorithm: "SHA1", Digits: 8, Period: 30}
+	for unix, expected := range map[int64]string{59: "94287082", 1111111109: "07081804", 2000000000: "69279037"} {
+		code, err := totp.GenerateAt(time.Unix(unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, expected, code)
+	}
+}
+
+func TestTOTP_VerifyWithSkew(t *testing.T) {
+	totp := &TOTP{Secret: []byte("12345678901234567890"), Algorithm: "SHA1", Digits: 6, Period: 30}
+	now := time.Unix(59, 0) // counter 1
+
+	code := func(counter int64) string {
+		c, err := generateOTP(totp.Secret, totp.Algorithm, totp.Digits, counter)
+		require.NoError(t, err)
+		return c
+	}
+
+	for _, tt := range []struct {
+		counter int64
+		skew    int
+		valid   bool
+	}{
+		{1, 0, true},
+		{0, 0, false},
+		{0, 1, true},
+		{2, 1, true},
+		{3, 1, false},
+		{3, 2, true},
+	} {
+		valid, err := totp.VerifyAt(code(tt.counter), now, tt.skew)
+		require.NoError(t, err)
+		assert.Equal(t, tt.valid, valid, "counter %d, skew %d", tt.counter, tt.skew)
+	}
+
+	_, err := totp.VerifyAt(code(1), now, -1)
+	assert.Error(t, err)
+}
+
+func TestTOTP_VerifyWithSkew_ConstantTime(t *testing.T) {
+	totp := &TOTP{Secret: []byte("12345678901234567890"), Algorithm: "SHA256", Digits: 8, Period: 30}
+	now := time.Unix(1700000000, 0)
+	counter := now.Unix() / 30
+	const skew = 3
+
+	calls := 0
+	generate = func(secret []byte, algorithm string, digits int, counter int64) (string, error) {
+		calls++
+		return generateOTP(secret, algorithm, digits, counter)
+	}
+	defer func() { generate = generateOTP }()
+
+	codes := map[string]string{"none": "00000000"}
+	for _, window := range []int64{-skew, 0, skew} {
+		c, err := generateOTP(totp.Secret, totp.Algorithm, totp.Digits, counter+window)
+		require.NoError(t, err)
+		codes[strconv.FormatInt(window, 10)] = c
+	}
+
+	// every candidate is evaluated, no matter which window matched
+	for name, code := range codes {
+		calls = 0
+		valid, err := totp.VerifyAt(code, now, skew)
+		require.NoError(t, err)
+		assert.Equal(t, name != "none", valid, name)
+		assert.Equal(t, 2*skew+1, calls, name)
+	}
+
+	// and the durations don't depend on it either: the fastest of many runs
+	// of a code matching in the first window is close to that of one
+	// matching in the last window or not at all. The runs are interleaved, so
+	// the load of the machine affects all of them alike.
+	names := []string{"-3", "3", "none"}
+	fastest := make([]time.Duration, len(names))
+	for i := range fastest {
+		fastest[i] = time.Duration(math.MaxInt64)
+	}
+	for i := 0; i < 2000; i++ {
+		for j, name := range names {
+			start := time.Now()
+			_, _ = totp.VerifyAt(codes[name], now, skew)
+			if d := time.Since(start); d < fastest[j] {
+				fastest[j] = d
+			}
+		}
+	}
+	for j := 1; j < len(names); j++ {
+		ratio := float64(fastest[j]) / float64(fastest[0])
+		assert.True(t, ratio > 0.5 && ratio < 2, "%s took %s, first window %s", names[j], fastest[j], fastest[0])
+	}
+}
+
+func TestRegisterAlgorithm(t *testing.T) {
+	// RFC 6238 test vector of SHA1 at T = 59
+	sha1TOTP := &TOTP{Secret: []byte("12345678901234567890"), Algorithm: "SHA1", Digits: 8, Period: 30}
+	valid, err := sha1TOTP.VerifyAt("94287082", time.Unix(59, 0), 0)
+	require.NoError(t, err)
+	assert.True(t, valid)
+
+	assert.Error(t, RegisterAlgorithm("SHA1", sha3.New256))
+	assert.Error(t, RegisterHash("MD4", crypto.MD4))
+	require.NoError(t, RegisterHash("SHA3-384", crypto.SHA3_384))
+	assert.Error(t, RegisterHash("SHA3-384", crypto.SHA3_384))
+
+	for _, algorithm := range []string{"SHA3-256", "SHA3-384", "SHA3-512", "BLAKE2b-256", "BLAKE2b-512"} {
+		totp := &TOTP{Secret: []byte("12345678901234567890"), Algorithm: algorithm, Digits: 8, Period: 30}
+		code, err := generateOTP(totp.Secret, totp.Algorithm, totp.Digits, 1)
+		require.NoError(t, err, algorithm)
+		assert.NotEqual(t, "94287082", code, algorithm)
+
+		valid, err := totp.VerifyAt(code, time.Unix(59, 0), 0)
+		require.NoError(t, err)
+		assert.True(t, valid, algorithm)
+
+		// registered algorithms aren't part of the Key Uri Format
+		assert.Empty(t, totp.URI(), algorithm)
+		totp.Algorithm = "SHA256"
+		_, err = ParseFromURI(strings.Replace(totp.URI(), "SHA256", algorithm, 1))
+		assert.Error(t, err, algorithm)
+	}
+}
@@ -0,0 +1,119 @@
+package dvx
+
+import (
+	"sync"
+)
+
+// KeyUsageConfig configures the tracking of encryptions per derived key (see
+// Protocol.TrackKeyUsage).
+type KeyUsageConfig struct {
+	// Thresholds are amounts of encryptions under a single key. When a key
+	// reaches one of them a warning is logged and OnThreshold is called. For
+	// example: []uint64{1 << 32, 1 << 40}
+	Thresholds []uint64
+	// Logger receives the warnings with the key "logger" set to
+	// "dvx_key_usage.audit". Optional.
+	Logger Logger
+	// OnThreshold is called synchronously by the encryption that reached a
+	// threshold, e.g. to schedule the rotation of keyRing. Optional.
+	OnThreshold func(keyRing string, encryptions uint64, threshold uint64)
+}
+
+// TrackKeyUsage enables counting the encryptions (Encrypt and
+// EncryptNotBefore) under every derived key of p. Counts are reported by
+// KeyUsage and summarized in Stats (MaxKeyUsage and KeysOverThreshold).
+//
+// XChaCha20-Poly1305 uses random 192 bit nonces, so even billions of
+// encryptions under one key don't risk a nonce collision. The counters exist
+// to demonstrate that key usage stays within the bounds of a policy and to
+// trigger rotation. They are kept in memory of p only: encryptions of other
+// processes and before a restart aren't included. Every keyRing occupies an
+// entry until p is discarded. TrackKeyUsage must be called before p is used.
+func (p *Protocol) TrackKeyUsage(config *KeyUsageConfig) {
+	p.usage = &keyUsage{
+		config: *config,
+		log:    named(config.Logger, "dvx_key_usage.audit"),
+		counts: make(map[string]uint64),
+	}
+}
+
+// KeyUsage returns the amount of encryptions under the key of keyRing, or 0 if
+// TrackKeyUsage wasn't called.
+func (p *Protocol) KeyUsage(keyRing string) uint64 {
+	if p.usage == nil {
+		return 0
+	}
+
+	p.usage.mu.Lock()
+	defer p.usage.mu.Unlock()
+	return p.usage.counts[string(keyRingToBytes(keyRing))]
+}
+
+// keyUsage counts the encryptions per keyRing of a Protocol.
+type keyUsage struct {
+	config KeyUsageConfig
+	log    Logger
+
+	mu     sync.Mutex
+	counts map[string]uint64
+	max    uint64
+	over   uint64
+}
+
+// add counts an encryption under the key of keyRing and reports thresholds it
+// reached.
+func (u *keyUsage) add(keyRing string, keyRingBytes []byte) {
+	if u == nil {
+		return
+	}
+
+	u.mu.Lock()
+	n := u.counts[string(keyRingBytes)] + 1
+	u.counts[string(keyRingBytes)] = n
+	if n > u.max {
+		u.max = n
+	}
+	var reached []uint64
+	for _, threshold := range u.config.Thresholds {
+		if n == threshold {
+			reached = append(reached, threshold)
+		}
+	}
+	if len(reached) > 0 && n == u.lowestThreshold() {
+		u.over++
+	}
+	u.mu.Unlock()
+
+	for _, threshold := range reached {
+		u.log.Warn("key usage threshold reached",
+			"key_ring_fingerprint", fingerprint(keyRingBytes),
+			"encryptions", n,
+			"threshold", threshold)
+		if u.config.OnThreshold != nil {
+			u.config.OnThreshold(keyRing, n, threshold)
+		}
+	}
+}
+
+// lowestThreshold returns the lowest of the Thresholds.
+func (u *keyUsage) lowestThreshold() uint64 {
+	lowest := u.config.Thresholds[0]
+	for _, threshold := range u.config.Thresholds[1:] {
+		if threshold < lowest {
+			lowest = threshold
+		}
+	}
+	return lowest
+}
+
+// stats returns the highest amount of encryptions under a single key and the
+// amount of keys that reached a threshold.
+func (u *keyUsage) stats() (max uint64, over uint64) {
+	if u == nil {
+		return 0, 0
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.max, u.over
+}
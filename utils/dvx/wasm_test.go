@@ -0,0 +1,47 @@
+package dvx
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildWASM ensures that the verification surface (Decode, VerifyPK,
+// totp.ParseFromURI) builds for browsers and WASI without pulling in
+// net/http.
+func TestBuildWASM(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the package for other platforms")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go command not available")
+	}
+	platforms, err := exec.Command(goBin, "tool", "dist", "list").Output()
+	require.NoError(t, err)
+
+	for _, platform := range []string{"js/wasm", "wasip1/wasm"} {
+		t.Run(platform, func(t *testing.T) {
+			if !strings.Contains(string(platforms), platform+"\n") {
+				t.Skipf("%s isn't supported by this go version", platform)
+			}
+			parts := strings.Split(platform, "/")
+			env := append(os.Environ(), "GOOS="+parts[0], "GOARCH="+parts[1])
+
+			cmd := exec.Command(goBin, "build", "-o", os.DevNull, ".", "./totp")
+			cmd.Env = env
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err, string(out))
+
+			cmd = exec.Command(goBin, "list", "-deps", ".", "./totp")
+			cmd.Env = env
+			out, err = cmd.Output()
+			require.NoError(t, err)
+			assert.NotContains(t, strings.Split(string(out), "\n"), "net/http")
+		})
+	}
+}
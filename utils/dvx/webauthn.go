@@ -0,0 +1,171 @@
+package dvx
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	// webAuthnNonceSize is the size of the random nonce of WebAuthn
+	// challenges. WebAuthn requires challenges with at least 16 random bytes.
+	webAuthnNonceSize = 16
+	// webAuthnTagSize is the size of the MAC of WebAuthn challenges.
+	webAuthnTagSize = 32
+	// webAuthnHeaderSize is the size of the expiry and nonce in front of the
+	// tag.
+	webAuthnHeaderSize = 8 + webAuthnNonceSize
+)
+
+// IssueWebAuthnChallenge derives a secret key `sk` using the keyRing, and from
+// it a key of the relying party rpID (e.g. "example.com"), and issues a
+// stateless challenge for a WebAuthn registration or authentication ceremony
+// (passkeys), that VerifyWebAuthnChallenge accepts until ttl elapsed. The
+// challenge embeds the expiry and a random nonce, authenticated with a keyed
+// MAC over both and binding. binding ties the challenge to the session or
+// user it was issued for (e.g. a session-id or user handle) and isn't part of
+// the challenge itself.
+//
+// Pass []byte(challenge) as challenge of the PublicKeyCredentialCreationOptions
+// or PublicKeyCredentialRequestOptions. Clients return it base64url encoded in
+// the "challenge" of clientDataJSON.
+func (p *Protocol) IssueWebAuthnChallenge(keyRing string, rpID string, binding string, ttl time.Duration) (challenge string, err error) {
+	return p.IssueWebAuthnChallengeContext(context.Background(), keyRing, rpID, binding, ttl)
+}
+
+// IssueWebAuthnChallengeContext is like IssueWebAuthnChallenge, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) IssueWebAuthnChallengeContext(ctx context.Context, keyRing string, rpID string, binding string, ttl time.Duration) (challenge string, err error) {
+	defer p.done(OpIssueWebAuthnChallenge, time.Now(), &err)
+
+	if err = p.checkEntropy(); err != nil {
+		return "", err
+	}
+	if ttl <= 0 {
+		return "", errorf(ErrInvalidFormat, "dvx: ttl of webauthn challenge must be positive")
+	}
+	if rpID == "" {
+		return "", errorf(ErrInvalidFormat, "dvx: rpID of webauthn challenge must not be empty")
+	}
+	now, err := p.now(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpIssueWebAuthnChallenge)
+	if err != nil {
+		return "", err
+	}
+	key, err := p.deriveWebAuthnKey(ctx, keyRingBytes, rpID, featureVersion)
+	if err != nil {
+		return "", err
+	}
+
+	data := make([]byte, webAuthnHeaderSize, webAuthnHeaderSize+webAuthnTagSize)
+	binary.BigEndian.PutUint64(data, uint64(now.Add(ttl).Unix()))
+	if _, err = io.ReadFull(rand.Reader, data[8:webAuthnHeaderSize]); err != nil {
+		return "", errorf(ErrRandomness, "%s: failed to read random %d bytes for webauthn nonce: %v", featureVersion, webAuthnNonceSize, err)
+	}
+
+	data, err = appendWebAuthnTag(data, featureVersion, key, binding)
+	if err != nil {
+		return "", err
+	}
+	return EncodeVersion(featureVersion, WebAuthnChallenge, data), nil
+}
+
+// VerifyWebAuthnChallenge derives the key of the relying party rpID like
+// IssueWebAuthnChallenge and verifies clientChallenge, the base64url encoded
+// "challenge" of the clientDataJSON returned by the client. It fails with
+// ErrAuthentication if the challenge wasn't issued for keyRing, rpID and
+// binding or was changed, and with ErrExpired once the Clock of p reached its
+// expiry, which it returns otherwise.
+//
+// VerifyWebAuthnChallenge only verifies the challenge, not the WebAuthn
+// response (signature, origin, rpIdHash, …), which remains the responsibility
+// of a WebAuthn library. It doesn't remember verified challenges: callers must
+// reject challenges that were already used until they expire.
+func (p *Protocol) VerifyWebAuthnChallenge(keyRing string, rpID string, binding string, clientChallenge string) (expiresAt time.Time, err error) {
+	return p.VerifyWebAuthnChallengeContext(context.Background(), keyRing, rpID, binding, clientChallenge)
+}
+
+// VerifyWebAuthnChallengeContext is like VerifyWebAuthnChallenge, but passes ctx to the KeyPool (see ContextKeyPool)
+// and returns ctx.Err() if ctx is done before the key derivation.
+func (p *Protocol) VerifyWebAuthnChallengeContext(ctx context.Context, keyRing string, rpID string, binding string, clientChallenge string) (expiresAt time.Time, err error) {
+	defer p.done(OpVerifyWebAuthnChallenge, time.Now(), &err)
+
+	// clients encode without padding, but some libraries pass it on
+	challenge, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(clientChallenge, "="))
+	if err != nil {
+		return time.Time{}, errorf(ErrInvalidFormat, "dvx: webauthn challenge not base64url: %v", err)
+	}
+	v, data, err := DecodeExpect(string(challenge), WebAuthnChallenge)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if v == "dv1" {
+		return time.Time{}, errorf(ErrInvalidFormat, "dvx: dv1 doesn't support webauthn challenges")
+	}
+	if len(data) != webAuthnHeaderSize+webAuthnTagSize {
+		return time.Time{}, errorf(ErrInvalidFormat, "%s: webauthn challenge has invalid length (%d). Expected %d", v, len(data), webAuthnHeaderSize+webAuthnTagSize)
+	}
+
+	keyRingBytes, err := p.keyRingInput(keyRing, OpVerifyWebAuthnChallenge)
+	if err != nil {
+		return time.Time{}, err
+	}
+	key, err := p.deriveWebAuthnKey(ctx, keyRingBytes, rpID, v)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	header := data[:webAuthnHeaderSize]
+	expected, err := appendWebAuthnTag(append([]byte(nil), header...), v, key, binding)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if subtle.ConstantTimeCompare(expected, data) != 1 {
+		return time.Time{}, errorf(ErrAuthentication, "%s: webauthn challenge verification failed", v)
+	}
+
+	// the expiry is only checked after the tag, so unauthenticated challenges
+	// never reveal whether they are expired
+	expiresAt = time.Unix(int64(binary.BigEndian.Uint64(header)), 0)
+	now, err := p.now(ctx)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !now.Before(expiresAt) {
+		return time.Time{}, errorf(ErrExpired, "dvx: webauthn challenge expired at %s", expiresAt.UTC().Format(time.RFC3339))
+	}
+	return expiresAt, nil
+}
+
+// deriveWebAuthnKey derives the key of the relying party rpID, so challenges
+// of one relying party are never valid for another one.
+func (p *Protocol) deriveWebAuthnKey(ctx context.Context, keyRing []byte, rpID string, version string) ([]byte, error) {
+	key, err := p.kdf64(ctx, keyRing, version, purposeWebAuthn)
+	if err != nil {
+		return nil, err
+	}
+	return primitiveOf(version).MAC512(key, []byte(rpID))
+}
+
+// appendWebAuthnTag appends the MAC of a WebAuthn challenge to data. The MAC
+// is calculated over the version and TypePrefix followed by data and binding.
+// data has a fixed size, so binding is unambiguous.
+func appendWebAuthnTag(data []byte, version string, key []byte, binding string) ([]byte, error) {
+	msg := make([]byte, 0, len(version)+len(WebAuthnChallenge)+len(data)+len(binding))
+	msg = append(append(append(append(msg, version...), WebAuthnChallenge...), data...), binding...)
+
+	tag, err := primitiveOf(version).MAC256(key, msg)
+	if err != nil {
+		return nil, err
+	}
+	return append(data, tag...), nil
+}
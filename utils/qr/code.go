@@ -0,0 +1,192 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+
+	"github.com/skip2/go-qrcode"
+)
+
+// Level is the error correction level of a QR code. Lower levels need fewer
+// modules for the same data, but recover less damage.
+type Level int
+
+const (
+	// LevelQ recovers 25% of the data. It's the default of Options and the
+	// level of PNGRaw, EPSRaw and PDFRaw.
+	LevelQ Level = iota
+	// LevelL recovers 7% of the data.
+	LevelL
+	// LevelM recovers 15% of the data.
+	LevelM
+	// LevelH recovers 30% of the data.
+	LevelH
+)
+
+// recoveryLevel returns the qrcode.RecoveryLevel of l.
+func (l Level) recoveryLevel() (qrcode.RecoveryLevel, error) {
+	switch l {
+	case LevelL:
+		return qrcode.Low, nil
+	case LevelM:
+		return qrcode.Medium, nil
+	case LevelQ:
+		return qrcode.High, nil
+	case LevelH:
+		return qrcode.Highest, nil
+	default:
+		return 0, fmt.Errorf("qr: invalid level %d", l)
+	}
+}
+
+// Options control the encoding of New, e.g. for tiny displays on which the
+// automatically selected code is too dense to scan.
+type Options struct {
+	// Level is the error correction level. For example: LevelL
+	Level Level
+	// Version forces the QR version (1-40), which defines the number of
+	// modules per side (17 + 4 * Version). Zero selects the smallest version
+	// that fits the data. For example: 3
+	Version int
+	// DisableBorder omits the quiet zone of 4 modules around the code. The
+	// rendering surface must provide it instead.
+	DisableBorder bool
+	// MaxVersion limits the version selected if Version is zero, so data too
+	// large for a scannable code fails instead of producing an ultra-dense
+	// code. Zero doesn't limit New (but see Check). For example: 10
+	MaxVersion int
+	// Mask forces the mask pattern: 1 to 8 select the patterns 000 to 111 of
+	// ISO/IEC 18004, 1 to 4 the patterns 00 to 11 of Micro QR codes. Zero
+	// selects the pattern that is easiest to scan. For example: 3
+	Mask int
+	// Micro encodes a Micro QR code, with a single finder pattern, 11 to 17
+	// modules per side and a quiet zone of 2 modules, for up to 35 digits,
+	// 21 alphanumeric characters or 15 bytes. Version and MaxVersion range
+	// from 1 (M1) to 4 (M4). M1 only holds digits and requires LevelL, LevelQ
+	// requires M4 and LevelH isn't supported.
+	Micro bool
+}
+
+// Code is an encoded QR code, that can be rendered in multiple formats.
+type Code struct {
+	// symbol are the modules without quiet zone, indexed by row and column.
+	// Dark modules are true.
+	symbol  [][]bool
+	border  int
+	version int
+	mask    int
+	micro   bool
+}
+
+// New encodes data into a QR code. A nil opts uses the defaults of Options.
+func New(data string, opts *Options) (*Code, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Micro {
+		return newMicro(data, opts)
+	}
+	level, err := opts.Level.recoveryLevel()
+	if err != nil {
+		return nil, err
+	}
+	if opts.Mask < 0 || opts.Mask > len(masks) {
+		return nil, fmt.Errorf("qr: invalid mask %d", opts.Mask)
+	}
+
+	var code *qrcode.QRCode
+	if opts.Version == 0 {
+		code, err = qrcode.New(data, level)
+	} else {
+		code, err = qrcode.NewWithForcedVersion(data, opts.Version, level)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("qr: encoding data failed: %w", err)
+	}
+	if opts.Version == 0 && opts.MaxVersion != 0 && code.VersionNumber > opts.MaxVersion {
+		return nil, fmt.Errorf("qr: data needs version %d, but MaxVersion is %d: shorten the data or lower the Level", code.VersionNumber, opts.MaxVersion)
+	}
+	// the quiet zone is added by bitmap
+	code.DisableBorder = true
+
+	c := &Code{symbol: code.Bitmap(), border: 4, version: code.VersionNumber}
+	if opts.DisableBorder {
+		c.border = 0
+	}
+	if c.mask, err = readMask(c.symbol, opts.Level); err != nil {
+		return nil, err
+	}
+	if opts.Mask != 0 && opts.Mask-1 != c.mask {
+		c.mask = opts.Mask - 1
+		if err = remask(c.symbol, c.version, opts.Level, c.mask); err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// Version returns the QR version of c, or the Micro QR version (1 for M1 to
+// 4 for M4).
+func (c *Code) Version() int {
+	return c.version
+}
+
+// Micro reports whether c is a Micro QR code.
+func (c *Code) Micro() bool {
+	return c.micro
+}
+
+// Mask returns the mask pattern of c, numbered like Options.Mask.
+func (c *Code) Mask() int {
+	return c.mask + 1
+}
+
+// Size returns the number of modules per side of c, including the quiet
+// zone.
+func (c *Code) Size() int {
+	return len(c.symbol) + 2*c.border
+}
+
+// bitmap returns the modules of c including the quiet zone, indexed by row
+// and column. Dark modules are true.
+func (c *Code) bitmap() [][]bool {
+	bitmap := make([][]bool, c.Size())
+	for i := range bitmap {
+		bitmap[i] = make([]bool, c.Size())
+		if i >= c.border && i < c.border+len(c.symbol) {
+			copy(bitmap[i][c.border:], c.symbol[i-c.border])
+		}
+	}
+	return bitmap
+}
+
+// PNG renders c as png image with a width and height of size pixels. If size
+// is smaller than Size, the image has a pixel per module instead.
+func (c *Code) PNG(size int) ([]byte, error) {
+	bitmap := c.bitmap()
+	if size < len(bitmap) {
+		size = len(bitmap)
+	}
+
+	img := image.NewPaletted(image.Rect(0, 0, size, size), color.Palette{color.White, color.Black})
+	// every pixel shows the module it is nearest to
+	modulesPerPixel := float64(len(bitmap)) / float64(size)
+	for y := 0; y < size; y++ {
+		row := bitmap[int(float64(y)*modulesPerPixel)]
+		for x := 0; x < size; x++ {
+			if row[int(float64(x)*modulesPerPixel)] {
+				img.Pix[img.PixOffset(x, y)] = 1
+			}
+		}
+	}
+
+	b := bytes.Buffer{}
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&b, img); err != nil {
+		return nil, fmt.Errorf("qr: rendering png failed: %w", err)
+	}
+	return b.Bytes(), nil
+}
@@ -0,0 +1,297 @@
+package qr
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/skip2/go-qrcode/bitset"
+	"github.com/skip2/go-qrcode/reedsolomon"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew_MatchesQRCode(t *testing.T) {
+	for _, data := range []string{"1234", "HELLO WORLD", "https://example.com/path?q=1", strings.Repeat("azoo", 60)} {
+		for _, level := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+			recovery, err := level.recoveryLevel()
+			require.NoError(t, err)
+			expected, err := qrcode.New(data, recovery)
+			require.NoError(t, err)
+
+			code, err := New(data, &Options{Level: level})
+			require.NoError(t, err)
+			assert.Equal(t, expected.VersionNumber, code.Version())
+			assert.Equal(t, expected.Bitmap(), code.bitmap())
+
+			png, err := code.PNG(1000)
+			require.NoError(t, err)
+			expectedPNG, err := expected.PNG(1000)
+			require.NoError(t, err)
+			assert.Equal(t, expectedPNG, png)
+
+			// go-qrcode encodes the data again on every call
+			expected, err = qrcode.New(data, recovery)
+			require.NoError(t, err)
+			expected.DisableBorder = true
+			code, err = New(data, &Options{Level: level, DisableBorder: true})
+			require.NoError(t, err)
+			assert.Equal(t, expected.Bitmap(), code.bitmap())
+		}
+	}
+}
+
+func TestNew_Mask(t *testing.T) {
+	for _, level := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+		auto, err := New("otpauth://totp/azoo:user?secret=JBSWY3DPEHPK3PXP", &Options{Level: level, Version: 7})
+		require.NoError(t, err)
+
+		for mask := 1; mask <= 8; mask++ {
+			code, err := New("otpauth://totp/azoo:user?secret=JBSWY3DPEHPK3PXP", &Options{Level: level, Version: 7, Mask: mask})
+			require.NoError(t, err)
+			assert.Equal(t, mask, code.Mask())
+			read, err := readMask(code.symbol, level)
+			require.NoError(t, err)
+			assert.Equal(t, mask-1, read)
+
+			// reverting to the automatically selected mask restores the code
+			require.NoError(t, remask(code.symbol, code.Version(), level, auto.Mask()-1))
+			assert.Equal(t, auto.symbol, code.symbol)
+		}
+	}
+
+	_, err := New("azoo", &Options{Mask: 9})
+	assert.Error(t, err)
+	_, err = New("azoo", &Options{Mask: -1})
+	assert.Error(t, err)
+}
+
+func TestFunctionModules(t *testing.T) {
+	for version := 1; version <= 40; version++ {
+		// number of data modules of ISO/IEC 18004
+		expected := (16*version+128)*version + 64
+		if version >= 2 {
+			n := version/7 + 2
+			expected -= (25*n-10)*n - 55
+		}
+		if version >= 7 {
+			expected -= 36
+		}
+
+		data := 0
+		for _, row := range functionModules(version) {
+			for _, function := range row {
+				if !function {
+					data++
+				}
+			}
+		}
+		assert.Equal(t, expected, data, "version %d", version)
+	}
+}
+
+func TestReedSolomon(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{2, 5, 10, 14, 30} {
+		data := make([]byte, 20)
+		r.Read(data)
+
+		b := bitset.New()
+		b.AppendBytes(data)
+		expected := reedsolomon.Encode(b, n)
+		ec := reedSolomon(data, n)
+		for i := range ec {
+			assert.Equal(t, expected.ByteAt(8*(len(data)+i)), ec[i])
+		}
+	}
+}
+
+func TestEncodeMicroData(t *testing.T) {
+	// example of ISO/IEC 18004 Annex I: "01234567" as M2-L
+	codewords := encodeMicroData("01234567", modeNumeric, 2, microSymbols[1][LevelL])
+	assert.Equal(t, []byte{0x40, 0x18, 0xac, 0xc3, 0x00}, codewords)
+	assert.Equal(t, []byte{0x86, 0x0d, 0x22, 0xae, 0x30}, reedSolomon(codewords, 5))
+}
+
+func TestNew_Micro(t *testing.T) {
+	tests := []struct {
+		data    string
+		level   Level
+		version int
+	}{
+		{"1", LevelL, 1},
+		{"12345", LevelL, 1},
+		{"01234567", LevelL, 2},
+		{"AZOO", LevelL, 2},
+		{"0123456", LevelM, 2},
+		{"azoo", LevelL, 3},
+		{"HTTPS://AZOO.DEV", LevelM, 4},
+		{"azoo.dev/utils", LevelL, 4},
+		{"azoo", LevelQ, 4},
+		{strings.Repeat("9", 35), LevelL, 4},
+	}
+	for _, test := range tests {
+		for mask := 0; mask <= 4; mask++ {
+			code, err := New(test.data, &Options{Level: test.level, Micro: true, Mask: mask})
+			require.NoError(t, err, test.data)
+			assert.True(t, code.Micro())
+			assert.Equal(t, test.version, code.Version(), test.data)
+			assert.Equal(t, 9+2*test.version+4, code.Size())
+			if mask != 0 {
+				assert.Equal(t, mask, code.Mask())
+			}
+
+			number, data := decodeMicro(t, code.symbol)
+			assert.Equal(t, microSymbols[test.version-1][test.level].number, number)
+			assert.Equal(t, test.data, data)
+		}
+	}
+
+	code, err := New("12345", &Options{Micro: true, Level: LevelL, DisableBorder: true})
+	require.NoError(t, err)
+	assert.Equal(t, 11, code.Size())
+	assert.Equal(t, code.symbol, code.bitmap())
+}
+
+func TestNew_MicroErrors(t *testing.T) {
+	tests := []*Options{
+		{Micro: true, Level: LevelH},
+		{Micro: true, Level: Level(7)},
+		{Micro: true, Version: 5},
+		{Micro: true, MaxVersion: -1},
+		{Micro: true, Mask: 5},
+		// M1 only holds digits
+		{Micro: true, Level: LevelL, Version: 1},
+		// LevelM starts at M2
+		{Micro: true, Level: LevelM, MaxVersion: 1},
+	}
+	for _, opts := range tests {
+		_, err := New("azoo", opts)
+		assert.Error(t, err, "%+v", opts)
+	}
+
+	_, err := New(strings.Repeat("a", 16), &Options{Micro: true, Level: LevelL})
+	assert.Error(t, err)
+	_, err = New(strings.Repeat("1", 36), &Options{Micro: true, Level: LevelL})
+	assert.Error(t, err)
+}
+
+// decodeMicro reads the Micro QR code symbol and returns its symbol number
+// and data. It fails t if the format information or error correction are
+// invalid.
+func decodeMicro(t *testing.T, symbol [][]bool) (int, string) {
+	size := len(symbol)
+	version := (size - 9) / 2
+
+	format := 0
+	for i := 0; i < 8; i++ {
+		if symbol[1+i][8] {
+			format |= 1 << i
+		}
+		if symbol[8][1+i] {
+			format |= 1 << (14 - i)
+		}
+	}
+	number, mask := -1, -1
+	for n := 0; n < 8; n++ {
+		for m := range microMasks {
+			if formatInfo(n<<2|m, 0x4445) == format {
+				number, mask = n, m
+			}
+		}
+	}
+	require.NotEqual(t, -1, number, "invalid format information %015b", format)
+	var sym microSymbol
+	var level Level
+	found := false
+	for l, s := range microSymbols[version-1] {
+		if s.number == number {
+			sym, level, found = s, l, true
+		}
+	}
+	require.True(t, found, "symbol number %d isn't version M%d", number, version)
+
+	// read the modules in placement order
+	function := microFunctionModules(size)
+	var bits bitBuffer
+	for right, up := size-1, true; right >= 1; right, up = right-2, !up {
+		for v := 0; v < size; v++ {
+			row := v
+			if up {
+				row = size - 1 - v
+			}
+			for _, col := range [2]int{right, right - 1} {
+				if !function[row][col] {
+					bits = append(bits, symbol[row][col] != microMasks[mask](row, col))
+				}
+			}
+		}
+	}
+
+	codeword := func(bits bitBuffer) byte {
+		var c byte
+		for i, bit := range bits {
+			if bit {
+				c |= 0x80 >> i
+			}
+		}
+		return c
+	}
+	var data, ec []byte
+	for i := 0; i < sym.dataBits; i += 8 {
+		data = append(data, codeword(bits[i:min(i+8, sym.dataBits)]))
+	}
+	for i := 0; i < sym.ecCodewords; i++ {
+		ec = append(ec, codeword(bits[sym.dataBits+8*i:sym.dataBits+8*i+8]))
+	}
+	require.Equal(t, reedSolomon(data, sym.ecCodewords), ec, "level %s", level)
+
+	n := 0
+	read := func(count int) int {
+		v := 0
+		for i := 0; i < count; i++ {
+			v <<= 1
+			if bits[n] {
+				v |= 1
+			}
+			n++
+		}
+		return v
+	}
+	m := mode(read(version - 1))
+	countBits := version + 1
+	if m == modeNumeric {
+		countBits = version + 2
+	}
+	count := read(countBits)
+	var b strings.Builder
+	switch m {
+	case modeNumeric:
+		for i := 0; i < count; i += 3 {
+			digits := min(3, count-i)
+			v := read([4]int{0, 4, 7, 10}[digits])
+			b.WriteString(fmt.Sprintf("%0*d", digits, v))
+		}
+	case modeAlphanumeric:
+		for i := 0; i < count; i += 2 {
+			if i+1 < count {
+				v := read(11)
+				b.WriteByte(alphanumeric[v/45])
+				b.WriteByte(alphanumeric[v%45])
+			} else {
+				b.WriteByte(alphanumeric[read(6)])
+			}
+		}
+	default:
+		for i := 0; i < count; i++ {
+			b.WriteByte(byte(read(8)))
+		}
+	}
+	// the terminator follows the data, unless the capacity is reached
+	for end := min(n+2*version+1, sym.dataBits); n < end; n++ {
+		assert.False(t, bits[n], "terminator bit %d", n)
+	}
+	return number, b.String()
+}
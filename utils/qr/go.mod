@@ -2,4 +2,7 @@ module azoo.dev/utils/qr
 
 go 1.16
 
-require github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+require (
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	github.com/stretchr/testify v1.7.0
+)
@@ -0,0 +1,175 @@
+package qr
+
+import (
+	"fmt"
+)
+
+// masks are the mask patterns 000 to 111 of ISO/IEC 18004. A data module at
+// row i and column j is inverted if the pattern returns true.
+var masks = [8]func(i, j int) bool{
+	func(i, j int) bool { return (i+j)%2 == 0 },
+	func(i, j int) bool { return i%2 == 0 },
+	func(i, j int) bool { return j%3 == 0 },
+	func(i, j int) bool { return (i+j)%3 == 0 },
+	func(i, j int) bool { return (i/2+j/3)%2 == 0 },
+	func(i, j int) bool { return (i*j)%2+(i*j)%3 == 0 },
+	func(i, j int) bool { return ((i*j)%2+(i*j)%3)%2 == 0 },
+	func(i, j int) bool { return ((i+j)%2+(i*j)%3)%2 == 0 },
+}
+
+// formatBits returns the error correction bits of l in the format
+// information of a QR code.
+func (l Level) formatBits() int {
+	switch l {
+	case LevelL:
+		return 1
+	case LevelM:
+		return 0
+	case LevelQ:
+		return 3
+	default:
+		return 2
+	}
+}
+
+// formatInfo returns the 15 bit format information of the 5 bits of data:
+// data followed by its BCH(15,5) code, XORed with xor.
+func formatInfo(data int, xor int) int {
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ xor
+}
+
+// functionModules returns which modules of a QR code of version aren't
+// data modules: finder patterns and separators, timing patterns, alignment
+// patterns, format and version information and the dark module.
+func functionModules(version int) [][]bool {
+	size := 17 + 4*version
+	function := make([][]bool, size)
+	for i := range function {
+		function[i] = make([]bool, size)
+	}
+	fill := func(row, col, rows, cols int) {
+		for i := row; i < row+rows; i++ {
+			for j := col; j < col+cols; j++ {
+				function[i][j] = true
+			}
+		}
+	}
+
+	// finder patterns with their separators and format information
+	fill(0, 0, 9, 9)
+	fill(0, size-8, 9, 8)
+	fill(size-8, 0, 8, 9)
+	// timing patterns
+	fill(6, 0, 1, size)
+	fill(0, 6, size, 1)
+	// alignment patterns, except those overlapping the finder patterns
+	positions := alignmentPositions(version)
+	last := len(positions) - 1
+	for a, row := range positions {
+		for b, col := range positions {
+			if a == 0 && b == 0 || a == 0 && b == last || a == last && b == 0 {
+				continue
+			}
+			fill(row-2, col-2, 5, 5)
+		}
+	}
+	// version information
+	if version >= 7 {
+		fill(0, size-11, 6, 3)
+		fill(size-11, 0, 3, 6)
+	}
+	return function
+}
+
+// alignmentPositions returns the rows (and columns) of the centers of the
+// alignment patterns of version.
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	n := version/7 + 2
+	step := (version*8 + n*3 + 5) / (n*4 - 4) * 2
+	positions := make([]int, n)
+	positions[0] = 6
+	for i, pos := n-1, 17+4*version-7; i >= 1; i, pos = i-1, pos-step {
+		positions[i] = pos
+	}
+	return positions
+}
+
+// setFormatInfo writes both copies of the format information of level and
+// mask into the QR code symbol.
+func setFormatInfo(symbol [][]bool, level Level, mask int) {
+	bits := formatInfo(level.formatBits()<<3|mask, 0x5412)
+	bit := func(i int) bool { return bits>>i&1 == 1 }
+	size := len(symbol)
+
+	for i := 0; i <= 5; i++ {
+		symbol[i][8] = bit(i)
+	}
+	symbol[7][8] = bit(6)
+	symbol[8][8] = bit(7)
+	symbol[8][7] = bit(8)
+	for i := 9; i < 15; i++ {
+		symbol[8][14-i] = bit(i)
+	}
+
+	for i := 0; i < 8; i++ {
+		symbol[8][size-1-i] = bit(i)
+	}
+	for i := 8; i < 15; i++ {
+		symbol[size-15+i][8] = bit(i)
+	}
+	symbol[size-8][8] = true
+}
+
+// readMask returns the mask pattern of the format information of the QR
+// code symbol.
+func readMask(symbol [][]bool, level Level) (int, error) {
+	bits := 0
+	bit := func(i int, v bool) {
+		if v {
+			bits |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		bit(i, symbol[i][8])
+	}
+	bit(6, symbol[7][8])
+	bit(7, symbol[8][8])
+	bit(8, symbol[8][7])
+	for i := 9; i < 15; i++ {
+		bit(i, symbol[8][14-i])
+	}
+
+	for mask := range masks {
+		if formatInfo(level.formatBits()<<3|mask, 0x5412) == bits {
+			return mask, nil
+		}
+	}
+	return 0, fmt.Errorf("qr: invalid format information %015b", bits)
+}
+
+// remask replaces the mask pattern of the data modules of the QR code symbol
+// of version and level with mask and updates its format information.
+func remask(symbol [][]bool, version int, level Level, mask int) error {
+	current, err := readMask(symbol, level)
+	if err != nil {
+		return err
+	}
+
+	function := functionModules(version)
+	for i, row := range symbol {
+		for j := range row {
+			if !function[i][j] && masks[current](i, j) != masks[mask](i, j) {
+				row[j] = !row[j]
+			}
+		}
+	}
+	setFormatInfo(symbol, level, mask)
+	return nil
+}
@@ -0,0 +1,370 @@
+package qr
+
+import (
+	"fmt"
+	"strings"
+)
+
+// microSymbol describes a Micro QR version at a Level.
+type microSymbol struct {
+	// number is the symbol number of the format information
+	number int
+	// dataBits is the number of data bits. M1 and M3 end with a data
+	// codeword of 4 bits
+	dataBits int
+	// ecCodewords is the number of error correction codewords
+	ecCodewords int
+}
+
+// microSymbols are the Micro QR versions M1 to M4 per supported Level. M1
+// only detects errors, it is used for LevelL.
+var microSymbols = [4]map[Level]microSymbol{
+	{LevelL: {0, 20, 2}},
+	{LevelL: {1, 40, 5}, LevelM: {2, 32, 6}},
+	{LevelL: {3, 84, 6}, LevelM: {4, 68, 8}},
+	{LevelL: {5, 128, 8}, LevelM: {6, 112, 10}, LevelQ: {7, 80, 14}},
+}
+
+// microMasks are the mask patterns 00 to 11 of Micro QR codes, which are the
+// patterns 001, 100, 110 and 111 of QR codes.
+var microMasks = [4]func(i, j int) bool{masks[1], masks[4], masks[6], masks[7]}
+
+// mode is the encoding mode of the data of a Micro QR code. Its value is the
+// mode indicator.
+type mode int
+
+const (
+	modeNumeric mode = iota
+	modeAlphanumeric
+	modeByte
+)
+
+// alphanumeric are the characters of modeAlphanumeric, ordered by value.
+const alphanumeric = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ $%*+-./:"
+
+// modeOf returns the most compact mode that encodes data.
+func modeOf(data string) mode {
+	m := modeNumeric
+	for i := 0; i < len(data); i++ {
+		switch {
+		case data[i] >= '0' && data[i] <= '9':
+		case strings.IndexByte(alphanumeric, data[i]) >= 0:
+			m = modeAlphanumeric
+		default:
+			return modeByte
+		}
+	}
+	return m
+}
+
+// String returns the name of m. For example: "numeric"
+func (m mode) String() string {
+	switch m {
+	case modeNumeric:
+		return "numeric"
+	case modeAlphanumeric:
+		return "alphanumeric"
+	default:
+		return "byte"
+	}
+}
+
+// microBits returns the number of bits of data encoded in mode m by Micro QR
+// version, or -1 if the version doesn't support m or the length of data.
+func microBits(data string, m mode, version int) int {
+	// M1 only supports numeric, M2 also alphanumeric data
+	if version < int(m)+1 {
+		return -1
+	}
+	countBits := version + 1
+	if m == modeNumeric {
+		countBits = version + 2
+	}
+	if len(data) >= 1<<countBits {
+		return -1
+	}
+
+	n := version - 1 + countBits
+	switch m {
+	case modeNumeric:
+		n += 10*(len(data)/3) + [3]int{0, 4, 7}[len(data)%3]
+	case modeAlphanumeric:
+		n += 11*(len(data)/2) + 6*(len(data)%2)
+	default:
+		n += 8 * len(data)
+	}
+	return n
+}
+
+// bitBuffer is a sequence of bits, most significant bit first.
+type bitBuffer []bool
+
+// append appends the n least significant bits of v.
+func (b *bitBuffer) append(v int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, v>>i&1 == 1)
+	}
+}
+
+// encodeMicroData encodes data in mode m for Micro QR version into the data
+// codewords of sym, including terminator and padding. The last codeword of
+// M1 and M3 holds 4 bits in its upper half.
+func encodeMicroData(data string, m mode, version int, sym microSymbol) []byte {
+	var b bitBuffer
+	b.append(int(m), version-1)
+	countBits := version + 1
+	if m == modeNumeric {
+		countBits = version + 2
+	}
+	b.append(len(data), countBits)
+	switch m {
+	case modeNumeric:
+		for i := 0; i < len(data); i += 3 {
+			group := data[i:min(i+3, len(data))]
+			v := 0
+			for _, c := range []byte(group) {
+				v = v*10 + int(c-'0')
+			}
+			b.append(v, [4]int{0, 4, 7, 10}[len(group)])
+		}
+	case modeAlphanumeric:
+		for i := 0; i < len(data); i += 2 {
+			v := strings.IndexByte(alphanumeric, data[i])
+			if i+1 < len(data) {
+				b.append(v*45+strings.IndexByte(alphanumeric, data[i+1]), 11)
+			} else {
+				b.append(v, 6)
+			}
+		}
+	default:
+		for i := 0; i < len(data); i++ {
+			b.append(int(data[i]), 8)
+		}
+	}
+
+	// terminator of 3, 5, 7 or 9 zeros, or less if the capacity is reached,
+	// and zeros up to the next codeword
+	b.append(0, min(2*version+1, sym.dataBits-len(b)))
+	b.append(0, min((8-len(b)%8)%8, sym.dataBits-len(b)))
+	for pad := 0; sym.dataBits-len(b) >= 8; pad++ {
+		b.append([2]int{0xec, 0x11}[pad%2], 8)
+	}
+	b.append(0, sym.dataBits-len(b))
+
+	codewords := make([]byte, (sym.dataBits+7)/8)
+	for i, bit := range b {
+		if bit {
+			codewords[i/8] |= 0x80 >> (i % 8)
+		}
+	}
+	return codewords
+}
+
+// min returns the smaller of a and b.
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// gfMul multiplies x and y in GF(256) with the polynomial 0x11d of QR codes.
+func gfMul(x, y byte) byte {
+	z := 0
+	for i := 7; i >= 0; i-- {
+		z = z<<1 ^ (z>>7)*0x11d
+		z ^= int(y>>i&1) * int(x)
+	}
+	return byte(z)
+}
+
+// reedSolomon returns the n error correction codewords of data.
+func reedSolomon(data []byte, n int) []byte {
+	// generator polynomial (x - α^0)(x - α^1)…(x - α^(n-1)), highest degree
+	// first
+	generator := []byte{1}
+	root := byte(1)
+	for i := 0; i < n; i++ {
+		next := make([]byte, len(generator)+1)
+		for j, c := range generator {
+			next[j] ^= c
+			next[j+1] ^= gfMul(c, root)
+		}
+		generator = next
+		root = gfMul(root, 2)
+	}
+
+	rem := make([]byte, n)
+	for _, d := range data {
+		factor := d ^ rem[0]
+		copy(rem, rem[1:])
+		rem[n-1] = 0
+		for j := range rem {
+			rem[j] ^= gfMul(generator[j+1], factor)
+		}
+	}
+	return rem
+}
+
+// microFunctionModules returns which modules of a Micro QR code of size
+// aren't data modules: the finder pattern with its separator and the format
+// information in the upper left corner, and the timing patterns.
+func microFunctionModules(size int) [][]bool {
+	function := make([][]bool, size)
+	for i := range function {
+		function[i] = make([]bool, size)
+		for j := range function[i] {
+			function[i][j] = i == 0 || j == 0 || i <= 8 && j <= 8
+		}
+	}
+	return function
+}
+
+// newMicro is New for opts.Micro.
+func newMicro(data string, opts *Options) (*Code, error) {
+	if _, err := opts.Level.recoveryLevel(); err != nil {
+		return nil, err
+	}
+	if opts.Level == LevelH {
+		return nil, fmt.Errorf("qr: Micro QR codes don't support level %s", opts.Level)
+	}
+	if opts.Version < 0 || opts.Version > 4 {
+		return nil, fmt.Errorf("qr: invalid Micro QR version %d", opts.Version)
+	}
+	if opts.MaxVersion < 0 || opts.MaxVersion > 4 {
+		return nil, fmt.Errorf("qr: invalid Micro QR MaxVersion %d", opts.MaxVersion)
+	}
+	if opts.Mask < 0 || opts.Mask > len(microMasks) {
+		return nil, fmt.Errorf("qr: invalid Micro QR mask %d", opts.Mask)
+	}
+
+	first, last := 1, 4
+	if opts.Version != 0 {
+		first, last = opts.Version, opts.Version
+	} else if opts.MaxVersion != 0 {
+		last = opts.MaxVersion
+	}
+	m := modeOf(data)
+	version := 0
+	var sym microSymbol
+	for v := first; v <= last && version == 0; v++ {
+		s, ok := microSymbols[v-1][opts.Level]
+		if n := microBits(data, m, v); ok && n >= 0 && n <= s.dataBits {
+			version, sym = v, s
+		}
+	}
+	if version == 0 {
+		return nil, fmt.Errorf("qr: %d characters of %s data don't fit into Micro QR version M%d at level %s: shorten the data or lower the Level",
+			len(data), m, last, opts.Level)
+	}
+
+	codewords := encodeMicroData(data, m, version, sym)
+	ec := reedSolomon(codewords, sym.ecCodewords)
+	// the 4 bit codeword of M1 and M3 is placed with 4 modules only
+	var bits bitBuffer
+	for i, c := range codewords {
+		if i == len(codewords)-1 && sym.dataBits%8 != 0 {
+			bits.append(int(c>>4), 4)
+		} else {
+			bits.append(int(c), 8)
+		}
+	}
+	for _, c := range ec {
+		bits.append(int(c), 8)
+	}
+
+	size := 9 + 2*version
+	function := microFunctionModules(size)
+	symbol := make([][]bool, size)
+	for i := range symbol {
+		symbol[i] = make([]bool, size)
+	}
+	for i := 0; i < 7; i++ {
+		for j := 0; j < 7; j++ {
+			d := max(abs(i-3), abs(j-3))
+			symbol[i][j] = d != 2
+		}
+	}
+	for k := 8; k < size; k++ {
+		symbol[0][k] = k%2 == 0
+		symbol[k][0] = k%2 == 0
+	}
+
+	// codewords are placed in columns of two modules, from the bottom right
+	// corner alternating upwards and downwards
+	n := 0
+	for right, up := size-1, true; right >= 1; right, up = right-2, !up {
+		for v := 0; v < size; v++ {
+			row := v
+			if up {
+				row = size - 1 - v
+			}
+			for _, col := range [2]int{right, right - 1} {
+				if !function[row][col] {
+					symbol[row][col] = bits[n]
+					n++
+				}
+			}
+		}
+	}
+
+	mask := opts.Mask - 1
+	if mask < 0 {
+		// the pattern with the most dark modules on the right and bottom
+		// edges, which are read as quiet zone otherwise
+		best := -1
+		for k, pattern := range microMasks {
+			sum1, sum2 := 0, 0
+			for i := 1; i < size; i++ {
+				if symbol[i][size-1] != pattern(i, size-1) {
+					sum1++
+				}
+				if symbol[size-1][i] != pattern(size-1, i) {
+					sum2++
+				}
+			}
+			score := sum1 + 16*sum2
+			if sum1 <= sum2 {
+				score = 16*sum1 + sum2
+			}
+			if score > best {
+				best, mask = score, k
+			}
+		}
+	}
+	for i := range symbol {
+		for j := range symbol[i] {
+			if !function[i][j] && microMasks[mask](i, j) {
+				symbol[i][j] = !symbol[i][j]
+			}
+		}
+	}
+
+	format := formatInfo(sym.number<<2|mask, 0x4445)
+	for i := 0; i < 8; i++ {
+		symbol[1+i][8] = format>>i&1 == 1
+		symbol[8][1+i] = format>>(14-i)&1 == 1
+	}
+
+	border := 2
+	if opts.DisableBorder {
+		border = 0
+	}
+	return &Code{symbol: symbol, border: border, version: version, mask: mask, micro: true}, nil
+}
+
+// max returns the larger of a and b.
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// abs returns the absolute value of a.
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+	return a
+}
@@ -0,0 +1,241 @@
+package qr
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultMaxVersion is the largest version accepted by Check and the payload
+// helpers if opts set neither Version nor MaxVersion. Version 10 (57 modules
+// per side) still scans reliably from phone screens and small prints.
+const DefaultMaxVersion = 10
+
+// byteCapacity is the number of bytes a code holds in byte mode, indexed by
+// Level and version - 1.
+var byteCapacity = [...][40]int{
+	LevelQ: {11, 20, 32, 46, 60, 74, 86, 108, 130, 151, 177, 203, 241, 258, 292, 322, 364, 394, 442, 482, 509, 565, 611, 661, 715, 751, 805, 868, 908, 982, 1030, 1112, 1168, 1228, 1283, 1351, 1423, 1499, 1579, 1663},
+	LevelL: {17, 32, 53, 78, 106, 134, 154, 192, 230, 271, 321, 367, 425, 458, 520, 586, 644, 718, 792, 858, 929, 1003, 1091, 1171, 1273, 1367, 1465, 1528, 1628, 1732, 1840, 1952, 2068, 2188, 2303, 2431, 2563, 2699, 2809, 2953},
+	LevelM: {14, 26, 42, 62, 84, 106, 122, 152, 180, 213, 251, 287, 331, 362, 412, 450, 504, 560, 624, 666, 711, 779, 857, 911, 997, 1059, 1125, 1190, 1264, 1370, 1452, 1538, 1628, 1722, 1809, 1911, 1989, 2099, 2213, 2331},
+	LevelH: {7, 14, 24, 34, 44, 58, 64, 84, 98, 119, 137, 155, 177, 194, 220, 250, 280, 310, 338, 382, 403, 439, 461, 511, 535, 593, 625, 658, 698, 742, 790, 842, 898, 958, 983, 1051, 1093, 1139, 1219, 1273},
+}
+
+// microByteCapacity is the number of bytes a Micro QR code holds in byte
+// mode, indexed by Level and version - 1. M1 and M2 don't support byte mode.
+var microByteCapacity = [...][4]int{
+	LevelQ: {0, 0, 0, 9},
+	LevelL: {0, 0, 9, 15},
+	LevelM: {0, 0, 7, 13},
+	LevelH: {0, 0, 0, 0},
+}
+
+// String returns the name of l. For example: "Q"
+func (l Level) String() string {
+	switch l {
+	case LevelL:
+		return "L"
+	case LevelM:
+		return "M"
+	case LevelQ:
+		return "Q"
+	case LevelH:
+		return "H"
+	default:
+		return fmt.Sprintf("Level(%d)", int(l))
+	}
+}
+
+// Capacity returns the number of bytes a code of version at level holds in
+// byte mode, the mode of URIs and other text with lowercase letters.
+func Capacity(level Level, version int) (int, error) {
+	if _, err := level.recoveryLevel(); err != nil {
+		return 0, err
+	}
+	if version < 1 || version > 40 {
+		return 0, fmt.Errorf("qr: invalid version %d", version)
+	}
+	return byteCapacity[level][version-1], nil
+}
+
+// capacity is like Capacity, but returns the capacity of the Micro QR
+// version if micro is set.
+func capacity(level Level, version int, micro bool) (int, error) {
+	if !micro {
+		return Capacity(level, version)
+	}
+	if _, err := level.recoveryLevel(); err != nil {
+		return 0, err
+	}
+	if version < 1 || version > 4 {
+		return 0, fmt.Errorf("qr: invalid Micro QR version %d", version)
+	}
+	return microByteCapacity[level][version-1], nil
+}
+
+// Check returns an error if data doesn't fit into a code with opts: into the
+// forced Version, or otherwise into MaxVersion (DefaultMaxVersion if zero, or
+// M4 for Micro QR codes).
+// Unlike New it doesn't encode data, but assumes byte mode, so it never
+// accepts data New can't encode. The error names the number of bytes to
+// remove and the levels at which data would fit.
+func Check(data string, opts *Options) error {
+	if opts == nil {
+		opts = &Options{}
+	}
+	version := opts.Version
+	if version == 0 {
+		version = opts.MaxVersion
+	}
+	if version == 0 {
+		version = DefaultMaxVersion
+		if opts.Micro {
+			version = 4
+		}
+	}
+	limit, err := capacity(opts.Level, version, opts.Micro)
+	if err != nil {
+		return err
+	}
+	if len(data) <= limit {
+		return nil
+	}
+
+	var fits []string
+	for _, l := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+		if n, _ := capacity(l, version, opts.Micro); l != opts.Level && len(data) <= n {
+			fits = append(fits, fmt.Sprintf("Level%s (%d bytes)", l, n))
+		}
+	}
+	hint := ""
+	if len(fits) > 0 {
+		hint = ", or use " + strings.Join(fits, " or ")
+	}
+	name := fmt.Sprint(version)
+	if opts.Micro {
+		name = "M" + name
+	}
+	return fmt.Errorf("qr: %d bytes of data exceed the capacity of %d bytes of version %s at level %s: shorten the data by %d bytes%s",
+		len(data), limit, name, opts.Level, len(data)-limit, hint)
+}
+
+// CheckOTPAuth checks that uri is an otpauth URI (the key URI format of
+// authenticator apps) with a secret, and fits into a code with opts (see
+// Check).
+func CheckOTPAuth(uri string, opts *Options) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return fmt.Errorf("qr: invalid otpauth URI: %w", err)
+	}
+	if u.Scheme != "otpauth" {
+		return fmt.Errorf("qr: otpauth URI scheme must be %q, but is %q", "otpauth", u.Scheme)
+	}
+	if u.Host != "totp" && u.Host != "hotp" {
+		return fmt.Errorf("qr: otpauth URI type must be %q or %q, but is %q", "totp", "hotp", u.Host)
+	}
+	if strings.TrimPrefix(u.Path, "/") == "" {
+		return fmt.Errorf("qr: otpauth URI has no label")
+	}
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return fmt.Errorf("qr: invalid otpauth URI query: %w", err)
+	}
+	if query.Get("secret") == "" {
+		return fmt.Errorf("qr: otpauth URI has no secret")
+	}
+	return Check(uri, opts)
+}
+
+// CheckURL checks that rawURL is an absolute http or https URL, and fits
+// into a code with opts (see Check).
+func CheckURL(rawURL string, opts *Options) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("qr: invalid URL: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("qr: URL scheme must be %q or %q, but is %q", "http", "https", u.Scheme)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("qr: URL has no host")
+	}
+	return Check(rawURL, opts)
+}
+
+// WiFiSecurity is the authentication type of a WiFi network.
+type WiFiSecurity string
+
+const (
+	// WiFiWPA is WPA/WPA2/WPA3 personal with a passphrase of 8 to 63
+	// characters.
+	WiFiWPA WiFiSecurity = "WPA"
+	// WiFiWEP is WEP with a key of 5 or 13 characters, or 10 or 26 hex
+	// digits.
+	WiFiWEP WiFiSecurity = "WEP"
+	// WiFiNone is an open network without password.
+	WiFiNone WiFiSecurity = "nopass"
+)
+
+// WiFi describes a WiFi network for the "WIFI:" payload that phones join
+// after scanning.
+type WiFi struct {
+	// SSID is the name of the network (1 to 32 bytes). For example: "ACME Guests"
+	SSID string
+	// Security is the authentication type. For example: WiFiWPA
+	Security WiFiSecurity
+	// Password is the passphrase or key. It must be empty for WiFiNone.
+	Password string
+	// Hidden marks a network that doesn't broadcast its SSID.
+	Hidden bool
+}
+
+// Payload validates w and returns its "WIFI:" payload, after checking that
+// it fits into a code with opts (see Check).
+func (w *WiFi) Payload(opts *Options) (string, error) {
+	if len(w.SSID) == 0 || len(w.SSID) > 32 {
+		return "", fmt.Errorf("qr: WiFi SSID must have 1 to 32 bytes, but has %d", len(w.SSID))
+	}
+	switch w.Security {
+	case WiFiWPA:
+		if len(w.Password) < 8 || len(w.Password) > 63 {
+			return "", fmt.Errorf("qr: WiFi WPA password must have 8 to 63 characters, but has %d", len(w.Password))
+		}
+	case WiFiWEP:
+		_, err := hex.DecodeString(w.Password)
+		isHex := err == nil && (len(w.Password) == 10 || len(w.Password) == 26)
+		if !isHex && len(w.Password) != 5 && len(w.Password) != 13 {
+			return "", fmt.Errorf("qr: WiFi WEP password must have 5 or 13 characters, or 10 or 26 hex digits")
+		}
+	case WiFiNone:
+		if w.Password != "" {
+			return "", fmt.Errorf("qr: WiFi without security cannot have a password")
+		}
+	default:
+		return "", fmt.Errorf("qr: invalid WiFi security %q", w.Security)
+	}
+
+	b := strings.Builder{}
+	b.WriteString("WIFI:T:")
+	b.WriteString(string(w.Security))
+	b.WriteString(";S:")
+	b.WriteString(escapeWiFi(w.SSID))
+	if w.Password != "" {
+		b.WriteString(";P:")
+		b.WriteString(escapeWiFi(w.Password))
+	}
+	if w.Hidden {
+		b.WriteString(";H:true")
+	}
+	b.WriteString(";;")
+
+	payload := b.String()
+	if err := Check(payload, opts); err != nil {
+		return "", err
+	}
+	return payload, nil
+}
+
+// escapeWiFi escapes the special characters of the "WIFI:" payload.
+func escapeWiFi(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `;`, `\;`, `,`, `\,`, `:`, `\:`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,184 @@
+package qr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapacity(t *testing.T) {
+	for _, level := range []Level{LevelL, LevelM, LevelQ, LevelH} {
+		for version := 1; version <= 40; version++ {
+			capacity, err := Capacity(level, version)
+			require.NoError(t, err)
+
+			// lowercase letters are encoded in byte mode
+			data := strings.Repeat("a", capacity)
+			_, err = New(data, &Options{Level: level, Version: version})
+			assert.NoError(t, err, "%s %d", level, version)
+			assert.NoError(t, Check(data, &Options{Level: level, Version: version}))
+
+			_, err = New(data+"a", &Options{Level: level, Version: version})
+			assert.Error(t, err, "%s %d", level, version)
+			assert.Error(t, Check(data+"a", &Options{Level: level, Version: version}))
+		}
+
+		for version := 1; version <= 4; version++ {
+			opts := &Options{Level: level, Version: version, Micro: true}
+			capacity := microByteCapacity[level][version-1]
+			if capacity == 0 {
+				_, err := New("a", opts)
+				assert.Error(t, err, "%s M%d", level, version)
+				assert.Error(t, Check("a", opts))
+				continue
+			}
+
+			data := strings.Repeat("a", capacity)
+			_, err := New(data, opts)
+			assert.NoError(t, err, "%s M%d", level, version)
+			assert.NoError(t, Check(data, opts))
+
+			_, err = New(data+"a", opts)
+			assert.Error(t, err, "%s M%d", level, version)
+			assert.Error(t, Check(data+"a", opts))
+		}
+	}
+
+	_, err := Capacity(LevelQ, 0)
+	assert.Error(t, err)
+	_, err = Capacity(LevelQ, 41)
+	assert.Error(t, err)
+	_, err = Capacity(Level(7), 1)
+	assert.Error(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		opts *Options
+		err  string
+	}{
+		{"DefaultMaxVersion", strings.Repeat("a", 151), nil, ""},
+		{"DefaultMaxVersion exceeded", strings.Repeat("a", 200), nil,
+			"qr: 200 bytes of data exceed the capacity of 151 bytes of version 10 at level Q: shorten the data by 49 bytes, or use LevelL (271 bytes) or LevelM (213 bytes)"},
+		{"MaxVersion", strings.Repeat("a", 33), &Options{Level: LevelL, MaxVersion: 2},
+			"qr: 33 bytes of data exceed the capacity of 32 bytes of version 2 at level L: shorten the data by 1 bytes"},
+		{"Version over MaxVersion", strings.Repeat("a", 33), &Options{Level: LevelL, Version: 3, MaxVersion: 2}, ""},
+		{"Micro", strings.Repeat("a", 14), &Options{Level: LevelM, Micro: true},
+			"qr: 14 bytes of data exceed the capacity of 13 bytes of version M4 at level M: shorten the data by 1 bytes, or use LevelL (15 bytes)"},
+		{"invalid version", "a", &Options{Version: 41}, "qr: invalid version 41"},
+		{"invalid Micro version", "a", &Options{Version: 5, Micro: true}, "qr: invalid Micro QR version 5"},
+		{"invalid level", "a", &Options{Level: Level(7)}, "qr: invalid level 7"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := Check(test.data, test.opts)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestCheckOTPAuth(t *testing.T) {
+	tests := []struct {
+		name string
+		uri  string
+		err  string
+	}{
+		{"totp", "otpauth://totp/azoo:alice?secret=JBSWY3DPEHPK3PXP&issuer=azoo", ""},
+		{"hotp", "otpauth://hotp/alice?secret=JBSWY3DPEHPK3PXP&counter=1", ""},
+		{"invalid URI", "otpauth://totp/%zz?secret=JBSWY3DPEHPK3PXP", "qr: invalid otpauth URI: "},
+		{"scheme", "https://totp/alice?secret=JBSWY3DPEHPK3PXP", `qr: otpauth URI scheme must be "otpauth", but is "https"`},
+		{"type", "otpauth://motp/alice?secret=JBSWY3DPEHPK3PXP", `qr: otpauth URI type must be "totp" or "hotp", but is "motp"`},
+		{"no label", "otpauth://totp/?secret=JBSWY3DPEHPK3PXP", "qr: otpauth URI has no label"},
+		{"invalid query", "otpauth://totp/alice?secret=%zz", "qr: invalid otpauth URI query: "},
+		{"no secret", "otpauth://totp/alice?issuer=azoo", "qr: otpauth URI has no secret"},
+		{"too long", "otpauth://totp/alice?secret=" + strings.Repeat("A", 200), "qr: 228 bytes of data exceed the capacity"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckOTPAuth(test.uri, nil)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.True(t, strings.HasPrefix(err.Error(), test.err), err.Error())
+			}
+		})
+	}
+}
+
+func TestCheckURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		err  string
+	}{
+		{"https", "https://azoo.dev/users?id=1", ""},
+		{"http", "http://localhost:8080", ""},
+		{"invalid URL", "https://azoo.dev/%zz", "qr: invalid URL: "},
+		{"scheme", "ftp://azoo.dev", `qr: URL scheme must be "http" or "https", but is "ftp"`},
+		{"relative", "/users", `qr: URL scheme must be "http" or "https", but is ""`},
+		{"no host", "https:///users", "qr: URL has no host"},
+		{"too long", "https://azoo.dev/" + strings.Repeat("a", 200), "qr: 217 bytes of data exceed the capacity"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := CheckURL(test.url, nil)
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				assert.True(t, strings.HasPrefix(err.Error(), test.err), err.Error())
+			}
+		})
+	}
+}
+
+func TestWiFi_Payload(t *testing.T) {
+	tests := []struct {
+		name    string
+		wifi    WiFi
+		payload string
+		err     string
+	}{
+		{"WPA", WiFi{SSID: "ACME Guests", Security: WiFiWPA, Password: "password"}, "WIFI:T:WPA;S:ACME Guests;P:password;;", ""},
+		{"WEP", WiFi{SSID: "ACME", Security: WiFiWEP, Password: "abcde"}, "WIFI:T:WEP;S:ACME;P:abcde;;", ""},
+		{"WEP hex", WiFi{SSID: "ACME", Security: WiFiWEP, Password: "0123456789"}, "WIFI:T:WEP;S:ACME;P:0123456789;;", ""},
+		{"open", WiFi{SSID: "ACME", Security: WiFiNone}, "WIFI:T:nopass;S:ACME;;", ""},
+		{"hidden", WiFi{SSID: "ACME", Security: WiFiNone, Hidden: true}, "WIFI:T:nopass;S:ACME;H:true;;", ""},
+		{"escape semicolon", WiFi{SSID: "a;b", Security: WiFiWPA, Password: "pass;word"}, `WIFI:T:WPA;S:a\;b;P:pass\;word;;`, ""},
+		{"escape comma", WiFi{SSID: "a,b", Security: WiFiWPA, Password: "pass,word"}, `WIFI:T:WPA;S:a\,b;P:pass\,word;;`, ""},
+		{"escape colon", WiFi{SSID: "a:b", Security: WiFiWPA, Password: "pass:word"}, `WIFI:T:WPA;S:a\:b;P:pass\:word;;`, ""},
+		{"escape quote", WiFi{SSID: `"ab"`, Security: WiFiWPA, Password: `pass"word`}, `WIFI:T:WPA;S:\"ab\";P:pass\"word;;`, ""},
+		{"escape backslash", WiFi{SSID: `a\b`, Security: WiFiWPA, Password: `pass\;word`}, `WIFI:T:WPA;S:a\\b;P:pass\\\;word;;`, ""},
+		{"no SSID", WiFi{Security: WiFiNone}, "", "qr: WiFi SSID must have 1 to 32 bytes, but has 0"},
+		{"long SSID", WiFi{SSID: strings.Repeat("a", 33), Security: WiFiNone}, "", "qr: WiFi SSID must have 1 to 32 bytes, but has 33"},
+		{"short WPA", WiFi{SSID: "ACME", Security: WiFiWPA, Password: "1234567"}, "", "qr: WiFi WPA password must have 8 to 63 characters, but has 7"},
+		{"long WPA", WiFi{SSID: "ACME", Security: WiFiWPA, Password: strings.Repeat("a", 64)}, "", "qr: WiFi WPA password must have 8 to 63 characters, but has 64"},
+		{"invalid WEP", WiFi{SSID: "ACME", Security: WiFiWEP, Password: "abcdefghij"}, "", "qr: WiFi WEP password must have 5 or 13 characters, or 10 or 26 hex digits"},
+		{"open with password", WiFi{SSID: "ACME", Security: WiFiNone, Password: "password"}, "", "qr: WiFi without security cannot have a password"},
+		{"security", WiFi{SSID: "ACME", Security: "WPA3", Password: "password"}, "", `qr: invalid WiFi security "WPA3"`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payload, err := test.wifi.Payload(nil)
+			if test.err == "" {
+				require.NoError(t, err)
+				assert.Equal(t, test.payload, payload)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+
+	// the escaped payload must fit
+	_, err := (&WiFi{SSID: "ACME", Security: WiFiWPA, Password: strings.Repeat(";", 63)}).Payload(&Options{Level: LevelH, MaxVersion: 5})
+	assert.Error(t, err)
+}
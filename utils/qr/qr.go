@@ -2,20 +2,17 @@ package qr
 
 import (
 	"encoding/base64"
-	"fmt"
 	"strings"
-
-	"github.com/skip2/go-qrcode"
 )
 
 // PNGRaw encodes data into a raw png image with quality level Q (25% error
 // correction) and a size of 1000 pixels.
 func PNGRaw(data string) ([]byte, error) {
-	buf, err := qrcode.Encode(data, qrcode.High, 1000)
+	code, err := New(data, nil)
 	if err != nil {
-		return nil, fmt.Errorf("qr: encoding data failed: %w", err)
+		return nil, err
 	}
-	return buf, nil
+	return code.PNG(1000)
 }
 
 // PNGDataURI is like PNGRaw but encodes the image into a "data:" URI.
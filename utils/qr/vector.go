@@ -0,0 +1,134 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// vectorSize is the width and height of vector images in points (2 inches).
+// Unlike PNGs they can be scaled to any size without aliasing.
+const vectorSize = 144
+
+// rect is a horizontal run of dark modules. Its coordinates are in modules,
+// with the origin in the bottom left corner (like PostScript and PDF).
+type rect struct {
+	x, y, w int
+}
+
+// modules returns the runs of dark modules of c and the number of modules per
+// side (including the quiet zone).
+func (c *Code) modules() ([]rect, int) {
+	bitmap := c.bitmap()
+	n := len(bitmap)
+	var rects []rect
+	for row, line := range bitmap {
+		for x := 0; x < len(line); x++ {
+			if !line[x] {
+				continue
+			}
+			start := x
+			for x < len(line) && line[x] {
+				x++
+			}
+			rects = append(rects, rect{x: start, y: n - 1 - row, w: x - start})
+		}
+	}
+	return rects, n
+}
+
+// number formats f for PostScript and PDF, which don't support exponents.
+func number(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// EPSRaw encodes data like PNGRaw into an Encapsulated PostScript image (see
+// Code.EPS), e.g. for print-rendered documents.
+func EPSRaw(data string) ([]byte, error) {
+	code, err := New(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return code.EPS(), nil
+}
+
+// PDFRaw encodes data like PNGRaw into a single-page PDF document (see
+// Code.PDF).
+func PDFRaw(data string) ([]byte, error) {
+	code, err := New(data, nil)
+	if err != nil {
+		return nil, err
+	}
+	return code.PDF(), nil
+}
+
+// EPS renders c as Encapsulated PostScript image with a size of 144 points (2
+// inches).
+func (c *Code) EPS() []byte {
+	rects, n := c.modules()
+
+	b := bytes.Buffer{}
+	b.WriteString("%!PS-Adobe-3.0 EPSF-3.0\n")
+	fmt.Fprintf(&b, "%%%%BoundingBox: 0 0 %d %d\n", vectorSize, vectorSize)
+	b.WriteString("%%LanguageLevel: 2\n")
+	b.WriteString("%%Pages: 1\n")
+	b.WriteString("%%EndComments\n")
+	b.WriteString("gsave 1 dict begin\n")
+	scale := number(float64(vectorSize) / float64(n))
+	fmt.Fprintf(&b, "%s %s scale\n", scale, scale)
+	fmt.Fprintf(&b, "1 setgray 0 0 %d %d rectfill\n", n, n)
+	// all runs are filled as one path, so renderers draw no seams between
+	// adjacent runs
+	b.WriteString("0 setgray\n")
+	b.WriteString("/r { 3 1 roll moveto dup 0 rlineto 0 1 rlineto neg 0 rlineto closepath } bind def\n")
+	b.WriteString("newpath\n")
+	for _, r := range rects {
+		fmt.Fprintf(&b, "%d %d %d r\n", r.x, r.y, r.w)
+	}
+	b.WriteString("fill\n")
+	b.WriteString("end grestore\n")
+	b.WriteString("%%EOF\n")
+
+	return b.Bytes()
+}
+
+// PDF renders c as single-page PDF document. The page has a size of 144
+// points (2 inches) and contains only the QR code.
+func (c *Code) PDF() []byte {
+	rects, n := c.modules()
+
+	content := bytes.Buffer{}
+	scale := number(float64(vectorSize) / float64(n))
+	fmt.Fprintf(&content, "q\n%s 0 0 %s 0 0 cm\n", scale, scale)
+	fmt.Fprintf(&content, "1 g\n0 0 %d %d re\nf\n0 g\n", n, n)
+	for _, r := range rects {
+		fmt.Fprintf(&content, "%d %d %d 1 re\n", r.x, r.y, r.w)
+	}
+	content.WriteString("f\nQ\n")
+
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << >> /Contents 4 0 R >>", vectorSize, vectorSize),
+		fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+	}
+
+	b := bytes.Buffer{}
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n", len(objects)+1)
+	b.WriteString("0000000000 65535 f \n")
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\n", len(objects)+1)
+	fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", xref)
+
+	return b.Bytes()
+}
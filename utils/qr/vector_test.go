@@ -0,0 +1,157 @@
+package qr
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// vectorCodes are the codes rendered by the vector tests.
+func vectorCodes(t *testing.T) []*Code {
+	tests := []struct {
+		data string
+		opts *Options
+	}{
+		{"https://azoo.dev", nil},
+		{"https://azoo.dev", &Options{Level: LevelL, Version: 10}},
+		{"https://azoo.dev", &Options{DisableBorder: true}},
+		{"12345", &Options{Micro: true, Level: LevelL}},
+	}
+	var codes []*Code
+	for _, test := range tests {
+		code, err := New(test.data, test.opts)
+		require.NoError(t, err)
+		codes = append(codes, code)
+	}
+	return codes
+}
+
+// paint returns the modules of size n covered by the runs of rects, each
+// given as x, y and w with the origin in the bottom left corner. It fails t
+// if runs overlap or exceed the code.
+func paint(t *testing.T, n int, rects [][3]int) [][]bool {
+	bitmap := make([][]bool, n)
+	for i := range bitmap {
+		bitmap[i] = make([]bool, n)
+	}
+	for _, r := range rects {
+		x, y, w := r[0], r[1], r[2]
+		require.True(t, x >= 0 && y >= 0 && y < n && w > 0 && x+w <= n, "run %v exceeds %d modules", r, n)
+		for ; w > 0; x, w = x+1, w-1 {
+			require.False(t, bitmap[n-1-y][x], "runs overlap at %d %d", x, y)
+			bitmap[n-1-y][x] = true
+		}
+	}
+	return bitmap
+}
+
+func TestEPS(t *testing.T) {
+	run := regexp.MustCompile(`^(\d+) (\d+) (\d+) r$`)
+	for _, code := range vectorCodes(t) {
+		eps := string(code.EPS())
+		lines := strings.Split(strings.TrimSuffix(eps, "\n"), "\n")
+		require.True(t, strings.HasSuffix(eps, "\n"))
+
+		assert.Equal(t, []string{
+			"%!PS-Adobe-3.0 EPSF-3.0",
+			"%%BoundingBox: 0 0 144 144",
+			"%%LanguageLevel: 2",
+			"%%Pages: 1",
+			"%%EndComments",
+			"gsave 1 dict begin",
+			fmt.Sprintf("%s %s scale", number(144/float64(code.Size())), number(144/float64(code.Size()))),
+			fmt.Sprintf("1 setgray 0 0 %d %d rectfill", code.Size(), code.Size()),
+			"0 setgray",
+			"/r { 3 1 roll moveto dup 0 rlineto 0 1 rlineto neg 0 rlineto closepath } bind def",
+			"newpath",
+		}, lines[:11])
+		assert.Equal(t, []string{"fill", "end grestore", "%%EOF"}, lines[len(lines)-3:])
+
+		var rects [][3]int
+		for _, line := range lines[11 : len(lines)-3] {
+			m := run.FindStringSubmatch(line)
+			require.NotNil(t, m, "unexpected line %q", line)
+			rects = append(rects, [3]int{atoi(t, m[1]), atoi(t, m[2]), atoi(t, m[3])})
+		}
+		assert.Equal(t, code.bitmap(), paint(t, code.Size(), rects))
+	}
+}
+
+func TestPDF(t *testing.T) {
+	run := regexp.MustCompile(`^(\d+) (\d+) (\d+) 1 re$`)
+	for _, code := range vectorCodes(t) {
+		pdf := code.PDF()
+		require.True(t, bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")))
+		require.True(t, bytes.HasSuffix(pdf, []byte("\n%%EOF\n")))
+
+		// startxref points to the cross-reference table, whose entries point
+		// to the objects
+		m := regexp.MustCompile(`startxref\n(\d+)\n%%EOF\n$`).FindSubmatch(pdf)
+		require.NotNil(t, m)
+		xref := atoi(t, string(m[1]))
+		require.True(t, bytes.HasPrefix(pdf[xref:], []byte("xref\n0 5\n0000000000 65535 f \n")))
+		entries := strings.Split(string(pdf[xref:]), "\n")[3:7]
+		for i, entry := range entries {
+			require.Regexp(t, `^\d{10} 00000 n $`, entry)
+			offset := atoi(t, entry[:10])
+			assert.True(t, bytes.HasPrefix(pdf[offset:], []byte(fmt.Sprintf("%d 0 obj\n", i+1))), "object %d", i+1)
+		}
+		assert.Contains(t, string(pdf[xref:]), "trailer\n<< /Size 5 /Root 1 0 R >>\n")
+		assert.Contains(t, string(pdf), "/MediaBox [0 0 144 144]")
+
+		// the stream has the declared length
+		m = regexp.MustCompile(`(?s)<< /Length (\d+) >>\nstream\n(.*)endstream\nendobj\n`).FindSubmatch(pdf)
+		require.NotNil(t, m)
+		content := string(m[2])
+		assert.Equal(t, atoi(t, string(m[1])), len(content))
+
+		lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+		scale := number(144 / float64(code.Size()))
+		assert.Equal(t, []string{
+			"q",
+			fmt.Sprintf("%s 0 0 %s 0 0 cm", scale, scale),
+			"1 g",
+			fmt.Sprintf("0 0 %d %d re", code.Size(), code.Size()),
+			"f",
+			"0 g",
+		}, lines[:6])
+		assert.Equal(t, []string{"f", "Q"}, lines[len(lines)-2:])
+
+		var rects [][3]int
+		for _, line := range lines[6 : len(lines)-2] {
+			m := run.FindStringSubmatch(line)
+			require.NotNil(t, m, "unexpected line %q", line)
+			rects = append(rects, [3]int{atoi(t, m[1]), atoi(t, m[2]), atoi(t, m[3])})
+		}
+		assert.Equal(t, code.bitmap(), paint(t, code.Size(), rects))
+	}
+}
+
+func TestVectorRaw(t *testing.T) {
+	code, err := New("https://azoo.dev", nil)
+	require.NoError(t, err)
+
+	eps, err := EPSRaw("https://azoo.dev")
+	require.NoError(t, err)
+	assert.Equal(t, code.EPS(), eps)
+	pdf, err := PDFRaw("https://azoo.dev")
+	require.NoError(t, err)
+	assert.Equal(t, code.PDF(), pdf)
+
+	_, err = EPSRaw(strings.Repeat("a", 3000))
+	assert.Error(t, err)
+	_, err = PDFRaw(strings.Repeat("a", 3000))
+	assert.Error(t, err)
+}
+
+func atoi(t *testing.T, s string) int {
+	i, err := strconv.Atoi(s)
+	require.NoError(t, err)
+	return i
+}
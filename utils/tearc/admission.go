@@ -0,0 +1,163 @@
+package tearc
+
+import (
+	"hash/maphash"
+	"math/bits"
+)
+
+// Policy selects which loaded items are admitted to the ARC of a bucket.
+type Policy int
+
+const (
+	// PolicyARC admits every loaded item. If the bucket is full, the ARC
+	// replaces one of its items with it.
+	PolicyARC Policy = iota
+	// PolicyTinyLFU admits a loaded item to a full bucket only if it was
+	// requested more often than the least recently used item, which it would
+	// likely replace. Keys that are requested once (e.g. per-user keys used
+	// at login) are still loaded and returned, but don't evict hot keys. The
+	// request frequencies are estimated by a count-min sketch, that is halved
+	// periodically so the cache adapts to a changing workload.
+	PolicyTinyLFU
+)
+
+func (p Policy) valid() bool {
+	return p == PolicyARC || p == PolicyTinyLFU
+}
+
+const (
+	// sketchDepth is the amount of counter rows of a count-min sketch
+	sketchDepth = 4
+	// sketchMinWidth is the minimum amount of counters per row
+	sketchMinWidth = 256
+	// sketchMaxCount is the value at which counters saturate
+	sketchMaxCount = 15
+	// sketchSamplesPerCounter is the amount of recorded requests per counter
+	// of a row after which all counters are halved
+	sketchSamplesPerCounter = 10
+)
+
+// sketchMultipliers are odd constants for the multiplicative hashing of the
+// rows of a count-min sketch
+var sketchMultipliers = [sketchDepth]uint64{
+	0x9e3779b97f4a7c15, 0xbf58476d1ce4e5b9, 0x94d049bb133111eb, 0xd6e8feb86659fd93,
+}
+
+// tinyLFU estimates the request frequency of keys with a count-min sketch.
+// A doorkeeper bloom filter absorbs the first request of every key, so keys
+// requested once don't occupy counters of the sketch. It isn't safe for
+// concurrent use, the bucket's eqLock must be held.
+type tinyLFU struct {
+	capacity   int
+	seed       maphash.Seed
+	hash       maphash.Hash
+	shift      uint
+	rows       [sketchDepth][]uint8
+	doorkeeper []uint64
+	samples    int
+	resetAt    int
+
+	// hashKey replaces the seeded maphash of keys, if it isn't nil
+	hashKey func(key string) uint64
+}
+
+func newTinyLFU(capacity int) *tinyLFU {
+	width := sketchMinWidth
+	for width < capacity {
+		width <<= 1
+	}
+
+	t := &tinyLFU{
+		capacity:   capacity,
+		seed:       maphash.MakeSeed(),
+		shift:      uint(64 - bits.TrailingZeros(uint(width))),
+		doorkeeper: make([]uint64, width/64),
+		resetAt:    sketchSamplesPerCounter * width,
+	}
+	for i := range t.rows {
+		t.rows[i] = make([]uint8, width)
+	}
+	return t
+}
+
+// indexes returns the counter index of key in every row. They are the upper
+// bits of the hash of key multiplied with a different odd constant per row.
+func (t *tinyLFU) indexes(key string) (idx [sketchDepth]uint64) {
+	var h uint64
+	if t.hashKey != nil {
+		h = t.hashKey(key)
+	} else {
+		t.hash.SetSeed(t.seed)
+		_, _ = t.hash.WriteString(key)
+		h = t.hash.Sum64()
+		t.hash.Reset()
+	}
+
+	for i := range idx {
+		idx[i] = (h * sketchMultipliers[i]) >> t.shift
+	}
+	return idx
+}
+
+// record records a request of key.
+func (t *tinyLFU) record(key string) {
+	idx := t.indexes(key)
+
+	if !t.admittedByDoorkeeper(idx) {
+		t.doorkeeper[idx[0]/64] |= 1 << (idx[0] % 64)
+		t.doorkeeper[idx[1]/64] |= 1 << (idx[1] % 64)
+	} else {
+		for i, row := range t.rows {
+			if row[idx[i]] < sketchMaxCount {
+				row[idx[i]]++
+			}
+		}
+	}
+
+	t.samples++
+	if t.samples >= t.resetAt {
+		t.age()
+	}
+}
+
+// estimate returns the estimated amount of requests of key since the last
+// aging.
+func (t *tinyLFU) estimate(key string) int {
+	idx := t.indexes(key)
+
+	min := uint8(sketchMaxCount)
+	for i, row := range t.rows {
+		if row[idx[i]] < min {
+			min = row[idx[i]]
+		}
+	}
+	if t.admittedByDoorkeeper(idx) {
+		return int(min) + 1
+	}
+	return int(min)
+}
+
+func (t *tinyLFU) admittedByDoorkeeper(idx [sketchDepth]uint64) bool {
+	return t.doorkeeper[idx[0]/64]&(1<<(idx[0]%64)) != 0 &&
+		t.doorkeeper[idx[1]/64]&(1<<(idx[1]%64)) != 0
+}
+
+// age halves all counters and clears the doorkeeper, so old requests lose
+// their weight.
+func (t *tinyLFU) age() {
+	for _, row := range t.rows {
+		for i := range row {
+			row[i] >>= 1
+		}
+	}
+	for i := range t.doorkeeper {
+		t.doorkeeper[i] = 0
+	}
+	t.samples /= 2
+}
+
+// admit reports whether candidate should be added to a full bucket, in which
+// victim is the item likely to be replaced.
+func (t *tinyLFU) admit(candidate string, victim string) bool {
+	return t.estimate(candidate) > t.estimate(victim)
+}
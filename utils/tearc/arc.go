@@ -0,0 +1,287 @@
+package tearc
+
+import (
+	"container/list"
+)
+
+// ShardStats describes the adaptive replacement cache (ARC) of a shard. The
+// ARC splits the cached items into T1, the ones used once since they were
+// loaded, and T2, the ones used at least twice. The ghost lists B1 and B2
+// keep the keys (but not the values) of items recently replaced from T1 and
+// T2. A load of a key in B1 means T1 was too small, so its Target grows, and
+// a load of a key in B2 shrinks it in favor of T2.
+type ShardStats struct {
+	// Size is the maximum amount of cached items of the shard.
+	Size int
+	// Target is the adaptive target size of T1 (between 0 and Size). The
+	// remaining Size-Target items are the target of T2.
+	Target int
+	// T1 and T2 are the amounts of cached items in T1 and T2.
+	T1 int
+	T2 int
+	// B1 and B2 are the amounts of keys in the ghost lists B1 and B2.
+	B1 int
+	B2 int
+	// B1Hits and B2Hits count the loaded keys found in B1 and B2 since the
+	// cache was created.
+	B1Hits uint64
+	B2Hits uint64
+}
+
+// arc is the adaptive replacement cache of a bucket. It isn't safe for
+// concurrent use, all calls must hold the eqLock of the bucket.
+type arc struct {
+	size int
+	// part is the target size of t1
+	part  int
+	items map[string]interface{}
+	t1    *arcList
+	t2    *arcList
+	b1    *arcList
+	b2    *arcList
+
+	b1Hits uint64
+	b2Hits uint64
+
+	// removed is called with every value that is replaced, removed or
+	// purged, but not with values overwritten by set.
+	removed func(key string, value interface{})
+}
+
+func newARC(size int, removed func(key string, value interface{})) *arc {
+	return &arc{
+		size:    size,
+		items:   make(map[string]interface{}),
+		t1:      newARCList(),
+		t2:      newARCList(),
+		b1:      newARCList(),
+		b2:      newARCList(),
+		removed: removed,
+	}
+}
+
+// get returns the value of key and whether it is cached. An item of t1 is
+// moved to t2, as it is used the second time.
+func (c *arc) get(key string) (interface{}, bool) {
+	value, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.t1.remove(key)
+	c.t2.pushFront(key)
+	return value, true
+}
+
+// peek returns the value of key without using it.
+func (c *arc) peek(key string) (interface{}, bool) {
+	value, ok := c.items[key]
+	return value, ok
+}
+
+func (c *arc) has(key string) bool {
+	_, ok := c.items[key]
+	return ok
+}
+
+func (c *arc) len() int {
+	return len(c.items)
+}
+
+// set caches value for key. A cached value of key is overwritten, without
+// calling removed. If the cache is full, another item is replaced.
+func (c *arc) set(key string, value interface{}) {
+	_, cached := c.items[key]
+	c.items[key] = value
+	if cached {
+		return
+	}
+
+	if c.b1.remove(key) {
+		c.b1Hits++
+		if c.full() {
+			c.part = minInt(c.size, c.part+maxInt(c.b2.len()/(c.b1.len()+1), 1))
+		}
+		c.replace(false)
+		c.t2.pushFront(key)
+		return
+	}
+	if c.b2.remove(key) {
+		c.b2Hits++
+		if c.full() {
+			c.part = maxInt(0, c.part-maxInt(c.b1.len()/(c.b2.len()+1), 1))
+		}
+		c.replace(true)
+		c.t2.pushFront(key)
+		return
+	}
+
+	if c.full() && c.t1.len()+c.b1.len() == c.size {
+		if c.t1.len() < c.size {
+			c.b1.removeTail()
+			c.replace(false)
+		} else {
+			c.evict(c.t1.removeTail())
+		}
+	} else if total := c.t1.len() + c.b1.len() + c.t2.len() + c.b2.len(); total >= c.size {
+		if total >= 2*c.size {
+			if c.b2.len() > 0 {
+				c.b2.removeTail()
+			} else {
+				c.b1.removeTail()
+			}
+		}
+		c.replace(false)
+	}
+	c.t1.pushFront(key)
+}
+
+// replace replaces the least recently used item of t1 or t2, depending on
+// the target size of t1, if the cache is full. Its key is moved to the ghost
+// list. b2Hit reports whether the key being cached was found in b2.
+func (c *arc) replace(b2Hit bool) {
+	if !c.full() {
+		return
+	}
+	if c.t1.len() > 0 && ((b2Hit && c.t1.len() == c.part) || c.t1.len() > c.part) {
+		key := c.t1.removeTail()
+		c.b1.pushFront(key)
+		c.evict(key)
+	} else if c.t2.len() > 0 {
+		key := c.t2.removeTail()
+		c.b2.pushFront(key)
+		c.evict(key)
+	} else {
+		key := c.t1.removeTail()
+		c.b1.pushFront(key)
+		c.evict(key)
+	}
+}
+
+// remove removes the item of key, moves its key to the ghost list and
+// reports whether it was cached.
+func (c *arc) remove(key string) bool {
+	switch {
+	case c.t1.remove(key):
+		c.b1.pushFront(key)
+	case c.t2.remove(key):
+		c.b2.pushFront(key)
+	default:
+		return false
+	}
+	c.evict(key)
+	c.trimGhosts()
+	return true
+}
+
+// trimGhosts bounds the ghost lists, which grow beyond the size of the cache
+// when items are removed (e.g. by the reaper) instead of replaced.
+func (c *arc) trimGhosts() {
+	for c.b1.len()+c.b2.len() > c.size {
+		if c.b1.len() > c.b2.len() {
+			c.b1.removeTail()
+		} else {
+			c.b2.removeTail()
+		}
+	}
+}
+
+// evict deletes the value of key and calls removed.
+func (c *arc) evict(key string) {
+	value, ok := c.items[key]
+	if !ok {
+		return
+	}
+	delete(c.items, key)
+	c.removed(key, value)
+}
+
+// purge calls removed with every cached value and clears the cache,
+// including its ghost lists.
+func (c *arc) purge() {
+	for key, value := range c.items {
+		c.removed(key, value)
+	}
+	c.items = make(map[string]interface{})
+	c.t1, c.t2, c.b1, c.b2 = newARCList(), newARCList(), newARCList(), newARCList()
+	c.part = 0
+}
+
+func (c *arc) full() bool {
+	return c.t1.len()+c.t2.len() == c.size
+}
+
+func (c *arc) stats() ShardStats {
+	return ShardStats{
+		Size:   c.size,
+		Target: c.part,
+		T1:     c.t1.len(),
+		T2:     c.t2.len(),
+		B1:     c.b1.len(),
+		B2:     c.b2.len(),
+		B1Hits: c.b1Hits,
+		B2Hits: c.b2Hits,
+	}
+}
+
+// arcList is a list of keys ordered by their last usage, with the most
+// recently used one in front.
+type arcList struct {
+	l    *list.List
+	keys map[string]*list.Element
+}
+
+func newARCList() *arcList {
+	return &arcList{
+		l:    list.New(),
+		keys: make(map[string]*list.Element),
+	}
+}
+
+func (al *arcList) pushFront(key string) {
+	if elt, ok := al.keys[key]; ok {
+		al.l.MoveToFront(elt)
+		return
+	}
+	al.keys[key] = al.l.PushFront(key)
+}
+
+// remove removes key and reports whether it was in the list.
+func (al *arcList) remove(key string) bool {
+	elt, ok := al.keys[key]
+	if !ok {
+		return false
+	}
+	delete(al.keys, key)
+	al.l.Remove(elt)
+	return true
+}
+
+// removeTail removes and returns the least recently used key.
+func (al *arcList) removeTail() string {
+	elt := al.l.Back()
+	if elt == nil {
+		return ""
+	}
+	al.l.Remove(elt)
+	key := elt.Value.(string)
+	delete(al.keys, key)
+	return key
+}
+
+func (al *arcList) len() int {
+	return al.l.Len()
+}
+
+func minInt(x, y int) int {
+	if x < y {
+		return x
+	}
+	return y
+}
+
+func maxInt(x, y int) int {
+	if x > y {
+		return x
+	}
+	return y
+}
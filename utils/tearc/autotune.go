@@ -0,0 +1,234 @@
+package tearc
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// minTuneGets is the minimum amount of Get calls of an interval for a
+// recommendation. Fewer calls don't allow meaningful ratios.
+const minTuneGets = 100
+
+// AutoTune configures the adaptive mode of a cache. Every Interval it samples
+// the hit ratio, the lock contention and the occupancy of the cache and
+// recommends a better size and amount of shards. Recommendations are logged
+// and sent to Events.
+type AutoTune struct {
+	// Interval is the time between two samples. For example: 1 * time.Minute
+	Interval time.Duration
+	// TargetHitRatio is the fraction of Get calls that should be served from
+	// the cache. A full cache below it is recommended to grow, a mostly empty
+	// cache above it to shrink. For example: 0.9
+	TargetHitRatio float64
+	// MaxContention is the fraction of Get calls that may wait for the lock
+	// of their shard. Above it more shards are recommended. Zero disables
+	// shard recommendations. For example: 0.05
+	MaxContention float64
+	// MinSize and MaxSize bound the recommended size. Zero disables the
+	// respective bound. For example: 1024 and 65536
+	MinSize int
+	MaxSize int
+	// Apply resizes the cache to the recommended size (see Cache.Resize).
+	// The amount of shards can't be changed while the cache is running, so
+	// shard recommendations are never applied, but require a new cache.
+	Apply bool
+	// Events receives every recommendation. Events are dropped if the
+	// channel is full, so the cache is never blocked by its receiver. It is
+	// optional.
+	Events chan<- TuneEvent
+}
+
+func (at *AutoTune) validate() error {
+	switch {
+	case at.Interval <= 0:
+		return fmt.Errorf("tearc: config.AutoTune.Interval must be greater than zero")
+	case at.TargetHitRatio <= 0 || at.TargetHitRatio > 1:
+		return fmt.Errorf("tearc: config.AutoTune.TargetHitRatio must be greater than zero and at most one")
+	case at.MaxContention < 0 || at.MaxContention > 1:
+		return fmt.Errorf("tearc: config.AutoTune.MaxContention must be between zero and one")
+	case at.MinSize < 0 || at.MaxSize < 0:
+		return fmt.Errorf("tearc: config.AutoTune.MinSize and MaxSize cannot be negative")
+	case at.MaxSize > 0 && at.MinSize > at.MaxSize:
+		return fmt.Errorf("tearc: config.AutoTune.MinSize must not be greater than MaxSize")
+	}
+	return nil
+}
+
+// TuneEvent is a recommendation of AutoTune with the sample it is based on.
+type TuneEvent struct {
+	// Time is the end of the sampled interval.
+	Time time.Time
+	// Gets is the amount of Get calls of the interval.
+	Gets uint64
+	// HitRatio is the fraction of Gets served from the cache.
+	HitRatio float64
+	// Contention is the fraction of Gets that waited for the lock of their
+	// shard.
+	Contention float64
+	// Occupancy is the fraction of the size of the cache in use.
+	Occupancy float64
+	// Size and Shards are the current configuration of the cache.
+	Size   int
+	Shards int
+	// RecommendedSize and RecommendedShards are the recommended
+	// configuration. RecommendedSize is always divisible by Shards.
+	RecommendedSize   int
+	RecommendedShards int
+	// Reason explains the recommendation.
+	Reason string
+	// Applied reports whether the cache was resized to RecommendedSize.
+	Applied bool
+}
+
+// tuner samples a cache every AutoTune.Interval and recommends (or applies)
+// a better configuration.
+type tuner struct {
+	log       Logger
+	config    *AutoTune
+	cache     *tearc
+	closeOnce sync.Once
+	closeSig  chan struct{}
+}
+
+func newTuner(config *AutoTune, cache *tearc, log Logger) *tuner {
+	return &tuner{
+		log:      log,
+		config:   config,
+		cache:    cache,
+		closeSig: make(chan struct{}),
+	}
+}
+
+// sample returns the counters of all buckets since the last sample and
+// resets them, together with the amount of cached items and the size.
+func (t *tuner) sample() (gets, hits, contended uint64, items, size int) {
+	for _, b := range t.cache.buckets {
+		gets += atomic.SwapUint64(&b.gets, 0)
+		hits += atomic.SwapUint64(&b.hits, 0)
+		contended += atomic.SwapUint64(&b.contended, 0)
+
+		b.eqLock.Lock()
+		if b.arc != nil {
+			items += b.arc.len()
+		}
+		size += b.size
+		b.eqLock.Unlock()
+	}
+	return
+}
+
+// recommend returns the recommendation for a sample, or false if the cache
+// is already well configured or there were too few Gets.
+func (t *tuner) recommend(gets, hits, contended uint64, items, size int) (TuneEvent, bool) {
+	shards := int(t.cache.shards)
+	if gets < minTuneGets {
+		t.log.Debug("too few gets to tune cache", "gets", gets)
+		return TuneEvent{}, false
+	}
+
+	e := TuneEvent{
+		Time:              time.Now().UTC(),
+		Gets:              gets,
+		HitRatio:          float64(hits) / float64(gets),
+		Contention:        float64(contended) / float64(gets),
+		Occupancy:         float64(items) / float64(size),
+		Size:              size,
+		Shards:            shards,
+		RecommendedSize:   size,
+		RecommendedShards: shards,
+	}
+
+	var reasons []string
+	switch {
+	case e.HitRatio < t.config.TargetHitRatio && e.Occupancy >= 0.9:
+		e.RecommendedSize = size * 2
+		reasons = append(reasons, fmt.Sprintf("hit ratio %.2f is below %.2f at an occupancy of %.2f, grow", e.HitRatio, t.config.TargetHitRatio, e.Occupancy))
+	case e.HitRatio >= t.config.TargetHitRatio && e.Occupancy < 0.25:
+		e.RecommendedSize = size / 2
+		reasons = append(reasons, fmt.Sprintf("occupancy %.2f is low at a hit ratio of %.2f, shrink", e.Occupancy, e.HitRatio))
+	}
+	if t.config.MinSize > 0 && e.RecommendedSize < t.config.MinSize {
+		e.RecommendedSize = t.config.MinSize
+	}
+	if t.config.MaxSize > 0 && e.RecommendedSize > t.config.MaxSize {
+		e.RecommendedSize = t.config.MaxSize
+	}
+	// the size must stay divisible by the shards, so it can be applied
+	e.RecommendedSize = (e.RecommendedSize + shards - 1) / shards * shards
+	if e.RecommendedSize < shards {
+		e.RecommendedSize = shards
+	}
+	if len(reasons) > 0 && e.RecommendedSize == size {
+		reasons[0] += " (bounded by MinSize or MaxSize)"
+	}
+
+	if t.config.MaxContention > 0 && e.Contention > t.config.MaxContention && shards*2 <= e.RecommendedSize {
+		e.RecommendedShards = shards * 2
+		reasons = append(reasons, fmt.Sprintf("contention %.2f is above %.2f, add shards (requires a new cache)", e.Contention, t.config.MaxContention))
+	}
+
+	if e.RecommendedSize == size && e.RecommendedShards == shards {
+		t.log.Debug("cache is well tuned",
+			"hit_ratio", e.HitRatio,
+			"contention", e.Contention,
+			"occupancy", e.Occupancy)
+		return TuneEvent{}, false
+	}
+	e.Reason = strings.Join(reasons, "; ")
+	return e, true
+}
+
+func (t *tuner) tune() {
+	e, ok := t.recommend(t.sample())
+	if !ok {
+		return
+	}
+
+	if t.config.Apply && e.RecommendedSize != e.Size {
+		if err := t.cache.Resize(e.RecommendedSize); err != nil {
+			t.log.Warn("unable to apply recommended size", "size", e.RecommendedSize, "error", err)
+		} else {
+			e.Applied = true
+		}
+	}
+
+	t.log.Info("recommended cache configuration",
+		"size", e.Size,
+		"shards", e.Shards,
+		"recommended_size", e.RecommendedSize,
+		"recommended_shards", e.RecommendedShards,
+		"applied", e.Applied,
+		"reason", e.Reason)
+
+	if t.config.Events != nil {
+		select {
+		case t.config.Events <- e:
+		default:
+			t.log.Debug("dropped tune event, channel is full")
+		}
+	}
+}
+
+func (t *tuner) start() {
+	go func() {
+		ticker := time.NewTicker(t.config.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				t.tune()
+			case <-t.closeSig:
+				return
+			}
+		}
+	}()
+}
+
+func (t *tuner) Close() {
+	t.closeOnce.Do(func() {
+		close(t.closeSig)
+	})
+}
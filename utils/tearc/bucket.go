@@ -2,165 +2,538 @@ package tearc
 
 import (
 	"container/heap"
-	"errors"
+	"context"
 	"fmt"
+	"math"
+	"runtime/debug"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
-
-	"github.com/bluele/gcache"
-	logger "github.com/harwoeck/liblog/contract"
 )
 
 type BucketConfig struct {
-	// MinTick is the minimum amount of time between bucket reaper runs.
-	// For example: 1 * time.Second
+	// MinTick is the minimum amount of time between reaper runs. The reaper
+	// runs when the next item of any bucket is due. For example:
+	// 1 * time.Second
 	MinTick time.Duration
-	// MaxTick is the maximum amount of time between bucket reaper runs.
-	// For example: 10 * time.Second
+	// MaxTick is the maximum amount of time between reaper runs, while items
+	// are cached. For example: 10 * time.Second
 	MaxTick time.Duration
+	// MaxLifetime is the absolute maximum lifetime of every item, even if it
+	// is used constantly. It caps the hard TTL returned by the LoaderFunc
+	// (see TTL.Hard). Zero disables it. For example: 1 * time.Hour
+	MaxLifetime time.Duration
+	// Zeroize overwrites []byte values with zeros after they are evicted,
+	// replaced by a refresh or the cache is closed. Unless CopyOnRead is set,
+	// values returned by Get must therefore not be used after they could have
+	// been evicted.
+	Zeroize bool
+	// CopyOnRead makes Get return copies of []byte values, so callers can
+	// neither retain nor modify the cached value. Together with Zeroize
+	// every value returned by Get stays valid.
+	CopyOnRead bool
+	// MemoryPressure enables the eviction of cached items when the process
+	// approaches its memory limit. It is optional.
+	MemoryPressure *MemoryPressure
+	// Policy selects which loaded items are admitted to a full bucket. The
+	// zero value PolicyARC admits every item. For example: PolicyTinyLFU
+	Policy Policy
+	// MaxConcurrentLoads bounds the amount of concurrent LoaderFunc calls,
+	// globally and per shard. It is optional.
+	MaxConcurrentLoads *LoadLimit
+	// Eviction selects the mechanisms that evict items. The zero value
+	// EvictionARCAndTTL uses both the ARC and the TTLs of the items, which
+	// are disabled by EvictionTTLOnly and EvictionARCOnly respectively. For
+	// example: EvictionTTLOnly
+	Eviction Eviction
+	// Sharding selects how keys are mapped to shards. The zero value
+	// ShardingModulo is the fastest, ShardingJump keeps the mapping of most
+	// keys stable across different amounts of shards. For example:
+	// ShardingJump
+	Sharding Sharding
+	// AutoTune enables the adaptive mode, which recommends (or applies) a
+	// better size and amount of shards. It is optional.
+	AutoTune *AutoTune
+	// RefreshTimeout bounds the Context passed to the LoaderFunc by a
+	// refresh (see TTL.Soft). Refreshes outlive the Get that triggered them,
+	// so they don't use its Context. Zero uses 30 seconds. For example:
+	// 10 * time.Second
+	RefreshTimeout time.Duration
 }
 
+// defaultRefreshTimeout is the RefreshTimeout used if it isn't configured.
+const defaultRefreshTimeout = 30 * time.Second
+
 type bucket struct {
+	// gets, hits and contended count the Get calls, the ones served from
+	// the cache and the ones that waited for eqLock since the last sample of
+	// AutoTune. They are accessed atomically and come first, so they are
+	// 64-bit aligned on 32-bit platforms
+	gets      uint64
+	hits      uint64
+	contended uint64
+	waiting   int32
+
 	id        int
-	log       logger.Logger
+	size      int
+	log       Logger
 	loader    LoaderFunc
 	evicted   EvictedFunc
 	config    *BucketConfig
-	arc       gcache.Cache
+	reaper    *reaper
+	arc       *arc
+	admission *tinyLFU
+	loads     *loadLimiter
 	eq        evictionQueue
 	eqPtrMap  map[string]*heapItem
 	eqLock    sync.Mutex
 	closeOnce sync.Once
-	closeSig  chan struct{}
+
+	// generation is the last generation assigned to a loaded value. It is
+	// guarded by eqLock
+	generation uint64
+
+	// now returns the current time, which is virtual in a Simulation
+	now func() time.Time
+	// simulated runs refreshes synchronously, so a Simulation is
+	// deterministic
+	simulated bool
+}
+
+// utcNow is the clock of a running cache.
+func utcNow() time.Time {
+	return time.Now().UTC()
 }
 
-func (b *bucket) loadAndSet(key string, loadInfo interface{}) (interface{}, error) {
-	value, evictIn, err := b.loader(key, loadInfo)
+func (b *bucket) loadAndSet(key string, lc LoaderContext) (interface{}, error) {
+	if err := b.loads.acquire(&lc); err != nil {
+		return nil, err
+	}
+	value, ttl, err := b.load(key, lc)
+	b.loads.release()
 	if err != nil {
 		return nil, fmt.Errorf("tearc: unable to load value with LoaderFunc: %w", err)
 	}
-
-	err = b.arc.Set(key, value)
-	if err != nil {
-		return nil, fmt.Errorf("tearc: failed to set value to arc cache: %w", err)
+	if err = lc.check(value); err != nil {
+		b.zeroize(key, value)
+		return nil, err
 	}
 
-	evictionTime := time.Now().UTC().Add(evictIn)
-	func() {
-		b.eqLock.Lock()
-		defer b.eqLock.Unlock()
+	ttl = b.limit(ttl)
 
-		item := &heapItem{
-			key:          key,
-			evictionTime: evictionTime,
+	b.eqLock.Lock()
+	defer b.eqLock.Unlock()
+
+	if !b.admit(key) {
+		b.log.Debug("item isn't admitted to full bucket", "key", key)
+		out := b.read(value)
+		if b.config.CopyOnRead {
+			// the caller got a copy and the value isn't cached
+			b.zeroize(key, value)
 		}
+		return out, nil
+	}
 
+	b.set(key, value)
+
+	item := b.eqPtrMap[key]
+	if item == nil {
+		item = &heapItem{key: key}
+		item.reset(ttl, b.now())
 		b.eqPtrMap[key] = item
 		heap.Push(&b.eq, item)
-	}()
+	} else {
+		// the item was replaced by the arc cache, but not yet reaped. It is
+		// reused, so every key has a single item in the eviction queue
+		item.reset(ttl, b.now())
+		heap.Fix(&b.eq, item.index)
+	}
+	b.generation++
+	item.generation = b.generation
+	b.schedule(item)
+
+	return b.read(value), nil
+}
+
+func (b *bucket) Get(key string, lc LoaderContext) (interface{}, error) {
+	atomic.AddUint64(&b.gets, 1)
+	if atomic.AddInt32(&b.waiting, 1) > 1 {
+		atomic.AddUint64(&b.contended, 1)
+	}
+	b.eqLock.Lock()
+	atomic.AddInt32(&b.waiting, -1)
+	if b.admission != nil {
+		b.admission.record(key)
+	}
+
+	// the value is read while holding b.eqLock, so it can't be evicted (and
+	// zeroized) before it is copied
+	value, ok := b.arc.get(key)
+	if !ok {
+		b.eqLock.Unlock()
+		return b.loadAndSet(key, lc)
+	}
+	if err := lc.check(value); err != nil {
+		b.eqLock.Unlock()
+		return nil, err
+	}
+
+	now := b.now()
+	refresh := false
+	var generation uint64
+	if item := b.eqPtrMap[key]; item != nil {
+		if item.overdue(now) {
+			// the eviction time (idle or hard TTL) has passed, but the reaper
+			// didn't run yet, as it runs at most every MinTick. The value
+			// must not be returned anymore, so it is evicted (and zeroized)
+			// and loaded again like a miss
+			heap.Remove(&b.eq, item.index)
+			b.remove(item)
+			b.eqLock.Unlock()
+			return b.loadAndSet(key, lc)
+		}
+
+		if !item.ttl.Fixed {
+			item.slide(now)
+			heap.Fix(&b.eq, item.index)
+		}
+
+		refresh = !item.refreshing && !item.refreshTime.IsZero() && !now.Before(item.refreshTime)
+		if refresh {
+			item.refreshing = true
+			generation = item.generation
+		}
+	}
+
+	value = b.read(value)
+	b.eqLock.Unlock()
+	atomic.AddUint64(&b.hits, 1)
+
+	if refresh {
+		if b.simulated {
+			b.refresh(key, lc, generation)
+		} else {
+			go b.refresh(key, lc, generation)
+		}
+	}
 
 	return value, nil
 }
 
-func (b *bucket) Get(key string, loadInfo interface{}) (interface{}, error) {
-	value, err := b.arc.Get(key)
+// refresh loads key again after its soft TTL has passed and replaces the
+// cached value of the given generation.
+func (b *bucket) refresh(key string, lc LoaderContext, generation uint64) {
+	b.log.Debug("refreshing item", "key", key)
+
+	// the Get that triggered the refresh may have returned and its Context
+	// been canceled, so only Length and Metadata are kept
+	timeout := b.config.RefreshTimeout
+	if timeout == 0 {
+		timeout = defaultRefreshTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	lc.Context = ctx
+	lc.Deadline = time.Time{}
+
+	if b.loads.acquire(nil) != nil {
+		// the next Get tries again
+		b.eqLock.Lock()
+		if item := b.eqPtrMap[key]; item != nil {
+			item.refreshing = false
+		}
+		b.eqLock.Unlock()
+		b.log.Debug("postponed refresh, maximum amount of concurrent loads reached", "key", key)
+		return
+	}
+	value, ttl, err := b.load(key, lc)
+	b.loads.release()
+	if err == nil {
+		if err = lc.check(value); err != nil {
+			b.zeroize(key, value)
+		}
+	}
+
+	b.eqLock.Lock()
+	defer b.eqLock.Unlock()
+
+	item := b.eqPtrMap[key]
+	if item == nil || item.generation != generation || !b.arc.has(key) {
+		// evicted, reloaded (or closed) while refreshing. The refreshed value
+		// is stale and must neither bring the item back nor replace a newer
+		// value
+		if err == nil {
+			b.zeroize(key, value)
+		}
+		return
+	}
 	if err != nil {
-		if errors.Is(err, gcache.KeyNotFoundError) {
-			return b.loadAndSet(key, loadInfo)
+		// the next Get tries again
+		item.refreshing = false
+		b.log.Warn("unable to refresh item", "key", key, "error", err)
+		return
+	}
+	b.set(key, value)
+
+	item.reset(b.limit(ttl), b.now())
+	heap.Fix(&b.eq, item.index)
+	b.generation++
+	item.generation = b.generation
+	b.schedule(item)
+}
+
+// schedule schedules the reaper for the eviction time of item, unless the
+// timed eviction is disabled.
+func (b *bucket) schedule(item *heapItem) {
+	if b.config.Eviction == EvictionARCOnly {
+		return
+	}
+	b.reaper.schedule(item.evictionTime)
+}
+
+// admit reports whether the loaded key is set to the arc cache, according to
+// config.Policy. With PolicyTinyLFU a full bucket only admits key, if it was
+// requested more often than the item with the earliest eviction time, which
+// is the least recently used one. With EvictionTTLOnly a full bucket admits
+// no further keys, as the ARC must not replace any item. b.eqLock must be
+// held.
+func (b *bucket) admit(key string) bool {
+	if b.config.Eviction == EvictionTTLOnly && !b.arc.has(key) && b.arc.len() >= b.size {
+		return false
+	}
+	if b.admission == nil || b.eq.Len() == 0 || b.arc.has(key) ||
+		b.arc.len() < b.admission.capacity {
+		return true
+	}
+	return b.admission.admit(key, b.eq[0].key)
+}
+
+// load calls the LoaderFunc and converts a panic into an error wrapping
+// ErrLoaderPanic, so neither the caller nor the state of the bucket (e.g.
+// reserved loads) is affected by it.
+func (b *bucket) load(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.log.Error("LoaderFunc panicked", "key", key, "panic", r, "stack", string(debug.Stack()))
+			value, ttl, err = nil, TTL{}, fmt.Errorf("%w: %v", ErrLoaderPanic, r)
+		}
+	}()
+	return b.loader(key, lc)
+}
+
+// notifyEvicted calls the EvictedFunc and logs a panic instead of crashing
+// the process. It is called in a new go routine.
+func (b *bucket) notifyEvicted(key string) {
+	defer func() {
+		if r := recover(); r != nil {
+			b.log.Error("EvictedFunc panicked", "key", key, "panic", r, "stack", string(debug.Stack()))
 		}
+	}()
+	b.evicted(key)
+}
 
-		return nil, fmt.Errorf("tearc: getting value errored: %w", err)
+// read returns a copy of value, if config.CopyOnRead is set and value is a
+// []byte. b.eqLock must be held.
+func (b *bucket) read(value interface{}) interface{} {
+	if !b.config.CopyOnRead {
+		return value
+	}
+	if buf, ok := value.([]byte); ok {
+		return append([]byte(nil), buf...)
 	}
+	return value
+}
 
-	go func(evictionTime time.Time) {
-		b.eqLock.Lock()
-		defer b.eqLock.Unlock()
+// limit caps the hard TTL of ttl at config.MaxLifetime. With
+// EvictionARCOnly it replaces ttl, so the item is never evicted by time.
+func (b *bucket) limit(ttl TTL) TTL {
+	if b.config.Eviction == EvictionARCOnly {
+		return TTL{Idle: arcOnlyIdle}
+	}
+	if b.config.MaxLifetime > 0 && (ttl.Hard <= 0 || ttl.Hard > b.config.MaxLifetime) {
+		ttl.Hard = b.config.MaxLifetime
+	}
+	return ttl
+}
 
-		item := b.eqPtrMap[key]
-		if item == nil {
-			return
+// set sets value to the arc cache and zeroizes the value it replaces.
+// b.eqLock must be held, so the replaced value can't change in between.
+func (b *bucket) set(key string, value interface{}) {
+	if b.config.Zeroize {
+		if old, ok := b.arc.peek(key); ok && !sameBuffer(old, value) {
+			defer b.zeroize(key, old)
 		}
+	}
+	b.arc.set(key, value)
+}
 
-		item.evictionTime = evictionTime
-		heap.Fix(&b.eq, item.index)
-	}(time.Now().UTC().Add(1 * time.Minute))
+// zeroize overwrites value with zeros, if config.Zeroize is set and value is
+// a []byte. It is called by the arc cache for every removed value.
+func (b *bucket) zeroize(_ string, value interface{}) {
+	if !b.config.Zeroize {
+		return
+	}
+	if buf, ok := value.([]byte); ok {
+		for i := range buf {
+			buf[i] = 0
+		}
+	}
+}
 
-	return value, nil
+// sameBuffer reports whether a and b are []byte values sharing their first
+// byte, which must not be zeroized when one replaces the other.
+func sameBuffer(a, b interface{}) bool {
+	x, ok := a.([]byte)
+	if !ok || len(x) == 0 {
+		return false
+	}
+	y, ok := b.([]byte)
+	if !ok || len(y) == 0 {
+		return false
+	}
+	return &x[0] == &y[0]
+}
+
+// remove removes item from the arc cache and the pointer map, and calls the
+// evicted information callback in a new go routine. item must already be
+// removed from the eviction queue and b.eqLock must be held.
+func (b *bucket) remove(item *heapItem) {
+	if b.arc.remove(item.key) {
+		go b.notifyEvicted(item.key)
+	}
+
+	delete(b.eqPtrMap, item.key)
 }
 
 func (b *bucket) Close() {
 	b.closeOnce.Do(func() {
-		b.closeSig <- struct{}{}
-
 		b.eqLock.Lock()
 		defer b.eqLock.Unlock()
 
-		b.arc.Purge()
+		b.arc.purge()
 		b.arc = nil
 		b.eq = nil
 		b.eqPtrMap = nil
 	})
 }
 
-func (b *bucket) startReaper() {
-	reap := func() time.Duration {
-		b.eqLock.Lock()
-		defer b.eqLock.Unlock()
+// newARC creates the arc cache of the bucket with size items.
+func (b *bucket) newARC(size int) *arc {
+	return newARC(size, b.zeroize)
+}
+
+// resize replaces the arc cache with one of size items. If the bucket holds
+// more items, the ones with the earliest eviction times (the least recently
+// used ones) are evicted first. The remaining items are moved to the new arc
+// cache in the order they were used, without being zeroized.
+func (b *bucket) resize(size int) {
+	b.eqLock.Lock()
+	defer b.eqLock.Unlock()
 
-		for b.eq.Len() > 0 {
-			// pop one item from the heap
-			item := heap.Pop(&b.eq).(*heapItem)
-
-			// if item isn't yet ready for eviction -> push it again to the heap
-			// and return duration timeout for it
-			now := time.Now().UTC()
-			if now.Before(item.evictionTime) {
-				heap.Push(&b.eq, item)
-				timeout := item.evictionTime.Sub(now) + 50*time.Millisecond
-
-				b.log.Debug("next item in eviction queue isn't ready",
-					logger.NewField("next_item", item.key),
-					logger.NewField("eviction_time", item.evictionTime),
-					logger.NewField("timeout", timeout))
-
-				return timeout
-			}
-
-			b.log.Debug("next item in eviction queue is evicted now",
-				logger.NewField("next_item", item.key),
-				logger.NewField("eviction_time", item.evictionTime))
-
-			// remove item from arc cache and call evicted information
-			// callback in new go routine
-			if b.arc.Remove(item.key) {
-				go b.evicted(item.key)
-			}
-
-			// remove from pointer map
-			delete(b.eqPtrMap, item.key)
+	if b.arc == nil {
+		// closed
+		return
+	}
+
+	for b.arc.len() > size && b.eq.Len() > 0 {
+		b.remove(heap.Pop(&b.eq).(*heapItem))
+	}
+
+	items := make([]*heapItem, len(b.eq))
+	copy(items, b.eq)
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].evictionTime.Before(items[j].evictionTime)
+	})
+
+	b.size = size
+	arc := b.newARC(size)
+	arc.b1Hits, arc.b2Hits = b.arc.b1Hits, b.arc.b2Hits
+	for _, item := range items {
+		// items replaced by the arc cache, but not yet reaped, are skipped
+		if value, ok := b.arc.peek(item.key); ok {
+			arc.set(item.key, value)
 		}
+	}
+	// the old arc cache is dropped without Purge, as it would zeroize the
+	// moved values
+	b.arc = arc
+
+	if b.admission != nil {
+		b.admission = newTinyLFU(size)
+	}
+}
+
+// stats returns the ShardStats of the arc cache.
+func (b *bucket) stats() ShardStats {
+	b.eqLock.Lock()
+	defer b.eqLock.Unlock()
+
+	if b.arc == nil {
+		// closed
+		return ShardStats{Size: b.size}
+	}
+	return b.arc.stats()
+}
+
+// shrink evicts the fraction of items with the earliest eviction times, which
+// are the least recently used ones, and returns their amount.
+func (b *bucket) shrink(fraction float64) int {
+	b.eqLock.Lock()
+	defer b.eqLock.Unlock()
 
-		return b.config.MinTick
-	}
-
-	b.closeSig = make(chan struct{})
-	go func() {
-		t := time.NewTicker(b.config.MinTick)
-
-		for {
-			select {
-			case <-t.C:
-				next := reap()
-				if next < b.config.MinTick {
-					next = b.config.MinTick
-				} else if next > b.config.MaxTick {
-					next = b.config.MaxTick
-				}
-				t.Reset(next)
-			case <-b.closeSig:
-				t.Stop()
-				return
-			}
+	n := int(math.Ceil(float64(b.eq.Len()) * fraction))
+	if n > b.eq.Len() {
+		n = b.eq.Len()
+	}
+	for i := 0; i < n; i++ {
+		b.remove(heap.Pop(&b.eq).(*heapItem))
+	}
+	return n
+}
+
+// removeKey evicts the item of key and reports whether it existed.
+func (b *bucket) removeKey(key string) bool {
+	b.eqLock.Lock()
+	defer b.eqLock.Unlock()
+
+	item := b.eqPtrMap[key]
+	if item == nil {
+		return false
+	}
+	// the item may have been replaced by the arc cache, but not yet reaped
+	cached := b.arc.has(key)
+	heap.Remove(&b.eq, item.index)
+	b.remove(item)
+	return cached
+}
+
+// reap evicts all items whose eviction time has come and returns the
+// eviction time of the next item, or the zero time if the bucket is empty.
+func (b *bucket) reap() time.Time {
+	b.eqLock.Lock()
+	defer b.eqLock.Unlock()
+
+	for b.eq.Len() > 0 {
+		// if the next item isn't yet ready for eviction -> return its
+		// eviction time
+		item := b.eq[0]
+		if b.now().Before(item.evictionTime) {
+			b.log.Debug("next item in eviction queue isn't ready",
+				"next_item", item.key,
+				"eviction_time", item.evictionTime)
+
+			return item.evictionTime
 		}
-	}()
+
+		b.log.Debug("next item in eviction queue is evicted now",
+			"next_item", item.key,
+			"eviction_time", item.evictionTime)
+
+		// remove item from heap, arc cache and pointer map
+		heap.Pop(&b.eq)
+		b.remove(item)
+	}
+
+	return time.Time{}
 }
@@ -7,11 +7,68 @@
 // This results in a caching data structure that has at max n-items chosen by
 // adaptive replacement caching, but fully clears its memory after the
 // configured eviction time. The eviction time resets after every usage (Get)
-// of the cached item.
+// of the cached item, unless the LoaderFunc fixed its lifetime (TTL.Fixed).
+// Get never returns an item after its eviction time, even if the reaper
+// didn't evict it yet.
+//
+// Every Get passes a LoaderContext with the caller's context, the requested
+// length of []byte values, a deadline and metadata on to the LoaderFunc. It
+// is validated before the cache is accessed, and values of another length are
+// never returned.
+//
+// Every item has its own TTL, returned by the LoaderFunc. Besides the sliding
+// idle time, it can specify a soft TTL, after which the item is refreshed in
+// the background on its next usage, and a hard TTL, after which the item is
+// evicted regardless of its usage. Used together an often used item is never
+// loaded in the hot path, but still replaced regularly.
+// BucketConfig.MaxLifetime caps the hard TTL of every item, so no item stays
+// in memory for longer, and BucketConfig.Zeroize overwrites evicted []byte
+// values with zeros. BucketConfig.CopyOnRead makes Get return copies of []byte
+// values, so callers never use a zeroized value.
+// BucketConfig.MemoryPressure evicts the least recently used items after GC
+// cycles in which the process approaches its memory limit, so a process
+// killed for running out of memory leaves as few items in its core dump as
+// possible.
+//
+// BucketConfig.Policy selects which loaded items are admitted to a full
+// shard. PolicyTinyLFU puts a TinyLFU admission filter in front of the ARC:
+// a loaded item only replaces another one if it was requested more often, so
+// a burst of keys that are used only once doesn't evict the hot keys.
+// BucketConfig.MaxConcurrentLoads bounds the concurrent LoaderFunc calls
+// globally and per shard, so a cold start doesn't overload a rate-limited
+// backend. Excess cache misses wait or fail fast with ErrLoadLimit.
+//
+// A running cache can be resized (Cache.Resize) and its reaper ticks can be
+// changed (Cache.SetTicks), e.g. to react to memory pressure, without
+// dropping all cached items at once. BucketConfig.Sharding selects how keys
+// are mapped to shards: ShardingJump uses jump consistent hashing (JumpHash),
+// so a different amount of shards only moves a minimal fraction of keys.
+//
+// BucketConfig.AutoTune samples the hit ratio, the lock contention and the
+// occupancy of a cache and recommends a better size and amount of shards,
+// with its reasoning sent to AutoTune.Events. With AutoTune.Apply the
+// recommended size is applied with Cache.Resize.
+//
+// BucketConfig.Eviction disables one of the eviction mechanisms:
+// EvictionARCOnly ignores the TTLs (pure ARC) and EvictionTTLOnly never
+// replaces items of a full shard (pure TTL), so tearc can replace simpler
+// caches, too.
+//
+// Cache.Stats reports the state of the ARC of every shard: the sizes of T1
+// and T2 (items used once and at least twice), the sizes of the ghost lists
+// B1 and B2 (keys of recently replaced items), the adaptive target size of T1
+// and how often loaded keys were found in the ghost lists.
+//
+// Simulate replays a recorded access trace (see ReadTrace) against a cache
+// configuration in virtual time and reports its hit ratio and the peak amount
+// of cached items, so the size and TTLs of a new deployment can be chosen
+// offline with a trace of production.
 //
 // tearc internally uses sharded caches to minimize mutex contention. This
 // performs slightly worse on small caches, but improves stable performance in
-// more congested times.
+// more congested times. A single reaper go routine evicts the items of all
+// shards. It only wakes up when the next item is due, and not at all while
+// the cache is empty.
 //
 // tearc stands for Timed-Eviction-Adaptive-Replacement-Cache
 package tearc
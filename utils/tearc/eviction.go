@@ -0,0 +1,32 @@
+package tearc
+
+import (
+	"time"
+)
+
+// Eviction selects the mechanisms that evict items from a cache.
+type Eviction int
+
+const (
+	// EvictionARCAndTTL evicts items when they are replaced by the ARC of a
+	// full bucket or when their eviction time (see TTL) has come.
+	EvictionARCAndTTL Eviction = iota
+	// EvictionARCOnly disables the timed eviction (pure ARC). The TTLs
+	// returned by the LoaderFunc are ignored, items are neither refreshed
+	// nor evicted by time and the reaper never runs. Items are only evicted
+	// when they are replaced by the ARC, removed or shrunk.
+	EvictionARCOnly
+	// EvictionTTLOnly disables the replacement of the ARC (pure TTL). Items
+	// are only evicted when their eviction time has come. A full bucket
+	// doesn't cache further items, but still loads and returns them.
+	EvictionTTLOnly
+)
+
+func (e Eviction) valid() bool {
+	return e == EvictionARCAndTTL || e == EvictionARCOnly || e == EvictionTTLOnly
+}
+
+// arcOnlyIdle is the idle time of all items with EvictionARCOnly. It keeps
+// the eviction queue ordered by the last usage of the items (for Shrink and
+// Resize), but is never reached.
+const arcOnlyIdle = 100 * 365 * 24 * time.Hour
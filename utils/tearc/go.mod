@@ -2,8 +2,4 @@ module azoo.dev/utils/tearc
 
 go 1.16
 
-require (
-	github.com/bluele/gcache v0.0.2
-	github.com/harwoeck/liblog/contract v1.1.2
-	github.com/stretchr/testify v1.7.0
-)
+require github.com/stretchr/testify v1.7.0
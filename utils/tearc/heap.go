@@ -8,6 +8,49 @@ type heapItem struct {
 	key          string
 	evictionTime time.Time
 	index        int
+
+	// ttl of the current value and the times derived from it at load time.
+	// Zero times are disabled
+	ttl         TTL
+	refreshTime time.Time
+	expiryTime  time.Time
+	refreshing  bool
+
+	// generation of the current value. Every load assigns the next
+	// generation of the bucket, so a refresh detects that the value was
+	// replaced while it was loading
+	generation uint64
+}
+
+// reset sets the TTL of a value loaded at now.
+func (item *heapItem) reset(ttl TTL, now time.Time) {
+	item.ttl = ttl
+	item.refreshTime = time.Time{}
+	item.expiryTime = time.Time{}
+	item.refreshing = false
+
+	if ttl.Soft > 0 {
+		item.refreshTime = now.Add(ttl.Soft)
+	}
+	if ttl.Hard > 0 {
+		item.expiryTime = now.Add(ttl.Hard)
+	}
+	item.slide(now)
+}
+
+// slide moves the eviction time to now + Idle, but never beyond the expiry
+// time of the hard TTL.
+func (item *heapItem) slide(now time.Time) {
+	item.evictionTime = now.Add(item.ttl.Idle)
+	if !item.expiryTime.IsZero() && item.expiryTime.Before(item.evictionTime) {
+		item.evictionTime = item.expiryTime
+	}
+}
+
+// overdue reports whether the eviction time has passed at now, which
+// includes the expiry of the hard TTL.
+func (item *heapItem) overdue(now time.Time) bool {
+	return !now.Before(item.evictionTime)
 }
 
 // evictionQueue implements a heap.Interface and holds references to the next
@@ -0,0 +1,47 @@
+package tearc
+
+// Sharding selects how keys are mapped to the shards of a cache.
+type Sharding int
+
+const (
+	// ShardingModulo maps the hash of a key modulo the amount of shards. It
+	// is the fastest mapping, but a different amount of shards maps almost
+	// every key to another shard.
+	ShardingModulo Sharding = iota
+	// ShardingJump maps the hash of a key with jump consistent hashing (see
+	// JumpHash). When the amount of shards grows from n to m, only
+	// (m-n)/m of the keys move to another shard, so resharding a cache
+	// keeps most items in place.
+	ShardingJump
+)
+
+func (s Sharding) valid() bool {
+	return s == ShardingModulo || s == ShardingJump
+}
+
+// JumpHash returns the bucket of key among buckets, using the jump consistent
+// hash of Lamping and Veach (https://arxiv.org/abs/1406.2294). It is
+// deterministic and returns a value in [0, buckets). Growing buckets from n to
+// n+1 moves only 1/(n+1) of all keys, all of them to the new bucket n. It
+// returns 0 if buckets isn't positive.
+func JumpHash(key uint64, buckets int) int {
+	var b, j int64 = -1, 0
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	if b < 0 {
+		return 0
+	}
+	return int(b)
+}
+
+// shardIndex returns the shard of a key with hash among shards, according to
+// sharding.
+func shardIndex(sharding Sharding, hash uint64, shards uint64) uint64 {
+	if sharding == ShardingJump {
+		return uint64(JumpHash(hash, int(shards)))
+	}
+	return hash % shards
+}
@@ -0,0 +1,140 @@
+package tearc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLoadLimit is returned by Cache.Get for a cache miss, if LoadLimit.FailFast
+// is set and the maximum amount of concurrent loads is reached.
+var ErrLoadLimit = errors.New("tearc: maximum amount of concurrent loads reached")
+
+// LoadLimit bounds the amount of concurrent LoaderFunc calls, so a cold start
+// with many cache misses can't open unbounded concurrent calls against a
+// rate-limited backend (e.g. an HSM). Loads are limited globally and per
+// shard, a zero value disables the respective limit.
+type LoadLimit struct {
+	// Global is the maximum amount of concurrent loads of all shards. For
+	// example: 32
+	Global int
+	// PerShard is the maximum amount of concurrent loads of every shard. For
+	// example: 2
+	PerShard int
+	// FailFast makes Get return ErrLoadLimit instead of waiting for a
+	// running load to finish, if a limit is reached. Otherwise Get waits
+	// until its LoaderContext is done. Refreshes (see TTL.Soft) never wait,
+	// but are tried again by the next Get.
+	FailFast bool
+}
+
+func (ll *LoadLimit) validate() error {
+	switch {
+	case ll.Global < 0:
+		return fmt.Errorf("tearc: config.MaxConcurrentLoads.Global cannot be negative")
+	case ll.PerShard < 0:
+		return fmt.Errorf("tearc: config.MaxConcurrentLoads.PerShard cannot be negative")
+	case ll.Global == 0 && ll.PerShard == 0:
+		return fmt.Errorf("tearc: config.MaxConcurrentLoads needs a Global or PerShard limit")
+	}
+	return nil
+}
+
+// loadLimiter is the LoadLimit of a single bucket. A nil *loadLimiter doesn't
+// limit anything.
+type loadLimiter struct {
+	global   chan struct{}
+	shard    chan struct{}
+	failFast bool
+}
+
+// newLoadLimiters returns a loadLimiter for every bucket, that share the
+// global limit.
+func newLoadLimiters(config *LoadLimit, shards int) []*loadLimiter {
+	limiters := make([]*loadLimiter, shards)
+	if config == nil {
+		return limiters
+	}
+
+	var global chan struct{}
+	if config.Global > 0 {
+		global = make(chan struct{}, config.Global)
+	}
+	for i := range limiters {
+		limiters[i] = &loadLimiter{global: global, failFast: config.FailFast}
+		if config.PerShard > 0 {
+			limiters[i].shard = make(chan struct{}, config.PerShard)
+		}
+	}
+	return limiters
+}
+
+// acquire reserves a load. It waits for a running load to finish until lc
+// is done, unless lc is nil or config.FailFast is set, in which case it
+// returns ErrLoadLimit if no load can be reserved immediately. Every
+// successful acquire must be followed by a release.
+func (l *loadLimiter) acquire(lc *LoaderContext) error {
+	if l == nil {
+		return nil
+	}
+	if l.failFast {
+		lc = nil
+	}
+
+	// the shard limit is always acquired first, so waiting loads can't
+	// deadlock each other
+	if err := reserve(l.shard, lc); err != nil {
+		return err
+	}
+	if err := reserve(l.global, lc); err != nil {
+		free(l.shard)
+		return err
+	}
+	return nil
+}
+
+func (l *loadLimiter) release() {
+	if l == nil {
+		return
+	}
+	free(l.global)
+	free(l.shard)
+}
+
+// reserve reserves a slot of the semaphore sem. A nil sem has unlimited
+// slots.
+func reserve(sem chan struct{}, lc *LoaderContext) error {
+	if sem == nil {
+		return nil
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	default:
+	}
+	if lc == nil {
+		return ErrLoadLimit
+	}
+
+	var deadline <-chan time.Time
+	if !lc.Deadline.IsZero() {
+		timer := time.NewTimer(time.Until(lc.Deadline))
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	select {
+	case sem <- struct{}{}:
+		return nil
+	case <-lc.Context.Done():
+		return lc.Context.Err()
+	case <-deadline:
+		return context.DeadlineExceeded
+	}
+}
+
+func free(sem chan struct{}) {
+	if sem != nil {
+		<-sem
+	}
+}
@@ -0,0 +1,59 @@
+package tearc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLoaderPanic is wrapped by the error of Cache.Get, if the LoaderFunc
+// panicked. The panic is logged with its stack trace.
+var ErrLoaderPanic = errors.New("tearc: LoaderFunc panicked")
+
+// LoaderContext carries the information of a Cache.Get call to the
+// LoaderFunc. It is validated by Get, before the cache is accessed.
+type LoaderContext struct {
+	// Context is the context of the caller, e.g. to pass it on to the
+	// backend of the LoaderFunc. Get fails with Context.Err() if it is done.
+	// Nil is replaced with context.Background().
+	Context context.Context
+	// Length is the requested length of []byte values. Get fails if a loaded
+	// or cached []byte value has another length. Zero accepts every length.
+	// For example: 32
+	Length int
+	// Deadline is the time after which Get fails with
+	// context.DeadlineExceeded instead of waiting for a load (see
+	// BucketConfig.MaxConcurrentLoads). It doesn't interrupt a running
+	// LoaderFunc, which should use Context instead. Zero disables it.
+	Deadline time.Time
+	// Metadata is passed to the LoaderFunc unchanged, e.g. the caller or
+	// tenant for audit logs. It is optional.
+	Metadata map[string]string
+}
+
+// validate checks lc and sets its defaults.
+func (lc *LoaderContext) validate() error {
+	if lc.Context == nil {
+		lc.Context = context.Background()
+	}
+	if lc.Length < 0 {
+		return fmt.Errorf("tearc: LoaderContext.Length cannot be %d", lc.Length)
+	}
+	if err := lc.Context.Err(); err != nil {
+		return err
+	}
+	if !lc.Deadline.IsZero() && !time.Now().Before(lc.Deadline) {
+		return context.DeadlineExceeded
+	}
+	return nil
+}
+
+// check returns an error if value is a []byte, but not of the requested
+// length.
+func (lc *LoaderContext) check(value interface{}) error {
+	if buf, ok := value.([]byte); ok && lc.Length > 0 && len(buf) != lc.Length {
+		return fmt.Errorf("tearc: value has %d bytes, but %d were requested", len(buf), lc.Length)
+	}
+	return nil
+}
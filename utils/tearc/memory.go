@@ -0,0 +1,122 @@
+package tearc
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// MemoryPressure configures the eviction of cached items when the process
+// approaches its memory limit. A process that is killed for running out of
+// memory may leave a core dump, which should contain as few cached items
+// (private keys) as possible.
+type MemoryPressure struct {
+	// Limit is the memory limit of the process in bytes, e.g. the limit of
+	// its container or the value passed to debug.SetMemoryLimit. For example:
+	// 512 << 20
+	Limit uint64
+	// Threshold is the fraction of Limit at which cached items are evicted.
+	// For example: 0.9
+	Threshold float64
+	// Evict is the fraction of cached items that is evicted after every GC
+	// cycle above Threshold. The least recently used items are evicted first.
+	// For example: 0.25
+	Evict float64
+}
+
+func (mp *MemoryPressure) validate() error {
+	switch {
+	case mp.Limit == 0:
+		return fmt.Errorf("tearc: config.MemoryPressure.Limit must be greater than zero")
+	case mp.Threshold <= 0 || mp.Threshold > 1:
+		return fmt.Errorf("tearc: config.MemoryPressure.Threshold must be greater than zero and at most one")
+	case mp.Evict <= 0 || mp.Evict > 1:
+		return fmt.Errorf("tearc: config.MemoryPressure.Evict must be greater than zero and at most one")
+	}
+	return nil
+}
+
+// memoryWatcher checks the memory usage of the process after every GC cycle
+// and shrinks the cache if it is above the threshold.
+type memoryWatcher struct {
+	log       Logger
+	config    *MemoryPressure
+	cache     *tearc
+	gc        chan struct{}
+	closeOnce sync.Once
+	closeSig  chan struct{}
+}
+
+func newMemoryWatcher(config *MemoryPressure, cache *tearc, log Logger) *memoryWatcher {
+	return &memoryWatcher{
+		log:      log,
+		config:   config,
+		cache:    cache,
+		gc:       make(chan struct{}, 1),
+		closeSig: make(chan struct{}),
+	}
+}
+
+// gcSentinel is allocated unreachable to get notified about the next GC cycle
+// by its finalizer. It contains a pointer, as the tiny allocator (used for
+// small objects without pointers) doesn't guarantee finalizers.
+type gcSentinel struct {
+	_ *byte
+}
+
+// register notifies w.gc after the next GC cycle and registers again, until
+// w is closed.
+func (w *memoryWatcher) register() {
+	runtime.SetFinalizer(&gcSentinel{}, func(*gcSentinel) {
+		select {
+		case <-w.closeSig:
+			return
+		default:
+		}
+
+		// finalizers run in a single go routine and must not block
+		select {
+		case w.gc <- struct{}{}:
+		default:
+		}
+		w.register()
+	})
+}
+
+func (w *memoryWatcher) check() {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	// the same measure of memory usage as used by the Go runtime for its
+	// memory limit
+	used := m.Sys - m.HeapReleased
+	if float64(used) < float64(w.config.Limit)*w.config.Threshold {
+		return
+	}
+
+	evicted := w.cache.Shrink(w.config.Evict)
+	w.log.Warn("process approaches its memory limit, evicted cached items",
+		"used", used,
+		"limit", w.config.Limit,
+		"evicted", evicted)
+}
+
+func (w *memoryWatcher) start() {
+	w.register()
+	go func() {
+		for {
+			select {
+			case <-w.gc:
+				w.check()
+			case <-w.closeSig:
+				return
+			}
+		}
+	}()
+}
+
+func (w *memoryWatcher) Close() {
+	w.closeOnce.Do(func() {
+		close(w.closeSig)
+	})
+}
@@ -0,0 +1,146 @@
+package tearc
+
+import (
+	"sync"
+	"time"
+)
+
+// reaper evicts the items of all buckets of a cache in a single go routine.
+// It sleeps until the next item of any bucket is due (but at least MinTick
+// and at most MaxTick), and doesn't wake up at all while the cache is empty.
+type reaper struct {
+	log       Logger
+	buckets   []*bucket
+	now       func() time.Time
+	next      time.Time
+	minTick   time.Duration
+	maxTick   time.Duration
+	nextLock  sync.Mutex
+	wake      chan struct{}
+	closeOnce sync.Once
+	closeSig  chan struct{}
+}
+
+func newReaper(config *BucketConfig, buckets []*bucket, log Logger) *reaper {
+	return &reaper{
+		log:      log,
+		buckets:  buckets,
+		now:      utcNow,
+		minTick:  config.MinTick,
+		maxTick:  config.MaxTick,
+		wake:     make(chan struct{}, 1),
+		closeSig: make(chan struct{}),
+	}
+}
+
+// schedule makes sure the reaper runs at evictionTime at latest. It is called
+// by buckets for every newly loaded item.
+func (r *reaper) schedule(evictionTime time.Time) {
+	r.nextLock.Lock()
+	defer r.nextLock.Unlock()
+
+	if !r.next.IsZero() && !evictionTime.Before(r.next) {
+		return
+	}
+	r.next = evictionTime
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+		// the reaper is already woken up and reads r.next
+	}
+}
+
+// setTicks replaces MinTick and MaxTick and wakes the reaper up, so the next
+// run is scheduled with them.
+func (r *reaper) setTicks(minTick time.Duration, maxTick time.Duration) {
+	r.nextLock.Lock()
+	r.minTick, r.maxTick = minTick, maxTick
+	r.nextLock.Unlock()
+
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// reap reaps every bucket and returns the earliest eviction time of
+// the remaining items, or the zero time if there are none.
+func (r *reaper) reap() time.Time {
+	var next time.Time
+	for _, b := range r.buckets {
+		t := b.reap()
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	return next
+}
+
+// timeout returns the time until r.next, limited to MinTick and MaxTick. ok
+// is false if no item is scheduled.
+func (r *reaper) timeout() (timeout time.Duration, ok bool) {
+	r.nextLock.Lock()
+	defer r.nextLock.Unlock()
+
+	if r.next.IsZero() {
+		return 0, false
+	}
+
+	timeout = r.next.Sub(r.now()) + 50*time.Millisecond
+	if timeout < r.minTick {
+		timeout = r.minTick
+	} else if timeout > r.maxTick {
+		timeout = r.maxTick
+	}
+	return timeout, true
+}
+
+func (r *reaper) start() {
+	go func() {
+		// the timer only runs while items are scheduled
+		t := time.NewTimer(time.Hour)
+		t.Stop()
+
+		reset := func() {
+			if !t.Stop() {
+				// drain a fired, but not yet received timer
+				select {
+				case <-t.C:
+				default:
+				}
+			}
+			if timeout, ok := r.timeout(); ok {
+				r.log.Debug("next reaper run", "timeout", timeout)
+				t.Reset(timeout)
+			}
+		}
+
+		for {
+			select {
+			case <-t.C:
+				// items may be scheduled while reaping. Only items that are
+				// due before the remaining ones must replace r.next
+				r.nextLock.Lock()
+				r.next = time.Time{}
+				r.nextLock.Unlock()
+
+				if next := r.reap(); !next.IsZero() {
+					r.schedule(next)
+				}
+				reset()
+			case <-r.wake:
+				reset()
+			case <-r.closeSig:
+				t.Stop()
+				return
+			}
+		}
+	}()
+}
+
+func (r *reaper) Close() {
+	r.closeOnce.Do(func() {
+		r.closeSig <- struct{}{}
+	})
+}
@@ -0,0 +1,192 @@
+package tearc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// Access is a recorded Cache.Get of key at Time.
+type Access struct {
+	Key  string
+	Time time.Time
+}
+
+// Simulation configures the cache that Simulate replays a trace against.
+type Simulation struct {
+	// Size and Shards are the size and amount of shards of the cache (see
+	// NewCache). For example: 65536 and 64
+	Size   int
+	Shards int
+	// Config is the configuration of the cache. The reaper runs according
+	// to MinTick and MaxTick in virtual time. MemoryPressure, AutoTune and
+	// MaxConcurrentLoads are ignored, as loads don't take any time.
+	Config *BucketConfig
+	// TTL returns the TTL the LoaderFunc would return for key. For example:
+	//   func(string) tearc.TTL { return tearc.TTL{Idle: time.Minute} }
+	TTL func(key string) TTL
+}
+
+// SimulationResult is the outcome of Simulate.
+type SimulationResult struct {
+	// Accesses is the amount of replayed accesses.
+	Accesses uint64
+	// Hits is the amount of accesses served from the cache.
+	Hits uint64
+	// HitRatio is the fraction of Accesses served from the cache.
+	HitRatio float64
+	// Loads is the amount of LoaderFunc calls, including refreshes after
+	// the soft TTL of an item.
+	Loads uint64
+	// PeakResident is the highest amount of items (e.g. secrets) that were
+	// in memory at the same time. Items whose eviction time has passed, but
+	// that the reaper didn't evict yet, are included.
+	PeakResident int
+	// PeakTime is the time of the access that reached PeakResident.
+	PeakTime time.Time
+	// Shards are the Stats of the cache after the last access.
+	Shards []ShardStats
+}
+
+// Simulate replays trace against a cache configured by sim and reports its
+// hit ratio and the peak amount of cached items, e.g. to size the cache of a
+// new deployment with a trace recorded in production. trace must be ordered
+// by time.
+//
+// The cache runs in virtual time: the TTLs, refreshes and reaper runs follow
+// the times of the accesses instead of the wall clock, so a trace of days is
+// replayed in seconds. Keys are mapped to shards with a fixed hash instead of
+// a random seed and refreshes run synchronously, so equal traces and
+// configurations always have equal results.
+func Simulate(trace []Access, sim *Simulation) (*SimulationResult, error) {
+	if sim == nil {
+		return nil, fmt.Errorf("tearc: simulation must not be nil")
+	}
+	if sim.TTL == nil {
+		return nil, fmt.Errorf("tearc: simulation.TTL must not be nil")
+	}
+
+	var now time.Time
+	clock := func() time.Time {
+		return now
+	}
+	var loads uint64
+	t, err := newTearc(sim.Size, sim.Shards, func(key string, _ LoaderContext) (interface{}, TTL, error) {
+		loads++
+		return struct{}{}, sim.TTL(key), nil
+	}, nil, sim.Config, nil)
+	if err != nil {
+		return nil, err
+	}
+	t.hash = fnvHash
+	t.reaper.now = clock
+	for _, b := range t.buckets {
+		b.now = clock
+		b.simulated = true
+		if b.admission != nil {
+			b.admission.hashKey = fnvHash
+		}
+	}
+
+	// reapAt is the virtual time of the next reaper run, or zero while the
+	// reaper sleeps. It follows the timer of the reaper go routine
+	var reapAt time.Time
+	r := t.reaper
+	woken := func() bool {
+		select {
+		case <-r.wake:
+			return true
+		default:
+			return false
+		}
+	}
+	reset := func() {
+		reapAt = time.Time{}
+		if timeout, ok := r.timeout(); ok {
+			reapAt = now.Add(timeout)
+		}
+	}
+
+	result := &SimulationResult{}
+	for i, access := range trace {
+		at := access.Time.UTC()
+		if i > 0 && at.Before(now) {
+			return nil, fmt.Errorf("tearc: trace isn't ordered by time at access %d", i)
+		}
+
+		for !reapAt.IsZero() && !reapAt.After(at) {
+			now = reapAt
+			r.next = time.Time{}
+			if next := r.reap(); !next.IsZero() {
+				r.schedule(next)
+			}
+			woken()
+			reset()
+		}
+
+		now = at
+		if _, err := t.Get(access.Key, LoaderContext{}); err != nil {
+			return nil, err
+		}
+		if woken() {
+			reset()
+		}
+
+		resident := 0
+		for _, b := range t.buckets {
+			resident += b.arc.len()
+		}
+		if resident > result.PeakResident {
+			result.PeakResident = resident
+			result.PeakTime = at
+		}
+	}
+
+	result.Accesses = uint64(len(trace))
+	for _, b := range t.buckets {
+		result.Hits += atomic.LoadUint64(&b.hits)
+	}
+	if result.Accesses > 0 {
+		result.HitRatio = float64(result.Hits) / float64(result.Accesses)
+	}
+	result.Loads = loads
+	result.Shards = t.Stats()
+	return result, nil
+}
+
+// ReadTrace reads a trace for Simulate from CSV records of a key and the
+// RFC 3339 time of its access. Lines starting with # are ignored. For
+// example:
+//   users/jane,2021-08-30T12:00:00.125Z
+func ReadTrace(r io.Reader) ([]Access, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = 2
+	cr.Comment = '#'
+	cr.ReuseRecord = true
+
+	var trace []Access
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return trace, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tearc: invalid trace: %w", err)
+		}
+		at, err := time.Parse(time.RFC3339Nano, record[1])
+		if err != nil {
+			return nil, fmt.Errorf("tearc: invalid time of access %d: %w", len(trace), err)
+		}
+		trace = append(trace, Access{Key: record[0], Time: at})
+	}
+}
+
+// fnvHash is the hash of keys in a Simulation.
+func fnvHash(key string) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum64()
+}
@@ -5,28 +5,99 @@ import (
 	"hash/maphash"
 	"sync"
 	"time"
-
-	"github.com/bluele/gcache"
-	logger "github.com/harwoeck/liblog/contract"
 )
 
 // Cache represents a single tearc instance
 type Cache interface {
-	Get(key string, loadInfo interface{}) (interface{}, error)
+	// Get returns the value of key and loads it with the LoaderFunc, if it
+	// isn't cached. lc is validated first (see LoaderContext).
+	Get(key string, lc LoaderContext) (interface{}, error)
+	// Shrink evicts the given fraction (0 to 1) of items of every shard,
+	// starting with the least recently used ones, and returns the amount of
+	// evicted items. It can be used to react to memory pressure signals not
+	// covered by BucketConfig.MemoryPressure.
+	Shrink(fraction float64) int
+	// Remove evicts the value of key and reports whether it was cached. It
+	// can be used to invalidate values whose source changed. A load of key
+	// running concurrently may cache the value again.
+	Remove(key string) bool
+	// Resize changes the size of the cache. The amount of shards stays the
+	// same, so size must be divisible by it. When shrinking, every shard
+	// evicts only as many of its least recently used items as exceed its
+	// new size. All other items stay cached.
+	Resize(size int) error
+	// SetTicks replaces BucketConfig.MinTick and BucketConfig.MaxTick of the
+	// running cache.
+	SetTicks(minTick time.Duration, maxTick time.Duration) error
+	// Stats returns the partition sizes and ghost list hits of the adaptive
+	// replacement cache of every shard, ordered by shard.
+	Stats() []ShardStats
 	Close()
 }
 
 // LoaderFunc represents a callback to load a non-existing value into the
-// cache. info is the loadInfo object passed to Cache.Get.
-type LoaderFunc func(key string, info interface{}) (value interface{}, evictIn time.Duration, err error)
+// cache. lc is the LoaderContext passed to Cache.Get. If the value is
+// refreshed after its soft TTL, LoaderFunc is called in a new go routine with
+// the Length and Metadata of the Get that triggered the refresh, possibly
+// after this Get has returned, and a Context bounded by
+// BucketConfig.RefreshTimeout instead of the one of the Get. A panic is recovered and returned as error wrapping
+// ErrLoaderPanic.
+type LoaderFunc func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error)
+
+// TTL specifies when a loaded value is refreshed and evicted. All durations
+// are relative to the time the value was loaded, except Idle without Fixed.
+type TTL struct {
+	// Idle is the time after which the value is evicted, if it isn't used
+	// (Get). Every Get resets it. For example: 1 * time.Minute
+	Idle time.Duration
+	// Soft is the time after which the next Get refreshes the value in a new
+	// go routine, while still returning the current value. The refreshed value
+	// gets the TTL returned by the LoaderFunc. If the refresh fails, the
+	// current value is kept and the next Get tries again. Zero disables
+	// refreshing. For example: 10 * time.Minute
+	Soft time.Duration
+	// Hard is the time after which the value is evicted, even if it is used
+	// constantly. A Get after Hard never returns the value, but loads it
+	// again. Zero disables the absolute eviction. For example: 1 * time.Hour
+	Hard time.Duration
+	// Fixed disables the reset of Idle by Get for this value, so it is
+	// evicted Idle after it was loaded (or refreshed), however often it is
+	// used, while other values keep their sliding eviction time. It suits
+	// secrets whose lifetime must never be extended by access, e.g.
+	// one-time unwrap keys. For example: true
+	Fixed bool
+}
 
 // EvictedFunc is an information callback that is called after an item has been
-// evicted from the cache.
+// evicted from the cache. It is called in a new go routine. A panic is
+// recovered and logged.
 type EvictedFunc func(key string)
 
-// NewCache creates a new tearc instance
-func NewCache(size int, shards int, loader LoaderFunc, evicted EvictedFunc, config *BucketConfig, log logger.Logger) (Cache, error) {
-	log = log.Named("tearc")
+// NewCache creates a new tearc instance. If log is nil nothing is logged.
+func NewCache(size int, shards int, loader LoaderFunc, evicted EvictedFunc, config *BucketConfig, log Logger) (Cache, error) {
+	log = named(log, "tearc")
+
+	t, err := newTearc(size, shards, loader, evicted, config, log)
+	if err != nil {
+		return nil, err
+	}
+
+	t.reaper.start()
+	if config.MemoryPressure != nil {
+		t.memory = newMemoryWatcher(config.MemoryPressure, t, named(log, "memory"))
+		t.memory.start()
+	}
+	if config.AutoTune != nil {
+		t.tuner = newTuner(config.AutoTune, t, named(log, "autotune"))
+		t.tuner.start()
+	}
+
+	return t, nil
+}
+
+// newTearc validates the arguments of NewCache and creates the cache, without
+// starting its go routines.
+func newTearc(size int, shards int, loader LoaderFunc, evicted EvictedFunc, config *BucketConfig, log Logger) (*tearc, error) {
 
 	if size <= 0 {
 		return nil, fmt.Errorf("tearc: size cannot be %d! Must be greater than zero", size)
@@ -50,11 +121,41 @@ func NewCache(size int, shards int, loader LoaderFunc, evicted EvictedFunc, conf
 		if config.MinTick >= config.MaxTick {
 			return nil, fmt.Errorf("tearc: config.MinTick must be less than config.MustTick")
 		}
+		if config.RefreshTimeout < 0 {
+			return nil, fmt.Errorf("tearc: config.RefreshTimeout cannot be negative")
+		}
+		if !config.Policy.valid() {
+			return nil, fmt.Errorf("tearc: config.Policy %d is unknown", config.Policy)
+		}
+		if !config.Eviction.valid() {
+			return nil, fmt.Errorf("tearc: config.Eviction %d is unknown", config.Eviction)
+		}
+		if config.Eviction == EvictionARCOnly && config.MaxLifetime > 0 {
+			return nil, fmt.Errorf("tearc: config.MaxLifetime must be zero with EvictionARCOnly")
+		}
+		if !config.Sharding.valid() {
+			return nil, fmt.Errorf("tearc: config.Sharding %d is unknown", config.Sharding)
+		}
+		if config.MaxConcurrentLoads != nil {
+			if err := config.MaxConcurrentLoads.validate(); err != nil {
+				return nil, err
+			}
+		}
+		if config.MemoryPressure != nil {
+			if err := config.MemoryPressure.validate(); err != nil {
+				return nil, err
+			}
+		}
+		if config.AutoTune != nil {
+			if err := config.AutoTune.validate(); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	t := &tearc{
-		size:   size,
-		shards: uint64(shards),
+		sharding: config.Sharding,
+		shards:   uint64(shards),
 		hasherPool: sync.Pool{
 			New: func() interface{} {
 				return &maphash.Hash{}
@@ -62,35 +163,54 @@ func NewCache(size int, shards int, loader LoaderFunc, evicted EvictedFunc, conf
 		jumpSeed: maphash.MakeSeed(),
 	}
 
+	loads := newLoadLimiters(config.MaxConcurrentLoads, shards)
 	t.buckets = make([]*bucket, shards)
 	for i := 0; i < shards; i++ {
 		t.buckets[i] = &bucket{
 			id:       i,
-			log:      log.Named(fmt.Sprintf("bucket-%d", i)),
+			size:     size / shards,
+			log:      named(log, fmt.Sprintf("bucket-%d", i)),
 			loader:   loader,
 			evicted:  evicted,
 			config:   config,
-			arc:      gcache.New(size / shards).ARC().Build(),
 			eq:       make(evictionQueue, 0),
 			eqPtrMap: make(map[string]*heapItem),
+			loads:    loads[i],
+			now:      utcNow,
+		}
+		t.buckets[i].arc = t.buckets[i].newARC(size / shards)
+		if config.Policy == PolicyTinyLFU {
+			t.buckets[i].admission = newTinyLFU(size / shards)
 		}
+	}
 
-		go t.buckets[i].startReaper()
+	t.reaper = newReaper(config, t.buckets, named(log, "reaper"))
+	for _, b := range t.buckets {
+		b.reaper = t.reaper
 	}
 
 	return t, nil
 }
 
 type tearc struct {
-	size   int
-	shards uint64
+	shards   uint64
+	sharding Sharding
 
 	hasherPool sync.Pool
 	jumpSeed   maphash.Seed
 	buckets    []*bucket
+	reaper     *reaper
+	memory     *memoryWatcher
+	tuner      *tuner
+
+	// hash replaces the seeded maphash of keys, if it isn't nil
+	hash func(key string) uint64
 }
 
 func (t *tearc) jump(key string) *bucket {
+	if t.hash != nil {
+		return t.buckets[shardIndex(t.sharding, t.hash(key), t.shards)]
+	}
 	h := t.hasherPool.Get().(*maphash.Hash)
 	defer func() {
 		h.Reset()
@@ -98,15 +218,66 @@ func (t *tearc) jump(key string) *bucket {
 	}()
 	h.SetSeed(t.jumpSeed)
 	_, _ = h.WriteString(key)
-	jumpIdx := h.Sum64() % t.shards
+	jumpIdx := shardIndex(t.sharding, h.Sum64(), t.shards)
 	return t.buckets[jumpIdx]
 }
 
-func (t *tearc) Get(key string, loadInfo interface{}) (interface{}, error) {
-	return t.jump(key).Get(key, loadInfo)
+func (t *tearc) Get(key string, lc LoaderContext) (interface{}, error) {
+	if err := lc.validate(); err != nil {
+		return nil, err
+	}
+	return t.jump(key).Get(key, lc)
+}
+
+func (t *tearc) Shrink(fraction float64) int {
+	evicted := 0
+	for _, b := range t.buckets {
+		evicted += b.shrink(fraction)
+	}
+	return evicted
+}
+
+func (t *tearc) Remove(key string) bool {
+	return t.jump(key).removeKey(key)
+}
+
+func (t *tearc) Resize(size int) error {
+	if size <= 0 {
+		return fmt.Errorf("tearc: size cannot be %d! Must be greater than zero", size)
+	}
+	if uint64(size)%t.shards != 0 {
+		return fmt.Errorf("tearc: size must be easily dividable into shards")
+	}
+	for _, b := range t.buckets {
+		b.resize(size / int(t.shards))
+	}
+	return nil
+}
+
+func (t *tearc) SetTicks(minTick time.Duration, maxTick time.Duration) error {
+	if minTick >= maxTick {
+		return fmt.Errorf("tearc: minTick must be less than maxTick")
+	}
+	t.reaper.setTicks(minTick, maxTick)
+	return nil
+}
+
+func (t *tearc) Stats() []ShardStats {
+	stats := make([]ShardStats, len(t.buckets))
+	for i, b := range t.buckets {
+		stats[i] = b.stats()
+	}
+	return stats
 }
 
 func (t *tearc) Close() {
+	if t.tuner != nil {
+		t.tuner.Close()
+	}
+	if t.memory != nil {
+		t.memory.Close()
+	}
+	t.reaper.Close()
 	for _, b := range t.buckets {
 		b.Close()
 	}
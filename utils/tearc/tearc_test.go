@@ -1,11 +1,15 @@
 package tearc
 
 import (
+	"context"
 	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
-	"github.com/harwoeck/liblog/contract"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -17,15 +21,15 @@ func TestSimple(t *testing.T) {
 	load1 := false
 	load2 := false
 
-	cache, err := NewCache(1000, 4, func(key string, loadInfo interface{}) (value interface{}, evictIn time.Duration, err error) {
+	cache, err := NewCache(1000, 4, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
 		switch key {
 		case "key1":
 			load1 = true
-			return []byte("private key 1"), 1 * time.Second, nil
+			return []byte("private key 1"), TTL{Idle: 1 * time.Second}, nil
 		case "key2":
 			load2 = true
-			return []byte("private key 2"), 1 * time.Second, nil
-		default: return nil, 0, fmt.Errorf("unknown key")
+			return []byte("private key 2"), TTL{Idle: 1 * time.Second}, nil
+		default: return nil, TTL{}, fmt.Errorf("unknown key")
 		}
 	}, func(key string) {
 		switch key {
@@ -35,12 +39,12 @@ func TestSimple(t *testing.T) {
 	}, &BucketConfig{
 		MinTick: 500 * time.Millisecond,
 		MaxTick: 3 * time.Second,
-	}, contract.MustNewStd())
+	}, nil)
 	require.NoError(t, err)
 	defer cache.Close()
 
 	// verify load key 1
-	x, err := cache.Get("key1", nil)
+	x, err := cache.Get("key1", LoaderContext{})
 	require.NoError(t, err)
 	assert.True(t, load1)
 	buf, ok := x.([]byte)
@@ -48,7 +52,7 @@ func TestSimple(t *testing.T) {
 	assert.Equal(t, "private key 1", string(buf))
 
 	// verify load key 2
-	y, err := cache.Get("key2", nil)
+	y, err := cache.Get("key2", LoaderContext{})
 	require.NoError(t, err)
 	assert.True(t, load2)
 	buf, ok = y.([]byte)
@@ -57,7 +61,7 @@ func TestSimple(t *testing.T) {
 
 	// verify key2 is cached
 	load2 = false
-	x, err = cache.Get("key2", nil)
+	x, err = cache.Get("key2", LoaderContext{})
 	require.NoError(t, err)
 	assert.False(t, load2)
 	buf, ok = x.([]byte)
@@ -67,7 +71,1141 @@ func TestSimple(t *testing.T) {
 	// sleep
 	time.Sleep(2 * time.Second)
 
-	// verify correct evictions
+	// verify correct evictions: Get moves the eviction of key2 to Idle from
+	// now (see TestSlidingIdle), so it is evicted like key1
 	assert.True(t, evicted1)
-	assert.False(t, evicted2)
+	assert.True(t, evicted2)
+}
+
+func TestSlidingIdle(t *testing.T) {
+	var loads int32
+	evicted := make(chan string, 10)
+
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		atomic.AddInt32(&loads, 1)
+		return []byte("private key"), TTL{Idle: 1 * time.Second}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick: 100 * time.Millisecond,
+		MaxTick: 1 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// every Get moves the eviction to Idle from now, so a value used more
+	// often than Idle stays cached longer than Idle
+	for i := 0; i < 8; i++ {
+		_, err := cache.Get("key", LoaderContext{})
+		require.NoError(t, err)
+		time.Sleep(250 * time.Millisecond)
+	}
+	assert.Equal(t, int32(1), atomic.LoadInt32(&loads))
+	assert.Empty(t, evicted)
+
+	// without Get it is evicted after Idle
+	select {
+	case key := <-evicted:
+		assert.Equal(t, "key", key)
+	case <-time.After(3 * time.Second):
+		t.Fatal("key wasn't evicted after Idle")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	var loads int32
+	evicted := make(chan string, 10)
+
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		n := atomic.AddInt32(&loads, 1)
+		switch key {
+		case "soft":
+			return n, TTL{Idle: 10 * time.Second, Soft: 300 * time.Millisecond}, nil
+		case "hard":
+			return n, TTL{Idle: 10 * time.Second, Hard: 500 * time.Millisecond}, nil
+		default:
+			return nil, TTL{}, fmt.Errorf("unknown key")
+		}
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick: 100 * time.Millisecond,
+		MaxTick: 1 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// after the soft TTL the current value is returned and refreshed in the
+	// background
+	x, err := cache.Get("soft", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), x)
+	time.Sleep(400 * time.Millisecond)
+	x, err = cache.Get("soft", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), x)
+	assert.Eventually(t, func() bool {
+		x, err := cache.Get("soft", LoaderContext{})
+		return err == nil && x == int32(2)
+	}, 1*time.Second, 10*time.Millisecond)
+
+	// after the hard TTL the value is evicted, even though it is used
+	// constantly
+	x, err = cache.Get("hard", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), x)
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		_, err = cache.Get("hard", LoaderContext{})
+		require.NoError(t, err)
+		select {
+		case key := <-evicted:
+			assert.Equal(t, "hard", key)
+			x, err = cache.Get("hard", LoaderContext{})
+			require.NoError(t, err)
+			assert.Equal(t, int32(4), x)
+			return
+		default:
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	t.Fatal("value wasn't evicted after its hard TTL")
+}
+
+func TestRefresh_CanceledContext(t *testing.T) {
+	var loads int32
+	release := make(chan struct{})
+	refreshed := make(chan error, 1)
+	var refreshLC LoaderContext
+
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		n := atomic.AddInt32(&loads, 1)
+		if n > 1 {
+			// the refresh runs after the Get that triggered it has returned
+			// and its Context was canceled
+			<-release
+			refreshLC = lc
+			refreshed <- lc.Context.Err()
+		}
+		return n, TTL{Idle: 10 * time.Second, Soft: 100 * time.Millisecond}, nil
+	}, nil, &BucketConfig{
+		MinTick:        100 * time.Millisecond,
+		MaxTick:        1 * time.Second,
+		RefreshTimeout: 5 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	metadata := map[string]string{"tenant": "a"}
+	_, err = cache.Get("key", LoaderContext{Metadata: metadata})
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	x, err := cache.Get("key", LoaderContext{Context: ctx, Metadata: metadata})
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), x)
+	cancel()
+	close(release)
+
+	assert.NoError(t, <-refreshed)
+	_, ok := refreshLC.Context.Deadline()
+	assert.True(t, ok, "refreshes are bounded by RefreshTimeout")
+	assert.Equal(t, metadata, refreshLC.Metadata)
+	assert.Eventually(t, func() bool {
+		x, err := cache.Get("key", LoaderContext{})
+		return err == nil && x == int32(2)
+	}, 1*time.Second, 10*time.Millisecond)
+
+	_, err = NewCache(100, 1, func(key string, lc LoaderContext) (interface{}, TTL, error) {
+		return nil, TTL{}, nil
+	}, nil, &BucketConfig{MinTick: 1, MaxTick: 2, RefreshTimeout: -1}, nil)
+	assert.Error(t, err)
+}
+
+func TestFixedTTL(t *testing.T) {
+	var loads int32
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		n := atomic.AddInt32(&loads, 1)
+		return n, TTL{Idle: 500 * time.Millisecond, Fixed: key == "fixed"}, nil
+	}, nil, &BucketConfig{
+		MinTick: 100 * time.Millisecond,
+		MaxTick: 1 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	fixed, err := cache.Get("fixed", LoaderContext{})
+	require.NoError(t, err)
+	sliding, err := cache.Get("sliding", LoaderContext{})
+	require.NoError(t, err)
+
+	// the fixed value is evicted 500ms after it was loaded, even though it is
+	// used constantly, while the sliding value stays cached
+	for i := 0; i < 7; i++ {
+		time.Sleep(100 * time.Millisecond)
+		x, err := cache.Get("sliding", LoaderContext{})
+		require.NoError(t, err)
+		assert.Equal(t, sliding, x)
+		x, err = cache.Get("fixed", LoaderContext{})
+		require.NoError(t, err)
+		if i < 3 {
+			assert.Equal(t, fixed, x)
+		}
+	}
+	x, err := cache.Get("fixed", LoaderContext{})
+	require.NoError(t, err)
+	assert.NotEqual(t, fixed, x)
+}
+
+func TestMaxLifetime(t *testing.T) {
+	var loads int32
+	var values [][]byte
+	var valuesLock sync.Mutex
+	evicted := make(chan string, 10)
+
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		atomic.AddInt32(&loads, 1)
+		buf := []byte("private key")
+		valuesLock.Lock()
+		values = append(values, buf)
+		valuesLock.Unlock()
+		return buf, TTL{Idle: 10 * time.Second, Hard: 1 * time.Hour}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick:     100 * time.Millisecond,
+		MaxTick:     1 * time.Second,
+		MaxLifetime: 500 * time.Millisecond,
+		Zeroize:     true,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// the value is evicted after MaxLifetime instead of its hard TTL, even
+	// though it is used constantly
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		require.True(t, time.Now().Before(deadline), "value wasn't evicted after MaxLifetime")
+		_, err = cache.Get("key", LoaderContext{})
+		require.NoError(t, err)
+		if len(evicted) > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, "key", <-evicted)
+
+	x, err := cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "private key", string(x.([]byte)))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+
+	// the evicted value is zeroized
+	valuesLock.Lock()
+	defer valuesLock.Unlock()
+	assert.Equal(t, make([]byte, len("private key")), values[0])
+}
+
+func TestOverdue(t *testing.T) {
+	var loads int32
+	var values [][]byte
+	var valuesLock sync.Mutex
+	evicted := make(chan string, 10)
+
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		atomic.AddInt32(&loads, 1)
+		buf := []byte("private key")
+		valuesLock.Lock()
+		values = append(values, buf)
+		valuesLock.Unlock()
+		return buf, TTL{Idle: 50 * time.Millisecond}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick: 5 * time.Second,
+		MaxTick: 10 * time.Second,
+		Zeroize: true,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	_, err = cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+
+	// the reaper doesn't run before MinTick, but Get doesn't return the
+	// overdue value
+	time.Sleep(100 * time.Millisecond)
+	x, err := cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "private key", string(x.([]byte)))
+	assert.Equal(t, int32(2), atomic.LoadInt32(&loads))
+	select {
+	case key := <-evicted:
+		assert.Equal(t, "key", key)
+	case <-time.After(time.Second):
+		t.Fatal("overdue item wasn't evicted")
+	}
+
+	// the overdue value is zeroized
+	valuesLock.Lock()
+	defer valuesLock.Unlock()
+	assert.Equal(t, make([]byte, len("private key")), values[0])
+}
+
+func TestReaper(t *testing.T) {
+	evicted := make(chan time.Time, 10)
+
+	goroutines := runtime.NumGoroutine()
+	cache, err := NewCache(64, 64, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{Idle: 300 * time.Millisecond}, nil
+	}, func(key string) {
+		evicted <- time.Now()
+	}, &BucketConfig{
+		MinTick: 10 * time.Millisecond,
+		MaxTick: 1 * time.Hour,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// a single reaper serves all shards
+	assert.LessOrEqual(t, runtime.NumGoroutine(), goroutines+1)
+
+	// the reaper wakes up when the item is due, although MaxTick is long
+	loaded := time.Now()
+	_, err = cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	select {
+	case at := <-evicted:
+		assert.WithinDuration(t, loaded.Add(300*time.Millisecond), at, 200*time.Millisecond)
+	case <-time.After(2 * time.Second):
+		t.Fatal("item wasn't evicted")
+	}
+}
+
+func TestCopyOnRead(t *testing.T) {
+	evicted := make(chan string, 10)
+
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return []byte("private key"), TTL{Idle: 200 * time.Millisecond}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick:    10 * time.Millisecond,
+		MaxTick:    1 * time.Second,
+		Zeroize:    true,
+		CopyOnRead: true,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// modifying a returned value doesn't modify the cached value
+	x, err := cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	x.([]byte)[0] = 'X'
+	y, err := cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "private key", string(y.([]byte)))
+
+	// returned values aren't zeroized on eviction
+	select {
+	case <-evicted:
+	case <-time.After(2 * time.Second):
+		t.Fatal("item wasn't evicted")
+	}
+	assert.Equal(t, "private key", string(y.([]byte)))
+}
+
+func TestShrink(t *testing.T) {
+	evicted := make(chan string, 10)
+
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick: 1 * time.Second,
+		MaxTick: 10 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	for _, key := range []string{"key1", "key2", "key3", "key4"} {
+		_, err = cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+		time.Sleep(time.Millisecond)
+	}
+	_, err = cache.Get("key1", LoaderContext{})
+	require.NoError(t, err)
+
+	// the least recently used half is evicted
+	assert.Equal(t, 2, cache.Shrink(0.5))
+	assert.ElementsMatch(t, []string{"key2", "key3"}, []string{<-evicted, <-evicted})
+}
+
+func TestMemoryPressure(t *testing.T) {
+	evicted := make(chan string, 10)
+
+	_, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{}, nil
+	}, nil, &BucketConfig{
+		MinTick:        1 * time.Second,
+		MaxTick:        10 * time.Second,
+		MemoryPressure: &MemoryPressure{Limit: 1 << 20, Threshold: 1.5, Evict: 1},
+	}, nil)
+	assert.Error(t, err)
+
+	// every process is above a limit of one byte
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick:        1 * time.Second,
+		MaxTick:        10 * time.Second,
+		MemoryPressure: &MemoryPressure{Limit: 1, Threshold: 0.9, Evict: 1},
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	_, err = cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		runtime.GC()
+		select {
+		case key := <-evicted:
+			assert.Equal(t, "key", key)
+			return
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	t.Fatal("item wasn't evicted after GC cycles above the memory limit")
+}
+
+func TestTinyLFU(t *testing.T) {
+	_, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{}, nil
+	}, nil, &BucketConfig{
+		MinTick: 1 * time.Second,
+		MaxTick: 10 * time.Second,
+		Policy:  Policy(42),
+	}, nil)
+	assert.Error(t, err)
+
+	loads := make(map[string]int)
+	cache, err := NewCache(4, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		loads[key]++
+		return []byte(key), TTL{Idle: 1 * time.Minute}, nil
+	}, nil, &BucketConfig{
+		MinTick:    1 * time.Second,
+		MaxTick:    10 * time.Second,
+		Zeroize:    true,
+		CopyOnRead: true,
+		Policy:     PolicyTinyLFU,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	hot := []string{"hot1", "hot2", "hot3", "hot4"}
+	for i := 0; i < 5; i++ {
+		for _, key := range hot {
+			_, err = cache.Get(key, LoaderContext{})
+			require.NoError(t, err)
+		}
+	}
+
+	// keys requested once are loaded and returned, but not admitted
+	for i := 0; i < 50; i++ {
+		key := fmt.Sprintf("once%d", i)
+		value, err := cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+		assert.Equal(t, key, string(value.([]byte)))
+	}
+	for _, key := range hot {
+		_, err = cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+		assert.Equal(t, 1, loads[key], key)
+	}
+
+	// a key that becomes hotter than the cached ones is admitted
+	for i := 0; i < 10; i++ {
+		_, err = cache.Get("new", LoaderContext{})
+		require.NoError(t, err)
+	}
+	assert.Less(t, loads["new"], 10)
+}
+
+func TestMaxConcurrentLoads(t *testing.T) {
+	_, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{}, nil
+	}, nil, &BucketConfig{
+		MinTick:            1 * time.Second,
+		MaxTick:            10 * time.Second,
+		MaxConcurrentLoads: &LoadLimit{},
+	}, nil)
+	assert.Error(t, err)
+
+	var running, maxRunning int32
+	cache, err := NewCache(100, 4, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if n <= max || atomic.CompareAndSwapInt32(&maxRunning, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}, nil, &BucketConfig{
+		MinTick:            1 * time.Second,
+		MaxTick:            10 * time.Second,
+		MaxConcurrentLoads: &LoadLimit{Global: 2, PerShard: 1},
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// excess loads wait
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			value, err := cache.Get(key, LoaderContext{})
+			assert.NoError(t, err)
+			assert.Equal(t, key, value)
+		}(fmt.Sprintf("key%d", i))
+	}
+	wg.Wait()
+	assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(2))
+
+	// excess loads fail fast
+	loading := make(chan struct{})
+	unblock := make(chan struct{})
+	cache, err = NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		if key == "slow" {
+			close(loading)
+			<-unblock
+		}
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}, nil, &BucketConfig{
+		MinTick:            1 * time.Second,
+		MaxTick:            10 * time.Second,
+		MaxConcurrentLoads: &LoadLimit{Global: 1, FailFast: true},
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := cache.Get("slow", LoaderContext{})
+		done <- err
+	}()
+	<-loading
+	_, err = cache.Get("key", LoaderContext{})
+	assert.ErrorIs(t, err, ErrLoadLimit)
+	close(unblock)
+	assert.NoError(t, <-done)
+	_, err = cache.Get("key", LoaderContext{})
+	assert.NoError(t, err)
+}
+
+func TestLoaderContext(t *testing.T) {
+	unblock := make(chan struct{})
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		if key == "slow" {
+			<-unblock
+		}
+		return []byte(key + lc.Metadata["suffix"]), TTL{Idle: 1 * time.Minute}, nil
+	}, nil, &BucketConfig{
+		MinTick:            1 * time.Second,
+		MaxTick:            10 * time.Second,
+		MaxConcurrentLoads: &LoadLimit{Global: 1},
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// invalid LoaderContexts are rejected
+	_, err = cache.Get("key", LoaderContext{Length: -1})
+	assert.Error(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = cache.Get("key", LoaderContext{Context: ctx})
+	assert.ErrorIs(t, err, context.Canceled)
+	_, err = cache.Get("key", LoaderContext{Deadline: time.Now().Add(-time.Second)})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	// metadata is passed to the LoaderFunc
+	value, err := cache.Get("key", LoaderContext{Metadata: map[string]string{"suffix": "-a"}})
+	require.NoError(t, err)
+	assert.Equal(t, "key-a", string(value.([]byte)))
+
+	// loaded and cached values must have the requested length
+	_, err = cache.Get("other", LoaderContext{Length: 32})
+	assert.Error(t, err)
+	_, err = cache.Get("key", LoaderContext{Length: 64})
+	assert.Error(t, err)
+	value, err = cache.Get("key", LoaderContext{Length: 5})
+	require.NoError(t, err)
+	assert.Equal(t, "key-a", string(value.([]byte)))
+
+	// waiting for a load ends at the deadline
+	done := make(chan error)
+	go func() {
+		_, err := cache.Get("slow", LoaderContext{})
+		done <- err
+	}()
+	time.Sleep(50 * time.Millisecond)
+	_, err = cache.Get("waiting", LoaderContext{Deadline: time.Now().Add(50 * time.Millisecond)})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	close(unblock)
+	assert.NoError(t, <-done)
+}
+
+// errorLogger sends the messages of Error entries to a channel.
+type errorLogger struct {
+	nopLogger
+	errors chan string
+}
+
+func (l *errorLogger) Error(msg string, _ ...interface{}) {
+	l.errors <- msg
+}
+
+func TestPanics(t *testing.T) {
+	log := &errorLogger{errors: make(chan string, 10)}
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		if key == "panic" {
+			panic("loader failed")
+		}
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}, func(key string) {
+		panic("evicted failed")
+	}, &BucketConfig{
+		MinTick:            1 * time.Second,
+		MaxTick:            10 * time.Second,
+		MaxConcurrentLoads: &LoadLimit{Global: 1, FailFast: true},
+	}, log)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// a panicking LoaderFunc returns an error and releases its load
+	_, err = cache.Get("panic", LoaderContext{})
+	assert.ErrorIs(t, err, ErrLoaderPanic)
+	assert.Equal(t, "LoaderFunc panicked", <-log.errors)
+	value, err := cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "key", value)
+
+	// a panicking EvictedFunc is logged
+	assert.Equal(t, 1, cache.Shrink(1))
+	select {
+	case msg := <-log.errors:
+		assert.Equal(t, "EvictedFunc panicked", msg)
+	case <-time.After(2 * time.Second):
+		t.Fatal("panic of EvictedFunc wasn't logged")
+	}
+}
+
+func TestReload(t *testing.T) {
+	loads := make(map[string]int)
+	c, err := NewCache(2, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		loads[key]++
+		return key, TTL{Idle: 300 * time.Millisecond}, nil
+	}, nil, &BucketConfig{
+		MinTick: 10 * time.Millisecond,
+		MaxTick: 1 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer c.Close()
+	b := c.(*tearc).buckets[0]
+
+	// key1 is replaced by the arc cache, but not yet reaped
+	for _, key := range []string{"key1", "key2", "key3"} {
+		_, err = c.Get(key, LoaderContext{})
+		require.NoError(t, err)
+	}
+	b.eqLock.Lock()
+	assert.False(t, b.arc.has("key1"))
+	b.eqLock.Unlock()
+
+	// reloading key1 reuses its item in the eviction queue
+	time.Sleep(200 * time.Millisecond)
+	_, err = c.Get("key1", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads["key1"])
+	b.eqLock.Lock()
+	n := 0
+	for _, item := range b.eq {
+		if item.key == "key1" {
+			n++
+		}
+	}
+	b.eqLock.Unlock()
+	assert.Equal(t, 1, n)
+
+	// the eviction time of the first load doesn't evict the reloaded value
+	time.Sleep(200 * time.Millisecond)
+	_, err = c.Get("key1", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads["key1"])
+}
+
+func TestStaleRefresh(t *testing.T) {
+	var loads int32
+	refreshing := make(chan struct{})
+	unblock := make(chan struct{})
+	cache, err := NewCache(100, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		n := atomic.AddInt32(&loads, 1)
+		if n == 2 {
+			close(refreshing)
+			<-unblock
+		}
+		return fmt.Sprintf("value%d", n), TTL{Idle: 1 * time.Minute, Soft: 10 * time.Millisecond}, nil
+	}, nil, &BucketConfig{
+		MinTick: 1 * time.Second,
+		MaxTick: 10 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	_, err = cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	time.Sleep(20 * time.Millisecond)
+	_, err = cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+
+	// the item is evicted and loaded again while it is refreshed
+	<-refreshing
+	assert.Equal(t, 1, cache.Shrink(1))
+	x, err := cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "value3", x)
+
+	// the stale refresh doesn't replace the newer value
+	close(unblock)
+	time.Sleep(50 * time.Millisecond)
+	x, err = cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, "value3", x)
+}
+
+func TestRemove(t *testing.T) {
+	evicted := make(chan string, 10)
+	loads := 0
+
+	cache, err := NewCache(100, 4, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		loads++
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick: 1 * time.Second,
+		MaxTick: 10 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	_, err = cache.Get("key1", LoaderContext{})
+	require.NoError(t, err)
+
+	assert.True(t, cache.Remove("key1"))
+	assert.Equal(t, "key1", <-evicted)
+	assert.False(t, cache.Remove("key1"))
+	assert.False(t, cache.Remove("key2"))
+
+	// removed values are loaded again
+	_, err = cache.Get("key1", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, loads)
+}
+
+func TestResize(t *testing.T) {
+	evicted := make(chan string, 20)
+	loads := map[string]int{}
+	var loadsLock sync.Mutex
+
+	cache, err := NewCache(10, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		loadsLock.Lock()
+		loads[key]++
+		loadsLock.Unlock()
+		return []byte(key), TTL{Idle: 1 * time.Minute}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick: 1 * time.Second,
+		MaxTick: 10 * time.Second,
+		Zeroize: true,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	var values [][]byte
+	for i := 0; i < 10; i++ {
+		x, err := cache.Get(fmt.Sprint("key", i), LoaderContext{})
+		require.NoError(t, err)
+		values = append(values, x.([]byte))
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.Error(t, cache.Resize(0))
+	require.NoError(t, cache.Resize(4))
+
+	// only the least recently used items are evicted
+	var keys []string
+	for i := 0; i < 6; i++ {
+		keys = append(keys, <-evicted)
+	}
+	assert.ElementsMatch(t, []string{"key0", "key1", "key2", "key3", "key4", "key5"}, keys)
+	for i := 6; i < 10; i++ {
+		key := fmt.Sprint("key", i)
+		x, err := cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+		assert.Equal(t, key, string(x.([]byte)))
+		assert.Equal(t, key, string(values[i]), "moved values aren't zeroized")
+		assert.Equal(t, 1, loads[key])
+	}
+
+	// a grown cache holds more items without evictions
+	require.NoError(t, cache.Resize(20))
+	for i := 0; i < 20; i++ {
+		_, err := cache.Get(fmt.Sprint("key", i), LoaderContext{})
+		require.NoError(t, err)
+	}
+	assert.Len(t, evicted, 0)
+	for i := 6; i < 10; i++ {
+		assert.Equal(t, 1, loads[fmt.Sprint("key", i)])
+	}
+
+	sharded, err := NewCache(10, 2, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}, nil, &BucketConfig{MinTick: 1 * time.Second, MaxTick: 10 * time.Second}, nil)
+	require.NoError(t, err)
+	defer sharded.Close()
+	assert.Error(t, sharded.Resize(7))
+}
+
+func TestSetTicks(t *testing.T) {
+	evicted := make(chan time.Time, 10)
+
+	cache, err := NewCache(10, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{Idle: 50 * time.Millisecond}, nil
+	}, func(key string) {
+		evicted <- time.Now()
+	}, &BucketConfig{
+		MinTick: 5 * time.Second,
+		MaxTick: 10 * time.Second,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	assert.Error(t, cache.SetTicks(time.Second, time.Second))
+
+	// the reaper is rescheduled with the new MinTick instead of waiting for
+	// the old one
+	_, err = cache.Get("key", LoaderContext{})
+	require.NoError(t, err)
+	require.NoError(t, cache.SetTicks(10*time.Millisecond, 1*time.Second))
+	select {
+	case <-evicted:
+	case <-time.After(1 * time.Second):
+		t.Fatal("item wasn't evicted after MinTick was lowered")
+	}
+}
+
+func TestAutoTune(t *testing.T) {
+	events := make(chan TuneEvent, 10)
+	newCache := func(size int, autoTune *AutoTune) *tearc {
+		autoTune.Interval = time.Hour
+		autoTune.Events = events
+		cache, err := NewCache(size, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+			return key, TTL{Idle: 1 * time.Minute}, nil
+		}, nil, &BucketConfig{
+			MinTick:  1 * time.Second,
+			MaxTick:  10 * time.Second,
+			AutoTune: autoTune,
+		}, nil)
+		require.NoError(t, err)
+		return cache.(*tearc)
+	}
+
+	// a full cache that misses most Gets grows
+	cache := newCache(4, &AutoTune{TargetHitRatio: 0.9, Apply: true})
+	defer cache.Close()
+	for i := 0; i < 200; i++ {
+		_, err := cache.Get(fmt.Sprint("key", i%8), LoaderContext{})
+		require.NoError(t, err)
+	}
+	cache.tuner.tune()
+	e := <-events
+	assert.Equal(t, uint64(200), e.Gets)
+	assert.Less(t, e.HitRatio, 0.9)
+	assert.Equal(t, 1.0, e.Occupancy)
+	assert.Equal(t, 8, e.RecommendedSize)
+	assert.Equal(t, 1, e.RecommendedShards)
+	assert.True(t, e.Applied)
+	assert.Contains(t, e.Reason, "grow")
+	assert.Equal(t, 8, cache.buckets[0].size)
+
+	// the counters are reset by every sample
+	cache.tuner.tune()
+	assert.Len(t, events, 0)
+
+	// a mostly empty cache shrinks, but not below MinSize
+	cache = newCache(100, &AutoTune{TargetHitRatio: 0.9, MinSize: 64})
+	defer cache.Close()
+	for i := 0; i < 200; i++ {
+		_, err := cache.Get("key", LoaderContext{})
+		require.NoError(t, err)
+	}
+	cache.tuner.tune()
+	e = <-events
+	assert.Equal(t, 64, e.RecommendedSize)
+	assert.False(t, e.Applied)
+	assert.Equal(t, 100, cache.buckets[0].size)
+
+	_, err := NewCache(10, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{}, nil
+	}, nil, &BucketConfig{
+		MinTick:  1 * time.Second,
+		MaxTick:  10 * time.Second,
+		AutoTune: &AutoTune{Interval: time.Minute, TargetHitRatio: 2},
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestJumpHash(t *testing.T) {
+	assert.Equal(t, 0, JumpHash(42, 0))
+	assert.Equal(t, 0, JumpHash(42, 1))
+
+	const keys = 10000
+	counts := make([]int, 10)
+	for k := uint64(0); k < keys; k++ {
+		key := k * 0x9e3779b97f4a7c15
+		b := JumpHash(key, 10)
+		require.True(t, b >= 0 && b < 10)
+		counts[b]++
+		assert.Equal(t, b, JumpHash(key, 10), "the mapping is deterministic")
+
+		// growing the amount of buckets only moves keys to the new bucket
+		if grown := JumpHash(key, 11); grown != b {
+			assert.Equal(t, 10, grown)
+		}
+	}
+	for _, c := range counts {
+		assert.InDelta(t, keys/10, c, keys/50, "keys are distributed evenly")
+	}
+
+	moved := 0
+	for k := uint64(0); k < keys; k++ {
+		key := k * 0x9e3779b97f4a7c15
+		if shardIndex(ShardingJump, key, 10) != shardIndex(ShardingJump, key, 11) {
+			moved++
+		}
+	}
+	assert.InDelta(t, keys/11, moved, keys/50)
+}
+
+func TestShardingJump(t *testing.T) {
+	loader := func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{Idle: 1 * time.Minute}, nil
+	}
+	_, err := NewCache(16, 4, loader, nil, &BucketConfig{MinTick: time.Second, MaxTick: 10 * time.Second, Sharding: 7}, nil)
+	assert.Error(t, err)
+
+	cache, err := NewCache(16, 4, loader, nil, &BucketConfig{MinTick: time.Second, MaxTick: 10 * time.Second, Sharding: ShardingJump}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	used := map[*bucket]bool{}
+	for i := 0; i < 16; i++ {
+		key := fmt.Sprint("key", i)
+		x, err := cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+		assert.Equal(t, key, x)
+		used[cache.(*tearc).jump(key)] = true
+	}
+	assert.Greater(t, len(used), 1)
+}
+
+func TestEvictionARCOnly(t *testing.T) {
+	evicted := make(chan string, 10)
+	cache, err := NewCache(2, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{Idle: 10 * time.Millisecond, Hard: 10 * time.Millisecond}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick:  10 * time.Millisecond,
+		MaxTick:  100 * time.Millisecond,
+		Eviction: EvictionARCOnly,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// the TTL is ignored
+	_, err = cache.Get("key1", LoaderContext{})
+	require.NoError(t, err)
+	time.Sleep(200 * time.Millisecond)
+	assert.Len(t, evicted, 0)
+	r := cache.(*tearc).reaper
+	r.nextLock.Lock()
+	assert.True(t, r.next.IsZero(), "the reaper isn't scheduled")
+	r.nextLock.Unlock()
+
+	// the ARC replaces items of a full bucket
+	for _, key := range []string{"key2", "key3"} {
+		_, err = cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, cache.(*tearc).buckets[0].arc.len())
+
+	_, err = NewCache(2, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		return key, TTL{}, nil
+	}, nil, &BucketConfig{MinTick: time.Second, MaxTick: 10 * time.Second, Eviction: EvictionARCOnly, MaxLifetime: time.Hour}, nil)
+	assert.Error(t, err)
+}
+
+func TestEvictionTTLOnly(t *testing.T) {
+	var loads int32
+	evicted := make(chan string, 10)
+	cache, err := NewCache(2, 1, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		atomic.AddInt32(&loads, 1)
+		return key, TTL{Idle: 300 * time.Millisecond}, nil
+	}, func(key string) {
+		evicted <- key
+	}, &BucketConfig{
+		MinTick:  10 * time.Millisecond,
+		MaxTick:  100 * time.Millisecond,
+		Eviction: EvictionTTLOnly,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	for _, key := range []string{"key1", "key2", "key3"} {
+		x, err := cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+		assert.Equal(t, key, x)
+	}
+
+	// the full bucket returns key3, but doesn't replace a cached item
+	assert.Len(t, evicted, 0)
+	for _, key := range []string{"key1", "key2"} {
+		_, err := cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(3), atomic.LoadInt32(&loads))
+	_, err = cache.Get("key3", LoaderContext{})
+	require.NoError(t, err)
+	assert.Equal(t, int32(4), atomic.LoadInt32(&loads))
+
+	// items are evicted by time
+	var keys []string
+	for i := 0; i < 2; i++ {
+		select {
+		case key := <-evicted:
+			keys = append(keys, key)
+		case <-time.After(2 * time.Second):
+			t.Fatal("items weren't evicted")
+		}
+	}
+	assert.ElementsMatch(t, []string{"key1", "key2"}, keys)
+}
+
+func TestStats(t *testing.T) {
+	var loads int32
+	cache, err := NewCache(4, 2, func(key string, lc LoaderContext) (value interface{}, ttl TTL, err error) {
+		atomic.AddInt32(&loads, 1)
+		return key, TTL{}, nil
+	}, nil, &BucketConfig{
+		MinTick:  time.Second,
+		MaxTick:  10 * time.Second,
+		Eviction: EvictionARCOnly,
+	}, nil)
+	require.NoError(t, err)
+	defer cache.Close()
+
+	// all keys of the same shard
+	var keys []string
+	for i := 0; len(keys) < 3; i++ {
+		key := fmt.Sprintf("key%d", i)
+		if cache.(*tearc).jump(key).id == 0 {
+			keys = append(keys, key)
+		}
+	}
+	a, b, c := keys[0], keys[1], keys[2]
+
+	// a is used twice (T2), b once (T1). c replaces b, which is loaded
+	// again from B1 and replaces a, which is loaded again from B2
+	for _, key := range []string{a, a, b, c, b, a} {
+		_, err = cache.Get(key, LoaderContext{})
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(5), atomic.LoadInt32(&loads))
+	assert.Equal(t, []ShardStats{
+		{Size: 2, Target: 0, T1: 0, T2: 2, B1: 1, B2: 0, B1Hits: 1, B2Hits: 1},
+		{Size: 2},
+	}, cache.Stats())
+
+	// removed keys move to the ghost lists
+	assert.True(t, cache.Remove(a))
+	assert.Equal(t, ShardStats{Size: 2, T2: 1, B1: 1, B2: 1, B1Hits: 1, B2Hits: 1}, cache.Stats()[0])
+
+	// resizing keeps the ghost list hits
+	require.NoError(t, cache.Resize(8))
+	assert.Equal(t, ShardStats{Size: 4, T1: 1, B1Hits: 1, B2Hits: 1}, cache.Stats()[0])
+}
+
+func TestSimulate(t *testing.T) {
+	trace, err := ReadTrace(strings.NewReader(`# key,time
+a,2021-08-30T12:00:00Z
+b,2021-08-30T12:00:10Z
+a,2021-08-30T12:00:20Z
+c,2021-08-30T12:00:30Z
+a,2021-08-30T12:02:00Z
+`))
+	require.NoError(t, err)
+	require.Len(t, trace, 5)
+
+	result, err := Simulate(trace, &Simulation{
+		Size:   4,
+		Shards: 1,
+		Config: &BucketConfig{MinTick: time.Second, MaxTick: 10 * time.Second},
+		TTL:    func(string) TTL { return TTL{Idle: time.Minute} },
+	})
+	require.NoError(t, err)
+	// a is evicted after idling for a minute, long before the trace ends
+	assert.Equal(t, uint64(5), result.Accesses)
+	assert.Equal(t, uint64(1), result.Hits)
+	assert.Equal(t, 0.2, result.HitRatio)
+	assert.Equal(t, uint64(4), result.Loads)
+	assert.Equal(t, 3, result.PeakResident)
+	assert.Equal(t, trace[3].Time, result.PeakTime)
+	// only a is cached, loaded again from B2 after it was reaped
+	assert.Equal(t, ShardStats{Size: 4, T2: 1, B1: 2, B2Hits: 1}, result.Shards[0])
+
+	// equal traces and configurations have equal results
+	trace = trace[:0]
+	start := time.Date(2021, 8, 30, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10000; i++ {
+		trace = append(trace, Access{Key: fmt.Sprintf("key%d", (i*i)%997%(i%50+1)), Time: start.Add(time.Duration(i) * time.Second)})
+	}
+	sim := &Simulation{
+		Size:   32,
+		Shards: 4,
+		Config: &BucketConfig{MinTick: time.Second, MaxTick: 10 * time.Second, Policy: PolicyTinyLFU},
+		TTL:    func(string) TTL { return TTL{Idle: 10 * time.Minute, Soft: time.Minute} },
+	}
+	first, err := Simulate(trace, sim)
+	require.NoError(t, err)
+	second, err := Simulate(trace, sim)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Greater(t, first.Loads, first.Accesses-first.Hits, "refreshes are loads")
+
+	_, err = Simulate([]Access{{Key: "a", Time: start}, {Key: "b", Time: start.Add(-time.Second)}}, sim)
+	assert.Error(t, err)
+	_, err = ReadTrace(strings.NewReader("a,yesterday\n"))
+	assert.Error(t, err)
 }